@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+	"pano/internal/system"
+)
+
+// runAdd handles "pano add --file <path> [more paths]", which the Explorer entries run,
+// and "pano add --text <text> [--pin]"
+// Items go to the running instance so it can refresh and notify; without one they are
+// stored directly. Returns the exit code
+func runAdd(args []string) int {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	file := fs.String("file", "", "file to add as an item")
+	text := fs.String("text", "", "text to add as an item")
+	pin := fs.Bool("pin", false, "pin the text item")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *text != "" {
+		return addText(*text, *pin)
+	}
+
+	// Send To passes every selected file after the fixed arguments
+	paths := fs.Args()
+	if *file != "" {
+		paths = append([]string{*file}, paths...)
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "no file given, use --file <path>")
+		return 2
+	}
+	for i, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			paths[i] = abs
+		}
+	}
+
+	dbPath, err := storage.GetDatabasePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate database: %v\n", err)
+		return 1
+	}
+	err = system.SendIPC(filepath.Dir(dbPath), system.IPCRequest{Command: system.IPCCommandAdd, Paths: paths})
+	if err == nil {
+		return 0
+	}
+	if !errors.Is(err, system.ErrNoInstance) {
+		fmt.Fprintf(os.Stderr, "add failed: %v\n", err)
+		return 1
+	}
+
+	return addDirect(paths)
+}
+
+// addDirect stores files in the database when no instance is running
+func addDirect(paths []string) int {
+	db, err := storage.NewDatabaseWithPassword(promptMasterPassword)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	db.SetAuditSource(storage.AuditSourceCLI)
+	if err := db.LoadError(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load database: %v\n", err)
+		return 1
+	}
+
+	manager := clipboard.NewManager(db)
+	code := 0
+	for _, path := range paths {
+		_, err := manager.AddFile(path)
+		var warning *storage.LimitWarning
+		if err != nil && !errors.As(err, &warning) {
+			fmt.Fprintf(os.Stderr, "failed to add %s: %v\n", path, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// addText stores text through the running instance, or directly without one
+func addText(text string, pin bool) int {
+	dbPath, err := storage.GetDatabasePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate database: %v\n", err)
+		return 1
+	}
+	err = system.SendIPC(filepath.Dir(dbPath), system.IPCRequest{Command: system.IPCCommandAddText, Text: text, Pin: pin})
+	if err == nil {
+		return 0
+	}
+	if !errors.Is(err, system.ErrNoInstance) {
+		fmt.Fprintf(os.Stderr, "add failed: %v\n", err)
+		return 1
+	}
+
+	db, err := storage.NewDatabaseWithPassword(promptMasterPassword)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	db.SetAuditSource(storage.AuditSourceCLI)
+	if err := db.LoadError(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load database: %v\n", err)
+		return 1
+	}
+
+	err = clipboard.NewManager(db).AddText(text, pin)
+	var warning *storage.LimitWarning
+	if err != nil && !errors.As(err, &warning) {
+		fmt.Fprintf(os.Stderr, "add failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
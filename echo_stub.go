@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// disableEcho is a no-op where the console mode isn't managed; what is typed stays visible
+func disableEcho() func() {
+	return func() {}
+}
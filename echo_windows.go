@@ -0,0 +1,25 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// disableEcho stops the console from showing what is typed and returns a func that
+// turns it back on
+func disableEcho() func() {
+	handle := windows.Handle(os.Stdin.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return func() {}
+	}
+	if err := windows.SetConsoleMode(handle, mode&^windows.ENABLE_ECHO_INPUT); err != nil {
+		return func() {}
+	}
+	return func() {
+		_ = windows.SetConsoleMode(handle, mode)
+	}
+}
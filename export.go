@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+)
+
+// runExport handles "pano export": it writes the history in an older format and
+// returns the exit code
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", storage.ExportFormatV1, "target format: v1 (readable by the original build)")
+	out := fs.String("o", "-", "output file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != storage.ExportFormatV1 {
+		fmt.Fprintf(os.Stderr, "unsupported export format %q\n", *format)
+		return 2
+	}
+
+	db, err := storage.NewDatabaseWithPassword(promptMasterPassword)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	db.SetAuditSource(storage.AuditSourceCLI)
+	if err := db.LoadError(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load database: %v\n", err)
+		return 1
+	}
+
+	// An unreadable defaults file can't lift a policy it might hold, so it refuses too
+	configPath, err := clipboard.GetConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate config: %v\n", err)
+		return 1
+	}
+	cfg, err := clipboard.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read config: %v\n", err)
+		return 1
+	}
+	if err := clipboard.NewPermissions(cfg).CheckExport(); err != nil {
+		db.RecordExportDenied()
+		fmt.Fprintf(os.Stderr, "export refused: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if *out != "-" {
+		if f, err = os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create %s: %v\n", *out, err)
+			return 1
+		}
+		w = f
+	}
+
+	report, err := db.ExportV1(w)
+	if f != nil {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to save export: %w", closeErr)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export failed: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Exported %s\n", report)
+	return 0
+}
@@ -10,9 +10,27 @@ import (
 	"fyne.io/fyne/v2"
 )
 
+// iconDesignSize is the pixel grid the icon is drawn on
+const iconDesignSize = 64
+
 // getPanoIcon creates a modern clipboard icon for system tray and exe
 func getPanoIcon() fyne.Resource {
-	size := 64
+	return encodeIcon(renderPanoIcon())
+}
+
+// getPanoIconSized draws the icon at a pixel size, so small tray icons are filtered here
+// instead of by the shell; sizes at or above the design size use the full drawing
+func getPanoIconSized(size int) fyne.Resource {
+	img := renderPanoIcon()
+	if size <= 0 || size >= iconDesignSize {
+		return encodeIcon(img)
+	}
+	return encodeIcon(downsampleIcon(img, size))
+}
+
+// renderPanoIcon draws the icon at iconDesignSize
+func renderPanoIcon() *image.RGBA {
+	size := iconDesignSize
 	img := image.NewRGBA(image.Rect(0, 0, size, size))
 
 	// Colors
@@ -110,7 +128,11 @@ func getPanoIcon() fyne.Resource {
 		}
 	}
 
-	// Encode to PNG
+	return img
+}
+
+// encodeIcon wraps an icon image as a PNG resource
+func encodeIcon(img image.Image) fyne.Resource {
 	var buf bytes.Buffer
 	if err := png.Encode(&buf, img); err != nil {
 		return nil
@@ -119,6 +141,34 @@ func getPanoIcon() fyne.Resource {
 	return fyne.NewStaticResource("pano-icon.png", buf.Bytes())
 }
 
+// downsampleIcon shrinks a square icon with an area average (image.RGBA is premultiplied)
+func downsampleIcon(src *image.RGBA, size int) *image.RGBA {
+	srcSize := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := y*srcSize/size, (y+1)*srcSize/size
+		for x := 0; x < size; x++ {
+			x0, x1 := x*srcSize/size, (x+1)*srcSize/size
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := src.RGBAAt(sx, sy)
+					r += int(c.R)
+					g += int(c.G)
+					b += int(c.B)
+					a += int(c.A)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
 // isInsideRoundedRect checks if a point is inside a rounded rectangle
 func isInsideRoundedRect(x, y, left, top, right, bottom int, radius float64) bool {
 	// Check corners
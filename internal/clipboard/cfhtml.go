@@ -0,0 +1,86 @@
+package clipboard
+
+import (
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CF_HTML ("HTML Format") starts with an ASCII header of Key:Value lines before the
+// markup, as written by Chrome and Edge when copying from a page:
+//
+//	Version:0.9
+//	StartHTML:0000000105
+//	EndHTML:0000000401
+//	StartFragment:0000000141
+//	EndFragment:0000000365
+//	SourceURL:https://example.com/article
+//	<html><body><!--StartFragment-->...
+//
+// Firefox writes the same header with SourceURL last; some applications leave
+// SourceURL out, pad the offsets, or use "about:blank".
+
+// cfhtmlMaxHeader bounds how far into the data the header is searched
+const cfhtmlMaxHeader = 4096
+
+// ParseCFHTMLSourceURL returns the page URL from a CF_HTML header, or "" when there is
+// none; missing, garbled or non-web URLs are ignored rather than reported
+func ParseCFHTMLSourceURL(data []byte) string {
+	header := data
+	if len(header) > cfhtmlMaxHeader {
+		header = header[:cfhtmlMaxHeader]
+	}
+
+	// The header ends where the markup starts: at StartHTML if it is sane, else at the first '<'
+	end := bytes.IndexByte(header, '<')
+	if end < 0 {
+		end = len(header)
+	}
+	if start := cfhtmlOffset(header[:end], "StartHTML"); start > 0 && start < end {
+		end = start
+	}
+
+	for _, line := range strings.FieldsFunc(string(header[:end]), func(r rune) bool {
+		return r == '\r' || r == '\n'
+	}) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "SourceURL") {
+			continue
+		}
+		return cleanSourceURL(strings.TrimSpace(value))
+	}
+	return ""
+}
+
+// cfhtmlOffset reads a numeric header such as StartHTML, -1 if absent or invalid
+func cfhtmlOffset(header []byte, name string) int {
+	for _, line := range strings.FieldsFunc(string(header), func(r rune) bool {
+		return r == '\r' || r == '\n'
+	}) {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return -1
+			}
+			return n
+		}
+	}
+	return -1
+}
+
+// cleanSourceURL keeps only absolute http(s) URLs
+func cleanSourceURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,52 @@
+package clipboard
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+
+	"pano/internal/storage"
+)
+
+// Closing the monitor and then the database leaves no goroutine behind: the poll loop,
+// the callbacks, the change feed and the save timer are all waited for
+func TestCloseLeaksNoGoroutines(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	storage.UseTempDataDir(t)
+	db, err := storage.NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	changed := make(chan struct{}, 1)
+	db.OnChange(func(storage.ChangeEvent) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	r := &fakeReader{}
+	m := NewMonitor(db, WithReader(r))
+	m.SetOnChange(func(string, []byte) {})
+	if err := m.Start(); err != nil {
+		t.Fatalf("failed to start monitor: %v", err)
+	}
+	r.set("captured", nil, false)
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the monitor captured nothing")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Errorf("failed to close monitor: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("second close failed: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("failed to close database: %v", err)
+	}
+}
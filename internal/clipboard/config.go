@@ -0,0 +1,299 @@
+package clipboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"pano/internal/storage"
+)
+
+// ConfigFile is the optional defaults file in the data directory
+const ConfigFile = "config.json"
+
+// Config holds startup defaults for the Monitor and Manager
+// Nil fields are unset, so configs from several sources can be layered with Merge
+type Config struct {
+	PollIntervalMs   *int     `json:"poll_interval_ms,omitempty"`
+	LockedIntervalMs *int     `json:"locked_interval_ms,omitempty"`
+	MaxItems         *int     `json:"max_items,omitempty"`
+	MaxTotalMB       *int     `json:"max_total_mb,omitempty"` // 0 turns the size cap off
+	GraceMinutes     *int     `json:"grace_minutes,omitempty"`
+	LimitPolicy      *string  `json:"limit_policy,omitempty"`
+	NewlineMode      *string  `json:"newline_mode,omitempty"`
+	DeltaImages      *bool    `json:"delta_images,omitempty"`
+	ArchiveEnabled   *bool    `json:"archive_enabled,omitempty"`
+	ArchiveMaxMB     *int     `json:"archive_max_mb,omitempty"`
+	StripTracking    *bool    `json:"strip_tracking,omitempty"`
+	TrackingParams   []string `json:"tracking_params,omitempty"`
+	DedupMode        *string  `json:"dedup_mode,omitempty"`
+	PinLimit         *int     `json:"pin_limit,omitempty"`
+	DoubleCopyPinMs  *int     `json:"double_copy_pin_ms,omitempty"` // 0 turns the gesture off
+
+	// CaptureExcludedApps are the executables whose copies are never stored; an empty
+	// list excludes nothing
+	CaptureExcludedApps []string `json:"capture_excluded_apps,omitempty"`
+
+	// Redaction rules from the defaults file act as policy: they always apply and can't be
+	// edited in the app; with RedactionLocked users can't add rules of their own either
+	RedactionRules  []storage.RedactionRule `json:"redaction_rules,omitempty"`
+	RedactionLocked *bool                   `json:"redaction_locked,omitempty"`
+
+	// DisableExport forbids writing the history out of the data directory, see Permissions
+	DisableExport *bool `json:"disable_export,omitempty"`
+}
+
+// DefaultConfig returns the built-in defaults with every field set
+func DefaultConfig() *Config {
+	poll := int(DefaultPollInterval / time.Millisecond)
+	locked := int(DefaultLockedInterval / time.Millisecond)
+	maxItems := storage.DefaultMaxItems
+	maxTotalMB := int(storage.DefaultMaxTotalSize / (1024 * 1024))
+	graceMinutes := int(storage.DefaultGraceWindow / time.Minute)
+	limitPolicy := string(storage.LimitEvictOldest)
+	newline := string(NewlineAsIs)
+	deltaImages, archiveEnabled, stripTracking := false, false, false
+	archiveMaxMB := 0
+	dedupMode := string(storage.DedupAll)
+	pinLimit := storage.DefaultPinLimit
+	doubleCopy := 0
+	return &Config{
+		PollIntervalMs:   &poll,
+		LockedIntervalMs: &locked,
+		MaxItems:         &maxItems,
+		MaxTotalMB:       &maxTotalMB,
+		GraceMinutes:     &graceMinutes,
+		LimitPolicy:      &limitPolicy,
+		NewlineMode:      &newline,
+		DeltaImages:      &deltaImages,
+		ArchiveEnabled:   &archiveEnabled,
+		ArchiveMaxMB:     &archiveMaxMB,
+		StripTracking:    &stripTracking,
+		TrackingParams:   append([]string(nil), storage.DefaultTrackingParams...),
+		DedupMode:        &dedupMode,
+		PinLimit:         &pinLimit,
+		DoubleCopyPinMs:  &doubleCopy,
+
+		CaptureExcludedApps: append([]string(nil), DefaultExcludedApps...),
+	}
+}
+
+// GetConfigPath returns the full path to the defaults file
+func GetConfigPath() (string, error) {
+	dbPath, err := storage.GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), ConfigFile), nil
+}
+
+// LoadConfig reads a JSON defaults file; a missing file yields an empty config
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks set fields for out-of-range values
+func (c *Config) Validate() error {
+	if c.PollIntervalMs != nil && *c.PollIntervalMs <= 0 {
+		return fmt.Errorf("poll_interval_ms must be positive")
+	}
+	if c.LockedIntervalMs != nil && *c.LockedIntervalMs <= 0 {
+		return fmt.Errorf("locked_interval_ms must be positive")
+	}
+	if c.MaxItems != nil && *c.MaxItems <= 0 {
+		return fmt.Errorf("max_items must be positive")
+	}
+	if c.MaxTotalMB != nil && *c.MaxTotalMB < 0 {
+		return fmt.Errorf("max_total_mb must not be negative")
+	}
+	if c.GraceMinutes != nil && *c.GraceMinutes < 0 {
+		return fmt.Errorf("grace_minutes must not be negative")
+	}
+	if c.ArchiveMaxMB != nil && *c.ArchiveMaxMB < 0 {
+		return fmt.Errorf("archive_max_mb must not be negative")
+	}
+	if c.LimitPolicy != nil {
+		switch storage.LimitPolicy(*c.LimitPolicy) {
+		case storage.LimitEvictOldest, storage.LimitReject:
+		default:
+			return fmt.Errorf("unknown limit_policy: %q", *c.LimitPolicy)
+		}
+	}
+	if c.DedupMode != nil {
+		switch storage.DedupMode(*c.DedupMode) {
+		case storage.DedupAll, storage.DedupRecent, storage.DedupOff:
+		default:
+			return fmt.Errorf("unknown dedup_mode: %q", *c.DedupMode)
+		}
+	}
+	if c.PinLimit != nil && *c.PinLimit < 1 {
+		return fmt.Errorf("pin_limit must be at least 1")
+	}
+	if c.DoubleCopyPinMs != nil && *c.DoubleCopyPinMs < 0 {
+		return fmt.Errorf("double_copy_pin_ms must not be negative")
+	}
+	if _, err := storage.NewRedactor(c.RedactionRules); err != nil {
+		return err
+	}
+	if c.NewlineMode != nil {
+		switch NewlineMode(*c.NewlineMode) {
+		case NewlineAsIs, NewlineLF, NewlineCRLF:
+		default:
+			return fmt.Errorf("unknown newline_mode: %q", *c.NewlineMode)
+		}
+	}
+	return nil
+}
+
+// Merge returns a copy of c with every field set in over replacing it
+// Layering is DefaultConfig < file < preferences < flags
+func (c *Config) Merge(over *Config) *Config {
+	merged := *c
+	if over == nil {
+		return &merged
+	}
+	if over.PollIntervalMs != nil {
+		merged.PollIntervalMs = over.PollIntervalMs
+	}
+	if over.LockedIntervalMs != nil {
+		merged.LockedIntervalMs = over.LockedIntervalMs
+	}
+	if over.MaxItems != nil {
+		merged.MaxItems = over.MaxItems
+	}
+	if over.MaxTotalMB != nil {
+		merged.MaxTotalMB = over.MaxTotalMB
+	}
+	if over.GraceMinutes != nil {
+		merged.GraceMinutes = over.GraceMinutes
+	}
+	if over.NewlineMode != nil {
+		merged.NewlineMode = over.NewlineMode
+	}
+	if over.DeltaImages != nil {
+		merged.DeltaImages = over.DeltaImages
+	}
+	if over.ArchiveEnabled != nil {
+		merged.ArchiveEnabled = over.ArchiveEnabled
+	}
+	if over.ArchiveMaxMB != nil {
+		merged.ArchiveMaxMB = over.ArchiveMaxMB
+	}
+	if over.StripTracking != nil {
+		merged.StripTracking = over.StripTracking
+	}
+	if over.TrackingParams != nil {
+		merged.TrackingParams = over.TrackingParams
+	}
+	if over.LimitPolicy != nil {
+		merged.LimitPolicy = over.LimitPolicy
+	}
+	if over.DedupMode != nil {
+		merged.DedupMode = over.DedupMode
+	}
+	if over.PinLimit != nil {
+		merged.PinLimit = over.PinLimit
+	}
+	if over.DoubleCopyPinMs != nil {
+		merged.DoubleCopyPinMs = over.DoubleCopyPinMs
+	}
+	if over.CaptureExcludedApps != nil {
+		merged.CaptureExcludedApps = over.CaptureExcludedApps
+	}
+	if over.RedactionRules != nil {
+		merged.RedactionRules = over.RedactionRules
+	}
+	if over.RedactionLocked != nil {
+		merged.RedactionLocked = over.RedactionLocked
+	}
+	if over.DisableExport != nil {
+		merged.DisableExport = over.DisableExport
+	}
+	return &merged
+}
+
+// MonitorOptions converts the set fields into Monitor options
+func (c *Config) MonitorOptions() []MonitorOption {
+	opts := make([]MonitorOption, 0)
+	if c.PollIntervalMs != nil {
+		opts = append(opts, WithPollInterval(time.Duration(*c.PollIntervalMs)*time.Millisecond))
+	}
+	if c.LockedIntervalMs != nil {
+		opts = append(opts, WithLockedInterval(time.Duration(*c.LockedIntervalMs)*time.Millisecond))
+	}
+	if c.DoubleCopyPinMs != nil {
+		opts = append(opts, WithDoubleCopyPin(time.Duration(*c.DoubleCopyPinMs)*time.Millisecond))
+	}
+	if c.CaptureExcludedApps != nil {
+		opts = append(opts, WithExcludedApps(c.CaptureExcludedApps))
+	}
+	return opts
+}
+
+// ManagerOptions converts the set fields into Manager options
+func (c *Config) ManagerOptions() []ManagerOption {
+	opts := make([]ManagerOption, 0)
+	if c.MaxItems != nil {
+		opts = append(opts, WithMaxItems(*c.MaxItems))
+	}
+	if c.MaxTotalMB != nil {
+		opts = append(opts, WithMaxTotalSize(int64(*c.MaxTotalMB)*1024*1024))
+	}
+	if c.GraceMinutes != nil {
+		opts = append(opts, WithGraceWindow(time.Duration(*c.GraceMinutes)*time.Minute))
+	}
+	if c.NewlineMode != nil {
+		opts = append(opts, WithNewlineMode(NewlineMode(*c.NewlineMode)))
+	}
+	if c.DeltaImages != nil {
+		opts = append(opts, WithDeltaImages(*c.DeltaImages))
+	}
+	if c.ArchiveEnabled != nil || c.ArchiveMaxMB != nil {
+		enabled, maxMB := false, 0
+		if c.ArchiveEnabled != nil {
+			enabled = *c.ArchiveEnabled
+		}
+		if c.ArchiveMaxMB != nil {
+			maxMB = *c.ArchiveMaxMB
+		}
+		opts = append(opts, WithArchive(enabled, int64(maxMB)*1024*1024))
+	}
+	if c.StripTracking != nil || c.TrackingParams != nil {
+		enabled, params := false, storage.DefaultTrackingParams
+		if c.StripTracking != nil {
+			enabled = *c.StripTracking
+		}
+		if c.TrackingParams != nil {
+			params = c.TrackingParams
+		}
+		opts = append(opts, WithURLCleaning(enabled, params))
+	}
+	if c.LimitPolicy != nil {
+		opts = append(opts, WithLimitPolicy(storage.LimitPolicy(*c.LimitPolicy)))
+	}
+	if c.DedupMode != nil {
+		opts = append(opts, WithDedupMode(storage.DedupMode(*c.DedupMode)))
+	}
+	if c.PinLimit != nil {
+		opts = append(opts, WithPinLimit(*c.PinLimit))
+	}
+	opts = append(opts, WithPermissions(NewPermissions(c)))
+	return opts
+}
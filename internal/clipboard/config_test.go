@@ -0,0 +1,120 @@
+package clipboard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pano/internal/storage"
+)
+
+// writeConfig writes a defaults file into a temp directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ConfigFile)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+// TestLoadConfig reads defaults files, and rejects values no option accepts
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), ConfigFile))
+	if err != nil || cfg.MaxItems != nil || cfg.PollIntervalMs != nil {
+		t.Errorf("missing file loaded as %+v, %v; want an empty config", cfg, err)
+	}
+
+	cfg, err = LoadConfig(writeConfig(t, `{"max_items": 50, "newline_mode": "crlf", "tracking_params": []}`))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if *cfg.MaxItems != 50 || NewlineMode(*cfg.NewlineMode) != NewlineCRLF {
+		t.Errorf("loaded %+v", cfg)
+	}
+	if cfg.TrackingParams == nil || len(cfg.TrackingParams) != 0 {
+		t.Errorf("an empty tracking list loaded as %q; it must stay set and empty", cfg.TrackingParams)
+	}
+
+	invalid := []string{
+		`{"poll_interval_ms": 0}`,
+		`{"locked_interval_ms": -1}`,
+		`{"max_items": 0}`,
+		`{"max_total_mb": -5}`,
+		`{"grace_minutes": -1}`,
+		`{"archive_max_mb": -1}`,
+		`{"limit_policy": "drop"}`,
+		`{"dedup_mode": "sometimes"}`,
+		`{"pin_limit": 0}`,
+		`{"double_copy_pin_ms": -1}`,
+		`{"newline_mode": "cr"}`,
+		`{"redaction_rules": [{"pattern": "(", "replacement": "***"}]}`,
+		`{"max_items": "elli"}`,
+		`{`,
+	}
+	for _, content := range invalid {
+		if _, err := LoadConfig(writeConfig(t, content)); err == nil {
+			t.Errorf("%s loaded without an error", content)
+		}
+	}
+}
+
+// TestConfigMerge layers a file and flags over the defaults: only set fields override
+func TestConfigMerge(t *testing.T) {
+	file, err := LoadConfig(writeConfig(t, `{"max_items": 50, "limit_policy": "reject", "capture_excluded_apps": []}`))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	flagItems := 80
+	merged := DefaultConfig().Merge(file).Merge(&Config{MaxItems: &flagItems}).Merge(nil)
+
+	if *merged.MaxItems != 80 {
+		t.Errorf("max_items is %d, want the flag's 80", *merged.MaxItems)
+	}
+	if storage.LimitPolicy(*merged.LimitPolicy) != storage.LimitReject {
+		t.Errorf("limit_policy is %q, want the file's", *merged.LimitPolicy)
+	}
+	if *merged.PollIntervalMs != int(DefaultPollInterval/time.Millisecond) {
+		t.Errorf("poll_interval_ms is %d, want the default", *merged.PollIntervalMs)
+	}
+	if len(merged.CaptureExcludedApps) != 0 {
+		t.Errorf("excluded apps are %q; the file's empty list excludes nothing", merged.CaptureExcludedApps)
+	}
+	if *file.MaxItems != 50 {
+		t.Error("merging changed the file's config")
+	}
+}
+
+// TestConfigOptions builds a monitor and a manager from a config and checks what they
+// and the database were set to
+func TestConfigOptions(t *testing.T) {
+	storage.UseTempDataDir(t)
+	db, err := storage.NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	cfg, err := LoadConfig(writeConfig(t, `{"poll_interval_ms": 250, "max_items": 40,
+		"max_total_mb": 0, "grace_minutes": 2, "limit_policy": "reject", "newline_mode": "lf",
+		"dedup_mode": "off", "pin_limit": 3}`))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	m := NewMonitor(db, cfg.MonitorOptions()...)
+	manager := NewManager(db, cfg.ManagerOptions()...)
+
+	if got := m.PollInterval(); got != 250*time.Millisecond {
+		t.Errorf("poll interval is %v", got)
+	}
+	if got := manager.GetNewlineMode(); got != NewlineLF {
+		t.Errorf("newline mode is %q", got)
+	}
+	if db.GetMaxItems() != 40 || db.GetMaxTotalSize() != 0 || db.GetGraceWindow() != 2*time.Minute {
+		t.Errorf("limits are %d items, %d bytes, %v grace", db.GetMaxItems(), db.GetMaxTotalSize(), db.GetGraceWindow())
+	}
+	if db.GetLimitPolicy() != storage.LimitReject || db.GetDedupMode() != storage.DedupOff || db.GetPinLimit() != 3 {
+		t.Errorf("policies are %q, %q, pin limit %d", db.GetLimitPolicy(), db.GetDedupMode(), db.GetPinLimit())
+	}
+}
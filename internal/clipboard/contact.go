@@ -0,0 +1,335 @@
+package clipboard
+
+import (
+	"bytes"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Contact holds the fields of a vCard shown in the preview
+type Contact struct {
+	Name   string
+	Org    string
+	Phones []string
+	Emails []string
+}
+
+// Event holds the fields of an iCalendar event shown in the preview
+type Event struct {
+	Summary  string
+	Location string
+	Start    time.Time
+	End      time.Time
+	AllDay   bool
+}
+
+// contentLine is one unfolded "NAME;PARAM=VALUE:value" line
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseVCard extracts the first contact from vCard text (2.1, 3.0 and 4.0)
+// Returns false when the text has no contact with a name, phone or email
+func ParseVCard(text string) (Contact, bool) {
+	var c Contact
+	var structuredName string
+	inCard := false
+
+	for _, line := range parseContentLines(text) {
+		switch line.name {
+		case "BEGIN":
+			inCard = strings.EqualFold(line.value, "VCARD")
+		case "END":
+			if inCard && strings.EqualFold(line.value, "VCARD") {
+				inCard = false
+				if c.Name == "" {
+					c.Name = structuredName
+				}
+				return c, c.Name != "" || len(c.Phones) > 0 || len(c.Emails) > 0
+			}
+		case "FN":
+			if inCard {
+				c.Name = line.value
+			}
+		case "N":
+			if inCard {
+				// Family;Given;Additional;Prefix;Suffix
+				parts := splitComponents(line.value)
+				names := make([]string, 0, 2)
+				if len(parts) > 1 && parts[1] != "" {
+					names = append(names, parts[1])
+				}
+				if parts[0] != "" {
+					names = append(names, parts[0])
+				}
+				structuredName = strings.Join(names, " ")
+			}
+		case "ORG":
+			if inCard {
+				c.Org = strings.Join(nonEmpty(splitComponents(line.value)), ", ")
+			}
+		case "TEL":
+			if inCard && line.value != "" {
+				c.Phones = append(c.Phones, strings.TrimPrefix(line.value, "tel:"))
+			}
+		case "EMAIL":
+			if inCard && line.value != "" {
+				c.Emails = append(c.Emails, line.value)
+			}
+		}
+	}
+	return Contact{}, false
+}
+
+// ParseICalendar extracts the first event from iCalendar text
+// Returns false when the text has no VEVENT with a summary or start time
+func ParseICalendar(text string) (Event, bool) {
+	var e Event
+	inEvent := false
+
+	for _, line := range parseContentLines(text) {
+		switch line.name {
+		case "BEGIN":
+			inEvent = strings.EqualFold(line.value, "VEVENT")
+		case "END":
+			if inEvent && strings.EqualFold(line.value, "VEVENT") {
+				return e, e.Summary != "" || !e.Start.IsZero()
+			}
+		case "SUMMARY":
+			if inEvent {
+				e.Summary = line.value
+			}
+		case "LOCATION":
+			if inEvent {
+				e.Location = line.value
+			}
+		case "DTSTART":
+			if inEvent {
+				e.Start, e.AllDay = parseICalTime(line)
+			}
+		case "DTEND":
+			if inEvent {
+				e.End, _ = parseICalTime(line)
+			}
+		}
+	}
+	return Event{}, false
+}
+
+// parseICalTime parses DATE and DATE-TIME values, honoring TZID when the zone is known
+func parseICalTime(line contentLine) (time.Time, bool) {
+	value := strings.TrimSpace(line.value)
+	if strings.EqualFold(line.params["VALUE"], "DATE") || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t.Local(), false
+	}
+
+	loc := time.Local
+	if tzid := line.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.Local(), false
+}
+
+// parseContentLines unfolds and splits vCard/iCalendar content lines
+// Values are unescaped and decoded from quoted-printable and legacy charsets
+func parseContentLines(text string) []contentLine {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	// Unfold: a line starting with a space or tab continues the previous one
+	raw := make([]string, 0)
+	for _, l := range strings.Split(text, "\n") {
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(raw) > 0 {
+			raw[len(raw)-1] += l[1:]
+			continue
+		}
+		// vCard 2.1 quoted-printable soft line breaks end with "="
+		if len(raw) > 0 && strings.HasSuffix(raw[len(raw)-1], "=") && isQuotedPrintable(raw[len(raw)-1]) {
+			raw[len(raw)-1] = strings.TrimSuffix(raw[len(raw)-1], "=") + l
+			continue
+		}
+		raw = append(raw, l)
+	}
+
+	lines := make([]contentLine, 0, len(raw))
+	for _, l := range raw {
+		colon := indexUnquoted(l, ':')
+		if colon < 0 {
+			continue
+		}
+		head, value := l[:colon], l[colon+1:]
+
+		parts := strings.Split(head, ";")
+		name := strings.ToUpper(parts[0])
+		// Drop group prefixes such as "item1.EMAIL"
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			name = name[dot+1:]
+		}
+
+		// Parameter names are upper-cased; values keep their case (TZID is case sensitive)
+		params := make(map[string]string)
+		for _, p := range parts[1:] {
+			key, val, ok := strings.Cut(p, "=")
+			if !ok {
+				// vCard 2.1 bare parameters like "TEL;CELL" or "EMAIL;QUOTED-PRINTABLE"
+				if strings.EqualFold(p, "QUOTED-PRINTABLE") {
+					params["ENCODING"] = p
+				}
+				continue
+			}
+			params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+
+		lines = append(lines, contentLine{name: name, params: params, value: decodeValue(value, params)})
+	}
+	return lines
+}
+
+// decodeValue applies ENCODING and CHARSET parameters and unescapes the value
+func decodeValue(value string, params map[string]string) string {
+	data := []byte(value)
+	if strings.EqualFold(params["ENCODING"], "QUOTED-PRINTABLE") {
+		if decoded, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(data))); err == nil {
+			data = decoded
+		}
+	}
+	return unescapeValue(decodeCharset(data, params["CHARSET"]))
+}
+
+// isQuotedPrintable reports whether a raw line declares quoted-printable encoding
+func isQuotedPrintable(line string) bool {
+	colon := indexUnquoted(line, ':')
+	if colon < 0 {
+		return false
+	}
+	return strings.Contains(strings.ToUpper(line[:colon]), "QUOTED-PRINTABLE")
+}
+
+// indexUnquoted finds sep outside double-quoted parameter values
+func indexUnquoted(s string, sep byte) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// unescapeValue resolves \n, \, \; and \\ escapes
+func unescapeValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return strings.TrimSpace(s)
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				sb.WriteByte('\n')
+			default:
+				sb.WriteByte(s[i])
+			}
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// splitComponents splits a structured value (N, ORG) on semicolons
+// Values are unescaped first, so an escaped "\;" inside a component also splits; rare enough for a preview
+func splitComponents(s string) []string {
+	parts := strings.Split(s, ";")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// nonEmpty drops empty strings
+func nonEmpty(parts []string) []string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Single-byte charsets seen in vCards exported by older Windows software
+var (
+	// windows-1252 differs from ISO-8859-1 in 0x80-0x9F
+	cp1252High = [32]rune{
+		'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+		0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+	}
+	// ISO-8859-9 / windows-1254 replace six Icelandic letters with Turkish ones
+	turkishLetters = map[byte]rune{
+		0xD0: 'Ğ', 0xDD: 'İ', 0xDE: 'Ş', 0xF0: 'ğ', 0xFD: 'ı', 0xFE: 'ş',
+	}
+)
+
+// decodeCharset converts a legacy single-byte charset to UTF-8
+// Unknown charsets are returned unchanged when they are already valid UTF-8
+func decodeCharset(data []byte, charset string) string {
+	switch strings.ToUpper(charset) {
+	case "", "UTF-8", "US-ASCII":
+		return string(data)
+	case "ISO-8859-1", "LATIN1", "WINDOWS-1252", "CP1252", "ISO-8859-9", "LATIN5", "WINDOWS-1254", "CP1254":
+	default:
+		if utf8.Valid(data) {
+			return string(data)
+		}
+	}
+
+	charset = strings.ToUpper(charset)
+	windows := strings.HasPrefix(charset, "WINDOWS") || strings.HasPrefix(charset, "CP")
+	turkish := strings.HasSuffix(charset, "-9") || strings.HasSuffix(charset, "1254") || charset == "LATIN5"
+
+	var sb strings.Builder
+	for _, b := range data {
+		switch {
+		case b < 0x80:
+			sb.WriteByte(b)
+		case windows && b < 0xA0:
+			sb.WriteRune(cp1252High[b-0x80])
+		case turkish && turkishLetters[b] != 0:
+			sb.WriteRune(turkishLetters[b])
+		default:
+			sb.WriteRune(rune(b))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,187 @@
+package clipboard
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math/bits"
+)
+
+// DIB parsing is plain Go so it builds everywhere; only reading the clipboard is Windows-only
+
+const (
+	dibInfoHeaderSize = 40  // BITMAPINFOHEADER
+	dibV5HeaderSize   = 124 // BITMAPV5HEADER
+
+	biRGB       = 0 // Uncompressed, fixed channel layout
+	biBitfields = 3 // Uncompressed, channel layout given by masks
+)
+
+// BITMAPINFOHEADER structure for DIB format
+type bitmapInfoHeader struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	ImageSize     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+}
+
+// dibMasks are the channel bit masks of a 32-bit DIB
+type dibMasks struct {
+	red, green, blue, alpha uint32
+}
+
+// defaultDIBMasks is the BGRA layout of BI_RGB; its alpha byte is only a hint, see parseDIB
+var defaultDIBMasks = dibMasks{red: 0x00FF0000, green: 0x0000FF00, blue: 0x000000FF, alpha: 0xFF000000}
+
+// parseDIB converts a packed DIB (CF_DIB or CF_DIBV5: header, masks, color table, pixels)
+// into an image
+//
+// Alpha follows what producers actually write rather than the format's promises:
+//   - a 32-bit DIB whose alpha bytes are all 0 is opaque; many apps leave the byte unset
+//   - colors brighter than their alpha can't be premultiplied, so such an image is
+//     straight alpha (NRGBA); otherwise it is taken as premultiplied (RGBA), which is
+//     what Windows itself produces for CF_DIBV5
+func parseDIB(data []byte) (image.Image, error) {
+	if len(data) < dibInfoHeaderSize {
+		return nil, fmt.Errorf("clipboard data too short")
+	}
+	header := bitmapInfoHeader{
+		Size:        binary.LittleEndian.Uint32(data[0:]),
+		Width:       int32(binary.LittleEndian.Uint32(data[4:])),
+		Height:      int32(binary.LittleEndian.Uint32(data[8:])),
+		Planes:      binary.LittleEndian.Uint16(data[12:]),
+		BitCount:    binary.LittleEndian.Uint16(data[14:]),
+		Compression: binary.LittleEndian.Uint32(data[16:]),
+		ImageSize:   binary.LittleEndian.Uint32(data[20:]),
+		ClrUsed:     binary.LittleEndian.Uint32(data[32:]),
+	}
+	if header.Size < dibInfoHeaderSize || int(header.Size) > len(data) {
+		return nil, fmt.Errorf("invalid bitmap header size: %d", header.Size)
+	}
+
+	width := int(header.Width)
+	height := int(header.Height)
+	topDown := height < 0
+	if topDown {
+		height = -height
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid image dimensions: %dx%d", width, height)
+	}
+
+	// Masks live in V4/V5 headers, or right after a plain header with BI_BITFIELDS
+	masks := defaultDIBMasks
+	offset := int(header.Size)
+	switch header.Compression {
+	case biRGB:
+	case biBitfields:
+		// Headers between the plain and the V2 size have no room for the masks either
+		if len(data) < dibInfoHeaderSize+12 {
+			return nil, fmt.Errorf("clipboard data too short")
+		}
+		maskData := data[dibInfoHeaderSize:]
+		if header.Size == dibInfoHeaderSize {
+			offset += 12
+		}
+		masks.red = binary.LittleEndian.Uint32(maskData[0:])
+		masks.green = binary.LittleEndian.Uint32(maskData[4:])
+		masks.blue = binary.LittleEndian.Uint32(maskData[8:])
+		// Only V4 and V5 headers carry an alpha mask
+		masks.alpha = 0
+		if header.Size >= dibInfoHeaderSize+16 {
+			masks.alpha = binary.LittleEndian.Uint32(maskData[12:])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported bitmap compression: %d", header.Compression)
+	}
+	offset += int(header.ClrUsed) * 4 // Color table
+
+	var bytesPerPixel int
+	switch header.BitCount {
+	case 32:
+		bytesPerPixel = 4
+	case 24:
+		if header.Compression != biRGB {
+			return nil, fmt.Errorf("unsupported 24-bit bitmap compression: %d", header.Compression)
+		}
+		bytesPerPixel = 3
+	default:
+		return nil, fmt.Errorf("unsupported bit depth: %d", header.BitCount)
+	}
+
+	// Rows are aligned to 4 bytes
+	rowSize := ((width*int(header.BitCount) + 31) / 32) * 4
+	if offset > len(data) || len(data)-offset < rowSize*height {
+		return nil, fmt.Errorf("insufficient data for image")
+	}
+	pixelData := data[offset:]
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	hasAlpha := false
+	premultiplied := true
+	for y := 0; y < height; y++ {
+		srcY := y
+		if !topDown {
+			srcY = height - 1 - y // Bottom-up rows
+		}
+		row := pixelData[srcY*rowSize:]
+		dst := img.Pix[y*img.Stride:]
+		for x := 0; x < width; x++ {
+			var r, g, b, a uint8
+			if bytesPerPixel == 4 {
+				px := binary.LittleEndian.Uint32(row[x*4:])
+				r, g, b = maskChannel(px, masks.red), maskChannel(px, masks.green), maskChannel(px, masks.blue)
+				a = 255
+				if masks.alpha != 0 {
+					a = maskChannel(px, masks.alpha)
+				}
+			} else {
+				b, g, r, a = row[x*3], row[x*3+1], row[x*3+2], 255
+			}
+			if a != 0 {
+				hasAlpha = true
+			}
+			if r > a || g > a || b > a {
+				premultiplied = false
+			}
+			dst[x*4], dst[x*4+1], dst[x*4+2], dst[x*4+3] = r, g, b, a
+		}
+	}
+
+	if !hasAlpha {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 255
+		}
+		return img, nil
+	}
+	if premultiplied {
+		// Same bytes, read as premultiplied
+		return &image.RGBA{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect}, nil
+	}
+	return img, nil
+}
+
+// maskChannel extracts a channel selected by mask and scales it to 8 bits
+func maskChannel(px, mask uint32) uint8 {
+	if mask == 0 {
+		return 0
+	}
+	shift := bits.TrailingZeros32(mask)
+	width := bits.OnesCount32(mask)
+	v := (px & mask) >> shift
+	switch {
+	case width == 8:
+		return uint8(v)
+	case width > 8:
+		return uint8(v >> (width - 8))
+	default:
+		return uint8(v * 255 / (1<<width - 1))
+	}
+}
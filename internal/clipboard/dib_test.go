@@ -0,0 +1,200 @@
+package clipboard
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// dibSpec describes a packed DIB for buildDIB
+type dibSpec struct {
+	headerSize  int // 40, or 124 for a V5 header with masks inside
+	width       int
+	height      int // Negative for top-down rows
+	bitCount    int
+	compression int
+	masks       []uint32 // Written into a V5 header, or after a 40-byte one
+	rows        [][]byte // Pixel rows as stored, without padding
+}
+
+// buildDIB packs spec as the clipboard hands out CF_DIB and CF_DIBV5
+func buildDIB(spec dibSpec) []byte {
+	data := make([]byte, spec.headerSize)
+	le := binary.LittleEndian
+	le.PutUint32(data[0:], uint32(spec.headerSize))
+	le.PutUint32(data[4:], uint32(int32(spec.width)))
+	le.PutUint32(data[8:], uint32(int32(spec.height)))
+	le.PutUint16(data[12:], 1)
+	le.PutUint16(data[14:], uint16(spec.bitCount))
+	le.PutUint32(data[16:], uint32(spec.compression))
+	for i, mask := range spec.masks {
+		if spec.headerSize > dibInfoHeaderSize {
+			le.PutUint32(data[dibInfoHeaderSize+4*i:], mask)
+		} else {
+			data = le.AppendUint32(data, mask)
+		}
+	}
+	for _, row := range spec.rows {
+		data = append(data, row...)
+		for len(row)%4 != 0 {
+			row = append(row, 0)
+			data = append(data, 0)
+		}
+	}
+	return data
+}
+
+// bgra returns 32-bit pixels in BI_RGB byte order
+func bgra(pixels ...color.NRGBA) []byte {
+	row := make([]byte, 0, 4*len(pixels))
+	for _, p := range pixels {
+		row = append(row, p.B, p.G, p.R, p.A)
+	}
+	return row
+}
+
+var (
+	red   = color.NRGBA{R: 255, A: 255}
+	blue  = color.NRGBA{B: 255, A: 255}
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// nrgbaAt returns a pixel of img as straight alpha
+func nrgbaAt(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestParseDIB(t *testing.T) {
+	v5Masks := []uint32{0x00FF0000, 0x0000FF00, 0x000000FF, 0xFF000000}
+	tests := []struct {
+		name  string
+		spec  dibSpec
+		model string          // "rgba" for premultiplied, "nrgba" for straight alpha, "" if opaque
+		want  [][]color.NRGBA // Rows from the top
+	}{
+		{
+			"24-bit bottom-up with padded rows",
+			dibSpec{headerSize: 40, width: 1, height: 2, bitCount: 24, rows: [][]byte{{0, 0, 255}, {255, 0, 0}}},
+			"",
+			[][]color.NRGBA{{blue}, {red}},
+		},
+		{
+			"32-bit with unset alpha is opaque",
+			dibSpec{headerSize: 40, width: 2, height: 1, bitCount: 32, rows: [][]byte{bgra(color.NRGBA{R: 255}, color.NRGBA{G: 128})}},
+			"",
+			[][]color.NRGBA{{red, {G: 128, A: 255}}},
+		},
+		{
+			"top-down rows",
+			dibSpec{headerSize: 40, width: 1, height: -2, bitCount: 32, rows: [][]byte{bgra(red), bgra(blue)}},
+			"",
+			[][]color.NRGBA{{red}, {blue}},
+		},
+		{
+			"V5 premultiplied alpha",
+			dibSpec{headerSize: 124, width: 2, height: 1, bitCount: 32, compression: biBitfields, masks: v5Masks,
+				rows: [][]byte{bgra(color.NRGBA{R: 128, A: 128}, color.NRGBA{})}},
+			"rgba",
+			[][]color.NRGBA{{{R: 255, A: 128}, {}}},
+		},
+		{
+			"straight alpha, colors brighter than alpha",
+			dibSpec{headerSize: 124, width: 2, height: 1, bitCount: 32, compression: biBitfields, masks: v5Masks,
+				rows: [][]byte{bgra(color.NRGBA{R: 255, A: 128}, white)}},
+			"nrgba",
+			[][]color.NRGBA{{{R: 255, A: 128}, white}},
+		},
+		{
+			"bitfields after a plain header",
+			dibSpec{headerSize: 40, width: 1, height: 1, bitCount: 32, compression: biBitfields,
+				masks: []uint32{0x000000FF, 0x0000FF00, 0x00FF0000}, rows: [][]byte{{255, 0, 0, 0}}},
+			"",
+			[][]color.NRGBA{{red}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := parseDIB(buildDIB(tt.spec))
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			switch img.(type) {
+			case *image.RGBA:
+				if tt.model == "nrgba" {
+					t.Error("straight alpha taken as premultiplied")
+				}
+			case *image.NRGBA:
+				if tt.model == "rgba" {
+					t.Error("premultiplied alpha taken as straight")
+				}
+			default:
+				t.Errorf("parsed as %T", img)
+			}
+			if b := img.Bounds(); b.Dx() != len(tt.want[0]) || b.Dy() != len(tt.want) {
+				t.Fatalf("image is %v", b)
+			}
+			for y, row := range tt.want {
+				for x, want := range row {
+					if got := nrgbaAt(img, x, y); got != want {
+						t.Errorf("pixel %d,%d is %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// Damaged or unsupported DIBs fail without reading past the data
+func TestParseDIBErrors(t *testing.T) {
+	good := buildDIB(dibSpec{headerSize: 40, width: 2, height: 2, bitCount: 32, rows: [][]byte{bgra(red, red), bgra(red, red)}})
+	withHeader := func(offset int, value uint32) []byte {
+		data := append([]byte(nil), good...)
+		binary.LittleEndian.PutUint32(data[offset:], value)
+		return data
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", good[:39]},
+		{"header size too small", withHeader(0, 12)},
+		{"header size past the data", withHeader(0, 4096)},
+		{"zero width", withHeader(4, 0)},
+		{"negative width", withHeader(4, 0xFFFFFFFE)},
+		{"RLE compressed", withHeader(16, 1)},
+		{"16-bit", withHeader(14, 16)},
+		{"24-bit bitfields", withHeader(14, 24|biBitfields<<16)},
+		{"missing pixels", good[:len(good)-1]},
+		{"color table past the data", withHeader(32, 1<<20)},
+		{"bitfields without masks", withHeader(16, biBitfields)[:40]},
+		{"bitfields in a header too small for masks", buildDIB(dibSpec{headerSize: 44, width: 1, height: 1, bitCount: 32, compression: biBitfields})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if img, err := parseDIB(tt.data); err == nil {
+				t.Errorf("parsed into %v", img.Bounds())
+			}
+		})
+	}
+}
+
+func TestMaskChannel(t *testing.T) {
+	tests := []struct {
+		px, mask uint32
+		want     uint8
+	}{
+		{0x00AB0000, 0x00FF0000, 0xAB},
+		{0x1F << 11, 0x1F << 11, 255},   // 5 bits, full
+		{0x10 << 11, 0x1F << 11, 131},   // 5 bits, about half
+		{0x3FF << 20, 0x3FF << 20, 255}, // 10 bits keep their top 8
+		{0x200 << 20, 0x3FF << 20, 128},
+		{0xFFFFFFFF, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := maskChannel(tt.px, tt.mask); got != tt.want {
+			t.Errorf("maskChannel(%#x, %#x) = %d, want %d", tt.px, tt.mask, got, tt.want)
+		}
+	}
+}
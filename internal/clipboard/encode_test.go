@@ -0,0 +1,87 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestEncodeImage encodes the stored PNG byte for byte, with and without the data URI
+// prefix, and refuses text items and images whose text would be too long
+func TestEncodeImage(t *testing.T) {
+	r := &fakeReader{}
+	m, db := newTestMonitor(t, r)
+	manager := NewManager(db)
+
+	r.set("", testImage(t), false)
+	m.checkClipboard()
+	r.set("metin", nil, false)
+	m.checkClipboard()
+	items := db.GetAllItems() // metin, image
+	if len(items) != 2 || items[1].Type != "image" {
+		t.Fatalf("history is %+v", items)
+	}
+	_, stored, err := db.GetItem(items[1].ID)
+	if err != nil {
+		t.Fatalf("failed to read image: %v", err)
+	}
+
+	encoded, err := manager.EncodeImage(items[1].ID, false)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(encoded); err != nil || !bytes.Equal(decoded, stored) {
+		t.Errorf("base64 doesn't decode to the stored PNG: %v", err)
+	}
+	uri, err := manager.EncodeImage(items[1].ID, true)
+	if err != nil {
+		t.Fatalf("failed to encode as data URI: %v", err)
+	}
+	if uri != "data:image/png;base64,"+encoded {
+		t.Errorf("data URI is %q", uri)
+	}
+
+	if _, err := manager.EncodeImage(items[0].ID, false); err == nil {
+		t.Error("a text item was encoded as an image")
+	}
+	if _, err := manager.EncodeImage("yok", false); err == nil {
+		t.Error("a missing item was encoded")
+	}
+
+	// Base64 is a third longer than the bytes it encodes
+	if err := db.AddItem("image", bytes.Repeat([]byte{0x89}, MaxEncodedImageSize)); err != nil {
+		t.Fatalf("failed to add big image: %v", err)
+	}
+	if _, err := manager.EncodeImage(db.GetAllItems()[0].ID, false); !errors.Is(err, ErrEncodedTooLarge) {
+		t.Errorf("encoding an image over the cap gave %v", err)
+	}
+}
+
+// TestIgnoreNext checks that text Pano writes itself isn't captured, while the next
+// copy by someone else is
+func TestIgnoreNext(t *testing.T) {
+	r := &fakeReader{}
+	m, db := newTestMonitor(t, r)
+	r.set("önce", nil, false)
+	m.checkClipboard()
+
+	encoded := "data:image/png;base64," + strings.Repeat("QUJD", 8)
+	m.IgnoreNext("text", []byte(encoded))
+	r.set(encoded, nil, false)
+	m.checkClipboard()
+	expectTexts(t, db, "önce")
+
+	r.set("sonra", nil, false)
+	m.checkClipboard()
+	expectTexts(t, db, "sonra", "önce")
+
+	// Images can't be fingerprinted before the write, so they aren't skipped here
+	m.IgnoreNext("image", testImage(t))
+	r.set("", testImage(t), false)
+	m.checkClipboard()
+	if got := len(db.GetAllItems()); got != 3 {
+		t.Errorf("history has %d items after an image copy, want 3", got)
+	}
+}
@@ -0,0 +1,44 @@
+package clipboard
+
+import (
+	"strings"
+
+	"pano/internal/storage"
+)
+
+// DefaultExcludedApps are the programs whose copies are never captured: password managers
+var DefaultExcludedApps = []string{"keepass.exe", "bitwarden.exe", "1password.exe"}
+
+// SetExcludedApps sets the executables (e.g. "keepass.exe") whose copies the poll loop
+// never stores; names are matched without case on their base name
+func (m *Monitor) SetExcludedApps(names []string) {
+	excluded := excludedAppSet(names)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.excludedApps = excluded
+}
+
+// excludedAppSet returns the lowercased base names of names
+func excludedAppSet(names []string) map[string]bool {
+	excluded := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			excluded[strings.ToLower(storage.FileName(name))] = true
+		}
+	}
+	return excluded
+}
+
+// ownerExcluded reports whether the program that set the clipboard is excluded
+// An unknown owner is never excluded
+func (m *Monitor) ownerExcluded() bool {
+	m.mu.Lock()
+	excluded := m.excludedApps
+	m.mu.Unlock()
+	if len(excluded) == 0 {
+		return false
+	}
+	owner := m.reader.Owner()
+	return owner != "" && excluded[strings.ToLower(storage.FileName(owner))]
+}
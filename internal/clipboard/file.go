@@ -0,0 +1,72 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"io"
+	"net/http"
+	"os"
+	"unicode/utf8"
+
+	"pano/internal/storage"
+)
+
+// MaxFileTextSize caps text files added from Explorer; larger files are rarely meant
+// to be pasted and would make the list slow
+const MaxFileTextSize = 1024 * 1024
+
+// ErrUnsupportedFile is returned by ReadFileItem for files that are neither text nor an image
+var ErrUnsupportedFile = errors.New("unsupported file type")
+
+// ReadFileItem reads a file as an item: UTF-8 text files as text, PNG, JPEG and GIF
+// images as PNG-encoded images
+func ReadFileItem(path string) (string, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if info.IsDir() {
+		return "", nil, fmt.Errorf("%s is a folder: %w", info.Name(), ErrUnsupportedFile)
+	}
+	if info.Size() > storage.MaxItemSize {
+		return "", nil, &storage.RejectError{Reason: storage.RejectTooLarge, Size: int(info.Size()), Limit: storage.MaxItemSize}
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	switch http.DetectContentType(data) {
+	case "image/png", "image/jpeg", "image/gif":
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to decode image: %w", err)
+		}
+		encoded, err := encodeCapturePNG(img)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to encode image: %w", err)
+		}
+		return "image", encoded, nil
+	}
+
+	// Anything valid UTF-8 without NUL bytes counts as text, whatever its extension
+	if utf8.Valid(data) && !bytes.ContainsRune(data, 0) {
+		if len(data) > MaxFileTextSize {
+			return "", nil, &storage.RejectError{Reason: storage.RejectTooLarge, Size: len(data), Limit: MaxFileTextSize}
+		}
+		// Editors on Windows often save a BOM, which shouldn't end up in pasted text
+		return "text", bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), nil
+	}
+	return "", nil, fmt.Errorf("%s: %w", info.Name(), ErrUnsupportedFile)
+}
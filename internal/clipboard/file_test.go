@@ -0,0 +1,102 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pano/internal/storage"
+)
+
+// writeFile writes data to name in a temp directory and returns its path
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// TestReadFileItem reads files by their content rather than their extension: text as
+// text without a BOM, the three image formats as PNG, anything else refused
+func TestReadFileItem(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(2, 1, color.NRGBA{R: 255, A: 255})
+	encoded := make(map[string][]byte)
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	encoded["png"] = bytes.Clone(buf.Bytes())
+	buf.Reset()
+	jpeg.Encode(&buf, img, nil)
+	encoded["jpeg"] = bytes.Clone(buf.Bytes())
+	buf.Reset()
+	gif.Encode(&buf, img, nil)
+	encoded["gif"] = bytes.Clone(buf.Bytes())
+
+	for format, data := range encoded {
+		t.Run(format, func(t *testing.T) {
+			itemType, content, err := ReadFileItem(writeFile(t, "resim.dat", data))
+			if err != nil || itemType != "image" {
+				t.Fatalf("read as %q, %v", itemType, err)
+			}
+			decoded, err := png.Decode(bytes.NewReader(content))
+			if err != nil {
+				t.Fatalf("content isn't a PNG: %v", err)
+			}
+			if decoded.Bounds() != img.Bounds() {
+				t.Errorf("image is %v, want %v", decoded.Bounds(), img.Bounds())
+			}
+		})
+	}
+
+	itemType, content, err := ReadFileItem(writeFile(t, "not.png", []byte("\xef\xbb\xbfİlk satır\nikinci")))
+	if err != nil || itemType != "text" || string(content) != "İlk satır\nikinci" {
+		t.Errorf("text file read as %q %q, %v", itemType, content, err)
+	}
+
+	var reject *storage.RejectError
+	_, _, err = ReadFileItem(writeFile(t, "uzun.txt", bytes.Repeat([]byte("a"), MaxFileTextSize+1)))
+	if !errors.As(err, &reject) || reject.Reason != storage.RejectTooLarge || reject.Limit != MaxFileTextSize {
+		t.Errorf("an overlong text file gave %v", err)
+	}
+	if _, _, err := ReadFileItem(writeFile(t, "veri.txt", []byte("metin\x00ikili"))); !errors.Is(err, ErrUnsupportedFile) {
+		t.Errorf("a binary file gave %v", err)
+	}
+	if _, _, err := ReadFileItem(writeFile(t, "bozuk.png", append(encoded["png"][:20:20], 0xff))); err == nil {
+		t.Error("a damaged PNG was read")
+	}
+	if _, _, err := ReadFileItem(t.TempDir()); !errors.Is(err, ErrUnsupportedFile) {
+		t.Errorf("a folder gave %v", err)
+	}
+	if _, _, err := ReadFileItem(filepath.Join(t.TempDir(), "yok.txt")); err == nil {
+		t.Error("a missing file was read")
+	}
+}
+
+// Files are added like a manual capture: forced, so a pause doesn't drop them
+func TestAddFile(t *testing.T) {
+	r := &fakeReader{}
+	m, db := newTestMonitor(t, r)
+	m.SetPaused(true)
+	manager := NewManager(db)
+
+	itemType, err := manager.AddFile(writeFile(t, "not.txt", []byte("dosyadan")))
+	if err != nil || itemType != "text" {
+		t.Fatalf("added as %q, %v", itemType, err)
+	}
+	expectTexts(t, db, "dosyadan")
+	if !db.GetAllItems()[0].Forced {
+		t.Error("the file wasn't stored as a forced capture")
+	}
+	if _, err := manager.AddFile(filepath.Join(t.TempDir(), "yok.txt")); err == nil {
+		t.Error("a missing file was added")
+	}
+}
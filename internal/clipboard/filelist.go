@@ -0,0 +1,32 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// MissingFilesError is returned when a files item is copied but some of its files no
+// longer exist where they were copied from
+type MissingFilesError struct {
+	Paths []string // The files that are gone
+	Total int      // Number of files of the item
+}
+
+// Error lists the missing files
+func (e *MissingFilesError) Error() string {
+	return fmt.Sprintf("%d of %d files no longer exist: %v", len(e.Paths), e.Total, e.Paths)
+}
+
+// missingFiles returns the paths that no longer exist
+// Files that can't be checked for another reason are left for the paste to report
+func missingFiles(paths []string) []string {
+	var missing []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,124 @@
+package clipboard
+
+import "time"
+
+// Another clipboard manager running next to Pano (or Windows' cloud clipboard sync) may
+// answer every clipboard change by setting the clipboard again, and the two programs
+// then keep re-setting the same content: each round has a new sequence number though
+// nobody touched a key. loopDetector spots that pattern, the same content appearing
+// with a new sequence number more than loopThreshold times within loopWindow without
+// keyboard or mouse input since its first appearance. The monitor then skips that
+// content for loopSuppress and has the database merge duplicates over loopDedupWindow,
+// so the bouncing copies don't fill the history.
+
+const (
+	loopThreshold   = 3                // Appearances tolerated before it counts as a loop
+	loopWindow      = 10 * time.Second // Appearances older than this start counting again
+	loopSuppress    = 30 * time.Second // How long captures of looping content are skipped
+	loopDedupWindow = 10 * time.Minute // Duplicates merged over this while suppressing
+)
+
+// loopVerdict is what the monitor does with an appearance
+type loopVerdict int
+
+const (
+	loopNone       loopVerdict = iota // Capture as usual
+	loopDetected                      // This appearance completed a loop; suppression starts
+	loopSuppressed                    // The content is being suppressed; don't capture
+)
+
+// loopSighting counts the appearances of one content since the user last did something
+type loopSighting struct {
+	first time.Time
+	count int
+}
+
+// loopDetector is fed every clipboard change the sequence number confirms
+// Not safe for concurrent use; the monitor only calls it holding checkMu
+type loopDetector struct {
+	sightings  map[string]loopSighting
+	suppressed map[string]time.Time // Content key to the end of its suppression
+}
+
+// newLoopDetector creates a detector that has seen nothing
+func newLoopDetector() *loopDetector {
+	return &loopDetector{
+		sightings:  make(map[string]loopSighting),
+		suppressed: make(map[string]time.Time),
+	}
+}
+
+// observe records content identified by key appearing at now
+// lastInput is the time of the last keyboard or mouse input, zero if unknown
+func (d *loopDetector) observe(key string, now, lastInput time.Time) loopVerdict {
+	d.prune(now)
+	if _, ok := d.suppressed[key]; ok {
+		return loopSuppressed
+	}
+
+	s, ok := d.sightings[key]
+	if !ok || lastInput.After(s.first) {
+		// Copying it again by hand isn't a loop: count from here
+		s = loopSighting{first: now}
+	}
+	s.count++
+	if s.count > loopThreshold {
+		delete(d.sightings, key)
+		d.suppressed[key] = now.Add(loopSuppress)
+		return loopDetected
+	}
+	d.sightings[key] = s
+	return loopNone
+}
+
+// prune forgets sightings older than loopWindow and ended suppressions
+func (d *loopDetector) prune(now time.Time) {
+	for key, s := range d.sightings {
+		if now.Sub(s.first) > loopWindow {
+			delete(d.sightings, key)
+		}
+	}
+	for key, until := range d.suppressed {
+		if !now.Before(until) {
+			delete(d.suppressed, key)
+		}
+	}
+}
+
+// observeLoop feeds a confirmed clipboard change to the loop detector and starts the
+// mitigation when it completes a loop (caller holds checkMu)
+func (m *Monitor) observeLoop(itemType string, hash Fingerprint) loopVerdict {
+	m.mu.Lock()
+	lastInput := m.lastInput
+	callback := m.onLoop
+	m.mu.Unlock()
+
+	var input time.Time
+	if lastInput != nil {
+		input = lastInput()
+	}
+	now := m.now()
+	verdict := m.loops.observe(itemType+":"+string(hash[:]), now, input)
+	if verdict == loopDetected {
+		m.db.RaiseDedupWindow(loopDedupWindow, now.Add(loopSuppress))
+		if callback != nil {
+			m.goCallback(func() { callback(itemType) })
+		}
+	}
+	return verdict
+}
+
+// SetLastInput sets where the monitor learns when the user last pressed a key or
+// clicked; without it every repeat counts towards a loop
+func (m *Monitor) SetLastInput(lastInput func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastInput = lastInput
+}
+
+// SetOnLoop sets the callback for a detected clipboard loop, called with the content type
+func (m *Monitor) SetOnLoop(callback func(itemType string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onLoop = callback
+}
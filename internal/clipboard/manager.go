@@ -2,13 +2,17 @@ package clipboard
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"image"
 	"image/png"
+	"io"
+	"sync"
+	"time"
 
 	"pano/internal/storage"
-
-	"github.com/atotto/clipboard"
 )
 
 // decodePNGImage decodes PNG bytes to image.Image
@@ -18,26 +22,140 @@ func decodePNGImage(data []byte) (image.Image, error) {
 
 // Manager handles clipboard operations
 type Manager struct {
-	db *storage.Database
+	db          *storage.Database
+	writer      Writer // Clipboard writes, see reader.go
+	mu          sync.RWMutex
+	newlineMode NewlineMode // Default line ending conversion for copied text
+	permissions Permissions // What the defaults file allows, see permissions.go
+
+	stackMu sync.Mutex
+	stack   pasteStack // Items queued for sequential pasting, see pastestack.go
+
+	protect protectLimiter // Wrong passphrases of protected items, see protect.go
 }
 
 // NewManager creates a new clipboard manager
-func NewManager(db *storage.Database) *Manager {
-	return &Manager{
-		db: db,
+func NewManager(db *storage.Database, opts ...ManagerOption) *Manager {
+	m := &Manager{
+		db:     db,
+		writer: platformWriter{},
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
 // CopyToClipboard copies an item to the system clipboard
+// Protected items return storage.ErrProtected, see CopyProtected
 func (m *Manager) CopyToClipboard(id string) error {
-	item, content, err := m.db.GetItem(id)
+	item, content, err := m.readableItem(id)
+	if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+	defer storage.Zero(content)
+
+	return m.writeItem(item, content, m.GetNewlineMode())
+}
+
+// CopyWithNewlines copies an item converting line endings with mode, ignoring the default
+// The stored content is not modified
+func (m *Manager) CopyWithNewlines(id string, mode NewlineMode) error {
+	item, content, err := m.readableItem(id)
 	if err != nil {
 		return fmt.Errorf("failed to get item: %w", err)
 	}
+	defer storage.Zero(content)
+
+	return m.writeItem(item, content, mode)
+}
+
+// writeItem writes an item's content to the clipboard and counts the use
+// A use that can't be counted doesn't fail the copy, and formatting that can't be read
+// only leaves the text without it
+func (m *Manager) writeItem(item *storage.ClipboardItem, content []byte, mode NewlineMode) error {
+	var rtf []byte
+	if item.RTF != "" {
+		rtf, _ = m.db.GetItemRTF(item.ID)
+		defer storage.Zero(rtf)
+	}
+	if err := m.writeContent(item.Type, content, rtf, mode); err != nil {
+		return err
+	}
+	_ = m.db.MarkUsed(item.ID)
+	return nil
+}
+
+// CopyText writes arbitrary text (e.g. a field of a contact preview) to the system clipboard
+func (m *Manager) CopyText(text string) error {
+	if err := m.writer.WriteText(text); err != nil {
+		return fmt.Errorf("failed to write to clipboard: %w", err)
+	}
+	return nil
+}
 
-	switch item.Type {
+// MaxEncodedImageSize caps the text produced by EncodeImage
+const MaxEncodedImageSize = 2 << 20
+
+// ErrEncodedTooLarge is returned by EncodeImage when the text would exceed MaxEncodedImageSize
+var ErrEncodedTooLarge = errors.New("encoded image is too large")
+
+// EncodeImage returns the stored PNG of an image item as base64, prefixed with
+// "data:image/png;base64," when dataURI is set
+// The stored bytes are encoded as they are; the image is never decoded or re-encoded
+func (m *Manager) EncodeImage(id string, dataURI bool) (string, error) {
+	item, content, err := m.readableItem(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get item: %w", err)
+	}
+	defer storage.Zero(content)
+	if item.Type != "image" {
+		return "", fmt.Errorf("item is not an image: %s", item.Type)
+	}
+
+	prefix := ""
+	if dataURI {
+		prefix = "data:image/png;base64,"
+	}
+	if len(prefix)+base64.StdEncoding.EncodedLen(len(content)) > MaxEncodedImageSize {
+		return "", ErrEncodedTooLarge
+	}
+	return prefix + base64.StdEncoding.EncodeToString(content), nil
+}
+
+// SetNewlineMode sets the default line ending conversion for copied text
+func (m *Manager) SetNewlineMode(mode NewlineMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.newlineMode = mode
+}
+
+// GetNewlineMode returns the default line ending conversion for copied text
+func (m *Manager) GetNewlineMode() NewlineMode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.newlineMode
+}
+
+// writeContent writes decrypted item content to the clipboard
+// Text line endings are converted according to mode, and rtf (nil for none) is written
+// along with the text; the write is recorded so the monitor doesn't capture it again
+func (m *Manager) writeContent(itemType string, content, rtf []byte, mode NewlineMode) error {
+	switch itemType {
 	case "text":
-		if err := clipboard.WriteAll(string(content)); err != nil {
+		text := NormalizeNewlines(content, mode)
+		if mode != NewlineAsIs {
+			defer storage.Zero(text)
+		}
+		ownWrites.record("text", text)
+		var err error
+		if len(rtf) > 0 {
+			err = m.writer.WriteRichText(string(text), rtf)
+		} else {
+			err = m.writer.WriteText(string(text))
+		}
+		if err != nil {
+			ownWrites.take("text", text)
 			return fmt.Errorf("failed to write to clipboard: %w", err)
 		}
 	case "image":
@@ -46,50 +164,327 @@ func (m *Manager) CopyToClipboard(id string) error {
 		if err != nil {
 			return fmt.Errorf("failed to decode image: %w", err)
 		}
-		if err := WriteClipboardImage(img); err != nil {
+		ownWrites.record("image", nil)
+		if err := m.writer.WriteImage(img); err != nil {
+			ownWrites.take("image", nil)
 			return fmt.Errorf("failed to write image to clipboard: %w", err)
 		}
+	case "files":
+		paths, err := storage.DecodeFiles(content)
+		if err != nil {
+			return err
+		}
+		// Only the paths were stored; files moved or deleted since can't be pasted
+		if missing := missingFiles(paths); len(missing) > 0 {
+			return &MissingFilesError{Paths: missing, Total: len(paths)}
+		}
+		ownWrites.record("files", content)
+		if err := m.writer.WriteFiles(paths); err != nil {
+			ownWrites.take("files", content)
+			return fmt.Errorf("failed to write files to clipboard: %w", err)
+		}
 	default:
-		return fmt.Errorf("unknown item type: %s", item.Type)
+		return fmt.Errorf("unknown item type: %s", itemType)
 	}
 
 	return nil
 }
 
+// CopyOriginalToClipboard copies the pre-cleaning content of an item (e.g. the URL with tracking params)
+func (m *Manager) CopyOriginalToClipboard(id string) error {
+	if m.db.IsProtected(id) {
+		return storage.ErrProtected
+	}
+	content, err := m.db.GetItemOriginal(id)
+	if err != nil {
+		return fmt.Errorf("failed to get original content: %w", err)
+	}
+	defer storage.Zero(content)
+
+	return m.writeContent("text", content, nil, m.GetNewlineMode())
+}
+
 // PinItem toggles the pinned status of an item
 func (m *Manager) PinItem(id string) error {
 	return m.db.TogglePin(id)
 }
 
-// DeleteItem removes an item from the database
+// DeleteItem moves an item to the trash
 func (m *Manager) DeleteItem(id string) error {
 	return m.db.DeleteItem(id)
 }
 
+// DeleteItems moves several items to the trash at once and returns how many went
+func (m *Manager) DeleteItems(ids []string) (int, error) {
+	return m.db.DeleteItems(ids)
+}
+
+// RestoreItem takes a deleted item back out of the trash (undo)
+func (m *Manager) RestoreItem(id string) error {
+	return m.db.RestoreItem(id)
+}
+
+// PurgeDeleted permanently removes items deleted more than olderThan ago
+func (m *Manager) PurgeDeleted(olderThan time.Duration) (int, error) {
+	return m.db.PurgeDeleted(olderThan)
+}
+
+// AddFile stores a file as an item, see ReadFileItem
+// Like a manual capture, it bypasses pause and exclusions
+// Returns the stored item type; a *storage.LimitWarning still means the item was stored
+func (m *Manager) AddFile(path string) (string, error) {
+	itemType, content, err := ReadFileItem(path)
+	if err != nil {
+		return "", err
+	}
+	return itemType, m.db.AddCapturedItem(itemType, content, storage.CaptureInfo{Forced: true})
+}
+
+// AddText stores text typed into Pano rather than copied, optionally pinned
+// Like a manual capture, it bypasses pause and exclusions; a *storage.LimitWarning
+// still means the item was stored
+func (m *Manager) AddText(text string, pin bool) error {
+	return m.db.AddCapturedItem("text", []byte(text), storage.CaptureInfo{Forced: true, Pinned: pin})
+}
+
+// UpdateContent replaces the text of a text item and moves it to the top
+func (m *Manager) UpdateContent(id string, content []byte) error {
+	return m.db.UpdateContent(id, content)
+}
+
+// SaveDraft stores the unsaved text of the editor dialog key
+func (m *Manager) SaveDraft(key, text string) error {
+	return m.db.SaveDraft(key, text)
+}
+
+// LoadDrafts returns the saved editor drafts, newest first
+func (m *Manager) LoadDrafts() ([]storage.Draft, error) {
+	return m.db.LoadDrafts()
+}
+
+// DeleteDraft removes the draft of the editor dialog key
+func (m *Manager) DeleteDraft(key string) error {
+	return m.db.DeleteDraft(key)
+}
+
+// StartPasteStack queues items for sequential pasting and puts the first on the clipboard
+// Replaces any running queue
+func (m *Manager) StartPasteStack(ids []string) (PasteStackState, error) {
+	m.stackMu.Lock()
+	defer m.stackMu.Unlock()
+	m.stack.ids = append([]string(nil), ids...)
+	return m.copyStackFrom(0)
+}
+
+// AdvancePasteStack puts the next queued item on the clipboard
+// Ends the queue after its last item; the returned state is then inactive
+func (m *Manager) AdvancePasteStack() (PasteStackState, error) {
+	m.stackMu.Lock()
+	defer m.stackMu.Unlock()
+	if len(m.stack.ids) == 0 {
+		return PasteStackState{}, nil
+	}
+	return m.copyStackFrom(m.stack.pos + 1)
+}
+
+// ClearPasteStack ends the queue; the clipboard keeps its current item
+func (m *Manager) ClearPasteStack() {
+	m.stackMu.Lock()
+	defer m.stackMu.Unlock()
+	m.stack.reset()
+}
+
+// GetPasteStack returns the queue position
+func (m *Manager) GetPasteStack() PasteStackState {
+	m.stackMu.Lock()
+	defer m.stackMu.Unlock()
+	return m.stack.state()
+}
+
 // GetAllItems returns all clipboard items
 func (m *Manager) GetAllItems() []storage.ClipboardItem {
 	return m.db.GetAllItems()
 }
 
+// GetItemsByType returns the items of one type ("" for every type), optionally only pinned ones
+func (m *Manager) GetItemsByType(itemType string, pinnedOnly bool) []storage.ClipboardItem {
+	return m.db.GetItemsByType(itemType, pinnedOnly)
+}
+
+// PruneOlderThan removes unpinned items older than d and returns how many went
+func (m *Manager) PruneOlderThan(d time.Duration) int {
+	return m.db.PruneOlderThan(d)
+}
+
+// DeleteOlderThan moves unpinned items older than d to the trash and returns their IDs for undo
+func (m *Manager) DeleteOlderThan(d time.Duration) ([]string, error) {
+	return m.db.DeleteOlderThan(d)
+}
+
+// GetItemsByTag returns the items carrying tag, pinned first
+func (m *Manager) GetItemsByTag(tag string) []storage.ClipboardItem {
+	return m.db.GetItemsByTag(tag)
+}
+
+// SetTags replaces an item's tags
+func (m *Manager) SetTags(id string, tags []string) error {
+	return m.db.SetTags(id, tags)
+}
+
+// SetTitle sets the title the user gave an item; an empty title removes it
+func (m *Manager) SetTitle(id, title string) error {
+	return m.db.SetTitle(id, title)
+}
+
+// GetItemUserTitle returns the title the user gave an item, "" if it has none
+// Unlike the implicit title it isn't taken from the content, so protected items show it too
+func (m *Manager) GetItemUserTitle(id string) (string, error) {
+	return m.db.GetItemUserTitle(id)
+}
+
+// AllTags returns every tag in use, sorted
+func (m *Manager) AllTags() []string {
+	return m.db.AllTags()
+}
+
+// Snapshot returns all items with the change counter they reflect
+func (m *Manager) Snapshot() ([]storage.ClipboardItem, uint64) {
+	return m.db.Snapshot()
+}
+
+// GetItems returns a page of items, ordered like GetAllItems, and the history's size
+func (m *Manager) GetItems(offset, limit int) ([]storage.ClipboardItem, int) {
+	return m.db.GetItems(offset, limit)
+}
+
+// Seq returns the change counter of the history
+func (m *Manager) Seq() uint64 {
+	return m.db.Seq()
+}
+
+// Close stops change delivery and releases the database journal
+func (m *Manager) Close() error {
+	return m.db.Close()
+}
+
+// AuditEntries returns the log of pins, deletes, clears and restores, newest first
+func (m *Manager) AuditEntries() ([]storage.AuditEntry, error) {
+	return m.db.AuditEntries()
+}
+
+// LoadError returns why the database couldn't be loaded, nil if it loaded
+func (m *Manager) LoadError() error {
+	return m.db.LoadError()
+}
+
+// Reload retries loading the database after a failed load
+func (m *Manager) Reload() error {
+	return m.db.Reload()
+}
+
+// PlanV1Export reports what an export for the original build would drop
+func (m *Manager) PlanV1Export() (storage.DowngradeReport, error) {
+	return m.db.PlanV1Export()
+}
+
+// ExportV1 writes the history as a database file the original build can read
+// Returns ErrExportDisabled, and records the attempt, if policy forbids exports
+func (m *Manager) ExportV1(w io.Writer) (storage.DowngradeReport, error) {
+	if err := m.permissions.CheckExport(); err != nil {
+		m.db.RecordExportDenied()
+		return storage.DowngradeReport{}, err
+	}
+	return m.db.ExportV1(w)
+}
+
+// Export writes a password-protected copy of the history that other machines can read
+// Returns ErrExportDisabled, and records the attempt, if policy forbids exports
+func (m *Manager) Export(path, password string) error {
+	if err := m.permissions.CheckExport(); err != nil {
+		m.db.RecordExportDenied()
+		return err
+	}
+	return m.db.Export(path, password)
+}
+
+// Import reads a portable export into the history, merging or replacing it
+func (m *Manager) Import(path, password string, merge bool) error {
+	return m.db.Import(path, password, merge)
+}
+
+// Permissions returns what the defaults file allows
+func (m *Manager) Permissions() Permissions {
+	return m.permissions
+}
+
+// OnChange registers a listener for database changes, delivered in commit order
+func (m *Manager) OnChange(listener func(storage.ChangeEvent)) {
+	m.db.OnChange(listener)
+}
+
 // GetItemContent retrieves the decrypted content of an item
+// Protected items return storage.ErrProtected, so no preview can show them
 func (m *Manager) GetItemContent(id string) ([]byte, error) {
-	_, content, err := m.db.GetItem(id)
+	_, content, err := m.readableItem(id)
 	return content, err
 }
 
+// GetItemTitle returns the implicit title of a multi-line text item, "" if it has none
+func (m *Manager) GetItemTitle(id string) (string, error) {
+	if m.db.IsProtected(id) {
+		return "", storage.ErrProtected
+	}
+	return m.db.GetItemTitle(id)
+}
+
+// GetPreview returns what the list shows of an item without reading its content
+// Protected items return storage.ErrProtected like their content
+func (m *Manager) GetPreview(id string) (storage.Preview, error) {
+	if m.db.IsProtected(id) {
+		return storage.Preview{}, storage.ErrProtected
+	}
+	return m.db.GetPreview(id)
+}
+
+// GetItemSourceURL returns the page an item was copied from, "" if unknown
+func (m *Manager) GetItemSourceURL(id string) (string, error) {
+	if m.db.IsProtected(id) {
+		return "", storage.ErrProtected
+	}
+	return m.db.GetItemSourceURL(id)
+}
+
+// GetItemSourceApp returns the program an item was copied from, "" if unknown
+func (m *Manager) GetItemSourceApp(id string) (string, error) {
+	if m.db.IsProtected(id) {
+		return "", storage.ErrProtected
+	}
+	return m.db.GetItemSourceApp(id)
+}
+
+// RestoreItems puts removed items back into the history (undo)
+func (m *Manager) RestoreItems(items []storage.ClipboardItem) error {
+	return m.db.RestoreItems(items)
+}
+
+// RestorePoints returns the automatic safety copies, newest first
+func (m *Manager) RestorePoints() ([]storage.RestorePoint, error) {
+	return m.db.RestorePoints()
+}
+
+// RestoreFromPoint replaces all items with those of a safety copy
+func (m *Manager) RestoreFromPoint(path string) error {
+	return m.db.RestoreFromPoint(path)
+}
+
 // ClearAll removes all items from the database
 func (m *Manager) ClearAll() error {
 	return m.db.ClearAll()
 }
 
-// GetItemCount returns the number of items
-func (m *Manager) GetItemCount() int {
-	return m.db.GetItemCount()
-}
-
-// GetPinnedCount returns the number of pinned items
-func (m *Manager) GetPinnedCount() int {
-	return m.db.GetPinnedCount()
+// Counts returns the item counts and the limit checks derived from them
+func (m *Manager) Counts() storage.Counts {
+	return m.db.Counts()
 }
 
 // SetMaxItems sets the maximum number of items
@@ -102,22 +497,201 @@ func (m *Manager) GetMaxItems() int {
 	return m.db.GetMaxItems()
 }
 
-// IsNearLimit returns true if item count is within 10 of the limit
-func (m *Manager) IsNearLimit() bool {
-	return m.db.IsNearLimit()
+// SetMaxTotalSize caps the summed size of the history in bytes (0 is unlimited)
+func (m *Manager) SetMaxTotalSize(bytes int64) {
+	m.db.SetMaxTotalSize(bytes)
+}
+
+// GetMaxTotalSize returns the cap on the summed size of the history, 0 if there is none
+func (m *Manager) GetMaxTotalSize() int64 {
+	return m.db.GetMaxTotalSize()
+}
+
+// GetTotalSize returns the summed size of the history in bytes
+func (m *Manager) GetTotalSize() int64 {
+	return m.db.GetTotalSize()
+}
+
+// SetGraceWindow sets how long new items are protected from eviction by the limit
+func (m *Manager) SetGraceWindow(window time.Duration) {
+	m.db.SetGraceWindow(window)
+}
+
+// GetGraceWindow returns how long new items are protected from eviction by the limit
+func (m *Manager) GetGraceWindow() time.Duration {
+	return m.db.GetGraceWindow()
+}
+
+// SetDeltaImages enables storing near-identical screenshots as diffs
+func (m *Manager) SetDeltaImages(enabled bool) {
+	m.db.SetDeltaImages(enabled)
+}
+
+// GetDeltaImages returns whether delta image storage is enabled
+func (m *Manager) GetDeltaImages() bool {
+	return m.db.GetDeltaImages()
+}
+
+// SetURLCleaning configures removal of tracking parameters from captured URLs
+func (m *Manager) SetURLCleaning(enabled bool, params []string) {
+	m.db.SetURLCleaning(enabled, params)
+}
+
+// SetLimitPolicy sets what a capture does when the history is full
+func (m *Manager) SetLimitPolicy(policy storage.LimitPolicy) {
+	m.db.SetLimitPolicy(policy)
+}
+
+// GetLimitPolicy returns what a capture does when the history is full
+func (m *Manager) GetLimitPolicy() storage.LimitPolicy {
+	return m.db.GetLimitPolicy()
+}
+
+// SetDedupMode sets which existing items a capture is compared against for duplicates
+func (m *Manager) SetDedupMode(mode storage.DedupMode) {
+	m.db.SetDedupMode(mode, storage.DefaultDedupWindow)
+}
+
+// GetDedupMode returns the duplicate detection mode
+func (m *Manager) GetDedupMode() storage.DedupMode {
+	return m.db.GetDedupMode()
 }
 
-// GetRemainingSlots returns how many more items can be added
-func (m *Manager) GetRemainingSlots() int {
-	return m.db.GetRemainingSlots()
+// SetPinLimit sets how many items may be pinned
+func (m *Manager) SetPinLimit(limit int) {
+	m.db.SetPinLimit(limit)
+}
+
+// GetPinLimit returns the maximum number of pinned items
+func (m *Manager) GetPinLimit() int {
+	return m.db.GetPinLimit()
+}
+
+// SetRedactionRules replaces the rules that mask captured text before it is stored
+func (m *Manager) SetRedactionRules(rules []storage.RedactionRule) error {
+	return m.db.SetRedactionRules(rules)
+}
+
+// SetArchiveEnabled makes the limit move old items to the archive instead of deleting them
+func (m *Manager) SetArchiveEnabled(enabled bool) {
+	m.db.SetArchiveEnabled(enabled)
+}
+
+// GetArchiveEnabled returns whether dropped items are archived
+func (m *Manager) GetArchiveEnabled() bool {
+	return m.db.GetArchiveEnabled()
+}
+
+// SetArchiveMaxBytes caps the archive size (0 disables the cap)
+func (m *Manager) SetArchiveMaxBytes(max int64) {
+	m.db.Archive().SetMaxBytes(max)
+}
+
+// GetArchiveSize returns the archive file size in bytes
+func (m *Manager) GetArchiveSize() int64 {
+	return m.db.Archive().Size()
+}
+
+// SearchItems returns text items containing query, newest first
+func (m *Manager) SearchItems(query string) []storage.ClipboardItem {
+	return m.db.SearchItems(query)
+}
+
+// SearchArchive returns archived items matching query, newest first
+func (m *Manager) SearchArchive(query string) ([]storage.ClipboardItem, error) {
+	return m.db.Archive().Search(query)
+}
+
+// GetArchivedItemContent retrieves the decrypted content of an archived item
+func (m *Manager) GetArchivedItemContent(id string) ([]byte, error) {
+	item, content, err := m.db.Archive().GetItem(id)
+	if err == nil && item.IsProtected() {
+		storage.Zero(content)
+		return nil, storage.ErrProtected
+	}
+	return content, err
+}
+
+// CopyArchivedToClipboard copies an archived item to the system clipboard
+func (m *Manager) CopyArchivedToClipboard(id string) error {
+	item, content, err := m.db.Archive().GetItem(id)
+	if err != nil {
+		return fmt.Errorf("failed to get archived item: %w", err)
+	}
+	defer storage.Zero(content)
+	if item.IsProtected() {
+		return storage.ErrProtected
+	}
+
+	return m.writeContent(item.Type, content, nil, m.GetNewlineMode())
+}
+
+// RestoreFromArchive moves an archived item back into the active history
+func (m *Manager) RestoreFromArchive(id string) error {
+	return m.db.RestoreFromArchive(id)
+}
+
+// VerifyIntegrity checks stored items against their hashes
+func (m *Manager) VerifyIntegrity(ctx context.Context, opts storage.IntegrityOptions) (storage.IntegrityReport, error) {
+	return m.db.VerifyIntegrity(ctx, opts)
+}
+
+// IsCorrupt returns whether an item failed the last integrity check, and why
+func (m *Manager) IsCorrupt(id string) (bool, string) {
+	return m.db.IsCorrupt(id)
+}
+
+// Flush writes pending changes to disk now, see storage.Database.Flush
+func (m *Manager) Flush() error {
+	return m.db.Flush()
+}
+
+// Rekey re-encrypts the history with a new data key, see storage.Database.Rekey
+func (m *Manager) Rekey(progress func(done, total int)) error {
+	return m.db.Rekey(progress)
+}
+
+// RecoveryKey returns the data key as a recovery key, see storage.Database.RecoveryKey
+func (m *Manager) RecoveryKey() (string, error) {
+	return m.db.RecoveryKey()
+}
+
+// Recover opens a history written under another machine's key with a recovery key
+func (m *Manager) Recover(recoveryKey string) error {
+	return m.db.Recover(recoveryKey)
+}
+
+// HasPassword reports whether the history is locked with a master password
+func (m *Manager) HasPassword() bool {
+	return m.db.HasPassword()
+}
+
+// SetPassword locks the history with a master password, see storage.Database.SetPassword
+func (m *Manager) SetPassword(password string, progress func(done, total int)) error {
+	return m.db.SetPassword(password, progress)
+}
+
+// ChangePassword replaces the master password after checking the current one
+func (m *Manager) ChangePassword(current, password string) error {
+	return m.db.ChangePassword(current, password)
+}
+
+// RemovePassword removes the master password after checking it
+func (m *Manager) RemovePassword(current string) error {
+	return m.db.RemovePassword(current)
+}
+
+// NonceReport scans the stored ciphertexts for reused nonces
+func (m *Manager) NonceReport() storage.NonceReport {
+	return m.db.NonceReport()
+}
+
+// SetOnSaveError sets a callback for failed delayed database writes
+func (m *Manager) SetOnSaveError(callback func(err error)) {
+	m.db.SetOnSaveError(callback)
 }
 
 // SetOnLimitWarn sets callback for limit warning
 func (m *Manager) SetOnLimitWarn(callback func(remaining int)) {
 	m.db.SetOnLimitWarn(callback)
 }
-
-// IsFull returns true if at or over limit
-func (m *Manager) IsFull() bool {
-	return m.db.IsFull()
-}
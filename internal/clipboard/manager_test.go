@@ -0,0 +1,25 @@
+package clipboard
+
+import "testing"
+
+// Hand-written items are forced past a pause and pinned when asked
+func TestAddText(t *testing.T) {
+	m, db := newTestMonitor(t, &fakeReader{})
+	m.SetPaused(true)
+	manager := NewManager(db)
+
+	if err := manager.AddText("elle yazılan", true); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if err := manager.AddText("ikinci", false); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	expectTexts(t, db, "elle yazılan", "ikinci") // Pinned items lead
+	items := db.GetAllItems()
+	if !items[0].Forced || !items[0].Pinned {
+		t.Errorf("pinned item is %+v", items[0])
+	}
+	if !items[1].Forced || items[1].Pinned {
+		t.Errorf("unpinned item is %+v", items[1])
+	}
+}
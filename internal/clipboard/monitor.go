@@ -1,40 +1,168 @@
 package clipboard
 
 import (
-	"bytes"
-	"crypto/sha256"
+	"errors"
 	"fmt"
-	"image"
-	"image/png"
 	"sync"
 	"time"
 
 	"pano/internal/storage"
+)
 
-	"github.com/atotto/clipboard"
+var (
+	// ErrCaptureSkipped is returned by CaptureNow when capture is paused or the copying
+	// program excluded, and force is off
+	ErrCaptureSkipped = errors.New("capture is paused")
+	// ErrClipboardEmpty is returned by CaptureNow when there is nothing to store
+	ErrClipboardEmpty = errors.New("clipboard is empty")
 )
 
 // Monitor handles clipboard monitoring
 type Monitor struct {
 	db            *storage.Database
-	lastTextHash  []byte
-	lastImageHash []byte
+	reader        Reader      // Platform clipboard access, see reader.go
+	lastTextHash  Fingerprint // Fingerprints of the last seen content, by type
+	lastImageHash Fingerprint
+	lastFilesHash Fingerprint
+	lastSeq       uint32 // Clipboard sequence number of the last fingerprinted poll
+	seqSeen       bool   // lastSeq is valid
+	recheckSeq    uint32 // Sequence number an empty read last scheduled a recheck for
+	rechecked     bool   // recheckSeq is valid
 	running       bool
 	mu            sync.Mutex
 	onChange      func(itemType string, content []byte)
 	onLimitWarn   func(remaining int)
-	pollInterval  time.Duration
+	onReject      func(err error) // Captures the poll loop couldn't store
+	pollInterval  time.Duration   // Configured interval, see poll.go
+	changedAt     time.Time       // Last clipboard change the loop saw, for idle backoff
+
+	locked         bool              // Workstation is locked, capture paused
+	lockedInterval time.Duration     // Slower polling while locked
+	onLockChange   func(locked bool) // Callback for the status indicator
+	wake           chan struct{}     // Forces an immediate check
+	priming        bool              // Next check only records hashes (content copied while locked)
+	paused         bool              // Capture turned off by the user, see pause.go
+	pausedUntil    time.Time         // End of a timed pause, zero if paused until Resume
+	resumeTimer    *time.Timer       // Ends the timed pause
+	pauseSeq       uint64            // Numbers pauses so a stale resume timer does nothing
+	onPauseChange  func(paused bool) // Callback for the pause indicator and tray
+	gameMode       bool              // A full-screen game is running, poll slowly
+	excludedApps   map[string]bool   // Lowercased executables whose copies aren't stored, see exclude.go
+
+	doubleCopyWindow time.Duration    // Copying the same content twice within this pins it; 0 is off
+	onDoubleCopy     func(err error)  // Called after a double copy, with nil once the item is pinned
+	lastCaptureAt    time.Time        // When the poll loop last stored a capture, zero if it can't be double-copied
+	now              func() time.Time // Clock for the double-copy window and idle backoff, replaceable in tests
+
+	loops     *loopDetector         // Spots another program re-setting the clipboard, see loop.go
+	lastInput func() time.Time      // Time of the last keyboard or mouse input, nil if unknown
+	onLoop    func(itemType string) // Called when a clipboard loop is detected
+
+	checkMu sync.Mutex // Serializes clipboard reads between the poll loop and CaptureNow
+
+	wg     sync.WaitGroup // Poll loop and callback goroutines, waited for by Close
+	closed bool           // Close was called; the monitor can't be started again
 }
 
+// Default polling intervals
+const (
+	DefaultPollInterval     = 200 * time.Millisecond // Faster polling
+	DefaultLockedInterval   = 5 * time.Second
+	DefaultGameModeInterval = 2 * time.Second // Keeps clipboard reads away from game input
+)
+
+// emptyRecheckDelay is how soon the loop looks again after a copy whose formats had no
+// data yet; programs that publish them empty fill them in within milliseconds
+const emptyRecheckDelay = 50 * time.Millisecond
+
+// closeTimeout bounds how long Close waits for goroutines to return
+const closeTimeout = 2 * time.Second
+
 // NewMonitor creates a new clipboard monitor
-func NewMonitor(db *storage.Database) *Monitor {
-	return &Monitor{
-		db:           db,
-		pollInterval: 200 * time.Millisecond, // Faster polling
-		running:      false,
+func NewMonitor(db *storage.Database, opts ...MonitorOption) *Monitor {
+	m := &Monitor{
+		db:             db,
+		reader:         newPlatformReader(),
+		pollInterval:   DefaultPollInterval,
+		lockedInterval: DefaultLockedInterval,
+		running:        false,
+		wake:           make(chan struct{}, 1),
+		now:            time.Now,
+		loops:          newLoopDetector(),
+		excludedApps:   excludedAppSet(DefaultExcludedApps),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// SetOnLockChange sets the callback for lock state transitions
+func (m *Monitor) SetOnLockChange(callback func(locked bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onLockChange = callback
+}
+
+// SetLocked pauses capture while the workstation is locked
+// Unlocking forces an immediate clipboard check
+func (m *Monitor) SetLocked(locked bool) {
+	m.mu.Lock()
+	if m.locked == locked {
+		m.mu.Unlock()
+		return
+	}
+	m.locked = locked
+	if !locked {
+		m.priming = true
+	}
+	callback := m.onLockChange
+	m.mu.Unlock()
+
+	// Wake the loop so it picks up the new interval right away
+	m.wakeLoop()
+
+	if callback != nil {
+		callback(locked)
+	}
+}
+
+// wakeLoop makes the poll loop run its next iteration immediately
+func (m *Monitor) wakeLoop() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
 	}
 }
 
+// SetPaused turns capture off or back on, see Pause and Resume
+// Content copied while paused is never recorded
+func (m *Monitor) SetPaused(paused bool) {
+	m.setPaused(paused, 0)
+}
+
+// SetGameMode slows polling down while a full-screen game runs
+// Unlike pausing, content copied meanwhile is still captured, just later
+func (m *Monitor) SetGameMode(active bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gameMode = active
+}
+
+// IsPaused returns whether capture is turned off by the user
+func (m *Monitor) IsPaused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
+// IsLocked returns whether capture is paused for a locked session
+func (m *Monitor) IsLocked() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.locked
+}
+
 // SetOnChange sets the callback function for clipboard changes
 func (m *Monitor) SetOnChange(callback func(itemType string, content []byte)) {
 	m.mu.Lock()
@@ -49,131 +177,478 @@ func (m *Monitor) SetOnLimitWarn(callback func(remaining int)) {
 	m.onLimitWarn = callback
 }
 
+// SetOnReject sets the callback for captures the poll loop couldn't store
+// CaptureNow returns the error instead
+func (m *Monitor) SetOnReject(callback func(err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onReject = callback
+}
+
+// SetDoubleCopyWindow sets how quickly the same content must be copied again to pin it; 0 turns it off
+func (m *Monitor) SetDoubleCopyWindow(window time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.doubleCopyWindow = max(window, 0)
+}
+
+// SetOnDoubleCopy sets the callback for the double-copy gesture
+// err is nil once the item is pinned, storage.ErrPinLimitReached if the pin limit is full
+func (m *Monitor) SetOnDoubleCopy(callback func(err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onDoubleCopy = callback
+}
+
 // Start begins monitoring the clipboard
 func (m *Monitor) Start() error {
 	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return fmt.Errorf("monitor is closed")
+	}
 	if m.running {
-		m.mu.Unlock()
 		return fmt.Errorf("monitor already running")
 	}
 	m.running = true
-	m.mu.Unlock()
+	m.changedAt = m.now()
 
-	go m.monitorLoop()
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.monitorLoop()
+	}()
 	return nil
 }
 
-// Stop stops monitoring the clipboard
+// Stop stops monitoring the clipboard; the poll loop returns without waiting for its next tick
 func (m *Monitor) Stop() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	m.running = false
+	m.mu.Unlock()
+	m.wakeLoop()
+}
+
+// Close stops monitoring and waits for the poll loop and callbacks it started to return
+// Closing twice is a no-op
+func (m *Monitor) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	m.stopResumeTimer()
+	m.mu.Unlock()
+
+	m.Stop()
+	if !waitTimeout(&m.wg, closeTimeout) {
+		return fmt.Errorf("monitor did not stop within %v", closeTimeout)
+	}
+	return nil
+}
+
+// goCallback runs a callback on its own goroutine, tracked for Close
+// Callbacks are dropped once the monitor is closed
+func (m *Monitor) goCallback(callback func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		callback()
+	}()
+}
+
+// waitTimeout waits for wg and reports whether it finished within timeout
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
 }
 
 // monitorLoop continuously checks for clipboard changes
+// The ticker is reset whenever the interval changes: a new setting, the session
+// locking, game mode or idle backoff
 func (m *Monitor) monitorLoop() {
-	ticker := time.NewTicker(m.pollInterval)
+	m.mu.Lock()
+	currentInterval := m.pollInterval
+	m.mu.Unlock()
+
+	ticker := time.NewTicker(currentInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			m.mu.Lock()
-			running := m.running
-			m.mu.Unlock()
+		case <-m.wake:
+		}
+
+		m.mu.Lock()
+		running := m.running
+		locked := m.locked
+		paused := m.paused
+		gameMode := m.gameMode
+		interval := idleInterval(m.pollInterval, m.now().Sub(m.changedAt))
+		m.mu.Unlock()
 
-			if !running {
-				return
-			}
+		if !running {
+			return
+		}
 
-			m.checkClipboard()
+		// Poll slowly while locked to save battery
+		if locked {
+			interval = m.lockedInterval
+		} else if gameMode && interval < DefaultGameModeInterval {
+			interval = DefaultGameModeInterval
+		}
+		if interval != currentInterval {
+			ticker.Reset(interval)
+			currentInterval = interval
 		}
+
+		if locked || paused {
+			continue
+		}
+		m.poll()
+	}
+}
+
+// Poll checks the clipboard once, as the poll loop does on every tick; nothing is read
+// while paused or locked
+// For driving the monitor without its loop, as the harness in panotest does
+func (m *Monitor) Poll() {
+	m.mu.Lock()
+	skip := m.locked || m.paused
+	m.mu.Unlock()
+	if !skip {
+		m.poll()
 	}
 }
 
+// poll is one check of the poll loop
+func (m *Monitor) poll() {
+	m.checkClipboard()
+
+	// Unchanged clipboard after unlock: nothing to prime
+	m.takePriming()
+}
+
 // checkClipboard checks if clipboard content has changed
 func (m *Monitor) checkClipboard() {
-	// Try to read image first (if available)
-	// Images are checked first because text might be empty but image could be present
-	if img, err := ReadClipboardImage(); err == nil && img != nil {
-		m.handleImage(img)
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+
+	// Nothing was copied since the last poll; the clipboard isn't even opened
+	seq, hasSeq := m.reader.Sequence()
+	if hasSeq && m.seqSeen && seq == m.lastSeq {
 		return
 	}
+	if hasSeq {
+		m.noteChange()
+	}
 
-	// Try to read text
-	text, err := clipboard.ReadAll()
-	if err == nil && text != "" {
-		m.handleText(text)
+	// Empty or unreadable clipboards are checked again on the next poll, and soon after
+	// a new copy in case its data is still on the way
+	itemType, hash, ok := m.fingerprint()
+	if !ok {
+		m.recheckSoon(seq, hasSeq)
 		return
 	}
-}
+	// Past the check above, a sequence number seen before means a new copy was made
+	copied := hasSeq && m.seqSeen
+	m.lastSeq, m.seqSeen = seq, hasSeq
+
+	lastHash := m.lastHash(itemType)
 
-// handleText processes new text content
-func (m *Monitor) handleText(text string) {
-	content := []byte(text)
-	hash := sha256.Sum256(content)
+	// Content another program keeps putting back isn't captured again
+	if copied && m.observeLoop(itemType, hash) != loopNone {
+		*lastHash = hash
+		return
+	}
 
 	// Check if content has changed
-	if bytes.Equal(hash[:], m.lastTextHash) {
+	if hash == *lastHash {
+		if copied {
+			m.checkDoubleCopy(itemType)
+		}
 		return
 	}
 
-	m.lastTextHash = hash[:]
+	*lastHash = hash
+	if !hasSeq {
+		m.noteChange()
+	}
 
-	// Add to database
-	err := m.db.AddItem("text", content)
+	// Whatever was copied while the session was locked is not recorded
+	if m.takePriming() {
+		return
+	}
 
-	// Check for limit warnings
+	// Copies from excluded programs such as password managers are never even read; copying
+	// the same secret again mustn't count as a double copy of the previous capture either
+	if m.ownerExcluded() {
+		m.lastCaptureAt = time.Time{}
+		return
+	}
+
+	// Content is only read and encoded once it is known to be new
+	content, err := m.readContent(itemType)
+	if err != nil {
+		// Forget the change so the next poll tries again
+		*lastHash, m.seqSeen = Fingerprint{}, false
+		m.recheckSoon(seq, hasSeq)
+		return
+	}
+
+	// Items Pano copied itself aren't captured again; see selfwrite.go
+	if ownWrites.take(itemType, content) {
+		return
+	}
+
+	if err := m.store(itemType, content, m.captureInfo(itemType)); err != nil {
+		m.forgetRejected(lastHash, hasSeq)
+		m.reportReject(err)
+		return
+	}
+
+	// Only a copy the sequence number confirms can start a double copy; without it,
+	// reads of the same copy can't be told apart from copying again
+	if hasSeq {
+		m.lastCaptureAt = m.now()
+	}
+}
+
+// recheckSoon wakes the loop after emptyRecheckDelay, once per clipboard sequence number
+// so an empty clipboard isn't polled in a tight loop (caller holds checkMu)
+func (m *Monitor) recheckSoon(seq uint32, hasSeq bool) {
+	if !hasSeq || (m.rechecked && m.recheckSeq == seq) {
+		return
+	}
+	m.recheckSeq, m.rechecked = seq, true
+	time.AfterFunc(emptyRecheckDelay, m.wakeLoop)
+}
+
+// forgetRejected clears the fingerprint of content the database refused, so copying it
+// again tries again (caller holds checkMu)
+// Without a sequence number every poll would retry, and report, the same refusal
+func (m *Monitor) forgetRejected(lastHash *Fingerprint, hasSeq bool) {
+	if hasSeq {
+		*lastHash = Fingerprint{}
+	}
+}
+
+// reportReject hands a capture the loop couldn't store to the reject callback
+func (m *Monitor) reportReject(err error) {
 	m.mu.Lock()
-	limitCallback := m.onLimitWarn
-	changeCallback := m.onChange
+	rejectCallback := m.onReject
 	m.mu.Unlock()
+	if rejectCallback != nil {
+		m.goCallback(func() { rejectCallback(err) })
+	}
+}
 
+// checkDoubleCopy pins the last capture when the same content was copied again
+// within the double-copy window (caller holds checkMu)
+// The item is stored again with Pinned set, so it goes through the same duplicate
+// match as any capture; with duplicates kept apart there is nothing to pin
+func (m *Monitor) checkDoubleCopy(itemType string) {
+	m.mu.Lock()
+	window := m.doubleCopyWindow
+	callback := m.onDoubleCopy
+	m.mu.Unlock()
+
+	captured := m.lastCaptureAt
+	if window <= 0 || captured.IsZero() || m.now().Sub(captured) > window {
+		return
+	}
+	if m.db.GetDedupMode() == storage.DedupOff {
+		return
+	}
+	m.lastCaptureAt = time.Time{} // A third copy doesn't count again
+
+	content, err := m.readContent(itemType)
 	if err != nil {
-		errStr := err.Error()
-		if len(errStr) >= 10 && errStr[:10] == "LIMIT_FULL" {
-			if limitCallback != nil {
-				go limitCallback(0)
-			}
-			return
-		} else if len(errStr) >= 10 && errStr[:10] == "LIMIT_WARN" {
-			var remaining int
-			fmt.Sscanf(errStr, "LIMIT_WARN:%d", &remaining)
-			if limitCallback != nil {
-				go limitCallback(remaining)
-			}
-			// Continue to trigger onChange since item was added
-		} else {
-			return // Silently ignore other errors
-		}
+		return
+	}
+	// Pano copying the item back isn't the user copying it twice
+	if ownWrites.take(itemType, content) {
+		return
 	}
 
-	if changeCallback != nil {
-		changeCallback("text", content)
+	info := m.captureInfo(itemType)
+	info.Pinned = true
+	err = m.store(itemType, content, info)
+	var reject *storage.RejectError
+	if errors.As(err, &reject) {
+		// Not a pin problem; reported like any capture the loop couldn't store
+		m.reportReject(err)
+		return
+	}
+	if callback != nil {
+		m.goCallback(func() { callback(err) })
 	}
 }
 
-// handleImage processes new image content
-func (m *Monitor) handleImage(img image.Image) {
-	// Convert image to PNG bytes
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
-		fmt.Printf("Error encoding image: %v\n", err)
-		return
+// CaptureNow reads the clipboard immediately and stores it
+// With force the item is stored even while paused or locked and is marked as forced
+func (m *Monitor) CaptureNow(force bool) (string, error) {
+	m.mu.Lock()
+	skip := !force && (m.paused || m.locked)
+	m.mu.Unlock()
+	if skip || (!force && m.ownerExcluded()) {
+		return "", ErrCaptureSkipped
 	}
 
-	content := buf.Bytes()
-	hash := sha256.Sum256(content)
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
 
-	// Check if content has changed
-	if bytes.Equal(hash[:], m.lastImageHash) {
+	itemType, hash, ok := m.fingerprint()
+	if !ok {
+		return "", ErrClipboardEmpty
+	}
+	content, err := m.readContent(itemType)
+	if err != nil {
+		return "", ErrClipboardEmpty
+	}
+
+	// Remember the hash so the poll loop doesn't store it again
+	*m.lastHash(itemType) = hash
+
+	info := m.captureInfo(itemType)
+	info.Forced = force
+	return itemType, m.store(itemType, content, info)
+}
+
+// Sequence returns the platform clipboard sequence number, see Reader
+func (m *Monitor) Sequence() (uint32, bool) {
+	return m.reader.Sequence()
+}
+
+// IgnoreNext makes the poll loop treat content as already seen, so text Pano writes
+// itself (e.g. an image copied as base64) isn't captured as a new item
+// Call it before writing the clipboard
+// Images and files are fingerprinted by their clipboard bytes, which aren't known
+// before the write; those writes are skipped through ownWrites instead
+func (m *Monitor) IgnoreNext(itemType string, content []byte) {
+	if itemType != "text" {
 		return
 	}
 
-	m.lastImageHash = hash[:]
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+	m.lastTextHash = TextFingerprint(string(content))
+}
+
+// takePriming reports whether this check should only record hashes, clearing the flag
+func (m *Monitor) takePriming() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	priming := m.priming
+	m.priming = false
+	return priming
+}
 
-	// Add to database
-	err := m.db.AddItem("image", content)
+// lastHash returns the fingerprint last seen for content of itemType (caller holds checkMu)
+func (m *Monitor) lastHash(itemType string) *Fingerprint {
+	switch itemType {
+	case "image":
+		return &m.lastImageHash
+	case "files":
+		return &m.lastFilesHash
+	}
+	return &m.lastTextHash
+}
+
+// fingerprint returns the type of the clipboard content and its fingerprint (caller holds checkMu)
+// Images are checked first because text might be empty but image could be present;
+// files come before text, which some programs add with the paths
+func (m *Monitor) fingerprint() (string, Fingerprint, bool) {
+	if hash, ok := m.reader.ImageFingerprint(); ok {
+		return "image", hash, true
+	}
+	if hash, ok := m.reader.FilesFingerprint(); ok {
+		return "files", hash, true
+	}
+	if hash, ok := m.reader.TextFingerprint(); ok {
+		return "text", hash, true
+	}
+	return "", Fingerprint{}, false
+}
+
+// readContent reads the clipboard content of a fingerprinted type, images as PNG and
+// files as the JSON list of their paths; empty content is an error, never an item
+func (m *Monitor) readContent(itemType string) ([]byte, error) {
+	switch itemType {
+	case "image":
+		data, err := m.reader.ReadImagePNG()
+		if err == nil && len(data) == 0 {
+			return nil, ErrClipboardEmpty
+		}
+		return data, err
+	case "files":
+		paths, err := m.reader.ReadFiles()
+		if err != nil {
+			return nil, err
+		}
+		return storage.EncodeFiles(paths)
+	}
+	text, err := m.reader.ReadText()
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return nil, ErrClipboardEmpty
+	}
+	return []byte(text), nil
+}
+
+// captureInfo returns where the content on the clipboard came from: the page and the
+// program, and for text the formatting copied with it
+// Only read once the content changed, so polling doesn't open the clipboard twice
+func (m *Monitor) captureInfo(itemType string) storage.CaptureInfo {
+	info := storage.CaptureInfo{SourceURL: m.readSourceURL(), SourceApp: m.reader.Owner()}
+	if itemType == "text" {
+		info.RTF = m.readRTF()
+	}
+	return info
+}
+
+// readRTF returns the Rich Text Format copied with the text, nil if there is none
+// Formatting too large to store is dropped; the text is still captured
+func (m *Monitor) readRTF() []byte {
+	rtf, err := m.reader.ReadRTF()
+	if err != nil || len(rtf) > storage.MaxItemSize {
+		return nil
+	}
+	return rtf
+}
+
+// readSourceURL returns the page URL browsers put in the CF_HTML header, "" if there is none
+func (m *Monitor) readSourceURL() string {
+	html, err := m.reader.ReadHTML()
+	if err != nil {
+		return ""
+	}
+	return ParseCFHTMLSourceURL(html)
+}
+
+// store adds content to the database and fires the callbacks
+// Shared by the poll loop and CaptureNow; returns nil when the item was stored
+// A near-limit warning goes to the limit callback; other errors are returned
+func (m *Monitor) store(itemType string, content []byte, info storage.CaptureInfo) error {
+	err := m.db.AddCapturedItem(itemType, content, info)
 
 	// Check for limit warnings
 	m.mu.Lock()
@@ -181,25 +656,18 @@ func (m *Monitor) handleImage(img image.Image) {
 	changeCallback := m.onChange
 	m.mu.Unlock()
 
-	if err != nil {
-		errStr := err.Error()
-		if len(errStr) >= 10 && errStr[:10] == "LIMIT_FULL" {
-			if limitCallback != nil {
-				go limitCallback(0)
-			}
-			return
-		} else if len(errStr) >= 10 && errStr[:10] == "LIMIT_WARN" {
-			var remaining int
-			fmt.Sscanf(errStr, "LIMIT_WARN:%d", &remaining)
-			if limitCallback != nil {
-				go limitCallback(remaining)
-			}
-		} else {
-			return // Silently ignore other errors
+	var warning *storage.LimitWarning
+	if errors.As(err, &warning) {
+		if limitCallback != nil {
+			m.goCallback(func() { limitCallback(warning.Remaining) })
 		}
+		// Continue to trigger onChange since item was added
+	} else if err != nil {
+		return err
 	}
 
 	if changeCallback != nil {
-		changeCallback("image", content)
+		changeCallback(itemType, content)
 	}
+	return nil
 }
@@ -0,0 +1,294 @@
+package clipboard
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+	"testing"
+	"time"
+
+	"pano/internal/storage"
+)
+
+// fakeReader is a clipboard the tests set directly
+// Every set is a new copy with a new sequence number; fill replaces the content under
+// the current one, as a program that publishes its formats before their data
+type fakeReader struct {
+	mu      sync.Mutex
+	seq     uint32
+	text    string
+	png     []byte
+	pending bool // Formats are advertised but reads return nothing yet
+	noSeq   bool // No sequence number, as on platforms without one
+}
+
+func (r *fakeReader) set(text string, png []byte, pending bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.text, r.png, r.pending = text, png, pending
+	r.seq++
+}
+
+func (r *fakeReader) fill(text string, png []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.text, r.png, r.pending = text, png, false
+}
+
+func (r *fakeReader) Sequence() (uint32, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seq, !r.noSeq
+}
+
+func (r *fakeReader) ImageFingerprint() (Fingerprint, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.png) == 0 {
+		return Fingerprint{}, false
+	}
+	return sha256.Sum256(r.png), true
+}
+
+func (r *fakeReader) TextFingerprint() (Fingerprint, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.text == "" {
+		return Fingerprint{}, false
+	}
+	return TextFingerprint(r.text), true
+}
+
+// ReadImagePNG returns zero-length data while pending, as GetClipboardData does for a
+// format whose owner hasn't rendered it yet
+func (r *fakeReader) ReadImagePNG() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending {
+		return []byte{}, nil
+	}
+	if len(r.png) == 0 {
+		return nil, errors.New("no image")
+	}
+	return bytes.Clone(r.png), nil
+}
+
+func (r *fakeReader) ReadText() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending {
+		return "", nil
+	}
+	return r.text, nil
+}
+
+// newTestMonitor returns a monitor reading r, storing into a database in a temp directory
+func newTestMonitor(t testing.TB, r *fakeReader) (*Monitor, *storage.Database) {
+	t.Helper()
+	storage.UseTempDataDir(t)
+	db, err := storage.NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewMonitor(db, WithReader(r)), db
+}
+
+// texts returns the text of every stored text item, newest first
+func texts(t *testing.T, db *storage.Database) []string {
+	t.Helper()
+	var got []string
+	for _, item := range db.GetAllItems() {
+		if item.Type != "text" {
+			continue
+		}
+		_, content, err := db.GetItem(item.ID)
+		if err != nil {
+			t.Fatalf("failed to read item: %v", err)
+		}
+		got = append(got, string(content))
+	}
+	return got
+}
+
+func expectTexts(t *testing.T, db *storage.Database, want ...string) {
+	t.Helper()
+	got := texts(t, db)
+	if len(got) != len(want) {
+		t.Fatalf("history is %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("history is %q, want %q", got, want)
+		}
+	}
+}
+
+func testImage(t testing.TB) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 2, color.NRGBA{R: 200, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// A copy whose formats come without data at first is captured once the data arrives
+// under the same sequence number, and nothing empty is stored meanwhile
+func TestLateClipboardData(t *testing.T) {
+	r := &fakeReader{}
+	m, db := newTestMonitor(t, r)
+
+	r.set("before", nil, false)
+	m.checkClipboard()
+	r.set("", nil, false)
+	m.checkClipboard()
+	expectTexts(t, db, "before")
+
+	r.fill("real", nil)
+	m.checkClipboard()
+	expectTexts(t, db, "real", "before")
+}
+
+// Content whose fingerprint is seen but reads back empty isn't marked as seen, for
+// text and images, so it is stored once it can be read
+func TestEmptyReadThenReal(t *testing.T) {
+	r := &fakeReader{}
+	m, db := newTestMonitor(t, r)
+
+	r.set("real", nil, true)
+	m.checkClipboard()
+	expectTexts(t, db)
+	r.fill("real", nil)
+	m.checkClipboard()
+	expectTexts(t, db, "real")
+
+	img := testImage(t)
+	r.set("", img, true)
+	m.checkClipboard()
+	if got := len(db.GetAllItems()); got != 1 {
+		t.Fatalf("history has %d items after an empty image read, want 1", got)
+	}
+	r.fill("", img)
+	m.checkClipboard()
+	if got := len(db.GetAllItems()); got != 2 {
+		t.Fatalf("history has %d items once the image could be read, want 2", got)
+	}
+}
+
+// A capture the database refused is tried again when the same content is copied again
+func TestRejectedCaptureIsRetried(t *testing.T) {
+	r := &fakeReader{}
+	m, db := newTestMonitor(t, r)
+	db.SetMaxItems(10)
+	db.SetLimitPolicy(storage.LimitReject)
+
+	var want []string
+	for i := 0; i < 10; i++ {
+		text := fmt.Sprintf("item %d", i)
+		r.set(text, nil, false)
+		m.checkClipboard()
+		want = append([]string{text}, want...)
+	}
+	r.set("extra", nil, false)
+	m.checkClipboard()
+	expectTexts(t, db, want...)
+
+	if err := db.DeleteItem(db.GetAllItems()[0].ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	r.set("extra", nil, false)
+	m.checkClipboard()
+	expectTexts(t, db, append([]string{"extra"}, want[1:]...)...)
+}
+
+// A capture the poll loop couldn't store reaches the reject callback with its reason
+func TestOnReject(t *testing.T) {
+	r := &fakeReader{}
+	m, db := newTestMonitor(t, r)
+	db.SetMaxItems(10)
+	db.SetLimitPolicy(storage.LimitReject)
+	rejected := make(chan error, 1)
+	m.SetOnReject(func(err error) { rejected <- err })
+
+	for i := 0; i < 10; i++ {
+		r.set(fmt.Sprintf("item %d", i), nil, false)
+		m.checkClipboard()
+	}
+	select {
+	case err := <-rejected:
+		t.Fatalf("a stored capture was reported as rejected: %v", err)
+	default:
+	}
+
+	r.set("extra", nil, false)
+	m.checkClipboard()
+	select {
+	case err := <-rejected:
+		var reject *storage.RejectError
+		if !errors.As(err, &reject) || reject.Reason != storage.RejectLimitFull {
+			t.Errorf("reject callback got %v, want RejectLimitFull", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the rejected capture wasn't reported")
+	}
+}
+
+// BenchmarkCheckUnchanged measures a poll that finds the content it already captured,
+// which is nearly every poll; with a sequence number the clipboard isn't opened at all
+func BenchmarkCheckUnchanged(b *testing.B) {
+	img := testImage(b)
+	tests := []struct {
+		name  string
+		text  string
+		png   []byte
+		noSeq bool
+	}{
+		{"sequence", "unchanged", nil, false},
+		{"text fingerprint", "unchanged", nil, true},
+		{"image fingerprint", "", img, true},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			r := &fakeReader{noSeq: tt.noSeq}
+			m, db := newTestMonitor(b, r)
+			r.set(tt.text, tt.png, false)
+			m.checkClipboard()
+			if len(db.GetAllItems()) != 1 {
+				b.Fatal("the content wasn't captured")
+			}
+
+			b.ReportAllocs()
+			for b.Loop() {
+				m.checkClipboard()
+			}
+		})
+	}
+}
+
+func (r *fakeReader) ReadHTML() ([]byte, error) {
+	return nil, errors.New("no HTML")
+}
+
+func (r *fakeReader) Owner() string {
+	return ""
+}
+
+func (r *fakeReader) FilesFingerprint() (Fingerprint, bool) {
+	return Fingerprint{}, false
+}
+
+func (r *fakeReader) ReadFiles() ([]string, error) {
+	return nil, errors.New("no files")
+}
+
+func (r *fakeReader) ReadRTF() ([]byte, error) {
+	return nil, errors.New("no RTF")
+}
@@ -0,0 +1,47 @@
+package clipboard
+
+import "bytes"
+
+// NewlineMode controls how line endings are written when text is copied
+type NewlineMode string
+
+const (
+	NewlineAsIs NewlineMode = ""     // Write content exactly as stored
+	NewlineLF   NewlineMode = "lf"   // Convert every line ending to \n
+	NewlineCRLF NewlineMode = "crlf" // Convert every line ending to \r\n
+)
+
+// NormalizeNewlines converts line endings in text to the given mode
+// \r\n, lone \r and lone \n all count as one line ending, so mixed content
+// never ends up with doubled blank lines. The input is never modified.
+func NormalizeNewlines(text []byte, mode NewlineMode) []byte {
+	var eol []byte
+	switch mode {
+	case NewlineLF:
+		eol = []byte("\n")
+	case NewlineCRLF:
+		eol = []byte("\r\n")
+	default:
+		return text
+	}
+
+	if !bytes.ContainsAny(text, "\r\n") {
+		return text
+	}
+
+	out := make([]byte, 0, len(text)+bytes.Count(text, []byte("\n")))
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			out = append(out, eol...)
+		case '\n':
+			out = append(out, eol...)
+		default:
+			out = append(out, text[i])
+		}
+	}
+	return out
+}
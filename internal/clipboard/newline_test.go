@@ -0,0 +1,64 @@
+package clipboard_test
+
+import (
+	"testing"
+
+	"pano/internal/clipboard"
+	"pano/internal/panotest"
+)
+
+// TestNormalizeNewlines converts mixed line endings either way; every ending counts once
+func TestNormalizeNewlines(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		mode clipboard.NewlineMode
+		want string
+	}{
+		{"as is", "a\r\nb\nc\r", clipboard.NewlineAsIs, "a\r\nb\nc\r"},
+		{"mixed to LF", "a\r\nb\nc\rd", clipboard.NewlineLF, "a\nb\nc\nd"},
+		{"mixed to CRLF", "a\r\nb\nc\rd", clipboard.NewlineCRLF, "a\r\nb\r\nc\r\nd"},
+		{"no doubled blank lines", "a\r\n\r\nb", clipboard.NewlineCRLF, "a\r\n\r\nb"},
+		{"lone CR before LF ending", "a\r\r\nb", clipboard.NewlineLF, "a\n\nb"},
+		{"trailing ending", "satır\n", clipboard.NewlineCRLF, "satır\r\n"},
+		{"single line", "tek satır", clipboard.NewlineCRLF, "tek satır"},
+		{"unknown mode", "a\nb", clipboard.NewlineMode("mac"), "a\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := []byte(tt.text)
+			if got := string(clipboard.NormalizeNewlines(in, tt.mode)); got != tt.want {
+				t.Errorf("NormalizeNewlines(%q, %q) = %q, want %q", tt.text, tt.mode, got, tt.want)
+			}
+			if string(in) != tt.text {
+				t.Errorf("the input was modified to %q", in)
+			}
+		})
+	}
+}
+
+// TestCopyWithNewlines copies with the default conversion and with one chosen per copy,
+// and checks that the stored text stays as captured
+func TestCopyWithNewlines(t *testing.T) {
+	h := panotest.New(t)
+	const stored = "bir\niki\r\nüç"
+	if err := h.DB.AddItem("text", []byte(stored)); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	id := h.DB.GetAllItems()[0].ID
+
+	h.Manager.SetNewlineMode(clipboard.NewlineCRLF)
+	if err := h.Manager.CopyToClipboard(id); err != nil {
+		t.Fatalf("failed to copy: %v", err)
+	}
+	if err := h.Manager.CopyWithNewlines(id, clipboard.NewlineLF); err != nil {
+		t.Fatalf("failed to copy with LF: %v", err)
+	}
+	writes := h.Clipboard.Writes()
+	if len(writes) != 2 || writes[0].Text != "bir\r\niki\r\nüç" || writes[1].Text != "bir\niki\nüç" {
+		t.Errorf("wrote %+v", writes)
+	}
+	if _, content, err := h.DB.GetItem(id); err != nil || string(content) != stored {
+		t.Errorf("stored text is now %q, %v", content, err)
+	}
+}
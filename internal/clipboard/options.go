@@ -0,0 +1,161 @@
+package clipboard
+
+import (
+	"time"
+
+	"pano/internal/storage"
+)
+
+// MonitorOption configures a Monitor at construction
+type MonitorOption func(*Monitor)
+
+// WithPollInterval sets how often the clipboard is checked
+func WithPollInterval(interval time.Duration) MonitorOption {
+	return func(m *Monitor) {
+		if interval > 0 {
+			m.pollInterval = interval
+		}
+	}
+}
+
+// WithLockedInterval sets how often the loop wakes while the session is locked
+func WithLockedInterval(interval time.Duration) MonitorOption {
+	return func(m *Monitor) {
+		if interval > 0 {
+			m.lockedInterval = interval
+		}
+	}
+}
+
+// WithReader replaces the platform clipboard reader, for tests and benchmarks
+func WithReader(reader Reader) MonitorOption {
+	return func(m *Monitor) {
+		if reader != nil {
+			m.reader = reader
+		}
+	}
+}
+
+// WithExcludedApps replaces DefaultExcludedApps, see SetExcludedApps
+func WithExcludedApps(names []string) MonitorOption {
+	return func(m *Monitor) {
+		m.excludedApps = excludedAppSet(names)
+	}
+}
+
+// WithDoubleCopyPin turns on pinning by copying the same content twice within window
+func WithDoubleCopyPin(window time.Duration) MonitorOption {
+	return func(m *Monitor) {
+		m.doubleCopyWindow = max(window, 0)
+	}
+}
+
+// WithClock replaces the clock used for the double-copy window, for tests
+func WithClock(now func() time.Time) MonitorOption {
+	return func(m *Monitor) {
+		if now != nil {
+			m.now = now
+		}
+	}
+}
+
+// ManagerOption configures a Manager (and its database) at construction
+type ManagerOption func(*Manager)
+
+// WithMaxItems sets the history limit
+func WithMaxItems(max int) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetMaxItems(max)
+	}
+}
+
+// WithMaxTotalSize caps the summed size of the history in bytes (0 is unlimited)
+func WithMaxTotalSize(bytes int64) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetMaxTotalSize(bytes)
+	}
+}
+
+// WithGraceWindow sets how long new items are protected from eviction by the limit
+func WithGraceWindow(window time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetGraceWindow(window)
+	}
+}
+
+// WithNewlineMode sets the default line ending conversion for copied text
+func WithNewlineMode(mode NewlineMode) ManagerOption {
+	return func(m *Manager) {
+		m.newlineMode = mode
+	}
+}
+
+// WithDeltaImages enables storing near-identical images as patches
+func WithDeltaImages(enabled bool) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetDeltaImages(enabled)
+	}
+}
+
+// WithArchive enables the archive for items dropped by the limit, capped at maxBytes (0 is unlimited)
+func WithArchive(enabled bool, maxBytes int64) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetArchiveEnabled(enabled)
+		m.db.Archive().SetMaxBytes(maxBytes)
+	}
+}
+
+// WithURLCleaning enables removing the given tracking parameters from captured URLs
+func WithURLCleaning(enabled bool, params []string) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetURLCleaning(enabled, params)
+	}
+}
+
+// WithLimitPolicy sets what a capture does when the history is full
+func WithLimitPolicy(policy storage.LimitPolicy) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetLimitPolicy(policy)
+	}
+}
+
+// WithDedupMode sets which existing items a capture is compared against for duplicates
+func WithDedupMode(mode storage.DedupMode) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetDedupMode(mode, storage.DefaultDedupWindow)
+	}
+}
+
+// WithPermissions sets the policy the Manager enforces
+func WithPermissions(permissions Permissions) ManagerOption {
+	return func(m *Manager) {
+		m.permissions = permissions
+	}
+}
+
+// WithWriter replaces the platform clipboard writer, for tests and the harness in panotest
+func WithWriter(writer Writer) ManagerOption {
+	return func(m *Manager) {
+		if writer != nil {
+			m.writer = writer
+		}
+	}
+}
+
+// WithManagerClock replaces the clock of the Manager and its database (timestamps,
+// the grace window, retention ages and passphrase lockouts), for tests
+func WithManagerClock(now func() time.Time) ManagerOption {
+	return func(m *Manager) {
+		if now != nil {
+			m.db.SetClock(now)
+			m.protect.now = now
+		}
+	}
+}
+
+// WithPinLimit sets how many items may be pinned
+func WithPinLimit(limit int) ManagerOption {
+	return func(m *Manager) {
+		m.db.SetPinLimit(limit)
+	}
+}
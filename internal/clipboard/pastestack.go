@@ -0,0 +1,60 @@
+package clipboard
+
+import "fmt"
+
+// A paste stack puts queued items on the clipboard one at a time: the first when the
+// queue starts, the next after each paste. Manager only keeps the queue; deciding that
+// a paste happened is up to the caller (see the UI's Ctrl+V heuristic).
+
+// PasteStackState describes the queue; the zero value means no queue is active
+type PasteStackState struct {
+	Position int // 1-based position of the item on the clipboard
+	Total    int
+}
+
+// Active reports whether a queue is running
+func (s PasteStackState) Active() bool {
+	return s.Total > 0
+}
+
+// pasteStack is the queue state machine (guarded by Manager.stackMu)
+type pasteStack struct {
+	ids []string
+	pos int // Index of the item on the clipboard
+}
+
+// state returns the public view of the queue
+func (s *pasteStack) state() PasteStackState {
+	if len(s.ids) == 0 {
+		return PasteStackState{}
+	}
+	return PasteStackState{Position: s.pos + 1, Total: len(s.ids)}
+}
+
+// reset ends the queue
+func (s *pasteStack) reset() {
+	s.ids = nil
+	s.pos = 0
+}
+
+// copyStackFrom puts the first copyable item at or after index i on the clipboard
+// Items deleted since they were queued are skipped; the queue ends when none is left
+// (caller must hold stackMu)
+func (m *Manager) copyStackFrom(i int) (PasteStackState, error) {
+	var firstErr error
+	for ; i < len(m.stack.ids); i++ {
+		err := m.CopyToClipboard(m.stack.ids[i])
+		if err == nil {
+			m.stack.pos = i
+			return m.stack.state(), nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	m.stack.reset()
+	if firstErr != nil {
+		return PasteStackState{}, fmt.Errorf("no queued item could be copied: %w", firstErr)
+	}
+	return PasteStackState{}, nil
+}
@@ -0,0 +1,82 @@
+package clipboard
+
+import "time"
+
+// DefaultPauseDuration is how long a timed pause lasts before capture resumes by itself
+const DefaultPauseDuration = 5 * time.Minute
+
+// Pause turns capture off until Resume; the poll loop keeps running
+// Content copied while paused is never recorded
+func (m *Monitor) Pause() {
+	m.setPaused(true, 0)
+}
+
+// PauseFor turns capture off and resumes it by itself after d
+// Pausing again or resuming early cancels the timer
+func (m *Monitor) PauseFor(d time.Duration) {
+	m.setPaused(true, d)
+}
+
+// Resume turns capture back on; whatever was copied during the pause is skipped
+func (m *Monitor) Resume() {
+	m.setPaused(false, 0)
+}
+
+// PausedUntil returns when a timed pause ends, zero when not paused or paused until Resume
+func (m *Monitor) PausedUntil() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pausedUntil
+}
+
+// SetOnPauseChange sets the callback for pause transitions, including the automatic resume
+func (m *Monitor) SetOnPauseChange(callback func(paused bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onPauseChange = callback
+}
+
+// setPaused changes the pause state; d > 0 schedules the resume
+func (m *Monitor) setPaused(paused bool, d time.Duration) {
+	m.mu.Lock()
+	changed := m.paused != paused
+	if m.paused && !paused {
+		m.priming = true
+	}
+	m.paused = paused
+	m.stopResumeTimer()
+	if paused && d > 0 {
+		m.pausedUntil = m.now().Add(d)
+		seq := m.pauseSeq
+		m.resumeTimer = time.AfterFunc(d, func() {
+			m.autoResume(seq)
+		})
+	}
+	callback := m.onPauseChange
+	m.mu.Unlock()
+
+	if changed && callback != nil {
+		callback(paused)
+	}
+}
+
+// autoResume ends the timed pause numbered seq, unless it was already replaced
+func (m *Monitor) autoResume(seq uint64) {
+	m.mu.Lock()
+	current := m.paused && m.pauseSeq == seq && !m.closed
+	m.mu.Unlock()
+	if current {
+		m.setPaused(false, 0)
+	}
+}
+
+// stopResumeTimer cancels a scheduled resume (m.mu must be held)
+// The sequence number makes a timer that already fired a no-op
+func (m *Monitor) stopResumeTimer() {
+	if m.resumeTimer != nil {
+		m.resumeTimer.Stop()
+		m.resumeTimer = nil
+	}
+	m.pauseSeq++
+	m.pausedUntil = time.Time{}
+}
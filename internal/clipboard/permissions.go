@@ -0,0 +1,35 @@
+package clipboard
+
+import "errors"
+
+// ErrExportDisabled is returned when the defaults file forbids exporting the history
+var ErrExportDisabled = errors.New("export is disabled by policy")
+
+// Permissions answers what the administrator's defaults file allows
+// The UI, the CLI and the Manager all ask it, so a policy is enforced the same way everywhere
+type Permissions struct {
+	exportDisabled bool
+}
+
+// NewPermissions reads the policy fields of cfg; nil allows everything
+func NewPermissions(cfg *Config) Permissions {
+	if cfg == nil {
+		return Permissions{}
+	}
+	return Permissions{
+		exportDisabled: cfg.DisableExport != nil && *cfg.DisableExport,
+	}
+}
+
+// CanExport reports whether the history may be written out of Pano's data directory
+func (p Permissions) CanExport() bool {
+	return !p.exportDisabled
+}
+
+// CheckExport returns ErrExportDisabled if exports are forbidden
+func (p Permissions) CheckExport() error {
+	if !p.CanExport() {
+		return ErrExportDisabled
+	}
+	return nil
+}
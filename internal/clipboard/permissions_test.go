@@ -0,0 +1,82 @@
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pano/internal/storage"
+)
+
+func TestNewPermissions(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name string
+		cfg  *Config
+		want bool
+	}{
+		{"no defaults file", nil, true},
+		{"not set", &Config{}, true},
+		{"allowed", &Config{DisableExport: &no}, true},
+		{"disabled", &Config{DisableExport: &yes}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPermissions(tt.cfg)
+			if p.CanExport() != tt.want {
+				t.Errorf("CanExport() = %v, want %v", p.CanExport(), tt.want)
+			}
+			if err := p.CheckExport(); (err == nil) != tt.want || (err != nil && !errors.Is(err, ErrExportDisabled)) {
+				t.Errorf("CheckExport() = %v", err)
+			}
+		})
+	}
+}
+
+// A manager built from a defaults file that disables exports refuses both kinds, writes
+// nothing and logs each attempt
+func TestExportDisabled(t *testing.T) {
+	_, db := newTestMonitor(t, &fakeReader{})
+	if err := db.AddItem("text", []byte("gizli")); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	cfg, err := LoadConfig(writeConfig(t, `{"disable_export": true}`))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	manager := NewManager(db, cfg.ManagerOptions()...)
+	if manager.Permissions().CanExport() {
+		t.Fatal("the manager allows exports")
+	}
+
+	path := filepath.Join(t.TempDir(), "yedek.pano")
+	if err := manager.Export(path, "parola"); !errors.Is(err, ErrExportDisabled) {
+		t.Errorf("Export gave %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := manager.ExportV1(&buf); !errors.Is(err, ErrExportDisabled) {
+		t.Errorf("ExportV1 gave %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("ExportV1 wrote %d bytes", buf.Len())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the refused export left a file: %v", err)
+	}
+
+	entries, err := db.AuditEntries()
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	denied := 0
+	for _, entry := range entries {
+		if entry.Op == storage.AuditExportDenied {
+			denied++
+		}
+	}
+	if denied != 2 {
+		t.Errorf("%d denied exports logged, want 2", denied)
+	}
+}
@@ -0,0 +1,40 @@
+package clipboard
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"sync"
+)
+
+// Captures are encoded with BestSpeed: a 4K screenshot takes a fraction of the default
+// level's time, which matters because encoding runs on the poll goroutine. The database
+// recompresses stored images in the background (see storage/recompress.go).
+
+// pngBufferPool lets consecutive encodes reuse the encoder's scratch buffers
+type pngBufferPool struct {
+	pool sync.Pool
+}
+
+func (p *pngBufferPool) Get() *png.EncoderBuffer {
+	if b, ok := p.pool.Get().(*png.EncoderBuffer); ok {
+		return b
+	}
+	return nil
+}
+
+func (p *pngBufferPool) Put(b *png.EncoderBuffer) {
+	p.pool.Put(b)
+}
+
+// captureEncoder encodes clipboard images at capture time
+var captureEncoder = png.Encoder{CompressionLevel: png.BestSpeed, BufferPool: &pngBufferPool{}}
+
+// encodeCapturePNG encodes an image for storage, favoring speed over size
+func encodeCapturePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := captureEncoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
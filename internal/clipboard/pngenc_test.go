@@ -0,0 +1,55 @@
+package clipboard
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// screenshot4K draws a 3840x2160 desktop-like image: a flat background with a band of
+// photo-like noise, as a window showing a picture would have
+func screenshot4K() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 3840, 2160))
+	seed := uint32(1)
+	for y := range 2160 {
+		for x := range 3840 {
+			c := color.NRGBA{R: 240, G: 240, B: 245, A: 255}
+			if y > 400 && y < 1200 {
+				seed = seed*1664525 + 1013904223
+				c = color.NRGBA{R: uint8(x / 16), G: uint8(y / 8), B: uint8(seed >> 28), A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// BenchmarkCapturePNG compares the capture encoder with png.Encode's default level, on
+// the poll goroutine's budget for a 4K screenshot
+func BenchmarkCapturePNG(b *testing.B) {
+	img := screenshot4K()
+	b.Run("best speed", func(b *testing.B) {
+		b.ReportAllocs()
+		var data []byte
+		for b.Loop() {
+			var err error
+			if data, err = encodeCapturePNG(img); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(len(data)), "png-bytes")
+	})
+	b.Run("default", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf bytes.Buffer
+		for b.Loop() {
+			buf.Reset()
+			if err := png.Encode(&buf, img); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(buf.Len()), "png-bytes")
+	})
+}
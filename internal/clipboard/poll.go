@@ -0,0 +1,55 @@
+package clipboard
+
+import "time"
+
+// The poll loop checks the clipboard every poll interval, which the user can tune
+// between MinPollInterval and MaxPollInterval. While nothing is copied it backs off:
+// after every IdleBackoffAfter without a change the interval doubles, up to
+// IdlePollCeiling, and the next change brings it back to the configured value. A copy
+// made while backed off is still captured, only up to the longer interval later.
+
+const (
+	MinPollInterval = 100 * time.Millisecond
+	MaxPollInterval = 2 * time.Second
+
+	// IdleBackoffAfter is how long the clipboard must stay unchanged for each doubling
+	IdleBackoffAfter = 60 * time.Second
+	// IdlePollCeiling bounds the backed-off interval; slower configured intervals don't back off
+	IdlePollCeiling = 2 * time.Second
+)
+
+// SetPollInterval sets how often the clipboard is checked, clamped to
+// MinPollInterval..MaxPollInterval; the poll loop picks it up right away
+func (m *Monitor) SetPollInterval(interval time.Duration) {
+	interval = min(max(interval, MinPollInterval), MaxPollInterval)
+	m.mu.Lock()
+	m.pollInterval = interval
+	m.mu.Unlock()
+	m.wakeLoop()
+}
+
+// PollInterval returns the configured poll interval, without idle backoff
+func (m *Monitor) PollInterval() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pollInterval
+}
+
+// noteChange records that the clipboard changed, ending any idle backoff
+func (m *Monitor) noteChange() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changedAt = m.now()
+}
+
+// idleInterval returns the poll interval after the clipboard stayed unchanged for idle
+func idleInterval(base, idle time.Duration) time.Duration {
+	if base >= IdlePollCeiling {
+		return base
+	}
+	interval := base
+	for waited := IdleBackoffAfter; idle >= waited && interval < IdlePollCeiling; waited += IdleBackoffAfter {
+		interval *= 2
+	}
+	return min(interval, IdlePollCeiling)
+}
@@ -0,0 +1,140 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"pano/internal/storage"
+)
+
+// Protected items are read only through CopyProtected, which checks the passphrase.
+// Wrong passphrases are rate-limited per item: after protectFreeAttempts failures each
+// further one locks the item for twice as long as the last, up to protectMaxLockout.
+// Every failure, and every attempt made while locked out, goes to the audit log.
+
+const (
+	protectFreeAttempts = 3
+	protectBaseLockout  = 30 * time.Second
+	protectMaxLockout   = 15 * time.Minute
+)
+
+// LockoutError is returned while an item is locked after too many wrong passphrases
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("too many wrong passphrases, locked until %s", e.Until.Format(time.TimeOnly))
+}
+
+// protectLimiter counts wrong passphrases per item
+type protectLimiter struct {
+	mu       sync.Mutex
+	failures map[string]int
+	until    map[string]time.Time
+	now      func() time.Time
+}
+
+// check returns a *LockoutError if id is locked out
+func (l *protectLimiter) check(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until, ok := l.until[id]; ok && l.clock().Before(until) {
+		return &LockoutError{Until: until}
+	}
+	return nil
+}
+
+// fail records a wrong passphrase for id and locks it once the free attempts are used
+func (l *protectLimiter) fail(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.failures == nil {
+		l.failures = make(map[string]int)
+		l.until = make(map[string]time.Time)
+	}
+	l.failures[id]++
+	if over := l.failures[id] - protectFreeAttempts; over >= 0 {
+		lockout := protectMaxLockout
+		if over < 8 {
+			lockout = min(protectBaseLockout<<over, protectMaxLockout)
+		}
+		l.until[id] = l.clock().Add(lockout)
+	}
+}
+
+// succeed clears the count of id
+func (l *protectLimiter) succeed(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, id)
+	delete(l.until, id)
+}
+
+func (l *protectLimiter) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
+
+// readableItem returns an item and its content, refusing protected items
+func (m *Manager) readableItem(id string) (*storage.ClipboardItem, []byte, error) {
+	item, content, err := m.db.GetItem(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item.IsProtected() {
+		storage.Zero(content)
+		return nil, nil, storage.ErrProtected
+	}
+	return item, content, nil
+}
+
+// Protect sets a passphrase on an item; its preview is masked from then on
+func (m *Manager) Protect(id, passphrase string) error {
+	return m.db.Protect(id, passphrase)
+}
+
+// Unprotect removes an item's passphrase; wrong ones count like in CopyProtected
+func (m *Manager) Unprotect(id, passphrase string) error {
+	if err := m.checkPassphrase(id, passphrase); err != nil {
+		return err
+	}
+	return m.db.Unprotect(id, passphrase)
+}
+
+// CopyProtected copies a protected item to the system clipboard after checking its
+// passphrase; returns storage.ErrWrongPassphrase or a *LockoutError if it can't
+func (m *Manager) CopyProtected(id, passphrase string) error {
+	if err := m.checkPassphrase(id, passphrase); err != nil {
+		return err
+	}
+	item, content, err := m.db.GetItem(id)
+	if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+	defer storage.Zero(content)
+
+	return m.writeItem(item, content, m.GetNewlineMode())
+}
+
+// checkPassphrase verifies passphrase for the protected item id under the rate limit
+func (m *Manager) checkPassphrase(id, passphrase string) error {
+	if err := m.protect.check(id); err != nil {
+		m.db.RecordUnlockDenied(id)
+		return err
+	}
+	err := m.db.CheckPassphrase(id, passphrase)
+	if errors.Is(err, storage.ErrWrongPassphrase) {
+		m.protect.fail(id)
+		m.db.RecordUnlockDenied(id)
+		return err
+	}
+	if err == nil {
+		m.protect.succeed(id)
+	}
+	return err
+}
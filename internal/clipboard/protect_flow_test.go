@@ -0,0 +1,60 @@
+package clipboard_test
+
+import (
+	"errors"
+	"testing"
+
+	"pano/internal/clipboard"
+	"pano/internal/panotest"
+	"pano/internal/storage"
+)
+
+// A protected item is copied only with its passphrase, and not at all while locked out
+// after wrong ones; each refusal is logged
+func TestCopyProtected(t *testing.T) {
+	h := panotest.New(t)
+	h.Copy("gizli")
+	id := h.DB.GetAllItems()[0].ID
+	if err := h.Manager.Protect(id, "parola"); err != nil {
+		t.Fatalf("failed to protect: %v", err)
+	}
+	writes := len(h.Clipboard.Writes())
+
+	if err := h.Manager.CopyToClipboard(id); !errors.Is(err, storage.ErrProtected) {
+		t.Errorf("a plain copy gave %v", err)
+	}
+	for range 3 {
+		if err := h.Manager.CopyProtected(id, "yanlış"); !errors.Is(err, storage.ErrWrongPassphrase) {
+			t.Fatalf("a wrong passphrase gave %v", err)
+		}
+	}
+	var lockout *clipboard.LockoutError
+	if err := h.Manager.CopyProtected(id, "parola"); !errors.As(err, &lockout) {
+		t.Errorf("the right passphrase while locked out gave %v", err)
+	}
+	if got := len(h.Clipboard.Writes()); got != writes {
+		t.Fatalf("%d writes to the clipboard before the item was opened", got-writes)
+	}
+
+	h.Advance(lockout.Until.Sub(h.Clock.Now()))
+	if err := h.Manager.CopyProtected(id, "parola"); err != nil {
+		t.Fatalf("failed to copy after the lockout: %v", err)
+	}
+	if got := h.Clipboard.Writes(); len(got) != writes+1 || got[len(got)-1].Text != "gizli" {
+		t.Errorf("clipboard writes are %+v", got[writes:])
+	}
+
+	entries, err := h.DB.AuditEntries()
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	denied := 0
+	for _, entry := range entries {
+		if entry.Op == storage.AuditUnlockDenied {
+			denied++
+		}
+	}
+	if denied != 4 {
+		t.Errorf("%d refusals logged, want 4", denied)
+	}
+}
@@ -0,0 +1,43 @@
+package clipboard
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Three wrong passphrases are free; each one after locks the item twice as long as the
+// last, up to protectMaxLockout, and a right one clears the count
+func TestProtectLimiter(t *testing.T) {
+	now := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	l := &protectLimiter{now: func() time.Time { return now }}
+
+	for range protectFreeAttempts - 1 {
+		l.fail("a")
+		if err := l.check("a"); err != nil {
+			t.Fatalf("locked within the free attempts: %v", err)
+		}
+	}
+	want := protectBaseLockout
+	for range 8 {
+		l.fail("a")
+		var lockout *LockoutError
+		if err := l.check("a"); !errors.As(err, &lockout) || !lockout.Until.Equal(now.Add(want)) {
+			t.Fatalf("after a wrong passphrase got %v, want locked for %v", err, want)
+		}
+		if err := l.check("b"); err != nil {
+			t.Fatalf("another item is locked too: %v", err)
+		}
+		now = now.Add(want)
+		if err := l.check("a"); err != nil {
+			t.Fatalf("still locked after %v: %v", want, err)
+		}
+		want = min(2*want, protectMaxLockout)
+	}
+
+	l.succeed("a")
+	l.fail("a")
+	if err := l.check("a"); err != nil {
+		t.Errorf("a right passphrase didn't clear the count: %v", err)
+	}
+}
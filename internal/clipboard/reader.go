@@ -0,0 +1,105 @@
+package clipboard
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"image"
+	"unicode/utf16"
+
+	"github.com/atotto/clipboard"
+)
+
+// The poll loop runs several times a second, and almost every poll sees the same
+// content. A Reader lets it decide that cheaply: the sequence number answers without
+// opening the clipboard where the platform has one, and fingerprints hash the raw
+// clipboard bytes in place. Content is only copied, decoded and encoded once its
+// fingerprint is new.
+
+// Fingerprint identifies clipboard content of one type
+type Fingerprint = [sha256.Size]byte
+
+// Reader is how the monitor reads the clipboard; WithReader swaps it for tests, benchmarks
+// and the fake clipboard in panotest
+type Reader interface {
+	// Sequence returns a number that changes with every clipboard change
+	// ok is false where the platform has none; content is then fingerprinted on every poll
+	Sequence() (seq uint32, ok bool)
+	// ImageFingerprint hashes the raw image on the clipboard, false when there is none
+	ImageFingerprint() (Fingerprint, bool)
+	// TextFingerprint returns TextFingerprint of the clipboard text, false when there is none
+	TextFingerprint() (Fingerprint, bool)
+	// FilesFingerprint hashes the list of files copied in Explorer, false when there is none
+	FilesFingerprint() (Fingerprint, bool)
+	// ReadImagePNG returns the clipboard image encoded as PNG
+	ReadImagePNG() ([]byte, error)
+	// ReadText returns the clipboard text
+	ReadText() (string, error)
+	// ReadFiles returns the paths of the files on the clipboard
+	ReadFiles() ([]string, error)
+	// ReadHTML returns the CF_HTML header of the clipboard, for the page a copy came from
+	ReadHTML() ([]byte, error)
+	// ReadRTF returns the Rich Text Format word processors copy along with the text
+	ReadRTF() ([]byte, error)
+	// Owner returns the executable name of the program that put the content on the
+	// clipboard, "" if unknown
+	Owner() string
+}
+
+// Writer is how the Manager writes the clipboard; WithWriter swaps it for tests
+type Writer interface {
+	// WriteText puts text on the clipboard
+	WriteText(text string) error
+	// WriteRichText puts text on the clipboard together with its Rich Text Format
+	WriteRichText(text string, rtf []byte) error
+	// WriteImage puts an image on the clipboard
+	WriteImage(img image.Image) error
+	// WriteFiles puts files on the clipboard, for pasting in Explorer
+	WriteFiles(paths []string) error
+}
+
+// platformWriter writes the system clipboard
+type platformWriter struct{}
+
+func (platformWriter) WriteText(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+func (platformWriter) WriteRichText(text string, rtf []byte) error {
+	return WriteClipboardRichText(text, rtf)
+}
+
+func (platformWriter) WriteImage(img image.Image) error {
+	return WriteClipboardImage(img)
+}
+
+func (platformWriter) WriteFiles(paths []string) error {
+	return WriteClipboardFiles(paths)
+}
+
+// TextFingerprint hashes text as UTF-16LE, the form Windows keeps it in, so the
+// clipboard buffer can be hashed without converting it
+func TextFingerprint(text string) Fingerprint {
+	h := sha256.New()
+	var buf [256]byte
+	n := 0
+	for _, r := range text {
+		if n+4 > len(buf) {
+			h.Write(buf[:n])
+			n = 0
+		}
+		if utf16.RuneLen(r) == 2 {
+			r1, r2 := utf16.EncodeRune(r)
+			binary.LittleEndian.PutUint16(buf[n:], uint16(r1))
+			binary.LittleEndian.PutUint16(buf[n+2:], uint16(r2))
+			n += 4
+		} else {
+			binary.LittleEndian.PutUint16(buf[n:], uint16(r))
+			n += 2
+		}
+	}
+	h.Write(buf[:n])
+
+	var fp Fingerprint
+	h.Sum(fp[:0])
+	return fp
+}
@@ -0,0 +1,84 @@
+package clipboard
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// Pano writes the clipboard itself when an item is copied; the poll loop would then
+// store that write again as a duplicate capture. Those writes are recorded here and
+// skipped by the monitor. The window that has focus can't tell them apart from copies
+// the user makes with Ctrl+C in Pano's own windows (both happen with Pano focused), so
+// only this registry decides: a change nobody recorded is always captured.
+
+// selfWriteTTL bounds how long a recorded write waits to be seen by the poll loop
+const selfWriteTTL = 2 * time.Second
+
+// selfWrite is one clipboard write made by Pano
+type selfWrite struct {
+	itemType string
+	hash     [sha256.Size]byte // Text and files; images are read back re-encoded, so any image change matches
+	at       time.Time
+}
+
+// writeRegistry remembers recent clipboard writes made by Pano
+type writeRegistry struct {
+	mu      sync.Mutex
+	entries []selfWrite
+	now     func() time.Time
+}
+
+// ownWrites is shared by writeContent and the monitor
+var ownWrites = newWriteRegistry()
+
+// newWriteRegistry creates an empty registry
+func newWriteRegistry() *writeRegistry {
+	return &writeRegistry{now: time.Now}
+}
+
+// record remembers a write; call it before writing, so the poll loop can't see the
+// change before it is recorded
+func (r *writeRegistry) record(itemType string, content []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.expire(now)
+	entry := selfWrite{itemType: itemType, at: now}
+	if itemType != "image" {
+		entry.hash = sha256.Sum256(content)
+	}
+	r.entries = append(r.entries, entry)
+}
+
+// take reports whether a clipboard change is a recorded write, consuming the record
+// Also used to drop the record of a write that failed
+func (r *writeRegistry) take(itemType string, content []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expire(r.now())
+	var hash [sha256.Size]byte
+	if itemType != "image" {
+		hash = sha256.Sum256(content)
+	}
+	for i, entry := range r.entries {
+		if entry.itemType == itemType && entry.hash == hash {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// expire drops writes older than selfWriteTTL (caller must hold lock)
+func (r *writeRegistry) expire(now time.Time) {
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		if now.Sub(entry.at) < selfWriteTTL {
+			kept = append(kept, entry)
+		}
+	}
+	r.entries = kept
+}
@@ -0,0 +1,55 @@
+//go:build ci
+// +build ci
+
+package clipboard_test
+
+import (
+	"testing"
+
+	"pano/internal/panotest"
+)
+
+// TestSelfWriteFocus puts the same text on the clipboard once through the manager and
+// once as the user's Ctrl+C, with Pano's window focused or not: only the manager's
+// write is skipped, whichever window has focus
+func TestSelfWriteFocus(t *testing.T) {
+	tests := []struct {
+		name    string
+		focused string // Program with the focused window
+		manager bool   // Written by Manager.CopyToClipboard rather than copied by the user
+		want    []string
+	}{
+		{"pano focused, manager write", "pano.exe", true, []string{"b", "a"}},
+		{"pano focused, user copy", "pano.exe", false, []string{"a", "b"}},
+		{"other focused, manager write", "notepad.exe", true, []string{"b", "a"}},
+		{"other focused, user copy", "notepad.exe", false, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := panotest.New(t)
+			h.Clipboard.Set(panotest.Change{Text: "a", App: "Code.exe"})
+			h.Poll()
+			h.Clipboard.Set(panotest.Change{Text: "b", App: "Code.exe"})
+			h.Poll()
+
+			h.Clipboard.Focus(tt.focused)
+			if tt.manager {
+				if err := h.Manager.CopyToClipboard(h.Must("a").ID); err != nil {
+					t.Fatalf("failed to copy: %v", err)
+				}
+			} else {
+				// Selecting the text in the detail view and pressing Ctrl+C
+				h.Clipboard.SetText("a")
+			}
+			h.Poll()
+
+			h.ExpectTexts(tt.want...)
+			if !tt.manager {
+				app, err := h.Manager.GetItemSourceApp(h.Must("a").ID)
+				if err != nil || app != tt.focused {
+					t.Errorf("user copy came from %q (%v), want %q", app, err, tt.focused)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	shell32        = windows.NewLazySystemDLL("shell32.dll")
+	dragQueryFileW = shell32.NewProc("DragQueryFileW")
+)
+
+const (
+	CF_HDROP = 15 // Files copied in Explorer, a DROPFILES block
+
+	// dropFilesSize is the size of the DROPFILES header in front of the paths
+	dropFilesSize = 20
+
+	// dragQueryCount asks DragQueryFileW for the number of files
+	dragQueryCount = 0xFFFFFFFF
+)
+
+// ReadClipboardFiles returns the paths of the files copied in Explorer
+func ReadClipboardFiles() ([]string, error) {
+	if err := openClipboardWithRetry(); err != nil {
+		return nil, err
+	}
+	defer closeClipboard.Call()
+
+	if ret, _, _ := isClipboardFormatAvailable.Call(CF_HDROP); ret == 0 {
+		return nil, fmt.Errorf("no files available in clipboard")
+	}
+	handle, _, err := getClipboardData.Call(CF_HDROP)
+	if handle == 0 {
+		return nil, fmt.Errorf("failed to get clipboard data: %v", err)
+	}
+
+	count, _, _ := dragQueryFileW.Call(handle, dragQueryCount, 0, 0)
+	if count == 0 {
+		return nil, fmt.Errorf("no files available in clipboard")
+	}
+	paths := make([]string, 0, count)
+	for i := uintptr(0); i < count; i++ {
+		// Called without a buffer it returns the length of the path, without the NUL
+		length, _, _ := dragQueryFileW.Call(handle, i, 0, 0)
+		if length == 0 {
+			continue
+		}
+		buf := make([]uint16, length+1)
+		dragQueryFileW.Call(handle, i, uintptr(unsafe.Pointer(&buf[0])), length+1)
+		paths = append(paths, windows.UTF16ToString(buf))
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files available in clipboard")
+	}
+	return paths, nil
+}
+
+// WriteClipboardFiles puts files on the clipboard the way Explorer copies them
+func WriteClipboardFiles(paths []string) error {
+	data := dropFiles(paths)
+
+	if err := openClipboardWithRetry(); err != nil {
+		return err
+	}
+	defer closeClipboard.Call()
+
+	ret, _, _ := emptyClipboard.Call()
+	if ret == 0 {
+		return fmt.Errorf("failed to empty clipboard")
+	}
+
+	handle, _, err := globalAlloc.Call(GMEM_MOVEABLE, uintptr(len(data)))
+	if handle == 0 {
+		return fmt.Errorf("failed to allocate global memory: %v", err)
+	}
+	ptr, _, err := globalLock.Call(handle)
+	if ptr == 0 {
+		globalFree.Call(handle)
+		return fmt.Errorf("failed to lock memory: %v", err)
+	}
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(ptr)), len(data)), data)
+	globalUnlock.Call(handle)
+
+	ret, _, err = setClipboardData.Call(CF_HDROP, handle)
+	if ret == 0 {
+		globalFree.Call(handle)
+		return fmt.Errorf("failed to set clipboard data: %v", err)
+	}
+	// Clipboard now owns the handle, don't free it
+	return nil
+}
+
+// dropFiles builds a DROPFILES block: the header, then each path as NUL-terminated
+// UTF-16, then one more NUL ending the list
+func dropFiles(paths []string) []byte {
+	var units []uint16
+	for _, path := range paths {
+		units = append(units, utf16.Encode([]rune(path))...)
+		units = append(units, 0)
+	}
+	units = append(units, 0)
+
+	data := make([]byte, dropFilesSize+2*len(units))
+	binary.LittleEndian.PutUint32(data[0:], dropFilesSize) // pFiles: offset of the paths
+	binary.LittleEndian.PutUint32(data[16:], 1)            // fWide: the paths are UTF-16
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(data[dropFilesSize+2*i:], unit)
+	}
+	return data
+}
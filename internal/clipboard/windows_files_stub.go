@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package clipboard
+
+import "fmt"
+
+// ReadClipboardFiles is a stub for non-Windows platforms
+func ReadClipboardFiles() ([]string, error) {
+	return nil, fmt.Errorf("file clipboard support is only available on Windows")
+}
+
+// WriteClipboardFiles is a stub for non-Windows platforms
+func WriteClipboardFiles(paths []string) error {
+	return fmt.Errorf("file clipboard support is only available on Windows")
+}
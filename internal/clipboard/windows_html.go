@@ -0,0 +1,61 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var registerClipboardFormatW = user32.NewProc("RegisterClipboardFormatW")
+
+// htmlFormatName is the registered clipboard format browsers use for CF_HTML
+const htmlFormatName = "HTML Format"
+
+// ReadClipboardHTML returns the raw CF_HTML block (header and markup) from the clipboard
+func ReadClipboardHTML() ([]byte, error) {
+	name, err := windows.UTF16PtrFromString(htmlFormatName)
+	if err != nil {
+		return nil, err
+	}
+	format, _, err := registerClipboardFormatW.Call(uintptr(unsafe.Pointer(name)))
+	if format == 0 {
+		return nil, fmt.Errorf("failed to register HTML clipboard format: %v", err)
+	}
+
+	if err := openClipboardWithRetry(); err != nil {
+		return nil, err
+	}
+	defer closeClipboard.Call()
+
+	if ret, _, _ := isClipboardFormatAvailable.Call(format); ret == 0 {
+		return nil, fmt.Errorf("no HTML format available in clipboard")
+	}
+
+	handle, _, err := getClipboardData.Call(format)
+	if handle == 0 {
+		return nil, fmt.Errorf("failed to get clipboard data: %v", err)
+	}
+
+	ptr, _, err := globalLock.Call(handle)
+	if ptr == 0 {
+		return nil, fmt.Errorf("failed to lock memory: %v", err)
+	}
+	defer globalUnlock.Call(handle)
+
+	size, _, _ := globalSize.Call(handle)
+	if size == 0 {
+		return nil, fmt.Errorf("invalid clipboard data size")
+	}
+
+	// Only the header is needed, so large pages are not copied in full
+	if size > cfhtmlMaxHeader {
+		size = cfhtmlMaxHeader
+	}
+	data := make([]byte, size)
+	copy(data, (*[1 << 30]byte)(unsafe.Pointer(ptr))[:size:size])
+	return data, nil
+}
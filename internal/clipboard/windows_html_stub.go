@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package clipboard
+
+import "fmt"
+
+// ReadClipboardHTML is a stub for non-Windows platforms
+func ReadClipboardHTML() ([]byte, error) {
+	return nil, fmt.Errorf("HTML clipboard support is only available on Windows")
+}
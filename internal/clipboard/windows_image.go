@@ -8,8 +8,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"image"
-	"image/color"
-	"image/png"
 	"time"
 	"unsafe"
 
@@ -45,21 +43,6 @@ const (
 	GMEM_MOVEABLE = 0x0002
 )
 
-// BITMAPINFOHEADER structure for DIB format
-type bitmapInfoHeader struct {
-	Size          uint32
-	Width         int32
-	Height        int32
-	Planes        uint16
-	BitCount      uint16
-	Compression   uint32
-	ImageSize     uint32
-	XPelsPerMeter int32
-	YPelsPerMeter int32
-	ClrUsed       uint32
-	ClrImportant  uint32
-}
-
 // ReadClipboardImage reads an image from Windows clipboard
 // This function is only available on Windows
 func ReadClipboardImage() (image.Image, error) {
@@ -79,6 +62,7 @@ func openClipboardWithRetry() error {
 }
 
 // readClipboardImage reads an image from Windows clipboard (internal)
+// CF_DIBV5 is preferred: it is what carries the alpha channel of transparent images
 func readClipboardImage() (image.Image, error) {
 	// Open clipboard with retry
 	if err := openClipboardWithRetry(); err != nil {
@@ -86,18 +70,13 @@ func readClipboardImage() (image.Image, error) {
 	}
 	defer closeClipboard.Call()
 
-	// Check if DIB format is available
-	ret, _, _ := isClipboardFormatAvailable.Call(CF_DIB)
-	if ret == 0 {
-		// Try DIBV5
-		ret, _, _ = isClipboardFormatAvailable.Call(CF_DIBV5)
-		if ret == 0 {
-			return nil, fmt.Errorf("no image format available in clipboard")
-		}
+	format, ok := availableImageFormat()
+	if !ok {
+		return nil, fmt.Errorf("no image format available in clipboard")
 	}
 
 	// Get clipboard data handle
-	handle, _, err := getClipboardData.Call(CF_DIB)
+	handle, _, err := getClipboardData.Call(format)
 	if handle == 0 {
 		return nil, fmt.Errorf("failed to get clipboard data: %v", err)
 	}
@@ -119,98 +98,22 @@ func readClipboardImage() (image.Image, error) {
 	data := make([]byte, size)
 	copy(data, (*[1 << 30]byte)(unsafe.Pointer(ptr))[:size:size])
 
-	// Parse BITMAPINFOHEADER
-	if len(data) < 40 {
-		return nil, fmt.Errorf("clipboard data too short")
-	}
-
-	header := &bitmapInfoHeader{}
-	reader := bytes.NewReader(data[:40])
-	if err := binary.Read(reader, binary.LittleEndian, header); err != nil {
-		return nil, fmt.Errorf("failed to read bitmap header: %v", err)
-	}
-
-	// Create image from DIB data
-	img, err := dibToImage(data, header)
+	img, err := parseDIB(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert DIB to image: %v", err)
 	}
-
 	return img, nil
 }
 
-// dibToImage converts DIB (Device Independent Bitmap) data to Go image.Image
-func dibToImage(data []byte, header *bitmapInfoHeader) (image.Image, error) {
-	width := int(header.Width)
-	height := int(header.Height)
-	if height < 0 {
-		height = -height // Top-down DIB
-	}
-
-	// Calculate offset to pixel data
-	offset := 40 + int(header.ClrUsed)*4 // Header size + color table
-
-	// Create RGBA image
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
-
-	// Calculate row size (must be aligned to 4 bytes)
-	rowSize := ((width*int(header.BitCount) + 31) / 32) * 4
-
-	if len(data) < offset+rowSize*height {
-		return nil, fmt.Errorf("insufficient data for image")
-	}
-
-	// Copy pixel data (DIB is stored bottom-up, we need top-down)
-	pixelData := data[offset:]
-
-	if header.BitCount == 32 {
-		// 32-bit RGBA
-		for y := 0; y < height; y++ {
-			srcY := height - 1 - y // Flip vertically
-			for x := 0; x < width; x++ {
-				idx := srcY*rowSize + x*4
-				if idx+3 < len(pixelData) {
-					// BGR to RGB conversion (Windows stores as BGR)
-					img.Set(x, y, color.RGBA{
-						R: pixelData[idx+2],
-						G: pixelData[idx+1],
-						B: pixelData[idx],
-						A: pixelData[idx+3],
-					})
-				}
-			}
+// availableImageFormat returns CF_DIBV5 if the clipboard has it, else CF_DIB (clipboard must be open)
+// Windows synthesizes each from the other, but a synthesized CF_DIB drops the alpha channel
+func availableImageFormat() (uintptr, bool) {
+	for _, format := range []uintptr{CF_DIBV5, CF_DIB} {
+		if ret, _, _ := isClipboardFormatAvailable.Call(format); ret != 0 {
+			return format, true
 		}
-	} else if header.BitCount == 24 {
-		// 24-bit RGB
-		for y := 0; y < height; y++ {
-			srcY := height - 1 - y // Flip vertically
-			for x := 0; x < width; x++ {
-				idx := srcY*rowSize + x*3
-				if idx+2 < len(pixelData) {
-					// BGR to RGB conversion
-					img.Set(x, y, color.RGBA{
-						R: pixelData[idx+2],
-						G: pixelData[idx+1],
-						B: pixelData[idx],
-						A: 255,
-					})
-				}
-			}
-		}
-	} else {
-		return nil, fmt.Errorf("unsupported bit depth: %d", header.BitCount)
 	}
-
-	return img, nil
-}
-
-// imageToPNG converts image.Image to PNG bytes
-func imageToPNG(img image.Image) ([]byte, error) {
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+	return 0, false
 }
 
 // WriteClipboardImage writes an image to Windows clipboard
@@ -12,3 +12,8 @@ import (
 func ReadClipboardImage() (image.Image, error) {
 	return nil, fmt.Errorf("image clipboard support is only available on Windows")
 }
+
+// WriteClipboardImage is a stub for non-Windows platforms
+func WriteClipboardImage(img image.Image) error {
+	return fmt.Errorf("image clipboard support is only available on Windows")
+}
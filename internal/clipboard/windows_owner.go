@@ -0,0 +1,44 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+import (
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	getClipboardOwner   = user32.NewProc("GetClipboardOwner")
+	getForegroundWindow = user32.NewProc("GetForegroundWindow")
+)
+
+// clipboardOwnerName returns the executable name of the process owning the clipboard
+// Programs that put content without an owner window leave it to the foreground window,
+// which is where a copy is almost always made
+func clipboardOwnerName() string {
+	hwnd, _, _ := getClipboardOwner.Call()
+	if hwnd == 0 {
+		hwnd, _, _ = getForegroundWindow.Call()
+	}
+	if hwnd == 0 {
+		return ""
+	}
+	var pid uint32
+	if _, err := windows.GetWindowThreadProcessId(windows.HWND(hwnd), &pid); err != nil || pid == 0 {
+		return ""
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(process)
+
+	buf := make([]uint16, windows.MAX_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(process, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	return filepath.Base(windows.UTF16ToString(buf[:size]))
+}
@@ -0,0 +1,142 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"unsafe"
+
+	"github.com/atotto/clipboard"
+)
+
+var getClipboardSequenceNumber = user32.NewProc("GetClipboardSequenceNumber")
+
+const CF_UNICODETEXT = 13 // UTF-16 text with a terminating NUL
+
+// windowsReader hashes clipboard memory in place and reads content only when asked
+type windowsReader struct{}
+
+// newPlatformReader returns the reader the monitor uses by default
+func newPlatformReader() Reader {
+	return windowsReader{}
+}
+
+// Sequence returns the clipboard sequence number; it is 0 without clipboard access
+func (windowsReader) Sequence() (uint32, bool) {
+	seq, _, _ := getClipboardSequenceNumber.Call()
+	return uint32(seq), seq != 0
+}
+
+// ImageFingerprint hashes the DIB bytes without decoding them, in the format ReadImagePNG reads
+func (windowsReader) ImageFingerprint() (Fingerprint, bool) {
+	var fp Fingerprint
+	ok := withClipboardData(func(data []byte) bool {
+		fp = sha256.Sum256(data)
+		return true
+	}, CF_DIBV5, CF_DIB)
+	return fp, ok
+}
+
+// TextFingerprint hashes the UTF-16 buffer up to its terminating NUL
+func (windowsReader) TextFingerprint() (Fingerprint, bool) {
+	var fp Fingerprint
+	ok := withClipboardData(func(data []byte) bool {
+		end := 0
+		for end+1 < len(data) && (data[end] != 0 || data[end+1] != 0) {
+			end += 2
+		}
+		if end == 0 {
+			return false
+		}
+		fp = sha256.Sum256(data[:end])
+		return true
+	}, CF_UNICODETEXT)
+	return fp, ok
+}
+
+// FilesFingerprint hashes the DROPFILES block, which holds the paths in full
+func (windowsReader) FilesFingerprint() (Fingerprint, bool) {
+	var fp Fingerprint
+	ok := withClipboardData(func(data []byte) bool {
+		fp = sha256.Sum256(data)
+		return true
+	}, CF_HDROP)
+	return fp, ok
+}
+
+// ReadImagePNG decodes the DIB and encodes it as PNG
+func (windowsReader) ReadImagePNG() ([]byte, error) {
+	img, err := readClipboardImage()
+	if err != nil {
+		return nil, err
+	}
+	data, err := encodeCapturePNG(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return data, nil
+}
+
+// ReadText returns the clipboard text
+func (windowsReader) ReadText() (string, error) {
+	return clipboard.ReadAll()
+}
+
+// ReadFiles returns the copied files, see ReadClipboardFiles
+func (windowsReader) ReadFiles() ([]string, error) {
+	return ReadClipboardFiles()
+}
+
+// ReadHTML returns the CF_HTML header, see ReadClipboardHTML
+func (windowsReader) ReadHTML() ([]byte, error) {
+	return ReadClipboardHTML()
+}
+
+// ReadRTF returns the Rich Text Format, see ReadClipboardRTF
+func (windowsReader) ReadRTF() ([]byte, error) {
+	return ReadClipboardRTF()
+}
+
+// Owner returns the program owning the clipboard, see clipboardOwnerName
+func (windowsReader) Owner() string {
+	return clipboardOwnerName()
+}
+
+// withClipboardData calls fn with the locked clipboard memory of the first available
+// format, without copying it; the slice is only valid during the call
+// Returns fn's result, false when no format is available or the clipboard can't be read
+func withClipboardData(fn func(data []byte) bool, formats ...uintptr) bool {
+	if err := openClipboardWithRetry(); err != nil {
+		return false
+	}
+	defer closeClipboard.Call()
+
+	format := uintptr(0)
+	for _, candidate := range formats {
+		if ret, _, _ := isClipboardFormatAvailable.Call(candidate); ret != 0 {
+			format = candidate
+			break
+		}
+	}
+	if format == 0 {
+		return false
+	}
+
+	handle, _, _ := getClipboardData.Call(format)
+	if handle == 0 {
+		return false
+	}
+	ptr, _, _ := globalLock.Call(handle)
+	if ptr == 0 {
+		return false
+	}
+	defer globalUnlock.Call(handle)
+
+	size, _, _ := globalSize.Call(handle)
+	if size == 0 {
+		return false
+	}
+	return fn(unsafe.Slice((*byte)(unsafe.Pointer(ptr)), size))
+}
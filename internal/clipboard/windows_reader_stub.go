@@ -0,0 +1,65 @@
+//go:build !windows
+// +build !windows
+
+package clipboard
+
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+)
+
+// genericReader reads text through the cross-platform clipboard package
+// There is no sequence number, and images and files are only supported on Windows
+type genericReader struct{}
+
+// newPlatformReader returns the reader the monitor uses by default
+func newPlatformReader() Reader {
+	return genericReader{}
+}
+
+func (genericReader) Sequence() (uint32, bool) {
+	return 0, false
+}
+
+func (genericReader) ImageFingerprint() (Fingerprint, bool) {
+	return Fingerprint{}, false
+}
+
+// TextFingerprint has to read the text in full; only Windows can hash it in place
+func (genericReader) TextFingerprint() (Fingerprint, bool) {
+	text, err := clipboard.ReadAll()
+	if err != nil || text == "" {
+		return Fingerprint{}, false
+	}
+	return TextFingerprint(text), true
+}
+
+func (genericReader) FilesFingerprint() (Fingerprint, bool) {
+	return Fingerprint{}, false
+}
+
+func (genericReader) ReadImagePNG() ([]byte, error) {
+	return nil, fmt.Errorf("image clipboard support is only available on Windows")
+}
+
+func (genericReader) ReadText() (string, error) {
+	return clipboard.ReadAll()
+}
+
+func (genericReader) ReadFiles() ([]string, error) {
+	return ReadClipboardFiles()
+}
+
+func (genericReader) ReadHTML() ([]byte, error) {
+	return ReadClipboardHTML()
+}
+
+func (genericReader) ReadRTF() ([]byte, error) {
+	return ReadClipboardRTF()
+}
+
+// Owner is unknown; the clipboard packages used here don't tell who wrote the content
+func (genericReader) Owner() string {
+	return ""
+}
@@ -0,0 +1,143 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"pano/internal/storage"
+)
+
+var enumClipboardFormats = user32.NewProc("EnumClipboardFormats")
+
+// rtfFormatName is the registered clipboard format WordPad and Word copy formatting in
+const rtfFormatName = "Rich Text Format"
+
+// rtfFormat returns the clipboard format ID of RTF, registering it on first use
+func rtfFormat() (uintptr, error) {
+	name, err := windows.UTF16PtrFromString(rtfFormatName)
+	if err != nil {
+		return 0, err
+	}
+	format, _, err := registerClipboardFormatW.Call(uintptr(unsafe.Pointer(name)))
+	if format == 0 {
+		return 0, fmt.Errorf("failed to register RTF clipboard format: %v", err)
+	}
+	return format, nil
+}
+
+// clipboardFormats lists the formats on the clipboard in the order the owner put
+// them there (clipboard must be open)
+func clipboardFormats() []uintptr {
+	var formats []uintptr
+	format := uintptr(0)
+	for {
+		format, _, _ = enumClipboardFormats.Call(format)
+		if format == 0 {
+			return formats
+		}
+		formats = append(formats, format)
+	}
+}
+
+// ReadClipboardRTF returns the Rich Text Format on the clipboard, without its
+// terminating NUL
+func ReadClipboardRTF() ([]byte, error) {
+	format, err := rtfFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := openClipboardWithRetry(); err != nil {
+		return nil, err
+	}
+	defer closeClipboard.Call()
+
+	if !slices.Contains(clipboardFormats(), format) {
+		return nil, fmt.Errorf("no RTF format available in clipboard")
+	}
+
+	handle, _, err := getClipboardData.Call(format)
+	if handle == 0 {
+		return nil, fmt.Errorf("failed to get clipboard data: %v", err)
+	}
+	ptr, _, err := globalLock.Call(handle)
+	if ptr == 0 {
+		return nil, fmt.Errorf("failed to lock memory: %v", err)
+	}
+	defer globalUnlock.Call(handle)
+
+	size, _, _ := globalSize.Call(handle)
+	if size == 0 {
+		return nil, fmt.Errorf("invalid clipboard data size")
+	}
+	if size > storage.MaxItemSize {
+		return nil, fmt.Errorf("RTF data too large (%d bytes)", size)
+	}
+
+	// The block may be larger than the RTF, which ends at the first NUL
+	data := unsafe.Slice((*byte)(unsafe.Pointer(ptr)), size)
+	if end := bytes.IndexByte(data, 0); end >= 0 {
+		data = data[:end]
+	}
+	return bytes.Clone(data), nil
+}
+
+// WriteClipboardRichText puts text and its RTF on the clipboard in one go, so programs
+// that paste formatting take the RTF and all others the text
+func WriteClipboardRichText(text string, rtf []byte) error {
+	format, err := rtfFormat()
+	if err != nil {
+		return err
+	}
+
+	units := append(utf16.Encode([]rune(text)), 0)
+	textData := unsafe.Slice((*byte)(unsafe.Pointer(&units[0])), 2*len(units))
+	rtfData := append(bytes.Clone(rtf), 0)
+	defer storage.Zero(rtfData)
+
+	if err := openClipboardWithRetry(); err != nil {
+		return err
+	}
+	defer closeClipboard.Call()
+
+	ret, _, _ := emptyClipboard.Call()
+	if ret == 0 {
+		return fmt.Errorf("failed to empty clipboard")
+	}
+	if err := setClipboardBytes(CF_UNICODETEXT, textData); err != nil {
+		return err
+	}
+	return setClipboardBytes(format, rtfData)
+}
+
+// setClipboardBytes copies data into global memory and hands it to the clipboard as
+// format (clipboard must be open and emptied)
+func setClipboardBytes(format uintptr, data []byte) error {
+	handle, _, err := globalAlloc.Call(GMEM_MOVEABLE, uintptr(len(data)))
+	if handle == 0 {
+		return fmt.Errorf("failed to allocate global memory: %v", err)
+	}
+	ptr, _, err := globalLock.Call(handle)
+	if ptr == 0 {
+		globalFree.Call(handle)
+		return fmt.Errorf("failed to lock memory: %v", err)
+	}
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(ptr)), len(data)), data)
+	globalUnlock.Call(handle)
+
+	ret, _, err := setClipboardData.Call(format, handle)
+	if ret == 0 {
+		globalFree.Call(handle)
+		return fmt.Errorf("failed to set clipboard data: %v", err)
+	}
+	// Clipboard now owns the handle, don't free it
+	return nil
+}
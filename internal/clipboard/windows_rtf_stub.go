@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package clipboard
+
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+)
+
+// ReadClipboardRTF is a stub for non-Windows platforms
+func ReadClipboardRTF() ([]byte, error) {
+	return nil, fmt.Errorf("RTF clipboard support is only available on Windows")
+}
+
+// WriteClipboardRichText writes only the text on non-Windows platforms
+func WriteClipboardRichText(text string, rtf []byte) error {
+	return clipboard.WriteAll(text)
+}
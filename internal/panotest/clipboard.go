@@ -0,0 +1,226 @@
+package panotest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"slices"
+	"strings"
+	"sync"
+
+	"pano/internal/clipboard"
+)
+
+// errEmpty is returned when the fake clipboard has nothing of the asked type
+var errEmpty = errors.New("clipboard is empty")
+
+// Change is one clipboard content: text, a PNG image or copied files, and the page and
+// program it came from
+type Change struct {
+	Text      string
+	PNG       []byte
+	Files     []string // Paths of files copied in Explorer
+	RTF       []byte   // Formatting copied along with Text, nil for none
+	SourceURL string   // Written as a CF_HTML header, "" for none
+	App       string   // Reported as the clipboard owner, "" for unknown
+}
+
+// FakeClipboard is an in-memory clipboard for the monitor (clipboard.Reader) and the
+// manager (clipboard.Writer)
+// Every Set, Repeat and write gets a new sequence number, like a copy on Windows.
+// Scripted changes are applied one at a time by Next.
+type FakeClipboard struct {
+	mu         sync.Mutex
+	seq        uint32
+	current    Change
+	script     []Change
+	writes     []Change // What the manager wrote, oldest first
+	foreground string   // Program with the focused window, see Focus
+}
+
+// NewFakeClipboard creates an empty clipboard
+func NewFakeClipboard() *FakeClipboard {
+	return &FakeClipboard{seq: 1}
+}
+
+// Set puts content on the clipboard, as a copy in another program would
+func (c *FakeClipboard) Set(change Change) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = change
+	c.seq++
+}
+
+// SetText copies text
+func (c *FakeClipboard) SetText(text string) {
+	c.Set(Change{Text: text})
+}
+
+// Repeat copies the current content again, as Ctrl+C twice or a program re-setting it
+func (c *FakeClipboard) Repeat() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+}
+
+// Script queues changes for Next
+func (c *FakeClipboard) Script(changes ...Change) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.script = append(c.script, changes...)
+}
+
+// Next applies the next scripted change; false when the script is done
+func (c *FakeClipboard) Next() bool {
+	c.mu.Lock()
+	if len(c.script) == 0 {
+		c.mu.Unlock()
+		return false
+	}
+	next := c.script[0]
+	c.script = c.script[1:]
+	c.mu.Unlock()
+
+	c.Set(next)
+	return true
+}
+
+// Writes returns what the manager wrote to the clipboard, oldest first
+func (c *FakeClipboard) Writes() []Change {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Change(nil), c.writes...)
+}
+
+func (c *FakeClipboard) Sequence() (uint32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seq, true
+}
+
+func (c *FakeClipboard) ImageFingerprint() (clipboard.Fingerprint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.current.PNG) == 0 {
+		return clipboard.Fingerprint{}, false
+	}
+	return sha256.Sum256(c.current.PNG), true
+}
+
+func (c *FakeClipboard) TextFingerprint() (clipboard.Fingerprint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.current.Text == "" {
+		return clipboard.Fingerprint{}, false
+	}
+	return clipboard.TextFingerprint(c.current.Text), true
+}
+
+// FilesFingerprint hashes the paths joined by NULs
+func (c *FakeClipboard) FilesFingerprint() (clipboard.Fingerprint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.current.Files) == 0 {
+		return clipboard.Fingerprint{}, false
+	}
+	return sha256.Sum256([]byte(strings.Join(c.current.Files, "\x00"))), true
+}
+
+func (c *FakeClipboard) ReadImagePNG() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.current.PNG) == 0 {
+		return nil, errEmpty
+	}
+	return bytes.Clone(c.current.PNG), nil
+}
+
+func (c *FakeClipboard) ReadText() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current.Text, nil
+}
+
+func (c *FakeClipboard) ReadFiles() ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.current.Files) == 0 {
+		return nil, errEmpty
+	}
+	return slices.Clone(c.current.Files), nil
+}
+
+// ReadHTML returns a CF_HTML header naming the source URL of the current content
+func (c *FakeClipboard) ReadHTML() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.current.SourceURL == "" {
+		return nil, errEmpty
+	}
+	return []byte("Version:0.9\r\nSourceURL:" + c.current.SourceURL + "\r\n<html></html>"), nil
+}
+
+func (c *FakeClipboard) ReadRTF() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.current.RTF) == 0 {
+		return nil, errEmpty
+	}
+	return bytes.Clone(c.current.RTF), nil
+}
+
+// Focus makes app the program with the focused window; content set without an App,
+// including the manager's writes, is reported as coming from it
+func (c *FakeClipboard) Focus(app string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.foreground = app
+}
+
+// Owner returns the program the current content was set by, or the focused program
+// when the content doesn't say, as on Windows
+func (c *FakeClipboard) Owner() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.current.App == "" {
+		return c.foreground
+	}
+	return c.current.App
+}
+
+func (c *FakeClipboard) WriteText(text string) error {
+	c.write(Change{Text: text})
+	return nil
+}
+
+func (c *FakeClipboard) WriteRichText(text string, rtf []byte) error {
+	c.write(Change{Text: text, RTF: bytes.Clone(rtf)})
+	return nil
+}
+
+// WriteImage stores img as PNG; the monitor reads it back like an image copied elsewhere
+func (c *FakeClipboard) WriteImage(img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	c.write(Change{PNG: buf.Bytes()})
+	return nil
+}
+
+func (c *FakeClipboard) WriteFiles(paths []string) error {
+	c.write(Change{Files: slices.Clone(paths)})
+	return nil
+}
+
+// write replaces the content with change and records it
+func (c *FakeClipboard) write(change Change) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = change
+	c.seq++
+	c.writes = append(c.writes, change)
+}
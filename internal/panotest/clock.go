@@ -0,0 +1,43 @@
+package panotest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a clock that only moves when told to
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a clock standing at start
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current fake time; pass it where a func() time.Time is wanted
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Ticking returns a clock func that moves c forward by d after every reading, standing
+// in for work that takes d between two looks at the clock
+func (c *Clock) Ticking(d time.Duration) func() time.Time {
+	return func() time.Time {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		now := c.now
+		c.now = c.now.Add(d)
+		return now
+	}
+}
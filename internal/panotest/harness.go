@@ -0,0 +1,179 @@
+// Package panotest runs the monitor, the manager and the database together against a
+// fake clipboard, a fake clock and a throwaway data directory, so behaviors that
+// interact (dedup windows, retention sweeps, limits, copy-back suppression) can be
+// exercised headlessly. Scenarios in scenarios.go are run by RunScenarios.
+package panotest
+
+import (
+	"errors"
+	"time"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+)
+
+// Start is where a new harness's clock stands
+var Start = time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+
+// rejectWait bounds how long ExpectReject waits for the monitor's reject callback,
+// which runs on its own goroutine
+const rejectWait = 2 * time.Second
+
+// TB is the part of testing.TB the harness uses, so the package doesn't import testing
+// outside its tests
+type TB interface {
+	Helper()
+	Setenv(key, value string)
+	TempDir() string
+	Cleanup(f func())
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Harness is one isolated Pano: database, manager and monitor wired to fakes
+// The monitor's loop isn't started; Poll runs one of its checks
+type Harness struct {
+	TB        TB
+	Clock     *Clock
+	Clipboard *FakeClipboard
+	DB        *storage.Database
+	Manager   *clipboard.Manager
+	Monitor   *clipboard.Monitor
+
+	rejects chan error // Captures the monitor couldn't store, see ExpectReject
+}
+
+// New creates a harness with its data in a temp directory of tb; it is closed when tb ends
+// The database writes on every change, so nothing depends on the save timer
+func New(tb TB) *Harness {
+	tb.Helper()
+	storage.UseTempDataDir(tb)
+
+	db, err := storage.NewDatabase()
+	if err != nil {
+		tb.Fatalf("failed to open database: %v", err)
+	}
+	db.SetSaveDelay(0)
+
+	h := &Harness{
+		TB:        tb,
+		Clock:     NewClock(Start),
+		Clipboard: NewFakeClipboard(),
+		DB:        db,
+		rejects:   make(chan error, 64),
+	}
+	h.Manager = clipboard.NewManager(db,
+		clipboard.WithWriter(h.Clipboard),
+		clipboard.WithManagerClock(h.Clock.Now))
+	h.Monitor = clipboard.NewMonitor(db,
+		clipboard.WithReader(h.Clipboard),
+		clipboard.WithClock(h.Clock.Now))
+	h.Monitor.SetOnReject(func(err error) {
+		select {
+		case h.rejects <- err:
+		default:
+		}
+	})
+
+	tb.Cleanup(h.Close)
+	return h
+}
+
+// Close stops the monitor and closes the database
+func (h *Harness) Close() {
+	if err := h.Monitor.Close(); err != nil {
+		h.TB.Errorf("failed to close monitor: %v", err)
+	}
+	if err := h.DB.Close(); err != nil {
+		h.TB.Errorf("failed to close database: %v", err)
+	}
+}
+
+// Poll runs one check of the monitor's poll loop
+func (h *Harness) Poll() {
+	h.Monitor.Poll()
+}
+
+// Copy puts text on the clipboard and polls once
+func (h *Harness) Copy(text string) {
+	h.Clipboard.SetText(text)
+	h.Poll()
+}
+
+// Advance moves the clock forward by d
+func (h *Harness) Advance(d time.Duration) {
+	h.Clock.Advance(d)
+}
+
+// ExpectReject fails the scenario unless the monitor refused a capture for reason
+func (h *Harness) ExpectReject(reason storage.RejectReason) {
+	h.TB.Helper()
+	select {
+	case err := <-h.rejects:
+		var reject *storage.RejectError
+		if !errors.As(err, &reject) || reject.Reason != reason {
+			h.TB.Fatalf("capture refused with %v, want reason %d", err, reason)
+		}
+	case <-time.After(rejectWait):
+		h.TB.Fatalf("no capture was refused, want reason %d", reason)
+	}
+}
+
+// Texts returns the content of the text items as shown: pinned first, newest first
+func (h *Harness) Texts() []string {
+	h.TB.Helper()
+	texts := make([]string, 0)
+	for _, item := range h.DB.GetAllItems() {
+		if item.Type != "text" {
+			continue
+		}
+		_, content, err := h.DB.GetItem(item.ID)
+		if err != nil {
+			h.TB.Fatalf("failed to read item %s: %v", item.ID, err)
+		}
+		texts = append(texts, string(content))
+	}
+	return texts
+}
+
+// Find returns the text item with content text
+func (h *Harness) Find(text string) (storage.ClipboardItem, bool) {
+	h.TB.Helper()
+	for _, item := range h.DB.GetAllItems() {
+		if item.Type != "text" {
+			continue
+		}
+		_, content, err := h.DB.GetItem(item.ID)
+		if err != nil {
+			h.TB.Fatalf("failed to read item %s: %v", item.ID, err)
+		}
+		if string(content) == text {
+			return item, true
+		}
+	}
+	return storage.ClipboardItem{}, false
+}
+
+// Must fails the scenario if text isn't in the history and returns its item
+func (h *Harness) Must(text string) storage.ClipboardItem {
+	h.TB.Helper()
+	item, ok := h.Find(text)
+	if !ok {
+		h.TB.Fatalf("%q is not in the history: %q", text, h.Texts())
+	}
+	return item
+}
+
+// ExpectTexts fails the scenario unless the text items are want, in order
+func (h *Harness) ExpectTexts(want ...string) {
+	h.TB.Helper()
+	got := h.Texts()
+	if len(got) != len(want) {
+		h.TB.Fatalf("history has %d items %q, want %d %q", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			h.TB.Fatalf("history is %q, want %q", got, want)
+		}
+	}
+}
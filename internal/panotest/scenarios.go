@@ -0,0 +1,640 @@
+package panotest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+)
+
+// Scenario is one end-to-end behavior checked on a fresh harness
+type Scenario struct {
+	Name string
+	Run  func(h *Harness)
+}
+
+// Scenarios are the behaviors the harness covers
+var Scenarios = []Scenario{
+	{Name: "burst capture", Run: burstCapture},
+	{Name: "repeated copy stays one item", Run: repeatedCopy},
+	{Name: "full history evicts oldest unpinned", Run: fullHistoryEvicts},
+	{Name: "full history refuses captures", Run: fullHistoryRefuses},
+	{Name: "lowered limit evicts oldest unpinned", Run: loweredLimitEvicts},
+	{Name: "grace window outlasts a lowered limit", Run: graceWindowBurst},
+	{Name: "size cap", Run: sizeCap},
+	{Name: "retention expiry during burst", Run: retentionDuringBurst},
+	{Name: "copy-back suppression", Run: copyBackSuppression},
+	{Name: "image copy-back suppression", Run: imageCopyBack},
+	{Name: "copy-back counts uses", Run: copyBackCountsUses},
+	{Name: "batch delete", Run: batchDelete},
+	{Name: "trash restore and purge", Run: trashRestorePurge},
+	{Name: "trash is outside the limit", Run: trashOutsideLimit},
+	{Name: "paused capture is forgotten", Run: pausedCapture},
+	{Name: "timed pause resumes by itself", Run: timedPause},
+	{Name: "copies from excluded apps are skipped", Run: excludedApp},
+	{Name: "forced capture while locked", Run: lockedForcedCapture},
+	{Name: "recent dedup window", Run: recentDedupWindow},
+	{Name: "double copy pins", Run: doubleCopyPins},
+	{Name: "clipboard loop is suppressed", Run: loopSuppressed},
+	{Name: "source URL is kept", Run: sourceURLKept},
+	{Name: "source app is kept", Run: sourceAppKept},
+	{Name: "user title survives a recopy", Run: userTitleKept},
+	{Name: "edit replaces an older duplicate", Run: editReplacesDuplicate},
+	{Name: "pages follow the list order", Run: pagesFollowOrder},
+	{Name: "copied files are kept as paths", Run: copiedFiles},
+	{Name: "RTF is copied back with the text", Run: rtfKept},
+	{Name: "slow redaction drops the capture", Run: slowRedaction},
+}
+
+// burstCapture copies many distinct texts in quick succession; every one is kept
+func burstCapture(h *Harness) {
+	want := make([]string, 0, 25)
+	for i := range 25 {
+		text := fmt.Sprintf("burst %d", i)
+		h.Copy(text)
+		h.Advance(50 * time.Millisecond)
+		want = append([]string{text}, want...)
+	}
+	h.ExpectTexts(want...)
+}
+
+// repeatedCopy copies the same text again; it moves to the top instead of duplicating
+func repeatedCopy(h *Harness) {
+	h.Copy("a")
+	h.Clipboard.Repeat()
+	h.Poll()
+	h.Copy("b")
+	h.Copy("a")
+	h.ExpectTexts("a", "b")
+}
+
+// fullHistoryEvicts copies past the item limit; the oldest unpinned item makes room,
+// even inside the grace window, and pinned items stay
+func fullHistoryEvicts(h *Harness) {
+	h.DB.SetMaxItems(10)
+	h.Copy("keep")
+	if err := h.Manager.PinItem(h.Must("keep").ID); err != nil {
+		h.TB.Fatalf("failed to pin: %v", err)
+	}
+	want := []string{}
+	for i := range 12 {
+		text := fmt.Sprintf("item %d", i)
+		h.Copy(text)
+		want = append([]string{text}, want...)
+	}
+	h.ExpectTexts(append([]string{"keep"}, want[:10]...)...)
+}
+
+// fullHistoryRefuses copies past the item limit with LimitReject; nothing is evicted
+// to make room, the capture is refused instead
+func fullHistoryRefuses(h *Harness) {
+	h.DB.SetLimitPolicy(storage.LimitReject)
+	h.DB.SetMaxItems(10)
+	want := []string{}
+	for i := range 10 {
+		text := fmt.Sprintf("item %d", i)
+		h.Copy(text)
+		want = append([]string{text}, want...)
+	}
+	h.Copy("one too many")
+	h.ExpectReject(storage.RejectLimitFull)
+	h.ExpectTexts(want...)
+}
+
+// loweredLimitEvicts lowers the item limit below the history; pinned items survive
+// without taking a slot, the oldest unpinned go
+func loweredLimitEvicts(h *Harness) {
+	h.DB.SetGraceWindow(0)
+	h.Copy("keep")
+	if err := h.Manager.PinItem(h.Must("keep").ID); err != nil {
+		h.TB.Fatalf("failed to pin: %v", err)
+	}
+	want := []string{}
+	for i := range 15 {
+		h.Advance(time.Minute)
+		text := fmt.Sprintf("item %d", i)
+		h.Copy(text)
+		want = append([]string{text}, want...)
+	}
+
+	h.DB.SetMaxItems(10)
+	h.ExpectTexts(append([]string{"keep"}, want[:10]...)...)
+}
+
+// graceWindowBurst lowers the limit right after a burst; the burst is kept until it
+// ages out of the grace window, then the next capture trims it and, with LimitReject,
+// is refused
+func graceWindowBurst(h *Harness) {
+	h.DB.SetLimitPolicy(storage.LimitReject)
+	for i := range 15 {
+		h.Copy(fmt.Sprintf("item %d", i))
+		h.Advance(time.Second)
+	}
+	h.DB.SetMaxItems(10)
+	if got := len(h.Texts()); got != 15 {
+		h.TB.Fatalf("burst inside the grace window kept %d items, want 15", got)
+	}
+
+	h.Advance(storage.DefaultGraceWindow)
+	h.Copy("late")
+	h.ExpectReject(storage.RejectLimitFull)
+	texts := h.Texts()
+	if len(texts) != 10 || texts[0] != "item 14" || texts[9] != "item 5" {
+		h.TB.Fatalf("after the grace window the history is %q, want items 14 to 5", texts)
+	}
+}
+
+// sizeCap copies more than the total size cap; the oldest items make room
+func sizeCap(h *Harness) {
+	h.DB.SetMaxTotalSize(1000)
+	for _, c := range "abcd" {
+		h.Copy(strings.Repeat(string(c), 400))
+		h.Advance(time.Second)
+	}
+	h.ExpectTexts(strings.Repeat("d", 400), strings.Repeat("c", 400))
+}
+
+// retentionDuringBurst runs a retention sweep in the middle of a burst; only old
+// unpinned items go
+func retentionDuringBurst(h *Harness) {
+	h.Copy("old")
+	h.Copy("old pinned")
+	if err := h.Manager.PinItem(h.Must("old pinned").ID); err != nil {
+		h.TB.Fatalf("failed to pin: %v", err)
+	}
+	h.Advance(25 * time.Hour)
+
+	h.Copy("new 1")
+	h.Copy("new 2")
+	if pruned := h.Manager.PruneOlderThan(24 * time.Hour); pruned != 1 {
+		h.TB.Fatalf("sweep pruned %d items, want 1", pruned)
+	}
+	h.Copy("new 3")
+	h.ExpectTexts("old pinned", "new 3", "new 2", "new 1")
+}
+
+// copyBackSuppression copies an item back to the clipboard; the monitor doesn't
+// capture Pano's own write
+func copyBackSuppression(h *Harness) {
+	h.Copy("a")
+	h.Copy("b")
+	if err := h.Manager.CopyToClipboard(h.Must("a").ID); err != nil {
+		h.TB.Fatalf("failed to copy: %v", err)
+	}
+	h.Poll()
+
+	if writes := h.Clipboard.Writes(); len(writes) != 1 || writes[0].Text != "a" {
+		h.TB.Fatalf("clipboard writes are %v, want one of \"a\"", writes)
+	}
+	h.ExpectTexts("b", "a")
+}
+
+// copyBackCountsUses copies an item back twice; each copy is counted, captures aren't
+// Something else is copied in between, so the monitor sees both writes as its own
+func copyBackCountsUses(h *Harness) {
+	h.Copy("a")
+	for _, other := range []string{"b", "c"} {
+		h.Copy(other)
+		if err := h.Manager.CopyToClipboard(h.Must("a").ID); err != nil {
+			h.TB.Fatalf("failed to copy: %v", err)
+		}
+		h.Poll()
+	}
+	if uses := h.Must("a").CopyCount; uses != 2 {
+		h.TB.Fatalf("copy count of a is %d, want 2", uses)
+	}
+	if b := h.Must("b"); b.CopyCount != 0 || !b.LastUsed.IsZero() {
+		h.TB.Fatalf("b was never copied back but counts %d uses", b.CopyCount)
+	}
+}
+
+// batchDelete removes several items at once; IDs already gone are skipped
+func batchDelete(h *Harness) {
+	h.Copy("a")
+	h.Copy("b")
+	h.Copy("c")
+	removed, err := h.Manager.DeleteItems([]string{h.Must("a").ID, h.Must("c").ID, "gone"})
+	if err != nil || removed != 2 {
+		h.TB.Fatalf("deleted %d items (%v), want 2", removed, err)
+	}
+	h.ExpectTexts("b")
+}
+
+// trashRestorePurge deletes an item, restores it, then lets the trash retention purge it
+func trashRestorePurge(h *Harness) {
+	h.Copy("a")
+	h.Copy("b")
+	id := h.Must("a").ID
+	if err := h.Manager.DeleteItem(id); err != nil {
+		h.TB.Fatalf("failed to delete: %v", err)
+	}
+	h.ExpectTexts("b")
+	if err := h.Manager.RestoreItem(id); err != nil {
+		h.TB.Fatalf("failed to restore: %v", err)
+	}
+	h.ExpectTexts("b", "a")
+
+	if err := h.Manager.DeleteItem(id); err != nil {
+		h.TB.Fatalf("failed to delete: %v", err)
+	}
+	if purged, err := h.Manager.PurgeDeleted(storage.TrashRetention); err != nil || purged != 0 {
+		h.TB.Fatalf("purged %d items (%v) right after the delete, want 0", purged, err)
+	}
+	h.Advance(storage.TrashRetention + time.Minute)
+	if purged, err := h.Manager.PurgeDeleted(storage.TrashRetention); err != nil || purged != 1 {
+		h.TB.Fatalf("purged %d items (%v), want 1", purged, err)
+	}
+	if err := h.Manager.RestoreItem(id); err == nil {
+		h.TB.Fatalf("restored a purged item")
+	}
+	h.ExpectTexts("b")
+}
+
+// trashOutsideLimit fills the history, deletes an item and copies one more; the trashed
+// item freed its slot, so nothing is evicted
+func trashOutsideLimit(h *Harness) {
+	h.DB.SetMaxItems(10)
+	want := []string{}
+	for i := range 10 {
+		text := fmt.Sprintf("item %d", i)
+		h.Copy(text)
+		want = append([]string{text}, want...)
+	}
+	if err := h.Manager.DeleteItem(h.Must("item 5").ID); err != nil {
+		h.TB.Fatalf("failed to delete: %v", err)
+	}
+	h.Copy("new")
+	want = append([]string{"new"}, want...)
+	h.ExpectTexts(append(want[:5:5], want[6:]...)...)
+	if counts := h.DB.Counts(); counts.Total() != 10 {
+		h.TB.Fatalf("history counts %d items, want 10", counts.Total())
+	}
+}
+
+// imageCopyBack copies an image item back; its re-encoded read isn't stored again
+func imageCopyBack(h *Harness) {
+	h.Clipboard.Set(Change{PNG: testPNG(h, 8, 8)})
+	h.Poll()
+	items := h.DB.GetAllItems()
+	if len(items) != 1 || items[0].Type != "image" {
+		h.TB.Fatalf("history is %v, want one image", items)
+	}
+
+	if err := h.Manager.CopyToClipboard(items[0].ID); err != nil {
+		h.TB.Fatalf("failed to copy: %v", err)
+	}
+	h.Poll()
+	if got := len(h.DB.GetAllItems()); got != 1 {
+		h.TB.Fatalf("history has %d items after the copy back, want 1", got)
+	}
+}
+
+// pausedCapture copies while capture is off; that content isn't stored, not even
+// when capture is turned back on
+func pausedCapture(h *Harness) {
+	h.Monitor.SetPaused(true)
+	h.Copy("secret")
+	h.Monitor.SetPaused(false)
+	h.Poll()
+	h.Copy("after")
+	h.ExpectTexts("after")
+}
+
+// timedPause copies during a short timed pause; capture comes back by itself and the
+// content copied meanwhile stays forgotten
+func timedPause(h *Harness) {
+	h.Monitor.PauseFor(20 * time.Millisecond)
+	h.Copy("secret")
+	deadline := time.Now().Add(2 * time.Second)
+	for h.Monitor.IsPaused() {
+		if time.Now().After(deadline) {
+			h.TB.Fatalf("capture is still paused after the pause ended")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	h.Poll()
+	h.Copy("after")
+	h.ExpectTexts("after")
+}
+
+// excludedApp copies from a password manager, matched without case or path, and then
+// from another program; only the latter is stored, and copying the secret twice doesn't
+// pin the earlier capture
+func excludedApp(h *Harness) {
+	h.Monitor.SetDoubleCopyWindow(time.Second)
+	h.Clipboard.Set(Change{Text: "before", App: "notepad.exe"})
+	h.Poll()
+	h.Clipboard.Set(Change{Text: "secret", App: `C:\Program Files\KeePass\KeePass.exe`})
+	h.Poll()
+	h.Clipboard.Set(Change{Text: "secret", App: `C:\Program Files\KeePass\KeePass.exe`})
+	h.Poll()
+	h.Clipboard.Set(Change{Text: "after", App: "notepad.exe"})
+	h.Poll()
+	h.ExpectTexts("after", "before")
+	if h.Must("before").Pinned {
+		h.TB.Fatalf("copying an excluded app's content twice pinned the previous capture")
+	}
+}
+
+// lockedForcedCapture copies while the session is locked; only a forced capture stores it
+func lockedForcedCapture(h *Harness) {
+	h.Monitor.SetLocked(true)
+	h.Copy("locked")
+	h.ExpectTexts()
+
+	if _, err := h.Monitor.CaptureNow(true); err != nil {
+		h.TB.Fatalf("forced capture failed: %v", err)
+	}
+	if item := h.Must("locked"); !item.Forced {
+		h.TB.Fatalf("forced capture isn't marked as forced")
+	}
+}
+
+// recentDedupWindow copies the same text again after the dedup window; it is a new item
+func recentDedupWindow(h *Harness) {
+	h.DB.SetDedupMode(storage.DedupRecent, time.Hour)
+	h.Copy("a")
+	h.Copy("b")
+	h.Advance(30 * time.Minute)
+	h.Copy("b")
+	h.ExpectTexts("b", "a")
+
+	h.Advance(2 * time.Hour)
+	h.Copy("a")
+	h.ExpectTexts("a", "b", "a")
+}
+
+// doubleCopyPins copies the same text twice within the window; the item gets pinned
+func doubleCopyPins(h *Harness) {
+	h.Monitor.SetDoubleCopyWindow(time.Second)
+	h.Copy("x")
+	h.Advance(300 * time.Millisecond)
+	h.Clipboard.Repeat()
+	h.Poll()
+	if !h.Must("x").Pinned {
+		h.TB.Fatalf("double copy didn't pin")
+	}
+
+	// Too late for the window: stays as it is
+	h.Copy("y")
+	h.Advance(2 * time.Second)
+	h.Clipboard.Repeat()
+	h.Poll()
+	if h.Must("y").Pinned {
+		h.TB.Fatalf("slow second copy pinned")
+	}
+}
+
+// loopSuppressed has two programs bounce content back and forth without any input;
+// after the loop is spotted the history stops growing
+func loopSuppressed(h *Harness) {
+	h.DB.SetDedupMode(storage.DedupOff, 0)
+	bounce := func(rounds int) {
+		for range rounds {
+			h.Copy("ping")
+			h.Advance(100 * time.Millisecond)
+			h.Copy("pong")
+			h.Advance(100 * time.Millisecond)
+		}
+	}
+	bounce(6)
+	spotted := len(h.Texts())
+	if spotted >= 12 {
+		h.TB.Fatalf("loop not spotted, %d items stored", spotted)
+	}
+	bounce(6)
+	if got := len(h.Texts()); got != spotted {
+		h.TB.Fatalf("history grew from %d to %d items during the loop", spotted, got)
+	}
+}
+
+// sourceURLKept copies text from a browser page; the page is stored with the item
+func sourceURLKept(h *Harness) {
+	const page = "https://example.com/article"
+	h.Clipboard.Set(Change{Text: "quote", SourceURL: page})
+	h.Poll()
+	source, err := h.Manager.GetItemSourceURL(h.Must("quote").ID)
+	if err != nil || source != page {
+		h.TB.Fatalf("source URL is %q (%v), want %q", source, err, page)
+	}
+}
+
+// sourceAppKept copies text in an editor; the program is stored with the item and a
+// copy of the same text elsewhere takes over the newer program
+func sourceAppKept(h *Harness) {
+	h.Clipboard.Set(Change{Text: "snippet", App: "Code.exe"})
+	h.Poll()
+	app, err := h.Manager.GetItemSourceApp(h.Must("snippet").ID)
+	if err != nil || app != "Code.exe" {
+		h.TB.Fatalf("source app is %q (%v), want %q", app, err, "Code.exe")
+	}
+
+	h.Clipboard.Set(Change{Text: "other"})
+	h.Poll()
+	h.Clipboard.Set(Change{Text: "snippet", App: "chrome.exe"})
+	h.Poll()
+	app, err = h.Manager.GetItemSourceApp(h.Must("snippet").ID)
+	if err != nil || app != "chrome.exe" {
+		h.TB.Fatalf("source app after copying again is %q (%v), want %q", app, err, "chrome.exe")
+	}
+}
+
+// userTitleKept names an item and copies its content again; the item moves to the top
+// with its title
+func userTitleKept(h *Harness) {
+	h.Copy("SELECT * FROM orders")
+	id := h.Must("SELECT * FROM orders").ID
+	if err := h.Manager.SetTitle(id, "  Siparişler\n sorgusu "); err != nil {
+		h.TB.Fatalf("failed to set title: %v", err)
+	}
+	h.Copy("b")
+	h.Copy("SELECT * FROM orders")
+	h.ExpectTexts("SELECT * FROM orders", "b")
+	title, err := h.Manager.GetItemUserTitle(h.Must("SELECT * FROM orders").ID)
+	if err != nil || title != "Siparişler sorgusu" {
+		h.TB.Fatalf("title = %q (%v), want %q", title, err, "Siparişler sorgusu")
+	}
+}
+
+// editReplacesDuplicate edits an item into the text of another; the edited item moves to
+// the top and the older duplicate goes to the trash. Images can't be edited
+func editReplacesDuplicate(h *Harness) {
+	h.Copy("a")
+	h.Copy("b")
+	h.Copy("c")
+	id := h.Must("a").ID
+	if err := h.Manager.UpdateContent(id, []byte("c")); err != nil {
+		h.TB.Fatalf("failed to edit: %v", err)
+	}
+	h.ExpectTexts("c", "b")
+	if got := h.Must("c").ID; got != id {
+		h.TB.Fatalf("edited text is item %s, want the edited item %s", got, id)
+	}
+
+	h.Clipboard.Set(Change{PNG: testPNG(h, 8, 8)})
+	h.Poll()
+	for _, item := range h.DB.GetAllItems() {
+		if item.Type == "image" {
+			if err := h.Manager.UpdateContent(item.ID, []byte("x")); !errors.Is(err, storage.ErrNotEditable) {
+				h.TB.Fatalf("editing an image returned %v, want %v", err, storage.ErrNotEditable)
+			}
+		}
+	}
+}
+
+// pagesFollowOrder reads the history in pages; together they are the whole list, pinned
+// items first, and trashed items are on none of them
+func pagesFollowOrder(h *Harness) {
+	for i := range 7 {
+		h.Copy(fmt.Sprintf("item %d", i))
+	}
+	if err := h.Manager.PinItem(h.Must("item 2").ID); err != nil {
+		h.TB.Fatalf("failed to pin: %v", err)
+	}
+	if err := h.Manager.DeleteItem(h.Must("item 4").ID); err != nil {
+		h.TB.Fatalf("failed to delete: %v", err)
+	}
+
+	all := h.Manager.GetAllItems()
+	paged := make([]storage.ClipboardItem, 0, len(all))
+	for offset := 0; ; offset += 4 {
+		page, total := h.Manager.GetItems(offset, 4)
+		if total != len(all) {
+			h.TB.Fatalf("GetItems total = %d, want %d", total, len(all))
+		}
+		if len(page) == 0 {
+			break
+		}
+		paged = append(paged, page...)
+	}
+	if len(paged) != len(all) {
+		h.TB.Fatalf("pages hold %d items, want %d", len(paged), len(all))
+	}
+	for i := range all {
+		if paged[i].ID != all[i].ID {
+			h.TB.Fatalf("item %d of the pages is %s, want %s", i, paged[i].ID, all[i].ID)
+		}
+	}
+	if !paged[0].Pinned {
+		h.TB.Fatalf("first page doesn't start with the pinned item")
+	}
+}
+
+// copiedFiles copies files in Explorer; the item keeps their paths, copying it back
+// isn't stored again, and a file deleted since makes the copy fail
+func copiedFiles(h *Harness) {
+	dir := h.TB.TempDir()
+	paths := []string{filepath.Join(dir, "report.pdf"), filepath.Join(dir, "notes.txt")}
+	for _, path := range paths {
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			h.TB.Fatalf("failed to create %s: %v", path, err)
+		}
+	}
+	h.Clipboard.Set(Change{Files: paths})
+	h.Poll()
+	items := h.DB.GetAllItems()
+	if len(items) != 1 || items[0].Type != "files" {
+		h.TB.Fatalf("history is %v, want one files item", items)
+	}
+	preview, err := h.DB.GetPreview(items[0].ID)
+	if err != nil || preview.FileCount != 2 || !slices.Equal(preview.Files, []string{"report.pdf", "notes.txt"}) {
+		h.TB.Fatalf("preview is %+v (%v), want the two file names", preview, err)
+	}
+
+	if err := h.Manager.CopyToClipboard(items[0].ID); err != nil {
+		h.TB.Fatalf("failed to copy: %v", err)
+	}
+	h.Poll()
+	writes := h.Clipboard.Writes()
+	if len(writes) != 1 || !slices.Equal(writes[0].Files, paths) {
+		h.TB.Fatalf("clipboard writes are %v, want the paths", writes)
+	}
+	if got := len(h.DB.GetAllItems()); got != 1 {
+		h.TB.Fatalf("history has %d items after the copy back, want 1", got)
+	}
+
+	if err := os.Remove(paths[1]); err != nil {
+		h.TB.Fatalf("failed to remove %s: %v", paths[1], err)
+	}
+	var missing *clipboard.MissingFilesError
+	err = h.Manager.CopyToClipboard(items[0].ID)
+	if !errors.As(err, &missing) || !slices.Equal(missing.Paths, paths[1:]) {
+		h.TB.Fatalf("copy with a deleted file returned %v, want a MissingFilesError", err)
+	}
+}
+
+// rtfKept copies formatted text from a word processor; the formatting is written back
+// with the text, and editing the text drops it
+func rtfKept(h *Harness) {
+	rtf := []byte(`{\rtf1\ansi {\b bold} text}`)
+	h.Clipboard.Set(Change{Text: "bold text", RTF: rtf})
+	h.Poll()
+	item := h.Must("bold text")
+	if stored, err := h.DB.GetItemRTF(item.ID); err != nil || !bytes.Equal(stored, rtf) {
+		h.TB.Fatalf("stored RTF is %q (%v), want %q", stored, err, rtf)
+	}
+
+	if err := h.Manager.CopyToClipboard(item.ID); err != nil {
+		h.TB.Fatalf("failed to copy: %v", err)
+	}
+	h.Poll()
+	writes := h.Clipboard.Writes()
+	if len(writes) != 1 || writes[0].Text != "bold text" || !bytes.Equal(writes[0].RTF, rtf) {
+		h.TB.Fatalf("clipboard writes are %v, want the text with its RTF", writes)
+	}
+
+	if err := h.Manager.UpdateContent(item.ID, []byte("plain text")); err != nil {
+		h.TB.Fatalf("failed to edit: %v", err)
+	}
+	if stored, err := h.DB.GetItemRTF(item.ID); err != nil || stored != nil {
+		h.TB.Fatalf("edited item still has RTF %q (%v)", stored, err)
+	}
+}
+
+// slowRedaction masks a copy with a pathological rule on a clock that makes every rule
+// look slow; out of time, the capture is refused rather than stored unmasked, and the
+// same copy is masked once rules run at normal speed
+func slowRedaction(h *Harness) {
+	if err := h.DB.SetRedactionRules(storage.ParseRedactionRules("CUST-\\d+ => CUST-████\n(a|aa)*c")); err != nil {
+		h.TB.Fatalf("failed to set rules: %v", err)
+	}
+	// Two rules at 150ms each run past the 200ms redaction budget
+	h.DB.SetClock(h.Clock.Ticking(150 * time.Millisecond))
+	text := strings.Repeat("a", 1<<12) + "c CUST-42"
+	h.Copy(text)
+	h.ExpectReject(storage.RejectRedaction)
+	h.ExpectTexts()
+
+	h.DB.SetClock(h.Clock.Now)
+	h.Clipboard.Repeat()
+	h.Poll()
+	h.ExpectTexts("████ CUST-████")
+}
+
+// testPNG encodes a w×h gradient
+// Encoded without compression, so the copy Pano writes back reads as other bytes, as
+// the re-encoded DIB does on Windows
+func testPNG(h *Harness, w, ht int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, ht))
+	for y := range ht {
+		for x := range w {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 30), G: uint8(y * 30), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.NoCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		h.TB.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
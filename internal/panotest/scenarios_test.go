@@ -0,0 +1,16 @@
+package panotest
+
+import "testing"
+
+// RunScenarios runs every scenario as a subtest, each on its own harness
+func RunScenarios(t *testing.T) {
+	for _, s := range Scenarios {
+		t.Run(s.Name, func(t *testing.T) {
+			s.Run(New(t))
+		})
+	}
+}
+
+func TestScenarios(t *testing.T) {
+	RunScenarios(t)
+}
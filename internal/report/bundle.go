@@ -0,0 +1,131 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Providers supply the parts of a bundle; nil providers are skipped
+// None of them may return item content; the builder only adds what they give it
+type Providers struct {
+	LogFiles    func() []string                   // Paths of the rotated log files, newest first
+	ReadFile    func(path string) ([]byte, error) // Reads a log file
+	Diagnostics func() string                     // Health report text
+	Metrics     func() map[string]int64           // Counters (item counts, sizes), no content
+	Preferences func() map[string]any             // Preference values by key
+	SystemInfo  func() map[string]string          // OS version, DPI, monitor count...
+}
+
+// File is one entry of the bundle
+type File struct {
+	Name string
+	Data []byte
+}
+
+// secretMarkers mark preference keys whose values never leave the machine
+var secretMarkers = []string{"key", "secret", "token", "password", "passphrase", "pin"}
+
+// isSecretKey reports whether a preference key looks like it holds a secret
+// Matched on word parts so "pinned_collapsed" is kept while "pin" or "api_key" are dropped
+func isSecretKey(key string) bool {
+	parts := strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+	for _, part := range parts {
+		for _, marker := range secretMarkers {
+			if part == marker {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SanitizePreferences drops secret-looking keys and returns the remaining values
+func SanitizePreferences(prefs map[string]any) map[string]any {
+	out := make(map[string]any, len(prefs))
+	for key, value := range prefs {
+		if !isSecretKey(key) {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// BuildFiles collects the bundle entries from the providers
+func BuildFiles(p Providers, now time.Time) ([]File, error) {
+	files := make([]File, 0)
+
+	addJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		files = append(files, File{Name: name, Data: data})
+		return nil
+	}
+
+	if p.LogFiles != nil && p.ReadFile != nil {
+		for _, path := range p.LogFiles() {
+			data, err := p.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			files = append(files, File{Name: "logs/" + filepath.Base(path), Data: data})
+		}
+	}
+	if p.Diagnostics != nil {
+		files = append(files, File{Name: "diagnostics.txt", Data: []byte(p.Diagnostics())})
+	}
+	if p.Metrics != nil {
+		if err := addJSON("metrics.json", p.Metrics()); err != nil {
+			return nil, err
+		}
+	}
+	if p.Preferences != nil {
+		if err := addJSON("preferences.json", SanitizePreferences(p.Preferences())); err != nil {
+			return nil, err
+		}
+	}
+	if p.SystemInfo != nil {
+		if err := addJSON("system.json", p.SystemInfo()); err != nil {
+			return nil, err
+		}
+	}
+
+	// A manifest lists what the bundle contains, so users can check before sending it
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	manifest := fmt.Sprintf("Pano hata raporu\nOluşturulma: %s\nVeritabanı ve pano içeriği bu pakete dahil değildir.\n\n%s\n",
+		now.Format(time.RFC3339), strings.Join(names, "\n"))
+	files = append(files, File{Name: "README.txt", Data: []byte(manifest)})
+
+	return files, nil
+}
+
+// WriteZip writes the files as a zip archive
+func WriteZip(w io.Writer, files []File, now time.Time) error {
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", f.Name, err)
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return nil
+}
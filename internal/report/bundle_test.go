@@ -0,0 +1,105 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSanitizePreferences drops keys that look like secrets by their word parts only
+func TestSanitizePreferences(t *testing.T) {
+	prefs := map[string]any{
+		"pinned_collapsed":  true,
+		"pin":               "1234",
+		"api_key":           "abc",
+		"sync.token":        "t",
+		"master-password":   "p",
+		"hotkey":            "Ctrl+Shift+V",
+		"keyboard_shortcut": "x",
+		"max_items":         100,
+	}
+	got := SanitizePreferences(prefs)
+	for _, key := range []string{"pin", "api_key", "sync.token", "master-password"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("%s was kept", key)
+		}
+	}
+	for _, key := range []string{"pinned_collapsed", "hotkey", "keyboard_shortcut", "max_items"} {
+		if got[key] != prefs[key] {
+			t.Errorf("%s was dropped", key)
+		}
+	}
+	if _, ok := prefs["pin"]; !ok {
+		t.Error("sanitizing changed the input")
+	}
+}
+
+// TestBuildFiles builds a bundle from every provider and reads it back from the zip
+func TestBuildFiles(t *testing.T) {
+	now := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	p := Providers{
+		LogFiles: func() []string { return []string{"/logs/pano.log", "/logs/missing.log", "/logs/pano.log.1"} },
+		ReadFile: func(path string) ([]byte, error) {
+			if strings.Contains(path, "missing") {
+				return nil, errors.New("gone")
+			}
+			return []byte("log " + path), nil
+		},
+		Diagnostics: func() string { return "Sağlık: iyi" },
+		Metrics:     func() map[string]int64 { return map[string]int64{"items": 42} },
+		Preferences: func() map[string]any { return map[string]any{"max_items": 100, "api_key": "gizli"} },
+		SystemInfo:  func() map[string]string { return map[string]string{"os": "windows"} },
+	}
+	files, err := BuildFiles(p, now)
+	if err != nil {
+		t.Fatalf("failed to build: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteZip(&buf, files, now); err != nil {
+		t.Fatalf("failed to write zip: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to read zip: %v", err)
+	}
+	got := map[string]string{}
+	var names []string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		got[f.Name] = string(data)
+		names = append(names, f.Name)
+	}
+
+	want := []string{"logs/pano.log", "logs/pano.log.1", "diagnostics.txt", "metrics.json", "preferences.json", "system.json", "README.txt"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("bundle holds %q, want %q", names, want)
+	}
+	if got["logs/pano.log"] != "log /logs/pano.log" || got["diagnostics.txt"] != "Sağlık: iyi" {
+		t.Errorf("log and diagnostics are %q and %q", got["logs/pano.log"], got["diagnostics.txt"])
+	}
+	if strings.Contains(got["preferences.json"], "gizli") || !strings.Contains(got["preferences.json"], "max_items") {
+		t.Errorf("preferences are %s", got["preferences.json"])
+	}
+	readme := got["README.txt"]
+	if !strings.Contains(readme, "2025-03-03T09:00:00Z") || !strings.Contains(readme, "diagnostics.txt\nlogs/pano.log\n") {
+		t.Errorf("manifest is %q", readme)
+	}
+}
+
+// Without providers the bundle is just the manifest
+func TestBuildFilesEmpty(t *testing.T) {
+	files, err := BuildFiles(Providers{}, time.Now())
+	if err != nil || len(files) != 1 || files[0].Name != "README.txt" {
+		t.Errorf("empty bundle is %v, %v", files, err)
+	}
+}
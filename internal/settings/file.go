@@ -0,0 +1,115 @@
+package settings
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileStore keeps preferences in a JSON file, written on every change
+// An empty path keeps values in memory only
+type fileStore struct {
+	path   string
+	mu     sync.Mutex
+	values map[string]any
+}
+
+// newFileStore loads the file if it exists; a missing or damaged file starts empty
+func newFileStore(path string) *fileStore {
+	fs := &fileStore{path: path, values: make(map[string]any)}
+	if path == "" {
+		return fs
+	}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(data, &fs.values); err != nil {
+			log.Printf("Warning: Ignoring damaged settings file: %v", err)
+			fs.values = make(map[string]any)
+		}
+	}
+	return fs
+}
+
+func (fs *fileStore) get(key string) (any, bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	v, ok := fs.values[key]
+	return v, ok
+}
+
+func (fs *fileStore) set(key string, value any) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.values[key] = value
+	if fs.path == "" {
+		return
+	}
+	if err := fs.saveInternal(); err != nil {
+		log.Printf("Warning: Failed to save settings: %v", err)
+	}
+}
+
+// saveInternal writes the file through a temp file (caller must hold lock)
+func (fs *fileStore) saveInternal() error {
+	data, err := json.MarshalIndent(fs.values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fs.path), 0755); err != nil {
+		return err
+	}
+	tmpPath := fs.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, fs.path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func (fs *fileStore) BoolWithFallback(key string, fallback bool) bool {
+	if v, ok := fs.get(key); ok {
+		if b, ok := v.(bool); ok {
+			return b
+		}
+	}
+	return fallback
+}
+
+func (fs *fileStore) SetBool(key string, value bool) {
+	fs.set(key, value)
+}
+
+func (fs *fileStore) IntWithFallback(key string, fallback int) int {
+	if v, ok := fs.get(key); ok {
+		// JSON numbers decode as float64
+		switch n := v.(type) {
+		case float64:
+			return int(n)
+		case int:
+			return n
+		}
+	}
+	return fallback
+}
+
+func (fs *fileStore) SetInt(key string, value int) {
+	fs.set(key, value)
+}
+
+func (fs *fileStore) StringWithFallback(key string, fallback string) string {
+	if v, ok := fs.get(key); ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return fallback
+}
+
+func (fs *fileStore) SetString(key string, value string) {
+	fs.set(key, value)
+}
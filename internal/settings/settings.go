@@ -0,0 +1,135 @@
+package settings
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"pano/internal/storage"
+)
+
+// Backend names reported in diagnostics
+const (
+	BackendFyne = "fyne" // Fyne preferences, with settings.json only read for missing keys
+	BackendFile = "file" // settings.json in the Pano data directory
+)
+
+const probeKey = "pano_settings_probe"
+
+// Settings is the preferences API used by the UI
+// It mirrors fyne.Preferences so either backend can sit behind it
+type Settings interface {
+	BoolWithFallback(key string, fallback bool) bool
+	SetBool(key string, value bool)
+	IntWithFallback(key string, fallback int) int
+	SetInt(key string, value int)
+	StringWithFallback(key string, fallback string) string
+	SetString(key string, value string)
+
+	// Backend returns the name of the backend that receives writes
+	Backend() string
+}
+
+// store is a single preferences backend; fyne.Preferences satisfies it
+type store interface {
+	BoolWithFallback(key string, fallback bool) bool
+	SetBool(key string, value bool)
+	IntWithFallback(key string, fallback int) int
+	SetInt(key string, value int)
+	StringWithFallback(key string, fallback string) string
+	SetString(key string, value string)
+}
+
+// layered writes to primary and reads primary first, then secondary
+type layered struct {
+	primary   store
+	secondary store
+	backend   string
+}
+
+// Open picks the Fyne preferences backend, or the JSON file when Fyne can't persist
+func Open(app fyne.App) Settings {
+	path, err := GetSettingsPath()
+	if err != nil {
+		// Without a data directory the file store only lives in memory
+		log.Printf("Warning: No settings file available: %v", err)
+		path = ""
+	}
+	file := newFileStore(path)
+	prefs := app.Preferences()
+
+	if err := probeFyne(app); err != nil {
+		log.Printf("Warning: Fyne preferences unavailable (%v), using settings file %s", err, path)
+		return &layered{primary: file, secondary: prefs, backend: BackendFile}
+	}
+	log.Printf("Settings backend: %s", BackendFyne)
+	return &layered{primary: prefs, secondary: file, backend: BackendFyne}
+}
+
+// GetSettingsPath returns the full path to the fallback settings file
+func GetSettingsPath() (string, error) {
+	dir, err := storage.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// probeFyne checks that Fyne preferences round-trip and that its storage is writable
+func probeFyne(app fyne.App) error {
+	prefs := app.Preferences()
+	token := strconv.FormatInt(time.Now().UnixNano(), 10)
+	prefs.SetString(probeKey, token)
+	if prefs.StringWithFallback(probeKey, "") != token {
+		return fmt.Errorf("round-trip verification failed")
+	}
+
+	// Fyne saves preferences.json in its storage root and drops write errors
+	root := app.Storage().RootURI()
+	if root == nil || root.Path() == "" {
+		return fmt.Errorf("no storage root")
+	}
+	if err := os.MkdirAll(root.Path(), 0755); err != nil {
+		return fmt.Errorf("storage root not writable: %w", err)
+	}
+	f, err := os.CreateTemp(root.Path(), "probe-*")
+	if err != nil {
+		return fmt.Errorf("storage root not writable: %w", err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+func (l *layered) BoolWithFallback(key string, fallback bool) bool {
+	return l.primary.BoolWithFallback(key, l.secondary.BoolWithFallback(key, fallback))
+}
+
+func (l *layered) SetBool(key string, value bool) {
+	l.primary.SetBool(key, value)
+}
+
+func (l *layered) IntWithFallback(key string, fallback int) int {
+	return l.primary.IntWithFallback(key, l.secondary.IntWithFallback(key, fallback))
+}
+
+func (l *layered) SetInt(key string, value int) {
+	l.primary.SetInt(key, value)
+}
+
+func (l *layered) StringWithFallback(key string, fallback string) string {
+	return l.primary.StringWithFallback(key, l.secondary.StringWithFallback(key, fallback))
+}
+
+func (l *layered) SetString(key string, value string) {
+	l.primary.SetString(key, value)
+}
+
+func (l *layered) Backend() string {
+	return l.backend
+}
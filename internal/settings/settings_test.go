@@ -0,0 +1,118 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"pano/internal/storage"
+)
+
+// TestFileStore writes every type, reopens the file and checks the values survived;
+// values of another type fall back
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Pano", "settings.json")
+	fs := newFileStore(path)
+	fs.SetBool("window_animations", false)
+	fs.SetInt("max_items", 120)
+	fs.SetString("hotkey", "Ctrl+Shift+V")
+
+	reopened := newFileStore(path)
+	if reopened.BoolWithFallback("window_animations", true) {
+		t.Error("bool didn't survive a reopen")
+	}
+	if got := reopened.IntWithFallback("max_items", 0); got != 120 {
+		t.Errorf("int is %d after a reopen, want 120", got)
+	}
+	if got := reopened.StringWithFallback("hotkey", ""); got != "Ctrl+Shift+V" {
+		t.Errorf("string is %q after a reopen", got)
+	}
+	if got := reopened.IntWithFallback("hotkey", 7); got != 7 {
+		t.Errorf("a string read as int gave %d, want the fallback", got)
+	}
+	if got := reopened.StringWithFallback("missing", "yok"); got != "yok" {
+		t.Errorf("missing key gave %q, want the fallback", got)
+	}
+}
+
+// A damaged file is ignored rather than failing the app, and is replaced on the next write
+func TestFileStoreDamaged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(`{"max_items": 12`), 0600); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	fs := newFileStore(path)
+	if got := fs.IntWithFallback("max_items", 50); got != 50 {
+		t.Errorf("damaged file gave %d, want the fallback", got)
+	}
+	fs.SetInt("max_items", 60)
+	if got := newFileStore(path).IntWithFallback("max_items", 0); got != 60 {
+		t.Errorf("rewritten file gave %d, want 60", got)
+	}
+}
+
+// An empty path keeps values in memory and writes nothing
+func TestFileStoreInMemory(t *testing.T) {
+	fs := newFileStore("")
+	fs.SetString("theme", "dark")
+	if got := fs.StringWithFallback("theme", ""); got != "dark" {
+		t.Errorf("in-memory store gave %q", got)
+	}
+}
+
+// TestLayered reads the primary store first and the secondary for keys the primary
+// lacks; writes only go to the primary
+func TestLayered(t *testing.T) {
+	primary, secondary := newFileStore(""), newFileStore("")
+	secondary.SetInt("max_items", 80)
+	secondary.SetBool("paused", true)
+	primary.SetBool("paused", false)
+	l := &layered{primary: primary, secondary: secondary, backend: BackendFile}
+
+	if got := l.IntWithFallback("max_items", 50); got != 80 {
+		t.Errorf("key only in the secondary gave %d, want 80", got)
+	}
+	if l.BoolWithFallback("paused", true) {
+		t.Error("the secondary overrode the primary")
+	}
+	l.SetString("theme", "dark")
+	if secondary.StringWithFallback("theme", "") != "" {
+		t.Error("a write reached the secondary")
+	}
+	if l.Backend() != BackendFile {
+		t.Errorf("backend is %q", l.Backend())
+	}
+}
+
+// TestOpen picks Fyne preferences when they work, with the file still read for keys
+// they lack
+func TestOpen(t *testing.T) {
+	storage.UseTempDataDir(t)
+	path, err := GetSettingsPath()
+	if err != nil {
+		t.Fatalf("failed to locate settings: %v", err)
+	}
+	newFileStore(path).SetInt("max_items", 90)
+
+	s := Open(test.NewTempApp(t))
+	if s.Backend() != BackendFyne {
+		t.Errorf("backend is %q, want %q", s.Backend(), BackendFyne)
+	}
+	if got := s.IntWithFallback("max_items", 50); got != 90 {
+		t.Errorf("file value gave %d, want 90", got)
+	}
+}
+
+// The settings file sits beside the history, in the data directory of the platform
+func TestGetSettingsPath(t *testing.T) {
+	storage.UseTempDataDir(t)
+	dir, err := storage.GetDataDir()
+	if err != nil {
+		t.Fatalf("failed to locate data directory: %v", err)
+	}
+	if path, err := GetSettingsPath(); err != nil || path != filepath.Join(dir, "settings.json") {
+		t.Errorf("settings path is %q, %v, want it in %q", path, err, dir)
+	}
+}
@@ -0,0 +1,325 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	ArchiveFile = "archive.db"
+
+	// archiveMaxLine bounds a single archived record (MaxItemSize after base64 and encryption overhead)
+	archiveMaxLine = MaxItemSize*2 + 64*1024
+)
+
+// Archive is a cold-storage tier for items that fell out of the active history
+// Each line of archive.db is one encrypted ClipboardItem, so archiving is a plain append
+type Archive struct {
+	path     string
+	key      []byte
+	mu       sync.Mutex
+	maxBytes int64 // Optional size cap, 0 means unlimited
+	count    int   // Archived items, -1 until counted
+}
+
+// GetArchivePath returns the full path to the archive file
+func GetArchivePath() (string, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), ArchiveFile), nil
+}
+
+// newArchive creates an archive handle; the file is only opened when used
+func newArchive(key []byte) (*Archive, error) {
+	path, err := GetArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{path: path, key: key, count: -1}, nil
+}
+
+// SetMaxBytes sets the archive size cap (0 disables the cap)
+func (a *Archive) SetMaxBytes(max int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.maxBytes = max
+	a.pruneInternal()
+}
+
+// Size returns the archive file size in bytes
+func (a *Archive) Size() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// Count returns the number of archived items
+// The file is scanned once; later changes keep the count up to date
+func (a *Archive) Count() (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.count >= 0 {
+		return a.count, nil
+	}
+
+	f, err := os.Open(a.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			a.count = 0
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	// Lines are counted without decrypting them; a torn final write counts as an item
+	// until the next rewrite drops it
+	count := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), archiveMaxLine)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read archive: %w", err)
+	}
+	a.count = count
+	return count, nil
+}
+
+// Append stores items at the end of the archive
+// Item content must already be encrypted and stored in full (no deltas)
+func (a *Archive) Append(items []ClipboardItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, item := range items {
+		line, err := a.encodeItem(item)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if a.count >= 0 {
+		a.count += len(items)
+	}
+
+	a.pruneInternal()
+	return nil
+}
+
+// Search returns archived items whose text contains query (case-insensitive, see FoldText), newest first
+// An empty query returns every archived item
+func (a *Archive) Search(query string) ([]ClipboardItem, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	items, err := a.readAllInternal()
+	if err != nil {
+		return nil, err
+	}
+
+	query = FoldText(strings.TrimSpace(query))
+	result := make([]ClipboardItem, 0)
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if query != "" {
+			if item.Type != "text" || item.IsProtected() {
+				continue
+			}
+			content, err := Decrypt(item.Content, a.key)
+			if err != nil {
+				continue
+			}
+			match := strings.Contains(FoldText(string(content)), query)
+			Zero(content)
+			if !match {
+				continue
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// GetItem retrieves and decrypts an archived item by ID
+func (a *Archive) GetItem(id string) (*ClipboardItem, []byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	items, err := a.readAllInternal()
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range items {
+		if items[i].ID == id {
+			content, err := Decrypt(items[i].Content, a.key)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to decrypt item: %w", err)
+			}
+			return &items[i], content, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("item not found")
+}
+
+// Remove deletes an item from the archive and returns it
+func (a *Archive) Remove(id string) (*ClipboardItem, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	items, err := a.readAllInternal()
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].ID == id {
+			removed := items[i]
+			items = append(items[:i], items[i+1:]...)
+			if err := a.writeAllInternal(items); err != nil {
+				return nil, err
+			}
+			return &removed, nil
+		}
+	}
+	return nil, fmt.Errorf("item not found")
+}
+
+// encodeItem encrypts an item into a single archive line
+func (a *Archive) encodeItem(item ClipboardItem) (string, error) {
+	jsonData, err := json.Marshal(item)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal archive item: %w", err)
+	}
+	defer Zero(jsonData)
+
+	line, err := Encrypt(jsonData, a.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt archive item: %w", err)
+	}
+	return line, nil
+}
+
+// readAllInternal reads every archived item, oldest first (caller must hold lock)
+func (a *Archive) readAllInternal() ([]ClipboardItem, error) {
+	f, err := os.Open(a.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ClipboardItem{}, nil
+		}
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	items := make([]ClipboardItem, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), archiveMaxLine)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		decrypted, err := Decrypt(line, a.key)
+		if err != nil {
+			// A torn final write must not hide the rest of the archive
+			continue
+		}
+		var item ClipboardItem
+		err = json.Unmarshal(decrypted, &item)
+		Zero(decrypted)
+		if err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return items, nil
+}
+
+// writeAllInternal replaces the archive with the given items (caller must hold lock)
+func (a *Archive) writeAllInternal(items []ClipboardItem) error {
+	var buf bytes.Buffer
+	for _, item := range items {
+		line, err := a.encodeItem(item)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	// Write to a temp file first so a crash never leaves a half-written archive
+	tmpPath := a.path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmpPath, a.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace archive: %w", err)
+	}
+	a.count = len(items)
+	return nil
+}
+
+// pruneInternal drops the oldest items until the archive fits maxBytes (caller must hold lock)
+func (a *Archive) pruneInternal() {
+	if a.maxBytes <= 0 {
+		return
+	}
+	info, err := os.Stat(a.path)
+	if err != nil || info.Size() <= a.maxBytes {
+		return
+	}
+
+	items, err := a.readAllInternal()
+	if err != nil {
+		return
+	}
+
+	// Estimate each line's size from the encoded record to find the cut point
+	size := info.Size()
+	drop := 0
+	for drop < len(items) && size > a.maxBytes {
+		line, err := a.encodeItem(items[drop])
+		if err != nil {
+			break
+		}
+		size -= int64(len(line) + 1)
+		drop++
+	}
+	_ = a.writeAllInternal(items[drop:])
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+// archivedTexts returns the contents of the archived items, newest first
+func archivedTexts(t *testing.T, db *Database) []string {
+	t.Helper()
+	items, err := db.Archive().Search("")
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	var got []string
+	for _, item := range items {
+		_, content, err := db.Archive().GetItem(item.ID)
+		if err != nil {
+			t.Fatalf("failed to read archived item: %v", err)
+		}
+		got = append(got, string(content))
+	}
+	return got
+}
+
+// TestArchiveEvicted checks that items the limit pushes out land in the archive, oldest
+// first, only while archiving is on, and that one restored comes back on top
+func TestArchiveEvicted(t *testing.T) {
+	db := newTestDB(t)
+	db.SetGraceWindow(0)
+	db.SetMaxItems(10)
+
+	db.SetArchiveEnabled(true)
+	addTexts(t, db, numbered("öğe", 12)...)
+	if got, want := archivedTexts(t, db), []string{"öğe 2", "öğe 1"}; !slices.Equal(got, want) {
+		t.Fatalf("archive holds %q, want %q", got, want)
+	}
+	if n, err := db.Archive().Count(); err != nil || n != 2 {
+		t.Errorf("archive counts %d, %v; want 2", n, err)
+	}
+	if found, err := db.Archive().Search("ÖĞE 1"); err != nil || len(found) != 1 {
+		t.Errorf("search found %d, %v; want the one item", len(found), err)
+	}
+
+	db.SetArchiveEnabled(false)
+	addTexts(t, db, "öğe 13")
+	if got := archivedTexts(t, db); len(got) != 2 {
+		t.Errorf("archive holds %q with archiving off", got)
+	}
+
+	items, err := db.Archive().Search("öğe 2")
+	if err != nil || len(items) != 1 {
+		t.Fatalf("failed to find the archived item: %d, %v", len(items), err)
+	}
+	if err := db.RestoreFromArchive(items[0].ID); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if got := historyTexts(t, db); got[0] != "öğe 2" || len(got) != 10 {
+		t.Errorf("history after the restore is %q", got)
+	}
+	if got, want := archivedTexts(t, db), []string{"öğe 1"}; !slices.Equal(got, want) {
+		t.Errorf("archive after the restore holds %q, want %q", got, want)
+	}
+}
+
+// TestArchiveMaxBytes checks that the size cap drops the oldest archived items first
+func TestArchiveMaxBytes(t *testing.T) {
+	db := newTestDB(t)
+	db.SetGraceWindow(0)
+	db.SetMaxItems(10)
+	db.SetArchiveEnabled(true)
+	addTexts(t, db, numbered("öğe", 16)...)
+	if n, _ := db.Archive().Count(); n != 6 {
+		t.Fatalf("%d archived items, want 6", n)
+	}
+
+	limit := db.Archive().Size() / 2
+	db.Archive().SetMaxBytes(limit)
+	if size := db.Archive().Size(); size > limit {
+		t.Errorf("archive is %d bytes over a cap of %d", size, limit)
+	}
+	got := archivedTexts(t, db)
+	if len(got) == 0 || len(got) >= 6 {
+		t.Fatalf("archive holds %q after halving its cap", got)
+	}
+	if got[0] != "öğe 6" {
+		t.Errorf("the newest archived item went first: %q", got)
+	}
+}
@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AuditFile = "audit.db"
+
+	AuditMaxEntries = 2000 // Oldest entries are dropped beyond this
+	auditPruneSlack = 200  // Extra entries allowed before the file is rewritten
+)
+
+// AuditOp names a user operation on items
+type AuditOp string
+
+const (
+	AuditPin     AuditOp = "pin"
+	AuditUnpin   AuditOp = "unpin"
+	AuditDelete  AuditOp = "delete"
+	AuditClear   AuditOp = "clear"
+	AuditRestore AuditOp = "restore" // Undo of a delete or clear, or a restore from the archive
+	AuditEdit    AuditOp = "edit"    // Text of an item changed by the user
+
+	AuditExport       AuditOp = "export"        // History written out, e.g. for an older version
+	AuditExportDenied AuditOp = "export_denied" // An export refused by policy
+	AuditRekey        AuditOp = "rekey"         // History re-encrypted with a new data key
+	AuditMigrate      AuditOp = "migrate"       // History moved to the SQLite row store
+	AuditImport       AuditOp = "import"        // Items read from a portable export
+	AuditPrune        AuditOp = "prune"         // Items older than the retention period removed
+	AuditProtect      AuditOp = "protect"       // Passphrase set on an item
+	AuditUnprotect    AuditOp = "unprotect"     // Passphrase removed from an item
+	AuditUnlockDenied AuditOp = "unlock_denied" // Wrong passphrase, or an attempt while locked out
+
+	AuditPasswordSet    AuditOp = "password_set"    // History locked with a master password
+	AuditPasswordChange AuditOp = "password_change" // Master password replaced
+	AuditPasswordRemove AuditOp = "password_remove" // Master password removed
+)
+
+// AuditOps lists every operation, in the order filters show them
+var AuditOps = []AuditOp{AuditPin, AuditUnpin, AuditDelete, AuditClear, AuditRestore, AuditEdit, AuditExport, AuditExportDenied, AuditRekey, AuditImport, AuditPrune, AuditProtect, AuditUnprotect, AuditUnlockDenied, AuditPasswordSet, AuditPasswordChange, AuditPasswordRemove}
+
+// AuditSource names the kind of process that made a change
+type AuditSource string
+
+const (
+	AuditSourceUI  AuditSource = "ui"
+	AuditSourceCLI AuditSource = "cli"
+)
+
+// AuditEntry is one recorded operation
+type AuditEntry struct {
+	Time   time.Time   `json:"time"`
+	Op     AuditOp     `json:"op"`
+	ItemID string      `json:"item_id,omitempty"` // Empty when the operation affected several items
+	Title  string      `json:"title,omitempty"`   // Title of a text item at the time, see auditTitle
+	Count  int         `json:"count,omitempty"`   // Items affected by clear, restore and batch delete
+	Source AuditSource `json:"source"`
+}
+
+// AuditLog records who did what to items, to answer "where did my pinned item go"
+// Each line of audit.db is one encrypted AuditEntry, so recording is a plain append;
+// the file is kept next to the database and is never part of an export
+type AuditLog struct {
+	path  string
+	key   []byte
+	mu    sync.Mutex
+	lines int // Entries in the file, -1 until counted
+}
+
+// GetAuditPath returns the full path to the audit log
+func GetAuditPath() (string, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), AuditFile), nil
+}
+
+// newAuditLog creates an audit log handle; the file is only opened when used
+func newAuditLog(key []byte) (*AuditLog, error) {
+	path, err := GetAuditPath()
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{path: path, key: key, lines: -1}, nil
+}
+
+// Record appends an entry, dropping the oldest ones once the log is well past its cap
+func (l *AuditLog) Record(entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	line, err := l.encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	if l.lines < 0 {
+		lines, err := l.readLinesInternal()
+		if err != nil {
+			return err
+		}
+		l.lines = len(lines)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	l.lines++
+
+	if l.lines > AuditMaxEntries+auditPruneSlack {
+		return l.pruneInternal()
+	}
+	return nil
+}
+
+// Entries returns the recorded entries, newest first; unreadable lines are skipped
+func (l *AuditLog) Entries() ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines, err := l.readLinesInternal()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]AuditEntry, 0, len(lines))
+	for i := len(lines) - 1; i >= 0; i-- {
+		decrypted, err := Decrypt(lines[i], l.key)
+		if err != nil {
+			continue
+		}
+		var entry AuditEntry
+		err = json.Unmarshal(decrypted, &entry)
+		Zero(decrypted)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// encodeEntry encrypts an entry as one line
+func (l *AuditLog) encodeEntry(entry AuditEntry) (string, error) {
+	jsonData, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	defer Zero(jsonData)
+
+	line, err := Encrypt(jsonData, l.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt audit entry: %w", err)
+	}
+	return line, nil
+}
+
+// readLinesInternal returns the encrypted lines, oldest first (caller must hold lock)
+func (l *AuditLog) readLinesInternal() ([]string, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return lines, nil
+}
+
+// pruneInternal rewrites the file with the newest AuditMaxEntries lines (caller must hold lock)
+// Lines are kept encrypted; nothing is decrypted to prune
+func (l *AuditLog) pruneInternal() error {
+	lines, err := l.readLinesInternal()
+	if err != nil {
+		return err
+	}
+	if len(lines) > AuditMaxEntries {
+		lines = lines[len(lines)-AuditMaxEntries:]
+	}
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	// Write to a temp file first so a crash never leaves a half-written log
+	tmpPath := l.path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace audit log: %w", err)
+	}
+	l.lines = len(lines)
+	return nil
+}
+
+// SetAuditSource sets the source recorded with every operation of this process
+func (db *Database) SetAuditSource(source AuditSource) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.auditSource = source
+}
+
+// AuditEntries returns the operations log, newest first
+func (db *Database) AuditEntries() ([]AuditEntry, error) {
+	if db.audit == nil {
+		return []AuditEntry{}, nil
+	}
+	return db.audit.Entries()
+}
+
+// RecordExportDenied logs an export that policy refused
+func (db *Database) RecordExportDenied() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.writeAudit(AuditEntry{Op: AuditExportDenied})
+}
+
+// recordAudit logs an operation on one item (caller must hold lock)
+// A failed write never fails the operation itself
+func (db *Database) recordAudit(op AuditOp, item ClipboardItem) {
+	db.writeAudit(AuditEntry{Op: op, ItemID: item.ID, Title: db.auditTitle(item)})
+}
+
+// recordAuditCount logs an operation on several items (caller must hold lock)
+// A single item is logged like recordAudit
+func (db *Database) recordAuditCount(op AuditOp, items []ClipboardItem) {
+	if len(items) == 1 {
+		db.recordAudit(op, items[0])
+		return
+	}
+	db.writeAudit(AuditEntry{Op: op, Count: len(items)})
+}
+
+// writeAudit stamps and appends an entry (caller must hold lock)
+func (db *Database) writeAudit(entry AuditEntry) {
+	if db.audit == nil {
+		return
+	}
+	entry.Time = db.now()
+	entry.Source = db.auditSource
+	_ = db.audit.Record(entry)
+}
+
+// auditTitle returns the title of a text item, or its first line cut to TitleMaxChars
+// Other item types have no title, and protected items show none (caller must hold lock)
+func (db *Database) auditTitle(item ClipboardItem) string {
+	if item.Type != "text" || item.IsProtected() {
+		return ""
+	}
+	if item.TitleCache != "" {
+		if title, err := Decrypt(item.TitleCache, db.key); err == nil {
+			defer Zero(title)
+			return string(title)
+		}
+	}
+	content, err := Decrypt(item.Content, db.key)
+	if err != nil {
+		return ""
+	}
+	defer Zero(content)
+
+	// Enough bytes for TitleMaxChars clusters of several multi-byte runes each
+	head := content
+	if limit := 4 * graphemeWindow * TitleMaxChars; len(head) > limit {
+		head = head[:limit]
+	}
+	for _, line := range strings.Split(strings.ToValidUTF8(string(head), ""), "\n") {
+		if line = trimTitleLine(line); line != "" {
+			if cut, ok := CutGraphemes(line, TitleMaxChars); ok {
+				line = cut + "…"
+			}
+			return line
+		}
+	}
+	return ""
+}
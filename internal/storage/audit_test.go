@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+// auditOps returns the operations of entries, as listed
+func auditOps(entries []AuditEntry) []AuditOp {
+	ops := make([]AuditOp, 0, len(entries))
+	for _, entry := range entries {
+		ops = append(ops, entry.Op)
+	}
+	return ops
+}
+
+// TestAuditOperations pins, deletes, clears and restores, and checks the log lists
+// them newest first with their titles and source, and that the file holds no titles
+func TestAuditOperations(t *testing.T) {
+	db := newTestDB(t)
+	db.SetAuditSource(AuditSourceCLI)
+	addTexts(t, db, "  \n# Toplantı notları\nayrıntılar", "ikinci")
+	items := db.GetAllItems() // ikinci, Toplantı notları
+
+	if err := db.TogglePin(items[1].ID); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := db.TogglePin(items[1].ID); err != nil {
+		t.Fatalf("failed to unpin: %v", err)
+	}
+	if err := db.DeleteItem(items[0].ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := db.RestoreItem(items[0].ID); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	cleared := db.GetAllItems()
+	if err := db.ClearAll(); err != nil {
+		t.Fatalf("failed to clear: %v", err)
+	}
+	if err := db.RestoreItems(cleared); err != nil {
+		t.Fatalf("failed to undo the clear: %v", err)
+	}
+
+	entries, err := db.AuditEntries()
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	want := []AuditOp{AuditRestore, AuditClear, AuditRestore, AuditDelete, AuditUnpin, AuditPin}
+	if got := auditOps(entries); !slices.Equal(got, want) {
+		t.Fatalf("logged %v, want %v", got, want)
+	}
+	for _, entry := range entries {
+		if entry.Source != AuditSourceCLI || entry.Time.IsZero() {
+			t.Errorf("%s entry has source %q and time %v", entry.Op, entry.Source, entry.Time)
+		}
+	}
+	if pin := entries[5]; pin.ItemID != items[1].ID || pin.Title != "Toplantı notları" {
+		t.Errorf("pin entry is %+v", pin)
+	}
+	if entries[3].Title != "ikinci" {
+		t.Errorf("delete entry has title %q", entries[3].Title)
+	}
+	if clear := entries[1]; clear.Count != 2 || clear.ItemID != "" {
+		t.Errorf("clear entry is %+v", clear)
+	}
+
+	path, err := GetAuditPath()
+	if err != nil {
+		t.Fatalf("failed to locate audit log: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit file: %v", err)
+	}
+	if bytes.Contains(data, []byte("Toplantı")) || bytes.Contains(data, []byte("ikinci")) {
+		t.Error("the audit file holds item titles in the clear")
+	}
+}
+
+// TestAuditPrune lets the log grow past its slack and checks only the newest
+// AuditMaxEntries remain; a damaged line is skipped rather than failing the read
+func TestAuditPrune(t *testing.T) {
+	db := newTestDB(t)
+	audit := db.audit
+	total := AuditMaxEntries + auditPruneSlack + 1
+	for i := range total {
+		if err := audit.Record(AuditEntry{Op: AuditPin, Count: i}); err != nil {
+			t.Fatalf("failed to record entry %d: %v", i, err)
+		}
+	}
+	entries, err := audit.Entries()
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if len(entries) != AuditMaxEntries {
+		t.Fatalf("%d entries after pruning, want %d", len(entries), AuditMaxEntries)
+	}
+	if entries[0].Count != total-1 || entries[len(entries)-1].Count != total-AuditMaxEntries {
+		t.Errorf("kept entries %d to %d", entries[len(entries)-1].Count, entries[0].Count)
+	}
+
+	f, err := os.OpenFile(audit.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("failed to open audit file: %v", err)
+	}
+	f.WriteString("bozuk satır\n")
+	f.Close()
+	if entries, err := audit.Entries(); err != nil || len(entries) != AuditMaxEntries {
+		t.Errorf("%d entries with a damaged line, %v", len(entries), err)
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Captures, pins and deletions don't write the database file themselves: they mark it
+// dirty and a timer writes it once, SaveDelay after the first change, so a burst of
+// copies costs one encrypt-and-write of the whole history instead of one per event and
+// the monitor isn't held up by it. Captures are journaled before they return, so a crash
+// before the write loses none of them; a pin or deletion made in the last SaveDelay
+// before a crash can be. Flush and Close write pending changes, Save writes right away.
+
+// DefaultSaveDelay is how long changes are collected before the database is written
+const DefaultSaveDelay = 500 * time.Millisecond
+
+// SetSaveDelay sets how long changes are collected before a write; 0 writes on every change
+func (db *Database) SetSaveDelay(delay time.Duration) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.saveDelay = delay
+	if delay <= 0 && db.dirty {
+		db.stopSaveTimer()
+		db.saveInternal()
+	}
+}
+
+// SetOnSaveError sets a callback for failed delayed writes, which have no caller to
+// return the error to; it runs on the timer's goroutine
+func (db *Database) SetOnSaveError(callback func(err error)) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.onSaveError = callback
+}
+
+// Flush writes pending changes now; a no-op if there are none
+func (db *Database) Flush() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.flushInternal()
+}
+
+// flushInternal writes pending changes (caller must hold lock)
+func (db *Database) flushInternal() error {
+	db.stopSaveTimer()
+	if !db.dirty {
+		return nil
+	}
+	return db.saveInternal()
+}
+
+// scheduleSave marks the database dirty and starts the write timer unless it runs
+// already; without a delay it writes right away (caller must hold lock)
+// A database that failed to load refuses here already, as saveInternal would
+func (db *Database) scheduleSave() error {
+	if db.saveDelay <= 0 {
+		return db.saveInternal()
+	}
+	if db.loadErr != nil {
+		return fmt.Errorf("database not loaded: %w", db.loadErr)
+	}
+	db.dirty = true
+	if db.saveTimer == nil {
+		db.saveTimer = time.AfterFunc(db.saveDelay, db.saveScheduled)
+	}
+	return nil
+}
+
+// saveScheduled is the write timer's callback
+func (db *Database) saveScheduled() {
+	db.mu.Lock()
+	db.saveTimer = nil
+	var err error
+	if db.dirty {
+		err = db.saveInternal()
+	}
+	onError := db.onSaveError
+	db.mu.Unlock()
+
+	if err != nil && onError != nil {
+		onError(err)
+	}
+}
+
+// stopSaveTimer cancels a pending timed write (caller must hold lock)
+func (db *Database) stopSaveTimer() {
+	if db.saveTimer != nil {
+		db.saveTimer.Stop()
+		db.saveTimer = nil
+	}
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Consistency contract
+//
+// Every mutation of a Database runs under db.mu and is therefore linearized:
+// there is a single order in which adds, pins, deletes, clears and restores
+// take effect. Each mutation bumps the change counter while still holding the
+// lock and queues a ChangeEvent carrying the new counter value, so events are
+// delivered in commit order with strictly increasing Seq. Readers get whole
+// snapshots taken under the read lock (Snapshot, GetAllItems), which always
+// reflect a prefix of that order: a snapshot that shows a delete also shows
+// every add committed before it.
+
+// ChangeKind names what a mutation did
+type ChangeKind string
+
+const (
+	ChangeAdd     ChangeKind = "add"     // A new item was captured
+	ChangeUpdate  ChangeKind = "update"  // Pin toggled, a duplicate moved to the top or an item copied
+	ChangeDelete  ChangeKind = "delete"  // Items removed or moved to the trash by the user
+	ChangeEvict   ChangeKind = "evict"   // Items dropped by the limit, the retention period or the trash
+	ChangeClear   ChangeKind = "clear"   // All items removed
+	ChangeRestore ChangeKind = "restore" // Items put back by undo, from the trash or from the archive
+	ChangeReload  ChangeKind = "reload"  // Items replaced by reading the database file again
+)
+
+// ChangeEvent describes one committed mutation
+type ChangeEvent struct {
+	Seq  uint64 // Change counter after the mutation, strictly increasing
+	Kind ChangeKind
+	IDs  []string // Affected item IDs (empty for ChangeClear)
+}
+
+// changeFeed delivers events to listeners in commit order on its own goroutine
+// Listeners may call back into the database without deadlocking
+type changeFeed struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	pending   []ChangeEvent
+	listeners []func(ChangeEvent)
+	running   bool
+	closed    bool          // Delivery was stopped by close and isn't started again
+	done      chan struct{} // Closed when deliver returns
+}
+
+// subscribe adds a listener and starts delivery on first use
+func (f *changeFeed) subscribe(listener func(ChangeEvent)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, listener)
+	if !f.running && !f.closed {
+		f.cond = sync.NewCond(&f.mu)
+		f.running = true
+		f.done = make(chan struct{})
+		go f.deliver()
+	}
+}
+
+// close stops delivery once the queued events are delivered, waiting up to timeout
+// Must not be called with db.mu held, since listeners may call back into the database
+func (f *changeFeed) close(timeout time.Duration) error {
+	f.mu.Lock()
+	f.closed = true
+	if !f.running {
+		f.mu.Unlock()
+		return nil
+	}
+	f.running = false
+	f.cond.Signal()
+	done := f.done
+	f.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("change feed did not stop within %v", timeout)
+	}
+}
+
+// push queues an event; callers hold db.mu so queue order is commit order
+func (f *changeFeed) push(event ChangeEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.running {
+		return
+	}
+	f.pending = append(f.pending, event)
+	f.cond.Signal()
+}
+
+func (f *changeFeed) deliver() {
+	defer close(f.done)
+
+	f.mu.Lock()
+	for {
+		for len(f.pending) == 0 && f.running {
+			f.cond.Wait()
+		}
+		if len(f.pending) == 0 {
+			f.mu.Unlock()
+			return
+		}
+		batch := f.pending
+		f.pending = nil
+		listeners := f.listeners
+		f.mu.Unlock()
+
+		for _, event := range batch {
+			for _, listener := range listeners {
+				listener(event)
+			}
+		}
+
+		f.mu.Lock()
+	}
+}
+
+// OnChange registers a listener for committed mutations, called in commit order
+// Listeners run on a dedicated goroutine and must not block for long
+func (db *Database) OnChange(listener func(ChangeEvent)) {
+	db.feed.subscribe(listener)
+}
+
+// Seq returns the current change counter
+func (db *Database) Seq() uint64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.seq
+}
+
+// Snapshot returns all items (ordered like GetAllItems) with the change counter they reflect
+func (db *Database) Snapshot() ([]ClipboardItem, uint64) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.orderedItems(), db.seq
+}
+
+// commit records a mutation (caller holds db.mu)
+func (db *Database) commit(kind ChangeKind, ids ...string) {
+	db.seq++
+	db.feed.push(ChangeEvent{Seq: db.seq, Kind: kind, IDs: ids})
+}
+
+// newItemID returns a unique, increasing item ID (caller holds db.mu)
+// Concurrent captures can land on the same clock tick on coarse Windows timers
+func (db *Database) newItemID() string {
+	id := db.now().UnixNano()
+	if id <= db.lastID {
+		id = db.lastID + 1
+	}
+	db.lastID = id
+	return fmt.Sprintf("%d", id)
+}
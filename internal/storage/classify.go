@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"strings"
+)
+
+// Text item classes
+const (
+	ClassText = "text"
+	ClassCode = "code"
+	ClassURL  = "url"
+
+	ClassVCard    = "vcard"    // Contact card (BEGIN:VCARD)
+	ClassCalendar = "calendar" // iCalendar event (BEGIN:VCALENDAR)
+)
+
+// codeKeywords are line prefixes that strongly suggest source code or config
+var codeKeywords = []string{
+	"func ", "def ", "class ", "import ", "package ", "#include", "using ",
+	"public ", "private ", "return ", "const ", "let ", "var ", "if (", "for (",
+	"<?xml", "<!DOCTYPE", "SELECT ", "#!/",
+}
+
+// ClassifyText guesses whether a text snippet is a URL, contact, calendar, code/config or prose
+func ClassifyText(text string) string {
+	head := strings.TrimLeft(text, " \t\r\n\ufeff")
+	if len(head) > 16 {
+		head = head[:16]
+	}
+	head = strings.ToUpper(head)
+	if strings.HasPrefix(head, "BEGIN:VCARD") {
+		return ClassVCard
+	}
+	if strings.HasPrefix(head, "BEGIN:VCALENDAR") {
+		return ClassCalendar
+	}
+
+	if isURL(text) {
+		return ClassURL
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	nonEmpty := 0
+	score := 0
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		nonEmpty++
+
+		// Indented lines
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "  ") {
+			score++
+		}
+
+		// Typical statement and block endings
+		last := trimmed[len(trimmed)-1]
+		if last == '{' || last == '}' || last == ';' || last == ':' || last == ',' || last == ')' {
+			score++
+		}
+
+		for _, kw := range codeKeywords {
+			if strings.HasPrefix(trimmed, kw) {
+				score += 2
+				break
+			}
+		}
+	}
+
+	// A single line is only code if it is very obviously so
+	if nonEmpty < 2 {
+		return ClassText
+	}
+	if score >= nonEmpty {
+		return ClassCode
+	}
+	return ClassText
+}
@@ -0,0 +1,65 @@
+package storage
+
+import "testing"
+
+func TestCountsLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		counts    Counts
+		remaining int
+		full      bool
+		near      bool
+		excess    int
+	}{
+		{"empty", Counts{Limit: 100}, 100, false, false, 0},
+		{"pins don't count", Counts{Active: 50, Pinned: 80, Limit: 100}, 50, false, false, 0},
+		{"near the limit", Counts{Active: 90, Limit: 100}, 10, false, true, 0},
+		{"at the limit", Counts{Active: 100, Pinned: 3, Limit: 100}, 0, true, true, 0},
+		{"over a lowered limit", Counts{Active: 120, Limit: 100}, 0, true, true, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.counts
+			if c.Remaining() != tt.remaining || c.IsFull() != tt.full || c.IsNearLimit() != tt.near || c.Excess() != tt.excess {
+				t.Errorf("remaining %d, full %v, near %v, excess %d; want %d, %v, %v, %d",
+					c.Remaining(), c.IsFull(), c.IsNearLimit(), c.Excess(), tt.remaining, tt.full, tt.near, tt.excess)
+			}
+			if c.Total() != c.Active+c.Pinned {
+				t.Errorf("total %d", c.Total())
+			}
+		})
+	}
+	if (Counts{Bytes: 10}).IsOverSize() {
+		t.Error("over size without a size cap")
+	}
+	if !(Counts{Bytes: 11, SizeLimit: 10}).IsOverSize() || (Counts{Bytes: 10, SizeLimit: 10}).IsOverSize() {
+		t.Error("size cap checked wrongly")
+	}
+}
+
+// TestCounts takes a snapshot of a history with pins, an image, the trash and the archive
+func TestCounts(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "bir", "iki", "üç", "dört")
+	addScreenshot(t, db, screenshot(1))
+	items := db.GetAllItems() // image, dört, üç, iki, bir
+	if err := db.TogglePin(items[1].ID); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := db.DeleteItem(items[4].ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := db.Archive().Append(items[2:4]); err != nil {
+		t.Fatalf("failed to archive: %v", err)
+	}
+
+	got := db.Counts()
+	want := Counts{
+		Active: 3, Pinned: 1, Archived: 2, Trashed: 1, Limit: got.Limit, SizeLimit: got.SizeLimit,
+		Bytes: int64(items[0].Size + len("dört") + len("üç") + len("iki")),
+		Text:  3, Images: 1,
+	}
+	if got != want {
+		t.Errorf("counts are\n%+v, want\n%+v", got, want)
+	}
+}
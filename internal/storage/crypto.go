@@ -11,29 +11,37 @@ import (
 
 // Encrypt encrypts data using AES-256-GCM with the hardware key
 func Encrypt(plaintext []byte, key []byte) (string, error) {
+	ciphertext, err := EncryptBytes(plaintext, key)
+	if err != nil {
+		return "", err
+	}
+
+	// Encode to base64 for storage
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// EncryptBytes encrypts data using AES-256-GCM and returns nonce+ciphertext without encoding
+func EncryptBytes(plaintext []byte, key []byte) ([]byte, error) {
 	// Create AES cipher block
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
 	}
 
 	// Create GCM mode
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
 	}
 
 	// Generate random nonce
 	nonce := make([]byte, gcm.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", fmt.Errorf("failed to generate nonce: %w", err)
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
 	// Encrypt and append nonce
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
-
-	// Encode to base64 for storage
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
 }
 
 // Decrypt decrypts data using AES-256-GCM with the hardware key
@@ -44,6 +52,11 @@ func Decrypt(ciphertext string, key []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to decode base64: %w", err)
 	}
 
+	return DecryptBytes(data, key)
+}
+
+// DecryptBytes decrypts raw nonce+ciphertext produced by EncryptBytes
+func DecryptBytes(data []byte, key []byte) ([]byte, error) {
 	// Create AES cipher block
 	block, err := aes.NewCipher(key)
 	if err != nil {
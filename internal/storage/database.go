@@ -1,60 +1,231 @@
 package storage
 
 import (
-	"crypto/sha256"
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
+	"image"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 )
 
 const (
-	DefaultMaxItems = 100              // Default maximum number of clipboard items
-	MaxItemSize     = 20 * 1024 * 1024 // 20MB per item
-	DatabaseFile    = "clipboard.db"
+	DefaultMaxItems     = 100               // Default maximum number of clipboard items
+	MaxItemSize         = 20 * 1024 * 1024  // 20MB per item
+	DefaultMaxTotalSize = 100 * 1024 * 1024 // Default cap on the summed size of all items
+	DatabaseFile        = "clipboard.db"
+
+	DefaultGraceWindow = 5 * time.Minute // Unpinned items this new are never evicted by the limit
+	DefaultDedupWindow = 24 * time.Hour  // How far back DedupRecent looks for duplicates
+
+	DefaultPinLimit = 50 // Default maximum number of pinned items
+
+	nearLimitSlots = 10 // Captures warn once this few slots are left
+
+	closeTimeout = 2 * time.Second // How long Close waits for background goroutines
+)
+
+// ErrPinLimitReached is returned by TogglePin when pinning would exceed the pin limit
+var ErrPinLimitReached = errors.New("pin limit reached")
+
+// DedupMode selects which existing items a capture is compared against for duplicates
+type DedupMode string
+
+const (
+	DedupAll    DedupMode = "all"    // Any item with the same content moves to the top
+	DedupRecent DedupMode = "recent" // Only items captured within the dedup window
+	DedupOff    DedupMode = "off"    // Every capture is a new item
+)
+
+// LimitPolicy decides what a capture does when the unpinned items are at the limit
+type LimitPolicy string
+
+const (
+	LimitEvictOldest LimitPolicy = "evict"  // The oldest unpinned item makes room
+	LimitReject      LimitPolicy = "reject" // The capture is refused with RejectLimitFull
 )
 
 // ClipboardItem represents a single clipboard entry
 type ClipboardItem struct {
-	ID        string    `json:"id"`
-	Type      string    `json:"type"`    // "text" or "image"
-	Content   string    `json:"content"` // Encrypted content
-	Timestamp time.Time `json:"timestamp"`
-	Pinned    bool      `json:"pinned"`
-	Size      int       `json:"size"` // Original size in bytes
-	Hash      string    `json:"hash"` // Content hash for duplicate detection
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`    // "text", "image" or "files"
+	Content     string          `json:"content"` // Encrypted content
+	Timestamp   time.Time       `json:"timestamp"`
+	Pinned      bool            `json:"pinned"`
+	Size        int             `json:"size"`                 // Original size in bytes
+	Hash        string          `json:"hash"`                 // Content hash for duplicate detection
+	HashVersion int             `json:"hashv,omitempty"`      // Form of Hash, see hash.go; 0 for items hashed before versioning
+	PHash       string          `json:"phash,omitempty"`      // Perceptual hash for images
+	Delta       *ImageDelta     `json:"delta,omitempty"`      // Set when content is a patch over another image
+	Class       string          `json:"class,omitempty"`      // Text classification ("text", "code" or "url")
+	Original    string          `json:"original,omitempty"`   // Encrypted pre-cleaning content (URLs with tracking params)
+	Forced      bool            `json:"forced,omitempty"`     // Captured manually, bypassing pause and exclusions
+	SourceURL   string          `json:"source,omitempty"`     // Encrypted URL of the page the content was copied from
+	SourceApp   string          `json:"app,omitempty"`        // Encrypted executable name of the program that copied it
+	RTF         string          `json:"rtf,omitempty"`        // Encrypted Rich Text Format copied along with the text
+	Redacted    bool            `json:"redacted,omitempty"`   // Parts of the content were masked by redaction rules
+	TitleCache  string          `json:"title,omitempty"`      // Encrypted implicit title of multi-line text, see ExtractTitle
+	Title       string          `json:"user_title,omitempty"` // Encrypted title set by the user, see SetTitle
+	Preview     string          `json:"preview,omitempty"`    // Encrypted preview the list renders from, see preview.go
+	Tags        []string        `json:"tags,omitempty"`       // User-defined labels, see tags.go
+	CopyCount   int             `json:"uses,omitempty"`       // Times the item was copied back from Pano
+	LastUsed    time.Time       `json:"last_used,omitzero"`   // When it was last copied back, zero if never
+	Protection  *ItemProtection `json:"protection,omitempty"` // Passphrase verifier, nil if unprotected (see protect.go)
+	Deleted     bool            `json:"deleted,omitempty"`    // In the trash: hidden from the history until restored or purged (see trash.go)
+	DeletedAt   time.Time       `json:"deleted_at,omitzero"`  // When it was moved to the trash
+
+	unknown []rawField // Fields written by a newer version, kept for round-tripping
 }
 
 // Database manages clipboard items storage
 type Database struct {
-	Items       []ClipboardItem     `json:"items"`
-	key         []byte              // Encryption key (not stored in JSON)
-	mu          sync.RWMutex        // Mutex for thread-safe operations
-	maxItems    int                 // Configurable max items limit
-	onLimitWarn func(remaining int) // Callback when near limit
+	Items        []ClipboardItem     `json:"items"`
+	key          []byte              // Encryption key (not stored in JSON)
+	pendingKey   []byte              // Key of an unfinished re-key, nil if none (see rekey.go)
+	password     *masterPassword     // Wraps the data key with the hardware key, nil without one (see password.go)
+	mu           sync.RWMutex        // Mutex for thread-safe operations
+	maxItems     int                 // Configurable max items limit
+	maxTotalSize int64               // Cap on the summed Size of all items, 0 for none
+	onLimitWarn  func(remaining int) // Callback when near limit
+	deltaImages  bool                // Store near-identical images as patches
+
+	stripTracking  bool     // Remove tracking parameters from captured URLs
+	trackingParams []string // Parameters removed when stripTracking is on
+
+	redactor *Redactor // Masks text before it is stored, nil without rules (see redact.go)
+
+	archive        *Archive // Cold storage for items dropped by the limit
+	archiveEnabled bool     // Move dropped items to the archive instead of deleting them
+
+	corrupt map[string]string // Items that failed the integrity check, by ID (not persisted)
+
+	graceWindow time.Duration // Recent items are kept even when over the limit
+	limitPolicy LimitPolicy   // What a capture does when the history is full
+
+	dedupMode   DedupMode     // Which items count as duplicates of a capture
+	dedupWindow time.Duration // Age limit for DedupRecent
+
+	raisedDedupWindow time.Duration // Temporary age limit merged regardless of the mode, see RaiseDedupWindow
+	raisedDedupUntil  time.Time     // When raisedDedupWindow ends
+
+	pinLimit int // Maximum number of pinned items; pinned items are never evicted
+
+	now func() time.Time // Clock for timestamps, the grace window and age sweeps, replaceable in tests
+
+	seq    uint64     // Change counter, bumped by every mutation (see changes.go)
+	lastID int64      // Last issued item ID, keeps IDs unique within a tick
+	feed   changeFeed // Ordered change events for listeners
+
+	journal *journal     // Captures not yet in a full save (see journal.go)
+	sqlite  *sqliteStore // Row store used instead of the database file, nil if none (see sqlite.go)
+
+	saveDelay   time.Duration   // How long changes are collected before a write, 0 to write each (see autosave.go)
+	saveTimer   *time.Timer     // Pending timed write, nil if none
+	dirty       bool            // Items changed since the last write
+	onSaveError func(err error) // Reports failed timed writes
+
+	recompressImages bool            // Re-encode captured images smaller in the background
+	recompress       recompressQueue // Images waiting for that (see recompress.go)
+
+	audit       *AuditLog   // User operations on items, nil if its path is unknown (see audit.go)
+	auditSource AuditSource // Recorded with every operation of this process
+
+	loadErr error // Why the database file couldn't be read; saving is refused until a reload succeeds
 }
 
-// NewDatabase creates or loads the database
+// NewDatabase creates or loads the database; a history locked with a master password
+// returns ErrPasswordRequired, see NewDatabaseWithPassword
 func NewDatabase() (*Database, error) {
-	key, err := GetHardwareKey()
+	return NewDatabaseWithPassword(nil)
+}
+
+// NewDatabaseWithPassword creates or loads the database, asking provider for the master
+// password if the history has one (see password.go)
+// Nothing is read or written before the password matches; if provider gives up,
+// ErrPasswordRequired is returned, or ErrWrongPassword after a wrong one
+func NewDatabaseWithPassword(provider PasswordProvider) (*Database, error) {
+	file, err := readPasswordKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	var password *masterPassword
+	if file != nil {
+		if password, err = askPassword(file, provider); err != nil {
+			return nil, err
+		}
+	}
+
+	hwKey, err := GetHardwareKey()
 	if err != nil {
+		password.zero()
 		return nil, fmt.Errorf("failed to get hardware key: %w", err)
 	}
+	key, pending, err := loadDataKey(hwKey, password)
+	if errors.Is(err, ErrKeyMismatch) {
+		// The history can't be read until it is recovered; captures made meanwhile
+		// are encrypted with this machine's key and carried over by the recovery
+		key, err = bytes.Clone(hwKey), nil
+	}
+	Zero(hwKey)
+	if err != nil {
+		password.zero()
+		return nil, err
+	}
 
 	db := &Database{
-		Items:    make([]ClipboardItem, 0),
-		key:      key,
-		maxItems: DefaultMaxItems,
+		Items:        make([]ClipboardItem, 0),
+		key:          newLockedKey(key),
+		password:     password,
+		maxItems:     DefaultMaxItems,
+		maxTotalSize: DefaultMaxTotalSize,
+
+		trackingParams: DefaultTrackingParams,
+		corrupt:        make(map[string]string),
+		graceWindow:    DefaultGraceWindow,
+		limitPolicy:    LimitEvictOldest,
+		dedupMode:      DedupAll,
+		dedupWindow:    DefaultDedupWindow,
+		pinLimit:       DefaultPinLimit,
+		auditSource:    AuditSourceUI,
+		now:            time.Now,
+
+		recompressImages: true,
+		saveDelay:        DefaultSaveDelay,
+	}
+
+	if pending != nil {
+		db.pendingKey = newLockedKey(pending)
+	}
+
+	archive, err := newArchive(db.key)
+	if err != nil {
+		return nil, err
+	}
+	db.archive = archive
+
+	if audit, err := newAuditLog(db.key); err == nil {
+		db.audit = audit
+	}
+
+	// Opened before Load, so the save after a replay empties it
+	// Without a journal captures are still saved, just not crash-safe between saves
+	if j, err := openJournal(db.key); err == nil {
+		db.journal = j
 	}
 
 	// Try to load existing database
-	if err := db.Load(); err != nil {
-		// If file doesn't exist, that's okay - we'll create it on first save
-		if !os.IsNotExist(err) {
-			return nil, err
-		}
+	// An unreadable file is kept as a load error instead of failing startup, so the
+	// UI can report it and retry; new captures stay in the journal meanwhile
+	if err := db.Load(); err != nil && !os.IsNotExist(err) {
+		db.loadErr = err
+	}
+	// A history from before a machine ID change opens with a recovery key
+	if errors.Is(db.loadErr, ErrKeyMismatch) {
+		db.promptRecovery()
 	}
 
 	return db, nil
@@ -71,33 +242,253 @@ func (db *Database) SetMaxItems(max int) {
 		max = 500
 	}
 	db.maxItems = max
+	if db.enforceLimit() {
+		db.saveInternal()
+	}
+}
+
+// SetMaxTotalSize caps the summed size of all items; the oldest unpinned items are
+// evicted to stay under it (0 or less removes the cap)
+func (db *Database) SetMaxTotalSize(bytes int64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.maxTotalSize = max(bytes, 0)
+	if db.enforceLimit() {
+		db.saveInternal()
+	}
+}
+
+// GetMaxTotalSize returns the cap on the summed size of all items, 0 if there is none
+func (db *Database) GetMaxTotalSize() int64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.maxTotalSize
+}
+
+// GetTotalSize returns the summed size of all items in the history, pinned included
+func (db *Database) GetTotalSize() int64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return totalSize(db.Items)
+}
+
+// totalSize sums the original sizes of items, leaving out those in the trash
+func totalSize(items []ClipboardItem) int64 {
+	var total int64
+	for _, item := range items {
+		if !item.Deleted {
+			total += int64(item.Size)
+		}
+	}
+	return total
+}
+
+// SetDeltaImages enables storing near-identical screenshots as a diff over an earlier image
+func (db *Database) SetDeltaImages(enabled bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.deltaImages = enabled
+}
+
+// GetDeltaImages returns whether delta image storage is enabled
+func (db *Database) GetDeltaImages() bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.deltaImages
+}
+
+// SetURLCleaning configures removal of tracking parameters from captured URLs
+// An empty params list falls back to DefaultTrackingParams
+func (db *Database) SetURLCleaning(enabled bool, params []string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.stripTracking = enabled
+	if len(params) == 0 {
+		params = DefaultTrackingParams
+	}
+	db.trackingParams = params
+}
+
+// SetRedactionRules replaces the redaction rules applied to captured text
+// On an invalid pattern the previous rules stay in effect
+func (db *Database) SetRedactionRules(rules []RedactionRule) error {
+	redactor, err := NewRedactor(rules)
+	if err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	redactor.now = db.now
+	db.redactor = redactor
+	return nil
+}
+
+// SetArchiveEnabled makes the limit move old items to the archive instead of deleting them
+func (db *Database) SetArchiveEnabled(enabled bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.archiveEnabled = enabled
+}
+
+// GetArchiveEnabled returns whether dropped items are archived
+func (db *Database) GetArchiveEnabled() bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.archiveEnabled
+}
+
+// Archive returns the cold-storage archive
+func (db *Database) Archive() *Archive {
+	return db.archive
+}
+
+// RestoreFromArchive moves an archived item back to the top of the active history
+func (db *Database) RestoreFromArchive(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	item, err := db.archive.Remove(id)
+	if err != nil {
+		return err
+	}
+
+	db.Items = append([]ClipboardItem{*item}, db.Items...)
+	// Archived before previews existed
+	db.backfillPreviews()
+	db.commit(ChangeRestore, item.ID)
+	db.recordAudit(AuditRestore, *item)
 	db.enforceLimit()
-	db.saveInternal()
+	return db.saveInternal()
 }
 
-// GetMaxItems returns the current maximum items limit
-func (db *Database) GetMaxItems() int {
+// SetGraceWindow sets how long new unpinned items are protected from eviction (0 disables)
+func (db *Database) SetGraceWindow(window time.Duration) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if window < 0 {
+		window = 0
+	}
+	db.graceWindow = window
+	if db.enforceLimit() {
+		db.saveInternal()
+	}
+}
+
+// SetLimitPolicy sets what a capture does when the unpinned items are at the limit
+func (db *Database) SetLimitPolicy(policy LimitPolicy) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	switch policy {
+	case LimitEvictOldest, LimitReject:
+	default:
+		policy = LimitEvictOldest
+	}
+	db.limitPolicy = policy
+}
+
+// GetLimitPolicy returns what a capture does when the history is full
+func (db *Database) GetLimitPolicy() LimitPolicy {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
-	return db.maxItems
+	return db.limitPolicy
+}
+
+// SetDedupMode sets which existing items a capture is compared against; window is the
+// age limit for DedupRecent (0 keeps DefaultDedupWindow)
+func (db *Database) SetDedupMode(mode DedupMode, window time.Duration) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	switch mode {
+	case DedupAll, DedupRecent, DedupOff:
+	default:
+		mode = DedupAll
+	}
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	db.dedupMode = mode
+	db.dedupWindow = window
 }
 
-// IsNearLimit returns true if item count is within 10 of the limit
-func (db *Database) IsNearLimit() bool {
+// GetDedupMode returns the duplicate detection mode
+func (db *Database) GetDedupMode() DedupMode {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
-	return len(db.Items) >= db.maxItems-10
+	return db.dedupMode
+}
+
+// SetPinLimit sets how many items may be pinned
+// Lowering it below the current pin count unpins nothing; it only blocks new pins
+func (db *Database) SetPinLimit(limit int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if limit < 1 {
+		limit = DefaultPinLimit
+	}
+	db.pinLimit = limit
+}
+
+// SetClock replaces the clock used for timestamps, the grace window, dedup and retention
+// ages and the redaction budget, for tests and the harness in panotest
+func (db *Database) SetClock(now func() time.Time) {
+	if now == nil {
+		return
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.now = now
+	if db.redactor != nil {
+		db.redactor.now = now
+	}
 }
 
-// GetRemainingSlots returns how many more items can be added
-func (db *Database) GetRemainingSlots() int {
+// GetPinLimit returns the maximum number of pinned items
+func (db *Database) GetPinLimit() int {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
-	remaining := db.maxItems - len(db.Items)
-	if remaining < 0 {
-		return 0
+	return db.pinLimit
+}
+
+// RaiseDedupWindow merges captures into items younger than window until until, whatever
+// the dedup mode; the monitor uses it while another program keeps re-setting the clipboard
+func (db *Database) RaiseDedupWindow(window time.Duration, until time.Time) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.raisedDedupWindow = window
+	db.raisedDedupUntil = until
+}
+
+// isDuplicateCandidate reports whether existing may absorb a capture under the dedup mode (caller holds db.mu)
+func (db *Database) isDuplicateCandidate(existing ClipboardItem, now time.Time) bool {
+	// A capture of trashed content is new; the trashed item may still be restored
+	if existing.Deleted {
+		return false
+	}
+	if now.Before(db.raisedDedupUntil) && now.Sub(existing.Timestamp) < db.raisedDedupWindow {
+		return true
+	}
+	switch db.dedupMode {
+	case DedupOff:
+		return false
+	case DedupRecent:
+		return now.Sub(existing.Timestamp) < db.dedupWindow
+	default:
+		return true
 	}
-	return remaining
+}
+
+// GetGraceWindow returns how long new unpinned items are protected from eviction
+func (db *Database) GetGraceWindow() time.Duration {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.graceWindow
+}
+
+// GetMaxItems returns the current maximum items limit
+func (db *Database) GetMaxItems() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.maxItems
 }
 
 // SetOnLimitWarn sets callback for limit warning
@@ -107,29 +498,44 @@ func (db *Database) SetOnLimitWarn(callback func(remaining int)) {
 	db.onLimitWarn = callback
 }
 
-// IsFull returns true if at or over limit
-func (db *Database) IsFull() bool {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
-	// Count unpinned items only (pinned don't count toward limit)
-	unpinnedCount := 0
-	for _, item := range db.Items {
-		if !item.Pinned {
-			unpinnedCount++
+// GetDataDir returns Pano's data directory without creating it: under APPDATA where it
+// is set (always on Windows), otherwise under the user config directory (~/.config on
+// Linux, ~/Library/Application Support on macOS)
+func GetDataDir() (string, error) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate data directory: %w", err)
 		}
+		appData = configDir
 	}
-	return unpinnedCount >= db.maxItems
+	return filepath.Join(appData, "Pano"), nil
+}
+
+// TempDirTB is the part of testing.TB UseTempDataDir uses, so the package doesn't import
+// testing outside its tests
+type TempDirTB interface {
+	Helper()
+	Setenv(key, value string)
+	TempDir() string
+}
+
+// UseTempDataDir points GetDataDir, and with it the database, the key file and every
+// other file of the history, at a temp directory of tb until tb ends
+// For tests of this and other packages; tb must not be parallel
+func UseTempDataDir(tb TempDirTB) {
+	tb.Helper()
+	tb.Setenv("APPDATA", tb.TempDir())
 }
 
 // GetDatabasePath returns the full path to the database file
 func GetDatabasePath() (string, error) {
-	appData := os.Getenv("APPDATA")
-	if appData == "" {
-		return "", fmt.Errorf("APPDATA environment variable not set")
+	panoDir, err := GetDataDir()
+	if err != nil {
+		return "", err
 	}
 
-	panoDir := filepath.Join(appData, "Pano")
-
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(panoDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create Pano directory: %w", err)
@@ -142,28 +548,113 @@ func GetDatabasePath() (string, error) {
 func (db *Database) Load() error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	return db.loadInternal()
+}
 
+// loadInternal reads the database file and replays the journal (caller must hold lock)
+func (db *Database) loadInternal() error {
 	dbPath, err := GetDatabasePath()
 	if err != nil {
 		return err
 	}
 
-	data, err := os.ReadFile(dbPath)
-	if err != nil {
-		return err
+	// A history moved to the row store is read from there; the file isn't looked at
+	if db.sqlite == nil && SQLiteEnabled() {
+		sqlitePath, err := GetSQLitePath()
+		if err != nil {
+			return err
+		}
+		if db.sqlite, err = openSQLiteStore(sqlitePath); err != nil {
+			return err
+		}
 	}
 
-	// Decrypt the entire database
-	decrypted, err := Decrypt(string(data), db.key)
-	if err != nil {
-		return fmt.Errorf("failed to decrypt database: %w", err)
+	var data []byte
+	var readErr error
+	if db.sqlite == nil {
+		data, readErr = os.ReadFile(dbPath)
+		if readErr != nil && !os.IsNotExist(readErr) {
+			return readErr
+		}
 	}
 
-	// Parse JSON
-	if err := json.Unmarshal(decrypted, &db.Items); err != nil {
-		return fmt.Errorf("failed to parse database: %w", err)
+	legacy := false
+	if db.sqlite != nil {
+		items, err := db.sqlite.load([][]byte{db.key, db.pendingKey})
+		if err != nil {
+			return err
+		}
+		db.Items = items
+	} else if readErr == nil {
+		// Legacy JSON files are read as well and converted right away, see below
+		// An interrupted re-key may have written the file with the new key already
+		items, err := decodeWithKeys(data, [][]byte{db.key, db.pendingKey})
+		if err != nil {
+			return err
+		}
+		db.Items = items
+		legacy = !isBinaryFormat(data)
+	}
+
+	// Captures journaled after the last full save survive a crash
+	replayed := db.replayJournal() > 0
+	if legacy {
+		// The conversion gets a restore point; if either fails the file stays legacy
+		// and is tried again on the next load
+		_ = db.withSafetyBackup(SafetyMigration, db.saveInternal)
+	} else if replayed {
+		// If this save fails the journal keeps the records for the next start
+		_ = db.saveInternal()
+	} else if readErr != nil {
+		return readErr
+	}
+
+	// New IDs must sort after loaded ones even if the clock went backwards
+	for _, item := range db.Items {
+		if id, err := strconv.ParseInt(item.ID, 10, 64); err == nil && id > db.lastID {
+			db.lastID = id
+		}
+	}
+
+	if err := db.resumeRekey(); err != nil {
+		return fmt.Errorf("failed to finish re-keying: %w", err)
 	}
 
+	// Items from before previews existed get theirs once; if the save fails they are
+	// made again on the next load
+	if db.backfillPreviews() > 0 {
+		_ = db.saveInternal()
+	}
+	return nil
+}
+
+// LoadError returns why the database file couldn't be loaded, nil if it loaded
+func (db *Database) LoadError() error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.loadErr
+}
+
+// Reload reads the database file again after a failed load
+// Captures made meanwhile are in the journal and are replayed on top of the file
+func (db *Database) Reload() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.loadErr = nil
+	if err := db.loadInternal(); err != nil && !os.IsNotExist(err) {
+		db.loadErr = err
+		return err
+	}
+	// Captures kept in memory while the file was unreadable are written out now;
+	// if this fails they are still in the journal
+	_ = db.saveInternal()
+
+	ids := make([]string, 0, len(db.Items))
+	for _, item := range db.Items {
+		ids = append(ids, item.ID)
+	}
+	db.commit(ChangeReload, ids...)
 	return nil
 }
 
@@ -176,136 +667,437 @@ func (db *Database) Save() error {
 
 // saveInternal saves the database without locking (caller must hold lock)
 func (db *Database) saveInternal() error {
-	dbPath, err := GetDatabasePath()
-	if err != nil {
-		return err
+	// Writing now would replace the unreadable file with whatever is in memory
+	if db.loadErr != nil {
+		return fmt.Errorf("database not loaded: %w", db.loadErr)
 	}
 
-	// Convert to JSON
-	jsonData, err := json.Marshal(db.Items)
-	if err != nil {
-		return fmt.Errorf("failed to marshal database: %w", err)
-	}
+	if db.sqlite != nil {
+		// Only the rows that changed
+		if err := db.sqlite.save(db.Items, db.key); err != nil {
+			return err
+		}
+	} else {
+		dbPath, err := GetDatabasePath()
+		if err != nil {
+			return err
+		}
 
-	// Encrypt the entire database
-	encrypted, err := Encrypt(jsonData, db.key)
-	if err != nil {
-		return fmt.Errorf("failed to encrypt database: %w", err)
-	}
+		// Encode and encrypt the entire database
+		encoded, err := encodeDatabaseFile(db.Items, db.key)
+		if err != nil {
+			return err
+		}
 
-	// Write to file
-	if err := os.WriteFile(dbPath, []byte(encrypted), 0600); err != nil {
-		return fmt.Errorf("failed to write database: %w", err)
+		// Write to file
+		if err := os.WriteFile(dbPath, encoded, 0600); err != nil {
+			return fmt.Errorf("failed to write database: %w", err)
+		}
 	}
 
-	return nil
+	// Everything journaled is in the file now
+	db.dirty = false
+	return db.journal.reset()
+}
+
+// CaptureInfo carries what is known about a capture besides its content
+type CaptureInfo struct {
+	Forced    bool   // Captured manually, bypassing pause and exclusions
+	SourceURL string // Page the content was copied from, empty if unknown
+	SourceApp string // Executable name of the program that copied it, empty if unknown
+	RTF       []byte // Rich Text Format copied along with text, nil if none
+	Pinned    bool   // Store pinned; ErrPinLimitReached if the pin limit is full
 }
 
 // AddItem adds a new clipboard item
 func (db *Database) AddItem(itemType string, content []byte) error {
+	return db.addItem(itemType, content, CaptureInfo{})
+}
+
+// AddForcedItem adds an item captured manually and marks it as forced
+func (db *Database) AddForcedItem(itemType string, content []byte) error {
+	return db.addItem(itemType, content, CaptureInfo{Forced: true})
+}
+
+// AddCapturedItem adds an item together with its capture details
+func (db *Database) AddCapturedItem(itemType string, content []byte, info CaptureInfo) error {
+	return db.addItem(itemType, content, info)
+}
+
+// addItem stores a clipboard item with its capture details
+// Returns a *RejectError when the capture wasn't stored, ErrPinLimitReached when it was
+// to be pinned past the pin limit, and a *LimitWarning when it was stored close to the limit
+func (db *Database) addItem(itemType string, content []byte, info CaptureInfo) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
 	// Check size limit
 	if len(content) > MaxItemSize {
-		return fmt.Errorf("item size (%d bytes) exceeds maximum (%d bytes)", len(content), MaxItemSize)
+		return &RejectError{Reason: RejectTooLarge, Size: len(content), Limit: MaxItemSize}
+	}
+
+	// Text is classified so the UI can keep code layout intact
+	var class string
+	var original []byte
+	redacted := false
+	var rtf []byte
+	if itemType == "text" {
+		// Masked first, so nothing below (original URL, journal, archive) sees the unmasked text
+		masked, changed, err := db.redactor.Redact(string(content))
+		if err != nil {
+			return &RejectError{Reason: RejectRedaction, Err: fmt.Errorf("failed to redact content: %w", err)}
+		}
+		if changed {
+			content = []byte(masked)
+			redacted = true
+		}
+
+		class = ClassifyText(string(content))
+
+		// Tracking parameters are removed before hashing so cleaned duplicates collapse
+		if class == ClassURL && db.stripTracking {
+			if cleaned := CleanURL(string(content), db.trackingParams); cleaned != string(content) {
+				original = content
+				content = []byte(cleaned)
+			}
+		}
+
+		// Formatting is only kept for the text exactly as copied; masked or cleaned
+		// text would paste as the original through its RTF
+		if !redacted && original == nil {
+			rtf = info.RTF
+		}
+	}
+
+	// Images are decoded once, for the hash here and the perceptual hash below
+	var img *image.NRGBA
+	if itemType == "image" {
+		if decoded, err := decodePNG(content); err == nil {
+			img = decoded
+		}
 	}
 
 	// Calculate content hash for duplicate detection
-	contentHash := fmt.Sprintf("%x", sha256.Sum256(content))
+	hashes := newContentHashes(itemType, content, img)
 
-	// Check for duplicate (same content already exists)
+	// Check for duplicate (same content already exists), within the dedup mode's scope
+	now := db.now()
 	for i, existing := range db.Items {
-		if existing.Hash == contentHash && existing.Type == itemType {
+		if existing.Type == itemType && hashes.matches(existing) && db.isDuplicateCandidate(existing, now) {
+			pin := info.Pinned && !existing.Pinned
+			if pin && db.countItems().Pinned >= db.pinLimit {
+				return ErrPinLimitReached
+			}
 			// Move existing item to top instead of creating duplicate
 			db.Items = append([]ClipboardItem{existing}, append(db.Items[:i], db.Items[i+1:]...)...)
-			db.Items[0].Timestamp = time.Now()
-			return db.saveInternal()
+			db.Items[0].Timestamp = now
+			// A match on a legacy hash is as good a time as any to upgrade it
+			db.Items[0].Hash = hashes.canonical
+			db.Items[0].HashVersion = HashVersion
+			if info.Forced {
+				db.Items[0].Forced = true
+			}
+			if pin {
+				db.Items[0].Pinned = true
+				db.recordAudit(AuditPin, db.Items[0])
+			}
+			// A fresh copy from a page replaces where the content came from
+			if info.SourceURL != "" {
+				if encrypted, err := Encrypt([]byte(info.SourceURL), db.key); err == nil {
+					db.Items[0].SourceURL = encrypted
+				}
+			}
+			if info.SourceApp != "" {
+				if encrypted, err := Encrypt([]byte(info.SourceApp), db.key); err == nil {
+					db.Items[0].SourceApp = encrypted
+				}
+			}
+			if len(rtf) > 0 {
+				if encrypted, err := Encrypt(rtf, db.key); err == nil {
+					db.Items[0].RTF = encrypted
+				}
+			}
+			db.commit(ChangeUpdate, existing.ID)
+			if err := db.scheduleSave(); err != nil {
+				return &RejectError{Reason: RejectIO, Err: err}
+			}
+			return nil
 		}
 	}
 
-	// Count current unpinned items
-	unpinnedCount := 0
-	for _, item := range db.Items {
-		if !item.Pinned {
-			unpinnedCount++
+	if info.Pinned && db.countItems().Pinned >= db.pinLimit {
+		return ErrPinLimitReached
+	}
+
+	// Items kept over the limit by the grace window are trimmed once they age out
+	if db.enforceLimit() {
+		if err := db.scheduleSave(); err != nil {
+			return &RejectError{Reason: RejectIO, Err: err}
 		}
 	}
 
-	// Check if we're at the limit - don't add new items if full
-	if unpinnedCount >= db.maxItems {
-		return fmt.Errorf("LIMIT_FULL:0")
+	// At the limit the oldest unpinned item makes room, or with LimitReject the capture
+	// is refused. Items protected by the grace window count towards the limit: a burst
+	// over a lowered limit evicts one item per capture, so the history doesn't grow,
+	// and shrinks back once the protected items age out and enforceLimit trims them
+	counts := db.countItems()
+	evicted := false
+	if counts.IsFull() {
+		if db.limitPolicy == LimitReject || !db.evictOldest() {
+			return &RejectError{Reason: RejectLimitFull, Limit: db.maxItems}
+		}
+		counts = db.countItems()
+		evicted = true
 	}
 
-	// Calculate remaining slots for warning
-	remaining := db.maxItems - unpinnedCount - 1
-	warnNeeded := remaining <= 10 && remaining >= 0
+	// Slots left once this item is in, for the warning; evicting only warns once, when
+	// this capture fills the history
+	remaining := counts.Remaining() - 1
+	warnNeeded := remaining <= nearLimitSlots && remaining >= 0
+	if db.limitPolicy == LimitEvictOldest {
+		warnNeeded = remaining == 0 && !evicted
+	}
+
+	// Images get a perceptual hash and may be stored as a patch over a similar image
+	stored := content
+	var phash string
+	var delta *ImageDelta
+	if img != nil {
+		phash = formatPHash(perceptualHash(img))
+		if db.deltaImages {
+			if base, patch, offset, ok := db.findDeltaBase(img, phash); ok {
+				stored = patch
+				delta = &ImageDelta{BaseID: base.ID, X: offset.X, Y: offset.Y}
+			}
+		}
+	}
 
 	// Encrypt content
-	encrypted, err := Encrypt(content, db.key)
+	encrypted, err := Encrypt(stored, db.key)
+	if delta != nil {
+		Zero(stored)
+	}
+	if err != nil {
+		return &RejectError{Reason: RejectCrypto, Err: fmt.Errorf("failed to encrypt content: %w", err)}
+	}
+
+	var encryptedOriginal string
+	if original != nil {
+		encryptedOriginal, err = Encrypt(original, db.key)
+		if err != nil {
+			return &RejectError{Reason: RejectCrypto, Err: fmt.Errorf("failed to encrypt original content: %w", err)}
+		}
+	}
+
+	// The title is taken from the stored (masked) text, so cards needn't extract it
+	var encryptedTitle string
+	if itemType == "text" {
+		if title, _, ok := ExtractTitle(string(content)); ok {
+			encryptedTitle, err = Encrypt([]byte(title), db.key)
+			if err != nil {
+				return &RejectError{Reason: RejectCrypto, Err: fmt.Errorf("failed to encrypt title: %w", err)}
+			}
+		}
+	}
+
+	// Cards render from the preview, so listing never decrypts the content
+	encryptedPreview, err := makePreview(itemType, content, img, db.key)
 	if err != nil {
-		return fmt.Errorf("failed to encrypt content: %w", err)
+		return &RejectError{Reason: RejectCrypto, Err: err}
+	}
+
+	var encryptedSource string
+	if info.SourceURL != "" {
+		encryptedSource, err = Encrypt([]byte(info.SourceURL), db.key)
+		if err != nil {
+			return &RejectError{Reason: RejectCrypto, Err: fmt.Errorf("failed to encrypt source URL: %w", err)}
+		}
+	}
+
+	var encryptedApp string
+	if info.SourceApp != "" {
+		encryptedApp, err = Encrypt([]byte(info.SourceApp), db.key)
+		if err != nil {
+			return &RejectError{Reason: RejectCrypto, Err: fmt.Errorf("failed to encrypt source app: %w", err)}
+		}
+	}
+
+	var encryptedRTF string
+	if len(rtf) > 0 {
+		encryptedRTF, err = Encrypt(rtf, db.key)
+		if err != nil {
+			return &RejectError{Reason: RejectCrypto, Err: fmt.Errorf("failed to encrypt RTF: %w", err)}
+		}
 	}
 
 	// Create new item
 	item := ClipboardItem{
-		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
-		Type:      itemType,
-		Content:   encrypted,
-		Timestamp: time.Now(),
-		Pinned:    false,
-		Size:      len(content),
-		Hash:      contentHash,
+		ID:          db.newItemID(),
+		Type:        itemType,
+		Content:     encrypted,
+		Timestamp:   now,
+		Pinned:      info.Pinned,
+		Size:        len(content),
+		Hash:        hashes.canonical,
+		HashVersion: HashVersion,
+		PHash:       phash,
+		Delta:       delta,
+		Class:       class,
+		Original:    encryptedOriginal,
+		Forced:      info.Forced,
+		SourceURL:   encryptedSource,
+		SourceApp:   encryptedApp,
+		RTF:         encryptedRTF,
+		Redacted:    redacted,
+		TitleCache:  encryptedTitle,
+		Preview:     encryptedPreview,
 	}
 
 	// Add to beginning of list
 	db.Items = append([]ClipboardItem{item}, db.Items...)
+	db.commit(ChangeAdd, item.ID)
 
-	// Save to disk
-	if err := db.saveInternal(); err != nil {
-		return err
+	// The size cap always makes room by evicting the oldest unpinned items
+	db.enforceLimit()
+
+	if item.Pinned {
+		db.recordAudit(AuditPin, item)
+	}
+
+	// Journal first, so a crash during the full save can't lose the capture
+	// A failed append only costs crash safety; the full save below still runs
+	_ = db.journal.append(item)
+
+	// Written with the next timed save; the journal covers the gap
+	if err := db.scheduleSave(); err != nil {
+		return &RejectError{Reason: RejectIO, Err: err}
+	}
+
+	// Patches are already small; full images get the slow, smaller encoding later
+	if img != nil && delta == nil && db.recompressImages {
+		db.recompress.push(db, item.ID)
 	}
 
 	// Return warning signal if near limit
 	if warnNeeded {
-		return fmt.Errorf("LIMIT_WARN:%d", remaining)
+		return &LimitWarning{Remaining: remaining}
 	}
 	return nil
 }
 
-// enforceLimit removes oldest unpinned items to stay within maxItems
-func (db *Database) enforceLimit() {
-	if len(db.Items) <= db.maxItems {
-		return
+// enforceLimit removes oldest unpinned items to stay within maxItems and maxTotalSize
+// It decides from the same Counts the status bar shows: only unpinned items count
+// towards maxItems; pinned items are never removed and are capped by the pin limit
+// Unpinned items newer than the grace window are kept even if that exceeds the item
+// limit; the size cap only spares the newest unpinned item
+// Items in the trash don't count and are left for PurgeDeleted
+// Returns whether any item was removed
+func (db *Database) enforceLimit() bool {
+	counts := db.countItems()
+	if counts.Excess() == 0 && !counts.IsOverSize() {
+		return false
 	}
 
 	// Separate pinned and unpinned items
 	pinnedItems := make([]ClipboardItem, 0)
 	unpinnedItems := make([]ClipboardItem, 0)
+	trashed := make([]ClipboardItem, 0)
 
 	for _, item := range db.Items {
-		if item.Pinned {
+		if item.Deleted {
+			trashed = append(trashed, item)
+		} else if item.Pinned {
 			pinnedItems = append(pinnedItems, item)
 		} else {
 			unpinnedItems = append(unpinnedItems, item)
 		}
 	}
 
-	// If pinned items exceed maxItems, keep only the newest pinned items
-	if len(pinnedItems) > db.maxItems {
-		pinnedItems = pinnedItems[:db.maxItems]
+	// Keep the newest unpinned items up to the limit, plus anything still inside the grace window
+	cutoff := db.now().Add(-db.graceWindow)
+	keptUnpinned := make([]ClipboardItem, 0, len(unpinnedItems))
+	for i, item := range unpinnedItems {
+		if i < counts.Limit || (db.graceWindow > 0 && item.Timestamp.After(cutoff)) {
+			keptUnpinned = append(keptUnpinned, item)
+		}
+	}
+	unpinnedItems = keptUnpinned
+
+	// Then drop the oldest of those until the sizes fit; pinned items alone may exceed the cap
+	if db.maxTotalSize > 0 {
+		size := totalSize(pinnedItems) + totalSize(unpinnedItems)
+		for len(unpinnedItems) > 1 && size > db.maxTotalSize {
+			size -= int64(unpinnedItems[len(unpinnedItems)-1].Size)
+			unpinnedItems = unpinnedItems[:len(unpinnedItems)-1]
+		}
 	}
 
-	// Calculate how many unpinned items we can keep
-	availableSlots := db.maxItems - len(pinnedItems)
+	// Combine: pinned items first, then unpinned items, then the trash
+	kept := append(pinnedItems, unpinnedItems...)
+	kept = append(kept, trashed...)
 
-	// Keep the newest unpinned items
-	if len(unpinnedItems) > availableSlots {
-		unpinnedItems = unpinnedItems[:availableSlots]
+	keptIDs := make(map[string]bool, len(kept))
+	for _, item := range kept {
+		keptIDs[item.ID] = true
 	}
+	return db.evictExcept(keptIDs)
+}
 
-	// Combine: pinned items first, then unpinned items
-	db.Items = append(pinnedItems, unpinnedItems...)
+// evictOldest removes the oldest unpinned item, regardless of the grace window, to
+// make room for a capture; returns false if every item is pinned
+func (db *Database) evictOldest() bool {
+	for i := len(db.Items) - 1; i >= 0; i-- {
+		if db.Items[i].Pinned || db.Items[i].Deleted {
+			continue
+		}
+		keptIDs := make(map[string]bool, len(db.Items)-1)
+		for _, item := range db.Items {
+			keptIDs[item.ID] = item.ID != db.Items[i].ID
+		}
+		return db.evictExcept(keptIDs)
+	}
+	return false
+}
+
+// evictExcept removes every item not in keptIDs, moving it to the archive if enabled
+// Returns whether any item was removed
+func (db *Database) evictExcept(keptIDs map[string]bool) bool {
+	// Dropped base items must hand their pixels to surviving delta items first
+	for _, item := range db.Items {
+		if !keptIDs[item.ID] {
+			// A failure leaves the dependent unreadable, which is no worse than losing it
+			_ = db.materializeDependents(item.ID)
+		}
+	}
+
+	// Re-read items since materialization may have rewritten their content
+	result := make([]ClipboardItem, 0, len(keptIDs))
+	dropped := make([]ClipboardItem, 0)
+	evicted := make([]string, 0)
+	for i := range db.Items {
+		if keptIDs[db.Items[i].ID] {
+			result = append(result, db.Items[i])
+			continue
+		}
+		evicted = append(evicted, db.Items[i].ID)
+		if db.archiveEnabled && db.materializeItem(&db.Items[i]) == nil {
+			dropped = append(dropped, db.Items[i])
+		}
+	}
+
+	// Oldest first, so the archive stays in capture order
+	for i, j := 0, len(dropped)-1; i < j; i, j = i+1, j-1 {
+		dropped[i], dropped[j] = dropped[j], dropped[i]
+	}
+	// Archive failures fall back to the old delete behaviour
+	_ = db.archive.Append(dropped)
+
+	db.Items = result
+	if len(evicted) == 0 {
+		return false
+	}
+	db.commit(ChangeEvict, evicted...)
+	return true
 }
 
 // GetItem retrieves and decrypts an item by ID
@@ -320,6 +1112,13 @@ func (db *Database) GetItem(id string) (*ClipboardItem, []byte, error) {
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to decrypt item: %w", err)
 			}
+			// Delta images are rebuilt from their base so callers always get the full image
+			if item.Delta != nil {
+				decrypted, err = db.reconstructImage(&db.Items[i], decrypted)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to reconstruct image: %w", err)
+				}
+			}
 			// Return a copy to avoid race conditions
 			itemCopy := db.Items[i]
 			return &itemCopy, decrypted, nil
@@ -328,45 +1127,247 @@ func (db *Database) GetItem(id string) (*ClipboardItem, []byte, error) {
 	return nil, nil, fmt.Errorf("item not found")
 }
 
+// GetItemOriginal returns the decrypted pre-cleaning content of an item
+func (db *Database) GetItemOriginal(id string) ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, item := range db.Items {
+		if item.ID == id {
+			if item.Original == "" {
+				return nil, fmt.Errorf("item has no original content")
+			}
+			decrypted, err := Decrypt(item.Original, db.key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt item: %w", err)
+			}
+			return decrypted, nil
+		}
+	}
+	return nil, fmt.Errorf("item not found")
+}
+
+// GetItemTitle returns the decrypted implicit title of an item, "" if it has none
+func (db *Database) GetItemTitle(id string) (string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, item := range db.Items {
+		if item.ID == id {
+			if item.TitleCache == "" {
+				return "", nil
+			}
+			decrypted, err := Decrypt(item.TitleCache, db.key)
+			if err != nil {
+				return "", fmt.Errorf("failed to decrypt title: %w", err)
+			}
+			return string(decrypted), nil
+		}
+	}
+	return "", fmt.Errorf("item not found")
+}
+
+// GetItemSourceURL returns the decrypted source page URL of an item, "" if it has none
+func (db *Database) GetItemSourceURL(id string) (string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, item := range db.Items {
+		if item.ID == id {
+			if item.SourceURL == "" {
+				return "", nil
+			}
+			decrypted, err := Decrypt(item.SourceURL, db.key)
+			if err != nil {
+				return "", fmt.Errorf("failed to decrypt source URL: %w", err)
+			}
+			return string(decrypted), nil
+		}
+	}
+	return "", fmt.Errorf("item not found")
+}
+
+// GetItemSourceApp returns the decrypted name of the program an item was copied from,
+// "" if unknown
+func (db *Database) GetItemSourceApp(id string) (string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, item := range db.Items {
+		if item.ID == id {
+			if item.SourceApp == "" {
+				return "", nil
+			}
+			decrypted, err := Decrypt(item.SourceApp, db.key)
+			if err != nil {
+				return "", fmt.Errorf("failed to decrypt source app: %w", err)
+			}
+			return string(decrypted), nil
+		}
+	}
+	return "", fmt.Errorf("item not found")
+}
+
+// GetItemRTF returns the decrypted Rich Text Format of a text item, nil if it has none
+func (db *Database) GetItemRTF(id string) ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, item := range db.Items {
+		if item.ID == id {
+			if item.RTF == "" {
+				return nil, nil
+			}
+			decrypted, err := Decrypt(item.RTF, db.key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt RTF: %w", err)
+			}
+			return decrypted, nil
+		}
+	}
+	return nil, fmt.Errorf("item not found")
+}
+
 // TogglePin toggles the pinned status of an item
+// Pinning fails with ErrPinLimitReached once the pin limit is reached; unpinning always works
 func (db *Database) TogglePin(id string) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
 	for i, item := range db.Items {
 		if item.ID == id {
+			if !item.Pinned && db.countItems().Pinned >= db.pinLimit {
+				return ErrPinLimitReached
+			}
 			db.Items[i].Pinned = !item.Pinned
-			return db.saveInternal()
+			db.commit(ChangeUpdate, id)
+			if item.Pinned {
+				db.recordAudit(AuditUnpin, item)
+			} else {
+				db.recordAudit(AuditPin, item)
+			}
+			return db.scheduleSave()
+		}
+	}
+	return fmt.Errorf("item not found")
+}
+
+// MarkUsed counts a copy of the item back to the clipboard
+func (db *Database) MarkUsed(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i := range db.Items {
+		if db.Items[i].ID == id {
+			db.Items[i].CopyCount++
+			db.Items[i].LastUsed = db.now()
+			db.commit(ChangeUpdate, id)
+			return db.scheduleSave()
 		}
 	}
 	return fmt.Errorf("item not found")
 }
 
-// DeleteItem removes an item from the database
+// DeleteItem moves an item to the trash, see RestoreItem
 func (db *Database) DeleteItem(id string) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
 	for i, item := range db.Items {
-		if item.ID == id {
-			db.Items = append(db.Items[:i], db.Items[i+1:]...)
-			return db.saveInternal()
+		if item.ID == id && !item.Deleted {
+			db.trashItem(&db.Items[i])
+			db.recordAudit(AuditDelete, item)
+			db.commit(ChangeDelete, id)
+			return db.scheduleSave()
 		}
 	}
 	return fmt.Errorf("item not found")
 }
 
+// DeleteItems moves the items with ids to the trash in one go and returns how many went
+// IDs no longer in the history are skipped
+func (db *Database) DeleteItems(ids []string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+	removed := make([]ClipboardItem, 0, len(ids))
+	removedIDs := make([]string, 0, len(ids))
+	for i := range db.Items {
+		if item := &db.Items[i]; remove[item.ID] && !item.Deleted {
+			removed = append(removed, *item)
+			removedIDs = append(removedIDs, item.ID)
+			db.trashItem(item)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	db.recordAuditCount(AuditDelete, removed)
+	db.commit(ChangeDelete, removedIDs...)
+	return len(removed), db.scheduleSave()
+}
+
 // GetAllItems returns all items (metadata only, no decrypted content)
 // Pinned items are returned first, then unpinned items by timestamp
 func (db *Database) GetAllItems() []ClipboardItem {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
+	return db.orderedItems()
+}
+
+// GetItemsByType returns the items of one type ("" for every type), pinned first
+// With pinnedOnly only pinned items are returned; only metadata is looked at
+func (db *Database) GetItemsByType(itemType string, pinnedOnly bool) []ClipboardItem {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	result := make([]ClipboardItem, 0)
+	for _, item := range db.orderedItems() {
+		if (itemType == "" || item.Type == itemType) && (!pinnedOnly || item.Pinned) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// GetItems returns up to limit items starting at offset, ordered like GetAllItems, and
+// the number of items in the history; pages of an unchanged history never overlap
+func (db *Database) GetItems(offset, limit int) ([]ClipboardItem, int) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// Walked in place like orderedItems, so only the page is copied
+	page := make([]ClipboardItem, 0, max(min(limit, len(db.Items)), 0))
+	total := 0
+	for _, pinned := range []bool{true, false} {
+		for _, item := range db.Items {
+			if item.Deleted || item.Pinned != pinned {
+				continue
+			}
+			if total >= offset && len(page) < limit {
+				page = append(page, item)
+			}
+			total++
+		}
+	}
+	return page, total
+}
 
+// orderedItems lists pinned items first (caller holds db.mu)
+func (db *Database) orderedItems() []ClipboardItem {
 	// Separate pinned and unpinned items
 	pinned := make([]ClipboardItem, 0)
 	unpinned := make([]ClipboardItem, 0)
 
 	for _, item := range db.Items {
+		if item.Deleted {
+			continue
+		}
 		if item.Pinned {
 			pinned = append(pinned, item)
 		} else {
@@ -375,38 +1376,144 @@ func (db *Database) GetAllItems() []ClipboardItem {
 	}
 
 	// Return pinned first, then unpinned
-	result := make([]ClipboardItem, 0, len(db.Items))
+	result := make([]ClipboardItem, 0, len(pinned)+len(unpinned))
 	result = append(result, pinned...)
 	result = append(result, unpinned...)
 	return result
 }
 
-// ClearAll removes all items from the database
+// ClearAll removes all items from the database, the trash included, after saving them
+// to a restore point
 func (db *Database) ClearAll() error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	db.Items = make([]ClipboardItem, 0)
+	return db.withSafetyBackup(SafetyClear, func() error {
+		// Items in the trash were already recorded when they were deleted
+		if visible := db.orderedItems(); len(visible) > 0 {
+			db.recordAuditCount(AuditClear, visible)
+		}
+		db.Items = make([]ClipboardItem, 0)
+		db.commit(ChangeClear)
+		return db.saveInternal()
+	})
+}
+
+// RestoreItems puts previously removed items back (undo for delete and clear)
+// Items whose ID is still present are skipped; history order follows the timestamps
+func (db *Database) RestoreItems(items []ClipboardItem) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	present := make(map[string]bool, len(db.Items))
+	for _, item := range db.Items {
+		present[item.ID] = true
+	}
+	restored := make([]string, 0, len(items))
+	restoredItems := make([]ClipboardItem, 0, len(items))
+	for _, item := range items {
+		if !present[item.ID] {
+			db.Items = append(db.Items, item)
+			present[item.ID] = true
+			restored = append(restored, item.ID)
+			restoredItems = append(restoredItems, item)
+		}
+	}
+	if len(restored) == 0 {
+		return nil
+	}
+	db.commit(ChangeRestore, restored...)
+	db.recordAuditCount(AuditRestore, restoredItems)
+
+	sort.SliceStable(db.Items, func(i, j int) bool {
+		return db.Items[i].Timestamp.After(db.Items[j].Timestamp)
+	})
 	return db.saveInternal()
 }
 
-// GetItemCount returns the number of items in the database
-func (db *Database) GetItemCount() int {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
-	return len(db.Items)
+// Counts is a snapshot of item counts; the limit checks are derived from it so they
+// always agree with each other
+type Counts struct {
+	Active   int // Unpinned items in the history; only these count towards Limit
+	Pinned   int // Pinned items, capped by the pin limit instead
+	Archived int // Items in the archive, 0 if it can't be read
+	Trashed  int // Deleted items still in the trash; they count towards nothing
+	Limit    int // Maximum number of unpinned items
+
+	Bytes     int64 // Summed size of the history, pinned included
+	SizeLimit int64 // Cap on Bytes, 0 if there is none
+
+	Text   int // Text items in the history, pinned or not
+	Images int // Image items in the history, pinned or not
 }
 
-// GetPinnedCount returns the number of pinned items
-func (db *Database) GetPinnedCount() int {
+// Total returns the number of items in the history
+func (c Counts) Total() int {
+	return c.Active + c.Pinned
+}
+
+// Remaining returns how many more items can be captured before the limit
+func (c Counts) Remaining() int {
+	return max(c.Limit-c.Active, 0)
+}
+
+// IsFull reports whether the unpinned items are at the limit, so a capture evicts the
+// oldest or is refused (see LimitPolicy)
+func (c Counts) IsFull() bool {
+	return c.Active >= c.Limit
+}
+
+// Excess returns how many unpinned items are over the limit, e.g. after it was lowered
+func (c Counts) Excess() int {
+	return max(c.Active-c.Limit, 0)
+}
+
+// IsOverSize reports whether the history is over its size cap
+func (c Counts) IsOverSize() bool {
+	return c.SizeLimit > 0 && c.Bytes > c.SizeLimit
+}
+
+// IsNearLimit reports whether only a few slots are left
+func (c Counts) IsNearLimit() bool {
+	return c.Remaining() <= nearLimitSlots
+}
+
+// Counts returns the item counts, taken in one pass under the read lock
+func (db *Database) Counts() Counts {
 	db.mu.RLock()
-	defer db.mu.RUnlock()
+	counts := db.countItems()
+	db.mu.RUnlock()
 
-	count := 0
+	// The archive has its own lock and may read its file the first time
+	if db.archive != nil {
+		if archived, err := db.archive.Count(); err == nil {
+			counts.Archived = archived
+		}
+	}
+	return counts
+}
+
+// countItems counts the history without the archive; the trash is only counted in
+// Trashed (caller must hold lock)
+func (db *Database) countItems() Counts {
+	counts := Counts{Limit: db.maxItems, SizeLimit: db.maxTotalSize}
 	for _, item := range db.Items {
+		if item.Deleted {
+			counts.Trashed++
+			continue
+		}
+		counts.Bytes += int64(item.Size)
 		if item.Pinned {
-			count++
+			counts.Pinned++
+		} else {
+			counts.Active++
+		}
+		switch item.Type {
+		case "text":
+			counts.Text++
+		case "image":
+			counts.Images++
 		}
 	}
-	return count
+	return counts
 }
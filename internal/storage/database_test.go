@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestDB opens a database in a temp directory; it is closed when t ends
+func newTestDB(t testing.TB) *Database {
+	t.Helper()
+	UseTempDataDir(t)
+	db, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// TestConcurrentMutations runs captures, pins, deletes and reads from several
+// goroutines at once and checks the consistency contract in changes.go: events
+// arrive in commit order and every snapshot is whole. Meant for go test -race
+func TestConcurrentMutations(t *testing.T) {
+	db := newTestDB(t)
+
+	var events sync.Mutex
+	var lastSeq uint64
+	var outOfOrder []string
+	db.OnChange(func(event ChangeEvent) {
+		events.Lock()
+		defer events.Unlock()
+		if event.Seq != lastSeq+1 {
+			outOfOrder = append(outOfOrder, fmt.Sprintf("%d after %d", event.Seq, lastSeq))
+		}
+		lastSeq = event.Seq
+	})
+
+	duration := 2 * time.Second
+	if testing.Short() {
+		duration = 200 * time.Millisecond
+	}
+	deadline := time.Now().Add(duration)
+	pinLimit := db.pinLimit // Not changed while the workers run
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for worker := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(worker)))
+			var seenSeq uint64
+			for i := 0; time.Now().Before(deadline); i++ {
+				switch rng.Intn(5) {
+				case 0, 1:
+					// Duplicates and the limit may refuse a capture; that's fine here
+					_ = db.AddItem("text", fmt.Appendf(nil, "worker %d item %d", worker, rng.Intn(50)))
+				case 2:
+					if items := db.GetAllItems(); len(items) > 0 {
+						_ = db.TogglePin(items[rng.Intn(len(items))].ID)
+					}
+				case 3:
+					if items := db.GetAllItems(); len(items) > 0 {
+						_ = db.DeleteItem(items[rng.Intn(len(items))].ID)
+					}
+				case 4:
+					items, seq := db.Snapshot()
+					if seq < seenSeq {
+						errs <- fmt.Errorf("snapshot went back from seq %d to %d", seenSeq, seq)
+						return
+					}
+					seenSeq = seq
+					ids := make(map[string]bool, len(items))
+					for _, item := range items {
+						if ids[item.ID] {
+							errs <- fmt.Errorf("snapshot at seq %d has item %s twice", seq, item.ID)
+							return
+						}
+						ids[item.ID] = true
+					}
+					counts := db.Counts()
+					if counts.Active < 0 || counts.Pinned < 0 || counts.Pinned > pinLimit {
+						errs <- fmt.Errorf("inconsistent counts %+v", counts)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	items, seq := db.Snapshot()
+	if counts := db.Counts(); counts.Total() != len(items) {
+		t.Errorf("counts total %d, snapshot has %d items", counts.Total(), len(items))
+	}
+	// Close delivers every queued event before it returns
+	if err := db.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	events.Lock()
+	defer events.Unlock()
+	if len(outOfOrder) > 0 {
+		t.Errorf("events out of commit order: %v", outOfOrder)
+	}
+	if lastSeq != seq {
+		t.Errorf("last event has seq %d, database is at %d", lastSeq, seq)
+	}
+}
+
+// TestAddItemHammer captures from several goroutines at once, with the saves coalesced,
+// and checks that no capture is lost across a reopen, that a shared duplicate is kept
+// once, and that a small limit holds
+func TestAddItemHammer(t *testing.T) {
+	const workers, perWorker = 8, 40
+
+	db := newTestDB(t)
+	db.SetMaxItems(500)
+
+	var wg sync.WaitGroup
+	for worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				if err := db.AddItem("text", fmt.Appendf(nil, "worker %d item %d", worker, i)); err != nil {
+					t.Errorf("failed to add: %v", err)
+				}
+				// Every worker copies the same text too; it must stay one item
+				if err := db.AddItem("text", []byte("shared")); err != nil {
+					t.Errorf("failed to add duplicate: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := db.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	reopened, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	counts := map[string]int{}
+	for _, item := range reopened.GetAllItems() {
+		_, content, err := reopened.GetItem(item.ID)
+		if err != nil {
+			t.Fatalf("failed to read item: %v", err)
+		}
+		counts[string(content)]++
+	}
+	if len(counts) != workers*perWorker+1 {
+		t.Errorf("reopened history has %d distinct texts, want %d", len(counts), workers*perWorker+1)
+	}
+	for text, n := range counts {
+		if n != 1 {
+			t.Errorf("%q stored %d times", text, n)
+		}
+	}
+
+	// With a small limit concurrent captures evict instead of overshooting it
+	limited := newTestDB(t)
+	limited.SetGraceWindow(0)
+	limited.SetMaxItems(10)
+	for worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				_ = limited.AddItem("text", fmt.Appendf(nil, "limited %d %d", worker, i))
+			}
+		}()
+	}
+	wg.Wait()
+	if c := limited.Counts(); c.Active > c.Limit {
+		t.Errorf("%d unpinned items over a limit of %d", c.Active, c.Limit)
+	}
+}
+
+// addTexts adds text items in order; a *LimitWarning is no failure
+func addTexts(t *testing.T, db *Database, texts ...string) {
+	t.Helper()
+	for _, text := range texts {
+		var warning *LimitWarning
+		if err := db.AddItem("text", []byte(text)); err != nil && !errors.As(err, &warning) {
+			t.Fatalf("failed to add %q: %v", text, err)
+		}
+	}
+}
+
+// numbered returns "<prefix> 1" to "<prefix> n"
+func numbered(prefix string, n int) []string {
+	texts := make([]string, n)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("%s %d", prefix, i+1)
+	}
+	return texts
+}
+
+// TestReloadAfterLoadError opens a history whose file can't be read: captures made
+// meanwhile must not overwrite the file, and once it reads again Reload brings back both
+// the file's items and those captures
+func TestReloadAfterLoadError(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "bir", "iki")
+	db.Close()
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		t.Fatalf("failed to locate database: %v", err)
+	}
+	good, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read database: %v", err)
+	}
+	damaged := []byte("PANO okunamaz")
+	if err := os.WriteFile(dbPath, damaged, 0600); err != nil {
+		t.Fatalf("failed to damage database: %v", err)
+	}
+
+	db, err = NewDatabase()
+	if err != nil {
+		t.Fatalf("an unreadable file failed startup: %v", err)
+	}
+	defer db.Close()
+	if db.LoadError() == nil {
+		t.Fatal("no load error for an unreadable file")
+	}
+	var reject *RejectError
+	// The capture is kept in memory and the journal; only its save is refused
+	if err := db.AddItem("text", []byte("üç")); !errors.As(err, &reject) || reject.Reason != RejectIO {
+		t.Errorf("a capture into the unloaded history gave %v, want a failed save", err)
+	}
+	if err := db.Save(); err == nil {
+		t.Error("saved over the unreadable file")
+	}
+	if data, _ := os.ReadFile(dbPath); !bytes.Equal(data, damaged) {
+		t.Fatal("the unreadable file was replaced")
+	}
+
+	if err := os.WriteFile(dbPath, good, 0600); err != nil {
+		t.Fatalf("failed to repair database: %v", err)
+	}
+	// Listeners run on the feed's goroutine
+	reloaded := make(chan []string, 1)
+	db.OnChange(func(e ChangeEvent) {
+		if e.Kind == ChangeReload {
+			reloaded <- e.IDs
+		}
+	})
+	if err := db.Reload(); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if db.LoadError() != nil {
+		t.Errorf("load error %v after a successful reload", db.LoadError())
+	}
+	if got := historyTexts(t, db); !slices.Equal(got, []string{"üç", "iki", "bir"}) {
+		t.Errorf("history after the reload is %q", got)
+	}
+	select {
+	case ids := <-reloaded:
+		if len(ids) != 3 {
+			t.Errorf("reload event names %d items, want 3", len(ids))
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("no reload event")
+	}
+	if err := db.Flush(); err != nil {
+		t.Errorf("failed to save after the reload: %v", err)
+	}
+}
+
+// TestGetDataDir keeps the data under APPDATA where it is set and falls back to the user
+// config directory elsewhere, without creating either
+func TestGetDataDir(t *testing.T) {
+	appData := t.TempDir()
+	t.Setenv("APPDATA", appData)
+	dir, err := GetDataDir()
+	if err != nil || dir != filepath.Join(appData, "Pano") {
+		t.Errorf("data directory is %q, %v under APPDATA", dir, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("the data directory was created: %v", err)
+	}
+
+	t.Setenv("APPDATA", "")
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("HOME", config)
+	want, wantErr := os.UserConfigDir()
+	dir, err = GetDataDir()
+	switch {
+	case wantErr != nil:
+		if err == nil {
+			t.Errorf("data directory is %q without a config directory", dir)
+		}
+	case err != nil || dir != filepath.Join(want, "Pano"):
+		t.Errorf("data directory is %q, %v without APPDATA, want it under %q", dir, err, want)
+	}
+}
@@ -0,0 +1,289 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/bits"
+	"strconv"
+)
+
+const (
+	deltaHashThreshold = 10  // Max perceptual hash distance (bits) to consider images similar
+	deltaSearchDepth   = 10  // How many recent full images are checked as a base
+	deltaMaxAreaRatio  = 0.5 // Diff region larger than this fraction of the image is stored in full
+)
+
+// ImageDelta describes an image item stored as a patch on top of a base item
+type ImageDelta struct {
+	BaseID string `json:"base_id"`
+	X      int    `json:"x"` // Top-left corner of the patch inside the base image
+	Y      int    `json:"y"`
+}
+
+// decodePNG decodes PNG bytes into an NRGBA image so pixels can be compared exactly
+func decodePNG(data []byte) (*image.NRGBA, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return toNRGBA(img), nil
+}
+
+// encodePNG encodes an image to PNG bytes
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// toNRGBA converts any image to a zero-origin NRGBA image
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	if n, ok := img.(*image.NRGBA); ok && bounds.Min == (image.Point{}) {
+		return n
+	}
+
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			out.SetNRGBA(x, y, c)
+		}
+	}
+	return out
+}
+
+// perceptualHash computes a 64-bit difference hash (dHash) of an image
+func perceptualHash(img *image.NRGBA) uint64 {
+	const w, h = 9, 8
+	bounds := img.Bounds()
+
+	// Sample a 9x8 grayscale grid
+	var gray [h][w]uint32
+	for y := 0; y < h; y++ {
+		srcY := y * bounds.Dy() / h
+		for x := 0; x < w; x++ {
+			srcX := x * bounds.Dx() / w
+			c := img.NRGBAAt(srcX, srcY)
+			gray[y][x] = (299*uint32(c.R) + 587*uint32(c.G) + 114*uint32(c.B)) / 1000
+		}
+	}
+
+	// Each bit records whether brightness increases left to right
+	var hash uint64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w-1; x++ {
+			hash <<= 1
+			if gray[y][x] < gray[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// formatPHash formats a perceptual hash for storage
+func formatPHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// phashDistance returns the number of differing bits between two stored hashes
+func phashDistance(a, b string) (int, bool) {
+	ha, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	hb, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	return bits.OnesCount64(ha ^ hb), true
+}
+
+// diffRect returns the smallest rectangle containing every differing pixel
+// Both images must have the same dimensions
+func diffRect(a, b *image.NRGBA) image.Rectangle {
+	bounds := a.Bounds()
+	minX, minY := bounds.Dx(), bounds.Dy()
+	maxX, maxY := -1, -1
+
+	for y := 0; y < bounds.Dy(); y++ {
+		rowA := a.Pix[y*a.Stride : y*a.Stride+bounds.Dx()*4]
+		rowB := b.Pix[y*b.Stride : y*b.Stride+bounds.Dx()*4]
+		if bytes.Equal(rowA, rowB) {
+			continue
+		}
+		for x := 0; x < bounds.Dx(); x++ {
+			if !bytes.Equal(rowA[x*4:x*4+4], rowB[x*4:x*4+4]) {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+			}
+		}
+		if y < minY {
+			minY = y
+		}
+		maxY = y
+	}
+
+	if maxX < 0 {
+		return image.Rectangle{}
+	}
+	return image.Rect(minX, minY, maxX+1, maxY+1)
+}
+
+// cropNRGBA copies the given region of an image into a new zero-origin image
+func cropNRGBA(img *image.NRGBA, rect image.Rectangle) *image.NRGBA {
+	out := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := 0; y < rect.Dy(); y++ {
+		src := img.Pix[(rect.Min.Y+y)*img.Stride+rect.Min.X*4 : (rect.Min.Y+y)*img.Stride+rect.Max.X*4]
+		copy(out.Pix[y*out.Stride:], src)
+	}
+	return out
+}
+
+// applyPatch returns a copy of base with patch drawn at (x, y)
+func applyPatch(base, patch *image.NRGBA, x, y int) (*image.NRGBA, error) {
+	rect := image.Rect(x, y, x+patch.Bounds().Dx(), y+patch.Bounds().Dy())
+	if !rect.In(base.Bounds()) {
+		return nil, fmt.Errorf("patch %v outside base image %v", rect, base.Bounds())
+	}
+
+	out := image.NewNRGBA(base.Bounds())
+	copy(out.Pix, base.Pix)
+	for row := 0; row < rect.Dy(); row++ {
+		dst := out.Pix[(y+row)*out.Stride+x*4 : (y+row)*out.Stride+rect.Max.X*4]
+		copy(dst, patch.Pix[row*patch.Stride:])
+	}
+	return out, nil
+}
+
+// findDeltaBase looks for a recent full image similar enough to store img as a patch
+// Returns the base item, the patch PNG and its offset, or ok=false (caller must hold lock)
+func (db *Database) findDeltaBase(img *image.NRGBA, phash string) (base *ClipboardItem, patch []byte, offset image.Point, ok bool) {
+	checked := 0
+	for i := range db.Items {
+		candidate := &db.Items[i]
+		if candidate.Type != "image" || candidate.Delta != nil || candidate.PHash == "" || candidate.Deleted {
+			continue
+		}
+		if checked >= deltaSearchDepth {
+			break
+		}
+		checked++
+
+		if dist, valid := phashDistance(candidate.PHash, phash); !valid || dist > deltaHashThreshold {
+			continue
+		}
+
+		data, err := Decrypt(candidate.Content, db.key)
+		if err != nil {
+			continue
+		}
+		baseImg, err := decodePNG(data)
+		Zero(data)
+		if err != nil || baseImg.Bounds() != img.Bounds() {
+			continue
+		}
+
+		rect := diffRect(baseImg, img)
+		if rect.Empty() {
+			// Pixel-identical but differently encoded; a 1x1 patch keeps things simple
+			rect = image.Rect(0, 0, 1, 1)
+		}
+		area := float64(rect.Dx()*rect.Dy()) / float64(img.Bounds().Dx()*img.Bounds().Dy())
+		if area > deltaMaxAreaRatio {
+			continue
+		}
+
+		patch, err := encodePNG(cropNRGBA(img, rect))
+		if err != nil {
+			continue
+		}
+		return candidate, patch, rect.Min, true
+	}
+	return nil, nil, image.Point{}, false
+}
+
+// reconstructImage rebuilds the full PNG of a delta item from its decrypted patch (caller must hold lock)
+func (db *Database) reconstructImage(item *ClipboardItem, patchData []byte) ([]byte, error) {
+	var base *ClipboardItem
+	for i := range db.Items {
+		if db.Items[i].ID == item.Delta.BaseID {
+			base = &db.Items[i]
+			break
+		}
+	}
+	if base == nil {
+		return nil, fmt.Errorf("base item %s not found", item.Delta.BaseID)
+	}
+
+	baseData, err := Decrypt(base.Content, db.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt base item: %w", err)
+	}
+	baseImg, err := decodePNG(baseData)
+	Zero(baseData)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := decodePNG(patchData)
+	if err != nil {
+		return nil, err
+	}
+
+	full, err := applyPatch(baseImg, patch, item.Delta.X, item.Delta.Y)
+	if err != nil {
+		return nil, err
+	}
+	return encodePNG(full)
+}
+
+// materializeDependents converts every delta item referencing baseID into a full image
+// Must run before the base item is removed (caller must hold lock)
+func (db *Database) materializeDependents(baseID string) error {
+	for i := range db.Items {
+		item := &db.Items[i]
+		if item.Delta == nil || item.Delta.BaseID != baseID {
+			continue
+		}
+		if err := db.materializeItem(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// materializeItem replaces a delta item's patch with the full image (caller must hold lock)
+func (db *Database) materializeItem(item *ClipboardItem) error {
+	if item.Delta == nil {
+		return nil
+	}
+
+	patchData, err := Decrypt(item.Content, db.key)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt item: %w", err)
+	}
+	full, err := db.reconstructImage(item, patchData)
+	Zero(patchData)
+	if err != nil {
+		return fmt.Errorf("failed to materialize item %s: %w", item.ID, err)
+	}
+	encrypted, err := Encrypt(full, db.key)
+	Zero(full)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt content: %w", err)
+	}
+
+	item.Content = encrypted
+	item.Delta = nil
+	return nil
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+// screenshot draws a gradient "window" with a small square whose color depends on mark,
+// so two marks differ only inside the square
+func screenshot(mark uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 160, 120))
+	for y := range 120 {
+		for x := range 160 {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y * 2), B: 90, A: 255})
+		}
+	}
+	for y := 40; y < 52; y++ {
+		for x := 70; x < 82; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: mark, G: 255 - mark, B: mark / 2, A: 255})
+		}
+	}
+	return img
+}
+
+// addScreenshot stores img and returns the new item, which is the newest
+func addScreenshot(t *testing.T, db *Database, img *image.NRGBA) ClipboardItem {
+	t.Helper()
+	data, err := encodePNG(img)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	if err := db.AddItem("image", data); err != nil {
+		t.Fatalf("failed to add image: %v", err)
+	}
+	return db.GetAllItems()[0]
+}
+
+// expectPixels fails unless item id reads back as exactly want
+func expectPixels(t *testing.T, db *Database, id string, want *image.NRGBA) {
+	t.Helper()
+	_, data, err := db.GetItem(id)
+	if err != nil {
+		t.Fatalf("failed to read item: %v", err)
+	}
+	got, err := decodePNG(data)
+	if err != nil {
+		t.Fatalf("failed to decode item: %v", err)
+	}
+	if got.Bounds() != want.Bounds() || !bytes.Equal(got.Pix, want.Pix) {
+		t.Fatalf("item %s doesn't read back pixel for pixel", id)
+	}
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	db.SetDeltaImages(true)
+
+	first, second := screenshot(10), screenshot(200)
+	base := addScreenshot(t, db, first)
+	delta := addScreenshot(t, db, second)
+	if base.Delta != nil {
+		t.Fatal("the first screenshot was stored as a patch")
+	}
+	if delta.Delta == nil || delta.Delta.BaseID != base.ID {
+		t.Fatalf("the second screenshot wasn't stored as a patch over the first: %+v", delta.Delta)
+	}
+	expectPixels(t, db, base.ID, first)
+	expectPixels(t, db, delta.ID, second)
+}
+
+// Removing a base item for good first turns its dependents into full images, whichever
+// way it goes
+func TestDeltaCascade(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(t *testing.T, db *Database, baseID string)
+	}{
+		{"trash purged", func(t *testing.T, db *Database, baseID string) {
+			if err := db.DeleteItem(baseID); err != nil {
+				t.Fatalf("failed to delete: %v", err)
+			}
+			if _, err := db.PurgeDeleted(0); err != nil {
+				t.Fatalf("failed to purge: %v", err)
+			}
+		}},
+		{"retention pruned", func(t *testing.T, db *Database, baseID string) {
+			// Only the base is older than a day
+			if db.PruneOlderThan(24*time.Hour) != 1 {
+				t.Fatal("the prune didn't remove the base")
+			}
+		}},
+		{"evicted by the limit", func(t *testing.T, db *Database, baseID string) {
+			// Filling the history pushes out the base, the oldest unpinned item
+			db.SetMaxItems(10)
+			for i := range 10 {
+				var warning *LimitWarning
+				if err := db.AddItem("text", []byte{'a' + byte(i)}); err != nil && !errors.As(err, &warning) {
+					t.Fatalf("failed to add: %v", err)
+				}
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			db.SetDeltaImages(true)
+			db.SetGraceWindow(0)
+			clock := time.Now()
+			db.SetClock(func() time.Time { return clock })
+
+			first, second := screenshot(10), screenshot(200)
+			base := addScreenshot(t, db, first)
+			clock = clock.Add(48 * time.Hour)
+			delta := addScreenshot(t, db, second)
+			if delta.Delta == nil {
+				t.Fatal("the second screenshot wasn't stored as a patch")
+			}
+			if err := db.TogglePin(delta.ID); err != nil {
+				t.Fatalf("failed to pin: %v", err)
+			}
+
+			tt.remove(t, db, base.ID)
+			if _, _, err := db.GetItem(base.ID); err == nil {
+				t.Fatal("the base is still there")
+			}
+			for _, item := range db.GetAllItems() {
+				if item.ID == delta.ID && item.Delta != nil {
+					t.Fatal("the dependent still points at the removed base")
+				}
+			}
+			expectPixels(t, db, delta.ID, second)
+		})
+	}
+}
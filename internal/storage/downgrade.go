@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExportFormatV1 is the format of the original build: base64 text of the encrypted
+// JSON array of items, with only the fields in v1Item. Newer builds still read it
+// (see decodeDatabaseFile), so a v1 export loads again without further conversion.
+const ExportFormatV1 = "v1"
+
+// v1Item is an item as the original build reads it
+type v1Item struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+	Pinned    bool      `json:"pinned"`
+	Size      int       `json:"size"`
+	Hash      string    `json:"hash"`
+}
+
+// V1LostFields are the item fields a v1 export drops, by their JSON name
+// "unknown" stands for fields written by builds newer than this one
+var V1LostFields = []string{"phash", "class", "original", "forced", "source", "app", "rtf", "redacted", "title", "user_title", "tags", "uses", "unknown"}
+
+// DowngradeReport describes what an export to an older format loses
+type DowngradeReport struct {
+	Items     int            // Items written
+	Expanded  int            // Delta images written as full images
+	Skipped   int            // Delta images whose base couldn't be read; not written
+	Protected int            // Protected items; not written, v1 would show them unmasked
+	Lost      map[string]int // Items losing each field in V1LostFields
+}
+
+// String summarizes the report for logs and the CLI
+func (r DowngradeReport) String() string {
+	fields := make([]string, 0, len(r.Lost))
+	for field, n := range r.Lost {
+		fields = append(fields, fmt.Sprintf("%s: %d", field, n))
+	}
+	sort.Strings(fields)
+	lost := "none"
+	if len(fields) > 0 {
+		lost = strings.Join(fields, ", ")
+	}
+	return fmt.Sprintf("%d items, %d delta images expanded, %d skipped, %d protected left out; lost fields: %s",
+		r.Items, r.Expanded, r.Skipped, r.Protected, lost)
+}
+
+// lostV1Fields returns the fields of item that a v1 export drops
+func lostV1Fields(item ClipboardItem) []string {
+	lost := make([]string, 0)
+	set := map[string]bool{
+		"phash":      item.PHash != "",
+		"class":      item.Class != "",
+		"original":   item.Original != "",
+		"forced":     item.Forced,
+		"source":     item.SourceURL != "",
+		"app":        item.SourceApp != "",
+		"rtf":        item.RTF != "",
+		"redacted":   item.Redacted,
+		"title":      item.TitleCache != "",
+		"user_title": item.Title != "",
+		"tags":       len(item.Tags) > 0,
+		"uses":       item.CopyCount > 0,
+		"unknown":    len(item.unknown) > 0,
+	}
+	for _, field := range V1LostFields {
+		if set[field] {
+			lost = append(lost, field)
+		}
+	}
+	return lost
+}
+
+// downgradeToV1 converts items to the v1 layout, with content encrypted with key
+// (caller must hold lock)
+// Delta images are re-encrypted as full images, since v1 can't rebuild them
+func (db *Database) downgradeToV1(key []byte) ([]v1Item, DowngradeReport, error) {
+	report := DowngradeReport{Lost: make(map[string]int)}
+	out := make([]v1Item, 0, len(db.Items))
+	for i := range db.Items {
+		item := db.Items[i]
+		// Deleted items wait in the trash for a purge; they aren't part of the history
+		if item.Deleted {
+			continue
+		}
+		// Only the password-encrypted portable export carries protected items
+		if item.IsProtected() {
+			report.Protected++
+			continue
+		}
+		content := item.Content
+		if item.Delta != nil {
+			full, err := db.fullContent(&db.Items[i])
+			if err != nil {
+				report.Skipped++
+				continue
+			}
+			content, err = Encrypt(full, key)
+			Zero(full)
+			if err != nil {
+				return nil, report, fmt.Errorf("failed to encrypt expanded image: %w", err)
+			}
+			report.Expanded++
+		} else if !bytes.Equal(key, db.key) {
+			content = reencrypt(content, [][]byte{db.key}, key)
+		}
+		// v1 compares a plain SHA-256 of the bytes
+		hash := item.Hash
+		if item.HashVersion != 0 {
+			if full, err := Decrypt(content, key); err == nil {
+				hash = legacyHashes(full, nil)[0]
+				Zero(full)
+			}
+		}
+		for _, field := range lostV1Fields(item) {
+			report.Lost[field]++
+		}
+		out = append(out, v1Item{
+			ID:        item.ID,
+			Type:      item.Type,
+			Content:   content,
+			Timestamp: item.Timestamp,
+			Pinned:    item.Pinned,
+			Size:      item.Size,
+			Hash:      hash,
+		})
+	}
+	report.Items = len(out)
+	return out, report, nil
+}
+
+// fullContent decrypts a delta item and rebuilds its image (caller must hold lock)
+func (db *Database) fullContent(item *ClipboardItem) ([]byte, error) {
+	patch, err := Decrypt(item.Content, db.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt item: %w", err)
+	}
+	defer Zero(patch)
+	return db.reconstructImage(item, patch)
+}
+
+// PlanV1Export reports what ExportV1 would write and drop, without writing anything
+func (db *Database) PlanV1Export() (DowngradeReport, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	_, report, err := db.downgradeToV1(db.key)
+	return report, err
+}
+
+// ExportV1 writes the history as a database file the original build can read
+// The file is encrypted with this machine's hardware key, which the original build
+// uses directly; after a re-key the items are re-encrypted for it
+func (db *Database) ExportV1(w io.Writer) (DowngradeReport, error) {
+	hwKey, err := GetHardwareKey()
+	if err != nil {
+		return DowngradeReport{}, fmt.Errorf("failed to get hardware key: %w", err)
+	}
+	defer Zero(hwKey)
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	items, report, err := db.downgradeToV1(hwKey)
+	if err != nil {
+		return report, err
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return report, fmt.Errorf("failed to encode items: %w", err)
+	}
+	defer Zero(data)
+
+	encrypted, err := Encrypt(data, hwKey)
+	if err != nil {
+		return report, fmt.Errorf("failed to encrypt database: %w", err)
+	}
+	if _, err := io.WriteString(w, encrypted); err != nil {
+		return report, fmt.Errorf("failed to write export: %w", err)
+	}
+	db.writeAudit(AuditEntry{Op: AuditExport, Count: report.Items})
+	return report, nil
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// readV1Export decrypts an ExportV1 file with the hardware key, as the original build
+// does, and returns its items with their contents
+func readV1Export(t *testing.T, data []byte) ([]v1Item, []string) {
+	t.Helper()
+	hwKey, err := GetHardwareKey()
+	if err != nil {
+		t.Fatalf("failed to get hardware key: %v", err)
+	}
+	plain, err := Decrypt(string(data), hwKey)
+	if err != nil {
+		t.Fatalf("the export doesn't open with the hardware key: %v", err)
+	}
+	var items []v1Item
+	if err := json.Unmarshal(plain, &items); err != nil {
+		t.Fatalf("the export isn't a v1 item list: %v", err)
+	}
+	contents := make([]string, 0, len(items))
+	for _, item := range items {
+		content, err := Decrypt(item.Content, hwKey)
+		if err != nil {
+			t.Fatalf("item %s doesn't open with the hardware key: %v", item.ID, err)
+		}
+		if item.Hash != fmt.Sprintf("%x", sha256.Sum256(content)) {
+			t.Errorf("item %s has hash %s, not the plain SHA-256 v1 compares", item.ID, item.Hash)
+		}
+		contents = append(contents, string(content))
+	}
+	return items, contents
+}
+
+// TestExportV1 exports a history after a re-key, so the items must be re-encrypted
+// for the hardware key, and checks what is written, what is left out and what is lost
+func TestExportV1(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "silinecek", "korunan", "etiketli", "sabit")
+	items := db.GetAllItems() // sabit, etiketli, korunan, silinecek
+	if err := db.TogglePin(items[0].ID); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := db.SetTags(items[1].ID, []string{"iş"}); err != nil {
+		t.Fatalf("failed to tag: %v", err)
+	}
+	if err := db.SetTitle(items[1].ID, "Başlık"); err != nil {
+		t.Fatalf("failed to set title: %v", err)
+	}
+	if err := db.Protect(items[2].ID, "parola"); err != nil {
+		t.Fatalf("failed to protect: %v", err)
+	}
+	if err := db.DeleteItem(items[3].ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := db.Rekey(nil); err != nil {
+		t.Fatalf("failed to re-key: %v", err)
+	}
+
+	plan, err := db.PlanV1Export()
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	var buf bytes.Buffer
+	report, err := db.ExportV1(&buf)
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if report.String() != plan.String() {
+		t.Errorf("the export did %s, the plan said %s", report, plan)
+	}
+	if report.Items != 2 || report.Protected != 1 || report.Skipped != 0 {
+		t.Errorf("report is %s", report)
+	}
+	if report.Lost["tags"] != 1 || report.Lost["user_title"] != 1 {
+		t.Errorf("lost fields are %v", report.Lost)
+	}
+
+	exported, contents := readV1Export(t, buf.Bytes())
+	if !slices.Equal(contents, []string{"sabit", "etiketli"}) {
+		t.Errorf("export holds %q", contents)
+	}
+	if !exported[0].Pinned || exported[1].Pinned {
+		t.Error("the pin wasn't kept")
+	}
+	if exported[0].ID != items[0].ID || !exported[0].Timestamp.Equal(items[0].Timestamp) {
+		t.Errorf("exported item is %+v", exported[0])
+	}
+}
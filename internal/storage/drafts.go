@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Drafts keep the text of an open editor dialog, so a crash doesn't lose what was being
+// typed. All drafts sit in DraftsFile as one JSON object encrypted with the data key,
+// rewritten atomically on every change; the UI saves an open editor every few seconds
+// and deletes its draft when the dialog is saved or cancelled. Drafts left on the next
+// start are offered for restoring.
+
+const (
+	DraftsFile = "drafts.db"
+
+	MaxDraftSize = 1024 * 1024 // Longer texts aren't kept as drafts
+	maxDrafts    = 20          // The oldest drafts go beyond this many
+)
+
+// ErrDraftTooLarge is returned by SaveDraft for a text over MaxDraftSize
+var ErrDraftTooLarge = errors.New("draft too large")
+
+// Draft is the unsaved text of one editor dialog
+type Draft struct {
+	Key     string    `json:"key"` // Which dialog it belongs to
+	Text    string    `json:"text"`
+	Updated time.Time `json:"updated"`
+}
+
+// getDraftsPath returns the drafts location next to the database
+func getDraftsPath() (string, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), DraftsFile), nil
+}
+
+// SaveDraft stores text as the draft of the dialog key, replacing its previous draft
+func (db *Database) SaveDraft(key, text string) error {
+	if len(text) > MaxDraftSize {
+		return ErrDraftTooLarge
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// A file no key opens can't be restored anyway, so it is replaced
+	drafts, _ := db.readDraftsInternal()
+	kept := make([]Draft, 0, len(drafts)+1)
+	kept = append(kept, Draft{Key: key, Text: text, Updated: db.now()})
+	for _, d := range drafts {
+		if d.Key != key {
+			kept = append(kept, d)
+		}
+	}
+	if len(kept) > maxDrafts {
+		kept = kept[:maxDrafts]
+	}
+	return db.writeDraftsInternal(kept)
+}
+
+// LoadDrafts returns the saved drafts, newest first
+func (db *Database) LoadDrafts() ([]Draft, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.readDraftsInternal()
+}
+
+// DeleteDraft removes the draft of the dialog key; a missing draft is no error
+func (db *Database) DeleteDraft(key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	drafts, err := db.readDraftsInternal()
+	if err != nil {
+		return err
+	}
+	kept := make([]Draft, 0, len(drafts))
+	for _, d := range drafts {
+		if d.Key != key {
+			kept = append(kept, d)
+		}
+	}
+	if len(kept) == len(drafts) {
+		return nil
+	}
+	return db.writeDraftsInternal(kept)
+}
+
+// readDraftsInternal reads DraftsFile, sorted newest first (caller must hold lock)
+func (db *Database) readDraftsInternal() ([]Draft, error) {
+	path, err := getDraftsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drafts: %w", err)
+	}
+	drafts, err := decodeDrafts(data, [][]byte{db.key, db.pendingKey})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(drafts, func(i, j int) bool {
+		return drafts[i].Updated.After(drafts[j].Updated)
+	})
+	return drafts, nil
+}
+
+// writeDraftsInternal replaces DraftsFile; no drafts removes it (caller must hold lock)
+func (db *Database) writeDraftsInternal(drafts []Draft) error {
+	path, err := getDraftsPath()
+	if err != nil {
+		return err
+	}
+	if len(drafts) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove drafts: %w", err)
+		}
+		return nil
+	}
+	data, err := encodeDrafts(drafts, db.key)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// encodeDrafts encrypts drafts with key
+func encodeDrafts(drafts []Draft, key []byte) ([]byte, error) {
+	plain, err := json.Marshal(drafts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode drafts: %w", err)
+	}
+	defer Zero(plain)
+	sealed, err := EncryptBytes(plain, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt drafts: %w", err)
+	}
+	return sealed, nil
+}
+
+// decodeDrafts decrypts drafts with the first of keys that opens them
+func decodeDrafts(data []byte, keys [][]byte) ([]Draft, error) {
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		plain, err := DecryptBytes(data, key)
+		if err != nil {
+			continue
+		}
+		var drafts []Draft
+		err = json.Unmarshal(plain, &drafts)
+		Zero(plain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse drafts: %w", err)
+		}
+		return drafts, nil
+	}
+	return nil, errors.New("failed to decrypt drafts")
+}
+
+// rekeyDrafts rewrites DraftsFile under to; drafts no key opens are dropped
+func rekeyDrafts(keys [][]byte, to []byte) error {
+	path, err := getDraftsPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read drafts: %w", err)
+	}
+	drafts, err := decodeDrafts(data, keys)
+	if err != nil {
+		return os.Remove(path)
+	}
+	encoded, err := encodeDrafts(drafts, to)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, encoded)
+}
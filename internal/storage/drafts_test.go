@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// draftKeys returns the keys of drafts, in order
+func draftKeys(drafts []Draft) []string {
+	keys := make([]string, 0, len(drafts))
+	for _, d := range drafts {
+		keys = append(keys, d.Key)
+	}
+	return keys
+}
+
+// TestDrafts saves, replaces and deletes drafts and checks the file is encrypted, kept
+// across a reopen and gone once the last draft is
+func TestDrafts(t *testing.T) {
+	db := newTestDB(t)
+	clock := time.Now()
+	db.SetClock(func() time.Time { return clock })
+	save := func(key, text string) {
+		t.Helper()
+		clock = clock.Add(time.Second)
+		if err := db.SaveDraft(key, text); err != nil {
+			t.Fatalf("failed to save draft: %v", err)
+		}
+	}
+
+	save("yeni", "ilk taslak")
+	save("düzenle:1", "ikinci taslak")
+	save("yeni", "gizli taslak metni")
+	drafts, err := db.LoadDrafts()
+	if err != nil || !slices.Equal(draftKeys(drafts), []string{"yeni", "düzenle:1"}) || drafts[0].Text != "gizli taslak metni" {
+		t.Fatalf("drafts are %+v, %v", drafts, err)
+	}
+
+	path, err := getDraftsPath()
+	if err != nil {
+		t.Fatalf("failed to locate drafts: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || bytes.Contains(data, []byte("taslak")) {
+		t.Errorf("drafts file holds plaintext or is missing: %v", err)
+	}
+	if err := db.SaveDraft("büyük", strings.Repeat("a", MaxDraftSize+1)); !errors.Is(err, ErrDraftTooLarge) {
+		t.Errorf("an oversized draft gave %v", err)
+	}
+	db.Close()
+
+	reopened, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+	if drafts, err := reopened.LoadDrafts(); err != nil || len(drafts) != 2 {
+		t.Fatalf("drafts after reopen are %+v, %v", drafts, err)
+	}
+	for _, key := range []string{"yeni", "yok", "düzenle:1"} {
+		if err := reopened.DeleteDraft(key); err != nil {
+			t.Errorf("failed to delete draft %q: %v", key, err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("drafts file left without drafts: %v", err)
+	}
+	if drafts, err := reopened.LoadDrafts(); err != nil || len(drafts) != 0 {
+		t.Errorf("drafts are %+v, %v", drafts, err)
+	}
+}
+
+// Past maxDrafts the oldest draft goes
+func TestDraftsCapped(t *testing.T) {
+	db := newTestDB(t)
+	clock := time.Now()
+	db.SetClock(func() time.Time { return clock })
+	for i := range maxDrafts + 1 {
+		clock = clock.Add(time.Second)
+		if err := db.SaveDraft(fmt.Sprintf("taslak %d", i), "metin"); err != nil {
+			t.Fatalf("failed to save draft: %v", err)
+		}
+	}
+	drafts, err := db.LoadDrafts()
+	if err != nil || len(drafts) != maxDrafts {
+		t.Fatalf("%d drafts, %v; want %d", len(drafts), err, maxDrafts)
+	}
+	if slices.Contains(draftKeys(drafts), "taslak 0") {
+		t.Error("the oldest draft was kept")
+	}
+}
+
+// A drafts file no key opens is replaced by the next draft rather than blocking it
+func TestDraftsUnreadable(t *testing.T) {
+	db := newTestDB(t)
+	path, err := getDraftsPath()
+	if err != nil {
+		t.Fatalf("failed to locate drafts: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("bozuk"), 0600); err != nil {
+		t.Fatalf("failed to write drafts: %v", err)
+	}
+	if _, err := db.LoadDrafts(); err == nil {
+		t.Error("an unreadable drafts file loaded")
+	}
+	if err := db.SaveDraft("yeni", "metin"); err != nil {
+		t.Fatalf("failed to save over the unreadable file: %v", err)
+	}
+	if drafts, err := db.LoadDrafts(); err != nil || len(drafts) != 1 {
+		t.Errorf("drafts are %+v, %v", drafts, err)
+	}
+}
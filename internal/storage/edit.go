@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotEditable is returned by UpdateContent for items that aren't text
+	ErrNotEditable = errors.New("only text items can be edited")
+	// ErrEmptyContent is returned by UpdateContent for text with nothing in it
+	ErrEmptyContent = errors.New("content is empty")
+)
+
+// UpdateContent replaces the text of an item and moves it to the top as if just copied
+// The text goes through redaction like a capture. Other items that already hold the new
+// text are older duplicates, so they go to the trash; the edited item takes over a pin
+// from them. Protected items must be unprotected first
+func (db *Database) UpdateContent(id string, content []byte) error {
+	if len(content) == 0 {
+		return ErrEmptyContent
+	}
+	if len(content) > MaxItemSize {
+		return &RejectError{Reason: RejectTooLarge, Size: len(content), Limit: MaxItemSize}
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	index := -1
+	for i, item := range db.Items {
+		if item.ID == id && !item.Deleted {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("item not found")
+	}
+	if db.Items[index].Type != "text" {
+		return ErrNotEditable
+	}
+	if db.Items[index].IsProtected() {
+		return ErrProtected
+	}
+
+	masked, redacted, err := db.redactor.Redact(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to redact content: %w", err)
+	}
+	if redacted {
+		content = []byte(masked)
+	}
+
+	encrypted, err := Encrypt(content, db.key)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt content: %w", err)
+	}
+	var encryptedTitle string
+	if title, _, ok := ExtractTitle(string(content)); ok {
+		if encryptedTitle, err = Encrypt([]byte(title), db.key); err != nil {
+			return fmt.Errorf("failed to encrypt title: %w", err)
+		}
+	}
+	encryptedPreview, err := makePreview("text", content, nil, db.key)
+	if err != nil {
+		return err
+	}
+
+	hashes := newContentHashes("text", content, nil)
+	item := db.Items[index]
+	item.Content = encrypted
+	item.Hash = hashes.canonical
+	item.HashVersion = HashVersion
+	item.Size = len(content)
+	item.Timestamp = db.now()
+	item.Class = ClassifyText(string(content))
+	// The pre-cleaning URL and the formatting belonged to the old text
+	item.Original = ""
+	item.RTF = ""
+	// Masks from an earlier redaction may still be in the text
+	item.Redacted = item.Redacted || redacted
+	item.TitleCache = encryptedTitle
+	item.Preview = encryptedPreview
+
+	// Older items with the same text go to the trash instead of swallowing the edit
+	kept := make([]ClipboardItem, 0, len(db.Items))
+	trashed := make([]string, 0)
+	for i := range db.Items {
+		other := &db.Items[i]
+		if i == index {
+			continue
+		}
+		if !other.Deleted && other.Type == "text" && hashes.matches(*other) {
+			item.Pinned = item.Pinned || other.Pinned
+			db.trashItem(other)
+			db.recordAudit(AuditDelete, *other)
+			trashed = append(trashed, other.ID)
+		}
+		kept = append(kept, *other)
+	}
+	db.Items = append([]ClipboardItem{item}, kept...)
+
+	db.recordAudit(AuditEdit, item)
+	if len(trashed) > 0 {
+		db.commit(ChangeDelete, trashed...)
+	}
+	db.commit(ChangeUpdate, id)
+	return db.scheduleSave()
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Files copied in Explorer are stored as a "files" item: the content is the JSON array
+// of their full paths, in the order the clipboard listed them. Only the paths are kept,
+// never the files, so copying the item back only works while the files still exist.
+// The preview holds the number of files and the names of the first previewMaxFiles.
+
+// previewMaxFiles is how many file names a files item's preview keeps
+const previewMaxFiles = 20
+
+// ErrNoFiles is returned by DecodeFiles for content without any path
+var ErrNoFiles = errors.New("no files in item")
+
+// filesPreview is the decrypted preview of a files item
+type filesPreview struct {
+	Count int      `json:"count"`
+	Names []string `json:"names"`
+}
+
+// EncodeFiles returns the content of a files item for paths
+func EncodeFiles(paths []string) ([]byte, error) {
+	if len(paths) == 0 {
+		return nil, ErrNoFiles
+	}
+	data, err := json.Marshal(paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode file list: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeFiles returns the paths of a files item's content
+func DecodeFiles(content []byte) ([]string, error) {
+	var paths []string
+	if err := json.Unmarshal(content, &paths); err != nil {
+		return nil, fmt.Errorf("invalid file list: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, ErrNoFiles
+	}
+	return paths, nil
+}
+
+// FileName returns the last element of a path, split at both Windows and Unix separators
+// so paths copied on Windows read the same everywhere
+func FileName(path string) string {
+	path = strings.TrimRight(path, `\/`)
+	if i := strings.LastIndexAny(path, `\/`); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// encodeFilesPreview returns the preview of a files item's content
+func encodeFilesPreview(content []byte) ([]byte, error) {
+	paths, err := DecodeFiles(content)
+	if err != nil {
+		return nil, err
+	}
+	preview := filesPreview{Count: len(paths)}
+	for _, path := range paths[:min(len(paths), previewMaxFiles)] {
+		preview.Names = append(preview.Names, FileName(path))
+	}
+	return json.Marshal(preview)
+}
+
+// decodeFilesPreview parses the decrypted preview of a files item
+func decodeFilesPreview(data []byte) (Preview, error) {
+	var preview filesPreview
+	if err := json.Unmarshal(data, &preview); err != nil {
+		return Preview{}, fmt.Errorf("invalid preview: %w", err)
+	}
+	return Preview{Files: preview.Names, FileCount: preview.Count}, nil
+}
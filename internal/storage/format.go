@@ -0,0 +1,513 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// On-disk format
+//
+// The database file is fileMagic, a version byte, then the raw AES-GCM
+// ciphertext (nonce included) of the item payload. The payload is a
+// sequence of length-prefixed item records; each record is a list of
+// tag/length/value fields. Encrypted item content is stored as raw bytes
+// instead of base64, and tags this build does not know are kept on the item
+// and written back unchanged, so older and newer builds can share a file.
+//
+// Files written before this format are base64 text of encrypted JSON and are
+// still read; they are converted when loaded, after a restore point is taken
+// (see safety.go).
+
+const (
+	fileMagic         = "PANO"
+	fileFormatVersion = 1
+
+	// maxFieldLen guards the decoder against absurd lengths in damaged files
+	maxFieldLen = MaxItemSize * 2
+)
+
+// Item field tags; never reuse a retired number
+const (
+	tagID        = 1
+	tagType      = 2
+	tagContent   = 3 // Raw ciphertext
+	tagTimestamp = 4 // Unix nanoseconds, zigzag varint
+	tagPinned    = 5
+	tagSize      = 6
+	tagHash      = 7 // Raw SHA-256, or the string if it isn't hex
+	tagPHash     = 8
+	tagDelta     = 9 // Nested fields: 1 base ID, 2 x, 3 y
+	tagClass     = 10
+	tagOriginal  = 11 // Raw ciphertext
+	tagForced    = 12
+	tagSource    = 13 // Raw ciphertext of the source page URL
+	tagRedacted  = 14
+	tagTitle     = 15 // Raw ciphertext of the implicit title
+	tagHashVer   = 16 // Form of the hash, omitted for unversioned hashes
+	tagTags      = 17 // One field per user-defined tag
+	tagProtect   = 18 // Nested fields: 1 salt, 2 hash, 3 iterations
+	tagPreview   = 19 // Raw ciphertext of the preview
+	tagApp       = 20 // Raw ciphertext of the source program name
+	tagUses      = 21 // Copy count, omitted for items never copied back
+	tagLastUsed  = 22 // Unix nanoseconds, zigzag varint
+	tagDeleted   = 23
+	tagDeletedAt = 24 // Unix nanoseconds, zigzag varint
+	tagUserTitle = 25 // Raw ciphertext of the title set by the user
+	tagRTF       = 26 // Raw ciphertext of the Rich Text Format
+)
+
+// rawField is an item field kept verbatim, used for tags from newer versions
+type rawField struct {
+	Tag   uint64
+	Value []byte
+}
+
+// isBinaryFormat reports whether file data uses the binary container
+func isBinaryFormat(data []byte) bool {
+	return len(data) > len(fileMagic) && string(data[:len(fileMagic)]) == fileMagic
+}
+
+// encodeDatabaseFile encrypts items into the binary file format
+func encodeDatabaseFile(items []ClipboardItem, key []byte) ([]byte, error) {
+	payload, err := encodeItems(items)
+	if err != nil {
+		return nil, err
+	}
+	defer Zero(payload)
+
+	sealed, err := EncryptBytes(payload, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt database: %w", err)
+	}
+
+	out := make([]byte, 0, len(fileMagic)+1+len(sealed))
+	out = append(out, fileMagic...)
+	out = append(out, fileFormatVersion)
+	out = append(out, sealed...)
+	return out, nil
+}
+
+// decodeDatabaseFile decrypts and parses a database file in either format
+func decodeDatabaseFile(data []byte, key []byte) ([]ClipboardItem, error) {
+	if !isBinaryFormat(data) {
+		// Legacy base64 JSON
+		decrypted, err := Decrypt(string(data), key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt database: %w (%w)", ErrKeyMismatch, err)
+		}
+		defer Zero(decrypted)
+
+		items := make([]ClipboardItem, 0)
+		if err := json.Unmarshal(decrypted, &items); err != nil {
+			return nil, fmt.Errorf("failed to parse database: %w", err)
+		}
+		return items, nil
+	}
+
+	version := data[len(fileMagic)]
+	if version > fileFormatVersion {
+		return nil, fmt.Errorf("database format version %d is newer than supported (%d)", version, fileFormatVersion)
+	}
+
+	payload, err := DecryptBytes(data[len(fileMagic)+1:], key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt database: %w (%w)", ErrKeyMismatch, err)
+	}
+	defer Zero(payload)
+
+	items, err := decodeItems(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database: %w", err)
+	}
+	return items, nil
+}
+
+// encodeItems serializes items into length-prefixed records
+func encodeItems(items []ClipboardItem) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, item := range items {
+		record, err := encodeItem(item)
+		if err != nil {
+			return nil, err
+		}
+		writeUvarint(&buf, uint64(len(record)))
+		buf.Write(record)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeItems parses records produced by encodeItems
+func decodeItems(data []byte) ([]ClipboardItem, error) {
+	r := bytes.NewReader(data)
+	items := make([]ClipboardItem, 0)
+	for r.Len() > 0 {
+		record, err := readChunk(r)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(items), err)
+		}
+		item, err := decodeItem(record)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(items), err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// encodeItem serializes a single item's fields
+func encodeItem(item ClipboardItem) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writeField(&buf, tagID, []byte(item.ID))
+	writeField(&buf, tagType, []byte(item.Type))
+
+	content, err := base64.StdEncoding.DecodeString(item.Content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content encoding for item %s: %w", item.ID, err)
+	}
+	writeField(&buf, tagContent, content)
+
+	// The zero time has no UnixNano; a record without a timestamp reads back as zero
+	if !item.Timestamp.IsZero() {
+		writeField(&buf, tagTimestamp, varintBytes(item.Timestamp.UnixNano()))
+	}
+	if item.Pinned {
+		writeField(&buf, tagPinned, []byte{1})
+	}
+	writeField(&buf, tagSize, uvarintBytes(uint64(item.Size)))
+
+	if hash, err := hex.DecodeString(item.Hash); err == nil && len(hash) == 32 {
+		writeField(&buf, tagHash, hash)
+	} else if item.Hash != "" {
+		writeField(&buf, tagHash, []byte(item.Hash))
+	}
+	if item.HashVersion != 0 {
+		writeField(&buf, tagHashVer, uvarintBytes(uint64(item.HashVersion)))
+	}
+
+	if item.PHash != "" {
+		writeField(&buf, tagPHash, []byte(item.PHash))
+	}
+	if item.Delta != nil {
+		var delta bytes.Buffer
+		writeField(&delta, 1, []byte(item.Delta.BaseID))
+		writeField(&delta, 2, varintBytes(int64(item.Delta.X)))
+		writeField(&delta, 3, varintBytes(int64(item.Delta.Y)))
+		writeField(&buf, tagDelta, delta.Bytes())
+	}
+	if item.Class != "" {
+		writeField(&buf, tagClass, []byte(item.Class))
+	}
+	if item.Original != "" {
+		original, err := base64.StdEncoding.DecodeString(item.Original)
+		if err != nil {
+			return nil, fmt.Errorf("invalid original encoding for item %s: %w", item.ID, err)
+		}
+		writeField(&buf, tagOriginal, original)
+	}
+	if item.Forced {
+		writeField(&buf, tagForced, []byte{1})
+	}
+	if item.SourceURL != "" {
+		source, err := base64.StdEncoding.DecodeString(item.SourceURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source encoding for item %s: %w", item.ID, err)
+		}
+		writeField(&buf, tagSource, source)
+	}
+	if item.SourceApp != "" {
+		app, err := base64.StdEncoding.DecodeString(item.SourceApp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source app encoding for item %s: %w", item.ID, err)
+		}
+		writeField(&buf, tagApp, app)
+	}
+	if item.RTF != "" {
+		rtf, err := base64.StdEncoding.DecodeString(item.RTF)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RTF encoding for item %s: %w", item.ID, err)
+		}
+		writeField(&buf, tagRTF, rtf)
+	}
+	if item.Redacted {
+		writeField(&buf, tagRedacted, []byte{1})
+	}
+	if item.TitleCache != "" {
+		title, err := base64.StdEncoding.DecodeString(item.TitleCache)
+		if err != nil {
+			return nil, fmt.Errorf("invalid title encoding for item %s: %w", item.ID, err)
+		}
+		writeField(&buf, tagTitle, title)
+	}
+	if item.Title != "" {
+		title, err := base64.StdEncoding.DecodeString(item.Title)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user title encoding for item %s: %w", item.ID, err)
+		}
+		writeField(&buf, tagUserTitle, title)
+	}
+	if item.Preview != "" {
+		preview, err := base64.StdEncoding.DecodeString(item.Preview)
+		if err != nil {
+			return nil, fmt.Errorf("invalid preview encoding for item %s: %w", item.ID, err)
+		}
+		writeField(&buf, tagPreview, preview)
+	}
+	for _, tag := range item.Tags {
+		writeField(&buf, tagTags, []byte(tag))
+	}
+	if item.CopyCount > 0 {
+		writeField(&buf, tagUses, uvarintBytes(uint64(item.CopyCount)))
+	}
+	if !item.LastUsed.IsZero() {
+		writeField(&buf, tagLastUsed, varintBytes(item.LastUsed.UnixNano()))
+	}
+	if item.Deleted {
+		writeField(&buf, tagDeleted, []byte{1})
+	}
+	if !item.DeletedAt.IsZero() {
+		writeField(&buf, tagDeletedAt, varintBytes(item.DeletedAt.UnixNano()))
+	}
+	if item.Protection != nil {
+		var protect bytes.Buffer
+		writeField(&protect, 1, item.Protection.Salt)
+		writeField(&protect, 2, item.Protection.Hash)
+		writeField(&protect, 3, uvarintBytes(uint64(item.Protection.Iterations)))
+		writeField(&buf, tagProtect, protect.Bytes())
+	}
+
+	// Fields from newer versions go back out untouched
+	for _, f := range item.unknown {
+		writeField(&buf, f.Tag, f.Value)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeItem parses a single item's fields
+func decodeItem(data []byte) (ClipboardItem, error) {
+	var item ClipboardItem
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		tag, value, err := readField(r)
+		if err != nil {
+			return item, err
+		}
+
+		switch tag {
+		case tagID:
+			item.ID = string(value)
+		case tagType:
+			item.Type = string(value)
+		case tagContent:
+			item.Content = base64.StdEncoding.EncodeToString(value)
+		case tagTimestamp:
+			ns, n := binary.Varint(value)
+			if n <= 0 {
+				return item, fmt.Errorf("invalid timestamp")
+			}
+			item.Timestamp = time.Unix(0, ns)
+		case tagPinned:
+			item.Pinned = len(value) > 0 && value[0] != 0
+		case tagSize:
+			size, n := binary.Uvarint(value)
+			if n <= 0 {
+				return item, fmt.Errorf("invalid size")
+			}
+			item.Size = int(size)
+		case tagHash:
+			if len(value) == 32 {
+				item.Hash = hex.EncodeToString(value)
+			} else {
+				item.Hash = string(value)
+			}
+		case tagHashVer:
+			version, n := binary.Uvarint(value)
+			if n <= 0 {
+				return item, fmt.Errorf("invalid hash version")
+			}
+			item.HashVersion = int(version)
+		case tagPHash:
+			item.PHash = string(value)
+		case tagDelta:
+			delta, err := decodeDelta(value)
+			if err != nil {
+				return item, err
+			}
+			item.Delta = delta
+		case tagClass:
+			item.Class = string(value)
+		case tagOriginal:
+			item.Original = base64.StdEncoding.EncodeToString(value)
+		case tagForced:
+			item.Forced = len(value) > 0 && value[0] != 0
+		case tagSource:
+			item.SourceURL = base64.StdEncoding.EncodeToString(value)
+		case tagApp:
+			item.SourceApp = base64.StdEncoding.EncodeToString(value)
+		case tagRTF:
+			item.RTF = base64.StdEncoding.EncodeToString(value)
+		case tagRedacted:
+			item.Redacted = len(value) > 0 && value[0] != 0
+		case tagTitle:
+			item.TitleCache = base64.StdEncoding.EncodeToString(value)
+		case tagUserTitle:
+			item.Title = base64.StdEncoding.EncodeToString(value)
+		case tagPreview:
+			item.Preview = base64.StdEncoding.EncodeToString(value)
+		case tagTags:
+			item.Tags = append(item.Tags, string(value))
+		case tagUses:
+			uses, n := binary.Uvarint(value)
+			if n <= 0 {
+				return item, fmt.Errorf("invalid copy count")
+			}
+			item.CopyCount = int(uses)
+		case tagLastUsed:
+			ns, n := binary.Varint(value)
+			if n <= 0 {
+				return item, fmt.Errorf("invalid last use")
+			}
+			item.LastUsed = time.Unix(0, ns)
+		case tagDeleted:
+			item.Deleted = len(value) > 0 && value[0] != 0
+		case tagDeletedAt:
+			ns, n := binary.Varint(value)
+			if n <= 0 {
+				return item, fmt.Errorf("invalid deletion time")
+			}
+			item.DeletedAt = time.Unix(0, ns)
+		case tagProtect:
+			protection, err := decodeProtection(value)
+			if err != nil {
+				return item, err
+			}
+			item.Protection = protection
+		default:
+			item.unknown = append(item.unknown, rawField{Tag: tag, Value: append([]byte(nil), value...)})
+		}
+	}
+	return item, nil
+}
+
+// decodeProtection parses the nested passphrase verifier fields
+func decodeProtection(data []byte) (*ItemProtection, error) {
+	protection := &ItemProtection{}
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		tag, value, err := readField(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid protection: %w", err)
+		}
+		switch tag {
+		case 1:
+			protection.Salt = append([]byte(nil), value...)
+		case 2:
+			protection.Hash = append([]byte(nil), value...)
+		case 3:
+			iterations, n := binary.Uvarint(value)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid protection iterations")
+			}
+			protection.Iterations = int(iterations)
+		}
+	}
+	return protection, nil
+}
+
+// decodeDelta parses the nested delta fields
+func decodeDelta(data []byte) (*ImageDelta, error) {
+	delta := &ImageDelta{}
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		tag, value, err := readField(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delta: %w", err)
+		}
+		switch tag {
+		case 1:
+			delta.BaseID = string(value)
+		case 2, 3:
+			v, n := binary.Varint(value)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid delta offset")
+			}
+			if tag == 2 {
+				delta.X = int(v)
+			} else {
+				delta.Y = int(v)
+			}
+		}
+	}
+	return delta, nil
+}
+
+// ExportJSON writes items as indented JSON for debugging
+// Content stays encrypted; this is a view of the container, not a plaintext export
+func (db *Database) ExportJSON(w io.Writer) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(db.Items)
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	buf.Write(tmp[:n])
+}
+
+func uvarintBytes(v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append([]byte(nil), tmp[:n]...)
+}
+
+func varintBytes(v int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	return append([]byte(nil), tmp[:n]...)
+}
+
+func writeField(buf *bytes.Buffer, tag uint64, value []byte) {
+	writeUvarint(buf, tag)
+	writeUvarint(buf, uint64(len(value)))
+	buf.Write(value)
+}
+
+// readChunk reads a uvarint length followed by that many bytes
+func readChunk(r *bytes.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid length: %w", err)
+	}
+	if length > maxFieldLen || length > uint64(r.Len()) {
+		return nil, fmt.Errorf("length %d exceeds remaining data", length)
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// readField reads a tag and its length-prefixed value
+func readField(r *bytes.Reader) (uint64, []byte, error) {
+	tag, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid tag: %w", err)
+	}
+	value, err := readChunk(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return tag, value, nil
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// formatSample returns items that set every field the container knows, plus an
+// unknown field from a newer version; content is random, as ciphertext would be
+func formatSample(n, contentSize int) []ClipboardItem {
+	base := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.Local)
+	items := make([]ClipboardItem, 0, n)
+	for i := range n {
+		content := make([]byte, contentSize)
+		rand.Read(content)
+		item := ClipboardItem{
+			ID:          fmt.Sprintf("%d", base.UnixNano()+int64(i)),
+			Type:        "text",
+			Content:     base64.StdEncoding.EncodeToString(content),
+			Timestamp:   base.Add(time.Duration(i) * time.Minute),
+			Size:        contentSize,
+			Hash:        fmt.Sprintf("%064x", i),
+			HashVersion: HashVersion,
+			Class:       "code",
+			Tags:        []string{"iş", "not"},
+			CopyCount:   i,
+		}
+		switch i % 4 {
+		case 1:
+			item.Type = "image"
+			item.PHash = "f0f0f0f0f0f0f0f0"
+			item.Delta = &ImageDelta{BaseID: items[0].ID, X: 12, Y: -3}
+		case 2:
+			item.Pinned = true
+			item.Protection = &ItemProtection{Salt: []byte("salt"), Hash: []byte("hash"), Iterations: 1000}
+		case 3:
+			item.Deleted = true
+			item.DeletedAt = item.Timestamp.Add(time.Hour)
+			item.unknown = []rawField{{Tag: 999, Value: []byte("newer")}}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	items := formatSample(8, 64)
+	payload, err := encodeItems(items)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	got, err := decodeItems(payload)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("round trip changed the items:\n got %+v\nwant %+v", got, items)
+	}
+}
+
+// FuzzDecode feeds arbitrary payloads to the decoder: it must fail cleanly, never panic,
+// and whatever it accepts must survive another encode and decode unchanged
+func FuzzDecode(f *testing.F) {
+	valid, err := encodeItems(formatSample(4, 32))
+	if err != nil {
+		f.Fatalf("failed to encode: %v", err)
+	}
+	f.Add(valid)
+	f.Add(valid[:len(valid)/2])
+	f.Add(valid[:len(valid)-1])
+	f.Add(valid[:1])
+	f.Add([]byte{})
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		items, err := decodeItems(data)
+		if err != nil {
+			return
+		}
+		again, err := encodeItems(items)
+		if err != nil {
+			// Content is base64 of what was read, so it always encodes
+			t.Fatalf("failed to encode decoded items: %v", err)
+		}
+		decoded, err := decodeItems(again)
+		if err != nil {
+			t.Fatalf("failed to decode re-encoded items: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, items) {
+			t.Fatalf("re-encoding changed the items:\n got %+v\nwant %+v", decoded, items)
+		}
+	})
+}
+
+// benchmarkKey is a fixed AES-256 key for the benchmarks
+var benchmarkKey = bytes.Repeat([]byte{7}, 32)
+
+// The encode and decode benchmarks report the file size, against the JSON format
+// files were written in before the binary container
+func BenchmarkEncodeDatabaseFile(b *testing.B) {
+	for _, size := range []int{1 << 10, 1 << 20} {
+		items := formatSample(20, size)
+		b.Run(fmt.Sprintf("binary/%dKB", size>>10), func(b *testing.B) {
+			b.ReportAllocs()
+			var data []byte
+			for b.Loop() {
+				var err error
+				if data, err = encodeDatabaseFile(items, benchmarkKey); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(data)), "file-bytes")
+		})
+		b.Run(fmt.Sprintf("json/%dKB", size>>10), func(b *testing.B) {
+			b.ReportAllocs()
+			var data string
+			for b.Loop() {
+				plain, err := json.Marshal(items)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if data, err = Encrypt(plain, benchmarkKey); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(data)), "file-bytes")
+		})
+	}
+}
+
+func BenchmarkDecodeDatabaseFile(b *testing.B) {
+	for _, size := range []int{1 << 10, 1 << 20} {
+		items := formatSample(20, size)
+		binary, err := encodeDatabaseFile(items, benchmarkKey)
+		if err != nil {
+			b.Fatal(err)
+		}
+		plain, err := json.Marshal(items)
+		if err != nil {
+			b.Fatal(err)
+		}
+		legacy, err := Encrypt(plain, benchmarkKey)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for _, file := range []struct {
+			name string
+			data []byte
+		}{{"binary", binary}, {"json", []byte(legacy)}} {
+			data := file.data
+			b.Run(fmt.Sprintf("%s/%dKB", file.name, size>>10), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(data)))
+				for b.Loop() {
+					if _, err := decodeDatabaseFile(data, benchmarkKey); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"unicode/utf8"
+
+	"github.com/go-text/typesetting/segmenter"
+)
+
+// Previews, titles and labels are cut by user-perceived characters (grapheme clusters,
+// UAX #29), not bytes or runes: a byte cut can split a multi-byte rune and show U+FFFD,
+// a rune cut can split an emoji ZWJ sequence, a skin tone modifier or a letter from its
+// combining accent.
+
+// graphemeWindow is how many runes per wanted cluster are segmented at first; longer
+// clusters (stacked combining marks) widen the window
+const graphemeWindow = 8
+
+const zeroWidthJoiner = '\u200d'
+
+// CutGraphemes returns the first max grapheme clusters of text and whether anything
+// was cut off
+func CutGraphemes(text string, max int) (string, bool) {
+	if max <= 0 {
+		return "", text != ""
+	}
+	// Each cluster has at least one rune, so short text needs no segmenting
+	if utf8.RuneCountInString(text) <= max {
+		return text, false
+	}
+
+	for window := max * graphemeWindow; ; window *= 4 {
+		runes, more := runePrefix(text, window)
+		// The boundary before cluster max+1 is final once that cluster has started,
+		// whatever follows the window
+		if starts := graphemeStarts(runes); len(starts) > max {
+			return string(runes[:starts[max]]), true
+		}
+		if !more {
+			return text, false
+		}
+	}
+}
+
+// SplitGraphemes splits text into pieces of at most n grapheme clusters
+func SplitGraphemes(text string, n int) []string {
+	if n <= 0 || utf8.RuneCountInString(text) <= n {
+		return []string{text}
+	}
+
+	runes := []rune(text)
+	starts := graphemeStarts(runes)
+	pieces := make([]string, 0, len(starts)/n+1)
+	for i := n; i < len(starts); i += n {
+		pieces = append(pieces, string(runes[starts[i-n]:starts[i]]))
+	}
+	return append(pieces, string(runes[starts[(len(starts)-1)/n*n]:]))
+}
+
+// graphemeStarts returns the rune offsets at which the grapheme clusters of runes start
+// The segmenter loses track of emoji ZWJ sequences that follow each other directly
+// ("👨‍👩‍👧👨‍👩‍👧" splits after the second 👨‍), so no break is taken right after a ZWJ
+func graphemeStarts(runes []rune) []int {
+	var seg segmenter.Segmenter
+	seg.Init(runes)
+	starts := make([]int, 0, len(runes))
+	iter := seg.GraphemeIterator()
+	for iter.Next() {
+		offset := iter.Grapheme().Offset
+		if offset > 0 && runes[offset-1] == zeroWidthJoiner {
+			continue
+		}
+		starts = append(starts, offset)
+	}
+	return starts
+}
+
+// runePrefix returns up to n runes from the start of text and whether more follow
+func runePrefix(text string, n int) ([]rune, bool) {
+	runes := make([]rune, 0, min(n, len(text)))
+	for _, r := range text {
+		if len(runes) == n {
+			return runes, true
+		}
+		runes = append(runes, r)
+	}
+	return runes, false
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	family   = "\U0001F468\u200d\U0001F469\u200d\U0001F467" // One cluster of five runes
+	thumbsUp = "\U0001F44D\U0001F3FD"                       // With a skin tone modifier
+	dottedI  = "i\u0307"                                    // i and a combining dot above
+)
+
+func TestCutGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want string
+		cut  bool
+	}{
+		{"short", "merhaba", 10, "merhaba", false},
+		{"exact", "merhaba", 7, "merhaba", false},
+		{"ascii", "merhaba", 3, "mer", true},
+		{"turkish letters", "şğüöçı", 4, "şğüö", true},
+		{"combining accent", "a" + dottedI + "b", 2, "a" + dottedI, true},
+		{"accent fits by clusters", "a" + dottedI + "b", 3, "a" + dottedI + "b", false},
+		{"skin tone", thumbsUp + thumbsUp, 1, thumbsUp, true},
+		{"zwj sequences in a row", family + family + family, 2, family + family, true},
+		{"nothing", "merhaba", 0, "", true},
+		{"empty", "", 0, "", false},
+		{"long combining run", "a" + strings.Repeat("\u0301", 40) + "b", 1, "a" + strings.Repeat("\u0301", 40), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cut := CutGraphemes(tt.text, tt.max)
+			if got != tt.want || cut != tt.cut {
+				t.Errorf("CutGraphemes(%q, %d) = %q, %v; want %q, %v", tt.text, tt.max, got, cut, tt.want, tt.cut)
+			}
+		})
+	}
+}
+
+func TestSplitGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		n    int
+		want []string
+	}{
+		{"fits", "abc", 3, []string{"abc"}},
+		{"even", "abcdef", 2, []string{"ab", "cd", "ef"}},
+		{"remainder", "abcdefg", 3, []string{"abc", "def", "g"}},
+		{"clusters kept whole", family + "a" + thumbsUp + dottedI, 2, []string{family + "a", thumbsUp + dottedI}},
+		{"no size", "abc", 0, []string{"abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitGraphemes(tt.text, tt.n)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SplitGraphemes(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.want)
+			}
+			if strings.Join(got, "") != tt.text {
+				t.Error("the pieces don't add up to the text")
+			}
+		})
+	}
+}
+
+// Decomposed accents and the Turkish i's fold to the same text as their usual forms
+func TestFoldText(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"ŞEKER", "şeker"},
+		{"s\u0327eker", "şeker"},
+		{"S\u0327EKER", "şeker"},
+		{"İSTANBUL", "istanbul"},
+		{"ıspanak", "ispanak"},
+		{"I\u0307zmir", "izmir"},
+	}
+	for _, tt := range tests {
+		if FoldText(tt.a) != FoldText(tt.b) {
+			t.Errorf("FoldText(%q) = %q, FoldText(%q) = %q", tt.a, FoldText(tt.a), tt.b, FoldText(tt.b))
+		}
+	}
+}
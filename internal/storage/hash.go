@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"image"
+)
+
+// Every duplicate-detection hash comes from hashContent, so captures, recompression and
+// the integrity check can't disagree about it. The canonical form is SHA-256 over
+//
+//	"pano-hash" 0x00 version 0x00 type 0x00 format 0x00 content
+//
+// where format says what content is: "utf8" for text as stored (after redaction and URL
+// cleaning), "rgba" for decoded images (width and height as little-endian uint64, then
+// the NRGBA pixels row by row) and "bytes" for anything else, including images that
+// don't decode. Changing any of this, e.g. normalizing text, means bumping HashVersion;
+// for reference, the text "hello" hashes to 87308eb66275c423fb093839def8ec105f6880f865e98c5c9c964aa467001bc2.
+//
+// Items stored before versioning (HashVersion 0) carry a bare SHA-256 of their bytes or,
+// for images captured since background recompression, of their pixels. They still match
+// through legacyHashes and move to the current version whenever their content is read
+// anyway: a capture matching them, recompression, or an integrity pass.
+
+// HashVersion is the version of the canonical hash form written by this build
+const HashVersion = 1
+
+const (
+	hashFormatText   = "utf8"
+	hashFormatPixels = "rgba"
+	hashFormatBytes  = "bytes"
+)
+
+// hashContent returns the canonical hash of an item's content
+// img is the decoded image for image items, nil if it isn't at hand or didn't decode;
+// content is decoded here when img is nil
+func hashContent(itemType string, content []byte, img *image.NRGBA) string {
+	if itemType == "image" && img == nil {
+		if decoded, err := decodePNG(content); err == nil {
+			img = decoded
+		}
+	}
+
+	format := hashFormatBytes
+	switch {
+	case itemType == "text":
+		format = hashFormatText
+	case itemType == "image" && img != nil:
+		format = hashFormatPixels
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "pano-hash\x00%d\x00%s\x00%s\x00", HashVersion, itemType, format)
+	if format == hashFormatPixels {
+		writePixels(h, img)
+	} else {
+		h.Write(content)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// legacyHashes returns the hashes an unversioned item with this content may carry
+func legacyHashes(content []byte, img *image.NRGBA) []string {
+	hashes := []string{fmt.Sprintf("%x", sha256.Sum256(content))}
+	if img != nil {
+		h := sha256.New()
+		writePixels(h, img)
+		hashes = append(hashes, fmt.Sprintf("%x", h.Sum(nil)))
+	}
+	return hashes
+}
+
+// writePixels feeds an image's size and pixels to h, independent of how it was encoded
+func writePixels(h hash.Hash, img *image.NRGBA) {
+	bounds := img.Bounds()
+	var dims [16]byte
+	binary.LittleEndian.PutUint64(dims[0:], uint64(bounds.Dx()))
+	binary.LittleEndian.PutUint64(dims[8:], uint64(bounds.Dy()))
+	h.Write(dims[:])
+	rowLen := bounds.Dx() * 4
+	for y := 0; y < bounds.Dy(); y++ {
+		start := y * img.Stride
+		h.Write(img.Pix[start : start+rowLen])
+	}
+}
+
+// contentHashes holds a capture's canonical hash and its legacy equivalents
+type contentHashes struct {
+	canonical string
+	legacy    []string
+}
+
+// newContentHashes hashes a capture for comparison against stored items
+func newContentHashes(itemType string, content []byte, img *image.NRGBA) contentHashes {
+	return contentHashes{
+		canonical: hashContent(itemType, content, img),
+		legacy:    legacyHashes(content, img),
+	}
+}
+
+// matches reports whether item holds the same content, whichever version its hash is
+func (c contentHashes) matches(item ClipboardItem) bool {
+	if item.HashVersion == HashVersion {
+		return item.Hash == c.canonical
+	}
+	if item.HashVersion != 0 {
+		return false // Written by a newer build; can't be compared
+	}
+	for _, legacy := range c.legacy {
+		if item.Hash == legacy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// goldenImage is a 2x1 image: opaque red, then half-transparent blue
+func goldenImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{B: 255, A: 128})
+	return img
+}
+
+// The canonical hash form is stored on disk, so these values must never change without
+// bumping HashVersion; they were computed independently of hashContent
+func TestHashContentGolden(t *testing.T) {
+	img := goldenImage()
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	// The same pixels inside a wider image, so the stride differs from the width
+	wide := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	wide.SetNRGBA(1, 0, color.NRGBA{R: 255, A: 255})
+	wide.SetNRGBA(2, 0, color.NRGBA{B: 255, A: 128})
+	sub := wide.SubImage(image.Rect(1, 0, 3, 1)).(*image.NRGBA)
+
+	const pixelHash = "c2a9d4f66b4dc62ba16362229a7de789f9e43896e2cfece7ed6cd69e3682edbb"
+	tests := []struct {
+		name     string
+		itemType string
+		content  []byte
+		img      *image.NRGBA
+		want     string
+	}{
+		{"text", "text", []byte("hello"), nil, "87308eb66275c423fb093839def8ec105f6880f865e98c5c9c964aa467001bc2"},
+		{"empty text", "text", []byte{}, nil, "bb013426dceef6621f538460ea99a41ef8e09b456bbcd83a9bd1876e393ed7c0"},
+		{"turkish text", "text", []byte("Işık ğüşöç"), nil, "2efba380281efd0fad7a6ec80135436f259a8e91049430db9de8ba493941023e"},
+		{"decoded image", "image", encoded.Bytes(), img, pixelHash},
+		{"png decoded here", "image", encoded.Bytes(), nil, pixelHash},
+		{"sub-image", "image", nil, sub, pixelHash},
+		{"image that doesn't decode", "image", []byte("not a png"), nil, "833ec55b78a0b89719adfb3353b74486b311ecb96464eeb5e85e5abae1e4538e"},
+		{"files", "files", []byte(`["C:\\a.txt"]`), nil, "441a533500524d845303556250eb9cd918b1bb87d7e042b43ddb42381191b22f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashContent(tt.itemType, tt.content, tt.img); got != tt.want {
+				t.Errorf("hashContent = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Unversioned items keep matching through their bare SHA-256, of the bytes or the pixels
+func TestLegacyHashesGolden(t *testing.T) {
+	text := newContentHashes("text", []byte("hello"), nil)
+	if !text.matches(ClipboardItem{Hash: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}) {
+		t.Error("legacy text hash doesn't match")
+	}
+	if text.matches(ClipboardItem{Hash: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", HashVersion: HashVersion + 1}) {
+		t.Error("a hash from a newer version matched")
+	}
+
+	pixels := newContentHashes("image", []byte("any encoding"), goldenImage())
+	if !pixels.matches(ClipboardItem{Hash: "8834216c0716a06e3193ebb45bd4ba0107e8978893640afbfe9f0b36f3673593"}) {
+		t.Error("legacy pixel hash doesn't match")
+	}
+}
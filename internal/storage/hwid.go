@@ -18,21 +18,32 @@ func GetHardwareKey() ([]byte, error) {
 	}
 
 	// Combine machine ID with OS and architecture for additional uniqueness
-	combined := fmt.Sprintf("%s-%s-%s", machineID, runtime.GOOS, runtime.GOARCH)
+	combined := []byte(fmt.Sprintf("%s-%s-%s", machineID, runtime.GOOS, runtime.GOARCH))
+	defer Zero(combined)
 
 	// Generate SHA-256 hash
-	hash := sha256.Sum256([]byte(combined))
+	hash := sha256.Sum256(combined)
 
-	return hash[:], nil
+	key := make([]byte, len(hash))
+	copy(key, hash[:])
+	Zero(hash[:])
+	return key, nil
 }
 
 // GetKeyFingerprint returns a human-readable fingerprint of the hardware key
 // This can be used for debugging (first 8 chars only)
+// The fingerprint is derived from a hash of the key, so it never reveals key bytes;
+// it is the only key-related value that may be logged
 func GetKeyFingerprint() (string, error) {
 	key, err := GetHardwareKey()
 	if err != nil {
 		return "", err
 	}
+	defer Zero(key)
 
-	return fmt.Sprintf("%x", key[:4]), nil
+	salted := append([]byte("pano-fingerprint:"), key...)
+	defer Zero(salted)
+
+	sum := sha256.Sum256(salted)
+	return fmt.Sprintf("%x", sum[:4]), nil
 }
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"image"
+	"time"
+)
+
+const (
+	DefaultIntegrityThrottle = 20 * time.Millisecond // Pause between items in background passes
+	DefaultIntegrityMaxSize  = 5 * 1024 * 1024       // Larger items are skipped unless requested
+)
+
+// IntegrityOptions controls an integrity pass
+type IntegrityOptions struct {
+	Throttle     time.Duration // Pause between items to avoid hammering the disk
+	MaxSize      int           // Items larger than this are skipped (0 = no limit)
+	IncludeLarge bool          // Check every item regardless of MaxSize
+}
+
+// IntegrityIssue describes an item that failed verification
+type IntegrityIssue struct {
+	ID     string
+	Reason string
+}
+
+// IntegrityReport summarizes an integrity pass
+type IntegrityReport struct {
+	Checked int
+	Skipped int
+	Issues  []IntegrityIssue
+}
+
+// VerifyIntegrity decrypts every item and checks its content against the stored hash
+// Mismatches are only flagged (see IsCorrupt), never deleted. The pass stops early when ctx is cancelled.
+func (db *Database) VerifyIntegrity(ctx context.Context, opts IntegrityOptions) (IntegrityReport, error) {
+	report := IntegrityReport{Issues: make([]IntegrityIssue, 0)}
+
+	for _, item := range db.GetAllItems() {
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		default:
+		}
+
+		if !opts.IncludeLarge && opts.MaxSize > 0 && item.Size > opts.MaxSize {
+			report.Skipped++
+			continue
+		}
+
+		reason, upgrade := db.verifyItem(item)
+		report.Checked++
+
+		db.mu.Lock()
+		if reason != "" {
+			db.corrupt[item.ID] = reason
+			report.Issues = append(report.Issues, IntegrityIssue{ID: item.ID, Reason: reason})
+		} else {
+			delete(db.corrupt, item.ID)
+		}
+		if upgrade != "" {
+			db.upgradeHash(item, upgrade)
+		}
+		db.mu.Unlock()
+
+		if opts.Throttle > 0 {
+			select {
+			case <-ctx.Done():
+				return report, ctx.Err()
+			case <-time.After(opts.Throttle):
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// verifyItem returns why an item is damaged, or "" if it is intact
+// For an intact item with an older hash, upgrade is its canonical hash (see hash.go)
+func (db *Database) verifyItem(item ClipboardItem) (reason, upgrade string) {
+	_, content, err := db.GetItem(item.ID)
+	if err != nil {
+		return err.Error(), ""
+	}
+	defer Zero(content)
+
+	// A hash from a newer build can't be recomputed; decrypting is all that can be checked
+	if item.HashVersion > HashVersion {
+		return "", ""
+	}
+
+	var img *image.NRGBA
+	if item.Type == "image" {
+		if decoded, err := decodePNG(content); err == nil {
+			img = decoded
+		}
+	}
+	canonical := hashContent(item.Type, content, img)
+	if item.HashVersion == HashVersion {
+		if canonical != item.Hash {
+			return "content does not match stored hash", ""
+		}
+		return "", ""
+	}
+
+	// Rebuilt delta images are re-encoded, so their legacy hash of the original bytes
+	// can't be checked; a successful rebuild is all there is
+	if item.Delta != nil || (contentHashes{legacy: legacyHashes(content, img)}).matches(item) {
+		return "", canonical
+	}
+	return "content does not match stored hash", ""
+}
+
+// upgradeHash moves a verified item to the canonical hash, unless it changed since it
+// was read (caller must hold lock)
+// The upgrade is saved with the next save; losing it only means verifying again
+func (db *Database) upgradeHash(read ClipboardItem, hash string) {
+	for i := range db.Items {
+		if db.Items[i].ID == read.ID {
+			if db.Items[i].Content == read.Content && db.Items[i].HashVersion == read.HashVersion {
+				db.Items[i].Hash = hash
+				db.Items[i].HashVersion = HashVersion
+			}
+			return
+		}
+	}
+}
+
+// IsCorrupt returns whether an item failed the last integrity pass, and why
+func (db *Database) IsCorrupt(id string) (bool, string) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	reason, ok := db.corrupt[id]
+	return ok, reason
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// setItem changes the stored record of the item with id in place, as damage on disk would
+func setItem(db *Database, id string, change func(item *ClipboardItem)) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for i := range db.Items {
+		if db.Items[i].ID == id {
+			change(&db.Items[i])
+		}
+	}
+}
+
+// TestVerifyIntegrity damages items in different ways and checks that each is flagged,
+// never removed, that a repaired one is cleared by the next pass, and that a legacy hash
+// is upgraded
+func TestVerifyIntegrity(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "sağlam", "eski özet", "bozuk şifre", "yanlış özet", strings.Repeat("büyük ", 100))
+	items := db.GetAllItems() // Newest first
+	large, wrongHash, badCipher, legacy := items[0], items[1], items[2], items[3]
+
+	// Unversioned items carry the bare SHA-256 of their content
+	sum := sha256.Sum256([]byte("eski özet"))
+	setItem(db, legacy.ID, func(item *ClipboardItem) {
+		item.Hash, item.HashVersion = hex.EncodeToString(sum[:]), 0
+	})
+	setItem(db, badCipher.ID, func(item *ClipboardItem) { item.Content = "bozuk" })
+	setItem(db, wrongHash.ID, func(item *ClipboardItem) { item.Hash = strings.Repeat("0", 64) })
+
+	report, err := db.VerifyIntegrity(context.Background(), IntegrityOptions{MaxSize: 300})
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if report.Checked != 4 || report.Skipped != 1 {
+		t.Errorf("checked %d and skipped %d, want 4 and 1", report.Checked, report.Skipped)
+	}
+	flagged := map[string]bool{}
+	for _, issue := range report.Issues {
+		flagged[issue.ID] = true
+	}
+	if len(flagged) != 2 || !flagged[badCipher.ID] || !flagged[wrongHash.ID] {
+		t.Errorf("flagged %v, want the broken ciphertext and the wrong hash", report.Issues)
+	}
+	if corrupt, reason := db.IsCorrupt(wrongHash.ID); !corrupt || reason == "" {
+		t.Error("the item with a wrong hash isn't marked corrupt")
+	}
+	if corrupt, _ := db.IsCorrupt(large.ID); corrupt {
+		t.Error("the skipped item is marked corrupt")
+	}
+	if n := len(db.GetAllItems()); n != 5 {
+		t.Errorf("%d items after the pass, want all 5 kept", n)
+	}
+	for _, item := range db.GetAllItems() {
+		if item.ID == legacy.ID && item.HashVersion != HashVersion {
+			t.Error("the verified legacy hash wasn't upgraded")
+		}
+	}
+
+	// Repaired, and this time large items are included
+	setItem(db, wrongHash.ID, func(item *ClipboardItem) { item.Hash = wrongHash.Hash })
+	report, err = db.VerifyIntegrity(context.Background(), IntegrityOptions{MaxSize: 300, IncludeLarge: true})
+	if err != nil {
+		t.Fatalf("failed to verify again: %v", err)
+	}
+	if report.Checked != 5 || len(report.Issues) != 1 {
+		t.Errorf("second pass checked %d with issues %v", report.Checked, report.Issues)
+	}
+	if corrupt, _ := db.IsCorrupt(wrongHash.ID); corrupt {
+		t.Error("the repaired item is still marked corrupt")
+	}
+}
+
+// A cancelled pass stops before the first item
+func TestVerifyIntegrityCancelled(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "bir", "iki")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := db.VerifyIntegrity(ctx, IntegrityOptions{})
+	if !errors.Is(err, context.Canceled) || report.Checked != 0 {
+		t.Errorf("checked %d with %v, want none and the cancellation", report.Checked, err)
+	}
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	TempDir = "tmp" // Scratch directory for decrypted artifacts, under the data directory
+
+	DefaultTempMaxAge = 24 * time.Hour
+
+	// tempPrefix marks Pano files in the system temp directory
+	tempPrefix = "pano-"
+
+	// secureDeleteMaxSize is the largest file overwritten before removal
+	secureDeleteMaxSize = 16 * 1024 * 1024
+)
+
+// JanitorOptions controls a temp file cleanup pass
+type JanitorOptions struct {
+	MaxAge time.Duration // Only files older than this are removed
+	DryRun bool          // Report what would be removed without touching anything
+	Root   string        // Data directory override, empty uses the Pano data directory
+}
+
+// JanitorReport lists what a cleanup pass removed (or would remove)
+type JanitorReport struct {
+	Files  []string
+	Bytes  int64
+	Errors []error
+}
+
+// GetTempDir returns the scratch directory for decrypted artifacts, creating it if needed
+func GetTempDir() (string, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(filepath.Dir(dbPath), TempDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	return dir, nil
+}
+
+// CleanTempFiles removes leftover temp artifacts older than MaxAge
+// Known locations are the data directory's tmp folder, *.tmp files next to the
+// database and pano-* entries in the system temp directory
+func CleanTempFiles(opts JanitorOptions) (JanitorReport, error) {
+	var report JanitorReport
+
+	root := opts.Root
+	if root == "" {
+		dbPath, err := GetDatabasePath()
+		if err != nil {
+			return report, err
+		}
+		root = filepath.Dir(dbPath)
+	}
+	cutoff := time.Now().Add(-opts.MaxAge)
+
+	candidates := make([]string, 0)
+
+	// Everything under the scratch directory
+	tempDir := filepath.Join(root, TempDir)
+	filepath.WalkDir(tempDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			candidates = append(candidates, path)
+		}
+		return nil
+	})
+
+	// Interrupted atomic writes next to the database
+	if matches, err := filepath.Glob(filepath.Join(root, "*.tmp")); err == nil {
+		candidates = append(candidates, matches...)
+	}
+
+	// Files other features placed in the system temp directory (skipped for test roots)
+	if opts.Root == "" {
+		if matches, err := filepath.Glob(filepath.Join(os.TempDir(), tempPrefix+"*")); err == nil {
+			for _, m := range matches {
+				if info, err := os.Stat(m); err == nil && !info.IsDir() {
+					candidates = append(candidates, m)
+				}
+			}
+		}
+	}
+
+	for _, path := range candidates {
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() || info.ModTime().After(cutoff) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := secureRemove(path, info.Size()); err != nil {
+				report.Errors = append(report.Errors, err)
+				continue
+			}
+		}
+		report.Files = append(report.Files, path)
+		report.Bytes += info.Size()
+	}
+
+	if !opts.DryRun {
+		removeEmptyDirs(tempDir)
+	}
+	return report, nil
+}
+
+// secureRemove overwrites small files with zeros before deleting them
+func secureRemove(path string, size int64) error {
+	if size > 0 && size <= secureDeleteMaxSize {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err == nil {
+			_, werr := f.Write(make([]byte, size))
+			if werr == nil {
+				werr = f.Sync()
+			}
+			f.Close()
+			if werr != nil {
+				return fmt.Errorf("failed to overwrite %s: %w", path, werr)
+			}
+		}
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeEmptyDirs deletes empty subdirectories below dir, keeping dir itself
+func removeEmptyDirs(dir string) {
+	dirs := make([]string, 0)
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != dir {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	// Deepest first so parents empty out as children go
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+}
+
+// String summarizes the report for logging
+func (r JanitorReport) String() string {
+	if len(r.Files) == 0 && len(r.Errors) == 0 {
+		return "no temp files to clean"
+	}
+	parts := []string{fmt.Sprintf("%d temp files (%d bytes)", len(r.Files), r.Bytes)}
+	if len(r.Errors) > 0 {
+		parts = append(parts, fmt.Sprintf("%d errors", len(r.Errors)))
+	}
+	return strings.Join(parts, ", ")
+}
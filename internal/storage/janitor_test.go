@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestCleanTempFiles plants old and fresh leftovers in a data directory and checks that
+// only old files in the known places go, the dry run touches nothing, and the history
+// and empty scratch folders are handled as promised
+func TestCleanTempFiles(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-2 * DefaultTempMaxAge)
+	// Relative path: whether it is a leftover to remove. Everything but the fresh file is
+	// old, so only the location decides for the others
+	files := map[string]bool{
+		filepath.Join(TempDir, "export.png"):      true,
+		filepath.Join(TempDir, "nested", "a.txt"): true,
+		filepath.Join(TempDir, "fresh.txt"):       false,
+		DatabaseFile + ".tmp":                     true,
+		DatabaseFile:                              false,
+		filepath.Join("unrelated", "old.tmp"):     false,
+	}
+	for rel := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("gizli içerik"), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+		if rel != filepath.Join(TempDir, "fresh.txt") {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatalf("failed to age %s: %v", rel, err)
+			}
+		}
+	}
+	var want []string
+	for rel, leftover := range files {
+		if leftover {
+			want = append(want, filepath.Join(root, rel))
+		}
+	}
+	slices.Sort(want)
+
+	dry, err := CleanTempFiles(JanitorOptions{MaxAge: DefaultTempMaxAge, DryRun: true, Root: root})
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	slices.Sort(dry.Files)
+	if !slices.Equal(dry.Files, want) || dry.Bytes != int64(len(want)*len("gizli içerik")) {
+		t.Errorf("dry run reports %q (%d bytes), want %q", dry.Files, dry.Bytes, want)
+	}
+	for _, path := range want {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("the dry run removed %s", path)
+		}
+	}
+
+	report, err := CleanTempFiles(JanitorOptions{MaxAge: DefaultTempMaxAge, Root: root})
+	if err != nil || len(report.Errors) > 0 {
+		t.Fatalf("cleanup failed: %v %v", err, report.Errors)
+	}
+	for rel, leftover := range files {
+		_, err := os.Stat(filepath.Join(root, rel))
+		if gone := os.IsNotExist(err); gone != leftover {
+			t.Errorf("%s removed: %v, want %v", rel, gone, leftover)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, TempDir, "nested")); !os.IsNotExist(err) {
+		t.Error("the emptied scratch folder is still there")
+	}
+	if _, err := os.Stat(filepath.Join(root, TempDir)); err != nil {
+		t.Error("the scratch directory itself was removed")
+	}
+	if report.String() != "3 temp files (39 bytes)" {
+		t.Errorf("report reads %q", report.String())
+	}
+}
+
+// Files are overwritten before they are removed, so a recovered block holds zeros
+func TestSecureRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decrypted.png")
+	if err := os.WriteFile(path, []byte("gizli"), 0600); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	// Keep a handle open so the overwritten content can still be read after the unlink
+	f, err := os.Open(path)
+	if err != nil {
+		t.Skipf("can't keep the file open: %v", err)
+	}
+	defer f.Close()
+	if err := secureRemove(path, 5); err != nil {
+		t.Skipf("can't remove an open file here: %v", err)
+	}
+	data := make([]byte, 5)
+	if _, err := f.ReadAt(data, 0); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if !allZero(data) {
+		t.Errorf("removed file still held %q", data)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("the file is still there")
+	}
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Write-ahead journal
+//
+// Every captured item is appended to clipboard.db.journal and synced before the
+// full database is written; a successful save empties the journal again. If the
+// process dies in between, Load replays the records whose IDs are missing from
+// the database. Each record is
+//
+//	uvarint length | EncryptBytes(encodeItem(item)) | CRC-32 of the ciphertext
+//
+// and reading stops at the first record that is short, fails its checksum or
+// fails to decrypt, so a torn write at the tail only loses that one record.
+
+const JournalFile = "clipboard.db.journal"
+
+// journal appends capture records next to the database
+type journal struct {
+	file *os.File
+	key  []byte
+}
+
+// getJournalPath returns the journal location next to the database
+func getJournalPath() (string, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), JournalFile), nil
+}
+
+// openJournal opens the journal for appending
+func openJournal(key []byte) (*journal, error) {
+	path, err := getJournalPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	return &journal{file: f, key: key}, nil
+}
+
+// append writes one item record and syncs it to disk
+// A nil journal (tests, or a journal that failed to open) ignores the call
+func (j *journal) append(item ClipboardItem) error {
+	if j == nil {
+		return nil
+	}
+	encoded, err := encodeItem(item)
+	if err != nil {
+		return err
+	}
+	sealed, err := EncryptBytes(encoded, j.key)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt journal record: %w", err)
+	}
+
+	// One write per record keeps the torn-write window to a single record
+	record := make([]byte, 0, len(sealed)+binary.MaxVarintLen64+4)
+	record = binary.AppendUvarint(record, uint64(len(sealed)))
+	record = append(record, sealed...)
+	record = binary.LittleEndian.AppendUint32(record, crc32.ChecksumIEEE(sealed))
+
+	if _, err := j.file.Write(record); err != nil {
+		return fmt.Errorf("failed to append to journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return nil
+}
+
+// reset empties the journal after the database was saved
+func (j *journal) reset() error {
+	if j == nil {
+		return nil
+	}
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+	return nil
+}
+
+// close releases the journal file
+func (j *journal) close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
+
+// readJournal decodes records until the end or the first damaged record
+func readJournal(data []byte, key []byte) []ClipboardItem {
+	items := make([]ClipboardItem, 0)
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		length, err := binary.ReadUvarint(r)
+		if err != nil || length > maxFieldLen || int(length)+4 > r.Len() {
+			break
+		}
+		sealed := make([]byte, length)
+		r.Read(sealed)
+		var sum [4]byte
+		r.Read(sum[:])
+		if binary.LittleEndian.Uint32(sum[:]) != crc32.ChecksumIEEE(sealed) {
+			break
+		}
+
+		encoded, err := DecryptBytes(sealed, key)
+		if err != nil {
+			break
+		}
+		item, err := decodeItem(encoded)
+		if err != nil {
+			break
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// replayJournal adds journaled items missing from db.Items (caller holds db.mu)
+// Returns the number of recovered items
+func (db *Database) replayJournal() int {
+	path, err := getJournalPath()
+	if err != nil {
+		return 0
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return 0
+	}
+
+	present := make(map[string]bool, len(db.Items))
+	for _, item := range db.Items {
+		present[item.ID] = true
+	}
+	recovered := 0
+	for _, item := range readJournal(data, db.key) {
+		if !present[item.ID] {
+			db.Items = append(db.Items, item)
+			present[item.ID] = true
+			recovered++
+		}
+	}
+	if recovered > 0 {
+		sort.SliceStable(db.Items, func(i, j int) bool {
+			return db.Items[i].Timestamp.After(db.Items[j].Timestamp)
+		})
+	}
+	return recovered
+}
+
+// Close stops change delivery, writes pending changes and releases the journal;
+// closing twice is a no-op
+func (db *Database) Close() error {
+	stopErr := db.feed.close(closeTimeout)
+	if err := db.recompress.close(closeTimeout); err != nil && stopErr == nil {
+		stopErr = err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	// Later changes are written right away; the journal is gone after this
+	if err := db.flushInternal(); err != nil && stopErr == nil {
+		stopErr = err
+	}
+	db.saveDelay = 0
+	err := db.journal.close()
+	db.journal = nil
+	// The store stays set, so a later save fails instead of writing the ignored file
+	if closeErr := db.sqlite.close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return stopErr
+}
@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// With a master password the data key is no longer wrapped with the hardware key alone
+// but with an HMAC of it keyed by a PBKDF2-SHA256 key derived from the password, so the
+// history needs both this machine and the password. The salt, work factor and a check
+// value sit in KeyFile next to the wrapped key; the check tells a wrong password apart
+// from a changed machine (ErrKeyMismatch) before anything is decrypted.
+//
+// Setting the password re-keys the history (see rekey.go): the old data key was wrapped
+// with the hardware key alone, or was the hardware key itself. The pending key is
+// written with the password already, so an interrupted run asks for it on the next
+// start and finishes. Changing or removing the password rewraps the data key in a
+// single atomic write. Nothing is opened or written until the password matches.
+
+const (
+	// PasswordIterations is the PBKDF2 work factor of new master passwords
+	PasswordIterations = 600_000
+
+	// passwordKeyMagic starts a KeyFile wrapped with a master password; a key wrapped
+	// with the hardware key alone starts with a random nonce
+	passwordKeyMagic = "PANOPW1\n"
+	passwordCheck    = "pano master password"
+)
+
+var (
+	// ErrPasswordRequired is returned when the history is locked with a master password
+	// and none was given
+	ErrPasswordRequired = errors.New("history is locked with a master password")
+	// ErrPasswordSet is returned by SetPassword when the history already has one
+	ErrPasswordSet = errors.New("master password is already set")
+	// ErrNoPassword is returned when a master password is changed or removed without one
+	ErrNoPassword = errors.New("no master password is set")
+)
+
+// PasswordProvider asks for the master password; retry is true after a wrong one. ok
+// false gives up
+type PasswordProvider func(retry bool) (password string, ok bool)
+
+// passwordKeyFile is KeyFile after passwordKeyMagic
+type passwordKeyFile struct {
+	Salt       []byte `json:"salt"`
+	Iterations int    `json:"iterations"`
+	Check      []byte `json:"check"`
+	Wrapped    []byte `json:"wrapped"`
+}
+
+// masterPassword is a master password that matched its check value
+type masterPassword struct {
+	salt       []byte
+	iterations int
+	check      []byte
+	key        []byte // Derived from the password, locked in memory
+}
+
+// newMasterPassword derives a master password with a fresh salt
+func newMasterPassword(password string) (*masterPassword, error) {
+	salt := make([]byte, protectionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, PasswordIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive password key: %w", err)
+	}
+	return &masterPassword{
+		salt:       salt,
+		iterations: PasswordIterations,
+		check:      passwordCheckValue(key),
+		key:        newLockedKey(key),
+	}, nil
+}
+
+// passwordCheckValue is what a key derived from the right password hashes to
+func passwordCheckValue(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(passwordCheck))
+	return mac.Sum(nil)
+}
+
+// unlock derives the key of password with the file's salt and checks it
+func (f *passwordKeyFile) unlock(password string) (*masterPassword, error) {
+	if f.Iterations <= 0 || f.Iterations > portableMaxIterations {
+		return nil, fmt.Errorf("invalid password work factor %d", f.Iterations)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, f.Salt, f.Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive password key: %w", err)
+	}
+	if subtle.ConstantTimeCompare(passwordCheckValue(key), f.Check) != 1 {
+		Zero(key)
+		return nil, ErrWrongPassword
+	}
+	return &masterPassword{
+		salt:       bytes.Clone(f.Salt),
+		iterations: f.Iterations,
+		check:      bytes.Clone(f.Check),
+		key:        newLockedKey(key),
+	}, nil
+}
+
+// matches reports whether password is the one mp was derived from
+func (mp *masterPassword) matches(password string) bool {
+	file := passwordKeyFile{Salt: mp.salt, Iterations: mp.iterations, Check: mp.check}
+	other, err := file.unlock(password)
+	other.zero()
+	return err == nil
+}
+
+// wrappingKey layers the password key over the hardware key
+func (mp *masterPassword) wrappingKey(hwKey []byte) []byte {
+	mac := hmac.New(sha256.New, hwKey)
+	mac.Write(mp.key)
+	return mac.Sum(nil)
+}
+
+// zero wipes the derived key; mp may be nil
+func (mp *masterPassword) zero() {
+	if mp != nil {
+		Zero(mp.key)
+	}
+}
+
+// wrapKey wraps key with the hardware key, and with mp if set
+func wrapKey(key, hwKey []byte, mp *masterPassword) ([]byte, error) {
+	if mp == nil {
+		return EncryptBytes(key, hwKey)
+	}
+	wrapping := mp.wrappingKey(hwKey)
+	defer Zero(wrapping)
+	wrapped, err := EncryptBytes(key, wrapping)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(passwordKeyFile{
+		Salt:       mp.salt,
+		Iterations: mp.iterations,
+		Check:      mp.check,
+		Wrapped:    wrapped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(passwordKeyMagic), data...), nil
+}
+
+// unwrapKey opens a key written by wrapKey; a key wrapped with a master password needs
+// mp, and ErrPasswordRequired is returned without it
+func unwrapKey(data, hwKey []byte, mp *masterPassword) ([]byte, error) {
+	file, err := parsePasswordKeyFile(data)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return DecryptBytes(data, hwKey)
+	}
+	if mp == nil {
+		return nil, ErrPasswordRequired
+	}
+	wrapping := mp.wrappingKey(hwKey)
+	defer Zero(wrapping)
+	return DecryptBytes(file.Wrapped, wrapping)
+}
+
+// parsePasswordKeyFile reads the password part of a wrapped key, nil for a key wrapped
+// with the hardware key alone
+func parsePasswordKeyFile(data []byte) (*passwordKeyFile, error) {
+	if !bytes.HasPrefix(data, []byte(passwordKeyMagic)) {
+		return nil, nil
+	}
+	var file passwordKeyFile
+	if err := json.Unmarshal(data[len(passwordKeyMagic):], &file); err != nil {
+		return nil, fmt.Errorf("failed to parse data key: %w", err)
+	}
+	return &file, nil
+}
+
+// readPasswordKeyFile returns the password part of KeyFile, or of the pending key after
+// an interrupted SetPassword; nil without a master password
+func readPasswordKeyFile() (*passwordKeyFile, error) {
+	path, err := GetKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range []string{path, path + pendingKeySuffix} {
+		data, err := os.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data key: %w", err)
+		}
+		if file, err := parsePasswordKeyFile(data); file != nil || err != nil {
+			return file, err
+		}
+	}
+	return nil, nil
+}
+
+// PasswordEnabled reports whether the history is locked with a master password, without
+// opening it
+func PasswordEnabled() bool {
+	file, err := readPasswordKeyFile()
+	return err == nil && file != nil
+}
+
+// askPassword asks provider until a password matches file; giving up after a wrong one
+// returns ErrWrongPassword, before any ErrPasswordRequired
+func askPassword(file *passwordKeyFile, provider PasswordProvider) (*masterPassword, error) {
+	if provider == nil {
+		return nil, ErrPasswordRequired
+	}
+	for retry := false; ; retry = true {
+		password, ok := provider(retry)
+		if !ok {
+			if retry {
+				return nil, ErrWrongPassword
+			}
+			return nil, ErrPasswordRequired
+		}
+		mp, err := file.unlock(password)
+		if errors.Is(err, ErrWrongPassword) {
+			continue
+		}
+		return mp, err
+	}
+}
+
+// HasPassword reports whether the history is locked with a master password
+func (db *Database) HasPassword() bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.password != nil
+}
+
+// SetPassword locks the history with a master password and re-keys it, so no key that
+// opened it without the password still does
+// progress is passed to the re-key, see Rekey
+func (db *Database) SetPassword(password string, progress func(done, total int)) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	// Derived before locking; it takes a while on purpose
+	mp, err := newMasterPassword(password)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.loadErr != nil {
+		mp.zero()
+		return fmt.Errorf("database not loaded: %w", db.loadErr)
+	}
+	if db.password != nil {
+		mp.zero()
+		return ErrPasswordSet
+	}
+	// A re-key left over is finished first, its pending key isn't wrapped with mp
+	if err := db.resumeRekey(); err != nil {
+		mp.zero()
+		return err
+	}
+
+	db.password = mp
+	if err := db.beginRekey(); err != nil {
+		db.password = nil
+		mp.zero()
+		return err
+	}
+	// From here on the pending key needs the password, so it stays set even on failure
+	if err := db.rekeyInternal(progress); err != nil {
+		return err
+	}
+	db.commitAll()
+	db.writeAudit(AuditEntry{Op: AuditPasswordSet})
+	return nil
+}
+
+// ChangePassword replaces the master password after checking the current one
+func (db *Database) ChangePassword(current, password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	mp, err := newMasterPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := db.rewrapKey(current, mp); err != nil {
+		mp.zero()
+		return err
+	}
+	return nil
+}
+
+// RemovePassword unlocks the history for good after checking the master password; the
+// data key is wrapped with the hardware key alone again
+func (db *Database) RemovePassword(current string) error {
+	return db.rewrapKey(current, nil)
+}
+
+// rewrapKey writes KeyFile wrapped with mp (nil for none) in place of the master
+// password current
+func (db *Database) rewrapKey(current string, mp *masterPassword) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.loadErr != nil {
+		return fmt.Errorf("database not loaded: %w", db.loadErr)
+	}
+	if db.password == nil {
+		return ErrNoPassword
+	}
+	if !db.password.matches(current) {
+		return ErrWrongPassword
+	}
+	// The pending key is wrapped with the current password; it must be gone first
+	if err := db.resumeRekey(); err != nil {
+		return err
+	}
+
+	keyPath, err := GetKeyPath()
+	if err != nil {
+		return err
+	}
+	if err := writeWrappedKey(keyPath, db.key, mp); err != nil {
+		return err
+	}
+	db.password.zero()
+	db.password = mp
+	if mp == nil {
+		db.writeAudit(AuditEntry{Op: AuditPasswordRemove})
+	} else {
+		db.writeAudit(AuditEntry{Op: AuditPasswordChange})
+	}
+	return nil
+}
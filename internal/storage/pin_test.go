@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+// Pins stop at the pin limit, unpinning always works and lowering the limit unpins nothing
+func TestPinLimit(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "bir", "iki", "üç")
+	items := db.GetAllItems()
+	db.SetPinLimit(2)
+
+	for _, item := range items[:2] {
+		if err := db.TogglePin(item.ID); err != nil {
+			t.Fatalf("failed to pin: %v", err)
+		}
+	}
+	if err := db.TogglePin(items[2].ID); !errors.Is(err, ErrPinLimitReached) {
+		t.Fatalf("pinning past the limit gave %v", err)
+	}
+	if got := db.Counts().Pinned; got != 2 {
+		t.Errorf("%d pinned, want 2", got)
+	}
+
+	db.SetPinLimit(1)
+	if db.Counts().Pinned != 2 {
+		t.Error("lowering the limit unpinned items")
+	}
+	if err := db.TogglePin(items[0].ID); err != nil {
+		t.Fatalf("unpinning over the limit gave %v", err)
+	}
+	if err := db.TogglePin(items[0].ID); !errors.Is(err, ErrPinLimitReached) {
+		t.Errorf("pinning at the lowered limit gave %v", err)
+	}
+
+	db.SetPinLimit(0)
+	if db.GetPinLimit() != DefaultPinLimit {
+		t.Errorf("pin limit 0 became %d, want the default", db.GetPinLimit())
+	}
+}
+
+// The item limit only ever evicts unpinned items, and pinned ones take none of its slots
+func TestLimitKeepsPinned(t *testing.T) {
+	db := newTestDB(t)
+	db.SetGraceWindow(0)
+	db.SetMaxItems(10)
+	addTexts(t, db, numbered("sabit", 10)...)
+	for _, item := range db.GetAllItems() {
+		if err := db.TogglePin(item.ID); err != nil {
+			t.Fatalf("failed to pin: %v", err)
+		}
+	}
+
+	addTexts(t, db, numbered("geçici", 15)...)
+	counts := db.Counts()
+	if counts.Pinned != 10 {
+		t.Fatalf("%d pinned items left, want 10", counts.Pinned)
+	}
+	if counts.Active != 10 {
+		t.Errorf("%d unpinned items kept, want the limit of 10", counts.Active)
+	}
+}
+
+// A capture can be stored pinned, also by pinning the duplicate it moves to the top,
+// and neither gets past the pin limit
+func TestCapturePinned(t *testing.T) {
+	db := newTestDB(t)
+	db.SetPinLimit(2)
+	pinned := CaptureInfo{Forced: true, Pinned: true}
+	addTexts(t, db, "eski")
+
+	if err := db.AddCapturedItem("text", []byte("elle yazılan"), pinned); err != nil {
+		t.Fatalf("failed to add pinned: %v", err)
+	}
+	if err := db.AddCapturedItem("text", []byte("eski"), pinned); err != nil {
+		t.Fatalf("failed to pin the duplicate: %v", err)
+	}
+	items := db.GetAllItems()
+	if len(items) != 2 || !items[0].Pinned || !items[1].Pinned {
+		t.Fatalf("history is %+v", items)
+	}
+
+	err := db.AddCapturedItem("text", []byte("fazla"), pinned)
+	if !errors.Is(err, ErrPinLimitReached) {
+		t.Errorf("a pinned capture past the limit gave %v", err)
+	}
+	if got := historyTexts(t, db); len(got) != 2 {
+		t.Errorf("history after the refused capture is %q", got)
+	}
+
+	entries, err := db.AuditEntries()
+	if err != nil || len(entries) != 2 || entries[0].Op != AuditPin || entries[1].Op != AuditPin {
+		t.Errorf("audit log is %+v, %v", entries, err)
+	}
+}
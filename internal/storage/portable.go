@@ -0,0 +1,390 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A portable export carries the history to another machine: unlike the database it is
+// encrypted with a key derived from a password instead of the hardware key. The file is
+//
+//	"PANOX" version salt(16) iterations(uint32, big-endian) AES-256-GCM(nonce + payload)
+//
+// where the key is PBKDF2-HMAC-SHA256 of the password and the payload is a JSON array
+// of portableItem with the plaintext content. Delta images are written as full images;
+// items whose content can't be decrypted are left out. Import stores them again like
+// captures, hashed and encrypted with this machine's key.
+
+const (
+	portableMagic    = "PANOX"
+	portableVersion  = 1
+	portableSaltSize = 16
+
+	// portableMaxIterations bounds the work factor Import accepts from a file
+	portableMaxIterations = 10_000_000
+
+	// PortableIterations is the PBKDF2 work factor of new exports
+	PortableIterations = 600_000
+)
+
+var (
+	// ErrEmptyPassword is returned by Export without a password
+	ErrEmptyPassword = errors.New("password is empty")
+	// ErrExportInDataDir is returned by Export for a path inside Pano's data directory,
+	// where it could replace the live database or its journal
+	ErrExportInDataDir = errors.New("export path is inside Pano's data directory")
+	// ErrWrongPassword is returned by Import when the password doesn't open the file, and
+	// for a master password that doesn't match (see password.go)
+	// GCM can't tell a wrong password from a damaged ciphertext; the header was intact
+	ErrWrongPassword = errors.New("wrong password")
+	// ErrCorruptExport is returned by Import for a file that isn't a readable portable export
+	ErrCorruptExport = errors.New("not a valid portable export")
+)
+
+// portableItem is an item in a portable export
+type portableItem struct {
+	Type       string          `json:"type"`
+	Timestamp  time.Time       `json:"timestamp"`
+	Pinned     bool            `json:"pinned,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	Title      string          `json:"title,omitempty"` // Set by the user
+	Content    []byte          `json:"content"`
+	Protection *ItemProtection `json:"protection,omitempty"` // Protected items stay protected on import
+}
+
+// Export writes the history to path, encrypted with a key derived from password
+func (db *Database) Export(path, password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	inside, err := isInDataDir(path)
+	if err != nil {
+		return err
+	}
+	if inside {
+		return ErrExportInDataDir
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	items := make([]portableItem, 0, len(db.Items))
+	defer func() {
+		for _, item := range items {
+			Zero(item.Content)
+		}
+	}()
+	for i := range db.Items {
+		item := &db.Items[i]
+		if item.Deleted {
+			continue
+		}
+		var content []byte
+		if item.Delta != nil {
+			content, err = db.fullContent(item)
+		} else {
+			content, err = Decrypt(item.Content, db.key)
+		}
+		if err != nil {
+			continue
+		}
+		var title string
+		if item.Title != "" {
+			if decrypted, err := Decrypt(item.Title, db.key); err == nil {
+				title = string(decrypted)
+			}
+		}
+		items = append(items, portableItem{
+			Type:       item.Type,
+			Timestamp:  item.Timestamp,
+			Pinned:     item.Pinned,
+			Tags:       item.Tags,
+			Title:      title,
+			Content:    content,
+			Protection: item.Protection,
+		})
+	}
+
+	payload, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("failed to encode items: %w", err)
+	}
+	defer Zero(payload)
+
+	data, err := sealPortable(payload, password)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	db.writeAudit(AuditEntry{Op: AuditExport, Count: len(items)})
+	return nil
+}
+
+// sealPortable encrypts payload into the portable file format
+func sealPortable(payload []byte, password string) ([]byte, error) {
+	salt := make([]byte, portableSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, PortableIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	defer Zero(key)
+
+	ciphertext, err := EncryptBytes(payload, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt export: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(portableMagic)
+	buf.WriteByte(portableVersion)
+	buf.Write(salt)
+	binary.Write(&buf, binary.BigEndian, uint32(PortableIterations))
+	buf.Write(ciphertext)
+	return buf.Bytes(), nil
+}
+
+// openPortable checks the header of a portable export and decrypts its payload
+func openPortable(data []byte, password string) ([]byte, error) {
+	header := len(portableMagic) + 1 + portableSaltSize + 4
+	if len(data) < header || string(data[:len(portableMagic)]) != portableMagic {
+		return nil, ErrCorruptExport
+	}
+	if version := data[len(portableMagic)]; version > portableVersion {
+		return nil, fmt.Errorf("export format version %d is newer than supported (%d)", version, portableVersion)
+	}
+	salt := data[len(portableMagic)+1 : len(portableMagic)+1+portableSaltSize]
+	iterations := binary.BigEndian.Uint32(data[header-4 : header])
+	if iterations == 0 || iterations > portableMaxIterations {
+		return nil, ErrCorruptExport
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, int(iterations), 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	defer Zero(key)
+
+	payload, err := DecryptBytes(data[header:], key)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+	return payload, nil
+}
+
+// Import reads a portable export and either merges its items into the history or
+// replaces the history with them
+// Merged items that match an existing item by hash are skipped (an imported pin still
+// pins the existing one); pins beyond the pin limit are dropped and the limit then
+// removes the oldest unpinned items. A restore point is taken first
+func (db *Database) Import(path, password string, merge bool) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read import: %w", err)
+	}
+	payload, err := openPortable(data, password)
+	if err != nil {
+		return err
+	}
+	defer Zero(payload)
+
+	var imported []portableItem
+	if err := json.Unmarshal(payload, &imported); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorruptExport, err)
+	}
+	defer func() {
+		for _, item := range imported {
+			Zero(item.Content)
+		}
+	}()
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.loadErr != nil {
+		return fmt.Errorf("database not loaded: %w", db.loadErr)
+	}
+
+	return db.withSafetyBackup(SafetyImport, func() error {
+		items := make([]ClipboardItem, 0, len(db.Items)+len(imported))
+		if merge {
+			items = append(items, db.Items...)
+		}
+		pinned := 0
+		for _, item := range items {
+			if item.Pinned && !item.Deleted {
+				pinned++
+			}
+		}
+
+		added := 0
+		for _, p := range imported {
+			item, hashes, err := db.importItem(p)
+			if err != nil {
+				continue
+			}
+			duplicate := false
+			for i := range items {
+				if items[i].Type == item.Type && !items[i].Deleted && hashes.matches(items[i]) {
+					if item.Pinned && !items[i].Pinned && pinned < db.pinLimit {
+						items[i].Pinned = true
+						pinned++
+					}
+					duplicate = true
+					break
+				}
+			}
+			if duplicate {
+				continue
+			}
+			if item.Pinned {
+				if pinned < db.pinLimit {
+					pinned++
+				} else {
+					item.Pinned = false
+				}
+			}
+			items = append(items, item)
+			added++
+		}
+
+		// The limit keeps the first unpinned items, so the newest must come first
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Timestamp.After(items[j].Timestamp)
+		})
+		db.Items = items
+		db.enforceLimit()
+
+		ids := make([]string, 0, len(db.Items))
+		for _, item := range db.Items {
+			ids = append(ids, item.ID)
+		}
+		db.commit(ChangeReload, ids...)
+		db.writeAudit(AuditEntry{Op: AuditImport, Count: added})
+		return db.saveInternal()
+	})
+}
+
+// importItem turns an exported item into a stored one (caller must hold lock)
+// Text goes through the redaction rules like a capture
+func (db *Database) importItem(p portableItem) (ClipboardItem, contentHashes, error) {
+	content := p.Content
+	if p.Type != "text" && p.Type != "image" && p.Type != "files" {
+		return ClipboardItem{}, contentHashes{}, fmt.Errorf("unsupported item type %q", p.Type)
+	}
+	if p.Type == "files" {
+		if _, err := DecodeFiles(content); err != nil {
+			return ClipboardItem{}, contentHashes{}, err
+		}
+	}
+	if len(content) > MaxItemSize {
+		return ClipboardItem{}, contentHashes{}, fmt.Errorf("item too large")
+	}
+
+	tags, err := NormalizeTags(p.Tags)
+	if err != nil {
+		return ClipboardItem{}, contentHashes{}, err
+	}
+	item := ClipboardItem{
+		ID:          db.newItemID(),
+		Type:        p.Type,
+		Timestamp:   p.Timestamp,
+		Pinned:      p.Pinned,
+		HashVersion: HashVersion,
+		Tags:        tags,
+		Protection:  p.Protection,
+	}
+	if item.Timestamp.IsZero() {
+		item.Timestamp = time.Now()
+	}
+	if p.Title != "" {
+		if item.Title, err = Encrypt([]byte(p.Title), db.key); err != nil {
+			return ClipboardItem{}, contentHashes{}, fmt.Errorf("failed to encrypt title: %w", err)
+		}
+	}
+
+	var img *image.NRGBA
+	if p.Type == "text" {
+		masked, changed, err := db.redactor.Redact(string(content))
+		if err != nil {
+			return ClipboardItem{}, contentHashes{}, err
+		}
+		if changed {
+			content = []byte(masked)
+			item.Redacted = true
+		}
+		item.Class = ClassifyText(string(content))
+		if title, _, ok := ExtractTitle(string(content)); ok {
+			if item.TitleCache, err = Encrypt([]byte(title), db.key); err != nil {
+				return ClipboardItem{}, contentHashes{}, fmt.Errorf("failed to encrypt title: %w", err)
+			}
+		}
+	} else if decoded, err := decodePNG(content); err == nil {
+		img = decoded
+		item.PHash = formatPHash(perceptualHash(img))
+	}
+
+	hashes := newContentHashes(p.Type, content, img)
+	item.Hash = hashes.canonical
+	item.Size = len(content)
+
+	if item.Preview, err = makePreview(p.Type, content, img, db.key); err != nil {
+		return ClipboardItem{}, contentHashes{}, err
+	}
+
+	encrypted, err := Encrypt(content, db.key)
+	if err != nil {
+		return ClipboardItem{}, contentHashes{}, fmt.Errorf("failed to encrypt content: %w", err)
+	}
+	item.Content = encrypted
+	return item, hashes, nil
+}
+
+// isInDataDir reports whether path lies in the directory holding the database
+// Paths are compared case-insensitively, as Windows does
+func isInDataDir(path string) (bool, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return false, err
+	}
+	dataDir, err := filepath.Abs(filepath.Dir(dbPath))
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve data directory: %w", err)
+	}
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve export path: %w", err)
+	}
+	// A link to the data directory is resolved too; the file itself needn't exist yet
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(target)); err == nil {
+		target = filepath.Join(resolved, filepath.Base(target))
+	}
+	if resolved, err := filepath.EvalSymlinks(dataDir); err == nil {
+		dataDir = resolved
+	}
+
+	rel, err := filepath.Rel(strings.ToLower(dataDir), strings.ToLower(target))
+	if err != nil {
+		return false, nil // Another volume
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"unicode/utf8"
+)
+
+// Previews let the list render cards without decrypting full contents: every item keeps
+// an encrypted preview next to its content, made once when it is added. Text items keep
+// their first PreviewTextBytes; image items keep the original size and a PNG thumbnail
+// of at most previewMaxPixels; files items keep their file names (see files.go). Items
+// saved by older versions get theirs on load.
+
+const (
+	PreviewTextBytes = 8 * 1024 // Bytes of a text item kept in its preview
+
+	// previewMaxPixels bounds the thumbnail area: twice a 320x180 card in each direction,
+	// so it stays sharp at 200% scaling. Bounding the area instead of the sides keeps
+	// panoramas tall enough to read
+	previewMaxPixels = 640 * 360
+)
+
+// ErrNoPreview is returned by GetPreview for an item without a preview
+var ErrNoPreview = errors.New("item has no preview")
+
+// Preview is the decrypted preview of an item
+type Preview struct {
+	Text      string // Head of a text item, at most PreviewTextBytes
+	Thumbnail []byte // PNG thumbnail of an image item
+	Width     int    // Size of the original image; the thumbnail may be smaller
+	Height    int
+	Files     []string // Names of the first files of a files item
+	FileCount int      // Number of files of a files item, Files may name fewer
+}
+
+// Complete reports whether a text preview holds the whole text of an item of size bytes
+func (p Preview) Complete(size int) bool {
+	return len(p.Text) >= size
+}
+
+// GetPreview returns the decrypted preview of an item
+func (db *Database) GetPreview(id string) (Preview, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, item := range db.Items {
+		if item.ID == id {
+			if item.Preview == "" {
+				return Preview{}, ErrNoPreview
+			}
+			decrypted, err := Decrypt(item.Preview, db.key)
+			if err != nil {
+				return Preview{}, fmt.Errorf("failed to decrypt preview: %w", err)
+			}
+			defer Zero(decrypted)
+			return decodePreview(item.Type, decrypted)
+		}
+	}
+	return Preview{}, fmt.Errorf("item not found")
+}
+
+// makePreview returns the encrypted preview of content; img is the decoded image, nil
+// for text or an image that didn't decode
+func makePreview(itemType string, content []byte, img *image.NRGBA, key []byte) (string, error) {
+	var plain []byte
+	switch {
+	case itemType == "text":
+		plain = textHead(content)
+	case itemType == "files":
+		encoded, err := encodeFilesPreview(content)
+		if err != nil {
+			return "", err
+		}
+		plain = encoded
+	case img != nil:
+		encoded, err := encodeImagePreview(img)
+		if err != nil {
+			return "", err
+		}
+		plain = encoded
+	default:
+		return "", nil
+	}
+	encrypted, err := Encrypt(plain, key)
+	if itemType == "image" {
+		Zero(plain)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt preview: %w", err)
+	}
+	return encrypted, nil
+}
+
+// textHead returns at most PreviewTextBytes of text, cut at a rune boundary
+func textHead(data []byte) []byte {
+	if len(data) <= PreviewTextBytes {
+		return data
+	}
+	cut := PreviewTextBytes
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	return data[:cut]
+}
+
+// encodeImagePreview writes the image size as two uvarints followed by the thumbnail PNG
+func encodeImagePreview(img *image.NRGBA) ([]byte, error) {
+	bounds := img.Bounds()
+	var buf bytes.Buffer
+	buf.Write(uvarintBytes(uint64(bounds.Dx())))
+	buf.Write(uvarintBytes(uint64(bounds.Dy())))
+	if err := png.Encode(&buf, shrinkImage(img, previewMaxPixels)); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodePreview parses decrypted preview data of an item of itemType
+func decodePreview(itemType string, data []byte) (Preview, error) {
+	if itemType == "files" {
+		return decodeFilesPreview(data)
+	}
+	if itemType != "image" {
+		return Preview{Text: string(data)}, nil
+	}
+	r := bytes.NewReader(data)
+	w, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Preview{}, fmt.Errorf("invalid preview: %w", err)
+	}
+	h, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Preview{}, fmt.Errorf("invalid preview: %w", err)
+	}
+	thumbnail := make([]byte, r.Len())
+	_, _ = r.Read(thumbnail)
+	return Preview{Thumbnail: thumbnail, Width: int(w), Height: int(h)}, nil
+}
+
+// shrinkImage scales img down to at most maxPixels, keeping its aspect ratio; smaller
+// images are returned as they are. Each target pixel averages the source pixels it
+// covers, weighted by alpha so transparent pixels don't darken the edges
+func shrinkImage(img *image.NRGBA, maxPixels int) *image.NRGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w*h <= maxPixels {
+		return img
+	}
+	scale := math.Sqrt(float64(maxPixels) / float64(w*h))
+	newW := max(1, int(float64(w)*scale))
+	newH := max(1, int(float64(h)*scale))
+
+	thumb := image.NewNRGBA(image.Rect(0, 0, newW, newH))
+	for y := 0; y < newH; y++ {
+		y0, y1 := y*h/newH, max((y+1)*h/newH, y*h/newH+1)
+		for x := 0; x < newW; x++ {
+			x0, x1 := x*w/newW, max((x+1)*w/newW, x*w/newW+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := img.Pix[sy*img.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					alpha := uint64(p[3])
+					r += uint64(p[0]) * alpha
+					g += uint64(p[1]) * alpha
+					b += uint64(p[2]) * alpha
+					a += alpha
+					n++
+				}
+			}
+			o := thumb.PixOffset(x, y)
+			if a > 0 {
+				thumb.Pix[o] = uint8(r / a)
+				thumb.Pix[o+1] = uint8(g / a)
+				thumb.Pix[o+2] = uint8(b / a)
+			}
+			thumb.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return thumb
+}
+
+// backfillPreviews makes the previews missing from items saved by older versions and
+// returns how many it made; items that can't be read are left for the next load
+// (caller must hold lock)
+func (db *Database) backfillPreviews() int {
+	made := 0
+	for i := range db.Items {
+		item := &db.Items[i]
+		if item.Preview != "" || (item.Type != "text" && item.Type != "image" && item.Type != "files") {
+			continue
+		}
+		content, err := Decrypt(item.Content, db.key)
+		if err != nil {
+			continue
+		}
+		if item.Delta != nil {
+			full, err := db.reconstructImage(item, content)
+			Zero(content)
+			if err != nil {
+				continue
+			}
+			content = full
+		}
+
+		var img *image.NRGBA
+		if item.Type == "image" {
+			if img, err = decodePNG(content); err != nil {
+				Zero(content)
+				continue
+			}
+		}
+		preview, err := makePreview(item.Type, content, img, db.key)
+		Zero(content)
+		if err != nil {
+			continue
+		}
+		item.Preview = preview
+		made++
+	}
+	return made
+}
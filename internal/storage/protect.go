@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// A protected item has a passphrase of its own: its preview is masked wherever items
+// are shown and its content is handed out only after the passphrase was checked (see
+// clipboard.Manager.CopyProtected). Only a PBKDF2-SHA256 verifier is stored, never the
+// passphrase. The content stays encrypted with the data key like any other item, so
+// protection keeps it from someone at the unlocked machine, not from the key holder.
+
+const (
+	// ProtectionIterations is the PBKDF2 work factor of new passphrases
+	ProtectionIterations = 600_000
+
+	protectionSaltSize = 16
+)
+
+// protectionIterations is the work factor newProtection uses, lowered by tests
+var protectionIterations = ProtectionIterations
+
+var (
+	// ErrProtected is returned when the content of a protected item is asked for
+	// without its passphrase
+	ErrProtected = errors.New("item is protected")
+	// ErrNotProtected is returned when a passphrase is checked for an unprotected item
+	ErrNotProtected = errors.New("item is not protected")
+	// ErrWrongPassphrase is returned when a passphrase doesn't match the item's
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// ItemProtection is the verifier of an item's passphrase
+type ItemProtection struct {
+	Salt       []byte `json:"salt"`
+	Hash       []byte `json:"hash"`
+	Iterations int    `json:"iterations"`
+}
+
+// newProtection derives the verifier of passphrase with a fresh salt
+func newProtection(passphrase string) (*ItemProtection, error) {
+	salt := make([]byte, protectionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	hash, err := pbkdf2.Key(sha256.New, passphrase, salt, protectionIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive passphrase hash: %w", err)
+	}
+	return &ItemProtection{Salt: salt, Hash: hash, Iterations: protectionIterations}, nil
+}
+
+// matches reports whether passphrase is the one p was derived from
+func (p *ItemProtection) matches(passphrase string) bool {
+	if p.Iterations <= 0 || p.Iterations > portableMaxIterations {
+		return false
+	}
+	hash, err := pbkdf2.Key(sha256.New, passphrase, p.Salt, p.Iterations, len(p.Hash))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hash, p.Hash) == 1
+}
+
+// IsProtected reports whether the item needs its passphrase to be read
+func (item ClipboardItem) IsProtected() bool {
+	return item.Protection != nil
+}
+
+// IsProtected reports whether the item with id needs its passphrase to be read
+func (db *Database) IsProtected(id string) bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, item := range db.Items {
+		if item.ID == id {
+			return item.IsProtected()
+		}
+	}
+	return false
+}
+
+// Protect sets a passphrase on an item; an item that already has one returns ErrProtected
+func (db *Database) Protect(id, passphrase string) error {
+	if passphrase == "" {
+		return ErrEmptyPassword
+	}
+	// Derived before locking; it takes a while on purpose
+	protection, err := newProtection(passphrase)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for i := range db.Items {
+		if db.Items[i].ID != id {
+			continue
+		}
+		if db.Items[i].IsProtected() {
+			return ErrProtected
+		}
+		db.Items[i].Protection = protection
+		db.commit(ChangeUpdate, id)
+		db.recordAudit(AuditProtect, db.Items[i])
+		return db.scheduleSave()
+	}
+	return fmt.Errorf("item not found")
+}
+
+// Unprotect removes an item's passphrase after checking it
+func (db *Database) Unprotect(id, passphrase string) error {
+	if err := db.CheckPassphrase(id, passphrase); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for i := range db.Items {
+		if db.Items[i].ID != id {
+			continue
+		}
+		// Checked above; the passphrase can't have changed without unprotecting first
+		db.Items[i].Protection = nil
+		db.commit(ChangeUpdate, id)
+		db.recordAudit(AuditUnprotect, db.Items[i])
+		return db.scheduleSave()
+	}
+	return fmt.Errorf("item not found")
+}
+
+// CheckPassphrase returns nil if passphrase opens the protected item with id,
+// ErrWrongPassphrase if it doesn't and ErrNotProtected for an unprotected item
+// The derivation runs without the lock
+func (db *Database) CheckPassphrase(id, passphrase string) error {
+	db.mu.RLock()
+	var protection *ItemProtection
+	found := false
+	for _, item := range db.Items {
+		if item.ID == id {
+			protection, found = item.Protection, true
+			break
+		}
+	}
+	db.mu.RUnlock()
+
+	switch {
+	case !found:
+		return fmt.Errorf("item not found")
+	case protection == nil:
+		return ErrNotProtected
+	case !protection.matches(passphrase):
+		return ErrWrongPassphrase
+	}
+	return nil
+}
+
+// RecordUnlockDenied logs a wrong passphrase or a refused attempt for a protected item
+func (db *Database) RecordUnlockDenied(id string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, item := range db.Items {
+		if item.ID == id {
+			db.recordAudit(AuditUnlockDenied, item)
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// fastProtection lowers the work factor of new passphrases until t ends
+func fastProtection(t *testing.T) {
+	iterations := protectionIterations
+	protectionIterations = 1000
+	t.Cleanup(func() { protectionIterations = iterations })
+}
+
+// TestProtect sets, checks and removes an item's passphrase; the verifier is saved with
+// the history and every step is logged
+func TestProtect(t *testing.T) {
+	fastProtection(t)
+	db := newTestDB(t)
+	addTexts(t, db, "gizli", "açık")
+	items := db.GetAllItems() // açık, gizli
+	secret, open := items[1].ID, items[0].ID
+
+	if err := db.Protect(secret, ""); !errors.Is(err, ErrEmptyPassword) {
+		t.Errorf("an empty passphrase gave %v", err)
+	}
+	if err := db.Protect(secret, "parola"); err != nil {
+		t.Fatalf("failed to protect: %v", err)
+	}
+	if err := db.Protect(secret, "başka"); !errors.Is(err, ErrProtected) {
+		t.Errorf("protecting twice gave %v", err)
+	}
+	if !db.IsProtected(secret) || db.IsProtected(open) {
+		t.Error("IsProtected reports the wrong items")
+	}
+	if err := db.CheckPassphrase(open, "parola"); !errors.Is(err, ErrNotProtected) {
+		t.Errorf("checking an unprotected item gave %v", err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	db.Close()
+
+	reopened, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+	if err := reopened.CheckPassphrase(secret, "Parola"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("a wrong passphrase gave %v", err)
+	}
+	if err := reopened.CheckPassphrase(secret, "parola"); err != nil {
+		t.Errorf("the passphrase didn't survive a reopen: %v", err)
+	}
+	if err := reopened.Unprotect(secret, "yanlış"); !errors.Is(err, ErrWrongPassphrase) || !reopened.IsProtected(secret) {
+		t.Errorf("unprotecting with a wrong passphrase gave %v", err)
+	}
+	if err := reopened.Unprotect(secret, "parola"); err != nil {
+		t.Fatalf("failed to unprotect: %v", err)
+	}
+	if reopened.IsProtected(secret) {
+		t.Error("the item is still protected")
+	}
+
+	reopened.RecordUnlockDenied(secret)
+	entries, err := reopened.AuditEntries()
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	want := []AuditOp{AuditUnlockDenied, AuditUnprotect, AuditProtect}
+	if got := auditOps(entries); len(got) < len(want) || !slices.Equal(got[:len(want)], want) {
+		t.Errorf("audit log is %q", got)
+	}
+}
+
+// A verifier with an out-of-range work factor, as a damaged file could hold, never matches
+func TestProtectionIterationsChecked(t *testing.T) {
+	fastProtection(t)
+	p, err := newProtection("parola")
+	if err != nil {
+		t.Fatalf("failed to derive: %v", err)
+	}
+	for _, iterations := range []int{0, -1, portableMaxIterations + 1} {
+		damaged := *p
+		damaged.Iterations = iterations
+		if damaged.matches("parola") {
+			t.Errorf("matched with %d iterations", iterations)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"sync"
+	"time"
+)
+
+// Captured images arrive encoded for speed, not size. Once stored, each is re-encoded
+// with BestCompression on a background goroutine and swapped in if smaller. Image hashes
+// cover the decoded pixels rather than the PNG bytes (see hash.go), so the swap changes
+// nothing for duplicate detection or the integrity check.
+
+// bestEncoder re-encodes stored images
+var bestEncoder = png.Encoder{CompressionLevel: png.BestCompression}
+
+// recompressQueue re-encodes images one at a time on its own goroutine
+type recompressQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []string
+	running bool
+	closed  bool          // Stopped by close and isn't started again
+	done    chan struct{} // Closed when run returns
+}
+
+// push queues an item and starts the worker on first use; safe with db.mu held
+func (q *recompressQueue) push(db *Database, id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	if !q.running {
+		q.cond = sync.NewCond(&q.mu)
+		q.running = true
+		q.done = make(chan struct{})
+		go q.run(db)
+	}
+	q.pending = append(q.pending, id)
+	q.cond.Signal()
+}
+
+// close drops queued items and waits up to timeout for the current one
+// Must not be called with db.mu held, since the worker takes it
+func (q *recompressQueue) close(timeout time.Duration) error {
+	q.mu.Lock()
+	q.closed = true
+	if !q.running {
+		q.mu.Unlock()
+		return nil
+	}
+	q.running = false
+	q.pending = nil
+	q.cond.Signal()
+	done := q.done
+	q.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("image recompression did not stop within %v", timeout)
+	}
+}
+
+// stopped reports whether close was called
+func (q *recompressQueue) stopped() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+func (q *recompressQueue) run(db *Database) {
+	defer close(q.done)
+
+	q.mu.Lock()
+	for {
+		for len(q.pending) == 0 && q.running {
+			q.cond.Wait()
+		}
+		if !q.running {
+			q.mu.Unlock()
+			return
+		}
+		id := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+
+		// A failure leaves the fast encoding in place, which is still a valid image
+		_ = db.recompressItem(id, q.stopped)
+
+		q.mu.Lock()
+	}
+}
+
+// recompressItem re-encodes an image with BestCompression and swaps it in if smaller
+// The slow part runs without the lock; stopped lets Close skip the swap
+func (db *Database) recompressItem(id string, stopped func() bool) error {
+	db.mu.RLock()
+	key := db.key // Replaced by Rekey
+	var prev string
+	found := false
+	for _, item := range db.Items {
+		if item.ID == id && item.Type == "image" && item.Delta == nil {
+			prev, found = item.Content, true
+			break
+		}
+	}
+	db.mu.RUnlock()
+	if !found {
+		return nil
+	}
+
+	content, err := Decrypt(prev, key)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt item: %w", err)
+	}
+	defer Zero(content)
+	img, err := decodePNG(content)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := bestEncoder.Encode(&buf, img); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	if buf.Len() >= len(content) || stopped() {
+		return nil
+	}
+	return db.updateItem(id, prev, buf.Bytes(), hashContent("image", nil, img))
+}
+
+// updateItem replaces an image's content with another encoding of the same pixels
+// prev is the ciphertext the new content was made from; if the item changed since,
+// nothing is replaced. hash is the canonical hash, which also upgrades older items
+func (db *Database) updateItem(id, prev string, content []byte, hash string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i := range db.Items {
+		if db.Items[i].ID != id {
+			continue
+		}
+		if db.Items[i].Content != prev {
+			return nil
+		}
+		encrypted, err := Encrypt(content, db.key)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt content: %w", err)
+		}
+		db.Items[i].Content = encrypted
+		db.Items[i].Size = len(content)
+		db.Items[i].Hash = hash
+		db.Items[i].HashVersion = HashVersion
+		db.commit(ChangeUpdate, id)
+		return db.scheduleSave()
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The data key is wrapped with the hardware key, which is derived from the machine ID.
+// When that ID changes (new motherboard, reinstalled Windows) the history no longer
+// opens. The recovery key is the data key written out as text for the user to keep;
+// entering it on the changed machine reads the history with it and re-encrypts
+// everything under a new data key wrapped with the new hardware key.
+//
+// The key is only handed out once the history has its own data key (see Rekey), so
+// the hardware key itself is never shown. A later re-key replaces the data key, and
+// with it the recovery key.
+
+var (
+	// ErrKeyMismatch is returned when the history doesn't decrypt with the key in use,
+	// usually because it was written on another machine (or the file is damaged)
+	ErrKeyMismatch = errors.New("history is encrypted with another key")
+	// ErrInvalidRecoveryKey is returned for text that isn't a recovery key at all
+	ErrInvalidRecoveryKey = errors.New("invalid recovery key")
+	// ErrNoDataKey is returned by RecoveryKey while the history is still encrypted with
+	// the hardware key; it needs a re-key first
+	ErrNoDataKey = errors.New("history has no data key yet")
+)
+
+// recoveryKeyGroup is how many characters are shown together in a recovery key
+const recoveryKeyGroup = 4
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RecoveryPrompt asks for a recovery key after the history failed to open; cause is
+// why, including a wrong key entered before. ok false gives up and leaves the history
+// unloaded, so it can still be recovered later with Recover
+type RecoveryPrompt func(cause error) (recoveryKey string, ok bool)
+
+var (
+	recoveryMu     sync.Mutex
+	recoveryPrompt RecoveryPrompt
+)
+
+// SetRecoveryPrompt sets the prompt NewDatabase uses when the history doesn't open with
+// this machine's key; nil turns it off
+func SetRecoveryPrompt(prompt RecoveryPrompt) {
+	recoveryMu.Lock()
+	defer recoveryMu.Unlock()
+	recoveryPrompt = prompt
+}
+
+// FormatRecoveryKey writes key as dash-separated groups of base32 characters
+func FormatRecoveryKey(key []byte) string {
+	encoded := recoveryEncoding.EncodeToString(key)
+	groups := make([]string, 0, len(encoded)/recoveryKeyGroup+1)
+	for len(encoded) > recoveryKeyGroup {
+		groups = append(groups, encoded[:recoveryKeyGroup])
+		encoded = encoded[recoveryKeyGroup:]
+	}
+	return strings.Join(append(groups, encoded), "-")
+}
+
+// ParseRecoveryKey reads a key written by FormatRecoveryKey; case, dashes and spaces
+// don't matter
+func ParseRecoveryKey(text string) ([]byte, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(text))
+	key, err := recoveryEncoding.DecodeString(cleaned)
+	if err != nil || len(key) != 32 {
+		return nil, ErrInvalidRecoveryKey
+	}
+	return key, nil
+}
+
+// RecoveryKey returns the data key as a recovery key, ErrNoDataKey if the history is
+// still encrypted with the hardware key
+func (db *Database) RecoveryKey() (string, error) {
+	keyPath, err := GetKeyPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(keyPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNoDataKey
+		}
+		return "", fmt.Errorf("failed to read data key: %w", err)
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.loadErr != nil {
+		return "", fmt.Errorf("database not loaded: %w", db.loadErr)
+	}
+	return FormatRecoveryKey(db.key), nil
+}
+
+// Recover opens a history that failed to load with a recovery key and moves it to a
+// new data key, wrapped with this machine's key
+func (db *Database) Recover(recoveryKey string) error {
+	oldKey, err := ParseRecoveryKey(recoveryKey)
+	if err != nil {
+		return err
+	}
+	defer Zero(oldKey)
+
+	newKey := make([]byte, 32)
+	if _, err := rand.Read(newKey); err != nil {
+		return fmt.Errorf("failed to generate data key: %w", err)
+	}
+	defer Zero(newKey)
+	return db.ReEncrypt(oldKey, newKey)
+}
+
+// ReEncrypt decrypts every item, the database file, the archive, the audit log, the
+// drafts and the restore points with oldKey and writes them back under newKey, which is stored wrapped
+// with the hardware key and used from then on
+// A history that failed to load is loaded with oldKey first; captures kept meanwhile
+// are carried over. A loaded history must be the one oldKey opens. The move itself is
+// the interruptible re-key described in rekey.go; the callers' keys are copied
+func (db *Database) ReEncrypt(oldKey, newKey []byte) error {
+	if len(oldKey) != 32 || len(newKey) != 32 {
+		return fmt.Errorf("keys must be 32 bytes")
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.loadErr == nil {
+		if !bytes.Equal(oldKey, db.key) {
+			return ErrKeyMismatch
+		}
+	} else if err := db.loadWithKey(oldKey); err != nil {
+		return err
+	}
+
+	keyPath, err := GetKeyPath()
+	if err != nil {
+		return err
+	}
+	if err := writeWrappedKey(keyPath+pendingKeySuffix, newKey, db.password); err != nil {
+		return err
+	}
+	if db.pendingKey != nil {
+		Zero(db.pendingKey)
+	}
+	db.pendingKey = newLockedKey(bytes.Clone(newKey))
+	if err := db.rekeyInternal(nil); err != nil {
+		return err
+	}
+
+	db.commitAll()
+	return nil
+}
+
+// loadWithKey retries a failed load with key as the data key and keeps it if the
+// history opens; on failure everything stays as it was (caller must hold lock)
+func (db *Database) loadWithKey(key []byte) error {
+	prevKey, prevItems, prevErr := db.key, db.Items, db.loadErr
+	db.key = newLockedKey(bytes.Clone(key))
+	db.loadErr = nil
+	if err := db.loadInternal(); err != nil && !os.IsNotExist(err) {
+		Zero(db.key)
+		db.key, db.Items, db.loadErr = prevKey, prevItems, prevErr
+		return err
+	}
+
+	// Captures made while the file was unreadable were encrypted with the previous key
+	present := make(map[string]bool, len(db.Items))
+	for _, item := range db.Items {
+		present[item.ID] = true
+	}
+	for _, item := range prevItems {
+		if !present[item.ID] {
+			reencryptItem(&item, [][]byte{prevKey}, db.key)
+			db.Items = append(db.Items, item)
+		}
+	}
+	sort.SliceStable(db.Items, func(i, j int) bool {
+		return db.Items[i].Timestamp.After(db.Items[j].Timestamp)
+	})
+
+	if db.journal != nil {
+		db.journal.key = db.key
+	}
+	if db.archive != nil {
+		db.archive.setKey(db.key)
+	}
+	if db.audit != nil {
+		db.audit.setKey(db.key)
+	}
+	Zero(prevKey)
+	return nil
+}
+
+// promptRecovery asks for a recovery key until the history opens or the prompt gives
+// up (caller must not hold lock)
+func (db *Database) promptRecovery() {
+	recoveryMu.Lock()
+	prompt := recoveryPrompt
+	recoveryMu.Unlock()
+	if prompt == nil {
+		return
+	}
+
+	cause := db.LoadError()
+	for {
+		input, ok := prompt(cause)
+		if !ok {
+			return
+		}
+		err := db.Recover(input)
+		if err == nil || !(errors.Is(err, ErrKeyMismatch) || errors.Is(err, ErrInvalidRecoveryKey)) {
+			return
+		}
+		cause = err
+	}
+}
+
+// setKey switches the key the archive is read and written with
+func (a *Archive) setKey(key []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.key = key
+}
+
+// setKey switches the key the audit log is read and written with
+func (l *AuditLog) setKey(key []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.key = key
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// moveToOtherMachine closes db and rewraps its data key as another machine would have
+// written it, so the history no longer opens here; returns the recovery key
+func moveToOtherMachine(t *testing.T, db *Database) string {
+	t.Helper()
+	if err := db.Rekey(nil); err != nil {
+		t.Fatalf("failed to re-key: %v", err)
+	}
+	recoveryKey, err := db.RecoveryKey()
+	if err != nil {
+		t.Fatalf("failed to get recovery key: %v", err)
+	}
+	otherHW := make([]byte, 32)
+	rand.Read(otherHW)
+	wrapped, err := wrapKey(db.key, otherHW, nil)
+	if err != nil {
+		t.Fatalf("failed to wrap: %v", err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	db.Close()
+
+	keyPath, err := GetKeyPath()
+	if err != nil {
+		t.Fatalf("failed to locate key: %v", err)
+	}
+	if err := os.WriteFile(keyPath, wrapped, 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return recoveryKey
+}
+
+func TestRecoveryKeyFormat(t *testing.T) {
+	key := bytes.Repeat([]byte{0x5a, 0xc3}, 16)
+	text := FormatRecoveryKey(key)
+	for _, input := range []string{text, " " + text + "\n", text[:4] + " " + text[5:]} {
+		got, err := ParseRecoveryKey(input)
+		if err != nil || !bytes.Equal(got, key) {
+			t.Errorf("ParseRecoveryKey(%q) = %x, %v", input, got, err)
+		}
+	}
+	if got, err := ParseRecoveryKey(strings.ToLower(text)); err != nil || !bytes.Equal(got, key) {
+		t.Errorf("lower case key gave %x, %v", got, err)
+	}
+	for _, bad := range []string{"", "ABCD-EFGH", text + "-AAAA", "1111-" + text[5:]} {
+		if _, err := ParseRecoveryKey(bad); !errors.Is(err, ErrInvalidRecoveryKey) {
+			t.Errorf("ParseRecoveryKey(%q) gave %v", bad, err)
+		}
+	}
+}
+
+// No recovery key is handed out while the history is on the hardware key
+func TestRecoveryKeyNeedsDataKey(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := db.RecoveryKey(); !errors.Is(err, ErrNoDataKey) {
+		t.Errorf("RecoveryKey on the hardware key gave %v", err)
+	}
+	if err := db.Rekey(nil); err != nil {
+		t.Fatalf("failed to re-key: %v", err)
+	}
+	text, err := db.RecoveryKey()
+	if err != nil {
+		t.Fatalf("failed to get recovery key: %v", err)
+	}
+	if key, err := ParseRecoveryKey(text); err != nil || !bytes.Equal(key, db.key) {
+		t.Error("the recovery key isn't the data key")
+	}
+}
+
+// TestRecover opens a history after a machine change: wrong keys leave everything as it
+// was, a capture made meanwhile never touches the unreadable file and survives, and the
+// right key moves it all to a new data key this machine opens
+func TestRecover(t *testing.T) {
+	db := newTestDB(t)
+	rekeyFixture(t, db)
+	recoveryKey := moveToOtherMachine(t, db)
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		t.Fatalf("failed to locate database: %v", err)
+	}
+	before, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("failed to read database: %v", err)
+	}
+
+	moved, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer moved.Close()
+	if !errors.Is(moved.LoadError(), ErrKeyMismatch) {
+		t.Fatalf("load error is %v, want ErrKeyMismatch", moved.LoadError())
+	}
+	var rejected *RejectError
+	if err := moved.AddItem("text", []byte("üç")); !errors.As(err, &rejected) || rejected.Reason != RejectIO {
+		t.Errorf("capture before recovery gave %v", err)
+	}
+	moved.Flush()
+	if after, _ := os.ReadFile(dbPath); !bytes.Equal(after, before) {
+		t.Fatal("the unreadable file was overwritten before recovery")
+	}
+
+	other := make([]byte, 32)
+	rand.Read(other)
+	if err := moved.Recover(FormatRecoveryKey(other)); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("a wrong key gave %v", err)
+	}
+	if err := moved.Recover("yanlış"); !errors.Is(err, ErrInvalidRecoveryKey) {
+		t.Errorf("text that isn't a key gave %v", err)
+	}
+	if !errors.Is(moved.LoadError(), ErrKeyMismatch) || len(moved.GetAllItems()) != 1 {
+		t.Fatal("a failed recovery changed the database")
+	}
+
+	if err := moved.Recover(recoveryKey); err != nil {
+		t.Fatalf("failed to recover: %v", err)
+	}
+	if moved.LoadError() != nil {
+		t.Errorf("load error after recovery: %v", moved.LoadError())
+	}
+	if got, _ := moved.RecoveryKey(); got == recoveryKey {
+		t.Error("the recovery key is still the old data key")
+	}
+	if got := historyTexts(t, moved); !slices.Equal(got, []string{"üç", "iki", "bir"}) {
+		t.Errorf("recovered history is %q", got)
+	}
+	moved.Close()
+
+	reopened, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+	if err := reopened.LoadError(); err != nil {
+		t.Fatalf("failed to load after recovery: %v", err)
+	}
+	if err := reopened.ReEncrypt(other, other); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("re-encrypting a loaded history from another key gave %v", err)
+	}
+	if err := reopened.DeleteItem(reopened.GetAllItems()[0].ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	checkReadable(t, reopened)
+}
+
+// The prompt is asked again after a wrong key and NewDatabase returns the history open
+func TestRecoveryPrompt(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "bir")
+	recoveryKey := moveToOtherMachine(t, db)
+
+	var causes []error
+	SetRecoveryPrompt(func(cause error) (string, bool) {
+		causes = append(causes, cause)
+		if len(causes) == 1 {
+			return "yanlış", true
+		}
+		return recoveryKey, true
+	})
+	t.Cleanup(func() { SetRecoveryPrompt(nil) })
+
+	reopened, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer reopened.Close()
+	if len(causes) != 2 || !errors.Is(causes[0], ErrKeyMismatch) || !errors.Is(causes[1], ErrInvalidRecoveryKey) {
+		t.Errorf("prompt was asked with %v", causes)
+	}
+	if err := reopened.LoadError(); err != nil {
+		t.Fatalf("history still unloaded: %v", err)
+	}
+	if got := historyTexts(t, reopened); !slices.Equal(got, []string{"bir"}) {
+		t.Errorf("history is %q", got)
+	}
+
+	// Giving up leaves the history unloaded for a later try
+	SetRecoveryPrompt(func(error) (string, bool) { return "", false })
+	moveToOtherMachine(t, reopened)
+	again, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer again.Close()
+	if !errors.Is(again.LoadError(), ErrKeyMismatch) {
+		t.Errorf("load error after giving up is %v", again.LoadError())
+	}
+}
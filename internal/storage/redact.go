@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Redaction masks matches of configured patterns in captured text before the item is
+// stored, so the original never reaches the database, the journal or the archive.
+//
+// Rules are matched against the captured text, not against each other's output, so a
+// replacement can't be re-matched by a later rule. Where matches of different rules
+// overlap, the earlier rule wins and the overlapping later match is dropped entirely.
+// Replacements may refer to groups with $1 or ${name}.
+
+// redactTimeBudget bounds the time spent on all rules for one capture
+// Go regexps run in linear time, so this only trips on very large texts or many rules
+const redactTimeBudget = 200 * time.Millisecond
+
+// ErrRedactionTimeout is returned when redaction ran out of time; the capture is dropped
+// rather than stored unmasked
+var ErrRedactionTimeout = errors.New("redaction timed out")
+
+// RedactionRule replaces matches of Pattern with Replacement
+type RedactionRule struct {
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+}
+
+// Redactor applies compiled redaction rules in order
+type Redactor struct {
+	rules  []*regexp.Regexp
+	repls  []string
+	budget time.Duration
+	now    func() time.Time // Clock the budget is measured on; the database's clock once set
+}
+
+// NewRedactor compiles rules; an invalid pattern is reported with its position
+func NewRedactor(rules []RedactionRule) (*Redactor, error) {
+	r := &Redactor{budget: redactTimeBudget, now: time.Now}
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %d: %w", i+1, err)
+		}
+		r.rules = append(r.rules, re)
+		r.repls = append(r.repls, rule.Replacement)
+	}
+	return r, nil
+}
+
+// Len returns the number of active rules
+func (r *Redactor) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.rules)
+}
+
+// redactMatch is one accepted match with its expanded replacement
+type redactMatch struct {
+	start, end int
+	repl       []byte
+}
+
+// Redact returns text with every rule applied and whether anything was replaced
+func (r *Redactor) Redact(text string) (string, bool, error) {
+	if r.Len() == 0 {
+		return text, false, nil
+	}
+	deadline := r.now().Add(r.budget)
+
+	accepted := make([]redactMatch, 0)
+	overlaps := func(start, end int) bool {
+		for _, m := range accepted {
+			if start < m.end && m.start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, re := range r.rules {
+		for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+			// Empty matches would insert the replacement between every character
+			if loc[0] == loc[1] || overlaps(loc[0], loc[1]) {
+				continue
+			}
+			repl := re.ExpandString(nil, r.repls[i], text, loc)
+			accepted = append(accepted, redactMatch{start: loc[0], end: loc[1], repl: repl})
+		}
+		if r.now().After(deadline) {
+			return "", false, ErrRedactionTimeout
+		}
+	}
+	if len(accepted) == 0 {
+		return text, false, nil
+	}
+
+	sort.Slice(accepted, func(i, j int) bool {
+		return accepted[i].start < accepted[j].start
+	})
+	var sb strings.Builder
+	sb.Grow(len(text))
+	last := 0
+	for _, m := range accepted {
+		sb.WriteString(text[last:m.start])
+		sb.Write(m.repl)
+		last = m.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), true, nil
+}
+
+// ParseRedactionRules reads one rule per line as "pattern => replacement"
+// Blank lines and lines starting with '#' are skipped; a line without "=>" masks with "████"
+func ParseRedactionRules(text string) []RedactionRule {
+	rules := make([]RedactionRule, 0)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, repl, ok := strings.Cut(line, "=>")
+		if !ok {
+			repl = "████"
+		}
+		rules = append(rules, RedactionRule{
+			Pattern:     strings.TrimSpace(pattern),
+			Replacement: strings.TrimSpace(repl),
+		})
+	}
+	return rules
+}
+
+// FormatRedactionRules writes rules in the format read by ParseRedactionRules
+func FormatRedactionRules(rules []RedactionRule) string {
+	lines := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		lines = append(lines, rule.Pattern+" => "+rule.Replacement)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,74 @@
+package storage
+
+import "fmt"
+
+// RejectReason classifies why a capture was not stored
+type RejectReason int
+
+const (
+	RejectTooLarge  RejectReason = iota // Content is over MaxItemSize
+	RejectLimitFull                     // Unpinned items are at the limit
+	RejectRedaction                     // Redaction failed; the capture is dropped rather than stored unmasked
+	RejectCrypto                        // Encryption failed
+	RejectIO                            // The database isn't loaded or couldn't be written
+)
+
+// RejectReasons lists every reason, so callers can check they handle all of them
+var RejectReasons = []RejectReason{RejectTooLarge, RejectLimitFull, RejectRedaction, RejectCrypto, RejectIO}
+
+// String returns a short name for logs and bug reports
+func (r RejectReason) String() string {
+	switch r {
+	case RejectTooLarge:
+		return "too_large"
+	case RejectLimitFull:
+		return "limit_full"
+	case RejectRedaction:
+		return "redaction"
+	case RejectCrypto:
+		return "crypto"
+	case RejectIO:
+		return "io"
+	default:
+		return fmt.Sprintf("reason_%d", int(r))
+	}
+}
+
+// RejectError is returned by the add functions when a capture was not stored
+// A save failure is reported as RejectIO even though the item stays in memory,
+// since it is lost if Pano exits before the next successful save
+type RejectError struct {
+	Reason RejectReason
+	Size   int   // Content size in bytes, for RejectTooLarge
+	Limit  int   // MaxItemSize for RejectTooLarge, the item limit for RejectLimitFull
+	Err    error // Underlying error, if any
+}
+
+// Error describes the rejection
+func (e *RejectError) Error() string {
+	switch e.Reason {
+	case RejectTooLarge:
+		return fmt.Sprintf("item size (%d bytes) exceeds maximum (%d bytes)", e.Size, e.Limit)
+	case RejectLimitFull:
+		return fmt.Sprintf("item limit (%d) reached", e.Limit)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("capture rejected (%s): %v", e.Reason, e.Err)
+	}
+	return fmt.Sprintf("capture rejected (%s)", e.Reason)
+}
+
+// Unwrap returns the underlying error
+func (e *RejectError) Unwrap() error {
+	return e.Err
+}
+
+// LimitWarning is returned when the item was stored but few slots remain
+type LimitWarning struct {
+	Remaining int
+}
+
+// Error describes the warning
+func (w *LimitWarning) Error() string {
+	return fmt.Sprintf("%d item slots remaining", w.Remaining)
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Every reason has its own name; one added without a name still prints
+func TestRejectReasonString(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, reason := range RejectReasons {
+		name := reason.String()
+		if strings.HasPrefix(name, "reason_") || seen[name] {
+			t.Errorf("reason %d has name %q", int(reason), name)
+		}
+		seen[name] = true
+	}
+	if got := RejectReason(99).String(); got != "reason_99" {
+		t.Errorf("unknown reason prints as %q", got)
+	}
+}
+
+// TestRejectErrors checks the typed results of captures that aren't stored or that
+// fill the last slots, and that a rejected capture leaves the history alone
+func TestRejectErrors(t *testing.T) {
+	db := newTestDB(t)
+	db.SetGraceWindow(0)
+	db.SetMaxItems(12)
+	db.SetLimitPolicy(LimitReject)
+
+	var reject *RejectError
+	err := db.AddItem("text", make([]byte, MaxItemSize+1))
+	if !errors.As(err, &reject) || reject.Reason != RejectTooLarge {
+		t.Fatalf("an oversized capture gave %v, want RejectTooLarge", err)
+	}
+	if reject.Size != MaxItemSize+1 || reject.Limit != MaxItemSize {
+		t.Errorf("size %d and limit %d reported", reject.Size, reject.Limit)
+	}
+	if len(db.GetAllItems()) != 0 {
+		t.Fatal("the oversized capture was stored")
+	}
+
+	// With 12 slots the warnings start at the second capture: 10 left after it
+	var warning *LimitWarning
+	if err := db.AddItem("text", []byte("1")); err != nil {
+		t.Fatalf("the first capture gave %v", err)
+	}
+	for i, remaining := range []int{10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0} {
+		err := db.AddItem("text", []byte(fmt.Sprintf("öğe %d", i+2)))
+		if !errors.As(err, &warning) || warning.Remaining != remaining {
+			t.Fatalf("capture %d gave %v, want a warning with %d left", i+2, err, remaining)
+		}
+	}
+
+	err = db.AddItem("text", []byte("fazla"))
+	if !errors.As(err, &reject) || reject.Reason != RejectLimitFull || reject.Limit != 12 {
+		t.Fatalf("a capture into the full history gave %v, want RejectLimitFull at 12", err)
+	}
+	if got := len(db.GetAllItems()); got != 12 {
+		t.Errorf("%d items after the rejected capture, want 12", got)
+	}
+	if err.Error() != "item limit (12) reached" {
+		t.Errorf("limit error reads %q", err)
+	}
+}
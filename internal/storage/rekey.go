@@ -0,0 +1,486 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Items used to be encrypted with the hardware key itself. Rekey moves them to a random
+// data key, kept in KeyFile wrapped (encrypted) with the hardware key, and replaces that
+// data key on every later run, so a key that may have leaked stops opening the history.
+//
+// A re-key first writes the new key wrapped to KeyFile+".next"; while that file exists
+// any file may be encrypted with either key. It then takes a restore point, rewrites the
+// restore points, the archive, the audit log, the drafts and the database with the new
+// key (each file replaced atomically), empties the journal and finally renames the
+// pending key over KeyFile. If it is interrupted, the next start reads every file with
+// whichever key opens it and finishes the run before the database is used.
+//
+// Fields from newer builds (see rawField) are kept as they are; if they hold ciphertext,
+// it stays under the old key.
+
+const (
+	// KeyFile holds the data key, wrapped with the hardware key (and the master password,
+	// see password.go), once the history was re-keyed
+	KeyFile = "clipboard.key"
+
+	pendingKeySuffix = ".next"
+	gcmNonceSize     = 12 // Nonce length of EncryptBytes
+)
+
+// GetKeyPath returns the full path to the wrapped data key
+func GetKeyPath() (string, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), KeyFile), nil
+}
+
+// loadDataKey returns the key the history is encrypted with and, after an interrupted
+// re-key, the key it was moving to (nil otherwise)
+// Without KeyFile the history was never re-keyed and the hardware key is the data key;
+// a KeyFile the hardware key doesn't open yields ErrKeyMismatch (see recovery.go). Keys
+// wrapped with a master password need mp (see password.go)
+func loadDataKey(hwKey []byte, mp *masterPassword) (key, pending []byte, err error) {
+	path, err := GetKeyPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if key, err = unwrapKey(data, hwKey, mp); err != nil {
+			if errors.Is(err, ErrPasswordRequired) {
+				return nil, nil, err
+			}
+			return nil, nil, fmt.Errorf("failed to unwrap data key: %w (%w)", ErrKeyMismatch, err)
+		}
+	case os.IsNotExist(err):
+		key = make([]byte, len(hwKey))
+		copy(key, hwKey)
+	default:
+		return nil, nil, fmt.Errorf("failed to read data key: %w", err)
+	}
+
+	// The pending key is written atomically; one that doesn't unwrap was never used
+	if data, err := os.ReadFile(path + pendingKeySuffix); err == nil {
+		if pending, err = unwrapKey(data, hwKey, mp); err != nil {
+			pending = nil
+		}
+	}
+	return key, pending, nil
+}
+
+// writeWrappedKey wraps key with the hardware key, and with mp if set, into path,
+// replacing it atomically
+func writeWrappedKey(path string, key []byte, mp *masterPassword) error {
+	hwKey, err := GetHardwareKey()
+	if err != nil {
+		return fmt.Errorf("failed to get hardware key: %w", err)
+	}
+	defer Zero(hwKey)
+
+	wrapped, err := wrapKey(key, hwKey, mp)
+	if err != nil {
+		return fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return writeFileAtomic(path, wrapped)
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// Rekey re-encrypts the history with a newly generated data key
+// progress, if set, is called with the steps done and the total (one per item, restore
+// point, the archive and the audit log); it runs with the database locked and must not
+// use it. An earlier interrupted run is finished instead of starting a new one
+func (db *Database) Rekey(progress func(done, total int)) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.loadErr != nil {
+		return fmt.Errorf("database not loaded: %w", db.loadErr)
+	}
+	if err := db.beginRekey(); err != nil {
+		return err
+	}
+	if err := db.rekeyInternal(progress); err != nil {
+		return err
+	}
+	db.commitAll()
+	return nil
+}
+
+// beginRekey writes a new pending key unless one is left over (caller must hold lock)
+func (db *Database) beginRekey() error {
+	if db.pendingKey != nil {
+		return nil
+	}
+	keyPath, err := GetKeyPath()
+	if err != nil {
+		return err
+	}
+	next := make([]byte, 32)
+	if _, err := rand.Read(next); err != nil {
+		return fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if err := writeWrappedKey(keyPath+pendingKeySuffix, next, db.password); err != nil {
+		Zero(next)
+		return err
+	}
+	db.pendingKey = newLockedKey(next)
+	return nil
+}
+
+// commitAll tells listeners every item changed, as after a re-key where every
+// ciphertext did (caller must hold lock)
+func (db *Database) commitAll() {
+	ids := make([]string, 0, len(db.Items))
+	for _, item := range db.Items {
+		ids = append(ids, item.ID)
+	}
+	db.commit(ChangeReload, ids...)
+}
+
+// rekeyInternal moves every file to db.pendingKey, see the comment at the top
+// (caller must hold lock)
+// If it fails before the data key is replaced, the files already moved are moved back
+// so they, the archive and the audit log agree with db.key again. Should that fail too,
+// or the database was already written, the pending key still opens them on the next start
+func (db *Database) rekeyInternal(progress func(done, total int)) (err error) {
+	keyPath, err := GetKeyPath()
+	if err != nil {
+		return err
+	}
+	from, to := db.key, db.pendingKey
+	keys := [][]byte{from, to}
+
+	var undo []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			_ = undo[i]()
+		}
+	}()
+
+	// The restore point is rewritten with the others below, so it opens afterwards too
+	if len(db.Items) > 0 {
+		if err := db.writeRestorePoint(SafetyRekey); err != nil {
+			return fmt.Errorf("failed to create restore point: %w", err)
+		}
+	}
+	safetyDir, err := GetSafetyDir()
+	if err != nil {
+		return err
+	}
+	points, err := listRestorePoints(safetyDir)
+	if err != nil {
+		return err
+	}
+
+	done, total := 0, len(points)+len(db.Items)+2
+	step := func() {
+		done++
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+
+	for _, point := range points {
+		if err := rekeyDatabaseFile(point.Path, keys, to); err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return rekeyDatabaseFile(point.Path, keys, from) })
+		step()
+	}
+	if db.archive != nil {
+		if err := db.archive.rekey(keys, to); err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return db.archive.rekey(keys, from) })
+	}
+	step()
+	if db.audit != nil {
+		if err := db.audit.rekey(keys, to); err != nil {
+			return err
+		}
+		undo = append(undo, func() error { return db.audit.rekey(keys, from) })
+	}
+	if err := rekeyDrafts(keys, to); err != nil {
+		return err
+	}
+	undo = append(undo, func() error { return rekeyDrafts(keys, from) })
+	step()
+
+	items := make([]ClipboardItem, len(db.Items))
+	for i, item := range db.Items {
+		reencryptItem(&item, keys, to)
+		items[i] = item
+		step()
+	}
+	if db.sqlite != nil {
+		// One transaction; the store opens with either key until the rename below
+		if err := db.sqlite.save(items, to); err != nil {
+			return err
+		}
+	} else {
+		dbPath, err := GetDatabasePath()
+		if err != nil {
+			return err
+		}
+		encoded, err := encodeDatabaseFile(items, to)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(dbPath, encoded); err != nil {
+			return err
+		}
+	}
+	// Until the rename the pending key keeps the new file readable
+	if err := os.Rename(keyPath+pendingKeySuffix, keyPath); err != nil {
+		return fmt.Errorf("failed to replace data key: %w", err)
+	}
+
+	db.Items = items
+	db.dirty = false
+	db.stopSaveTimer()
+	db.key, db.pendingKey = to, nil
+	if db.journal != nil {
+		db.journal.key = to
+	}
+	// Everything journaled is in the file; records left under the old key no longer
+	// decrypt and are skipped by the next replay
+	_ = db.journal.reset()
+	Zero(from)
+
+	db.writeAudit(AuditEntry{Op: AuditRekey, Count: len(items)})
+	return nil
+}
+
+// resumeRekey finishes a re-key interrupted in an earlier run (caller must hold lock)
+func (db *Database) resumeRekey() error {
+	if db.pendingKey == nil || db.loadErr != nil {
+		return nil
+	}
+	return db.rekeyInternal(nil)
+}
+
+// rekey rewrites the archive under to and switches to it
+// Lines no key opens are kept as they are; reading skips them either way
+func (a *Archive) rekey(keys [][]byte, to []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	lines, err := readLines(a.path, archiveMaxLine)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	// encodeItem encrypts with a.key
+	from := a.key
+	a.key = to
+	for i, line := range lines {
+		for _, key := range keys {
+			decrypted, err := Decrypt(line, key)
+			if err != nil {
+				continue
+			}
+			var item ClipboardItem
+			err = json.Unmarshal(decrypted, &item)
+			Zero(decrypted)
+			if err != nil {
+				break
+			}
+			reencryptItem(&item, keys, to)
+			if lines[i], err = a.encodeItem(item); err != nil {
+				a.key = from
+				return err
+			}
+			break
+		}
+	}
+	if err := writeLines(a.path, lines); err != nil {
+		a.key = from
+		return err
+	}
+	a.count = len(lines)
+	return nil
+}
+
+// rekey rewrites the audit log under to and switches to it
+func (l *AuditLog) rekey(keys [][]byte, to []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines, err := l.readLinesInternal()
+	if err != nil {
+		return err
+	}
+	for i, line := range lines {
+		lines[i] = reencrypt(line, keys, to)
+	}
+	if err := writeLines(l.path, lines); err != nil {
+		return err
+	}
+	l.key = to
+	return nil
+}
+
+// readLines returns the non-empty lines of a line-based file, nil if it doesn't exist
+func readLines(path string, maxLine int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// writeLines replaces a line-based file atomically; nothing is written for a file
+// that never existed
+func writeLines(path string, lines []string) error {
+	if lines == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// rekeyDatabaseFile rewrites a database file (e.g. a restore point) under to
+func rekeyDatabaseFile(path string, keys [][]byte, to []byte) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	items, err := decodeWithKeys(data, keys)
+	if err != nil {
+		return nil // Already unreadable; no reason to hold up the others
+	}
+	for i := range items {
+		reencryptItem(&items[i], keys, to)
+	}
+	encoded, err := encodeDatabaseFile(items, to)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, encoded)
+}
+
+// decodeWithKeys decodes a database file with the first of keys that opens it
+func decodeWithKeys(data []byte, keys [][]byte) ([]ClipboardItem, error) {
+	var firstErr error
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		items, err := decodeDatabaseFile(data, key)
+		if err == nil {
+			return items, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// reencryptItem moves the encrypted fields of item to the key to
+func reencryptItem(item *ClipboardItem, keys [][]byte, to []byte) {
+	item.Content = reencrypt(item.Content, keys, to)
+	item.Original = reencrypt(item.Original, keys, to)
+	item.SourceURL = reencrypt(item.SourceURL, keys, to)
+	item.SourceApp = reencrypt(item.SourceApp, keys, to)
+	item.RTF = reencrypt(item.RTF, keys, to)
+	item.TitleCache = reencrypt(item.TitleCache, keys, to)
+	item.Title = reencrypt(item.Title, keys, to)
+	item.Preview = reencrypt(item.Preview, keys, to)
+}
+
+// reencrypt decrypts ciphertext with the first of keys that opens it and encrypts it
+// with to; ciphertext that no key opens (already damaged) is returned unchanged
+func reencrypt(ciphertext string, keys [][]byte, to []byte) string {
+	if ciphertext == "" {
+		return ""
+	}
+	for _, key := range keys {
+		plaintext, err := Decrypt(ciphertext, key)
+		if err != nil {
+			continue
+		}
+		encrypted, err := Encrypt(plaintext, to)
+		Zero(plaintext)
+		if err != nil {
+			return ciphertext
+		}
+		return encrypted
+	}
+	return ciphertext
+}
+
+// NonceReport is the result of scanning stored ciphertexts for repeated nonces
+type NonceReport struct {
+	Scanned    int // Encrypted fields looked at
+	Duplicates int // Fields whose nonce was already seen; anything but 0 points at a broken RNG
+}
+
+// NonceReport scans the encrypted fields of all items for nonces used more than once
+// AES-GCM loses its guarantees when a nonce repeats under one key
+func (db *Database) NonceReport() NonceReport {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var report NonceReport
+	seen := make(map[string]bool)
+	for _, item := range db.Items {
+		for _, field := range []string{item.Content, item.Original, item.SourceURL, item.SourceApp, item.RTF, item.TitleCache, item.Title, item.Preview} {
+			data, err := base64.StdEncoding.DecodeString(field)
+			if err != nil || len(data) < gcmNonceSize {
+				continue
+			}
+			report.Scanned++
+			nonce := string(data[:gcmNonceSize])
+			if seen[nonce] {
+				report.Duplicates++
+			}
+			seen[nonce] = true
+		}
+	}
+	return report
+}
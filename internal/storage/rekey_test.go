@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+// rekeyFixture fills db with "bir" and "iki", archives copies of both, logs an
+// operation and saves a draft
+func rekeyFixture(t *testing.T, db *Database) {
+	t.Helper()
+	for _, text := range []string{"bir", "iki"} {
+		if err := db.AddItem("text", []byte(text)); err != nil {
+			t.Fatalf("failed to add: %v", err)
+		}
+	}
+	if err := db.Archive().Append(db.GetAllItems()); err != nil {
+		t.Fatalf("failed to archive: %v", err)
+	}
+	db.RecordExportDenied()
+	if err := db.SaveDraft("not", "yarım kalan not"); err != nil {
+		t.Fatalf("failed to save draft: %v", err)
+	}
+}
+
+// checkReadable fails t unless the history, the archive, the audit log and the drafts
+// all open with the keys db holds now
+func checkReadable(t *testing.T, db *Database) {
+	t.Helper()
+	if got := historyTexts(t, db); !slices.Equal(got, []string{"iki", "bir"}) {
+		t.Errorf("history is %q", got)
+	}
+	archived, err := db.Archive().Search("")
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+	if len(archived) != 2 {
+		t.Fatalf("%d archived items readable, want 2", len(archived))
+	}
+	for _, item := range archived {
+		if _, _, err := db.Archive().GetItem(item.ID); err != nil {
+			t.Errorf("archived item %s: %v", item.ID, err)
+		}
+	}
+	if entries, err := db.AuditEntries(); err != nil || len(entries) == 0 {
+		t.Errorf("audit log unreadable: %d entries, %v", len(entries), err)
+	}
+	if drafts, err := db.LoadDrafts(); err != nil || len(drafts) != 1 || drafts[0].Text != "yarım kalan not" {
+		t.Errorf("drafts are %+v, %v", drafts, err)
+	}
+}
+
+// TestRekeyFailureRollsBack fails a re-key at the database write, after the archive
+// and the audit log were moved, and checks they are back on the key the database
+// still uses; a second try then finishes
+func TestRekeyFailureRollsBack(t *testing.T) {
+	db := newTestDB(t)
+	rekeyFixture(t, db)
+	if err := db.Flush(); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	from := bytes.Clone(db.key)
+
+	// writeFileAtomic can't create its temp file over a directory
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		t.Fatalf("failed to locate database: %v", err)
+	}
+	if err := os.Mkdir(dbPath+".tmp", 0700); err != nil {
+		t.Fatalf("failed to block the write: %v", err)
+	}
+	if err := db.Rekey(nil); err == nil {
+		t.Fatal("re-key succeeded with the database unwritable")
+	}
+	if !bytes.Equal(db.key, from) {
+		t.Fatal("the data key changed although the re-key failed")
+	}
+	if !bytes.Equal(db.archive.key, from) || !bytes.Equal(db.audit.key, from) {
+		t.Error("the archive or the audit log stayed on the new key")
+	}
+	checkReadable(t, db)
+
+	if err := os.Remove(dbPath + ".tmp"); err != nil {
+		t.Fatalf("failed to unblock the write: %v", err)
+	}
+	if err := db.Rekey(nil); err != nil {
+		t.Fatalf("failed to re-key on the second try: %v", err)
+	}
+	if bytes.Equal(db.key, from) {
+		t.Error("the second try kept the old key")
+	}
+	checkReadable(t, db)
+}
+
+// TestResumeRekey stops a re-key after the archive was moved, as a crash would, and
+// checks that the next start finishes it with nothing lost
+func TestResumeRekey(t *testing.T) {
+	db := newTestDB(t)
+	rekeyFixture(t, db)
+	from := bytes.Clone(db.key)
+
+	db.mu.Lock()
+	if err := db.beginRekey(); err != nil {
+		db.mu.Unlock()
+		t.Fatalf("failed to begin re-key: %v", err)
+	}
+	to := bytes.Clone(db.pendingKey)
+	if err := db.archive.rekey([][]byte{db.key, db.pendingKey}, db.pendingKey); err != nil {
+		db.mu.Unlock()
+		t.Fatalf("failed to move the archive: %v", err)
+	}
+	db.mu.Unlock()
+	db.Close()
+
+	reopened, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+	if err := reopened.LoadError(); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if !bytes.Equal(reopened.key, to) || reopened.pendingKey != nil {
+		t.Errorf("the re-key wasn't finished: on the old key %v, pending %v",
+			bytes.Equal(reopened.key, from), reopened.pendingKey != nil)
+	}
+	keyPath, err := GetKeyPath()
+	if err != nil {
+		t.Fatalf("failed to locate key: %v", err)
+	}
+	if _, err := os.Stat(keyPath + pendingKeySuffix); !os.IsNotExist(err) {
+		t.Errorf("the pending key is still there: %v", err)
+	}
+	checkReadable(t, reopened)
+}
+
+// TestNonceReport counts the encrypted fields and flags a ciphertext stored twice, as a
+// repeating RNG would leave them
+func TestNonceReport(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "bir", "iki")
+	report := db.NonceReport()
+	if report.Scanned < 2 || report.Duplicates != 0 {
+		t.Fatalf("fresh history reported %+v", report)
+	}
+
+	db.mu.Lock()
+	db.Items[1].Content = db.Items[0].Content
+	db.mu.Unlock()
+	if got := db.NonceReport(); got.Scanned != report.Scanned || got.Duplicates != 1 {
+		t.Errorf("repeated nonce reported %+v", got)
+	}
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// PruneOlderThan removes unpinned items captured more than d ago and returns how many
+// went; d <= 0 removes nothing
+// Pruned items are deleted, not archived: the retention period exists so old content
+// doesn't stay on disk at all
+func (db *Database) PruneOlderThan(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// A history that failed to load isn't in memory; pruning it would save over the file
+	if db.loadErr != nil {
+		return 0
+	}
+
+	pruned := db.removeOlderThan(d)
+	if len(pruned) == 0 {
+		return 0
+	}
+	db.recordAuditCount(AuditPrune, pruned)
+	// A failed delayed write is reported through SetOnSaveError
+	_ = db.scheduleSave()
+	return len(pruned)
+}
+
+// DeleteOlderThan moves unpinned items captured more than d ago to the trash at the
+// user's request and returns their IDs, so the deletion can be undone with RestoreItem
+// Unlike PruneOlderThan it goes through the trash like any other delete
+func (db *Database) DeleteOlderThan(d time.Duration) ([]string, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("invalid age: %v", d)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.loadErr != nil {
+		return nil, fmt.Errorf("database not loaded: %w", db.loadErr)
+	}
+
+	cutoff := db.now().Add(-d)
+	deleted := make([]ClipboardItem, 0)
+	ids := make([]string, 0)
+	for i := range db.Items {
+		if item := &db.Items[i]; !item.Pinned && !item.Deleted && item.Timestamp.Before(cutoff) {
+			deleted = append(deleted, *item)
+			ids = append(ids, item.ID)
+			db.trashItem(item)
+		}
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	db.recordAuditCount(AuditDelete, deleted)
+	db.commit(ChangeDelete, ids...)
+	return ids, db.scheduleSave()
+}
+
+// removeOlderThan takes unpinned items older than d out of the history and returns
+// them (caller must hold lock)
+func (db *Database) removeOlderThan(d time.Duration) []ClipboardItem {
+	cutoff := db.now().Add(-d)
+	expired := make(map[string]bool)
+	for _, item := range db.Items {
+		// Items in the trash go with it, see PurgeDeleted
+		if !item.Pinned && !item.Deleted && item.Timestamp.Before(cutoff) {
+			expired[item.ID] = true
+		}
+	}
+	if len(expired) == 0 {
+		return []ClipboardItem{}
+	}
+
+	// Kept delta items need their full pixels before their base goes away
+	for id := range expired {
+		_ = db.materializeDependents(id)
+	}
+
+	// Re-read items since materialization may have rewritten their content
+	kept := make([]ClipboardItem, 0, len(db.Items)-len(expired))
+	removed := make([]ClipboardItem, 0, len(expired))
+	ids := make([]string, 0, len(expired))
+	for _, item := range db.Items {
+		if expired[item.ID] {
+			removed = append(removed, item)
+			ids = append(ids, item.ID)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+
+	db.Items = kept
+	db.commit(ChangeEvict, ids...)
+	return removed
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// agedHistory holds "eski", "sabit" (pinned) and "çöpte" (in the trash) captured 40
+// days before "yeni"; returns the ID of "eski" and of "çöpte"
+func agedHistory(t *testing.T) (db *Database, old, trashed string) {
+	t.Helper()
+	db = newTestDB(t)
+	clock := time.Now().Add(-40 * 24 * time.Hour)
+	db.SetClock(func() time.Time { return clock })
+	addTexts(t, db, "eski", "sabit", "çöpte")
+	items := db.GetAllItems() // çöpte, sabit, eski
+	if err := db.TogglePin(items[1].ID); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := db.DeleteItem(items[0].ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	clock = clock.Add(40 * 24 * time.Hour)
+	addTexts(t, db, "yeni")
+	return db, items[2].ID, items[0].ID
+}
+
+// Pruning removes old unpinned items for good and leaves the pinned and the trashed
+func TestPruneOlderThan(t *testing.T) {
+	db, old, trashed := agedHistory(t)
+	if n := db.PruneOlderThan(0); n != 0 {
+		t.Errorf("a zero period pruned %d items", n)
+	}
+	if n := db.PruneOlderThan(30 * 24 * time.Hour); n != 1 {
+		t.Errorf("pruned %d items, want 1", n)
+	}
+	if got := historyTexts(t, db); !slices.Equal(got, []string{"sabit", "yeni"}) {
+		t.Errorf("history is %q", got)
+	}
+	if err := db.RestoreItem(old); err == nil {
+		t.Error("the pruned item went to the trash")
+	}
+	if err := db.RestoreItem(trashed); err != nil {
+		t.Errorf("the trashed item didn't stay restorable: %v", err)
+	}
+	if entries, err := db.AuditEntries(); err != nil || !slices.ContainsFunc(entries, func(e AuditEntry) bool {
+		return e.Op == AuditPrune && e.ItemID == old
+	}) {
+		t.Errorf("no prune in the audit log: %+v, %v", entries, err)
+	}
+}
+
+// Deleting old items at the user's request goes through the trash and can be undone
+func TestDeleteOlderThan(t *testing.T) {
+	db, old, _ := agedHistory(t)
+	if _, err := db.DeleteOlderThan(0); err == nil {
+		t.Error("a zero age was accepted")
+	}
+	ids, err := db.DeleteOlderThan(30 * 24 * time.Hour)
+	if err != nil || !slices.Equal(ids, []string{old}) {
+		t.Fatalf("deleted %q, %v", ids, err)
+	}
+	if got := historyTexts(t, db); !slices.Equal(got, []string{"sabit", "yeni"}) {
+		t.Errorf("history is %q", got)
+	}
+	if err := db.RestoreItem(old); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if got := historyTexts(t, db); !slices.Equal(got, []string{"sabit", "yeni", "eski"}) {
+		t.Errorf("history after the undo is %q", got)
+	}
+}
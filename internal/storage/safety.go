@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operations that replace or drop many items at once run through withSafetyBackup,
+// which first writes the current items to a restore point next to the database.
+// Restore points are ordinary database files, so restoring one is just loading it.
+
+const (
+	SafetyDirName = "restore" // Restore points, next to the database
+	SafetyKeep    = 3         // Older restore points are deleted beyond this
+
+	safetyPrefix = "safety-"
+	safetyExt    = ".db"
+)
+
+// SafetyReason names the operation a restore point was taken before
+type SafetyReason string
+
+const (
+	SafetyClear     SafetyReason = "clear"     // Clearing the history
+	SafetyMigration SafetyReason = "migration" // Converting a legacy file to the binary format or the row store
+	SafetyRestore   SafetyReason = "restore"   // Restoring another restore point
+	SafetyRekey     SafetyReason = "rekey"     // Re-encrypting the history with a new key
+	SafetyImport    SafetyReason = "import"    // Importing a portable export
+)
+
+// RestorePoint is an automatic safety copy of the database
+type RestorePoint struct {
+	Path   string
+	Time   time.Time
+	Reason SafetyReason
+	Size   int64
+}
+
+// GetSafetyDir returns the directory that holds restore points
+func GetSafetyDir() (string, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), SafetyDirName), nil
+}
+
+// RestorePoints returns the restore points, newest first
+func (db *Database) RestorePoints() ([]RestorePoint, error) {
+	dir, err := GetSafetyDir()
+	if err != nil {
+		return nil, err
+	}
+	return listRestorePoints(dir)
+}
+
+// RestoreFromPoint replaces all items with those of a restore point
+// The current items get a restore point of their own first, so a restore can be undone
+func (db *Database) RestoreFromPoint(path string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read restore point: %w", err)
+	}
+	items, err := decodeDatabaseFile(data, db.key)
+	if err != nil {
+		return fmt.Errorf("failed to decode restore point: %w", err)
+	}
+
+	return db.withSafetyBackup(SafetyRestore, func() error {
+		db.Items = items
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		db.commit(ChangeReload, ids...)
+		db.writeAudit(AuditEntry{Op: AuditRestore, Count: len(items)})
+		return db.saveInternal()
+	})
+}
+
+// withSafetyBackup writes the current items to a restore point, then runs op
+// op doesn't run if the restore point can't be written; an empty history needs none
+// (caller must hold lock)
+func (db *Database) withSafetyBackup(reason SafetyReason, op func() error) error {
+	if len(db.Items) > 0 {
+		if err := db.writeRestorePoint(reason); err != nil {
+			return fmt.Errorf("failed to create restore point: %w", err)
+		}
+	}
+	return op()
+}
+
+// writeRestorePoint encodes the current items into a new restore point and drops the
+// oldest ones beyond SafetyKeep (caller must hold lock)
+func (db *Database) writeRestorePoint(reason SafetyReason) error {
+	dir, err := GetSafetyDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create restore point directory: %w", err)
+	}
+
+	encoded, err := encodeDatabaseFile(db.Items, db.key)
+	if err != nil {
+		return err
+	}
+
+	// Nanoseconds keep names unique and sortable even for back-to-back operations
+	name := fmt.Sprintf("%s%d-%s%s", safetyPrefix, time.Now().UnixNano(), reason, safetyExt)
+	path := filepath.Join(dir, name)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, encoded, 0600); err != nil {
+		return fmt.Errorf("failed to write restore point: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write restore point: %w", err)
+	}
+
+	// The new point is written; a leftover old one isn't worth failing the operation for
+	_ = pruneRestorePoints(dir, SafetyKeep)
+	return nil
+}
+
+// listRestorePoints reads the restore points in dir, newest first
+// Files that don't follow the naming scheme are ignored
+func listRestorePoints(dir string) ([]RestorePoint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []RestorePoint{}, nil
+		}
+		return nil, fmt.Errorf("failed to read restore points: %w", err)
+	}
+
+	points := make([]RestorePoint, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, safetyPrefix) || !strings.HasSuffix(name, safetyExt) {
+			continue
+		}
+		stamp, reason, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(name, safetyPrefix), safetyExt), "-")
+		if !ok {
+			continue
+		}
+		nanos, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		points = append(points, RestorePoint{
+			Path:   filepath.Join(dir, name),
+			Time:   time.Unix(0, nanos),
+			Reason: SafetyReason(reason),
+			Size:   info.Size(),
+		})
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Time.After(points[j].Time)
+	})
+	return points, nil
+}
+
+// pruneRestorePoints deletes all but the newest keep restore points in dir
+func pruneRestorePoints(dir string, keep int) error {
+	points, err := listRestorePoints(dir)
+	if err != nil {
+		return err
+	}
+	for _, point := range points[min(keep, len(points)):] {
+		if err := os.Remove(point.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old restore point: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestRestorePoints clears a history and restores it from the point taken before, then
+// undoes that restore with the point it took in turn
+func TestRestorePoints(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "bir", "iki")
+	if err := db.ClearAll(); err != nil {
+		t.Fatalf("failed to clear: %v", err)
+	}
+	addTexts(t, db, "yeni")
+
+	points, err := db.RestorePoints()
+	if err != nil {
+		t.Fatalf("failed to list restore points: %v", err)
+	}
+	if len(points) != 1 || points[0].Reason != SafetyClear || points[0].Size == 0 {
+		t.Fatalf("restore points are %+v", points)
+	}
+	if err := db.RestoreFromPoint(points[0].Path); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if got := historyTexts(t, db); !slices.Equal(got, []string{"iki", "bir"}) {
+		t.Errorf("restored history is %q", got)
+	}
+
+	if points, err = db.RestorePoints(); err != nil || len(points) != 2 || points[0].Reason != SafetyRestore {
+		t.Fatalf("restore points after the restore are %+v, %v", points, err)
+	}
+	if err := db.RestoreFromPoint(points[0].Path); err != nil {
+		t.Fatalf("failed to undo the restore: %v", err)
+	}
+	if got := historyTexts(t, db); !slices.Equal(got, []string{"yeni"}) {
+		t.Errorf("history after undoing the restore is %q", got)
+	}
+}
+
+// Only the newest SafetyKeep points stay; files that aren't restore points are left alone
+func TestRestorePointsPruned(t *testing.T) {
+	db := newTestDB(t)
+	dir, err := GetSafetyDir()
+	if err != nil {
+		t.Fatalf("failed to locate restore points: %v", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("failed to create restore point directory: %v", err)
+	}
+	stray := filepath.Join(dir, "notlar.txt")
+	if err := os.WriteFile(stray, []byte("benim"), 0600); err != nil {
+		t.Fatalf("failed to write stray file: %v", err)
+	}
+
+	for i := range SafetyKeep + 2 {
+		addTexts(t, db, numbered("öğe", i+1)...)
+		if err := db.ClearAll(); err != nil {
+			t.Fatalf("failed to clear: %v", err)
+		}
+	}
+	points, err := db.RestorePoints()
+	if err != nil {
+		t.Fatalf("failed to list restore points: %v", err)
+	}
+	if len(points) != SafetyKeep {
+		t.Fatalf("%d restore points, want %d", len(points), SafetyKeep)
+	}
+	// The newest point holds the largest history
+	if err := db.RestoreFromPoint(points[0].Path); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if got := len(db.GetAllItems()); got != SafetyKeep+2 {
+		t.Errorf("newest point has %d items, want %d", got, SafetyKeep+2)
+	}
+	if _, err := os.Stat(stray); err != nil {
+		t.Errorf("the stray file was removed: %v", err)
+	}
+}
+
+// Without a restore point the clear doesn't happen
+func TestClearNeedsRestorePoint(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "bir", "iki")
+	dir, err := GetSafetyDir()
+	if err != nil {
+		t.Fatalf("failed to locate restore points: %v", err)
+	}
+	// A file where the directory should be
+	if err := os.WriteFile(dir, nil, 0600); err != nil {
+		t.Fatalf("failed to block the restore points: %v", err)
+	}
+	if err := db.ClearAll(); err == nil {
+		t.Fatal("cleared without a restore point")
+	}
+	if got := historyTexts(t, db); !slices.Equal(got, []string{"iki", "bir"}) {
+		t.Errorf("history after the refused clear is %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// turkishFolder maps the dotted and dotless i's to a plain "i" after lower-casing, so a
+// query matches whichever of them the text uses: "ISTANBUL", "İstanbul" and "ıstanbul"
+// all fold to "istanbul"
+var turkishFolder = strings.NewReplacer("i̇", "i", "ı", "i")
+
+// FoldText lower-cases text for case-insensitive search, Turkish letters included
+// Text is composed (NFC) first, so a letter typed with a combining accent, as some
+// IMEs and macOS file names produce, matches its precomposed form
+func FoldText(text string) string {
+	return turkishFolder.Replace(strings.ToLower(norm.NFC.String(text)))
+}
+
+// SearchItems returns text items containing query (case-insensitive, see FoldText),
+// newest first; an empty query returns nothing
+func (db *Database) SearchItems(query string) []ClipboardItem {
+	query = FoldText(strings.TrimSpace(query))
+	if query == "" {
+		return []ClipboardItem{}
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	result := make([]ClipboardItem, 0)
+	for _, item := range db.Items {
+		// Matching would reveal what a protected item contains
+		if item.Type != "text" || item.IsProtected() || item.Deleted {
+			continue
+		}
+		content, err := Decrypt(item.Content, db.key)
+		if err != nil {
+			continue
+		}
+		match := strings.Contains(FoldText(string(content)), query)
+		Zero(content)
+		if match {
+			result = append(result, item)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+	return result
+}
@@ -0,0 +1,23 @@
+package storage
+
+// Zero overwrites a sensitive buffer in place
+//
+// This is best effort: Go may already have copied the bytes (string
+// conversions, slice growth, GC moves), so Zero only shortens how long the
+// original buffer stays readable. It is still worth calling on every
+// plaintext and key buffer once it is no longer needed.
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// newLockedKey copies key into a buffer that the OS is asked to keep out of
+// swap where supported, and zeroes the source
+func newLockedKey(key []byte) []byte {
+	locked := make([]byte, len(key))
+	copy(locked, key)
+	Zero(key)
+	lockMemory(locked)
+	return locked
+}
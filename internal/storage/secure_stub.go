@@ -0,0 +1,7 @@
+//go:build !windows
+// +build !windows
+
+package storage
+
+// lockMemory is a no-op on non-Windows platforms
+func lockMemory(b []byte) {}
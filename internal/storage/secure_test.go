@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// allZero reports whether every byte of b is zero
+func allZero(b []byte) bool {
+	return len(bytes.Trim(b, "\x00")) == 0
+}
+
+func TestZero(t *testing.T) {
+	buf := []byte("gizli düz metin")
+	Zero(buf[:5])
+	if !allZero(buf[:5]) || string(buf[5:]) != " düz metin" {
+		t.Errorf("Zero(buf[:5]) left %q", buf)
+	}
+	Zero(buf)
+	if !allZero(buf) {
+		t.Errorf("Zero left %q", buf)
+	}
+	Zero(nil) // Must not panic
+}
+
+// The locked copy is the only one left: the source is wiped
+func TestNewLockedKey(t *testing.T) {
+	source := bytes.Repeat([]byte{0xa5}, 32)
+	locked := newLockedKey(source)
+	if !bytes.Equal(locked, bytes.Repeat([]byte{0xa5}, 32)) {
+		t.Errorf("locked key is %x", locked)
+	}
+	if !allZero(source) {
+		t.Errorf("source still holds %x", source)
+	}
+	locked[0] = 1
+	if source[0] != 0 {
+		t.Error("the locked key shares memory with its source")
+	}
+}
+
+// A key the database no longer uses is wiped in place, wherever it was held
+func TestRetiredKeysAreZeroed(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.AddItem("text", []byte("merhaba")); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+
+	old := db.key
+	if err := db.Rekey(nil); err != nil {
+		t.Fatalf("failed to re-key: %v", err)
+	}
+	if !allZero(old) {
+		t.Error("the key replaced by a re-key wasn't wiped")
+	}
+	if allZero(db.key) {
+		t.Error("the new key is empty")
+	}
+
+	if err := db.SetPassword("parola", nil); err != nil {
+		t.Fatalf("failed to set password: %v", err)
+	}
+	derived := db.password.key
+	if err := db.RemovePassword("parola"); err != nil {
+		t.Fatalf("failed to remove password: %v", err)
+	}
+	if !allZero(derived) {
+		t.Error("the password key wasn't wiped once the password was removed")
+	}
+}
+
+// secretWords mark identifiers that hold key material or decrypted content
+var secretWords = []string{"key", "plain", "decrypted", "password", "passphrase", "secret", "content"}
+
+// publicWords are allowed despite containing one of secretWords
+var publicWords = []string{"hotkey", "keyfingerprint"}
+
+// isSecretName reports whether an identifier names key material or plaintext
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range publicWords {
+		name = strings.ReplaceAll(name, word, "")
+	}
+	for _, word := range secretWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLogCall reports whether call writes a log line: the log package, or fmt printing
+// to stdout or stderr as the command line does
+func isLogCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	switch pkg.Name {
+	case "log":
+		return true
+	case "fmt":
+		if strings.HasPrefix(sel.Sel.Name, "Print") {
+			return true
+		}
+		if strings.HasPrefix(sel.Sel.Name, "Fprint") && len(call.Args) > 0 {
+			if out, ok := call.Args[0].(*ast.SelectorExpr); ok {
+				if os, ok := out.X.(*ast.Ident); ok && os.Name == "os" {
+					return out.Sel.Name == "Stderr" || out.Sel.Name == "Stdout"
+				}
+			}
+		}
+	}
+	return false
+}
+
+// TestLogsNoSecrets is a lint over the whole module: no log call may be passed anything
+// named like a key or plaintext. GetKeyFingerprint is the only key-derived value that
+// may be logged
+func TestLogsNoSecrets(t *testing.T) {
+	root := filepath.Join("..", "..")
+	fset := token.NewFileSet()
+	checked := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != root {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !isLogCall(call) {
+				return true
+			}
+			checked++
+			for _, arg := range call.Args {
+				ast.Inspect(arg, func(n ast.Node) bool {
+					if id, ok := n.(*ast.Ident); ok && isSecretName(id.Name) {
+						t.Errorf("%s: log call is passed %s", fset.Position(id.Pos()), id.Name)
+					}
+					return true
+				})
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to scan the module: %v", err)
+	}
+	if checked == 0 {
+		t.Fatal("no log calls found; the scan looked in the wrong place")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+package storage
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockMemory pins a buffer in physical memory so it is never written to the page file
+// Failure is ignored; the buffer is still usable, just swappable
+func lockMemory(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	_ = windows.VirtualLock(uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)))
+}
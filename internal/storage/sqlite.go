@@ -0,0 +1,344 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	_ "modernc.org/sqlite" // Pure Go driver, registered as "sqlite"; no cgo needed
+)
+
+// SQLite row store
+//
+// The database file is written whole on every save: every item is encoded into one
+// container and the container is encrypted again, images included. The row store
+// replaces that file with clipboard.sqlite, one row per item. A row's content column
+// holds the item's content ciphertext as it was captured (per-item AES-GCM, see
+// crypto.go); its record column holds the other fields, encoded as in format.go and
+// encrypted. A save writes only the rows that changed since the last one, and the
+// list order, in one transaction.
+//
+// Everything above it stays as it is: items are still held in memory, the Database
+// API doesn't change and the journal still covers captures until the next save.
+// MigrateToSQLite moves a history to the row store once; from then on the presence of
+// clipboard.sqlite selects it.
+
+// SQLiteFile holds the history once it was moved to the row store
+const SQLiteFile = "clipboard.sqlite"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS items (
+	id      TEXT PRIMARY KEY,
+	record  BLOB NOT NULL,
+	content BLOB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS meta (
+	name  TEXT PRIMARY KEY,
+	value BLOB NOT NULL
+);`
+
+// sqliteOrder names the meta row with the item IDs in list order, encrypted
+// A store without it was never written completely
+const sqliteOrder = "order"
+
+// sqliteRow is what an item's row was last written with
+type sqliteRow struct {
+	record  [sha256.Size]byte // Hash of the encoded fields, before encryption
+	content string            // The item's Content; shares memory with it
+}
+
+// sqliteStore keeps the items in clipboard.sqlite
+type sqliteStore struct {
+	db    *sql.DB
+	key   []byte               // Key the rows were written with
+	rows  map[string]sqliteRow // Rows as last written or read, by item ID
+	order string               // IDs as last written, one per line
+}
+
+// GetSQLitePath returns the full path to the row store
+func GetSQLitePath() (string, error) {
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), SQLiteFile), nil
+}
+
+// SQLiteEnabled reports whether the history was moved to the row store
+func SQLiteEnabled() bool {
+	path, err := GetSQLitePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// openSQLiteStore opens the row store at path, creating it if needed
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	// Created here so it gets the same permissions as the database file
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Base(path), err)
+	}
+	f.Close()
+
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	// Writes are serialized by db.mu anyway, and pragmas hold per connection
+	conn.SetMaxOpenConns(1)
+	for _, stmt := range []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = FULL",
+		sqliteSchema,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to prepare %s: %w", filepath.Base(path), err)
+		}
+	}
+	return &sqliteStore{db: conn, rows: make(map[string]sqliteRow)}, nil
+}
+
+// load reads every item, in list order, with the first of keys that opens the store
+func (s *sqliteStore) load(keys [][]byte) ([]ClipboardItem, error) {
+	var sealedOrder []byte
+	err := s.db.QueryRow("SELECT value FROM meta WHERE name = ?", sqliteOrder).Scan(&sealedOrder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return make([]ClipboardItem, 0), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read item order: %w", err)
+	}
+
+	var key, order []byte
+	for _, k := range keys {
+		if k == nil {
+			continue
+		}
+		if order, err = DecryptBytes(sealedOrder, k); err == nil {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("failed to decrypt database: %w (%w)", ErrKeyMismatch, err)
+	}
+
+	rows, err := s.db.Query("SELECT id, record, content FROM items")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read items: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]ClipboardItem)
+	fingerprints := make(map[string]sqliteRow)
+	for rows.Next() {
+		var id string
+		var sealed, content []byte
+		if err := rows.Scan(&id, &sealed, &content); err != nil {
+			return nil, fmt.Errorf("failed to read item: %w", err)
+		}
+		record, err := DecryptBytes(sealed, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt item %s: %w", id, err)
+		}
+		item, err := decodeItem(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse item %s: %w", id, err)
+		}
+		item.Content = base64.StdEncoding.EncodeToString(content)
+		byID[id] = item
+		fingerprints[id] = sqliteRow{record: sha256.Sum256(record), content: item.Content}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read items: %w", err)
+	}
+
+	items := make([]ClipboardItem, 0, len(byID))
+	if len(order) > 0 {
+		for _, id := range strings.Split(string(order), "\n") {
+			if item, ok := byID[id]; ok {
+				items = append(items, item)
+				delete(byID, id)
+			}
+		}
+	}
+	// Rows and order are written together, so this only catches a hand-edited store
+	rest := make([]ClipboardItem, 0, len(byID))
+	for _, item := range byID {
+		rest = append(rest, item)
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].Timestamp.After(rest[j].Timestamp)
+	})
+	items = append(items, rest...)
+
+	s.key, s.rows, s.order = key, fingerprints, string(order)
+	return items, nil
+}
+
+// save writes the rows of items that changed since the last save or load and drops
+// those of removed items, in one transaction; a new key rewrites every row
+func (s *sqliteStore) save(items []ClipboardItem, key []byte) error {
+	previous, previousOrder := s.rows, s.order
+	if !bytes.Equal(key, s.key) {
+		previous, previousOrder = nil, ""
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to write database: %w", err)
+	}
+	defer tx.Rollback() // A no-op once committed
+
+	if previous == nil {
+		// Rows under another key can't be compared; none of them may stay
+		if _, err := tx.Exec("DELETE FROM items"); err != nil {
+			return fmt.Errorf("failed to drop items: %w", err)
+		}
+	}
+
+	written := make(map[string]sqliteRow, len(items))
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+		content := item.Content
+		item.Content = ""
+		record, err := encodeItem(item)
+		if err != nil {
+			return err
+		}
+		row := sqliteRow{record: sha256.Sum256(record), content: content}
+		written[item.ID] = row
+		if prev, ok := previous[item.ID]; ok && prev == row {
+			continue
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return fmt.Errorf("invalid content encoding for item %s: %w", item.ID, err)
+		}
+		sealed, err := EncryptBytes(record, key)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt item %s: %w", item.ID, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO items (id, record, content) VALUES (?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET record = excluded.record, content = excluded.content`,
+			item.ID, sealed, raw); err != nil {
+			return fmt.Errorf("failed to write item %s: %w", item.ID, err)
+		}
+	}
+	for id := range previous {
+		if _, ok := written[id]; ok {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM items WHERE id = ?", id); err != nil {
+			return fmt.Errorf("failed to drop item %s: %w", id, err)
+		}
+	}
+
+	order := strings.Join(ids, "\n")
+	if order != previousOrder || previous == nil {
+		sealed, err := EncryptBytes([]byte(order), key)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt item order: %w", err)
+		}
+		if _, err := tx.Exec("INSERT OR REPLACE INTO meta (name, value) VALUES (?, ?)", sqliteOrder, sealed); err != nil {
+			return fmt.Errorf("failed to write item order: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write database: %w", err)
+	}
+	s.key, s.rows, s.order = key, written, order
+	return nil
+}
+
+// close releases the store
+// A nil store (the database file is used) ignores the call
+func (s *sqliteStore) close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// MigrateToSQLite moves the history to the row store, see the comment at the top
+// A restore point of the history is written first. The rows are written to a temp
+// file that is renamed into place, so an interrupted move leaves the database file in
+// use; the file is removed once the store is in place. A history already moved is
+// left as it is
+func (db *Database) MigrateToSQLite() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.sqlite != nil {
+		return nil
+	}
+	if db.loadErr != nil {
+		return fmt.Errorf("database not loaded: %w", db.loadErr)
+	}
+	dbPath, err := GetDatabasePath()
+	if err != nil {
+		return err
+	}
+	path, err := GetSQLitePath()
+	if err != nil {
+		return err
+	}
+
+	if len(db.Items) > 0 {
+		if err := db.writeRestorePoint(SafetyMigration); err != nil {
+			return fmt.Errorf("failed to create restore point: %w", err)
+		}
+	}
+
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	tmp, err := openSQLiteStore(tmpPath)
+	if err != nil {
+		return err
+	}
+	err = tmp.save(db.Items, db.key)
+	if closeErr := tmp.close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move history to %s: %w", SQLiteFile, err)
+	}
+
+	store, err := openSQLiteStore(path)
+	if err != nil {
+		return err
+	}
+	if _, err := store.load([][]byte{db.key}); err != nil {
+		store.close()
+		return err
+	}
+	db.sqlite = store
+	db.stopSaveTimer()
+	db.dirty = false
+	_ = db.journal.reset()
+
+	// The store is read from now on; a file left behind is only ignored
+	if err := os.Remove(dbPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("history moved, but %s couldn't be removed: %w", DatabaseFile, err)
+	}
+	db.writeAudit(AuditEntry{Op: AuditMigrate, Count: len(db.Items)})
+	return nil
+}
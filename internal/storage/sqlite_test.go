@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+)
+
+// historyTexts returns the contents of db's text items, in list order
+func historyTexts(t *testing.T, db *Database) []string {
+	t.Helper()
+	var got []string
+	for _, item := range db.GetAllItems() {
+		_, content, err := db.GetItem(item.ID)
+		if err != nil {
+			t.Fatalf("failed to read item: %v", err)
+		}
+		got = append(got, string(content))
+	}
+	return got
+}
+
+// sqliteRows returns every row of the store as it is on disk, by item ID
+func sqliteRows(t *testing.T, db *Database) map[string]string {
+	t.Helper()
+	rows, err := db.sqlite.db.Query("SELECT id, record, content FROM items")
+	if err != nil {
+		t.Fatalf("failed to read rows: %v", err)
+	}
+	defer rows.Close()
+	got := make(map[string]string)
+	for rows.Next() {
+		var id string
+		var record, content []byte
+		if err := rows.Scan(&id, &record, &content); err != nil {
+			t.Fatalf("failed to read row: %v", err)
+		}
+		got[id] = string(record) + string(content)
+	}
+	return got
+}
+
+// migrated opens a database with "bir", "iki" and "üç" (pinned) and moves it to the
+// row store
+func migrated(t *testing.T) *Database {
+	t.Helper()
+	db := newTestDB(t)
+	for _, text := range []string{"bir", "iki", "üç"} {
+		if err := db.AddItem("text", []byte(text)); err != nil {
+			t.Fatalf("failed to add: %v", err)
+		}
+	}
+	if err := db.TogglePin(db.GetAllItems()[0].ID); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := db.MigrateToSQLite(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// TestMigrateToSQLite moves a history in either file format to the row store and
+// checks that it opens from there, with nothing lost and the file gone
+func TestMigrateToSQLite(t *testing.T) {
+	tests := []struct {
+		name   string
+		legacy bool // Rewrite the file as JSON, as versions before the container did
+	}{
+		{"binary file", false},
+		{"legacy JSON file", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			for _, text := range []string{"bir", "iki", "üç"} {
+				if err := db.AddItem("text", []byte(text)); err != nil {
+					t.Fatalf("failed to add: %v", err)
+				}
+			}
+			if err := db.TogglePin(db.GetAllItems()[2].ID); err != nil {
+				t.Fatalf("failed to pin: %v", err)
+			}
+			dbPath, err := GetDatabasePath()
+			if err != nil {
+				t.Fatalf("failed to locate database: %v", err)
+			}
+			if tt.legacy {
+				plain, err := json.Marshal(db.GetAllItems())
+				if err != nil {
+					t.Fatalf("failed to marshal: %v", err)
+				}
+				data, err := Encrypt(plain, db.key)
+				if err != nil {
+					t.Fatalf("failed to encrypt: %v", err)
+				}
+				db.Close()
+				if err := os.WriteFile(dbPath, []byte(data), 0600); err != nil {
+					t.Fatalf("failed to write legacy file: %v", err)
+				}
+				if db, err = NewDatabase(); err != nil {
+					t.Fatalf("failed to reopen: %v", err)
+				}
+				defer db.Close()
+			}
+			want := []string{"bir", "üç", "iki"}
+
+			if err := db.MigrateToSQLite(); err != nil {
+				t.Fatalf("failed to migrate: %v", err)
+			}
+			if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+				t.Errorf("%s is still there: %v", DatabaseFile, err)
+			}
+			if !SQLiteEnabled() {
+				t.Fatal("the row store isn't selected")
+			}
+			points, err := db.RestorePoints()
+			if err != nil {
+				t.Fatalf("failed to list restore points: %v", err)
+			}
+			if len(points) == 0 || points[0].Reason != SafetyMigration {
+				t.Errorf("no restore point before the move: %+v", points)
+			}
+			if err := db.AddItem("text", []byte("dört")); err != nil {
+				t.Fatalf("failed to add after the move: %v", err)
+			}
+			db.Close()
+
+			reopened, err := NewDatabase()
+			if err != nil {
+				t.Fatalf("failed to reopen: %v", err)
+			}
+			defer reopened.Close()
+			if err := reopened.LoadError(); err != nil {
+				t.Fatalf("failed to load the row store: %v", err)
+			}
+			if got := historyTexts(t, reopened); !slices.Equal(got, append([]string{want[0], "dört"}, want[1:]...)) {
+				t.Errorf("reopened history is %q", got)
+			}
+		})
+	}
+}
+
+// TestSQLiteWritesChangedRows checks that a save leaves the rows of unchanged items
+// as they were on disk and drops those of removed items
+func TestSQLiteWritesChangedRows(t *testing.T) {
+	db := migrated(t)
+	items := db.GetAllItems() // üç (pinned), iki, bir
+	before := sqliteRows(t, db)
+
+	if err := db.TogglePin(items[1].ID); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := db.DeleteItem(items[2].ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err := db.PurgeDeleted(0); err != nil {
+		t.Fatalf("failed to purge: %v", err)
+	}
+	if err := db.AddItem("text", []byte("dört")); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	after := sqliteRows(t, db)
+
+	if len(after) != 3 {
+		t.Fatalf("%d rows, want 3", len(after))
+	}
+	if after[items[0].ID] != before[items[0].ID] {
+		t.Error("the untouched item was written again")
+	}
+	if after[items[1].ID] == before[items[1].ID] {
+		t.Error("the pinned item wasn't written")
+	}
+	if _, ok := after[items[2].ID]; ok {
+		t.Error("the purged item is still stored")
+	}
+}
+
+// TestSQLiteRekey re-keys a history in the row store: every row is rewritten and the
+// store opens with the new key
+func TestSQLiteRekey(t *testing.T) {
+	db := migrated(t)
+	want := historyTexts(t, db)
+	before := sqliteRows(t, db)
+
+	if err := db.Rekey(nil); err != nil {
+		t.Fatalf("failed to re-key: %v", err)
+	}
+	for id, row := range sqliteRows(t, db) {
+		if row == before[id] {
+			t.Errorf("item %s kept its row under the old key", id)
+		}
+	}
+	db.Close()
+
+	reopened, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+	if err := reopened.LoadError(); err != nil {
+		t.Fatalf("failed to load after re-key: %v", err)
+	}
+	if got := historyTexts(t, reopened); !slices.Equal(got, want) {
+		t.Errorf("reopened history is %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTagsPerItem caps the tags on a single item
+	MaxTagsPerItem = 10
+	// MaxTagLength caps a tag's length in characters
+	MaxTagLength = 32
+)
+
+var (
+	// ErrTooManyTags is returned by SetTags for more than MaxTagsPerItem tags
+	ErrTooManyTags = errors.New("too many tags")
+	// ErrTagTooLong is returned by SetTags for a tag longer than MaxTagLength
+	ErrTagTooLong = errors.New("tag too long")
+)
+
+// NormalizeTags trims tags and drops empty ones and duplicates, keeping the first spelling
+// Tags are compared with FoldText, so "SQL" and "sql" are the same tag
+// Commas are dropped since the editor and the list separate tags with them
+func NormalizeTags(tags []string) ([]string, error) {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.ReplaceAll(tag, ",", ""))
+		if tag == "" || seen[FoldText(tag)] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("%w: %q", ErrTagTooLong, tag)
+		}
+		seen[FoldText(tag)] = true
+		result = append(result, tag)
+	}
+	if len(result) > MaxTagsPerItem {
+		return nil, ErrTooManyTags
+	}
+	return result, nil
+}
+
+// HasTag reports whether the item carries tag (compared with FoldText)
+func (item ClipboardItem) HasTag(tag string) bool {
+	tag = FoldText(strings.TrimSpace(tag))
+	for _, t := range item.Tags {
+		if FoldText(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTags replaces an item's tags; an empty list removes them
+func (db *Database) SetTags(id string, tags []string) error {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if len(tags) == 0 {
+		tags = nil
+	}
+	for i, item := range db.Items {
+		if item.ID == id {
+			// Always a new slice: snapshots handed out earlier share the old one
+			db.Items[i].Tags = tags
+			db.commit(ChangeUpdate, id)
+			return db.scheduleSave()
+		}
+	}
+	return fmt.Errorf("item not found")
+}
+
+// GetItemsByTag returns the items carrying tag, pinned first
+func (db *Database) GetItemsByTag(tag string) []ClipboardItem {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	result := make([]ClipboardItem, 0)
+	for _, item := range db.orderedItems() {
+		if item.HasTag(tag) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// AllTags returns every tag in use, sorted, in the spelling first met
+func (db *Database) AllTags() []string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	tags := make([]string, 0)
+	for _, item := range db.Items {
+		if item.Deleted {
+			continue
+		}
+		for _, tag := range item.Tags {
+			if folded := FoldText(tag); !seen[folded] {
+				seen[folded] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return FoldText(tags[i]) < FoldText(tags[j])
+	})
+	return tags
+}
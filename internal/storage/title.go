@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TitleMaxChars is the length an implicit title is cut to, in grapheme clusters
+const TitleMaxChars = 60
+
+// ExtractTitle returns the first non-empty line of text as an implicit title, with
+// markdown heading markers removed, and the text after it
+// ok is false when there is nothing after the first line; single lines need no title
+func ExtractTitle(text string) (title, rest string, ok bool) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		line = trimTitleLine(line)
+		if line == "" {
+			continue
+		}
+		rest = strings.TrimFunc(strings.Join(lines[i+1:], "\n"), isTitleSpace)
+		if rest == "" {
+			return "", "", false
+		}
+		if cut, ok := CutGraphemes(line, TitleMaxChars); ok {
+			line = cut + "…"
+		}
+		return line, rest, true
+	}
+	return "", "", false
+}
+
+// trimTitleLine strips whitespace, byte order marks and a markdown heading marker
+func trimTitleLine(line string) string {
+	line = strings.TrimFunc(line, isTitleSpace)
+	if marker := strings.TrimLeft(line, "#"); len(marker) < len(line) && len(line)-len(marker) <= 6 {
+		// "#tag" is not a heading; "# Title" and a bare "##" are
+		if marker == "" || marker[0] == ' ' || marker[0] == '\t' {
+			line = strings.TrimFunc(marker, isTitleSpace)
+		}
+	}
+	return line
+}
+
+// isTitleSpace matches whitespace and the byte order mark
+func isTitleSpace(r rune) bool {
+	return unicode.IsSpace(r) || r == '\ufeff'
+}
+
+// MaxUserTitleLength caps a title set by the user, in characters
+const MaxUserTitleLength = 100
+
+// ErrTitleTooLong is returned by SetTitle for a title longer than MaxUserTitleLength
+var ErrTitleTooLong = errors.New("title too long")
+
+// SetTitle sets the title the user gave an item; an empty title removes it
+// Line breaks become spaces, since the card shows the title on one line
+func (db *Database) SetTitle(id, title string) error {
+	title = strings.TrimFunc(strings.Join(strings.Fields(title), " "), isTitleSpace)
+	if utf8.RuneCountInString(title) > MaxUserTitleLength {
+		return ErrTitleTooLong
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i, item := range db.Items {
+		if item.ID != id {
+			continue
+		}
+		encrypted := ""
+		if title != "" {
+			var err error
+			if encrypted, err = Encrypt([]byte(title), db.key); err != nil {
+				return fmt.Errorf("failed to encrypt title: %w", err)
+			}
+		}
+		db.Items[i].Title = encrypted
+		db.commit(ChangeUpdate, id)
+		return db.scheduleSave()
+	}
+	return fmt.Errorf("item not found")
+}
+
+// GetItemUserTitle returns the decrypted title the user gave an item, "" if it has none
+func (db *Database) GetItemUserTitle(id string) (string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, item := range db.Items {
+		if item.ID == id {
+			if item.Title == "" {
+				return "", nil
+			}
+			decrypted, err := Decrypt(item.Title, db.key)
+			if err != nil {
+				return "", fmt.Errorf("failed to decrypt user title: %w", err)
+			}
+			return string(decrypted), nil
+		}
+	}
+	return "", fmt.Errorf("item not found")
+}
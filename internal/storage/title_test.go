@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractTitle(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		title     string
+		rest      string
+		wantTitle bool
+	}{
+		{"single line", "tek satır", "", "", false},
+		{"trailing blank lines", "tek satır\n\n  \n", "", "", false},
+		{"two lines", "Başlık\ngövde", "Başlık", "gövde", true},
+		{"windows line ends", "Başlık\r\nbir\r\niki", "Başlık", "bir\niki", true},
+		{"old mac line ends", "Başlık\rgövde", "Başlık", "gövde", true},
+		{"leading blank lines and BOM", "\ufeff\n  \n  Başlık  \n\ngövde\n", "Başlık", "gövde", true},
+		{"markdown heading", "## Kurulum\nadımlar", "Kurulum", "adımlar", true},
+		{"hashtag is not a heading", "#etiket\ngövde", "#etiket", "gövde", true},
+		{"seven hashes are not a heading", "####### çok\ngövde", "####### çok", "gövde", true},
+		{
+			"long first line",
+			strings.Repeat("ğ", TitleMaxChars+5) + "\ngövde",
+			strings.Repeat("ğ", TitleMaxChars) + "…", "gövde", true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, rest, ok := ExtractTitle(tt.text)
+			if ok != tt.wantTitle || title != tt.title || rest != tt.rest {
+				t.Errorf("ExtractTitle(%q) = %q, %q, %v; want %q, %q, %v",
+					tt.text, title, rest, ok, tt.title, tt.rest, tt.wantTitle)
+			}
+		})
+	}
+}
+
+// Multi-line captures get their implicit title at capture time, single lines none
+func TestImplicitTitle(t *testing.T) {
+	db := newTestDB(t)
+	addTexts(t, db, "tek satır", "# Notlar\nbirinci\nikinci")
+	items := db.GetAllItems() // Notlar, tek satır
+	if title, err := db.GetItemTitle(items[0].ID); err != nil || title != "Notlar" {
+		t.Errorf("implicit title is %q, %v", title, err)
+	}
+	if title, err := db.GetItemTitle(items[1].ID); err != nil || title != "" {
+		t.Errorf("a single line got the title %q, %v", title, err)
+	}
+	if _, err := db.GetItemTitle("yok"); err == nil {
+		t.Error("a missing item has a title")
+	}
+}
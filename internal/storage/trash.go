@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Deleting an item moves it to the trash instead of removing it: it is hidden from the
+// history, the limits and the exports, but keeps its content so the delete can be undone
+// with RestoreItem. PurgeDeleted removes it for good once TrashRetention has passed;
+// ClearAll empties the trash along with the history.
+
+// TrashRetention is how long deleted items stay restorable
+const TrashRetention = 24 * time.Hour
+
+// trashItem marks an item as deleted (caller must hold lock)
+func (db *Database) trashItem(item *ClipboardItem) {
+	item.Deleted = true
+	item.DeletedAt = db.now()
+}
+
+// RestoreItem takes an item back out of the trash
+// A pinned item comes back unpinned if the pin limit filled up in the meantime. If the
+// history filled up instead, room is made as for a capture: the oldest unpinned item
+// goes, or with LimitReject the item stays in the trash and a RejectError is returned
+func (db *Database) RestoreItem(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	index := db.trashIndex(id)
+	if index < 0 {
+		return fmt.Errorf("item not found in trash")
+	}
+	pinned := db.Items[index].Pinned && db.countItems().Pinned < db.pinLimit
+	if err := db.makeRoom(pinned, db.Items[index].Size); err != nil {
+		return err
+	}
+
+	// Evicting rebuilds the item list
+	item := &db.Items[db.trashIndex(id)]
+	item.Pinned = pinned
+	item.Deleted = false
+	item.DeletedAt = time.Time{}
+	db.commit(ChangeRestore, id)
+	db.recordAudit(AuditRestore, *item)
+	return db.scheduleSave()
+}
+
+// trashIndex returns the index of the trashed item with id, -1 if there is none
+// (caller must hold lock)
+func (db *Database) trashIndex(id string) int {
+	for i, item := range db.Items {
+		if item.ID == id && item.Deleted {
+			return i
+		}
+	}
+	return -1
+}
+
+// makeRoom evicts what a restored item of size needs, without touching the trash, so
+// it can't push itself or a newer item out afterwards (caller must hold lock)
+func (db *Database) makeRoom(pinned bool, size int) error {
+	// Items kept over the limit by the grace window are trimmed once they age out
+	db.enforceLimit()
+	if !pinned && db.countItems().IsFull() {
+		if db.limitPolicy == LimitReject || !db.evictOldest() {
+			return &RejectError{Reason: RejectLimitFull, Limit: db.maxItems}
+		}
+	}
+	// The size cap always makes room, as it does for captures
+	for counts := db.countItems(); counts.SizeLimit > 0 && counts.Bytes+int64(size) > counts.SizeLimit; counts = db.countItems() {
+		if !db.evictOldest() {
+			break
+		}
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes items deleted more than olderThan ago and returns
+// how many went; 0 empties the whole trash
+func (db *Database) PurgeDeleted(olderThan time.Duration) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// A history that failed to load isn't in memory; purging it would save over the file
+	if db.loadErr != nil {
+		return 0, nil
+	}
+
+	cutoff := db.now().Add(-olderThan)
+	purge := make(map[string]bool)
+	for _, item := range db.Items {
+		if item.Deleted && !item.DeletedAt.After(cutoff) {
+			purge[item.ID] = true
+		}
+	}
+	if len(purge) == 0 {
+		return 0, nil
+	}
+
+	// Delta items that stay need their full pixels before their base goes away
+	for i := range db.Items {
+		item := &db.Items[i]
+		if item.Delta != nil && purge[item.Delta.BaseID] && !purge[item.ID] {
+			if err := db.materializeItem(item); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	kept := make([]ClipboardItem, 0, len(db.Items)-len(purge))
+	ids := make([]string, 0, len(purge))
+	for _, item := range db.Items {
+		if purge[item.ID] {
+			ids = append(ids, item.ID)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	db.Items = kept
+	db.commit(ChangeEvict, ids...)
+	return len(ids), db.scheduleSave()
+}
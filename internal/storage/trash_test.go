@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestRestoreAtLimit restores the oldest item after its slot was taken: with
+// LimitEvictOldest the next oldest makes room instead of the restored item itself,
+// with LimitReject nothing is evicted and the item stays in the trash
+func TestRestoreAtLimit(t *testing.T) {
+	full := numbered("a", 10)
+	slices.Reverse(full) // Newest first, as the history lists them
+	tests := []struct {
+		policy LimitPolicy
+		want   []string
+		reject bool
+	}{
+		{LimitEvictOldest, append(append([]string{"yeni"}, full[:8]...), "a 1"), false},
+		{LimitReject, append([]string{"yeni"}, full[:9]...), true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			db := newTestDB(t)
+			db.SetGraceWindow(0)
+			db.SetMaxItems(10)
+			db.SetLimitPolicy(tt.policy)
+			addTexts(t, db, numbered("a", 10)...)
+			oldest := db.GetAllItems()[9].ID
+			if err := db.DeleteItem(oldest); err != nil {
+				t.Fatalf("failed to delete: %v", err)
+			}
+			addTexts(t, db, "yeni")
+
+			err := db.RestoreItem(oldest)
+			var reject *RejectError
+			if tt.reject != (errors.As(err, &reject) && reject.Reason == RejectLimitFull) || !tt.reject && err != nil {
+				t.Fatalf("restore gave %v", err)
+			}
+			if got := historyTexts(t, db); !slices.Equal(got, tt.want) {
+				t.Errorf("history is %q, want %q", got, tt.want)
+			}
+			if counts := db.Counts(); counts.Active != 10 || counts.Trashed != btoi(tt.reject) {
+				t.Errorf("counts are %+v", counts)
+			}
+			if tt.reject {
+				// Still restorable once there is room
+				if err := db.DeleteItem(db.GetAllItems()[0].ID); err != nil {
+					t.Fatalf("failed to delete: %v", err)
+				}
+				if err := db.RestoreItem(oldest); err != nil {
+					t.Errorf("failed to restore with room: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// btoi returns 1 for true
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// A restored item that would go over the size cap makes room by evicting the oldest
+// other items, never itself
+func TestRestoreOverSize(t *testing.T) {
+	db := newTestDB(t)
+	db.SetGraceWindow(0)
+	addTexts(t, db, "eski", "bir", "iki")
+	oldest := db.GetAllItems()[2].ID
+	if err := db.DeleteItem(oldest); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	addTexts(t, db, "üç")
+	db.SetMaxTotalSize(int64(db.Counts().Bytes) + 1) // "eski" no longer fits beside the rest
+
+	if err := db.RestoreItem(oldest); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	got := historyTexts(t, db)
+	if !slices.Contains(got, "eski") || slices.Contains(got, "bir") {
+		t.Errorf("history is %q, want eski restored and bir evicted", got)
+	}
+	if counts := db.Counts(); counts.IsOverSize() {
+		t.Errorf("history is over its cap: %+v", counts)
+	}
+}
+
+// A pinned item restored after the pin limit filled up comes back unpinned
+func TestRestorePinned(t *testing.T) {
+	db := newTestDB(t)
+	db.SetPinLimit(1)
+	addTexts(t, db, "bir", "iki")
+	items := db.GetAllItems() // iki, bir
+	if err := db.TogglePin(items[1].ID); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := db.DeleteItem(items[1].ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err := db.TogglePin(items[0].ID); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+
+	if err := db.RestoreItem(items[1].ID); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if counts := db.Counts(); counts.Pinned != 1 || counts.Active != 1 {
+		t.Errorf("counts are %+v, want the restored item unpinned", counts)
+	}
+	if err := db.RestoreItem(items[1].ID); err == nil {
+		t.Error("restored an item that isn't in the trash")
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultTrackingParams lists query parameters removed from copied URLs
+// A trailing "*" matches any parameter with that prefix
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid",
+	"mc_cid", "mc_eid", "igshid", "yclid", "_hsenc", "_hsmi", "mkt_tok",
+	"ref_src", "oly_anon_id", "oly_enc_id", "vero_id", "wickedid",
+}
+
+// isURL reports whether text is a single http(s) URL
+func isURL(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, " \t\r\n") {
+		return false
+	}
+	u, err := url.Parse(text)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// matchesTrackingParam reports whether a decoded parameter name is in the list
+func matchesTrackingParam(name string, params []string) bool {
+	name = strings.ToLower(name)
+	for _, p := range params {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanURL removes tracking parameters from an http(s) URL
+// The remaining query keeps its original order and encoding, and the fragment is left intact.
+// Text that is not a URL, or has none of the parameters, is returned unchanged.
+func CleanURL(text string, params []string) string {
+	trimmed := strings.TrimSpace(text)
+	if !isURL(trimmed) {
+		return text
+	}
+
+	// Split off the fragment first so a "?" inside it is never treated as a query
+	base, fragment, hasFragment := strings.Cut(trimmed, "#")
+	path, query, hasQuery := strings.Cut(base, "?")
+	if !hasQuery {
+		return text
+	}
+
+	kept := make([]string, 0)
+	removed := false
+	for _, part := range strings.Split(query, "&") {
+		if part == "" {
+			continue
+		}
+		rawName, _, _ := strings.Cut(part, "=")
+		name, err := url.QueryUnescape(rawName)
+		if err != nil {
+			name = rawName
+		}
+		if matchesTrackingParam(name, params) {
+			removed = true
+			continue
+		}
+		kept = append(kept, part)
+	}
+	if !removed {
+		return text
+	}
+
+	result := path
+	if len(kept) > 0 {
+		result += "?" + strings.Join(kept, "&")
+	}
+	if hasFragment {
+		result += "#" + fragment
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package storage
+
+import "testing"
+
+func TestCleanURL(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"no query", "https://ornek.com/yazi", "https://ornek.com/yazi"},
+		{"nothing to strip", "https://ornek.com/ara?q=pano&sayfa=2", "https://ornek.com/ara?q=pano&sayfa=2"},
+		{
+			"nothing to strip keeps the text as copied",
+			"  https://ornek.com/ara?q=pano&&sayfa=2&\n",
+			"  https://ornek.com/ara?q=pano&&sayfa=2&\n",
+		},
+		{"not a URL", "utm_source=x ve fbclid=y", "utm_source=x ve fbclid=y"},
+		{"other scheme", "ftp://ornek.com/?utm_source=x", "ftp://ornek.com/?utm_source=x"},
+		{"only tracking", "https://ornek.com/?utm_source=x&fbclid=y", "https://ornek.com/"},
+		{"mixed", "https://ornek.com/a?id=7&utm_medium=mail&b=2", "https://ornek.com/a?id=7&b=2"},
+		{"prefix pattern", "https://ornek.com/?utm_whatever=1&utm=2", "https://ornek.com/?utm=2"},
+		{"name case", "https://ornek.com/?UTM_Source=x&FBCLID=y&k=1", "https://ornek.com/?k=1"},
+		{"surrounding space", "  https://ornek.com/?gclid=1&k=1 ", "https://ornek.com/?k=1"},
+
+		{"fragment kept", "https://ornek.com/?utm_source=x#bolum-2", "https://ornek.com/#bolum-2"},
+		{"query-like fragment", "https://ornek.com/?k=1&fbclid=y#/yol?utm_source=z", "https://ornek.com/?k=1#/yol?utm_source=z"},
+		{"fragment without query", "https://ornek.com/#?utm_source=x", "https://ornek.com/#?utm_source=x"},
+		{"empty fragment", "https://ornek.com/?gclid=1#", "https://ornek.com/#"},
+
+		{"repeated tracking", "https://ornek.com/?utm_source=a&k=1&utm_source=b", "https://ornek.com/?k=1"},
+		{"repeated kept", "https://ornek.com/?etiket=a&fbclid=1&etiket=b&etiket=a", "https://ornek.com/?etiket=a&etiket=b&etiket=a"},
+		{"empty parts dropped", "https://ornek.com/?k=1&&fbclid=2&", "https://ornek.com/?k=1"},
+		{"no value", "https://ornek.com/?fbclid&k", "https://ornek.com/?k"},
+
+		{"encoded name", "https://ornek.com/?utm%5Fsource=x&k=1", "https://ornek.com/?k=1"},
+		{"encoded values kept", "https://ornek.com/?q=%C5%9Fi%C5%9F+kebap&gclid=1&y=a%26b", "https://ornek.com/?q=%C5%9Fi%C5%9F+kebap&y=a%26b"},
+		{"invalid escape kept", "https://ornek.com/?q=%zz&msclkid=1", "https://ornek.com/?q=%zz"},
+		{"plus in name", "https://ornek.com/?utm+source=1&fbclid=2", "https://ornek.com/?utm+source=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CleanURL(tt.text, DefaultTrackingParams); got != tt.want {
+				t.Errorf("CleanURL(%q)\n got %q\nwant %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrAppBarUnsupported is returned by Dock where the shell has no app bars
+var ErrAppBarUnsupported = errors.New("docking is not supported on this platform")
+
+// AppBar docks a window to the right edge of its monitor as a shell app bar: the shell
+// reserves the column, so maximized windows end where it begins
+// The bar follows resolution, work area and DPI changes until Undock
+type AppBar struct {
+	mu     sync.Mutex
+	docked bool
+	stop   func() // Platform-specific removal, set while docked
+}
+
+// NewAppBar creates an undocked app bar
+func NewAppBar() *AppBar {
+	return &AppBar{}
+}
+
+// Dock registers hwnd as an app bar width device-independent pixels wide and moves it
+// into the reserved column, spanning the work area height
+func (b *AppBar) Dock(hwnd uintptr, width int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.docked {
+		return fmt.Errorf("app bar already docked")
+	}
+	if hwnd == 0 {
+		return fmt.Errorf("no window to dock")
+	}
+
+	stop, err := dockAppBar(hwnd, width)
+	if err != nil {
+		return err
+	}
+	b.stop = stop
+	b.docked = true
+	return nil
+}
+
+// Undock gives the reserved column back to the shell and takes the window off the top;
+// it stays where it is
+func (b *AppBar) Undock() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.docked {
+		return
+	}
+	b.docked = false
+	b.stop()
+	b.stop = nil
+}
+
+// Supported reports whether the platform's shell has app bars
+func (b *AppBar) Supported() bool {
+	return appBarSupported
+}
+
+// Docked reports whether the bar is registered with the shell
+func (b *AppBar) Docked() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.docked
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+// dockAppBar is unavailable on non-Windows platforms
+func dockAppBar(hwnd uintptr, width int) (func(), error) {
+	return nil, ErrAppBarUnsupported
+}
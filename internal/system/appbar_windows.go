@@ -0,0 +1,205 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	procSHAppBarMessage = shell32.NewProc("SHAppBarMessage")
+	procSetWindowPos    = user32.NewProc("SetWindowPos")
+	procGetDpiForWindow = user32.NewProc("GetDpiForWindow")
+	procIsWindow        = user32.NewProc("IsWindow")
+	procShowWindowAsync = user32.NewProc("ShowWindowAsync")
+)
+
+const (
+	abmNew      = 0x0
+	abmRemove   = 0x1
+	abmQueryPos = 0x2
+	abmSetPos   = 0x3
+
+	abnPosChanged    = 0x1
+	abnFullScreenApp = 0x2
+	abeRight         = 2
+	wmAppBarCallback = 0x8000 + 1 // WM_APP + 1
+	wmSettingChange  = 0x001A     // Work area and scale changes
+	wmDisplayChange  = 0x007E     // Resolution changes
+
+	swpNoSize           = 0x0001
+	swpNoMove           = 0x0002
+	swpNoActivate       = 0x0010
+	swpAsyncWindowPos   = 0x4000
+	hwndTopmost         = ^uintptr(0) // HWND_TOPMOST, (HWND)-1
+	hwndNoTopmost       = ^uintptr(1) // HWND_NOTOPMOST, (HWND)-2
+	swRestore           = 9
+	appBarWindowClass   = "PanoAppBar"
+	appBarRemoveTimeout = 2 * time.Second
+)
+
+// appBarData mirrors APPBARDATA
+type appBarData struct {
+	Size            uint32
+	Hwnd            uintptr
+	CallbackMessage uint32
+	Edge            uint32
+	Rect            rect
+	LParam          uintptr
+}
+
+// dockAppBar registers a hidden window of its own as the app bar and keeps target in
+// the column the shell reserves for it
+// The shell talks to the bar window (position changes, full-screen apps), and the
+// window also sees WM_DISPLAYCHANGE, which a message-only window wouldn't; target is
+// only ever moved, asynchronously, so the UI thread is never waited on
+//
+// Manual check: dock, maximize another window (it stops at the column), change the
+// resolution and the scale in Display settings (the column follows), start a full-screen
+// video (it covers the column), then undock (maximized windows get the space back)
+func dockAppBar(target uintptr, width int) (func(), error) {
+	ready := make(chan error, 1)
+	hwndCh := make(chan uintptr, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		// The window and its message loop must stay on one OS thread
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		abd := appBarData{CallbackMessage: wmAppBarCallback, Edge: abeRight}
+		abd.Size = uint32(unsafe.Sizeof(abd))
+
+		wndProc := syscall.NewCallback(func(hwnd, message, wParam, lParam uintptr) uintptr {
+			switch message {
+			case wmAppBarCallback:
+				switch wParam {
+				case abnPosChanged:
+					placeAppBar(&abd, target, width)
+				case abnFullScreenApp:
+					// A full-screen app may cover the bar; it is topmost again afterwards
+					after := hwndTopmost
+					if lParam != 0 {
+						after = hwndNoTopmost
+					}
+					procSetWindowPos.Call(target, after, 0, 0, 0, 0, swpNoMove|swpNoSize|swpNoActivate|swpAsyncWindowPos)
+				}
+				return 0
+			case wmDisplayChange, wmSettingChange:
+				placeAppBar(&abd, target, width)
+				return 0
+			case wmDestroy:
+				procSHAppBarMessage.Call(abmRemove, uintptr(unsafe.Pointer(&abd)))
+				procSetWindowPos.Call(target, hwndNoTopmost, 0, 0, 0, 0, swpNoMove|swpNoSize|swpNoActivate|swpAsyncWindowPos)
+				procPostQuitMessage.Call(0)
+				return 0
+			}
+			ret, _, _ := procDefWindowProcW.Call(hwnd, message, wParam, lParam)
+			return ret
+		})
+
+		className, _ := syscall.UTF16PtrFromString(appBarWindowClass)
+		wc := wndClassEx{
+			WndProc:   wndProc,
+			ClassName: className,
+		}
+		wc.Size = uint32(unsafe.Sizeof(wc))
+		procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc))) // Fails harmlessly if already registered
+
+		// A top-level window that is never shown: broadcasts reach it, the user never does
+		hwnd, _, err := procCreateWindowExW.Call(
+			0,
+			uintptr(unsafe.Pointer(className)),
+			0,
+			0, 0, 0, 0, 0,
+			0,
+			0, 0, 0,
+		)
+		if hwnd == 0 {
+			ready <- fmt.Errorf("failed to create app bar window: %v", err)
+			return
+		}
+
+		abd.Hwnd = hwnd
+		if ret, _, _ := procSHAppBarMessage.Call(abmNew, uintptr(unsafe.Pointer(&abd))); ret == 0 {
+			procDestroyWindow.Call(hwnd)
+			ready <- fmt.Errorf("failed to register app bar")
+			return
+		}
+		// A maximized window would ignore the move
+		procShowWindowAsync.Call(target, swRestore)
+		placeAppBar(&abd, target, width)
+
+		hwndCh <- hwnd
+		ready <- nil
+
+		var m msg
+		for {
+			ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+			if int32(ret) <= 0 {
+				return
+			}
+			procDispatchMessageW.Call(uintptr(unsafe.Pointer(&m)))
+		}
+	}()
+
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+	hwnd := <-hwndCh
+
+	stop := func() {
+		// WM_CLOSE runs DestroyWindow on the window's own thread, which removes the bar;
+		// waiting keeps the column from staying reserved when the process exits right after
+		procPostMessageW.Call(hwnd, wmClose, 0, 0)
+		select {
+		case <-done:
+		case <-time.After(appBarRemoveTimeout):
+		}
+	}
+	return stop, nil
+}
+
+// placeAppBar reserves the column on the right edge of target's monitor and moves
+// target into it (called on the bar window's thread)
+// Width is scaled with target's DPI, so the column keeps its size on every monitor.
+// Setting the same position again doesn't change the work area, so the settings
+// change it may cause ends here instead of looping
+func placeAppBar(abd *appBarData, target uintptr, width int) {
+	if ret, _, _ := procIsWindow.Call(target); ret == 0 {
+		return
+	}
+	monitor, _, _ := procMonitorFromWindow.Call(target, monitorDefaultToNearest)
+	var mi monitorInfo
+	mi.Size = uint32(unsafe.Sizeof(mi))
+	if ret, _, _ := procGetMonitorInfoW.Call(monitor, uintptr(unsafe.Pointer(&mi))); ret == 0 {
+		return
+	}
+
+	dpi := uintptr(96)
+	if procGetDpiForWindow.Find() == nil {
+		if d, _, _ := procGetDpiForWindow.Call(target); d != 0 {
+			dpi = d
+		}
+	}
+	px := int32(uintptr(width) * dpi / 96)
+
+	// The shell may move the edge in, e.g. for a taskbar on the right
+	abd.Rect = rect{Left: mi.Monitor.Right - px, Top: mi.Monitor.Top, Right: mi.Monitor.Right, Bottom: mi.Monitor.Bottom}
+	procSHAppBarMessage.Call(abmQueryPos, uintptr(unsafe.Pointer(abd)))
+	abd.Rect.Left = abd.Rect.Right - px
+	procSHAppBarMessage.Call(abmSetPos, uintptr(unsafe.Pointer(abd)))
+
+	// The work area no longer includes the column; its height is what the window spans
+	procGetMonitorInfoW.Call(monitor, uintptr(unsafe.Pointer(&mi)))
+	procSetWindowPos.Call(target, hwndTopmost,
+		uintptr(abd.Rect.Left), uintptr(mi.Work.Top),
+		uintptr(px), uintptr(mi.Work.Bottom-mi.Work.Top),
+		swpNoActivate|swpAsyncWindowPos)
+}
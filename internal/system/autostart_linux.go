@@ -0,0 +1,68 @@
+//go:build linux
+// +build linux
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const autostartSupported = true
+
+// autostartEntry returns the path of the XDG autostart entry
+func autostartEntry() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "autostart", "pano.desktop"), nil
+}
+
+// IsEnabled checks if autostart is enabled
+func (a *AutostartManager) IsEnabled() (bool, error) {
+	path, err := autostartEntry()
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Enable writes the autostart entry for the current user
+func (a *AutostartManager) Enable() error {
+	path, err := autostartEntry()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create autostart directory: %w", err)
+	}
+
+	// Exec takes a quoted path; quotes and backslashes inside it are escaped
+	exe := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(filepath.Clean(a.exePath))
+	entry := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=%s\nExec=\"%s\"\nX-GNOME-Autostart-enabled=true\n", appName, exe)
+	if err := os.WriteFile(path, []byte(entry), 0644); err != nil {
+		return fmt.Errorf("failed to write autostart entry: %w", err)
+	}
+	return nil
+}
+
+// Disable removes the autostart entry
+func (a *AutostartManager) Disable() error {
+	path, err := autostartEntry()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove autostart entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build linux
+// +build linux
+
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAutostart toggles the XDG autostart entry of an executable whose path needs
+// escaping, and checks the entry appears and goes away
+func TestAutostart(t *testing.T) {
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	entry := filepath.Join(config, "autostart", "pano.desktop")
+	a := &AutostartManager{exePath: `/opt/Pano "beta"/$HOME/pano`}
+
+	if enabled, err := a.IsEnabled(); err != nil || enabled {
+		t.Fatalf("enabled before any toggle: %v, %v", enabled, err)
+	}
+	if err := a.Toggle(); err != nil {
+		t.Fatalf("failed to enable: %v", err)
+	}
+	if enabled, err := a.IsEnabled(); err != nil || !enabled {
+		t.Errorf("not enabled after a toggle: %v, %v", enabled, err)
+	}
+	data, err := os.ReadFile(entry)
+	if err != nil {
+		t.Fatalf("failed to read the entry: %v", err)
+	}
+	if want := "Exec=\"/opt/Pano \\\"beta\\\"/\\$HOME/pano\"\n"; !strings.Contains(string(data), want) {
+		t.Errorf("entry is\n%s\nwant the line %q", data, want)
+	}
+	if !strings.HasPrefix(string(data), "[Desktop Entry]\n") {
+		t.Errorf("entry doesn't start with its group header:\n%s", data)
+	}
+
+	if err := a.Toggle(); err != nil {
+		t.Fatalf("failed to disable: %v", err)
+	}
+	if _, err := os.Stat(entry); !os.IsNotExist(err) {
+		t.Errorf("the entry is still there: %v", err)
+	}
+	if err := a.Disable(); err != nil {
+		t.Errorf("disabling twice failed: %v", err)
+	}
+}
+
+// Only autostart is offered off Windows; the rest of the integrations are hidden
+func TestPlatformFeatures(t *testing.T) {
+	want := Features{Autostart: true}
+	if got := PlatformFeatures(); got != want {
+		t.Errorf("features are %+v, want %+v", got, want)
+	}
+}
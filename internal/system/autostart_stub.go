@@ -0,0 +1,26 @@
+//go:build !windows && !linux
+// +build !windows,!linux
+
+package system
+
+import "errors"
+
+const autostartSupported = false
+
+// errAutostartUnsupported is returned where Pano doesn't know how to start with the session
+var errAutostartUnsupported = errors.New("autostart is not supported on this platform")
+
+// IsEnabled always reports false where autostart is unsupported
+func (a *AutostartManager) IsEnabled() (bool, error) {
+	return false, nil
+}
+
+// Enable is not supported on this platform
+func (a *AutostartManager) Enable() error {
+	return errAutostartUnsupported
+}
+
+// Disable has nothing to remove on this platform
+func (a *AutostartManager) Disable() error {
+	return nil
+}
@@ -0,0 +1,41 @@
+package system
+
+import (
+	"sort"
+	"strings"
+)
+
+// knownClipboardManagers are executables of clipboard tools that set the clipboard
+// themselves, by lower-case name, with the name shown in diagnostics
+var knownClipboardManagers = map[string]string{
+	"ditto.exe":              "Ditto",
+	"copyq.exe":              "CopyQ",
+	"clipclip.exe":           "ClipClip",
+	"clipboardfusion.exe":    "ClipboardFusion",
+	"arsclip.exe":            "ArsClip",
+	"clipangel.exe":          "ClipAngel",
+	"clipdiary.exe":          "Clipdiary",
+	"clibor.exe":             "Clibor",
+	"1clipboard.exe":         "1Clipboard",
+	"clipmate.exe":           "ClipMate",
+	"pastecopy.net.exe":      "PasteCopy.NET",
+	"clipboardmaster.exe":    "Clipboard Master",
+	"rcclipboardmanager.exe": "RC Clipboard Manager",
+}
+
+// RunningClipboardManagers returns the known clipboard managers running now, sorted
+// Empty where processes can't be listed
+func RunningClipboardManagers() []string {
+	found := make(map[string]bool)
+	for _, exe := range listProcessNames() {
+		if name, ok := knownClipboardManagers[strings.ToLower(exe)]; ok {
+			found[name] = true
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+// listProcessNames lists nothing on non-Windows platforms
+func listProcessNames() []string {
+	return nil
+}
@@ -0,0 +1,22 @@
+package system
+
+// Features tells which platform integrations work in this build, so the UI can hide the
+// settings of the rest instead of offering switches that do nothing
+type Features struct {
+	Autostart        bool // Starting with the session, see AutostartManager
+	ShellMenu        bool // File manager entries that send files to Pano, see ShellMenuManager
+	Dock             bool // Docking the window as a sidebar, see AppBar
+	GameMode         bool // Spotting full-screen apps and quiet hours, see GameModeWatcher
+	SelectionCapture bool // Copying text selected with the mouse, see SelectionWatcher
+}
+
+// PlatformFeatures returns the features of the platform Pano was built for
+func PlatformFeatures() Features {
+	return Features{
+		Autostart:        autostartSupported,
+		ShellMenu:        shellMenuSupported,
+		Dock:             appBarSupported,
+		GameMode:         gameModeSupported,
+		SelectionCapture: selectionSupported,
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+// The shell, full-screen and input integrations are Windows-only; their stubs do nothing
+const (
+	shellMenuSupported = false
+	appBarSupported    = false
+	gameModeSupported  = false
+	selectionSupported = false
+)
+
+// Raw codes are X11 keysyms or macOS key codes, see isVirtualKey
+const rawcodesAreVirtualKeys = false
@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+package system
+
+// Windows has every integration
+const (
+	shellMenuSupported = true
+	appBarSupported    = true
+	gameModeSupported  = true
+	selectionSupported = true
+)
+
+// The hook reports virtual key codes as raw codes, see isVirtualKey
+const rawcodesAreVirtualKeys = true
@@ -0,0 +1,116 @@
+package system
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GameModeCheckInterval is how often the foreground window is checked for full screen
+const GameModeCheckInterval = 5 * time.Second
+
+// NotificationsMuted reports whether the user doesn't want to be disturbed right now
+// (Focus Assist, presentation mode or a full-screen application)
+func NotificationsMuted() bool {
+	return probeQuiet()
+}
+
+// FullscreenProber reports whether a full-screen application has the foreground
+type FullscreenProber func() bool
+
+// GameModeWatcher reports when a full-screen game (or other exclusive full-screen
+// application) starts and stops, so Pano can back off while it runs
+type GameModeWatcher struct {
+	prober   FullscreenProber
+	interval time.Duration
+	callback func(active bool)
+	active   bool
+	running  bool
+	stop     chan struct{}
+	mu       sync.Mutex
+}
+
+// NewGameModeWatcher creates a watcher using the platform's full-screen detection
+func NewGameModeWatcher() *GameModeWatcher {
+	return NewGameModeWatcherWithProber(probeFullscreen, GameModeCheckInterval)
+}
+
+// NewGameModeWatcherWithProber creates a watcher with a custom prober and interval
+func NewGameModeWatcherWithProber(prober FullscreenProber, interval time.Duration) *GameModeWatcher {
+	return &GameModeWatcher{
+		prober:   prober,
+		interval: interval,
+	}
+}
+
+// SetCallback sets the function called when game mode turns on or off
+func (g *GameModeWatcher) SetCallback(callback func(active bool)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.callback = callback
+}
+
+// IsActive returns whether a full-screen application was seen on the last check
+func (g *GameModeWatcher) IsActive() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.active
+}
+
+// Start begins checking on the interval
+func (g *GameModeWatcher) Start() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running {
+		return fmt.Errorf("game mode watcher already running")
+	}
+	g.running = true
+	g.stop = make(chan struct{})
+	go g.loop(g.stop)
+	return nil
+}
+
+// Stop stops checking; the callback isn't called again
+func (g *GameModeWatcher) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.running {
+		return
+	}
+	g.running = false
+	close(g.stop)
+}
+
+func (g *GameModeWatcher) loop(stop chan struct{}) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			g.Check()
+		}
+	}
+}
+
+// Check probes once and reports a change to the callback
+func (g *GameModeWatcher) Check() {
+	g.set(g.prober())
+}
+
+// set records the state and calls the callback when it changed
+func (g *GameModeWatcher) set(active bool) {
+	g.mu.Lock()
+	if active == g.active {
+		g.mu.Unlock()
+		return
+	}
+	g.active = active
+	callback := g.callback
+	g.mu.Unlock()
+
+	if callback != nil {
+		callback(active)
+	}
+}
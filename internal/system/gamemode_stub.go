@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+// probeQuiet never reports quiet time on non-Windows platforms
+func probeQuiet() bool {
+	return false
+}
+
+// probeFullscreen never reports a full-screen application on non-Windows platforms
+func probeFullscreen() bool {
+	return false
+}
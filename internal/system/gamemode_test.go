@@ -0,0 +1,96 @@
+package system
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFullscreen is a full-screen prober the test switches
+type fakeFullscreen struct {
+	mu     sync.Mutex
+	on     bool
+	probes int
+}
+
+func (f *fakeFullscreen) set(on bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.on = on
+}
+
+func (f *fakeFullscreen) probe() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.probes++
+	return f.on
+}
+
+// TestGameModeWatcherCheck reports each start and exit of a full-screen app once,
+// however often it is probed in between
+func TestGameModeWatcherCheck(t *testing.T) {
+	fake := &fakeFullscreen{}
+	watcher := NewGameModeWatcherWithProber(fake.probe, time.Hour)
+	var got []bool
+	watcher.SetCallback(func(active bool) { got = append(got, active) })
+
+	watcher.Check()
+	fake.set(true)
+	watcher.Check()
+	watcher.Check()
+	if !watcher.IsActive() {
+		t.Error("not active while a full-screen app runs")
+	}
+	fake.set(false)
+	watcher.Check()
+	watcher.Check()
+	if watcher.IsActive() {
+		t.Error("still active after the full-screen app exited")
+	}
+	if !slices.Equal(got, []bool{true, false}) {
+		t.Errorf("callback got %v, want [true false]", got)
+	}
+}
+
+// TestGameModeWatcherLoop checks on the interval once started, refuses a second start
+// and calls back no more after Stop
+func TestGameModeWatcherLoop(t *testing.T) {
+	fake := &fakeFullscreen{on: true}
+	watcher := NewGameModeWatcherWithProber(fake.probe, time.Millisecond)
+	changes := make(chan bool, 4)
+	watcher.SetCallback(func(active bool) { changes <- active })
+
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	if err := watcher.Start(); err == nil {
+		t.Error("a running watcher started again")
+	}
+	select {
+	case active := <-changes:
+		if !active {
+			t.Error("the first change turned game mode off")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no check on the interval")
+	}
+	watcher.Stop()
+	watcher.Stop() // Stopping twice is harmless
+
+	// A probe that was under way may still finish; nothing after it
+	time.Sleep(10 * time.Millisecond)
+	fake.mu.Lock()
+	probes := fake.probes
+	fake.mu.Unlock()
+	fake.set(false)
+	time.Sleep(20 * time.Millisecond)
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.probes != probes {
+		t.Errorf("%d probes after Stop", fake.probes-probes)
+	}
+	if len(changes) != 0 {
+		t.Errorf("callback got %v after Stop", <-changes)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	shell32                          = windows.NewLazySystemDLL("shell32.dll")
+	procSHQueryUserNotificationState = shell32.NewProc("SHQueryUserNotificationState")
+	procGetForegroundWindow          = user32.NewProc("GetForegroundWindow")
+	procGetWindowRect                = user32.NewProc("GetWindowRect")
+	procMonitorFromWindow            = user32.NewProc("MonitorFromWindow")
+	procGetMonitorInfoW              = user32.NewProc("GetMonitorInfoW")
+	procGetClassNameW                = user32.NewProc("GetClassNameW")
+)
+
+const (
+	qunsRunningD3DFullScreen = 3 // A Direct3D application is running in exclusive mode
+	qunsAcceptsNotifications = 5 // Anything else means busy, presenting or Focus Assist quiet time
+	monitorDefaultToNearest  = 2
+)
+
+// rect mirrors RECT
+type rect struct {
+	Left, Top, Right, Bottom int32
+}
+
+// monitorInfo mirrors MONITORINFO
+type monitorInfo struct {
+	Size    uint32
+	Monitor rect
+	Work    rect
+	Flags   uint32
+}
+
+// desktopClasses are shell windows that cover the monitor without being full-screen apps
+var desktopClasses = map[string]bool{"Progman": true, "WorkerW": true, "Shell_TrayWnd": true}
+
+// probeQuiet reports whether Windows is holding notifications back
+func probeQuiet() bool {
+	var state int32
+	if ret, _, _ := procSHQueryUserNotificationState.Call(uintptr(unsafe.Pointer(&state))); ret != 0 {
+		return false
+	}
+	return state != qunsAcceptsNotifications
+}
+
+// probeFullscreen reports an exclusive Direct3D application, or a foreground window
+// covering its whole monitor (borderless full screen)
+func probeFullscreen() bool {
+	var state int32
+	if ret, _, _ := procSHQueryUserNotificationState.Call(uintptr(unsafe.Pointer(&state))); ret == 0 && state == qunsRunningD3DFullScreen {
+		return true
+	}
+
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return false
+	}
+
+	className := make([]uint16, 256)
+	if n, _, _ := procGetClassNameW.Call(hwnd, uintptr(unsafe.Pointer(&className[0])), uintptr(len(className))); n > 0 {
+		if desktopClasses[syscall.UTF16ToString(className[:n])] {
+			return false
+		}
+	}
+
+	var window rect
+	if ret, _, _ := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&window))); ret == 0 {
+		return false
+	}
+	monitor, _, _ := procMonitorFromWindow.Call(hwnd, monitorDefaultToNearest)
+	info := monitorInfo{}
+	info.Size = uint32(unsafe.Sizeof(info))
+	if ret, _, _ := procGetMonitorInfoW.Call(monitor, uintptr(unsafe.Pointer(&info))); ret == 0 {
+		return false
+	}
+	return window == info.Monitor
+}
@@ -2,12 +2,17 @@ package system
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	hook "github.com/robotn/gohook"
 )
 
-// Key codes for Windows
+// Key codes: the scan codes are libuiohook's key codes (Event.Keycode) on every platform,
+// the virtual key codes are the raw codes (Event.Rawcode) on Windows only
 const (
 	// Ctrl key codes (scan codes and virtual key codes)
 	scCtrlLeft   = 29
@@ -26,22 +31,62 @@ const (
 	// V key codes
 	scV = 47
 	vkV = 86
+
+	// Space key codes
+	scSpace = 57
+	vkSpace = 32
 )
 
+// hotkeyCloseTimeout bounds how long Close waits for the listener to return
+const hotkeyCloseTimeout = 2 * time.Second
+
+// DefaultCaptureKey is the letter of the capture hotkey (Ctrl+Shift+S)
+const DefaultCaptureKey = "S"
+
+// PauseKey is the letter that, with Ctrl+Shift, pauses or resumes capture
+const PauseKey = "P"
+
+// letterScanCodes maps letters to their set 1 scan codes (virtual key codes equal the ASCII letter)
+var letterScanCodes = map[rune]uint16{
+	'Q': 16, 'W': 17, 'E': 18, 'R': 19, 'T': 20, 'Y': 21, 'U': 22, 'I': 23, 'O': 24, 'P': 25,
+	'A': 30, 'S': 31, 'D': 32, 'F': 33, 'G': 34, 'H': 35, 'J': 36, 'K': 37, 'L': 38,
+	'Z': 44, 'X': 45, 'C': 46, 'V': 47, 'B': 48, 'N': 49, 'M': 50,
+}
+
 // HotkeyManager handles global hotkey registration
 type HotkeyManager struct {
-	callback func()
-	running  bool
-	mu       sync.Mutex
+	callback        func()
+	captureCallback func() // Ctrl+Shift+<captureKey>
+	pasteCallback   func() // Ctrl+V in any app; only observed, the paste still happens
+	nextCallback    func() // Ctrl+Shift+Space
+	pauseCallback   func() // Ctrl+Shift+PauseKey
+	inputHandler    func(ev hook.Event)
+	captureKey      rune
+	running         bool
+	closed          bool          // Close was called; the manager can't be started again
+	done            chan struct{} // Closed when the listener goroutine returns
+	lastInput       atomic.Int64  // Unix nanoseconds of the last key press or click, 0 if none yet
+	mu              sync.Mutex
 }
 
 // NewHotkeyManager creates a new hotkey manager
 func NewHotkeyManager() *HotkeyManager {
 	return &HotkeyManager{
-		running: false,
+		captureKey: rune(DefaultCaptureKey[0]),
+		running:    false,
 	}
 }
 
+// LastInput returns when a key was last pressed or a mouse button clicked in any app,
+// zero before the first one or while the hook isn't running
+func (h *HotkeyManager) LastInput() time.Time {
+	nanos := h.lastInput.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
 // SetCallback sets the function to call when hotkey is pressed
 func (h *HotkeyManager) SetCallback(callback func()) {
 	h.mu.Lock()
@@ -49,54 +94,172 @@ func (h *HotkeyManager) SetCallback(callback func()) {
 	h.callback = callback
 }
 
-// Start registers the global hotkey (Ctrl+Shift+V)
+// SetCaptureCallback sets the function to call when the capture hotkey is pressed
+func (h *HotkeyManager) SetCaptureCallback(callback func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.captureCallback = callback
+}
+
+// SetPasteCallback sets the function to call when Ctrl+V is pressed anywhere
+func (h *HotkeyManager) SetPasteCallback(callback func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pasteCallback = callback
+}
+
+// SetNextCallback sets the function to call when Ctrl+Shift+Space is pressed
+func (h *HotkeyManager) SetNextCallback(callback func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextCallback = callback
+}
+
+// SetPauseCallback sets the function to call when Ctrl+Shift+PauseKey is pressed
+func (h *HotkeyManager) SetPauseCallback(callback func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pauseCallback = callback
+}
+
+// SetInputHandler sets a function that sees every key and mouse event of the hook, e.g.
+// SelectionWatcher.HandleEvent; it runs on the listener and must return quickly
+func (h *HotkeyManager) SetInputHandler(handler func(ev hook.Event)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.inputHandler = handler
+}
+
+// SetCaptureKey sets the letter used with Ctrl+Shift for the capture hotkey
+func (h *HotkeyManager) SetCaptureKey(key string) error {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if len(key) != 1 {
+		return fmt.Errorf("invalid capture key: %q", key)
+	}
+	letter := rune(key[0])
+	if _, ok := letterScanCodes[letter]; !ok {
+		return fmt.Errorf("invalid capture key: %q", key)
+	}
+	if letter == 'V' {
+		return fmt.Errorf("Ctrl+Shift+V is reserved for the window hotkey")
+	}
+	if key == SelectionOffKey {
+		return fmt.Errorf("Ctrl+Shift+%s is reserved for turning selection capture off", SelectionOffKey)
+	}
+	if key == PauseKey {
+		return fmt.Errorf("Ctrl+Shift+%s is reserved for pausing capture", PauseKey)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.captureKey = letter
+	return nil
+}
+
+// CaptureKey returns the letter used for the capture hotkey
+func (h *HotkeyManager) CaptureKey() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return string(h.captureKey)
+}
+
+// Start registers the global hotkeys (Ctrl+Shift+V, the capture and pause hotkeys and Ctrl+Shift+Space)
 func (h *HotkeyManager) Start() error {
 	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return fmt.Errorf("hotkey manager is closed")
+	}
 	if h.running {
-		h.mu.Unlock()
 		return fmt.Errorf("hotkey already registered")
 	}
 	h.running = true
-	h.mu.Unlock()
 
-	go h.listenForHotkey()
+	done := make(chan struct{})
+	h.done = done
+	go func() {
+		defer close(done)
+		h.listenForHotkey()
+	}()
 	return nil
 }
 
-// Stop unregisters the global hotkey
+// Stop unregisters the global hotkey; ending the hook also ends the listener
 func (h *HotkeyManager) Stop() {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	if !h.running {
+		return
+	}
 	h.running = false
 	hook.End()
 }
 
-// isCtrlKey checks if the rawcode is a Ctrl key
-func isCtrlKey(rawcode uint16) bool {
-	return rawcode == scCtrlLeft || rawcode == scCtrlRight ||
-		rawcode == vkCtrlLeft || rawcode == vkCtrlRight || rawcode == vkCtrl
+// Close unregisters the hotkeys and waits for the listener to return
+// Closing twice is a no-op
+func (h *HotkeyManager) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	done := h.done
+	h.mu.Unlock()
+
+	h.Stop()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(hotkeyCloseTimeout):
+		return fmt.Errorf("hotkey listener did not stop within %v", hotkeyCloseTimeout)
+	}
+}
+
+// isCtrlKey checks if the event is for a Ctrl key
+func isCtrlKey(ev hook.Event) bool {
+	return ev.Keycode == scCtrlLeft || ev.Keycode == scCtrlRight ||
+		isVirtualKey(ev, vkCtrlLeft, vkCtrlRight, vkCtrl)
+}
+
+// isShiftKey checks if the event is for a Shift key
+func isShiftKey(ev hook.Event) bool {
+	return ev.Keycode == scShiftLeft || ev.Keycode == scShiftRight ||
+		isVirtualKey(ev, vkShiftLeft, vkShiftRight, vkShift)
+}
+
+// isVKey checks if the event is for the V key
+func isVKey(ev hook.Event) bool {
+	return ev.Keycode == scV || isVirtualKey(ev, vkV)
 }
 
-// isShiftKey checks if the rawcode is a Shift key
-func isShiftKey(rawcode uint16) bool {
-	return rawcode == scShiftLeft || rawcode == scShiftRight ||
-		rawcode == vkShiftLeft || rawcode == vkShiftRight || rawcode == vkShift
+// isSpaceKey checks if the event is for the Space key
+func isSpaceKey(ev hook.Event) bool {
+	return ev.Keycode == scSpace || isVirtualKey(ev, vkSpace)
 }
 
-// isVKey checks if the rawcode is the V key
-func isVKey(rawcode uint16) bool {
-	return rawcode == scV || rawcode == vkV
+// isLetterKey checks if the event is for the given letter key
+func isLetterKey(ev hook.Event, letter rune) bool {
+	return ev.Keycode == letterScanCodes[letter] || isVirtualKey(ev, uint16(letter))
 }
 
-// listenForHotkey listens for Ctrl+Shift+V combination
+// isVirtualKey checks the raw code against virtual key codes on Windows; elsewhere raw
+// codes mean something else and only the key code is matched
+func isVirtualKey(ev hook.Event, codes ...uint16) bool {
+	return rawcodesAreVirtualKeys && slices.Contains(codes, ev.Rawcode)
+}
+
+// listenForHotkey listens for Ctrl+Shift+V, the capture and pause combinations, Ctrl+Shift+Space and Ctrl+V
 func (h *HotkeyManager) listenForHotkey() {
 	// Modifier key state tracking
 	ctrlPressed := false
 	shiftPressed := false
 
-	// Create event channel
+	// Create event channel; Stop ends the hook, which closes it
 	evChan := hook.Start()
-	defer hook.End()
 
 	for ev := range evChan {
 		// Check if we should stop
@@ -108,17 +271,28 @@ func (h *HotkeyManager) listenForHotkey() {
 			return
 		}
 
+		if ev.Kind == hook.KeyDown || ev.Kind == hook.MouseDown {
+			h.lastInput.Store(time.Now().UnixNano())
+		}
+
+		h.mu.Lock()
+		inputHandler := h.inputHandler
+		h.mu.Unlock()
+		if inputHandler != nil {
+			inputHandler(ev)
+		}
+
 		if ev.Kind == hook.KeyDown {
 			// Track Ctrl key
-			if isCtrlKey(ev.Rawcode) {
+			if isCtrlKey(ev) {
 				ctrlPressed = true
 			}
 			// Track Shift key
-			if isShiftKey(ev.Rawcode) {
+			if isShiftKey(ev) {
 				shiftPressed = true
 			}
 			// Check for V key with modifiers
-			if isVKey(ev.Rawcode) && ctrlPressed && shiftPressed {
+			if isVKey(ev) && ctrlPressed && shiftPressed {
 				// Ctrl+Shift+V detected - trigger callback
 				h.mu.Lock()
 				callback := h.callback
@@ -127,14 +301,42 @@ func (h *HotkeyManager) listenForHotkey() {
 				if callback != nil {
 					go callback() // Run in goroutine to avoid blocking
 				}
+			} else if isSpaceKey(ev) && ctrlPressed && shiftPressed {
+				h.mu.Lock()
+				nextCallback := h.nextCallback
+				h.mu.Unlock()
+
+				if nextCallback != nil {
+					go nextCallback()
+				}
+			} else if ctrlPressed && shiftPressed {
+				h.mu.Lock()
+				captureKey := h.captureKey
+				captureCallback := h.captureCallback
+				pauseCallback := h.pauseCallback
+				h.mu.Unlock()
+
+				if captureCallback != nil && isLetterKey(ev, captureKey) {
+					go captureCallback()
+				} else if pauseCallback != nil && isLetterKey(ev, rune(PauseKey[0])) {
+					go pauseCallback()
+				}
+			} else if isVKey(ev) && ctrlPressed {
+				h.mu.Lock()
+				pasteCallback := h.pasteCallback
+				h.mu.Unlock()
+
+				if pasteCallback != nil {
+					go pasteCallback()
+				}
 			}
 		} else if ev.Kind == hook.KeyUp {
 			// Reset Ctrl state when Ctrl key is released
-			if isCtrlKey(ev.Rawcode) {
+			if isCtrlKey(ev) {
 				ctrlPressed = false
 			}
 			// Reset Shift state when Shift key is released
-			if isShiftKey(ev.Rawcode) {
+			if isShiftKey(ev) {
 				shiftPressed = false
 			}
 		}
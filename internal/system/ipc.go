@@ -0,0 +1,536 @@
+package system
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pano/internal/storage"
+)
+
+// The running instance listens on a loopback port for commands from the CLI and the
+// Explorer entries, so they never open the database while the GUI holds it. The port is
+// published in IPCFile next to the database; requests must carry the per-install token
+// from IPCTokenFile, which lives in the user's profile and is created owner-only.
+//
+// Each connection carries one JSON request and one JSON response. A request states the
+// protocol version it speaks; the server answers with the version it used, the lower of
+// the two. Requests without a version come from builds before versioning and speak
+// version 1, which only knows "add". IPCClient negotiates with a ping before its first
+// command, so a newer CLI talking to an older instance gets ErrIPCVersion rather than
+// a confusing failure.
+
+// IPCFile holds the address of the running instance
+const IPCFile = "ipc.json"
+
+// IPCTokenFile holds the token that authenticates requests; it is kept across restarts
+const IPCTokenFile = "ipc.token"
+
+// IPCVersion is the newest protocol version this build speaks
+const IPCVersion = 2
+
+// ipcMinVersion is the oldest protocol version the server still accepts
+const ipcMinVersion = 1
+
+const (
+	ipcTimeout      = 5 * time.Second // Per connection, for both sides
+	ipcCloseTimeout = 2 * time.Second // How long Close waits for open connections
+	ipcBusyWait     = time.Second     // How long a request waits for the previous one to finish
+	ipcMaxConns     = 16              // Connections served at once; more are told the server is busy
+
+	// Items are at most 20MB, which JSON escaping of text or base64 of images can inflate
+	ipcMaxRequest  = 64 << 20
+	ipcMaxResponse = 64 << 20
+)
+
+// IPC commands
+const (
+	IPCCommandAdd     = "add"      // Store the files in Paths as items (version 1)
+	IPCCommandPing    = "ping"     // Answer with the server's versions; handled by the server itself
+	IPCCommandList    = "list"     // List items as []IPCItem, newest first, at most Limit if set
+	IPCCommandGet     = "get"      // Return the content of item ID as IPCContent
+	IPCCommandAddText = "add_text" // Store Text as a new item, pinned if Pin is set
+	IPCCommandCopy    = "copy"     // Put item ID on the clipboard
+	IPCCommandDelete  = "delete"   // Delete item ID
+)
+
+// ipcCommandVersions is the protocol version that introduced each command
+var ipcCommandVersions = map[string]int{
+	IPCCommandAdd:     1,
+	IPCCommandPing:    2,
+	IPCCommandList:    2,
+	IPCCommandGet:     2,
+	IPCCommandAddText: 2,
+	IPCCommandCopy:    2,
+	IPCCommandDelete:  2,
+}
+
+// Error codes sent in responses
+const (
+	IPCCodeAuth      = "auth"      // Token missing or wrong
+	IPCCodeVersion   = "version"   // No common protocol version for the request
+	IPCCodeBusy      = "busy"      // The instance is handling other requests; retry later
+	IPCCodeMalformed = "malformed" // The request isn't valid JSON
+	IPCCodeTooLarge  = "too_large" // The request or response exceeds the size limit
+	IPCCodeUnknown   = "unknown"   // The command doesn't exist
+	IPCCodeFailed    = "failed"    // The command ran and failed
+)
+
+// ErrNoInstance is returned by the client when no running instance answers
+var ErrNoInstance = errors.New("pano is not running")
+
+// Errors for the protocol failures, matched with errors.Is against any *IPCError of that code
+var (
+	ErrIPCAuth      = &IPCError{Code: IPCCodeAuth, Message: "invalid token"}
+	ErrIPCVersion   = &IPCError{Code: IPCCodeVersion, Message: "unsupported protocol version"}
+	ErrIPCBusy      = &IPCError{Code: IPCCodeBusy, Message: "pano is busy, try again"}
+	ErrIPCMalformed = &IPCError{Code: IPCCodeMalformed, Message: "malformed request"}
+	ErrIPCTooLarge  = &IPCError{Code: IPCCodeTooLarge, Message: "message too large"}
+)
+
+// IPCError is an error reported by the other side of the connection
+type IPCError struct {
+	Code    string
+	Message string
+}
+
+func (e *IPCError) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code, so errors.Is(err, ErrIPCBusy) holds whatever the message
+func (e *IPCError) Is(target error) bool {
+	t, ok := target.(*IPCError)
+	return ok && t.Code == e.Code
+}
+
+// IPCRequest is one command sent to the running instance
+type IPCRequest struct {
+	Version int      `json:"version,omitempty"` // 0 for builds before versioning, read as 1
+	Token   string   `json:"token"`
+	Command string   `json:"command"`
+	Paths   []string `json:"paths,omitempty"`
+	ID      string   `json:"id,omitempty"`
+	Text    string   `json:"text,omitempty"`
+	Pin     bool     `json:"pin,omitempty"`
+	Limit   int      `json:"limit,omitempty"`
+}
+
+// IPCResponse reports the outcome of a request
+type IPCResponse struct {
+	Version int             `json:"version,omitempty"` // Version the request was handled with
+	Error   string          `json:"error,omitempty"`
+	Code    string          `json:"code,omitempty"` // Set with Error, see the IPCCode constants
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// IPCPing is the answer to a ping
+type IPCPing struct {
+	Version    int `json:"version"`
+	MinVersion int `json:"min_version"`
+}
+
+// IPCItem describes an item in a list response; content is fetched with "get"
+type IPCItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Pinned    bool      `json:"pinned,omitempty"`
+	Size      int       `json:"size"`
+	Preview   string    `json:"preview,omitempty"`   // First line of text items, shortened
+	Protected bool      `json:"protected,omitempty"` // Needs its passphrase, has no preview
+}
+
+// ipcPreviewLength is how many characters of a text item IPCPreview keeps
+const ipcPreviewLength = 80
+
+// IPCPreview returns the first non-empty line of text, shortened for IPCItem.Preview
+func IPCPreview(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if cut, ok := storage.CutGraphemes(line, ipcPreviewLength); ok {
+			line = cut + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// IPCContent is the content of an item in a get response
+type IPCContent struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"` // Text items
+	Data []byte `json:"data,omitempty"` // Image items, PNG
+}
+
+// IPCHandler runs a command on the instance and returns its result, sent back as Data
+// Returning an *IPCError picks the code; any other error is reported as IPCCodeFailed
+type IPCHandler func(req IPCRequest) (any, error)
+
+// ipcEndpoint is the content of IPCFile
+type ipcEndpoint struct {
+	Addr  string `json:"addr"`
+	Token string `json:"token,omitempty"` // Written by builds before IPCTokenFile
+}
+
+// IPCServer accepts commands for the running instance
+type IPCServer struct {
+	listener net.Listener
+	path     string
+	token    string
+	handler  IPCHandler
+	handling chan struct{}  // Held while the handler runs; the GUI handles one request at a time
+	conns    chan struct{}  // One slot per connection being served
+	wg       sync.WaitGroup // Accept loop and open connections
+	mu       sync.Mutex
+	closed   bool
+}
+
+// StartIPCServer listens on a loopback port and publishes it in dir/IPCFile
+// handler runs on the connection's goroutine, one request at a time
+func StartIPCServer(dir string, handler IPCHandler) (*IPCServer, error) {
+	token, err := loadIPCToken(dir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for IPC: %w", err)
+	}
+
+	s := newIPCServer(token, handler)
+	s.listener = listener
+	s.path = filepath.Join(dir, IPCFile)
+	data, err := json.Marshal(ipcEndpoint{Addr: listener.Addr().String()})
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to encode IPC endpoint: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to write IPC endpoint: %w", err)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.acceptLoop()
+	}()
+	return s, nil
+}
+
+// newIPCServer creates a server that accepts requests carrying token
+func newIPCServer(token string, handler IPCHandler) *IPCServer {
+	return &IPCServer{
+		token:    token,
+		handler:  handler,
+		handling: make(chan struct{}, 1),
+		conns:    make(chan struct{}, ipcMaxConns),
+	}
+}
+
+// loadIPCToken reads the token in dir/IPCTokenFile, creating it if create is set
+func loadIPCToken(dir string, create bool) (string, error) {
+	path := filepath.Join(dir, IPCTokenFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); len(token) == 64 {
+			return token, nil
+		}
+	}
+	if !create {
+		return "", ErrNoInstance
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to create IPC token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	// Owner-only on Unix; on Windows the profile directory already restricts access
+	if err := os.WriteFile(path, []byte(token), 0600); err != nil {
+		return "", fmt.Errorf("failed to write IPC token: %w", err)
+	}
+	return token, nil
+}
+
+// acceptLoop serves connections until the listener is closed
+func (s *IPCServer) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// serveConn reads one request from conn, answers it and closes conn
+func (s *IPCServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ipcTimeout))
+
+	select {
+	case s.conns <- struct{}{}:
+		defer func() { <-s.conns }()
+	default:
+		writeIPCResponse(conn, 0, nil, ErrIPCBusy)
+		return
+	}
+
+	var req IPCRequest
+	if err := readIPCMessage(conn, ipcMaxRequest, &req); err != nil {
+		writeIPCResponse(conn, 0, nil, err)
+		return
+	}
+	version, result, err := s.handle(req)
+	writeIPCResponse(conn, version, result, err)
+}
+
+// handle checks a request and runs it; returns the version it was handled with
+func (s *IPCServer) handle(req IPCRequest) (int, any, error) {
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(s.token)) != 1 {
+		return 0, nil, ErrIPCAuth
+	}
+
+	version := req.Version
+	if version == 0 {
+		version = 1
+	}
+	if version < ipcMinVersion {
+		return 0, nil, ErrIPCVersion
+	}
+	version = min(version, IPCVersion)
+
+	introduced, ok := ipcCommandVersions[req.Command]
+	if !ok {
+		return version, nil, &IPCError{Code: IPCCodeUnknown, Message: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+	if introduced > version {
+		return version, nil, ErrIPCVersion
+	}
+	if req.Command == IPCCommandPing {
+		return version, IPCPing{Version: IPCVersion, MinVersion: ipcMinVersion}, nil
+	}
+
+	select {
+	case s.handling <- struct{}{}:
+		defer func() { <-s.handling }()
+	case <-time.After(ipcBusyWait):
+		return version, nil, ErrIPCBusy
+	}
+	result, err := s.handler(req)
+	return version, result, err
+}
+
+// Close stops listening, removes IPCFile and waits for open connections
+// Closing twice is a no-op; the token file is kept for the next start
+func (s *IPCServer) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.listener.Close()
+	os.Remove(s.path)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(ipcCloseTimeout):
+		return fmt.Errorf("IPC server did not stop within %v", ipcCloseTimeout)
+	}
+}
+
+// readIPCMessage decodes one JSON message of at most max bytes from r
+func readIPCMessage(r io.Reader, max int64, v any) error {
+	limited := &io.LimitedReader{R: r, N: max + 1}
+	if err := json.NewDecoder(limited).Decode(v); err != nil {
+		if limited.N <= 0 {
+			return ErrIPCTooLarge
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return err
+		}
+		return ErrIPCMalformed
+	}
+	return nil
+}
+
+// writeIPCResponse sends the outcome of a request; errors are reported by code
+func writeIPCResponse(w io.Writer, version int, result any, err error) {
+	resp := IPCResponse{Version: version}
+	if err == nil && result != nil {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			err = fmt.Errorf("failed to encode result: %w", marshalErr)
+		} else if len(data) > ipcMaxResponse {
+			err = ErrIPCTooLarge
+		} else {
+			resp.Data = data
+		}
+	}
+	if err != nil {
+		resp.Code = IPCCodeFailed
+		var ipcErr *IPCError
+		if errors.As(err, &ipcErr) {
+			resp.Code = ipcErr.Code
+		}
+		resp.Error = err.Error()
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// IPCClient sends commands to the running instance
+type IPCClient struct {
+	token   string
+	version int                      // Negotiated with the instance, see Dial
+	dial    func() (net.Conn, error) // Opens a connection to the instance
+}
+
+// DialIPC finds the instance published in dir and negotiates the protocol version
+// Returns ErrNoInstance when there is none
+func DialIPC(dir string) (*IPCClient, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IPCFile))
+	if err != nil {
+		return nil, ErrNoInstance
+	}
+	var endpoint ipcEndpoint
+	if err := json.Unmarshal(data, &endpoint); err != nil {
+		return nil, ErrNoInstance
+	}
+	token := endpoint.Token
+	if token == "" {
+		if token, err = loadIPCToken(dir, false); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &IPCClient{
+		token: token,
+		dial: func() (net.Conn, error) {
+			// A file left behind by a crash points at a port nobody listens on
+			conn, err := net.DialTimeout("tcp", endpoint.Addr, ipcTimeout)
+			if err != nil {
+				return nil, ErrNoInstance
+			}
+			return conn, nil
+		},
+	}
+	if err := c.handshake(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// handshake pings the instance and settles on the highest version both sides speak
+// An instance from before versioning doesn't know ping and is spoken to with version 1
+func (c *IPCClient) handshake() error {
+	c.version = IPCVersion
+	var ping IPCPing
+	version, err := c.roundTrip(IPCRequest{Command: IPCCommandPing}, &ping)
+	var ipcErr *IPCError
+	if err != nil && version == 0 && errors.As(err, &ipcErr) && ipcErr.Code == IPCCodeFailed {
+		c.version = 1
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.version = version
+	return nil
+}
+
+// Version returns the protocol version negotiated with the instance
+func (c *IPCClient) Version() int {
+	return c.version
+}
+
+// Call sends a command and decodes its result into result, which may be nil
+// Returns ErrIPCVersion without contacting the instance if it is too old for the command
+func (c *IPCClient) Call(req IPCRequest, result any) error {
+	if introduced, ok := ipcCommandVersions[req.Command]; ok && introduced > c.version {
+		return ErrIPCVersion
+	}
+	_, err := c.roundTrip(req, result)
+	return err
+}
+
+// roundTrip sends req on a new connection and returns the version of the response
+func (c *IPCClient) roundTrip(req IPCRequest, result any) (int, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ipcTimeout))
+
+	req.Token = c.token
+	req.Version = c.version
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return 0, fmt.Errorf("failed to send IPC request: %w", err)
+	}
+	var resp IPCResponse
+	if err := readIPCMessage(conn, ipcMaxResponse, &resp); err != nil {
+		return 0, fmt.Errorf("failed to read IPC response: %w", err)
+	}
+	if resp.Error != "" {
+		code := resp.Code
+		if code == "" {
+			code = IPCCodeFailed // Instances before versioning send no code
+		}
+		return resp.Version, &IPCError{Code: code, Message: resp.Error}
+	}
+	if result != nil && len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, result); err != nil {
+			return resp.Version, fmt.Errorf("failed to decode IPC response: %w", err)
+		}
+	}
+	return resp.Version, nil
+}
+
+// SendIPC sends a request to the instance published in dir/IPCFile
+// Returns ErrNoInstance when there is none, or the error the instance reported
+func SendIPC(dir string, req IPCRequest) error {
+	client, err := DialIPC(dir)
+	if err != nil {
+		return err
+	}
+	return client.Call(req, nil)
+}
+
+// ProbeIPC checks whether an instance is published in dir and answers, for commands that
+// must not run next to one; no connection stays open afterwards
+// Returns ErrNoInstance when there is none
+func ProbeIPC(dir string) error {
+	// Each round trip hangs up when it is done, so the client needs no closing
+	_, err := DialIPC(dir)
+	return err
+}
@@ -0,0 +1,294 @@
+package system
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// Closing the IPC server waits for its accept loop and the connections it served
+func TestIPCCloseLeaksNoGoroutines(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	dir := t.TempDir()
+	received := make(chan IPCRequest, 1)
+	server, err := StartIPCServer(dir, func(req IPCRequest) (any, error) {
+		received <- req
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to start IPC server: %v", err)
+	}
+
+	if err := SendIPC(dir, IPCRequest{Command: IPCCommandAddText, Text: "merhaba"}); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if req := <-received; req.Text != "merhaba" {
+		t.Errorf("handler got %q, want %q", req.Text, "merhaba")
+	}
+
+	if err := server.Close(); err != nil {
+		t.Errorf("failed to close: %v", err)
+	}
+	if err := server.Close(); err != nil {
+		t.Errorf("second close failed: %v", err)
+	}
+	if _, err := DialIPC(dir); err != ErrNoInstance {
+		t.Errorf("dialing a closed server returned %v, want ErrNoInstance", err)
+	}
+}
+
+// A probe leaves no connection behind: the server closes right after without waiting
+// on a client that never hung up
+func TestProbeIPC(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	dir := t.TempDir()
+	if err := ProbeIPC(dir); err != ErrNoInstance {
+		t.Errorf("probing without an instance returned %v, want ErrNoInstance", err)
+	}
+	server, err := StartIPCServer(dir, func(IPCRequest) (any, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to start IPC server: %v", err)
+	}
+	if err := ProbeIPC(dir); err != nil {
+		t.Errorf("probing the running instance returned %v", err)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- server.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("failed to close: %v", err)
+		}
+	case <-time.After(ipcTimeout / 2):
+		t.Fatal("the server is still waiting on the probe's connection")
+	}
+}
+
+// The files of "pano add" reach the handler as sent, and a failed add comes back to the
+// client with the handler's message
+func TestIPCAddFiles(t *testing.T) {
+	dir := t.TempDir()
+	received := make(chan []string, 1)
+	server, err := StartIPCServer(dir, func(req IPCRequest) (any, error) {
+		if req.Command != IPCCommandAdd {
+			return nil, fmt.Errorf("unexpected command %q", req.Command)
+		}
+		received <- req.Paths
+		if len(req.Paths) > 1 {
+			return nil, errors.New("okunamayan dosya")
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to start IPC server: %v", err)
+	}
+	defer server.Close()
+
+	paths := []string{filepath.Join(dir, "Belgeler", "not ş.txt")}
+	if err := SendIPC(dir, IPCRequest{Command: IPCCommandAdd, Paths: paths}); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if got := <-received; !slices.Equal(got, paths) {
+		t.Errorf("handler got %q, want %q", got, paths)
+	}
+
+	err = SendIPC(dir, IPCRequest{Command: IPCCommandAdd, Paths: []string{"a", "b"}})
+	<-received
+	var ipcErr *IPCError
+	if !errors.As(err, &ipcErr) || ipcErr.Code != IPCCodeFailed || ipcErr.Message != "okunamayan dosya" {
+		t.Errorf("a failed add gave %v", err)
+	}
+}
+
+// TestIPCHandle runs requests past the server's checks: the token, the version the
+// request is answered with, and whether the command exists in that version
+func TestIPCHandle(t *testing.T) {
+	handled := 0
+	s := newIPCServer("jeton", func(IPCRequest) (any, error) {
+		handled++
+		return "tamam", nil
+	})
+	tests := []struct {
+		name    string
+		req     IPCRequest
+		version int
+		err     error
+	}{
+		{"wrong token", IPCRequest{Token: "yanlış", Command: IPCCommandList, Version: 2}, 0, ErrIPCAuth},
+		{"no token", IPCRequest{Command: IPCCommandList, Version: 2}, 0, ErrIPCAuth},
+		{"before versioning", IPCRequest{Token: "jeton", Command: IPCCommandAdd}, 1, nil},
+		{"newer client", IPCRequest{Token: "jeton", Command: IPCCommandList, Version: IPCVersion + 3}, IPCVersion, nil},
+		{"command too new", IPCRequest{Token: "jeton", Command: IPCCommandList, Version: 1}, 1, ErrIPCVersion},
+		{"unknown command", IPCRequest{Token: "jeton", Command: "format", Version: 2}, 2, &IPCError{Code: IPCCodeUnknown}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := handled
+			version, result, err := s.handle(tt.req)
+			if version != tt.version {
+				t.Errorf("answered with version %d, want %d", version, tt.version)
+			}
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) || handled != before {
+					t.Errorf("got %v and %d handler calls, want %v", err, handled-before, tt.err)
+				}
+				return
+			}
+			if err != nil || result != "tamam" || handled != before+1 {
+				t.Errorf("got %v, %v", result, err)
+			}
+		})
+	}
+
+	// Ping is answered by the server itself
+	_, result, err := s.handle(IPCRequest{Token: "jeton", Command: IPCCommandPing, Version: 2})
+	if ping, ok := result.(IPCPing); !ok || err != nil || ping != (IPCPing{Version: IPCVersion, MinVersion: ipcMinVersion}) {
+		t.Errorf("ping gave %+v, %v", result, err)
+	}
+}
+
+// A request waits a while for the one before it and then reports busy, as does a
+// connection past the limit
+func TestIPCBusy(t *testing.T) {
+	s := newIPCServer("jeton", func(IPCRequest) (any, error) {
+		return nil, nil
+	})
+	s.handling <- struct{}{}
+	start := time.Now()
+	if _, _, err := s.handle(IPCRequest{Token: "jeton", Command: IPCCommandList, Version: 2}); !errors.Is(err, ErrIPCBusy) {
+		t.Errorf("a request behind a running one gave %v", err)
+	}
+	if waited := time.Since(start); waited < ipcBusyWait {
+		t.Errorf("gave up after %v, want at least %v", waited, ipcBusyWait)
+	}
+	<-s.handling
+
+	for range ipcMaxConns {
+		s.conns <- struct{}{}
+	}
+	server, client := net.Pipe()
+	go s.serveConn(server)
+	var resp IPCResponse
+	if err := json.NewDecoder(client).Decode(&resp); err != nil || resp.Code != IPCCodeBusy {
+		t.Errorf("a connection past the limit got %+v, %v", resp, err)
+	}
+	client.Close()
+}
+
+func TestReadIPCMessage(t *testing.T) {
+	var req IPCRequest
+	if err := readIPCMessage(strings.NewReader(`{"command":"list","limit":5}`), 64, &req); err != nil || req.Limit != 5 {
+		t.Errorf("read %+v, %v", req, err)
+	}
+	if err := readIPCMessage(strings.NewReader(`{"command":`), 64, &req); !errors.Is(err, ErrIPCMalformed) {
+		t.Errorf("a cut message gave %v", err)
+	}
+	long := `{"command":"add_text","text":"` + strings.Repeat("a", 100) + `"}`
+	if err := readIPCMessage(strings.NewReader(long), 64, &req); !errors.Is(err, ErrIPCTooLarge) {
+		t.Errorf("a message over the limit gave %v", err)
+	}
+}
+
+// fakeOldInstance answers every request on a new pipe the way builds before versioning
+// did: an error without a code or version
+func fakeOldInstance(dials *int) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		*dials++
+		server, client := net.Pipe()
+		go func() {
+			defer server.Close()
+			var req IPCRequest
+			json.NewDecoder(server).Decode(&req)
+			json.NewEncoder(server).Encode(map[string]string{"error": "unknown command " + req.Command})
+		}()
+		return client, nil
+	}
+}
+
+// The client settles on version 1 with an instance from before versioning and refuses
+// newer commands without sending them
+func TestIPCClientOldInstance(t *testing.T) {
+	dials := 0
+	c := &IPCClient{token: "jeton", dial: fakeOldInstance(&dials)}
+	if err := c.handshake(); err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	if c.Version() != 1 {
+		t.Errorf("negotiated version %d, want 1", c.Version())
+	}
+	if err := c.Call(IPCRequest{Command: IPCCommandList}, nil); !errors.Is(err, ErrIPCVersion) {
+		t.Errorf("list on an old instance gave %v", err)
+	}
+	if dials != 1 {
+		t.Errorf("%d connections, want only the handshake's", dials)
+	}
+}
+
+// Results come back decoded, and errors keep the code the handler picked
+func TestIPCCall(t *testing.T) {
+	dir := t.TempDir()
+	server, err := StartIPCServer(dir, func(req IPCRequest) (any, error) {
+		switch req.Command {
+		case IPCCommandList:
+			return []IPCItem{{ID: "1", Type: "text", Preview: "merhaba"}}, nil
+		case IPCCommandGet:
+			return nil, &IPCError{Code: IPCCodeTooLarge, Message: "öğe çok büyük"}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to start IPC server: %v", err)
+	}
+	defer server.Close()
+
+	client, err := DialIPC(dir)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	if client.Version() != IPCVersion {
+		t.Errorf("negotiated version %d, want %d", client.Version(), IPCVersion)
+	}
+	var items []IPCItem
+	if err := client.Call(IPCRequest{Command: IPCCommandList}, &items); err != nil || len(items) != 1 || items[0].Preview != "merhaba" {
+		t.Errorf("list gave %+v, %v", items, err)
+	}
+	if err := client.Call(IPCRequest{Command: IPCCommandGet, ID: "1"}, nil); !errors.Is(err, ErrIPCTooLarge) || err.Error() != "öğe çok büyük" {
+		t.Errorf("get gave %v", err)
+	}
+
+	// Another data directory's token doesn't open this instance
+	client.token = strings.Repeat("0", 64)
+	if err := client.Call(IPCRequest{Command: IPCCommandList}, nil); !errors.Is(err, ErrIPCAuth) {
+		t.Errorf("a wrong token gave %v", err)
+	}
+}
+
+func TestIPCPreview(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"\n  \n  ilk satır  \nikinci", "ilk satır"},
+		{"   ", ""},
+		{strings.Repeat("ş", ipcPreviewLength), strings.Repeat("ş", ipcPreviewLength)},
+		{strings.Repeat("ş", ipcPreviewLength+5), strings.Repeat("ş", ipcPreviewLength) + "…"},
+	}
+	for _, tt := range tests {
+		if got := IPCPreview(tt.text); got != tt.want {
+			t.Errorf("IPCPreview(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	LogDirName  = "logs" // Log directory, under the Pano data directory
+	LogFileName = "pano.log"
+	logMaxSize  = 1024 * 1024 // Rotate once the current file reaches 1 MB
+	logKeep     = 3           // pano.log plus pano.1.log and pano.2.log
+)
+
+// RotatingLog is an io.Writer that keeps the last few log files in a directory
+type RotatingLog struct {
+	mu   sync.Mutex
+	dir  string
+	file *os.File
+	size int64
+}
+
+// OpenRotatingLog opens (or creates) the current log file in dir
+func OpenRotatingLog(dir string) (*RotatingLog, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	l := &RotatingLog{dir: dir}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *RotatingLog) open() error {
+	f, err := os.OpenFile(filepath.Join(l.dir, LogFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// Write appends to the current file, rotating first when it is full
+func (l *RotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return 0, os.ErrClosed
+	}
+	if l.size+int64(len(p)) > logMaxSize && l.size > 0 {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate shifts pano.log to pano.1.log and so on, dropping the oldest
+func (l *RotatingLog) rotate() error {
+	l.file.Close()
+	l.file = nil
+
+	os.Remove(rotatedLogPath(l.dir, logKeep-1))
+	for i := logKeep - 2; i >= 0; i-- {
+		os.Rename(rotatedLogPath(l.dir, i), rotatedLogPath(l.dir, i+1))
+	}
+	return l.open()
+}
+
+// Close closes the current file
+func (l *RotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// LogFiles returns the existing log files in dir, newest first
+func LogFiles(dir string) []string {
+	files := make([]string, 0, logKeep)
+	for i := 0; i < logKeep; i++ {
+		path := rotatedLogPath(dir, i)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// rotatedLogPath returns pano.log for 0 and pano.N.log otherwise
+func rotatedLogPath(dir string, n int) string {
+	if n == 0 {
+		return filepath.Join(dir, LogFileName)
+	}
+	return filepath.Join(dir, fmt.Sprintf("pano.%d.log", n))
+}
@@ -0,0 +1,277 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	hook "github.com/robotn/gohook"
+)
+
+// SelectionOffKey is the letter that, with Ctrl+Shift, turns selection capture off
+const SelectionOffKey = "X"
+
+// Mouse and modifier values of gohook events
+const (
+	mouseLeft = 1 // Button of the primary mouse button
+
+	// gohook names libuiohook's events oddly: MouseHold is the release of a button and
+	// MouseUp the click that follows one without a drag
+	mouseReleased = hook.MouseHold
+	mouseClicked  = hook.MouseUp
+
+	// modifierMask covers Shift, Ctrl, Meta and Alt on both sides
+	modifierMask = 0xFF
+
+	// scrollSlop is the horizontal travel under which a long vertical drag is taken for
+	// a scroll bar or a pan rather than a selection
+	scrollSlop = 2
+)
+
+// SelectionParams decide which mouse gestures count as selecting text
+type SelectionParams struct {
+	MinDrag     int           // Pixels the pointer must travel while the button is down
+	MinPress    time.Duration // Shorter presses are clicks, however far the pointer moved
+	SettleDelay time.Duration // Wait after the release before copying; a new press cancels it
+	Debounce    time.Duration // Minimum time between two copies
+}
+
+// DefaultSelectionParams are the gesture thresholds used by NewSelectionWatcher
+var DefaultSelectionParams = SelectionParams{
+	MinDrag:     8,
+	MinPress:    80 * time.Millisecond,
+	SettleDelay: 150 * time.Millisecond,
+	Debounce:    600 * time.Millisecond,
+}
+
+// SelectionWatcher copies text selected with the mouse, like the primary selection on
+// Linux: after a left-button drag or a double click it sends Ctrl+C to the foreground
+// app, and the monitor captures the result like any other copy
+// It reads the events of the hotkey hook (see HotkeyManager.SetInputHandler) and is off
+// until SetEnabled; Ctrl+Shift+SelectionOffKey turns it off again from anywhere
+type SelectionWatcher struct {
+	params     SelectionParams
+	sendCopy   func()        // Sends Ctrl+C to the foreground app
+	foreground func() string // Executable name of the foreground app, "" if unknown
+	excluded   map[string]bool
+	enabled    bool
+	onOff      func() // Called after the off switch was pressed
+
+	// Gesture state
+	pressed    bool
+	skip       bool // The current press can't be a selection (modifier held, wheel turned)
+	startX     int
+	startY     int
+	startAt    time.Time
+	travelX    int // Farthest horizontal distance from the start while pressed
+	travelY    int
+	ctrl       bool
+	shift      bool
+	lastCopy   time.Time
+	generation uint64 // Bumped to cancel a copy waiting for SettleDelay
+
+	mu sync.Mutex
+}
+
+// NewSelectionWatcher creates a disabled watcher using the platform's input and
+// foreground detection
+func NewSelectionWatcher() *SelectionWatcher {
+	return NewSelectionWatcherWithActions(DefaultSelectionParams, sendCopyKeys, foregroundProcessName)
+}
+
+// NewSelectionWatcherWithActions creates a disabled watcher with custom thresholds, copy
+// action and foreground lookup
+func NewSelectionWatcherWithActions(params SelectionParams, sendCopy func(), foreground func() string) *SelectionWatcher {
+	w := &SelectionWatcher{
+		params:     params,
+		sendCopy:   sendCopy,
+		foreground: foreground,
+	}
+	w.SetExcludedApps(nil)
+	return w
+}
+
+// SetEnabled turns selection capture on or off; turning it off drops a pending copy
+func (w *SelectionWatcher) SetEnabled(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.enabled = enabled
+	if !enabled {
+		w.reset()
+	}
+}
+
+// Enabled returns whether selection capture is on
+func (w *SelectionWatcher) Enabled() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enabled
+}
+
+// SetExcludedApps sets the executables (e.g. "game.exe") in which selecting never copies;
+// Pano itself is always excluded. Names are matched without case
+func (w *SelectionWatcher) SetExcludedApps(names []string) {
+	excluded := make(map[string]bool, len(names)+1)
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			excluded[strings.ToLower(filepath.Base(name))] = true
+		}
+	}
+	if self, err := os.Executable(); err == nil {
+		excluded[strings.ToLower(filepath.Base(self))] = true
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.excluded = excluded
+}
+
+// SetOnOff sets the function called, on its own goroutine, when the off switch turned
+// selection capture off
+func (w *SelectionWatcher) SetOnOff(callback func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onOff = callback
+}
+
+// HandleEvent feeds one hook event to the watcher; it returns quickly and never blocks
+// on the copy
+func (w *SelectionWatcher) HandleEvent(ev hook.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch ev.Kind {
+	case hook.KeyDown:
+		w.handleKey(ev, true)
+	case hook.KeyUp:
+		w.handleKey(ev, false)
+	}
+	if !w.enabled {
+		return
+	}
+
+	switch ev.Kind {
+	case hook.MouseDown:
+		// Any press cancels a copy still waiting, the selection may be gone
+		w.generation++
+		w.pressed = ev.Button == mouseLeft
+		w.skip = w.modified(ev)
+		w.startX, w.startY = int(ev.X), int(ev.Y)
+		w.startAt = ev.When
+		w.travelX, w.travelY = 0, 0
+	case hook.MouseDrag:
+		if w.pressed {
+			w.travelX = max(w.travelX, abs(int(ev.X)-w.startX))
+			w.travelY = max(w.travelY, abs(int(ev.Y)-w.startY))
+		}
+	case hook.MouseWheel:
+		if w.pressed {
+			w.skip = true
+		}
+	case mouseReleased:
+		if ev.Button != mouseLeft || !w.pressed {
+			return
+		}
+		w.pressed = false
+		if w.isSelection(ev) {
+			w.schedule(ev.When)
+		}
+	case mouseClicked:
+		// A double click selects a word, a triple click the line
+		if ev.Button == mouseLeft && ev.Clicks >= 2 && !w.modified(ev) {
+			w.schedule(ev.When)
+		}
+	}
+}
+
+// handleKey tracks Ctrl and Shift and acts on the off switch (caller must hold lock)
+func (w *SelectionWatcher) handleKey(ev hook.Event, down bool) {
+	switch {
+	case isCtrlKey(ev):
+		w.ctrl = down
+	case isShiftKey(ev):
+		w.shift = down
+	case down && w.ctrl && w.shift && isLetterKey(ev, rune(SelectionOffKey[0])):
+		if !w.enabled {
+			return
+		}
+		w.enabled = false
+		w.reset()
+		if w.onOff != nil {
+			go w.onOff()
+		}
+	}
+}
+
+// modified reports whether a modifier was held, e.g. for Ctrl+drag to copy files or
+// Shift+click to extend a selection (caller must hold lock)
+func (w *SelectionWatcher) modified(ev hook.Event) bool {
+	return ev.Mask&modifierMask != 0 || w.ctrl || w.shift
+}
+
+// isSelection reports whether the press that ended with ev selected text rather than
+// clicked, scrolled or moved something (caller must hold lock)
+func (w *SelectionWatcher) isSelection(ev hook.Event) bool {
+	if w.skip || w.modified(ev) {
+		return false
+	}
+	travelX := max(w.travelX, abs(int(ev.X)-w.startX))
+	travelY := max(w.travelY, abs(int(ev.Y)-w.startY))
+	if travelX < w.params.MinDrag && travelY < w.params.MinDrag {
+		return false
+	}
+	if ev.When.Sub(w.startAt) < w.params.MinPress {
+		return false
+	}
+	// Straight up or down along a scroll bar
+	return travelX > scrollSlop
+}
+
+// schedule copies after SettleDelay unless debounced, cancelled or excluded by then
+// (caller must hold lock)
+func (w *SelectionWatcher) schedule(at time.Time) {
+	if !w.lastCopy.IsZero() && at.Sub(w.lastCopy) < w.params.Debounce {
+		return
+	}
+	w.generation++
+	generation := w.generation
+	time.AfterFunc(w.params.SettleDelay, func() {
+		w.fire(generation, at)
+	})
+}
+
+// fire sends the copy scheduled at if nothing cancelled it and the foreground app isn't
+// excluded; only copies sent count for the debounce
+func (w *SelectionWatcher) fire(generation uint64, at time.Time) {
+	w.mu.Lock()
+	if generation != w.generation || !w.enabled {
+		w.mu.Unlock()
+		return
+	}
+	sendCopy, foreground, excluded := w.sendCopy, w.foreground, w.excluded
+	w.mu.Unlock()
+
+	if name := foreground(); name != "" && excluded[strings.ToLower(name)] {
+		return
+	}
+	w.mu.Lock()
+	w.lastCopy = at
+	w.mu.Unlock()
+	sendCopy()
+}
+
+// reset drops the current gesture and a pending copy (caller must hold lock)
+func (w *SelectionWatcher) reset() {
+	w.pressed = false
+	w.generation++
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+// sendCopyKeys does nothing on non-Windows platforms
+func sendCopyKeys() {}
+
+// foregroundProcessName is unknown on non-Windows platforms
+func foregroundProcessName() string {
+	return ""
+}
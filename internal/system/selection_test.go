@@ -0,0 +1,160 @@
+package system
+
+import (
+	"testing"
+	"time"
+
+	hook "github.com/robotn/gohook"
+)
+
+// testSelectionParams settle quickly so the tests don't wait long for a copy
+var testSelectionParams = SelectionParams{
+	MinDrag:     8,
+	MinPress:    80 * time.Millisecond,
+	SettleDelay: 20 * time.Millisecond,
+	Debounce:    600 * time.Millisecond,
+}
+
+// selectionFixture is a watcher whose copies arrive on copies
+type selectionFixture struct {
+	w          *SelectionWatcher
+	copies     chan struct{}
+	foreground string
+	start      time.Time
+}
+
+func newSelectionFixture(t *testing.T) *selectionFixture {
+	t.Helper()
+	f := &selectionFixture{copies: make(chan struct{}, 8), foreground: "notepad.exe", start: time.Now()}
+	f.w = NewSelectionWatcherWithActions(testSelectionParams,
+		func() { f.copies <- struct{}{} },
+		func() string { return f.foreground })
+	f.w.SetEnabled(true)
+	return f
+}
+
+// drag presses the left button at (100, 100) at offset at, moves by dx, dy and releases
+// after d
+func (f *selectionFixture) drag(at time.Duration, dx, dy int16, d time.Duration, mask uint16) {
+	when := f.start.Add(at)
+	f.w.HandleEvent(hook.Event{Kind: hook.MouseDown, Button: mouseLeft, X: 100, Y: 100, When: when, Mask: mask})
+	f.w.HandleEvent(hook.Event{Kind: hook.MouseDrag, X: 100 + dx, Y: 100 + dy, When: when.Add(d / 2), Mask: mask})
+	f.w.HandleEvent(hook.Event{Kind: mouseReleased, Button: mouseLeft, X: 100 + dx, Y: 100 + dy, When: when.Add(d), Mask: mask})
+}
+
+// copied returns how many copies were sent once the settle delay has passed
+func (f *selectionFixture) copied() int {
+	time.Sleep(5 * testSelectionParams.SettleDelay)
+	n := 0
+	for {
+		select {
+		case <-f.copies:
+			n++
+		default:
+			return n
+		}
+	}
+}
+
+// TestSelectionGestures tells selections from clicks, flicks, scrolls and modified drags
+func TestSelectionGestures(t *testing.T) {
+	tests := []struct {
+		name    string
+		gesture func(f *selectionFixture)
+		want    int
+	}{
+		{"drag", func(f *selectionFixture) { f.drag(0, 60, 5, 200*time.Millisecond, 0) }, 1},
+		{"short drag", func(f *selectionFixture) { f.drag(0, 4, 3, 200*time.Millisecond, 0) }, 0},
+		{"quick flick", func(f *selectionFixture) { f.drag(0, 60, 0, 30*time.Millisecond, 0) }, 0},
+		{"scroll bar", func(f *selectionFixture) { f.drag(0, 1, 200, 300*time.Millisecond, 0) }, 0},
+		{"ctrl drag", func(f *selectionFixture) { f.drag(0, 60, 0, 200*time.Millisecond, 0x02) }, 0},
+		{"shift held", func(f *selectionFixture) {
+			f.w.HandleEvent(hook.Event{Kind: hook.KeyDown, Keycode: scShiftLeft})
+			f.drag(0, 60, 0, 200*time.Millisecond, 0)
+		}, 0},
+		{"wheel while pressed", func(f *selectionFixture) {
+			f.w.HandleEvent(hook.Event{Kind: hook.MouseDown, Button: mouseLeft, X: 100, Y: 100, When: f.start})
+			f.w.HandleEvent(hook.Event{Kind: hook.MouseWheel, When: f.start.Add(50 * time.Millisecond)})
+			f.w.HandleEvent(hook.Event{Kind: mouseReleased, Button: mouseLeft, X: 160, Y: 100, When: f.start.Add(200 * time.Millisecond)})
+		}, 0},
+		{"double click", func(f *selectionFixture) {
+			f.w.HandleEvent(hook.Event{Kind: mouseClicked, Button: mouseLeft, Clicks: 2, When: f.start})
+		}, 1},
+		{"single click", func(f *selectionFixture) {
+			f.w.HandleEvent(hook.Event{Kind: mouseClicked, Button: mouseLeft, Clicks: 1, When: f.start})
+		}, 0},
+		{"right button drag", func(f *selectionFixture) {
+			f.w.HandleEvent(hook.Event{Kind: hook.MouseDown, Button: 2, X: 100, Y: 100, When: f.start})
+			f.w.HandleEvent(hook.Event{Kind: mouseReleased, Button: 2, X: 160, Y: 100, When: f.start.Add(200 * time.Millisecond)})
+		}, 0},
+		{"excluded app", func(f *selectionFixture) {
+			f.w.SetExcludedApps([]string{" Game.exe "})
+			f.foreground = "GAME.EXE"
+			f.drag(0, 60, 0, 200*time.Millisecond, 0)
+		}, 0},
+		{"disabled", func(f *selectionFixture) {
+			f.w.SetEnabled(false)
+			f.drag(0, 60, 0, 200*time.Millisecond, 0)
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSelectionFixture(t)
+			tt.gesture(f)
+			if got := f.copied(); got != tt.want {
+				t.Errorf("%d copies, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// A second selection within the debounce doesn't copy, one after it does, and a press
+// before the settle delay cancels the pending copy
+func TestSelectionTiming(t *testing.T) {
+	f := newSelectionFixture(t)
+	f.drag(0, 60, 0, 200*time.Millisecond, 0)
+	if got := f.copied(); got != 1 {
+		t.Fatalf("%d copies, want 1", got)
+	}
+	f.drag(500*time.Millisecond, 60, 0, 200*time.Millisecond, 0)
+	if got := f.copied(); got != 0 {
+		t.Errorf("%d copies within the debounce, want 0", got)
+	}
+	f.drag(time.Second, 60, 0, 200*time.Millisecond, 0)
+	if got := f.copied(); got != 1 {
+		t.Errorf("%d copies after the debounce, want 1", got)
+	}
+
+	f.drag(3*time.Second, 60, 0, 200*time.Millisecond, 0)
+	f.w.HandleEvent(hook.Event{Kind: hook.MouseDown, Button: mouseLeft, When: f.start.Add(3*time.Second + 210*time.Millisecond)})
+	if got := f.copied(); got != 0 {
+		t.Errorf("%d copies after a press cancelled the selection", got)
+	}
+}
+
+// Ctrl+Shift+X turns capture off from anywhere and reports it
+func TestSelectionOffSwitch(t *testing.T) {
+	f := newSelectionFixture(t)
+	off := make(chan struct{}, 1)
+	f.w.SetOnOff(func() { off <- struct{}{} })
+
+	f.w.HandleEvent(hook.Event{Kind: hook.KeyDown, Keycode: scCtrlLeft})
+	f.w.HandleEvent(hook.Event{Kind: hook.KeyDown, Keycode: scShiftLeft})
+	f.w.HandleEvent(hook.Event{Kind: hook.KeyDown, Keycode: letterScanCodes[rune(SelectionOffKey[0])]})
+	select {
+	case <-off:
+	case <-time.After(time.Second):
+		t.Fatal("the off switch wasn't reported")
+	}
+	if f.w.Enabled() {
+		t.Error("capture is still on")
+	}
+
+	f.w.HandleEvent(hook.Event{Kind: hook.KeyUp, Keycode: scCtrlLeft})
+	f.w.HandleEvent(hook.Event{Kind: hook.KeyUp, Keycode: scShiftLeft})
+	f.w.SetEnabled(true)
+	f.drag(0, 60, 0, 200*time.Millisecond, 0)
+	if got := f.copied(); got != 1 {
+		t.Errorf("%d copies once turned back on, want 1", got)
+	}
+}
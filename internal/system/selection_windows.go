@@ -0,0 +1,72 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procSendInput = user32.NewProc("SendInput")
+
+const (
+	inputKeyboard  = 1
+	keyEventKeyUp  = 0x0002
+	vkControlInput = 0x11
+	vkCInput       = 0x43
+)
+
+// keybdInput mirrors KEYBDINPUT
+type keybdInput struct {
+	Vk        uint16
+	Scan      uint16
+	Flags     uint32
+	Time      uint32
+	ExtraInfo uintptr
+}
+
+// keyboardInput mirrors INPUT holding a KEYBDINPUT; the padding makes it as large as
+// the union's biggest member, MOUSEINPUT
+type keyboardInput struct {
+	Type    uint32
+	Ki      keybdInput
+	Padding uint64
+}
+
+// sendCopyKeys presses and releases Ctrl+C in the foreground app
+func sendCopyKeys() {
+	inputs := []keyboardInput{
+		{Type: inputKeyboard, Ki: keybdInput{Vk: vkControlInput}},
+		{Type: inputKeyboard, Ki: keybdInput{Vk: vkCInput}},
+		{Type: inputKeyboard, Ki: keybdInput{Vk: vkCInput, Flags: keyEventKeyUp}},
+		{Type: inputKeyboard, Ki: keybdInput{Vk: vkControlInput, Flags: keyEventKeyUp}},
+	}
+	procSendInput.Call(uintptr(len(inputs)), uintptr(unsafe.Pointer(&inputs[0])), unsafe.Sizeof(inputs[0]))
+}
+
+// foregroundProcessName returns the executable name of the foreground window's process
+func foregroundProcessName() string {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return ""
+	}
+	var pid uint32
+	if _, err := windows.GetWindowThreadProcessId(windows.HWND(hwnd), &pid); err != nil {
+		return ""
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(process)
+
+	buf := make([]uint16, windows.MAX_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(process, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	return filepath.Base(windows.UTF16ToString(buf[:size]))
+}
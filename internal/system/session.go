@@ -0,0 +1,99 @@
+package system
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LockStateProvider reports workstation lock changes to notify, one call at a time and
+// in the order they happened, until the returned stop is called
+// notify is called from the provider's own goroutine; calls made while Start is still
+// running wait for it
+type LockStateProvider func(notify func(locked bool)) (stop func(), err error)
+
+// SessionWatcher reports workstation lock and unlock events
+type SessionWatcher struct {
+	provider LockStateProvider
+	callback func(locked bool)
+	locked   bool
+	running  bool
+	mu       sync.Mutex
+	stop     func() // Provider shutdown, set while running
+}
+
+// NewSessionWatcher creates a watcher using the platform's session notifications
+func NewSessionWatcher() *SessionWatcher {
+	return NewSessionWatcherWithProvider(watchSession)
+}
+
+// NewSessionWatcherWithProvider creates a watcher with a custom lock state provider
+func NewSessionWatcherWithProvider(provider LockStateProvider) *SessionWatcher {
+	return &SessionWatcher{
+		provider: provider,
+	}
+}
+
+// SetCallback sets the function called when the session is locked or unlocked
+// It is called synchronously, in the order the changes happened
+func (s *SessionWatcher) SetCallback(callback func(locked bool)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callback = callback
+}
+
+// IsLocked returns whether the session was locked at the last change
+func (s *SessionWatcher) IsLocked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locked
+}
+
+// Start begins listening for session changes
+func (s *SessionWatcher) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return fmt.Errorf("session watcher already running")
+	}
+
+	stop, err := s.provider(s.notify)
+	if err != nil {
+		return err
+	}
+	s.stop = stop
+	s.running = true
+	return nil
+}
+
+// Stop stops listening for session changes; the callback isn't called again
+func (s *SessionWatcher) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	stop := s.stop
+	s.stop = nil
+	s.mu.Unlock()
+
+	// Outside the lock: the provider may be delivering a change that waits for it
+	stop()
+}
+
+// notify records a lock state change and forwards it to the callback
+// Repeated states are dropped, as are changes after Stop
+func (s *SessionWatcher) notify(locked bool) {
+	s.mu.Lock()
+	if !s.running || locked == s.locked {
+		s.mu.Unlock()
+		return
+	}
+	s.locked = locked
+	callback := s.callback
+	s.mu.Unlock()
+
+	if callback != nil {
+		callback(locked)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+// watchSession is a no-op on non-Windows platforms; the session is never reported as locked
+func watchSession(notify func(locked bool)) (func(), error) {
+	return func() {}, nil
+}
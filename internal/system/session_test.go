@@ -0,0 +1,80 @@
+package system
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeSession is a lock state provider driven by the test
+type fakeSession struct {
+	mu      sync.Mutex
+	notify  func(locked bool)
+	stopped bool
+	err     error // Returned by the next start
+}
+
+func (f *fakeSession) provide(notify func(locked bool)) (func(), error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.notify, f.stopped = notify, false
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.stopped = true
+	}, nil
+}
+
+// send reports changes in order, as the session window's message loop does
+func (f *fakeSession) send(states ...bool) {
+	f.mu.Lock()
+	notify := f.notify
+	f.mu.Unlock()
+	for _, locked := range states {
+		notify(locked)
+	}
+}
+
+// TestSessionWatcher drives the watcher through a fake provider: changes reach the
+// callback in order and once each, and nothing does after Stop
+func TestSessionWatcher(t *testing.T) {
+	fake := &fakeSession{}
+	watcher := NewSessionWatcherWithProvider(fake.provide)
+	var got []bool
+	watcher.SetCallback(func(locked bool) { got = append(got, locked) })
+
+	fake.err = errors.New("no session notifications")
+	if err := watcher.Start(); err == nil {
+		t.Fatal("a failing provider started")
+	}
+	fake.err = nil
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("failed to start after a failed start: %v", err)
+	}
+	if err := watcher.Start(); err == nil {
+		t.Error("started twice")
+	}
+
+	// Lock and unlock in quick succession, with repeats the system may send
+	fake.send(true, true, false, true, false, false)
+	if want := []bool{true, false, true, false}; !slices.Equal(got, want) {
+		t.Errorf("callback got %v, want %v", got, want)
+	}
+	if watcher.IsLocked() {
+		t.Error("locked after the last unlock")
+	}
+
+	watcher.Stop()
+	if !fake.stopped {
+		t.Error("the provider wasn't stopped")
+	}
+	got = nil
+	fake.send(true)
+	if len(got) != 0 {
+		t.Errorf("callback got %v after Stop", got)
+	}
+}
@@ -0,0 +1,178 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32                               = windows.NewLazySystemDLL("user32.dll")
+	wtsapi32                             = windows.NewLazySystemDLL("wtsapi32.dll")
+	procRegisterClassExW                 = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW                  = user32.NewProc("CreateWindowExW")
+	procDestroyWindow                    = user32.NewProc("DestroyWindow")
+	procDefWindowProcW                   = user32.NewProc("DefWindowProcW")
+	procGetMessageW                      = user32.NewProc("GetMessageW")
+	procDispatchMessageW                 = user32.NewProc("DispatchMessageW")
+	procPostMessageW                     = user32.NewProc("PostMessageW")
+	procPostQuitMessage                  = user32.NewProc("PostQuitMessage")
+	procWTSRegisterSessionNotification   = wtsapi32.NewProc("WTSRegisterSessionNotification")
+	procWTSUnRegisterSessionNotification = wtsapi32.NewProc("WTSUnRegisterSessionNotification")
+)
+
+const (
+	wmDestroy            = 0x0002
+	wmClose              = 0x0010
+	wmWTSSessionChange   = 0x02B1
+	wtsSessionLock       = 0x7
+	wtsSessionUnlock     = 0x8
+	notifyForThisSession = 0
+	sessionWindowClass   = "PanoSessionWatcher"
+	hwndMessage          = ^uintptr(2) // HWND_MESSAGE (-3)
+)
+
+// wndClassEx mirrors WNDCLASSEXW
+type wndClassEx struct {
+	Size       uint32
+	Style      uint32
+	WndProc    uintptr
+	ClsExtra   int32
+	WndExtra   int32
+	Instance   windows.Handle
+	Icon       windows.Handle
+	Cursor     windows.Handle
+	Background windows.Handle
+	MenuName   *uint16
+	ClassName  *uint16
+	IconSm     windows.Handle
+}
+
+// msg mirrors MSG
+type msg struct {
+	Hwnd    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	PtX     int32
+	PtY     int32
+}
+
+var (
+	sessionMu     sync.Mutex
+	sessionNotify func(locked bool) // Receives the changes while a watcher runs; one runs at a time
+
+	// The window class is registered once, with this procedure, so it can't close over
+	// the notify of one watcher; it calls the current one synchronously, which keeps
+	// changes in the order the message loop receives them
+	sessionWndProc = syscall.NewCallback(func(hwnd, message, wParam, lParam uintptr) uintptr {
+		switch message {
+		case wmWTSSessionChange:
+			switch wParam {
+			case wtsSessionLock:
+				deliverSession(true)
+			case wtsSessionUnlock:
+				deliverSession(false)
+			}
+			return 0
+		case wmDestroy:
+			procWTSUnRegisterSessionNotification.Call(hwnd)
+			procPostQuitMessage.Call(0)
+			return 0
+		}
+		ret, _, _ := procDefWindowProcW.Call(hwnd, message, wParam, lParam)
+		return ret
+	})
+)
+
+// deliverSession passes a lock state change to the running watcher, if any
+func deliverSession(locked bool) {
+	sessionMu.Lock()
+	notify := sessionNotify
+	sessionMu.Unlock()
+	if notify != nil {
+		notify(locked)
+	}
+}
+
+// watchSession creates a hidden message-only window that receives
+// WM_WTSSESSION_CHANGE and forwards lock/unlock to notify
+func watchSession(notify func(locked bool)) (func(), error) {
+	ready := make(chan error, 1)
+	hwndCh := make(chan uintptr, 1)
+
+	sessionMu.Lock()
+	sessionNotify = notify
+	sessionMu.Unlock()
+
+	go func() {
+		// The window and its message loop must stay on one OS thread
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		className, _ := syscall.UTF16PtrFromString(sessionWindowClass)
+		wc := wndClassEx{
+			WndProc:   sessionWndProc,
+			ClassName: className,
+		}
+		wc.Size = uint32(unsafe.Sizeof(wc))
+		procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc))) // Fails harmlessly if already registered
+
+		hwnd, _, err := procCreateWindowExW.Call(
+			0,
+			uintptr(unsafe.Pointer(className)),
+			0,
+			0, 0, 0, 0, 0,
+			hwndMessage,
+			0, 0, 0,
+		)
+		if hwnd == 0 {
+			ready <- fmt.Errorf("failed to create session window: %v", err)
+			return
+		}
+
+		ret, _, err := procWTSRegisterSessionNotification.Call(hwnd, notifyForThisSession)
+		if ret == 0 {
+			procDestroyWindow.Call(hwnd)
+			ready <- fmt.Errorf("failed to register session notification: %v", err)
+			return
+		}
+
+		hwndCh <- hwnd
+		ready <- nil
+
+		var m msg
+		for {
+			ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+			if int32(ret) <= 0 {
+				return
+			}
+			procDispatchMessageW.Call(uintptr(unsafe.Pointer(&m)))
+		}
+	}()
+
+	if err := <-ready; err != nil {
+		sessionMu.Lock()
+		sessionNotify = nil
+		sessionMu.Unlock()
+		return nil, err
+	}
+	hwnd := <-hwndCh
+
+	stop := func() {
+		sessionMu.Lock()
+		sessionNotify = nil
+		sessionMu.Unlock()
+		// WM_CLOSE runs DestroyWindow on the window's own thread, ending the loop
+		procPostMessageW.Call(hwnd, wmClose, 0, 0)
+	}
+	return stop, nil
+}
@@ -0,0 +1,70 @@
+//go:build windows && ci
+// +build windows,ci
+
+package system
+
+import (
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+var procFindWindowExW = user32.NewProc("FindWindowExW")
+
+// TestSessionWindowOrder posts lock and unlock messages to the real session window in
+// quick succession: the callback must see every one in the order posted and end
+// unlocked, or capture would stay paused after an unlock
+func TestSessionWindowOrder(t *testing.T) {
+	watcher := NewSessionWatcher()
+	var mu sync.Mutex
+	var got []bool
+	watcher.SetCallback(func(locked bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, locked)
+	})
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer watcher.Stop()
+
+	className, _ := syscall.UTF16PtrFromString(sessionWindowClass)
+	hwnd, _, _ := procFindWindowExW.Call(hwndMessage, 0, uintptr(unsafe.Pointer(className)), 0)
+	if hwnd == 0 {
+		t.Fatal("session window not found")
+	}
+
+	want := make([]bool, 1000)
+	for i := range want {
+		want[i] = i%2 == 0
+		change := uintptr(wtsSessionUnlock)
+		if want[i] {
+			change = wtsSessionLock
+		}
+		if ok, _, err := procPostMessageW.Call(hwnd, wmWTSSessionChange, change, 0); ok == 0 {
+			t.Fatalf("failed to post message: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= len(want) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(got, want) {
+		t.Errorf("callback got %d changes out of order or missing, want %d alternating", len(got), len(want))
+	}
+	if watcher.IsLocked() {
+		t.Error("locked after the last unlock")
+	}
+}
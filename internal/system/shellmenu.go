@@ -0,0 +1,57 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ShellMenuName is the label of the Explorer entries
+const ShellMenuName = "Pano'ya ekle"
+
+// ShellIntegration adds and removes the Explorer entries that send files to Pano
+type ShellIntegration interface {
+	IsEnabled() (bool, error)
+	Enable() error
+	Disable() error
+}
+
+// ShellMenuManager registers a Send To shortcut and a context menu verb for all files,
+// both running "pano add --file <path>"
+type ShellMenuManager struct {
+	exePath string
+}
+
+// NewShellMenuManager creates a manager for the running executable
+func NewShellMenuManager() (*ShellMenuManager, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	return &ShellMenuManager{exePath: filepath.Clean(exePath)}, nil
+}
+
+// Supported reports whether the platform has entries to register
+func (s *ShellMenuManager) Supported() bool {
+	return shellMenuSupported
+}
+
+// IsEnabled reports whether any of the entries is registered
+func (s *ShellMenuManager) IsEnabled() (bool, error) {
+	return shellMenuInstalled()
+}
+
+// Enable registers both entries for the current user
+func (s *ShellMenuManager) Enable() error {
+	if err := installShellMenu(s.exePath); err != nil {
+		// Don't leave half an integration behind
+		removeShellMenu()
+		return err
+	}
+	return nil
+}
+
+// Disable removes every key and file Enable created; missing ones are not an error
+func (s *ShellMenuManager) Disable() error {
+	return removeShellMenu()
+}
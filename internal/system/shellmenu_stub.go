@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package system
+
+import "errors"
+
+// errShellMenuUnsupported is returned where there is no Explorer to integrate with
+var errShellMenuUnsupported = errors.New("shell integration is only available on Windows")
+
+// shellMenuInstalled always reports false on non-Windows platforms
+func shellMenuInstalled() (bool, error) {
+	return false, nil
+}
+
+// installShellMenu is not supported on non-Windows platforms
+func installShellMenu(exePath string) error {
+	return errShellMenuUnsupported
+}
+
+// removeShellMenu has nothing to remove on non-Windows platforms
+func removeShellMenu() error {
+	return nil
+}
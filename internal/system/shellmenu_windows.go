@@ -0,0 +1,117 @@
+//go:build windows
+// +build windows
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// The verb is a classic one under HKCU, so no admin rights are needed; Windows 11 lists it
+// under "Show more options" since its compact menu only takes packaged extensions
+const (
+	shellVerbPath    = `Software\Classes\*\shell\PanoAdd`
+	shellCommandPath = shellVerbPath + `\command`
+)
+
+// sendToShortcut returns the path of the Send To shortcut
+func sendToShortcut() (string, error) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return "", fmt.Errorf("APPDATA environment variable not set")
+	}
+	return filepath.Join(appData, "Microsoft", "Windows", "SendTo", ShellMenuName+".lnk"), nil
+}
+
+// shellMenuInstalled reports whether the verb or the shortcut exists
+func shellMenuInstalled() (bool, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, shellCommandPath, registry.QUERY_VALUE)
+	if err == nil {
+		key.Close()
+		return true, nil
+	}
+
+	path, err := sendToShortcut()
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return true, nil
+	}
+	return false, nil
+}
+
+// installShellMenu writes the context menu verb and the Send To shortcut
+func installShellMenu(exePath string) error {
+	verb, _, err := registry.CreateKey(registry.CURRENT_USER, shellVerbPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to create registry key: %w", err)
+	}
+	defer verb.Close()
+	if err := verb.SetStringValue("", ShellMenuName); err != nil {
+		return fmt.Errorf("failed to set registry value: %w", err)
+	}
+	if err := verb.SetStringValue("Icon", exePath); err != nil {
+		return fmt.Errorf("failed to set registry value: %w", err)
+	}
+
+	command, _, err := registry.CreateKey(registry.CURRENT_USER, shellCommandPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to create registry key: %w", err)
+	}
+	defer command.Close()
+	if err := command.SetStringValue("", fmt.Sprintf(`"%s" add --file "%%1"`, exePath)); err != nil {
+		return fmt.Errorf("failed to set registry value: %w", err)
+	}
+
+	return createSendToShortcut(exePath)
+}
+
+// createSendToShortcut creates the .lnk through WScript.Shell, the simplest way to
+// write a shell link without COM bindings; Send To appends the selected paths to its arguments
+func createSendToShortcut(exePath string) error {
+	path, err := sendToShortcut()
+	if err != nil {
+		return err
+	}
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	script := fmt.Sprintf(
+		"$s = (New-Object -ComObject WScript.Shell).CreateShortcut(%s); $s.TargetPath = %s; $s.Arguments = 'add --file'; $s.IconLocation = %s; $s.Save()",
+		quote(path), quote(exePath), quote(exePath+",0"))
+
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: windows.CREATE_NO_WINDOW}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create Send To shortcut: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// removeShellMenu deletes the verb, its command key and the shortcut
+// Keys are deleted child first, since DeleteKey refuses keys with subkeys
+func removeShellMenu() error {
+	for _, path := range []string{shellCommandPath, shellVerbPath} {
+		if err := registry.DeleteKey(registry.CURRENT_USER, path); err != nil && err != registry.ErrNotExist {
+			return fmt.Errorf("failed to delete registry key: %w", err)
+		}
+	}
+
+	path, err := sendToShortcut()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove Send To shortcut: %w", err)
+	}
+	return nil
+}
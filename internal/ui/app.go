@@ -1,7 +1,11 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,10 +16,25 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"pano/internal/clipboard"
+	"pano/internal/settings"
 	"pano/internal/storage"
 	"pano/internal/system"
 )
 
+// ShowSource tells Show how the window was opened so focus lands in the right place
+type ShowSource int
+
+const (
+	ShowSourceTray   ShowSource = iota // Opened from the tray menu, focus the list
+	ShowSourceHotkey                   // Opened with the hotkey, focus the search entry
+)
+
+// defaultWindowSize is the main window size at 100% scaling
+var defaultWindowSize = fyne.NewSize(380, 520)
+
+// focusDelay gives the window time to be mapped before canvas focus is requested
+const focusDelay = 50 * time.Millisecond
+
 type App struct {
 	fyneApp     fyne.App
 	window      fyne.Window
@@ -23,26 +42,80 @@ type App struct {
 	monitor     *clipboard.Monitor
 	list        *ClipboardList
 	autostart   *system.AutostartManager
+	shellMenu   system.ShellIntegration // Explorer entries, nil where unavailable
+	ipc         *system.IPCServer       // Receives "pano add", nil until StartIPC
 	isVisible   bool
 	statusLabel *widget.Label
 	isDarkMode  bool
 	toastMu     sync.Mutex
+	toasts      *toastManager
+	fader       *windowFader // Show and hide fades, see fade.go
+	searchEntry *widget.Entry
+	searchError *widget.Label // Inline regex error under the search box
+	tray        trayRefresher
+	hotkeys     *system.HotkeyManager
+	config      *clipboard.Config // Effective startup configuration, kept in sync by the settings dialog
+	settings    *settingsModel    // Preferences (with a file fallback when Fyne can't persist), observable per key
+
+	activeChips map[string]bool           // Quick filters, saved as active_chips so the window reopens on the same view
+	chipButtons map[string]*widget.Button // Chip buttons by label, for their item counts
+
+	integrityMu     sync.Mutex
+	cancelIntegrity context.CancelFunc // Cancels a running integrity pass
+
+	digestMu   sync.Mutex
+	digestStop chan struct{} // Stops the weekly digest checks, see digest.go
+
+	retentionMu   sync.Mutex
+	retentionStop chan struct{}  // Stops the hourly prune, see retention.go
+	retentionWG   sync.WaitGroup // The prune goroutine, waited for by StopRetention
+
+	iconRenderer func(size int) fyne.Resource // Draws the app icon for the current DPI
+	stopDPIWatch func()                       // Removes the WM_DPICHANGED hook
+
+	reloading     bool // A retry of the failed database load is running
+	captureFailed bool // The last capture couldn't be saved, see reject.go (guarded by toastMu)
+
+	pausedIndicator *widget.Button // "Duraklatıldı" in the header while capture is paused, see pause.go
+
+	stackMu      sync.Mutex
+	stackSeq     uint32        // Clipboard sequence after the paste stack last wrote it
+	stackHasSeq  bool          // stackSeq is meaningful; false where the platform has no sequence
+	stackPending bool          // A Ctrl+V is waiting for pasteSettleDelay
+	overlay      *pasteOverlay // Paste stack position, nil when no queue runs (UI thread only)
+
+	appBar *system.AppBar // Reserves the screen column of the docked sidebar, see dock.go
+	docked bool           // The window is docked as a sidebar (UI thread only)
+	dockUI dockControls
+
+	gameMu      sync.Mutex
+	gameMode    bool // A full-screen game is running (see gamemode.go)
+	hookStopped bool // The keyboard hook was removed for game mode
+
+	selection *system.SelectionWatcher // Copies text selected with the mouse, nil until BindSelection (see selection.go)
+
+	draftsOffered bool // Drafts left by a crash were pointed out, see drafts.go (UI thread only)
 }
 
-func NewApp(fyneApp fyne.App, db *storage.Database, autostart *system.AutostartManager) *App {
+func NewApp(fyneApp fyne.App, db *storage.Database, autostart *system.AutostartManager, fileConfig, flagConfig *clipboard.Config) *App {
+	// Preferences are translated into options once; settings use the runtime setters afterwards
+	prefs := newSettingsModel(settings.Open(fyneApp))
+	cfg := effectiveConfig(prefs, fileConfig, flagConfig)
+
 	app := &App{
-		fyneApp:   fyneApp,
-		manager:   clipboard.NewManager(db),
-		monitor:   clipboard.NewMonitor(db),
-		autostart: autostart,
-		isVisible: false,
+		fyneApp:     fyneApp,
+		manager:     clipboard.NewManager(db, cfg.ManagerOptions()...),
+		monitor:     clipboard.NewMonitor(db, cfg.MonitorOptions()...),
+		config:      cfg,
+		settings:    prefs,
+		autostart:   autostart,
+		appBar:      system.NewAppBar(),
+		isVisible:   false,
+		activeChips: make(map[string]bool),
+		chipButtons: make(map[string]*widget.Button),
 	}
 
-	app.isDarkMode = fyneApp.Preferences().BoolWithFallback("dark_mode", true)
-
-	// Load saved max items limit
-	savedLimit := fyneApp.Preferences().IntWithFallback("max_items", 100)
-	app.manager.SetMaxItems(savedLimit)
+	app.isDarkMode = prefs.BoolWithFallback("dark_mode", true)
 
 	if app.isDarkMode {
 		fyneApp.Settings().SetTheme(NewDarkTheme())
@@ -51,10 +124,17 @@ func NewApp(fyneApp fyne.App, db *storage.Database, autostart *system.AutostartM
 	}
 
 	app.window = fyneApp.NewWindow("Pano")
-	app.window.Resize(fyne.NewSize(380, 520))
+	app.window.Resize(defaultWindowSize)
 	app.window.CenterOnScreen()
 
+	app.toasts = newToastManager()
+	app.fader = newWindowFader()
 	app.buildUI()
+	app.bindSettings()
+
+	if err := app.applyRedactionRules(); err != nil {
+		log.Printf("Warning: Ignoring redaction rules: %v", err)
+	}
 
 	app.window.SetCloseIntercept(func() {
 		app.Hide()
@@ -62,39 +142,99 @@ func NewApp(fyneApp fyne.App, db *storage.Database, autostart *system.AutostartM
 
 	// Set limit warning callback on monitor
 	app.monitor.SetOnLimitWarn(func(remaining int) {
-		if remaining == 0 {
+		if remaining == 0 && app.manager.GetLimitPolicy() == storage.LimitEvictOldest {
+			app.sendNotification("Limit Doldu", "Pano limiti doldu! Yeni kopyalamalar için en eski sabitlenmemiş öğeler silinecek.")
+		} else if remaining == 0 {
 			app.sendNotification("Limit Doldu", "Pano limiti doldu! Yeni kopyalamalar kaydedilmiyor.")
 		} else {
 			app.sendNotification("Pano Uyarısı", fmt.Sprintf("Sadece %d alan kaldı! Yakında kopyaladıkların kaydedilmeyecek.", remaining))
 		}
 	})
 
+	app.monitor.SetOnReject(app.handleCaptureError)
+
+	app.monitor.SetOnLoop(func(itemType string) {
+		suspects := "none found (possibly Windows cloud clipboard sync)"
+		if managers := system.RunningClipboardManagers(); len(managers) > 0 {
+			suspects = strings.Join(managers, ", ")
+		}
+		log.Printf("Warning: Clipboard loop detected (%s content re-set without input), captures suppressed for a while; other clipboard managers: %s", itemType, suspects)
+	})
+
+	// Changes are written in the background, so a failed write has no caller to tell
+	app.manager.SetOnSaveError(func(err error) {
+		log.Printf("Warning: Failed to save database: %v", err)
+		fyne.Do(func() {
+			app.sendNotification("Kaydedilemedi", "Geçmiş diske yazılamadı: "+err.Error())
+			app.setCaptureFailed(true)
+		})
+	})
+
+	app.monitor.SetOnDoubleCopy(func(err error) {
+		switch {
+		case err == nil:
+			app.sendNotification("Sabitlendi", "Öğe sabitlendi (çift kopyalama)")
+		case errors.Is(err, storage.ErrPinLimitReached):
+			app.sendNotification("Sabitlenemedi", fmt.Sprintf("En fazla %d öğe sabitlenebilir.", app.manager.GetPinLimit()))
+		default:
+			log.Printf("Warning: Double copy pin failed: %v", err)
+		}
+	})
+
+	app.monitor.SetOnLockChange(func(locked bool) {
+		fyne.Do(func() {
+			app.updateStatus()
+		})
+	})
+
+	app.monitor.SetOnPauseChange(func(paused bool) {
+		fyne.Do(func() {
+			app.updatePausedIndicator()
+		})
+		app.refreshTray()
+	})
+
 	app.monitor.SetOnChange(func(itemType string, content []byte) {
 		app.list.Refresh()
+		app.setCaptureFailed(false)
 		app.updateStatus()
+		app.refreshTray()
 	})
 
 	return app
 }
 
 func (a *App) sendNotification(title, message string) {
+	// Notifications would pull a full-screen game out of focus
+	if a.IsGameMode() {
+		return
+	}
 	notification := fyne.NewNotification(title, message)
 	a.fyneApp.SendNotification(notification)
 }
 
 func (a *App) buildUI() {
 	a.list = NewClipboardList(a.manager)
+	a.list.SetKeepLineBreaks(a.settings.BoolWithFallback("keep_line_breaks", false))
+	a.list.SetDisabledActions(a.disabledSmartActions())
+	a.applyFreshness()
+	a.list.SetPinnedCollapsed(a.settings.BoolWithFallback("pinned_collapsed", false))
+	a.list.SetOnSectionToggle(func(collapsed bool) {
+		a.settings.SetBool("pinned_collapsed", collapsed)
+	})
+	a.list.SetOnRetry(a.retryLoad)
+	a.list.SetOnRecover(a.promptRecovery)
 
 	a.list.SetCallbacks(
 		func(id string) {
-			if err := a.manager.CopyToClipboard(id); err != nil {
-				dialog.ShowError(err, a.window)
-			} else {
-				a.showToast("Panoya kopyalandı")
-			}
+			a.copyItem(id, nil)
 		},
 		func(id string) {
-			if err := a.manager.PinItem(id); err != nil {
+			if err := a.manager.PinItem(id); errors.Is(err, storage.ErrPinLimitReached) {
+				dialog.ShowInformation("Sabitleme sınırı",
+					fmt.Sprintf("En fazla %d öğe sabitlenebilir. Yeni bir öğe sabitlemek için önce birinin sabitlemesini kaldırın.", a.manager.GetPinLimit()),
+					a.window)
+			} else if err != nil {
 				dialog.ShowError(err, a.window)
 			} else {
 				a.list.Refresh()
@@ -105,12 +245,129 @@ func (a *App) buildUI() {
 			if err := a.manager.DeleteItem(id); err != nil {
 				dialog.ShowError(err, a.window)
 			} else {
-				a.list.Refresh()
-				a.updateStatus()
+				a.afterItemsChanged()
+				a.toasts.ShowWithAction("Öğe silindi", "Geri Al", func() {
+					a.undoDelete([]string{id})
+				})
 			}
 		},
 	)
 
+	a.list.SetOnCopyOriginal(func(id string) {
+		if err := a.manager.CopyOriginalToClipboard(id); err != nil {
+			dialog.ShowError(err, a.window)
+		} else {
+			a.showToast("Orijinali kopyalandı")
+		}
+	})
+
+	a.list.SetOnCopyWithNewlines(func(id string, mode clipboard.NewlineMode) {
+		if err := a.manager.CopyWithNewlines(id, mode); err != nil {
+			dialog.ShowError(err, a.window)
+		} else {
+			a.showToast("Panoya kopyalandı")
+		}
+	})
+
+	a.list.SetOnCopyText(a.copyText)
+
+	a.list.SetOnSmartAction(func(item storage.ClipboardItem, action smartAction) {
+		action.run(a, item)
+	})
+
+	a.list.SetOnEditTags(a.showTagEditor)
+	a.list.SetOnRename(a.showTitleEditor)
+	a.list.SetOnEdit(a.showEditItemDialog)
+
+	a.list.SetOnProtect(a.showProtectDialog)
+
+	a.list.SetOnCopyEncoded(a.copyImageEncoded)
+
+	a.list.SetOnDetails(a.showItemDetails)
+
+	a.searchEntry = widget.NewEntry()
+	a.searchEntry.SetPlaceHolder("Ara... (sha256:önek)")
+	a.searchEntry.OnChanged = func(query string) {
+		a.list.SetQuery(query)
+		a.list.Refresh()
+		a.updateStatus()
+	}
+	a.searchEntry.OnSubmitted = func(string) {
+		a.copyTopResult()
+	}
+
+	searchModeSelect := widget.NewSelect(searchModeLabels(), func(selected string) {
+		for _, opt := range searchModeOptions {
+			if opt.label == selected {
+				a.list.SetSearchMode(opt.mode)
+				a.settings.SetInt("search_mode", int(opt.mode))
+			}
+		}
+		a.list.Refresh()
+		a.updateStatus()
+	})
+	savedMode := searchMode(a.settings.IntWithFallback("search_mode", int(searchNormal)))
+	for _, opt := range searchModeOptions {
+		if opt.mode == savedMode {
+			a.list.SetSearchMode(opt.mode)
+			searchModeSelect.Selected = opt.label
+		}
+	}
+
+	sortSelect := widget.NewSelect(sortOrderLabels(), func(selected string) {
+		for _, opt := range sortOrderOptions {
+			if opt.label == selected {
+				a.list.SetSortOrder(opt.order)
+				a.settings.SetString("sort_order", string(opt.order))
+			}
+		}
+		a.list.Refresh()
+	})
+	savedOrder := sortOrder(a.settings.StringWithFallback("sort_order", string(sortNewest)))
+	for _, opt := range sortOrderOptions {
+		if opt.order == savedOrder {
+			a.list.SetSortOrder(opt.order)
+			sortSelect.Selected = opt.label
+		}
+	}
+
+	a.searchError = widget.NewLabel("")
+	a.searchError.Importance = widget.DangerImportance
+	a.searchError.Wrapping = fyne.TextWrapWord
+	a.searchError.Hide()
+
+	compareBtn := widget.NewButtonWithIcon("", theme.ViewRestoreIcon(), func() {
+		a.compareSelected()
+	})
+	compareBtn.Disable()
+	stackBtn := widget.NewButtonWithIcon("Sıraya al", theme.ListIcon(), func() {
+		a.queueSelected()
+	})
+	stackBtn.Disable()
+	deleteSelectedBtn := widget.NewButtonWithIcon("Seçilenleri Sil", theme.DeleteIcon(), func() {
+		a.showDeleteSelectedDialog()
+	})
+	deleteSelectedBtn.Importance = widget.DangerImportance
+	deleteSelectedBtn.Hide()
+	a.list.SetOnSelectionChange(func() {
+		selected := len(a.list.Selected())
+		if selected > 0 {
+			deleteSelectedBtn.Show()
+		} else {
+			deleteSelectedBtn.Hide()
+		}
+		if selected == 2 {
+			compareBtn.Enable()
+		} else {
+			compareBtn.Disable()
+		}
+		if selected >= 2 {
+			stackBtn.Enable()
+		} else {
+			stackBtn.Disable()
+		}
+	})
+
 	titleLabel := widget.NewLabelWithStyle("Pano Geçmişi", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
 
 	refreshBtn := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
@@ -119,6 +376,18 @@ func (a *App) buildUI() {
 		a.showToast("Yenilendi")
 	})
 
+	newItemBtn := widget.NewButtonWithIcon("Yeni öğe", theme.ContentAddIcon(), func() {
+		a.showNewItemDialog()
+	})
+
+	captureBtn := widget.NewButtonWithIcon("", theme.ContentPasteIcon(), func() {
+		go a.CaptureNow()
+	})
+
+	archiveBtn := widget.NewButtonWithIcon("", theme.StorageIcon(), func() {
+		a.showArchiveDialog()
+	})
+
 	settingsBtn := widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
 		a.showSettingsDialog()
 	})
@@ -128,7 +397,33 @@ func (a *App) buildUI() {
 	})
 	clearBtn.Importance = widget.DangerImportance
 
-	header := container.NewBorder(nil, nil, titleLabel, container.NewHBox(refreshBtn, settingsBtn, clearBtn))
+	jump := newJumpControl(a.list)
+
+	var moreBtn *widget.Button
+	moreBtn = widget.NewButtonWithIcon("", theme.MoreVerticalIcon(), func() {
+		menu := fyne.NewMenu("",
+			fyne.NewMenuItem("En yeniye git", jump.ToTop),
+			fyne.NewMenuItem("En eskiye git", func() {
+				a.list.ShowAll()
+				jump.ToBottom()
+			}),
+		)
+		pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(moreBtn).AddXY(0, moreBtn.Size().Height)
+		widget.ShowPopUpMenuAtPosition(menu, a.window.Canvas(), pos)
+	})
+
+	dockBtn := widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
+		a.toggleDock()
+	})
+	if !a.appBar.Supported() {
+		dockBtn.Hide()
+	}
+	a.dockUI = dockControls{title: titleLabel, newItem: newItemBtn, clear: clearBtn, stack: stackBtn, deleteSelected: deleteSelectedBtn, toggle: dockBtn}
+
+	a.pausedIndicator = a.newPausedIndicator()
+	header := container.NewBorder(nil, nil, container.NewHBox(titleLabel, a.pausedIndicator), container.NewHBox(newItemBtn, refreshBtn, captureBtn, archiveBtn, settingsBtn, clearBtn, dockBtn, moreBtn))
+	searchRow := container.NewBorder(nil, a.searchError, nil, container.NewHBox(searchModeSelect, sortSelect, compareBtn, stackBtn, deleteSelectedBtn), a.searchEntry)
+	chipRow := a.buildChipRow()
 
 	a.statusLabel = widget.NewLabel("")
 	a.updateStatus()
@@ -137,31 +432,118 @@ func (a *App) buildUI() {
 
 	footer := container.NewBorder(nil, nil, a.statusLabel, shortcutLabel)
 
-	scroll := container.NewVScroll(a.list)
-
 	content := container.NewBorder(
-		container.NewVBox(header, widget.NewSeparator()),
+		container.NewVBox(header, searchRow, chipRow, widget.NewSeparator()),
 		container.NewVBox(widget.NewSeparator(), footer),
 		nil, nil,
-		scroll,
+		jump.Overlay(),
 	)
 
-	a.window.SetContent(container.NewPadded(content))
+	a.window.SetContent(container.NewStack(container.NewPadded(content), a.toasts.Overlay(), a.fader.Overlay()))
 }
 
-func (a *App) showToast(message string) {
-	a.toastMu.Lock()
-	defer a.toastMu.Unlock()
-	
-	a.statusLabel.SetText("[OK] " + message)
+// buildChipRow creates the horizontally scrollable quick filter chips
+func (a *App) buildChipRow() fyne.CanvasObject {
+	a.activeChips = parseActiveChips(a.settings.StringWithFallback("active_chips", ""))
+	chips := container.NewHBox()
+	for _, chip := range filterChips {
+		label := chip.label
+		var btn *widget.Button
+		btn = widget.NewButton(label, func() {
+			a.activeChips[label] = !a.activeChips[label]
+			if a.activeChips[label] {
+				btn.Importance = widget.HighImportance
+			} else {
+				delete(a.activeChips, label)
+				btn.Importance = widget.MediumImportance
+			}
+			btn.Refresh()
+			a.settings.SetString("active_chips", formatActiveChips(a.activeChips))
+			a.applyChipFilter()
+		})
+		if a.activeChips[label] {
+			btn.Importance = widget.HighImportance
+		}
+		a.chipButtons[label] = btn
+		chips.Add(btn)
+	}
+	a.applyChipFilter()
+	return container.NewHScroll(chips)
+}
+
+// applyChipFilter pushes the active chips to the list
+func (a *App) applyChipFilter() {
+	if len(a.activeChips) == 0 {
+		a.list.SetFilter(nil)
+	} else {
+		a.list.SetFilter(activeChipFilter(a.activeChips))
+	}
+	a.list.Refresh()
+	if a.statusLabel != nil {
+		a.updateStatus()
+	}
+}
+
+// copyText copies a piece of an item, like a contact field or a smart action's result
+func (a *App) copyText(text string) {
+	if err := a.manager.CopyText(text); err != nil {
+		dialog.ShowError(err, a.window)
+	} else {
+		a.showToast("Panoya kopyalandı")
+	}
+}
+
+// copyImageEncoded copies an image item as base64 text, with the data URI prefix if dataURI
+// The text isn't captured as a new item unless capture_encoded_copies is on
+func (a *App) copyImageEncoded(id string, dataURI bool) {
+	text, err := a.manager.EncodeImage(id, dataURI)
+	if errors.Is(err, clipboard.ErrEncodedTooLarge) {
+		dialog.ShowInformation("Görsel çok büyük",
+			fmt.Sprintf("Base64 metni %s sınırını aşıyor, kopyalanmadı.", formatSize(clipboard.MaxEncodedImageSize)), a.window)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+
+	if !a.settings.BoolWithFallback("capture_encoded_copies", false) {
+		a.monitor.IgnoreNext("text", []byte(text))
+	}
+	if err := a.manager.CopyText(text); err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	if dataURI {
+		a.showToast("data URI olarak kopyalandı")
+	} else {
+		a.showToast("base64 olarak kopyalandı")
+	}
+}
+
+// retryLoad reads the database again after a failed load, showing the loading view meanwhile
+func (a *App) retryLoad() {
+	a.reloading = true
+	a.updateStatus()
 	go func() {
-		time.Sleep(1500 * time.Millisecond)
-		a.toastMu.Lock()
-		a.updateStatusInternal()
-		a.toastMu.Unlock()
+		err := a.manager.Reload()
+		fyne.Do(func() {
+			a.reloading = false
+			a.list.Refresh()
+			a.updateStatus()
+			a.refreshTray()
+			if err == nil {
+				a.showToast("Pano geçmişi yüklendi")
+			}
+		})
 	}()
 }
 
+// showToast shows a transient message; safe from any goroutine
+func (a *App) showToast(message string) {
+	a.toasts.Show(message)
+}
+
 func (a *App) updateStatus() {
 	a.toastMu.Lock()
 	defer a.toastMu.Unlock()
@@ -169,10 +551,41 @@ func (a *App) updateStatus() {
 }
 
 func (a *App) updateStatusInternal() {
-	total := a.manager.GetItemCount()
-	maxItems := a.manager.GetMaxItems()
-	pinned := a.manager.GetPinnedCount()
-	a.statusLabel.SetText(fmt.Sprintf("%d/%d öğe - %d sabit", total, maxItems, pinned))
+	counts := a.manager.Counts()
+	total := counts.Total()
+	status := fmt.Sprintf("%d/%d öğe • %d sabit • %s", counts.Active, counts.Limit, counts.Pinned, sizeUsage(counts))
+	if counts.Archived > 0 {
+		status += fmt.Sprintf(" • %d arşivde", counts.Archived)
+	}
+	if counts.Trashed > 0 {
+		status += fmt.Sprintf(" • %d çöpte", counts.Trashed)
+	}
+	if a.list.IsFiltered() {
+		status = fmt.Sprintf("%d gösteriliyor - ", a.list.VisibleCount()) + status
+	}
+	for _, chip := range filterChips {
+		if btn := a.chipButtons[chip.label]; btn != nil && btn.Text != chipText(chip, counts) {
+			btn.SetText(chipText(chip, counts))
+		}
+	}
+	if a.monitor.IsLocked() {
+		status += " - Kilitli"
+	}
+	if a.captureFailed {
+		status += " - Kaydetme hatası"
+	}
+	a.statusLabel.SetText(status)
+
+	loadErr := a.manager.LoadError()
+	a.list.SetState(selectListState(a.reloading, loadErr, total, a.list.VisibleCount(), a.list.IsFiltered()), loadErr)
+
+	// Regex errors and timeouts are shown under the search box
+	if err := a.list.SearchError(); err != nil {
+		a.searchError.SetText(err.Error())
+		a.searchError.Show()
+	} else {
+		a.searchError.Hide()
+	}
 }
 
 func (a *App) showSettingsDialog() {
@@ -182,23 +595,62 @@ func (a *App) showSettingsDialog() {
 		return
 	}
 
+	// Widgets follow changes made elsewhere (tray, main window) while the dialog is open
+	unsubscribe := make([]func(), 0)
+	bind := func(key string, fn func()) {
+		unsubscribe = append(unsubscribe, a.settings.Subscribe(key, fn))
+	}
+
 	// Theme selection
 	themeLabel := widget.NewLabelWithStyle("Tema", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
 	themeSelect := widget.NewSelect([]string{"Koyu Tema", "Açık Tema"}, func(s string) {
-		if s == "Koyu Tema" {
-			a.isDarkMode = true
-			a.fyneApp.Settings().SetTheme(NewDarkTheme())
+		a.settings.SetBool("dark_mode", s == "Koyu Tema")
+	})
+	syncTheme := func() {
+		if a.isDarkMode {
+			themeSelect.SetSelected("Koyu Tema")
 		} else {
-			a.isDarkMode = false
-			a.fyneApp.Settings().SetTheme(NewLightTheme())
+			themeSelect.SetSelected("Açık Tema")
 		}
-		a.fyneApp.Preferences().SetBool("dark_mode", a.isDarkMode)
-		a.list.Refresh()
+	}
+	syncTheme()
+	bind("dark_mode", syncTheme)
+
+	// Preview
+	previewLabel := widget.NewLabelWithStyle("Önizleme", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	lineBreaksCheck := widget.NewCheck("Satır sonlarını koru", func(checked bool) {
+		a.settings.SetBool("keep_line_breaks", checked)
 	})
-	if a.isDarkMode {
-		themeSelect.SetSelected("Koyu Tema")
-	} else {
-		themeSelect.SetSelected("Açık Tema")
+	lineBreaksCheck.Checked = a.list.keepLineBreaks
+	bind("keep_line_breaks", func() {
+		lineBreaksCheck.SetChecked(a.list.keepLineBreaks)
+	})
+	animationsCheck := widget.NewCheck("Pencere açılıp kapanırken soluklaştır", func(checked bool) {
+		a.settings.SetBool("window_animations", checked)
+	})
+	animationsCheck.Checked = a.animationsEnabled()
+	dockCheck := widget.NewCheck("Ekranın sağ kenarına kenar çubuğu olarak yerleştir", func(checked bool) {
+		a.settings.SetBool("dock_sidebar", checked)
+	})
+	dockCheck.Checked = a.settings.BoolWithFallback("dock_sidebar", false)
+	bind("dock_sidebar", func() {
+		dockCheck.SetChecked(a.settings.BoolWithFallback("dock_sidebar", false))
+	})
+	if !a.appBar.Supported() {
+		dockCheck.Hide()
+	}
+
+	newlineSelect := widget.NewSelect(newlineModeLabels(), func(selected string) {
+		for _, opt := range newlineModeOptions {
+			if opt.label == selected {
+				a.settings.SetString("newline_mode", string(opt.mode))
+			}
+		}
+	})
+	for _, opt := range newlineModeOptions {
+		if opt.mode == a.manager.GetNewlineMode() {
+			newlineSelect.SetSelected(opt.label)
+		}
 	}
 
 	// Max items limit
@@ -213,15 +665,202 @@ func (a *App) showSettingsDialog() {
 		limitValue.SetText(fmt.Sprintf("%d öğe", int(v)))
 	}
 	limitSlider.OnChangeEnded = func(v float64) {
-		newLimit := int(v)
-		a.manager.SetMaxItems(newLimit)
-		a.fyneApp.Preferences().SetInt("max_items", newLimit)
-		a.updateStatus()
+		a.settings.SetInt("max_items", int(v))
+	}
+	bind("max_items", func() {
+		limitSlider.SetValue(float64(a.manager.GetMaxItems()))
+	})
+
+	graceSelect := widget.NewSelect(graceLabels(), func(selected string) {
+		for _, opt := range graceOptions {
+			if opt.label == selected {
+				a.settings.SetInt("grace_minutes", opt.minutes)
+			}
+		}
+	})
+	for _, opt := range graceOptions {
+		if opt.minutes == *a.config.GraceMinutes {
+			graceSelect.SetSelected(opt.label)
+		}
+	}
+
+	limitPolicySelect := widget.NewSelect(limitPolicyLabels(), func(selected string) {
+		for _, opt := range limitPolicyOptions {
+			if opt.label == selected {
+				a.settings.SetString("limit_policy", string(opt.policy))
+			}
+		}
+	})
+	syncLimitPolicy := func() {
+		for _, opt := range limitPolicyOptions {
+			if opt.policy == a.manager.GetLimitPolicy() {
+				limitPolicySelect.SetSelected(opt.label)
+			}
+		}
 	}
+	syncLimitPolicy()
+	bind("limit_policy", syncLimitPolicy)
+
+	totalSizeSelect := widget.NewSelect(totalSizeLabels(), func(selected string) {
+		for _, opt := range totalSizeOptions {
+			if opt.label == selected {
+				a.settings.SetInt("max_total_mb", opt.mb)
+			}
+		}
+	})
+	syncTotalSize := func() {
+		for _, opt := range totalSizeOptions {
+			if int64(opt.mb)*1024*1024 == a.manager.GetMaxTotalSize() {
+				totalSizeSelect.SetSelected(opt.label)
+			}
+		}
+	}
+	syncTotalSize()
+	bind("max_total_mb", syncTotalSize)
+
+	dedupSelect := widget.NewSelect(dedupModeLabels(), func(selected string) {
+		for _, opt := range dedupModeOptions {
+			if opt.label == selected {
+				a.settings.SetString("dedup_mode", string(opt.mode))
+			}
+		}
+	})
+	syncDedup := func() {
+		for _, opt := range dedupModeOptions {
+			if opt.mode == a.manager.GetDedupMode() {
+				dedupSelect.SetSelected(opt.label)
+			}
+		}
+	}
+	syncDedup()
+	bind("dedup_mode", syncDedup)
+
+	pinLimitSelect := widget.NewSelect(pinLimitLabels(), func(selected string) {
+		for _, opt := range pinLimitOptions {
+			if opt.label == selected {
+				a.settings.SetInt("pin_limit", opt.limit)
+			}
+		}
+	})
+	syncPinLimit := func() {
+		for _, opt := range pinLimitOptions {
+			if opt.limit == a.manager.GetPinLimit() {
+				pinLimitSelect.SetSelected(opt.label)
+			}
+		}
+	}
+	syncPinLimit()
+	bind("pin_limit", syncPinLimit)
+
+	doubleCopySelect := widget.NewSelect(doubleCopyLabels(), func(selected string) {
+		for _, opt := range doubleCopyOptions {
+			if opt.label == selected {
+				a.settings.SetInt("double_copy_pin_ms", opt.ms)
+			}
+		}
+	})
+	syncDoubleCopy := func() {
+		for _, opt := range doubleCopyOptions {
+			if opt.ms == *a.config.DoubleCopyPinMs {
+				doubleCopySelect.SetSelected(opt.label)
+			}
+		}
+	}
+	syncDoubleCopy()
+	bind("double_copy_pin_ms", syncDoubleCopy)
+
+	// Storage
+	storageLabel := widget.NewLabelWithStyle("Depolama", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	deltaCheck := widget.NewCheck("Benzer ekran görüntülerini fark olarak sakla", func(checked bool) {
+		a.settings.SetBool("delta_images", checked)
+	})
+	deltaCheck.Checked = a.manager.GetDeltaImages()
+
+	prefs := a.settings
+
+	// Archive
+	archiveCheck := widget.NewCheck("Eski öğeleri silmek yerine arşivle", func(checked bool) {
+		prefs.SetBool("archive_enabled", checked)
+	})
+	archiveCheck.Checked = a.manager.GetArchiveEnabled()
+
+	capLabels := make([]string, 0, len(archiveCapOptions))
+	for _, opt := range archiveCapOptions {
+		capLabels = append(capLabels, opt.label)
+	}
+	archiveSizeLabel := widget.NewLabel(fmt.Sprintf("Arşiv boyutu: %s", formatSize(int(a.manager.GetArchiveSize()))))
+	archiveCapSelect := widget.NewSelect(capLabels, func(selected string) {
+		for _, opt := range archiveCapOptions {
+			if opt.label == selected {
+				prefs.SetInt("archive_max_mb", opt.mb)
+				archiveSizeLabel.SetText(fmt.Sprintf("Arşiv boyutu: %s", formatSize(int(a.manager.GetArchiveSize()))))
+			}
+		}
+	})
+	currentCap := *a.config.ArchiveMaxMB
+	for _, opt := range archiveCapOptions {
+		if opt.mb == currentCap {
+			archiveCapSelect.SetSelected(opt.label)
+		}
+	}
+
+	// URL cleaning
+	paramsEntry := widget.NewMultiLineEntry()
+	paramsEntry.SetText(strings.Join(a.config.TrackingParams, ", "))
+	paramsEntry.SetMinRowsVisible(2)
+	paramsEntry.Wrapping = fyne.TextWrapWord
+	encodedCheck := widget.NewCheck("Base64 olarak kopyalanan görselleri geçmişe kaydet", func(checked bool) {
+		prefs.SetBool("capture_encoded_copies", checked)
+	})
+	encodedCheck.Checked = prefs.BoolWithFallback("capture_encoded_copies", false)
+
+	stripCheck := widget.NewCheck("URL'lerden izleme parametrelerini temizle", func(checked bool) {
+		prefs.SetBool("strip_tracking", checked)
+	})
+	stripCheck.Checked = *a.config.StripTracking
+	paramsEntry.OnChanged = func(text string) {
+		prefs.SetString("tracking_params", text)
+	}
+
+	restorePointsBtn := widget.NewButtonWithIcon("Geri yükleme noktaları", theme.HistoryIcon(), func() {
+		a.showRestorePoints()
+	})
+
+	// Temp files (anything older than a minute, so in-flight writes are left alone)
+	tempLabel := widget.NewLabel("Geçici dosyalar hesaplanıyor...")
+	tempBtn := widget.NewButtonWithIcon("Geçici dosyaları şimdi temizle", theme.DeleteIcon(), nil)
+	tempBtn.OnTapped = func() {
+		tempBtn.Disable()
+		go func() {
+			report, err := storage.CleanTempFiles(storage.JanitorOptions{MaxAge: manualTempMinAge})
+			fyne.Do(func() {
+				tempBtn.Enable()
+				if err != nil {
+					dialog.ShowError(err, a.window)
+					return
+				}
+				tempLabel.SetText("Geçici dosya yok")
+				a.showToast(fmt.Sprintf("%d geçici dosya temizlendi (%s)", len(report.Files), formatSize(int(report.Bytes))))
+			})
+		}()
+	}
+	go func() {
+		// Dry run: only report what the button would remove
+		report, err := storage.CleanTempFiles(storage.JanitorOptions{MaxAge: manualTempMinAge, DryRun: true})
+		text := "Geçici dosya yok"
+		if err != nil {
+			text = "Geçici dosyalar okunamadı"
+		} else if len(report.Files) > 0 {
+			text = fmt.Sprintf("Geçici dosyalar: %d (%s)", len(report.Files), formatSize(int(report.Bytes)))
+		}
+		fyne.Do(func() {
+			tempLabel.SetText(text)
+		})
+	}()
 
 	// Autostart
 	autostartLabel := widget.NewLabelWithStyle("Başlangıç", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
-	autostartCheck := widget.NewCheck("Windows ile başlat", func(checked bool) {
+	autostartCheck := widget.NewCheck("Oturum açılınca başlat", func(checked bool) {
 		if checked {
 			if err := a.autostart.Enable(); err != nil {
 				dialog.ShowError(err, a.window)
@@ -233,6 +872,137 @@ func (a *App) showSettingsDialog() {
 		}
 	})
 	autostartCheck.Checked = isEnabled
+	if !a.autostart.Supported() {
+		autostartCheck.Hide()
+	}
+
+	// Explorer entries
+	shellCheck := widget.NewCheck("Gezgin'de \"Pano'ya ekle\" menüsünü göster", nil)
+	if a.shellMenu != nil {
+		shellEnabled, err := a.shellMenu.IsEnabled()
+		if err != nil {
+			log.Printf("Warning: Failed to check shell integration: %v", err)
+		}
+		shellCheck.Checked = shellEnabled
+		shellCheck.OnChanged = func(checked bool) {
+			var err error
+			if checked {
+				err = a.shellMenu.Enable()
+			} else {
+				err = a.shellMenu.Disable()
+			}
+			if err != nil {
+				dialog.ShowError(err, a.window)
+			}
+		}
+	} else {
+		shellCheck.Hide()
+	}
+	if !autostartCheck.Visible() && !shellCheck.Visible() {
+		autostartLabel.Hide()
+	}
+
+	// Hotkeys
+	hotkeyLabel := widget.NewLabelWithStyle("Kısayollar", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	captureKeySelect := widget.NewSelect(captureKeyOptions(), func(key string) {
+		prefs.SetString("capture_key", key)
+	})
+	captureKeySelect.SetSelected(prefs.StringWithFallback("capture_key", system.DefaultCaptureKey))
+
+	// Game mode
+	gameModeCheck := widget.NewCheck("Tam ekran oyunlarda oyun modu (yavaş yoklama, bildirim yok)", func(checked bool) {
+		prefs.SetBool("game_mode", checked)
+		if !checked {
+			a.SetGameMode(false)
+		}
+	})
+	gameModeCheck.Checked = prefs.BoolWithFallback("game_mode", true)
+	gameHookCheck := widget.NewCheck("Oyun modunda kısayol tuşlarını kapat", func(checked bool) {
+		prefs.SetBool("game_mode_disable_hotkeys", checked)
+	})
+	gameHookCheck.Checked = prefs.BoolWithFallback("game_mode_disable_hotkeys", false)
+	if !system.PlatformFeatures().GameMode {
+		gameModeCheck.Hide()
+		gameHookCheck.Hide()
+	}
+
+	// Weekly digest
+	digestCheck := widget.NewCheck("Haftalık özet bildirimi", func(checked bool) {
+		prefs.SetBool("weekly_digest", checked)
+		if checked {
+			// Count from now rather than sending a digest right away
+			prefs.SetInt("digest_last_run", int(time.Now().Unix()))
+		}
+	})
+	digestCheck.Checked = prefs.BoolWithFallback("weekly_digest", false)
+	digestDaySelect := widget.NewSelect(digestWeekdayLabels(), func(selected string) {
+		for _, opt := range digestWeekdayOptions {
+			if opt.label == selected {
+				prefs.SetInt("digest_weekday", int(opt.weekday))
+			}
+		}
+	})
+	savedWeekday := time.Weekday(prefs.IntWithFallback("digest_weekday", int(defaultDigestWeekday)))
+	for _, opt := range digestWeekdayOptions {
+		if opt.weekday == savedWeekday {
+			digestDaySelect.SetSelected(opt.label)
+		}
+	}
+	digestHourSelect := widget.NewSelect(digestHourLabels(), func(selected string) {
+		prefs.SetInt("digest_hour", digestHourFromLabel(selected))
+	})
+	digestHourSelect.SetSelectedIndex(prefs.IntWithFallback("digest_hour", defaultDigestHour))
+
+	// Diagnostics
+	diagLabel := widget.NewLabelWithStyle("Tanılama", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	integrityStartupCheck := widget.NewCheck("Başlangıçta bütünlük denetimi yap", func(checked bool) {
+		prefs.SetBool("integrity_on_startup", checked)
+	})
+	integrityStartupCheck.Checked = prefs.BoolWithFallback("integrity_on_startup", true)
+	backendText := "Ayar deposu: Fyne tercihleri"
+	if a.settings.Backend() == settings.BackendFile {
+		backendText = "Ayar deposu: settings.json (Fyne tercihleri kullanılamıyor)"
+	}
+	backendLabel := widget.NewLabel(backendText)
+	backendLabel.Wrapping = fyne.TextWrapWord
+	integrityBtn := widget.NewButtonWithIcon("Bütünlüğü denetle", theme.SearchIcon(), func() {
+		a.runIntegrityCheckNow()
+	})
+	auditBtn := widget.NewButtonWithIcon("İşlem geçmişi", theme.HistoryIcon(), func() {
+		a.showAuditLog()
+	})
+	rekeyBtn := widget.NewButtonWithIcon("Anahtarı yenile", theme.ViewRefreshIcon(), func() {
+		a.confirmRekey()
+	})
+	nonceBtn := widget.NewButtonWithIcon("Nonce denetimi", theme.SearchIcon(), func() {
+		a.showNonceReport()
+	})
+	recoveryBtn := widget.NewButtonWithIcon("Kurtarma anahtarı", theme.LoginIcon(), func() {
+		a.showRecoveryKey()
+	})
+	passwordBtn := widget.NewButtonWithIcon("Ana parola", theme.AccountIcon(), func() {
+		a.showMasterPassword()
+	})
+	portableBtn := widget.NewButtonWithIcon("Parolayla dışa aktar (başka bilgisayar için)", theme.DownloadIcon(), func() {
+		a.showPortableExport()
+	})
+	if !a.manager.Permissions().CanExport() {
+		portableBtn.SetText("Dışa aktarma yönetici tarafından kapatıldı")
+		portableBtn.Disable()
+	}
+	importBtn := widget.NewButtonWithIcon("İçe aktar", theme.UploadIcon(), func() {
+		a.showPortableImport()
+	})
+	exportV1Btn := widget.NewButtonWithIcon("Eski sürüm için dışa aktar", theme.DownloadIcon(), func() {
+		a.exportForOldVersion()
+	})
+	if !a.manager.Permissions().CanExport() {
+		exportV1Btn.SetText("Dışa aktarma yönetici tarafından kapatıldı")
+		exportV1Btn.Disable()
+	}
+	reportBtn := widget.NewButtonWithIcon("Hata raporu oluştur", theme.DocumentSaveIcon(), func() {
+		a.createBugReport()
+	})
 
 	// Info
 	infoLabel := widget.NewLabelWithStyle("Hakkında", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
@@ -241,61 +1011,481 @@ func (a *App) showSettingsDialog() {
 	dialogContent := container.NewVBox(
 		themeLabel,
 		themeSelect,
+		animationsCheck,
+		dockCheck,
+		widget.NewSeparator(),
+		previewLabel,
+		lineBreaksCheck,
+		container.NewBorder(nil, nil, widget.NewLabel("Kopyalarken satır sonları"), nil, newlineSelect),
+		widget.NewSeparator(),
+		a.buildFreshnessSettings(bind),
+		a.buildSmartActionSettings(bind),
 		widget.NewSeparator(),
 		limitLabel,
 		container.NewBorder(nil, nil, nil, limitValue, limitSlider),
+		container.NewBorder(nil, nil, widget.NewLabel("Limit dolunca"), nil, limitPolicySelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Toplam boyut"), nil, totalSizeSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Yeni öğeleri koru"), nil, graceSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Tekrarları birleştir"), nil, dedupSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Sabitlenebilecek öğe"), nil, pinLimitSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("İki kez kopyalayınca sabitle"), nil, doubleCopySelect),
+		a.buildPollSettings(bind),
+		a.buildRetentionSettings(bind),
+		widget.NewSeparator(),
+		storageLabel,
+		deltaCheck,
+		archiveCheck,
+		container.NewBorder(nil, nil, nil, archiveCapSelect, archiveSizeLabel),
+		stripCheck,
+		paramsEntry,
+		encodedCheck,
+		a.buildRedactionSettings(bind),
+		a.buildExclusionSettings(bind),
+		restorePointsBtn,
+		portableBtn,
+		importBtn,
+		container.NewBorder(nil, nil, nil, tempBtn, tempLabel),
 		widget.NewSeparator(),
 		autostartLabel,
 		autostartCheck,
+		shellCheck,
+		widget.NewSeparator(),
+		hotkeyLabel,
+		container.NewBorder(nil, nil, widget.NewLabel("Şimdi yakala: Ctrl+Shift+"), nil, captureKeySelect),
+		gameModeCheck,
+		gameHookCheck,
+		a.buildSelectionSettings(bind),
+		digestCheck,
+		container.NewGridWithColumns(2, digestDaySelect, digestHourSelect),
+		widget.NewSeparator(),
+		diagLabel,
+		integrityStartupCheck,
+		integrityBtn,
+		auditBtn,
+		container.NewGridWithColumns(2, rekeyBtn, nonceBtn),
+		container.NewGridWithColumns(2, recoveryBtn, passwordBtn),
+		exportV1Btn,
+		reportBtn,
+		backendLabel,
 		widget.NewSeparator(),
 		infoLabel,
 		infoText,
 	)
 
-	dialog.ShowCustom("Ayarlar", "Kapat", dialogContent, a.window)
+	settingsDialog := dialog.NewCustom("Ayarlar", "Kapat", dialogContent, a.window)
+	settingsDialog.SetOnClosed(func() {
+		for _, fn := range unsubscribe {
+			fn()
+		}
+	})
+	settingsDialog.Show()
+}
+
+// manualTempMinAge keeps the settings cleanup away from files still being written
+const manualTempMinAge = time.Minute
+
+// totalSizeOptions are the choices for the cap on the summed size of the history
+var totalSizeOptions = []struct {
+	label string
+	mb    int
+}{
+	{"Sınırsız", 0},
+	{"50 MB", 50},
+	{"100 MB", 100},
+	{"250 MB", 250},
+	{"500 MB", 500},
+}
+
+// totalSizeLabels returns the labels of totalSizeOptions
+func totalSizeLabels() []string {
+	labels := make([]string, 0, len(totalSizeOptions))
+	for _, opt := range totalSizeOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// sizeUsage describes how much of the size cap the history uses, for the status bar
+func sizeUsage(counts storage.Counts) string {
+	if counts.SizeLimit <= 0 {
+		return formatSize(int(counts.Bytes))
+	}
+	return fmt.Sprintf("%s/%s", formatSize(int(counts.Bytes)), formatSize(int(counts.SizeLimit)))
+}
+
+// limitPolicyOptions are the choices for what a capture does when the history is full
+var limitPolicyOptions = []struct {
+	label  string
+	policy storage.LimitPolicy
+}{
+	{"En eski öğeyi sil", storage.LimitEvictOldest},
+	{"Yeni kopyalamayı kaydetme", storage.LimitReject},
+}
+
+// limitPolicyLabels returns the labels of limitPolicyOptions
+func limitPolicyLabels() []string {
+	labels := make([]string, 0, len(limitPolicyOptions))
+	for _, opt := range limitPolicyOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// graceOptions are the choices for how long new items are protected from the limit
+var graceOptions = []struct {
+	label   string
+	minutes int
+}{
+	{"Kapalı", 0},
+	{"1 dakika", 1},
+	{"5 dakika", 5},
+	{"15 dakika", 15},
+	{"30 dakika", 30},
+}
+
+// graceLabels returns the labels of graceOptions
+func graceLabels() []string {
+	labels := make([]string, 0, len(graceOptions))
+	for _, opt := range graceOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// dedupModeOptions are the choices for which items a capture is merged with
+var dedupModeOptions = []struct {
+	label string
+	mode  storage.DedupMode
+}{
+	{"Tüm geçmişte", storage.DedupAll},
+	{"Son 24 saat içinde", storage.DedupRecent},
+	{"Kapalı", storage.DedupOff},
+}
+
+// dedupModeLabels returns the labels of dedupModeOptions
+func dedupModeLabels() []string {
+	labels := make([]string, 0, len(dedupModeOptions))
+	for _, opt := range dedupModeOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// pinLimitOptions are the choices for how many items may be pinned
+var pinLimitOptions = []struct {
+	label string
+	limit int
+}{
+	{"10", 10},
+	{"25", 25},
+	{"50", 50},
+	{"100", 100},
+	{"200", 200},
+}
+
+// pinLimitLabels returns the labels of pinLimitOptions
+func pinLimitLabels() []string {
+	labels := make([]string, 0, len(pinLimitOptions))
+	for _, opt := range pinLimitOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// doubleCopyOptions are the choices for how quickly a second copy pins an item
+var doubleCopyOptions = []struct {
+	label string
+	ms    int
+}{
+	{"Kapalı", 0},
+	{"1 saniye içinde", 1000},
+	{"2 saniye içinde", 2000},
+	{"3 saniye içinde", 3000},
+	{"5 saniye içinde", 5000},
+}
+
+// doubleCopyLabels returns the labels of doubleCopyOptions
+func doubleCopyLabels() []string {
+	labels := make([]string, 0, len(doubleCopyOptions))
+	for _, opt := range doubleCopyOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// newlineModeOptions are the line ending choices for copied text
+var newlineModeOptions = []struct {
+	label string
+	mode  clipboard.NewlineMode
+}{
+	{"Olduğu gibi", clipboard.NewlineAsIs},
+	{"LF", clipboard.NewlineLF},
+	{"CRLF", clipboard.NewlineCRLF},
+}
+
+// newlineModeLabels returns the labels of newlineModeOptions
+func newlineModeLabels() []string {
+	labels := make([]string, 0, len(newlineModeOptions))
+	for _, opt := range newlineModeOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// captureKeyOptions lists the letters usable for the capture hotkey (V, the selection and pause keys are taken)
+func captureKeyOptions() []string {
+	keys := make([]string, 0, 24)
+	for r := 'A'; r <= 'Z'; r++ {
+		if r != 'V' && string(r) != system.SelectionOffKey && string(r) != system.PauseKey {
+			keys = append(keys, string(r))
+		}
+	}
+	return keys
+}
+
+// parseParamList splits a comma or newline separated parameter list
+func parseParamList(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	params := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			params = append(params, f)
+		}
+	}
+	return params
 }
 
 func (a *App) showClearAllDialog() {
-	count := a.manager.GetItemCount()
+	count := a.manager.Counts().Total()
 	if count == 0 {
-		dialog.ShowInformation("Bilgi", "Silinecek öğe yok.", a.window)
+		a.showToast("Silinecek öğe yok")
 		return
 	}
 
 	dialog.ShowConfirm("Tümünü Temizle",
-		fmt.Sprintf("%d öğe silinecek. Devam edilsin mi?", count),
+		fmt.Sprintf("%d öğe silinecek. Silmeden önce bir geri yükleme noktası oluşturulur. Devam edilsin mi?", count),
 		func(ok bool) {
 			if ok {
+				removed := a.manager.GetAllItems()
 				if err := a.manager.ClearAll(); err != nil {
 					dialog.ShowError(err, a.window)
 				} else {
 					thumbCache.clear()
-					a.list.Refresh()
-					a.updateStatus()
+					a.afterItemsChanged()
+					a.toasts.ShowWithAction("Tüm öğeler silindi", "Geri Al", func() {
+						a.undoRemove(removed)
+					})
 				}
 			}
 		}, a.window)
 }
 
-func (a *App) Show() {
+// showDeleteSelectedDialog deletes the multi-selected items after one confirmation
+func (a *App) showDeleteSelectedDialog() {
+	ids := a.list.Selected()
+	if len(ids) == 0 {
+		return
+	}
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	pinned := 0
+	for _, item := range a.manager.GetAllItems() {
+		if selected[item.ID] && item.Pinned {
+			pinned++
+		}
+	}
+
+	message := fmt.Sprintf("Seçilen %d öğe silinecek. Devam edilsin mi?", len(ids))
+	if pinned > 0 {
+		message = fmt.Sprintf("Seçilen %d öğe silinecek; %d tanesi sabitlenmiş. Devam edilsin mi?", len(ids), pinned)
+	}
+	dialog.ShowConfirm("Seçilenleri Sil", message, func(ok bool) {
+		if !ok {
+			return
+		}
+		count, err := a.manager.DeleteItems(ids)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		a.list.ClearSelection()
+		a.afterItemsChanged()
+		a.toasts.ShowWithAction(fmt.Sprintf("%d öğe silindi", count), "Geri Al", func() {
+			a.undoDelete(ids)
+		})
+	}, a.window)
+}
+
+// afterItemsChanged refreshes everything that shows the item list
+func (a *App) afterItemsChanged() {
+	a.list.Refresh()
+	a.updateStatus()
+	a.refreshTray()
+}
+
+// undoDelete takes deleted items back out of the trash; items already purged or
+// restored are skipped, and with LimitReject those the full history has no room for
+// stay in the trash
+func (a *App) undoDelete(ids []string) {
+	restored, full := 0, 0
+	for _, id := range ids {
+		err := a.manager.RestoreItem(id)
+		var reject *storage.RejectError
+		switch {
+		case err == nil:
+			restored++
+		case errors.As(err, &reject) && reject.Reason == storage.RejectLimitFull:
+			full++
+		}
+	}
+	if restored > 0 {
+		a.afterItemsChanged()
+	}
+	switch {
+	case full > 0:
+		a.toasts.ShowWithAction(fmt.Sprintf("Limit dolu, %d öğe çöpte kaldı", full), "Ayarlar", a.showSettingsDialog)
+	case restored == 0:
+		a.showToast("Geri alınamadı")
+	default:
+		a.showToast("Geri alındı")
+	}
+}
+
+// undoRemove puts permanently removed items back
+func (a *App) undoRemove(items []storage.ClipboardItem) {
+	if err := a.manager.RestoreItems(items); err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	a.afterItemsChanged()
+	a.showToast("Geri alındı")
+}
+
+func (a *App) Show(source ShowSource) {
 	a.isVisible = true
 	a.list.Refresh()
 	a.updateStatus()
 	a.window.Show()
 	a.window.RequestFocus()
-	BringWindowToFront("Pano")
+	bringWindowToFront(a.mainWindowHandle())
+	if a.animationsEnabled() {
+		a.fader.FadeIn()
+	} else {
+		a.fader.Cancel()
+	}
+
+	// Focus only sticks once the window is actually mapped
+	time.AfterFunc(focusDelay, func() {
+		fyne.Do(func() {
+			a.focusForSource(source)
+		})
+	})
+	a.offerStaleCleanup()
+	a.offerDrafts()
+}
+
+// focusTarget is where keyboard focus lands when the window is shown
+type focusTarget int
+
+const (
+	focusList   focusTarget = iota // Nothing focused, the list is in front
+	focusSearch                    // The search entry, cleared
+)
+
+// focusTargetFor maps how the window was opened to where focus should land
+func focusTargetFor(source ShowSource) focusTarget {
+	if source == ShowSourceHotkey {
+		return focusSearch
+	}
+	return focusList
+}
+
+// focusForSource moves keyboard focus to the search entry for hotkey opens
+// and to the list for tray opens
+func (a *App) focusForSource(source ShowSource) {
+	canvas := a.window.Canvas()
+	if focusTargetFor(source) == focusSearch {
+		a.searchEntry.SetText("")
+		canvas.Focus(a.searchEntry)
+		return
+	}
+	canvas.Unfocus()
+}
+
+// copyTopResult copies the first visible item and hides the window (search Enter)
+func (a *App) copyTopResult() {
+	if len(a.list.items) == 0 {
+		return
+	}
+	a.copyItem(a.list.items[0].ID, a.Hide)
 }
 
 func (a *App) Hide() {
+	// The docked sidebar stays on screen; undocking is the way to hide it
+	if a.docked {
+		return
+	}
 	a.isVisible = false
-	a.window.Hide()
+	if !a.animationsEnabled() {
+		a.fader.Cancel()
+		a.window.Hide()
+		return
+	}
+	a.fader.FadeOut(func() {
+		// A Show during the fade has already cancelled it; this guards a late tick
+		if !a.isVisible {
+			a.window.Hide()
+		}
+	})
 }
 
-func (a *App) Toggle() {
-	if a.isVisible {
+// animationsEnabled reports whether the window fades when shown and hidden
+func (a *App) animationsEnabled() bool {
+	return a.settings.BoolWithFallback("window_animations", true)
+}
+
+func (a *App) Toggle(source ShowSource) {
+	if a.isVisible && !a.docked {
 		a.Hide()
 	} else {
-		a.Show()
+		a.Show(source)
+	}
+}
+
+// BindHotkeys connects the capture hotkey to the app and applies the saved key
+func (a *App) BindHotkeys(h *system.HotkeyManager) {
+	a.hotkeys = h
+	h.SetCaptureCallback(a.CaptureNow)
+	h.SetPasteCallback(a.onPaste)
+	h.SetNextCallback(a.advancePasteStack)
+	h.SetPauseCallback(a.togglePauseFromHotkey)
+	// Tells the monitor's loop detection that the user is at work, see clipboard/loop.go
+	a.monitor.SetLastInput(h.LastInput)
+	if err := h.SetCaptureKey(a.settings.StringWithFallback("capture_key", system.DefaultCaptureKey)); err != nil {
+		log.Printf("Warning: Invalid capture hotkey: %v", err)
+	}
+}
+
+// CaptureNow stores the current clipboard right away, bypassing pause and exclusions
+func (a *App) CaptureNow() {
+	itemType, err := a.monitor.CaptureNow(true)
+	switch {
+	case err == nil:
+		if itemType == "image" {
+			a.sendNotification("Yakalandı", "Panodaki görsel geçmişe eklendi.")
+		} else if itemType == "files" {
+			a.sendNotification("Yakalandı", "Panodaki dosyalar geçmişe eklendi.")
+		} else {
+			a.sendNotification("Yakalandı", "Panodaki metin geçmişe eklendi.")
+		}
+	case errors.Is(err, clipboard.ErrClipboardEmpty):
+		a.sendNotification("Yakalanamadı", "Pano boş.")
+	default:
+		a.handleCaptureError(err)
 	}
 }
 
@@ -303,15 +1493,147 @@ func (a *App) StartMonitoring() error {
 	return a.monitor.Start()
 }
 
+// StopDPIWatch removes the DPI change hook from the main window
+func (a *App) StopDPIWatch() {
+	if a.stopDPIWatch != nil {
+		a.stopDPIWatch()
+		a.stopDPIWatch = nil
+	}
+}
+
 func (a *App) StopMonitoring() {
 	a.monitor.Stop()
 }
 
+// Shutdown stops background work, then closes the IPC server, the monitor, the hotkeys and
+// the database in that order, so nothing is captured into a closed database; closing the
+// database writes changes still waiting for the timed save
+// Each part waits for its goroutines; errors are collected rather than stopping the rest
+func (a *App) Shutdown() error {
+	// Maximized windows get the sidebar's column back even if the rest fails
+	a.appBar.Undock()
+	a.StopDPIWatch()
+	a.StopIntegrityCheck()
+	a.StopDigest()
+	a.StopRetention()
+
+	var errs []error
+	if a.ipc != nil {
+		errs = append(errs, a.ipc.Close())
+	}
+	errs = append(errs, a.monitor.Close())
+	if a.hotkeys != nil {
+		errs = append(errs, a.hotkeys.Close())
+	}
+	errs = append(errs, a.manager.Close())
+	return errors.Join(errs...)
+}
+
+// StartIntegrityCheck runs a throttled background integrity pass if enabled in preferences
+func (a *App) StartIntegrityCheck() {
+	if !a.settings.BoolWithFallback("integrity_on_startup", true) {
+		return
+	}
+
+	ctx := a.beginIntegrityPass()
+	go func() {
+		report, err := a.manager.VerifyIntegrity(ctx, storage.IntegrityOptions{
+			Throttle: storage.DefaultIntegrityThrottle,
+			MaxSize:  storage.DefaultIntegrityMaxSize,
+		})
+		if err != nil {
+			return // Cancelled
+		}
+		if len(report.Issues) > 0 {
+			fyne.Do(func() {
+				a.list.Refresh()
+			})
+			a.sendNotification("Pano Uyarısı", fmt.Sprintf("%d öğe bütünlük denetiminden geçemedi.", len(report.Issues)))
+		}
+	}()
+}
+
+// StopIntegrityCheck cancels a running integrity pass
+func (a *App) StopIntegrityCheck() {
+	a.integrityMu.Lock()
+	defer a.integrityMu.Unlock()
+	if a.cancelIntegrity != nil {
+		a.cancelIntegrity()
+		a.cancelIntegrity = nil
+	}
+}
+
+// beginIntegrityPass cancels any running pass and returns a context for a new one
+func (a *App) beginIntegrityPass() context.Context {
+	a.integrityMu.Lock()
+	defer a.integrityMu.Unlock()
+	if a.cancelIntegrity != nil {
+		a.cancelIntegrity()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancelIntegrity = cancel
+	return ctx
+}
+
+// runIntegrityCheckNow checks every item, including large ones, and shows a report
+func (a *App) runIntegrityCheckNow() {
+	ctx := a.beginIntegrityPass()
+	progress := dialog.NewCustomWithoutButtons("Bütünlük Denetimi", widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+
+	go func() {
+		report, err := a.manager.VerifyIntegrity(ctx, storage.IntegrityOptions{IncludeLarge: true})
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				return
+			}
+			a.list.Refresh()
+
+			if len(report.Issues) == 0 {
+				a.showToast(fmt.Sprintf("%d öğe denetlendi, sorun bulunmadı", report.Checked))
+				return
+			}
+
+			// Problems need acknowledging, so they stay in a dialog
+			msg := fmt.Sprintf("%d öğe denetlendi, %d öğede sorun bulundu:\n", report.Checked, len(report.Issues))
+			for _, issue := range report.Issues {
+				msg += fmt.Sprintf("\n%s: %s", issue.ID, issue.Reason)
+			}
+			dialog.ShowInformation("Bütünlük Denetimi", msg, a.window)
+		})
+	}()
+}
+
+// SetSessionLocked pauses capture while the workstation is locked
+func (a *App) SetSessionLocked(locked bool) {
+	a.monitor.SetLocked(locked)
+}
+
+// Run shows the window and runs the app; the window is fitted to its monitor once the
+// native window exists
 func (a *App) Run() {
+	a.fyneApp.Lifecycle().SetOnStarted(a.fitToMonitor)
 	a.isVisible = true
 	a.window.ShowAndRun()
 }
 
+// ShowStarted shows the window of an app built after Run was called, as behind the unlock
+// window, and fits it to its monitor; the started hook has fired by then (UI thread only)
+func (a *App) ShowStarted() {
+	a.Show(ShowSourceTray)
+	a.fitToMonitor()
+}
+
+// fitToMonitor sizes the window, thumbnails and icons for the monitor DPI, follows DPI
+// changes and docks the window if asked to (UI thread only)
+func (a *App) fitToMonitor() {
+	a.window.Resize(scaledWindowSize(defaultWindowSize, dpiScale(windowDPI(a.mainWindowHandle())), a.window.Canvas().Scale()))
+	a.applyDPI()
+	a.watchDPIChanges()
+	a.applyDock()
+}
+
 func (a *App) GetWindow() fyne.Window {
 	return a.window
 }
@@ -0,0 +1,23 @@
+package ui
+
+import "testing"
+
+// The hotkey opens the window to type a search; the tray opens it to browse the list
+func TestFocusTargetFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		source ShowSource
+		want   focusTarget
+	}{
+		{"hotkey", ShowSourceHotkey, focusSearch},
+		{"tray", ShowSourceTray, focusList},
+		{"unknown source", ShowSource(99), focusList},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := focusTargetFor(tt.source); got != tt.want {
+				t.Errorf("focusTargetFor(%d) = %d, want %d", tt.source, got, tt.want)
+			}
+		})
+	}
+}
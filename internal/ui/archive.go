@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// archiveMaxResults limits how many archived items are listed at once
+const archiveMaxResults = 50
+
+// archiveCapOptions maps the settings labels to archive size caps in MB (0 = unlimited)
+var archiveCapOptions = []struct {
+	label string
+	mb    int
+}{
+	{"Sınırsız", 0},
+	{"50 MB", 50},
+	{"100 MB", 100},
+	{"500 MB", 500},
+}
+
+// showArchiveDialog opens the archive search window; the archive is only read on search
+func (a *App) showArchiveDialog() {
+	results := container.NewVBox()
+	statusLabel := widget.NewLabel("")
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Arşivde ara...")
+
+	var search func(query string)
+	search = func(query string) {
+		items, err := a.manager.SearchArchive(query)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		results.RemoveAll()
+		if len(items) == 0 {
+			statusLabel.SetText("Arşivde öğe bulunamadı")
+			return
+		}
+
+		shown := items
+		if len(shown) > archiveMaxResults {
+			shown = shown[:archiveMaxResults]
+		}
+		statusLabel.SetText(fmt.Sprintf("%d sonuç", len(items)))
+
+		for _, item := range shown {
+			results.Add(a.createArchiveRow(item, func() {
+				search(searchEntry.Text)
+			}))
+		}
+	}
+
+	searchEntry.OnSubmitted = search
+	searchBtn := widget.NewButtonWithIcon("", theme.SearchIcon(), func() {
+		search(searchEntry.Text)
+	})
+
+	scroll := container.NewVScroll(results)
+	scroll.SetMinSize(fyne.NewSize(340, 320))
+
+	content := container.NewBorder(
+		container.NewBorder(nil, nil, nil, searchBtn, searchEntry),
+		statusLabel,
+		nil, nil,
+		scroll,
+	)
+
+	d := dialog.NewCustom("Arşiv", "Kapat", content, a.window)
+	d.Show()
+	search("")
+	a.window.Canvas().Focus(searchEntry)
+}
+
+// createArchiveRow builds a compact row for an archived item
+func (a *App) createArchiveRow(item storage.ClipboardItem, onRestored func()) fyne.CanvasObject {
+	preview := "[Görsel]"
+	if item.Type == "files" {
+		preview = "[Dosyalar]"
+	}
+	if item.Type == "text" {
+		if data, err := a.manager.GetArchivedItemContent(item.ID); err == nil {
+			preview = buildFlatPreview(string(data))
+			storage.Zero(data)
+		}
+	}
+
+	label := widget.NewLabel(preview)
+	label.Truncation = fyne.TextTruncateEllipsis
+	infoLabel := widget.NewLabelWithStyle(formatTimestamp(item.Timestamp), fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+
+	itemID := item.ID
+	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		if err := a.manager.CopyArchivedToClipboard(itemID); err != nil {
+			dialog.ShowError(err, a.window)
+		} else {
+			a.showToast("Panoya kopyalandı")
+		}
+	})
+	restoreBtn := widget.NewButtonWithIcon("", theme.ContentUndoIcon(), func() {
+		if err := a.manager.RestoreFromArchive(itemID); err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		a.list.Refresh()
+		a.updateStatus()
+		a.showToast("Geçmişe geri yüklendi")
+		onRestored()
+	})
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(infoLabel, copyBtn, restoreBtn), label)
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// auditMaxRows limits how many log entries are listed at once
+const auditMaxRows = 200
+
+// auditAllLabel is the filter choice that shows every operation
+const auditAllLabel = "Tüm işlemler"
+
+// auditOpLabels names the logged operations
+var auditOpLabels = map[storage.AuditOp]string{
+	storage.AuditPin:     "Sabitlendi",
+	storage.AuditUnpin:   "Sabitleme kaldırıldı",
+	storage.AuditDelete:  "Silindi",
+	storage.AuditClear:   "Geçmiş temizlendi",
+	storage.AuditRestore: "Geri yüklendi",
+	storage.AuditEdit:    "Düzenlendi",
+
+	storage.AuditExport:       "Dışa aktarıldı",
+	storage.AuditExportDenied: "Dışa aktarma engellendi",
+	storage.AuditRekey:        "Anahtar yenilendi",
+	storage.AuditMigrate:      "SQLite'a taşındı",
+	storage.AuditImport:       "İçe aktarıldı",
+	storage.AuditPrune:        "Saklama süresi doldu",
+	storage.AuditProtect:      "Korumaya alındı",
+	storage.AuditUnprotect:    "Koruma kaldırıldı",
+	storage.AuditUnlockDenied: "Yanlış parola",
+
+	storage.AuditPasswordSet:    "Ana parola belirlendi",
+	storage.AuditPasswordChange: "Ana parola değiştirildi",
+	storage.AuditPasswordRemove: "Ana parola kaldırıldı",
+}
+
+// auditSourceLabels names where an operation came from
+var auditSourceLabels = map[storage.AuditSource]string{
+	storage.AuditSourceUI:  "arayüz",
+	storage.AuditSourceCLI: "komut satırı",
+}
+
+// auditFilterLabels returns the filter choices: everything, then each operation
+func auditFilterLabels() []string {
+	labels := []string{auditAllLabel}
+	for _, op := range storage.AuditOps {
+		labels = append(labels, auditOpLabels[op])
+	}
+	return labels
+}
+
+// showAuditLog lists pins, deletes, clears and restores, newest first, filtered by operation
+func (a *App) showAuditLog() {
+	entries, err := a.manager.AuditEntries()
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+
+	rows := container.NewVBox()
+	statusLabel := widget.NewLabel("")
+
+	show := func(selected string) {
+		rows.RemoveAll()
+		matched := 0
+		for _, entry := range entries {
+			if selected != auditAllLabel && auditOpLabels[entry.Op] != selected {
+				continue
+			}
+			matched++
+			if matched <= auditMaxRows {
+				rows.Add(createAuditRow(entry))
+			}
+		}
+		if matched == 0 {
+			statusLabel.SetText("Kayıtlı işlem yok")
+		} else {
+			statusLabel.SetText(fmt.Sprintf("%d işlem", matched))
+		}
+	}
+
+	filter := widget.NewSelect(auditFilterLabels(), show)
+	filter.SetSelected(auditAllLabel)
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(380, 320))
+
+	content := container.NewBorder(filter, statusLabel, nil, nil, scroll)
+	dialog.ShowCustom("İşlem Geçmişi", "Kapat", content, a.window)
+}
+
+// createAuditRow builds a row for one logged operation
+func createAuditRow(entry storage.AuditEntry) fyne.CanvasObject {
+	subject := entry.Title
+	switch {
+	case entry.Count > 1:
+		subject = fmt.Sprintf("%d öğe", entry.Count)
+	case subject == "" && entry.ItemID != "":
+		subject = "Öğe " + entry.ItemID
+	}
+
+	opLabel := widget.NewLabelWithStyle(auditOpLabels[entry.Op], fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	subjectLabel := widget.NewLabel(subject)
+	subjectLabel.Truncation = fyne.TextTruncateEllipsis
+
+	source := auditSourceLabels[entry.Source]
+	if source == "" {
+		source = string(entry.Source)
+	}
+	infoLabel := widget.NewLabelWithStyle(
+		fmt.Sprintf("%s - %s", entry.Time.Format("02.01.2006 15:04:05"), source),
+		fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+
+	return container.NewVBox(container.NewBorder(nil, nil, opLabel, nil, subjectLabel), infoLabel)
+}
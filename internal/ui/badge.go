@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Badge kinds, each with its own color (see GetBadgeColors)
+const (
+	badgePinned    = "pinned"
+	badgeText      = "text"
+	badgeImage     = "image"
+	badgeFile      = "file" // Files and any other item type
+	badgeTag       = "tag"  // User-defined tags
+	badgeStale     = "stale"
+	badgeProtected = "protected"
+)
+
+// Padding inside a badge, around its text
+const (
+	badgePadX = 6
+	badgePadY = 2
+)
+
+// Badge is a small rounded label, drawn with canvas primitives so it stays sharp at any scale
+// Colors follow the current theme and are picked again on every Refresh
+type Badge struct {
+	widget.BaseWidget
+	Text string
+	Kind string
+}
+
+// NewBadge creates a badge with the colors of kind
+func NewBadge(text, kind string) *Badge {
+	b := &Badge{Text: text, Kind: kind}
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+// newTypeBadge creates the badge for an item type
+func newTypeBadge(itemType string) *Badge {
+	switch itemType {
+	case "text":
+		return NewBadge("METİN", badgeText)
+	case "image":
+		return NewBadge("GÖRSEL", badgeImage)
+	case "files":
+		return NewBadge("DOSYA", badgeFile)
+	default:
+		return NewBadge("DİĞER", badgeFile)
+	}
+}
+
+// newTagBadges creates the chips for an item's tags
+func newTagBadges(tags []string) []fyne.CanvasObject {
+	badges := make([]fyne.CanvasObject, 0, len(tags))
+	for _, tag := range tags {
+		badges = append(badges, NewBadge(tag, badgeTag))
+	}
+	return badges
+}
+
+// newRTFBadge creates the badge shown on text items that carry formatting
+func newRTFBadge() *Badge {
+	return NewBadge("RTF", badgeText)
+}
+
+// newPinnedBadge creates the badge shown on pinned items
+func newPinnedBadge() *Badge {
+	return NewBadge("SABİT", badgePinned)
+}
+
+func (b *Badge) CreateRenderer() fyne.WidgetRenderer {
+	bg := canvas.NewRectangle(color.Transparent)
+	text := canvas.NewText(b.Text, color.White)
+	text.TextStyle = fyne.TextStyle{Bold: true}
+	text.TextSize = theme.CaptionTextSize()
+	r := &badgeRenderer{badge: b, bg: bg, text: text}
+	r.Refresh()
+	return r
+}
+
+type badgeRenderer struct {
+	badge *Badge
+	bg    *canvas.Rectangle
+	text  *canvas.Text
+	size  fyne.Size // Last layout size, to re-center after the text changes
+}
+
+// Layout centers a badge of its minimum size, so rows taller than the badge don't stretch it
+func (r *badgeRenderer) Layout(size fyne.Size) {
+	r.size = size
+	pill := r.MinSize()
+	pos := fyne.NewPos(0, (size.Height-pill.Height)/2)
+	r.bg.Move(pos)
+	r.bg.Resize(pill)
+	r.bg.CornerRadius = pill.Height / 2
+
+	textSize := r.text.MinSize()
+	r.text.Move(pos.AddXY((pill.Width-textSize.Width)/2, (pill.Height-textSize.Height)/2))
+	r.text.Resize(textSize)
+}
+
+func (r *badgeRenderer) MinSize() fyne.Size {
+	textSize := r.text.MinSize()
+	return fyne.NewSize(textSize.Width+2*badgePadX, textSize.Height+2*badgePadY)
+}
+
+func (r *badgeRenderer) Refresh() {
+	bg, fg := GetBadgeColors(r.badge.Kind)
+	r.bg.FillColor = bg
+	r.text.Text = r.badge.Text
+	r.text.Color = fg
+	r.text.TextSize = theme.CaptionTextSize()
+	r.Layout(r.size)
+	r.bg.Refresh()
+	r.text.Refresh()
+}
+
+func (r *badgeRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.bg, r.text}
+}
+
+func (r *badgeRenderer) Destroy() {}
+
+// contrastText returns white or dark text, whichever reads better on bg
+func contrastText(bg color.Color) color.Color {
+	dark := color.RGBA{R: 32, G: 32, B: 32, A: 255}
+	bgLum := luminance(bg)
+	if contrastRatio(luminance(color.White), bgLum) >= contrastRatio(luminance(dark), bgLum) {
+		return color.White
+	}
+	return dark
+}
+
+// contrastRatio is the WCAG contrast ratio of two relative luminances
+func contrastRatio(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return (a + 0.05) / (b + 0.05)
+}
+
+// luminance is the WCAG relative luminance of c
+func luminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	channel := func(v uint32) float64 {
+		s := float64(v) / 0xffff
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(r) + 0.7152*channel(g) + 0.0722*channel(b)
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+)
+
+func TestContrastText(t *testing.T) {
+	if got := contrastRatio(luminance(color.White), luminance(color.Black)); math.Abs(got-21) > 0.01 {
+		t.Errorf("white on black has contrast %.2f, want 21", got)
+	}
+	if got := contrastRatio(luminance(color.Black), luminance(color.White)); math.Abs(got-21) > 0.01 {
+		t.Errorf("contrast depends on the order: %.2f", got)
+	}
+	if contrastText(color.Black) != color.White {
+		t.Error("dark text on black")
+	}
+	if contrastText(color.White) == color.White {
+		t.Error("white text on white")
+	}
+}
+
+// Every badge kind has a color in both themes whose text stays readable; unknown kinds
+// look like files
+func TestBadgeColors(t *testing.T) {
+	saved := currentVariant
+	t.Cleanup(func() { currentVariant = saved })
+	kinds := []string{badgePinned, badgeText, badgeImage, badgeFile, badgeTag, badgeStale, badgeProtected}
+	for _, variant := range []fyne.ThemeVariant{theme.VariantLight, theme.VariantDark} {
+		currentVariant = variant
+		for _, kind := range kinds {
+			bg, fg := GetBadgeColors(kind)
+			if bg == nil {
+				t.Errorf("variant %d: no color for %s", variant, kind)
+				continue
+			}
+			if ratio := contrastRatio(luminance(fg), luminance(bg)); ratio < 3 {
+				t.Errorf("variant %d: %s text has contrast %.2f", variant, kind, ratio)
+			}
+		}
+		unknownBg, _ := GetBadgeColors("başka")
+		fileBg, _ := GetBadgeColors(badgeFile)
+		if unknownBg != fileBg {
+			t.Errorf("variant %d: an unknown kind isn't drawn as a file", variant)
+		}
+	}
+}
+
+func TestNewTypeBadge(t *testing.T) {
+	test.NewTempApp(t)
+	tests := []struct{ itemType, text, kind string }{
+		{"text", "METİN", badgeText},
+		{"image", "GÖRSEL", badgeImage},
+		{"files", "DOSYA", badgeFile},
+		{"video", "DİĞER", badgeFile},
+	}
+	for _, tt := range tests {
+		if b := newTypeBadge(tt.itemType); b.Text != tt.text || b.Kind != tt.kind {
+			t.Errorf("newTypeBadge(%q) is %q (%s), want %q (%s)", tt.itemType, b.Text, b.Kind, tt.text, tt.kind)
+		}
+	}
+}
+
+// A badge in a taller row keeps its own height and is centered, and follows text changes
+func TestBadgeLayout(t *testing.T) {
+	test.NewTempApp(t)
+	badge := NewBadge("SABİT", badgePinned)
+	r := test.TempWidgetRenderer(t, badge).(*badgeRenderer)
+	pill := r.MinSize()
+	if pill.Width <= r.text.MinSize().Width || pill.Height <= r.text.MinSize().Height {
+		t.Errorf("badge %v doesn't pad its text %v", pill, r.text.MinSize())
+	}
+
+	r.Layout(fyne.NewSize(200, pill.Height+20))
+	if r.bg.Size() != pill || r.bg.Position().Y != 10 {
+		t.Errorf("background at %v size %v in a row 20 taller, want centered at %v", r.bg.Position(), r.bg.Size(), pill)
+	}
+	if r.bg.CornerRadius != pill.Height/2 {
+		t.Errorf("corner radius %v, want a pill", r.bg.CornerRadius)
+	}
+
+	badge.Text = "ÇOK DAHA UZUN"
+	badge.Refresh()
+	if r.text.Text != badge.Text || r.MinSize().Width <= pill.Width {
+		t.Errorf("the badge didn't follow its text: %q, %v", r.text.Text, r.MinSize())
+	}
+}
@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/report"
+	"pano/internal/storage"
+	"pano/internal/system"
+)
+
+// reportDirName holds generated bug reports, under the Pano data directory
+const reportDirName = "reports"
+
+// createBugReport writes a bug report zip and opens its folder
+// The bundle never contains the database or item content, only counts and settings
+func (a *App) createBugReport() {
+	progress := dialog.NewCustomWithoutButtons("Hata Raporu", widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+
+	providers := a.reportProviders()
+	go func() {
+		path, err := writeBugReport(providers, time.Now())
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			a.showToast("Hata raporu oluşturuldu")
+			folder := &url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Dir(path))}
+			if err := a.fyneApp.OpenURL(folder); err != nil {
+				dialog.ShowInformation("Hata Raporu", path, a.window)
+			}
+		})
+	}()
+}
+
+// writeBugReport builds the bundle and saves it in the reports directory
+func writeBugReport(providers report.Providers, now time.Time) (string, error) {
+	dbPath, err := storage.GetDatabasePath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(filepath.Dir(dbPath), reportDirName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	files, err := report.BuildFiles(providers, now)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("pano-rapor-%s.zip", now.Format("20060102-150405")))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := report.WriteZip(f, files, now); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to save report: %w", err)
+	}
+	return path, nil
+}
+
+// reportProviders snapshots app state for the bug report (UI thread only)
+func (a *App) reportProviders() report.Providers {
+	prefs := a.reportPreferences()
+	scale := a.pixelScale()
+	canvasScale := a.window.Canvas().Scale()
+	paused, locked := a.monitor.IsPaused(), a.monitor.IsLocked()
+
+	return report.Providers{
+		LogFiles: func() []string {
+			dbPath, err := storage.GetDatabasePath()
+			if err != nil {
+				return nil
+			}
+			return system.LogFiles(filepath.Join(filepath.Dir(dbPath), system.LogDirName))
+		},
+		ReadFile: os.ReadFile,
+		Diagnostics: func() string {
+			return a.diagnosticsText(paused, locked)
+		},
+		Metrics: a.reportMetrics,
+		Preferences: func() map[string]any {
+			return prefs
+		},
+		SystemInfo: func() map[string]string {
+			info := platformInfo()
+			info["os"] = runtime.GOOS
+			info["arch"] = runtime.GOARCH
+			info["go_version"] = runtime.Version()
+			info["app_version"] = buildVersion()
+			info["dpi_scale"] = fmt.Sprintf("%.2f", scale)
+			info["canvas_scale"] = fmt.Sprintf("%.2f", canvasScale)
+			info["settings_backend"] = a.settings.Backend()
+			return info
+		},
+	}
+}
+
+// reportPreferences returns the preference values included in bug reports
+func (a *App) reportPreferences() map[string]any {
+	s := a.settings
+	cfg := a.config
+	return map[string]any{
+		"dark_mode":              s.BoolWithFallback("dark_mode", true),
+		"keep_line_breaks":       s.BoolWithFallback("keep_line_breaks", false),
+		"pinned_collapsed":       s.BoolWithFallback("pinned_collapsed", false),
+		"integrity_on_startup":   s.BoolWithFallback("integrity_on_startup", true),
+		"capture_key":            s.StringWithFallback("capture_key", system.DefaultCaptureKey),
+		"search_mode":            s.IntWithFallback("search_mode", int(searchNormal)),
+		"sort_order":             s.StringWithFallback("sort_order", string(sortNewest)),
+		"active_chips":           s.StringWithFallback("active_chips", ""),
+		"window_animations":      s.BoolWithFallback("window_animations", true),
+		"capture_encoded_copies": s.BoolWithFallback("capture_encoded_copies", false),
+		"disabled_smart_actions": s.StringWithFallback("disabled_smart_actions", ""),
+		"retention_days":         s.IntWithFallback("retention_days", 0),
+		"aging_days":             s.IntWithFallback("aging_days", defaultAgingDays),
+		"stale_days":             s.IntWithFallback("stale_days", defaultStaleDays),
+		"stale_prompt":           s.BoolWithFallback("stale_prompt", true),
+		"poll_interval_ms":       *cfg.PollIntervalMs,
+		"max_items":              *cfg.MaxItems,
+		"max_total_mb":           *cfg.MaxTotalMB,
+		"grace_minutes":          *cfg.GraceMinutes,
+		"limit_policy":           *cfg.LimitPolicy,
+		"newline_mode":           *cfg.NewlineMode,
+		"dedup_mode":             *cfg.DedupMode,
+		"pin_limit":              *cfg.PinLimit,
+		"double_copy_pin_ms":     *cfg.DoubleCopyPinMs,
+		"delta_images":           *cfg.DeltaImages,
+		"archive_enabled":        *cfg.ArchiveEnabled,
+		"archive_max_mb":         *cfg.ArchiveMaxMB,
+		"strip_tracking":         *cfg.StripTracking,
+		"tracking_params":        strings.Join(cfg.TrackingParams, ", "),
+		"capture_excluded_apps":  strings.Join(cfg.CaptureExcludedApps, ", "),
+		"redaction_rule_count":   len(a.redactionRules()),
+		"game_mode":              s.BoolWithFallback("game_mode", true),
+		"game_mode_hotkeys_off":  s.BoolWithFallback("game_mode_disable_hotkeys", false),
+		"selection_capture":      s.BoolWithFallback("selection_capture", false),
+		"excluded_app_count":     len(parseParamList(s.StringWithFallback("excluded_apps", ""))),
+	}
+}
+
+// reportMetrics counts items by type and class; no content or hashes are included
+func (a *App) reportMetrics() map[string]int64 {
+	metrics := map[string]int64{
+		"archive_bytes":   a.manager.GetArchiveSize(),
+		"max_items":       int64(a.manager.GetMaxItems()),
+		"max_total_bytes": a.manager.GetMaxTotalSize(),
+	}
+	for _, item := range a.manager.GetAllItems() {
+		metrics["items"]++
+		metrics["bytes"] += int64(item.Size)
+		metrics["type_"+item.Type]++
+		if item.Class != "" {
+			metrics["class_"+item.Class]++
+		}
+		if item.Pinned {
+			metrics["pinned"]++
+		}
+		if item.Delta != nil {
+			metrics["delta_images"]++
+		}
+		if corrupt, _ := a.manager.IsCorrupt(item.ID); corrupt {
+			metrics["corrupt"]++
+		}
+	}
+	return metrics
+}
+
+// diagnosticsText summarizes capture state for the bug report
+func (a *App) diagnosticsText(paused, locked bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Yakalama duraklatıldı: %v\n", paused)
+	fmt.Fprintf(&sb, "Oturum kilitli: %v\n", locked)
+	fmt.Fprintf(&sb, "Oyun modu: %v\n", a.IsGameMode())
+	counts := a.manager.Counts()
+	fmt.Fprintf(&sb, "Öğe: %d / %d (sabitlenmiş %d, arşivde %d)\n", counts.Active, counts.Limit, counts.Pinned, counts.Archived)
+	fmt.Fprintf(&sb, "Limite yakın: %v, dolu: %v\n", counts.IsNearLimit(), counts.IsFull())
+	fmt.Fprintf(&sb, "Koruma süresi: %s\n", a.manager.GetGraceWindow())
+	fmt.Fprintf(&sb, "Arşiv: %v (%s)\n", a.manager.GetArchiveEnabled(), formatSize(int(a.manager.GetArchiveSize())))
+	fmt.Fprintf(&sb, "Ayar deposu: %s\n", a.settings.Backend())
+
+	if temp, err := storage.CleanTempFiles(storage.JanitorOptions{MaxAge: manualTempMinAge, DryRun: true}); err == nil {
+		fmt.Fprintf(&sb, "Geçici dosyalar: %d (%s)\n", len(temp.Files), formatSize(int(temp.Bytes)))
+	}
+	return sb.String()
+}
+
+// buildVersion returns the module version and VCS revision embedded at build time
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			version += " (" + setting.Value[:12] + ")"
+		}
+	}
+	return version
+}
@@ -0,0 +1,304 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// listRowView is a recycled row of the list: a section header, a card or the load-more
+// button, whichever its row is. The card's labels, badges and buttons are made once and
+// updated for each item the row shows; the preview comes from the renderer's cache
+type listRowView struct {
+	widget.BaseWidget
+	r      *clipboardListRenderer
+	row    listRow // What the row shows
+	filled bool
+	root   *fyne.Container
+
+	pinnedHeader  *widget.Button
+	historyHeader *widget.Label
+	more          *widget.Button
+	card          *fyne.Container
+
+	bg, strip, veil *canvas.Rectangle
+	dimmed          *fyne.Container // User title and preview, under the veil of aging cards
+	title           *widget.Label
+	preview         *fyne.Container
+	source          *widget.Hyperlink
+	smart           *fyne.Container
+	content         *fyne.Container // Everything inside the card's padding
+	compact         bool            // Style content is arranged for
+
+	check          *widget.Check
+	corrupt        *widget.Icon
+	forced         *widget.Icon
+	redacted       *widget.Icon
+	pinnedBadge    *Badge
+	protectedBadge *Badge
+	staleBadge     *Badge
+	typeBadge      *Badge
+	rtfBadge       *Badge
+	tags           *fyne.Container
+	info           *widget.Label
+	infoRow        *fyne.Container
+
+	buttons     *fyne.Container
+	originalBtn *widget.Button
+	pinBtn      *widget.Button
+	moreBtn     *widget.Button
+	moreActions []smartAction // Smart actions of the card menu
+}
+
+// newListRowView creates an empty row; its widgets are made when it is first shown, so
+// the template widget.List sizes new rows by stays cheap
+func newListRowView(r *clipboardListRenderer) *listRowView {
+	placeholder := canvas.NewRectangle(color.Transparent)
+	placeholder.SetMinSize(fyne.NewSize(0, cardHeightEstimate))
+	v := &listRowView{r: r, root: container.NewStack(placeholder)}
+	v.ExtendBaseWidget(v)
+	return v
+}
+
+func (v *listRowView) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(v.root)
+}
+
+// show fills the row for row unless it already shows exactly that
+func (v *listRowView) show(row listRow) {
+	if v.filled && v.row.unchanged(row) {
+		return
+	}
+	if !v.filled {
+		v.build()
+		v.filled = true
+	}
+	v.row = row
+
+	setVisible(v.pinnedHeader, row.kind == rowHeader && row.section.kind == sectionPinned)
+	setVisible(v.historyHeader, row.kind == rowHeader && row.section.kind != sectionPinned)
+	setVisible(v.card, row.kind == rowCard)
+	setVisible(v.more, row.kind == rowMore)
+	switch row.kind {
+	case rowHeader:
+		v.fillHeader(row.section)
+	case rowCard:
+		v.fillCard(row)
+	case rowMore:
+		v.more.SetText(loadMoreText(row.more))
+	}
+	v.root.Refresh()
+}
+
+// measure returns the height the row needs for row at width
+func (v *listRowView) measure(row listRow, width float32) float32 {
+	v.show(row)
+	v.BaseWidget.Resize(fyne.NewSize(width, v.BaseWidget.MinSize().Height))
+	// Wrapped text only knows its height once it has been laid out at the width
+	return v.root.MinSize().Height
+}
+
+// build makes the header, card and button widgets
+func (v *listRowView) build() {
+	list := v.r.list
+
+	v.pinnedHeader = widget.NewButtonWithIcon("", theme.MenuDropDownIcon(), v.r.togglePinned)
+	v.pinnedHeader.Alignment = widget.ButtonAlignLeading
+	v.pinnedHeader.Importance = widget.LowImportance
+	v.historyHeader = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	v.more = widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), list.LoadMore)
+	v.more.Importance = widget.LowImportance
+
+	v.title = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	v.title.Truncation = fyne.TextTruncateEllipsis
+	v.preview = container.NewStack()
+	v.veil = canvas.NewRectangle(color.Transparent)
+	v.dimmed = container.NewStack(container.NewVBox(v.title, v.preview), v.veil)
+	v.source = widget.NewHyperlink("", nil)
+	v.source.Truncation = fyne.TextTruncateEllipsis
+	v.smart = container.NewStack()
+
+	v.check = widget.NewCheck("", func(checked bool) {
+		id := v.row.item.ID
+		if checked {
+			list.selected[id] = true
+		} else {
+			delete(list.selected, id)
+		}
+		v.row.key.selected = checked
+		if list.onSelectionChange != nil {
+			list.onSelectionChange()
+		}
+	})
+	// Integrity check failures get a warning badge; the item is never removed automatically
+	v.corrupt = widget.NewIcon(theme.WarningIcon())
+	// Manually captured items get a badge so they stand out from regular captures
+	v.forced = widget.NewIcon(theme.ContentPasteIcon())
+	// Masked items are marked so nobody mistakes them for the original text
+	v.redacted = widget.NewIcon(theme.VisibilityOffIcon())
+	v.pinnedBadge = newPinnedBadge()
+	v.protectedBadge = newProtectedBadge()
+	v.staleBadge = newStaleBadge()
+	v.typeBadge = newTypeBadge("")
+	// Text copied from word processors pastes with its formatting
+	v.rtfBadge = newRTFBadge()
+	v.tags = container.NewHBox()
+	v.info = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	v.infoRow = container.NewHBox(v.check, v.corrupt, v.forced, v.redacted, v.pinnedBadge,
+		v.protectedBadge, v.staleBadge, v.typeBadge, v.rtfBadge, v.tags, v.info)
+
+	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+		if list.onSelect != nil {
+			list.onSelect(v.row.item.ID)
+		}
+	})
+	copyBtn.Importance = widget.HighImportance
+	// Cleaned URLs keep their original around
+	v.originalBtn = widget.NewButtonWithIcon("", theme.HistoryIcon(), func() {
+		if list.onCopyOriginal != nil {
+			list.onCopyOriginal(v.row.item.ID)
+		}
+	})
+	detailsBtn := widget.NewButtonWithIcon("", theme.InfoIcon(), func() {
+		if list.onDetails != nil {
+			list.onDetails(v.row.item.ID)
+		}
+	})
+	renameBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+		if list.onRename != nil {
+			list.onRename(v.row.item)
+		}
+	})
+	renameBtn.Importance = widget.LowImportance
+	v.pinBtn = widget.NewButtonWithIcon("", theme.CheckButtonIcon(), func() {
+		if list.onPin != nil {
+			list.onPin(v.row.item.ID)
+		}
+	})
+	delBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		if list.onDelete != nil {
+			list.onDelete(v.row.item.ID)
+		}
+	})
+	// Text and image items get a menu for one-shot copies in other formats
+	v.moreBtn = widget.NewButtonWithIcon("", theme.MoreVerticalIcon(), func() {
+		v.r.showCardMenu(v.moreBtn, v.row.item, v.moreActions)
+	})
+	v.buttons = container.NewHBox(copyBtn, v.originalBtn, detailsBtn, renameBtn, v.pinBtn, delBtn, v.moreBtn)
+
+	v.bg = canvas.NewRectangle(color.Transparent)
+	v.bg.CornerRadius = 8
+	v.bg.StrokeWidth = 1
+	v.strip = canvas.NewRectangle(color.Transparent)
+	v.strip.SetMinSize(fyne.NewSize(staleStripWidth, 0))
+	v.content = container.NewVBox()
+	v.arrange(list.compact)
+	v.card = container.NewStack(v.bg, container.NewBorder(nil, nil, v.strip, nil, container.NewPadded(v.content)))
+
+	v.root.Objects = []fyne.CanvasObject{v.pinnedHeader, v.historyHeader, v.card, v.more}
+}
+
+// arrange lays out the card content for the regular or compact style
+func (v *listRowView) arrange(compact bool) {
+	v.compact = compact
+	if compact {
+		// The sidebar is too narrow for badges and buttons on one row
+		v.content.Objects = []fyne.CanvasObject{v.dimmed, v.source, v.smart, v.infoRow,
+			container.NewBorder(nil, nil, nil, v.buttons)}
+	} else {
+		v.content.Objects = []fyne.CanvasObject{v.dimmed, v.source, v.smart,
+			container.NewBorder(nil, nil, v.infoRow, v.buttons)}
+	}
+}
+
+// fillHeader shows a section header; the pinned one toggles its section
+func (v *listRowView) fillHeader(section listSection) {
+	if section.kind != sectionPinned {
+		v.historyHeader.SetText(section.title)
+		return
+	}
+	v.pinnedHeader.SetText(section.title)
+	if section.collapsed {
+		v.pinnedHeader.SetIcon(theme.MenuExpandIcon())
+	} else {
+		v.pinnedHeader.SetIcon(theme.MenuDropDownIcon())
+	}
+}
+
+// fillCard shows the card of the row's item
+func (v *listRowView) fillCard(row listRow) {
+	item := row.item
+	list := v.r.list
+	if list.compact != v.compact {
+		v.arrange(list.compact)
+	}
+
+	preview := v.r.cachedPreview(item, row.key.preview)
+	v.preview.Objects = []fyne.CanvasObject{preview.content}
+	v.moreActions = preview.more
+	if preview.smart != nil {
+		v.smart.Objects = []fyne.CanvasObject{preview.smart}
+	} else {
+		v.smart.Objects = nil
+	}
+	setVisible(v.smart, preview.smart != nil)
+
+	title := v.r.userTitleText(item)
+	v.title.SetText(title)
+	setVisible(v.title, title != "")
+	applyFreshness(v.veil, v.strip, row.key.fresh)
+
+	if u := v.r.sourceURL(item); u != nil {
+		v.source.SetText(sourceLinkText(u))
+		v.source.SetURL(u)
+		v.source.Show()
+	} else {
+		v.source.Hide()
+	}
+
+	info := fmt.Sprintf("%s - %s", formatSize(item.Size), row.key.time)
+	if list.compact {
+		info = row.key.time
+	} else if app := v.r.sourceAppText(item); app != "" {
+		info += " - " + app
+	}
+	v.info.SetText(info)
+
+	v.check.Checked = row.key.selected
+	setVisible(v.corrupt, row.key.corrupt)
+	setVisible(v.forced, item.Forced)
+	setVisible(v.redacted, item.Redacted)
+	setVisible(v.pinnedBadge, item.Pinned)
+	setVisible(v.protectedBadge, item.IsProtected())
+	setVisible(v.staleBadge, row.key.fresh == freshnessStale)
+	typeBadge := newTypeBadge(item.Type)
+	v.typeBadge.Text, v.typeBadge.Kind = typeBadge.Text, typeBadge.Kind
+	setVisible(v.rtfBadge, item.RTF != "")
+	v.tags.Objects = newTagBadges(item.Tags)
+	setVisible(v.tags, len(item.Tags) > 0)
+
+	if item.Pinned {
+		v.pinBtn.SetIcon(theme.CheckButtonCheckedIcon())
+	} else {
+		v.pinBtn.SetIcon(theme.CheckButtonIcon())
+	}
+	setVisible(v.originalBtn, item.Original != "" && !item.IsProtected())
+	setVisible(v.moreBtn, item.Type == "text" || item.Type == "image")
+
+	v.bg.FillColor = GetCardBackgroundColor(item.Pinned)
+	v.bg.StrokeColor = GetCardBorderColor(item.Pinned)
+}
+
+// setVisible shows or hides obj
+func setVisible(obj fyne.CanvasObject, visible bool) {
+	if visible {
+		obj.Show()
+	} else {
+		obj.Hide()
+	}
+}
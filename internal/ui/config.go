@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+
+	"pano/internal/clipboard"
+	"pano/internal/settings"
+)
+
+// effectiveConfig layers the startup configuration: defaults < file < preferences < flags
+// Preferences fall back to the file value, so only keys the user changed in the GUI win over it
+func effectiveConfig(prefs settings.Settings, file, flags *clipboard.Config) *clipboard.Config {
+	base := clipboard.DefaultConfig().Merge(file)
+
+	maxItems := prefs.IntWithFallback("max_items", *base.MaxItems)
+	maxTotalMB := prefs.IntWithFallback("max_total_mb", *base.MaxTotalMB)
+	graceMinutes := prefs.IntWithFallback("grace_minutes", *base.GraceMinutes)
+	limitPolicy := prefs.StringWithFallback("limit_policy", *base.LimitPolicy)
+	newline := prefs.StringWithFallback("newline_mode", *base.NewlineMode)
+	deltaImages := prefs.BoolWithFallback("delta_images", *base.DeltaImages)
+	archiveEnabled := prefs.BoolWithFallback("archive_enabled", *base.ArchiveEnabled)
+	archiveMaxMB := prefs.IntWithFallback("archive_max_mb", *base.ArchiveMaxMB)
+	stripTracking := prefs.BoolWithFallback("strip_tracking", *base.StripTracking)
+	trackingParams := parseParamList(prefs.StringWithFallback("tracking_params", strings.Join(base.TrackingParams, ", ")))
+	dedupMode := prefs.StringWithFallback("dedup_mode", *base.DedupMode)
+	pinLimit := prefs.IntWithFallback("pin_limit", *base.PinLimit)
+	doubleCopy := prefs.IntWithFallback("double_copy_pin_ms", *base.DoubleCopyPinMs)
+	pollInterval := prefs.IntWithFallback("poll_interval_ms", *base.PollIntervalMs)
+	excludedApps := parseParamList(prefs.StringWithFallback("capture_excluded_apps", strings.Join(base.CaptureExcludedApps, ", ")))
+
+	fromPrefs := &clipboard.Config{
+		PollIntervalMs:  &pollInterval,
+		MaxItems:        &maxItems,
+		MaxTotalMB:      &maxTotalMB,
+		GraceMinutes:    &graceMinutes,
+		LimitPolicy:     &limitPolicy,
+		NewlineMode:     &newline,
+		DeltaImages:     &deltaImages,
+		ArchiveEnabled:  &archiveEnabled,
+		ArchiveMaxMB:    &archiveMaxMB,
+		StripTracking:   &stripTracking,
+		TrackingParams:  trackingParams,
+		DedupMode:       &dedupMode,
+		PinLimit:        &pinLimit,
+		DoubleCopyPinMs: &doubleCopy,
+
+		CaptureExcludedApps: excludedApps,
+	}
+
+	return base.Merge(fromPrefs).Merge(flags)
+}
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// findItem returns the metadata of an item by ID
+func (a *App) findItem(id string) (storage.ClipboardItem, bool) {
+	for _, item := range a.manager.GetAllItems() {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return storage.ClipboardItem{}, false
+}
+
+// showItemDetails shows item metadata including its SHA-256 for verification
+func (a *App) showItemDetails(id string) {
+	item, ok := a.findItem(id)
+	if !ok {
+		dialog.ShowError(fmt.Errorf("item not found"), a.window)
+		return
+	}
+
+	hashLabel := widget.NewLabelWithStyle(item.Hash, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	hashLabel.Wrapping = fyne.TextWrapBreak
+	copyHashBtn := widget.NewButtonWithIcon("Karmayı kopyala", theme.ContentCopyIcon(), func() {
+		a.window.Clipboard().SetContent(item.Hash)
+		a.showToast("Karma kopyalandı")
+	})
+
+	typeRow := container.NewHBox(widget.NewLabel("Tür:"), newTypeBadge(item.Type))
+	if item.Pinned {
+		typeRow.Add(newPinnedBadge())
+	}
+
+	if item.IsProtected() {
+		typeRow.Add(newProtectedBadge())
+	}
+
+	content := container.NewVBox(typeRow)
+	if title, err := a.manager.GetItemUserTitle(id); err == nil && title != "" {
+		content.Add(widget.NewLabel(fmt.Sprintf("Başlık: %s", title)))
+	}
+	content.Add(widget.NewLabel(fmt.Sprintf("Boyut: %s", formatSize(item.Size))))
+	content.Add(widget.NewLabel(fmt.Sprintf("Zaman: %s", item.Timestamp.Format("02.01.2006 15:04:05"))))
+	if item.CopyCount > 0 {
+		content.Add(widget.NewLabel(fmt.Sprintf("Kullanım: %d kez, son %s", item.CopyCount, item.LastUsed.Format("02.01.2006 15:04"))))
+	}
+	if source, err := a.manager.GetItemSourceURL(id); err == nil && source != "" {
+		sourceLabel := widget.NewLabel(fmt.Sprintf("Kaynak: %s", source))
+		sourceLabel.Wrapping = fyne.TextWrapBreak
+		content.Add(sourceLabel)
+	}
+	if app, err := a.manager.GetItemSourceApp(id); err == nil && app != "" {
+		content.Add(widget.NewLabel(fmt.Sprintf("Uygulama: %s", app)))
+	}
+	// The hash of a short secret is enough to guess it offline
+	if item.IsProtected() {
+		dialog.ShowCustom("Öğe Ayrıntıları", "Kapat", content, a.window)
+		return
+	}
+	content.Add(container.NewVBox(
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("SHA-256", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		hashLabel,
+		copyHashBtn,
+	))
+
+	if item.Type == "files" {
+		content.Add(widget.NewSeparator())
+		content.Add(a.createFileList(id))
+	}
+	if item.Type == "text" {
+		content.Add(widget.NewSeparator())
+		content.Add(widget.NewButtonWithIcon("İçeriği görüntüle", theme.DocumentIcon(), func() {
+			a.showContentViewer(id)
+		}))
+	}
+
+	dialog.ShowCustom("Öğe Ayrıntıları", "Kapat", content, a.window)
+}
+
+// compareSelected compares the two multi-selected items by hash and, for text, shows a diff
+func (a *App) compareSelected() {
+	ids := a.list.Selected()
+	if len(ids) != 2 {
+		a.showToast("Karşılaştırmak için tam olarak iki öğe seçin")
+		return
+	}
+
+	first, okFirst := a.findItem(ids[0])
+	second, okSecond := a.findItem(ids[1])
+	if !okFirst || !okSecond {
+		dialog.ShowError(fmt.Errorf("item not found"), a.window)
+		return
+	}
+
+	if first.Hash == second.Hash {
+		a.showToast("Öğeler aynı (SHA-256 eşleşiyor)")
+		return
+	}
+
+	if first.Type != "text" || second.Type != "text" {
+		a.showToast("Öğeler farklı (SHA-256 eşleşmiyor)")
+		return
+	}
+
+	dataA, err := a.manager.GetItemContent(first.ID)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	defer storage.Zero(dataA)
+	dataB, err := a.manager.GetItemContent(second.ID)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	defer storage.Zero(dataB)
+
+	diff, err := UnifiedDiff(string(dataA), string(dataB), "ilk", "ikinci")
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	if diff == "" {
+		// Same lines, different bytes (e.g. line endings or trailing newline)
+		diff = "Satırlar aynı; fark yalnızca satır sonlarında."
+	}
+
+	diffLabel := widget.NewLabelWithStyle(diff, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	scroll := container.NewScroll(diffLabel)
+	scroll.SetMinSize(fyne.NewSize(340, 300))
+
+	content := container.NewBorder(
+		widget.NewLabel("Öğeler farklı (SHA-256 eşleşmiyor)."),
+		nil, nil, nil,
+		scroll,
+	)
+	dialog.ShowCustom("Karşılaştır", "Kapat", content, a.window)
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	diffContextLines = 3         // Unchanged lines shown around each change
+	diffMaxCells     = 4_000_000 // LCS table limit (lines a * lines b)
+)
+
+// diffOp is a single line in an edit script
+type diffOp struct {
+	kind byte // ' ' unchanged, '-' removed, '+' added
+	line string
+}
+
+// splitDiffLines splits text into lines, normalizing line endings
+func splitDiffLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a line edit script turning a into b using an LCS table
+func diffLines(a, b []string) ([]diffOp, error) {
+	// Trim the common prefix and suffix so the table only covers the changed middle
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	if (len(midA)+1)*(len(midB)+1) > diffMaxCells {
+		return nil, fmt.Errorf("metinler karşılaştırılamayacak kadar büyük")
+	}
+
+	// lcs[i][j] = length of the LCS of midA[i:] and midB[j:]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	i, j := 0, 0
+	for i < len(midA) && j < len(midB) {
+		switch {
+		case midA[i] == midB[j]:
+			ops = append(ops, diffOp{' ', midA[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', midA[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', midB[j]})
+			j++
+		}
+	}
+	for ; i < len(midA); i++ {
+		ops = append(ops, diffOp{'-', midA[i]})
+	}
+	for ; j < len(midB); j++ {
+		ops = append(ops, diffOp{'+', midB[j]})
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops, nil
+}
+
+// UnifiedDiff renders a unified diff of two texts with diffContextLines of context
+// Returns an empty string when the texts are identical
+func UnifiedDiff(a, b, nameA, nameB string) (string, error) {
+	ops, err := diffLines(splitDiffLines(a), splitDiffLines(b))
+	if err != nil {
+		return "", err
+	}
+
+	// Find changed op indexes; no changes means no diff
+	changed := make([]int, 0)
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+
+	// Group changes into hunks whose context windows overlap
+	for start := 0; start < len(changed); {
+		end := start
+		for end+1 < len(changed) && changed[end+1]-changed[end] <= 2*diffContextLines {
+			end++
+		}
+
+		from := changed[start] - diffContextLines
+		if from < 0 {
+			from = 0
+		}
+		to := changed[end] + diffContextLines + 1
+		if to > len(ops) {
+			to = len(ops)
+		}
+
+		// Line numbers (1-based) of the hunk start in each text
+		lineA, lineB := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != '+' {
+				lineA++
+			}
+			if op.kind != '-' {
+				lineB++
+			}
+		}
+		countA, countB := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				countA++
+			}
+			if op.kind != '-' {
+				countB++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB)
+		for _, op := range ops[from:to] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+
+		start = end + 1
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "identical",
+			a:    "bir\niki\nüç\n",
+			b:    "bir\niki\nüç\n",
+			want: "",
+		},
+		{
+			name: "line endings only",
+			a:    "bir\r\niki\r\n",
+			b:    "bir\niki",
+			want: "",
+		},
+		{
+			name: "insert",
+			a:    "bir\niki\n",
+			b:    "bir\nbuçuk\niki\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,3 @@\n bir\n+buçuk\n iki\n",
+		},
+		{
+			name: "delete",
+			a:    "bir\niki\nüç\n",
+			b:    "bir\nüç\n",
+			want: "--- a\n+++ b\n@@ -1,3 +1,2 @@\n bir\n-iki\n üç\n",
+		},
+		{
+			name: "change",
+			a:    "ad: Ayşe\nşehir: İzmir\n",
+			b:    "ad: Ayşe\nşehir: Iğdır\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n ad: Ayşe\n-şehir: İzmir\n+şehir: Iğdır\n",
+		},
+		{
+			name: "context is cut to three lines",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nbeş\n6\n7\n8\n9\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+beş\n 6\n 7\n 8\n",
+		},
+		{
+			name: "distant changes make two hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			b:    "bir\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\non iki\n",
+			want: "--- a\n+++ b\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+bir\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+on iki\n",
+		},
+		{
+			name: "nearby changes share a hunk",
+			a:    "1\n2\n3\n4\n5\n6\n7\n",
+			b:    "bir\n2\n3\n4\n5\n6\nyedi\n",
+			want: "--- a\n+++ b\n@@ -1,7 +1,7 @@\n-1\n+bir\n 2\n 3\n 4\n 5\n 6\n-7\n+yedi\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnifiedDiff(tt.a, tt.b, "a", "b")
+			if err != nil {
+				t.Fatalf("UnifiedDiff failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffTooLarge(t *testing.T) {
+	a := strings.Repeat("a\n", 2100)
+	b := strings.Repeat("b\n", 2100)
+	if _, err := UnifiedDiff(a, b, "a", "b"); err == nil {
+		t.Error("texts over the table limit were diffed")
+	}
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"pano/internal/storage"
+	"pano/internal/system"
+)
+
+// The weekly digest is an opt-in notification about how the history grows. A ticker
+// checks whether the configured weekly slot has passed since the last digest; the last
+// run is saved in preferences, so weeks missed while the machine was off fire once on
+// the next launch. A digest due during Focus Assist waits for the next check.
+
+const (
+	digestCheckInterval = 15 * time.Minute
+	digestWeek          = 7 * 24 * time.Hour
+	digestOldAge        = 30 * 24 * time.Hour // Items older than this are counted as old
+
+	defaultDigestWeekday = time.Monday
+	defaultDigestHour    = 10
+)
+
+// digestStats is what the digest reports, taken from item metadata
+type digestStats struct {
+	Captured int // Items captured (or copied again) in the last week
+	Bytes    int // Size of the whole history
+	Old      int // Items older than digestOldAge
+}
+
+// collectDigestStats summarizes items as of now
+func collectDigestStats(items []storage.ClipboardItem, now time.Time) digestStats {
+	var stats digestStats
+	for _, item := range items {
+		age := now.Sub(item.Timestamp)
+		if age < digestWeek {
+			stats.Captured++
+		}
+		if age > digestOldAge {
+			stats.Old++
+		}
+		stats.Bytes += item.Size
+	}
+	return stats
+}
+
+// composeDigest returns the notification text for stats
+func composeDigest(stats digestStats) string {
+	text := fmt.Sprintf("Bu hafta %d öğe yakalandı, %s kullanıldı", stats.Captured, formatSize(stats.Bytes))
+	if stats.Old > 0 {
+		text += fmt.Sprintf("; %d öğe 30 günden eski", stats.Old)
+	}
+	return text
+}
+
+// lastDigestSlot returns the most recent weekday at hour:00 that isn't after now
+func lastDigestSlot(now time.Time, weekday time.Weekday, hour int) time.Time {
+	y, m, d := now.Date()
+	slot := time.Date(y, m, d, hour, 0, 0, 0, now.Location())
+	slot = slot.AddDate(0, 0, -((int(now.Weekday()) - int(weekday) + 7) % 7))
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -7)
+	}
+	return slot
+}
+
+// StartDigest begins checking for the weekly digest; a no-op if already running
+func (a *App) StartDigest() {
+	a.digestMu.Lock()
+	defer a.digestMu.Unlock()
+	if a.digestStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	a.digestStop = stop
+
+	go func() {
+		ticker := time.NewTicker(digestCheckInterval)
+		defer ticker.Stop()
+		for {
+			a.checkDigest(time.Now())
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopDigest stops the digest checks
+func (a *App) StopDigest() {
+	a.digestMu.Lock()
+	defer a.digestMu.Unlock()
+	if a.digestStop != nil {
+		close(a.digestStop)
+		a.digestStop = nil
+	}
+}
+
+// checkDigest sends the digest if its weekly slot passed since the last one
+func (a *App) checkDigest(now time.Time) {
+	if !a.settings.BoolWithFallback("weekly_digest", false) {
+		return
+	}
+	last := a.settings.IntWithFallback("digest_last_run", 0)
+	if last == 0 {
+		// Turned on without a record (e.g. preferences copied over): start counting now
+		a.settings.SetInt("digest_last_run", int(now.Unix()))
+		return
+	}
+
+	weekday := time.Weekday(a.settings.IntWithFallback("digest_weekday", int(defaultDigestWeekday)))
+	hour := a.settings.IntWithFallback("digest_hour", defaultDigestHour)
+	if !lastDigestSlot(now, weekday, hour).After(time.Unix(int64(last), 0)) {
+		return
+	}
+	if a.IsGameMode() || system.NotificationsMuted() {
+		return
+	}
+
+	stats := collectDigestStats(a.manager.GetAllItems(), now)
+	a.settings.SetInt("digest_last_run", int(now.Unix()))
+	a.sendNotification("Haftalık Özet", composeDigest(stats))
+}
+
+// digestWeekdayOptions are the days the digest can be sent on, Monday first
+var digestWeekdayOptions = []struct {
+	label   string
+	weekday time.Weekday
+}{
+	{"Pazartesi", time.Monday},
+	{"Salı", time.Tuesday},
+	{"Çarşamba", time.Wednesday},
+	{"Perşembe", time.Thursday},
+	{"Cuma", time.Friday},
+	{"Cumartesi", time.Saturday},
+	{"Pazar", time.Sunday},
+}
+
+// digestWeekdayLabels returns the labels of digestWeekdayOptions
+func digestWeekdayLabels() []string {
+	labels := make([]string, 0, len(digestWeekdayOptions))
+	for _, opt := range digestWeekdayOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// digestHourLabels returns the hours of the day as "09:00"
+func digestHourLabels() []string {
+	labels := make([]string, 0, 24)
+	for hour := 0; hour < 24; hour++ {
+		labels = append(labels, fmt.Sprintf("%02d:00", hour))
+	}
+	return labels
+}
+
+// digestHourFromLabel parses a label of digestHourLabels back to its hour
+func digestHourFromLabel(label string) int {
+	var hour int
+	if _, err := fmt.Sscanf(label, "%d:00", &hour); err != nil {
+		return defaultDigestHour
+	}
+	return hour
+}
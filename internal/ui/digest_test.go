@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"pano/internal/storage"
+)
+
+func TestCollectDigestStats(t *testing.T) {
+	now := time.Date(2024, 3, 11, 10, 0, 0, 0, time.UTC)
+	items := []storage.ClipboardItem{
+		{Timestamp: now.Add(-time.Hour), Size: 100},
+		{Timestamp: now.Add(-6 * 24 * time.Hour), Size: 200},
+		{Timestamp: now.Add(-10 * 24 * time.Hour), Size: 300},
+		{Timestamp: now.Add(-40 * 24 * time.Hour), Size: 400},
+	}
+	want := digestStats{Captured: 2, Bytes: 1000, Old: 1}
+	if got := collectDigestStats(items, now); got != want {
+		t.Errorf("stats are %+v, want %+v", got, want)
+	}
+	if got := collectDigestStats(nil, now); got != (digestStats{}) {
+		t.Errorf("empty history gave %+v", got)
+	}
+}
+
+func TestComposeDigest(t *testing.T) {
+	tests := []struct {
+		stats digestStats
+		want  string
+	}{
+		{digestStats{Captured: 3, Bytes: 512}, "Bu hafta 3 öğe yakalandı, 512 B kullanıldı"},
+		{digestStats{Captured: 0, Bytes: 2048, Old: 4}, "Bu hafta 0 öğe yakalandı, 2.0 KB kullanıldı; 4 öğe 30 günden eski"},
+	}
+	for _, tt := range tests {
+		if got := composeDigest(tt.stats); got != tt.want {
+			t.Errorf("composeDigest(%+v) = %q, want %q", tt.stats, got, tt.want)
+		}
+	}
+}
+
+// The slot is the latest weekly one not after now, so a missed week fires only once
+func TestLastDigestSlot(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, time.UTC) // March 11 is a Monday
+	}
+	tests := []struct {
+		name    string
+		now     time.Time
+		weekday time.Weekday
+		want    time.Time
+	}{
+		{"on the slot", at(11, 10, 0), time.Monday, at(11, 10, 0)},
+		{"later that day", at(11, 18, 30), time.Monday, at(11, 10, 0)},
+		{"earlier that day", at(11, 9, 59), time.Monday, at(4, 10, 0)},
+		{"later in the week", at(14, 8, 0), time.Monday, at(11, 10, 0)},
+		{"day before the slot", at(10, 23, 0), time.Monday, at(4, 10, 0)},
+		{"sunday slot", at(13, 12, 0), time.Sunday, at(10, 10, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastDigestSlot(tt.now, tt.weekday, 10); !got.Equal(tt.want) {
+				t.Errorf("lastDigestSlot(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigestHourLabels(t *testing.T) {
+	labels := digestHourLabels()
+	if len(labels) != 24 || labels[9] != "09:00" {
+		t.Fatalf("labels are %q", labels)
+	}
+	for hour, label := range labels {
+		if got := digestHourFromLabel(label); got != hour {
+			t.Errorf("digestHourFromLabel(%q) = %d", label, got)
+		}
+	}
+	if got := digestHourFromLabel("öğlen"); got != defaultDigestHour {
+		t.Errorf("unparsable label gave %d", got)
+	}
+	if got := len(digestWeekdayLabels()); got != 7 {
+		t.Errorf("%d weekday labels", got)
+	}
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// dockWidth is the width of the docked sidebar at 100% scaling; the shell reserves it
+// on the right edge of the monitor (see system.AppBar)
+const dockWidth = 340
+
+// dockControls are the header widgets that change between the floating and docked layout
+type dockControls struct {
+	title          *widget.Label
+	newItem        *widget.Button
+	clear          *widget.Button
+	stack          *widget.Button
+	deleteSelected *widget.Button
+	toggle         *widget.Button
+}
+
+// toggleDock docks or undocks through the dock_sidebar preference, so the settings
+// dialog and the toolbar button stay in sync
+func (a *App) toggleDock() {
+	a.settings.SetBool("dock_sidebar", !a.docked)
+}
+
+// applyDock docks the window as a sidebar or returns it to a floating window, whichever
+// dock_sidebar asks for (UI thread only)
+// A failed dock clears the preference again, so it isn't retried on every start
+func (a *App) applyDock() {
+	want := a.settings.BoolWithFallback("dock_sidebar", false)
+	if want == a.docked {
+		return
+	}
+
+	if want {
+		// The sidebar is always visible; a hidden window has no place to dock
+		if !a.isVisible {
+			a.Show(ShowSourceTray)
+		}
+		if err := a.appBar.Dock(a.mainWindowHandle(), dockWidth); err != nil {
+			log.Printf("Warning: Failed to dock window: %v", err)
+			a.showToast("Pencere kenara yerleştirilemedi")
+			a.settings.SetBool("dock_sidebar", false)
+			return
+		}
+		a.docked = true
+	} else {
+		a.appBar.Undock()
+		a.docked = false
+		a.window.Resize(scaledWindowSize(defaultWindowSize, dpiScale(windowDPI(a.mainWindowHandle())), a.window.Canvas().Scale()))
+		a.window.CenterOnScreen()
+	}
+	a.applyDockLayout()
+}
+
+// applyDockLayout fits the header and the cards to the current mode (UI thread only)
+// Button labels are dropped in the sidebar so the header fits its width
+func (a *App) applyDockLayout() {
+	c := a.dockUI
+	if a.docked {
+		c.title.Hide()
+		c.newItem.SetText("")
+		c.clear.SetText("")
+		c.stack.SetText("")
+		c.deleteSelected.SetText("")
+		c.toggle.SetIcon(theme.NavigateBackIcon())
+	} else {
+		c.title.Show()
+		c.newItem.SetText("Yeni öğe")
+		c.clear.SetText("Temizle")
+		c.stack.SetText("Sıraya al")
+		c.deleteSelected.SetText("Seçilenleri Sil")
+		c.toggle.SetIcon(theme.NavigateNextIcon())
+	}
+	a.list.SetCompact(a.docked)
+	a.list.Refresh()
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"pano/internal/storage"
+)
+
+// v1FieldLabels names the fields a v1 export drops
+var v1FieldLabels = map[string]string{
+	"phash":      "görsel benzerlik özeti",
+	"class":      "metin türü",
+	"original":   "temizlenmemiş URL",
+	"forced":     "elle yakalama işareti",
+	"source":     "kaynak sayfa",
+	"app":        "kaynak uygulama",
+	"rtf":        "RTF biçimlendirmesi",
+	"redacted":   "maskeleme işareti",
+	"title":      "başlık",
+	"user_title": "kullanıcı başlığı",
+	"tags":       "etiketler",
+	"uses":       "kullanım sayısı",
+	"unknown":    "daha yeni sürümlerin alanları",
+}
+
+// exportForOldVersion shows what a v1 export drops, then saves it where the user picks
+func (a *App) exportForOldVersion() {
+	if !a.manager.Permissions().CanExport() {
+		return
+	}
+	plan, err := a.manager.PlanV1Export()
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+
+	lines := []string{fmt.Sprintf("%d öğe ilk sürümün okuyabileceği biçimde kaydedilecek.", plan.Items)}
+	if plan.Expanded > 0 {
+		lines = append(lines, fmt.Sprintf("%d fark olarak saklanan görsel tam görsel olarak yazılacak.", plan.Expanded))
+	}
+	if plan.Skipped > 0 {
+		lines = append(lines, fmt.Sprintf("%d görsel okunamadığı için atlanacak.", plan.Skipped))
+	}
+	if plan.Protected > 0 {
+		lines = append(lines, fmt.Sprintf("%d korumalı öğe atlanacak; bunlar yalnızca parolayla dışa aktarmaya girer.", plan.Protected))
+	}
+	lost := make([]string, 0)
+	for _, field := range storage.V1LostFields {
+		if n := plan.Lost[field]; n > 0 {
+			lost = append(lost, fmt.Sprintf("- %s (%d öğe)", v1FieldLabels[field], n))
+		}
+	}
+	if len(lost) > 0 {
+		lines = append(lines, "Bu bilgiler dışa aktarılmaz:")
+		lines = append(lines, lost...)
+	}
+	lines = append(lines, "Dosya bu bilgisayarın anahtarıyla şifrelenir.")
+
+	dialog.ShowConfirm("Eski sürüm için dışa aktar", strings.Join(lines, "\n"), func(ok bool) {
+		if ok {
+			a.saveV1Export()
+		}
+	}, a.window)
+}
+
+// saveV1Export asks for the target file and writes the export
+func (a *App) saveV1Export() {
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if w == nil {
+			return
+		}
+		report, err := a.manager.ExportV1(w)
+		if closeErr := w.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to save export: %w", closeErr)
+		}
+		if err != nil {
+			os.Remove(w.URI().Path())
+			dialog.ShowError(err, a.window)
+			return
+		}
+		a.showToast(fmt.Sprintf("%d öğe dışa aktarıldı", report.Items))
+	}, a.window)
+	save.SetFileName(storage.DatabaseFile)
+	save.Show()
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	baseDPI      = 96 // Windows DPI at 100% scaling
+	iconBaseSize = 32 // Logical size of the taskbar/tray icon Windows picks from the resource
+)
+
+// dpiScale converts a monitor DPI to a scale factor (1 at 100%); an unknown DPI (0) gives 0
+func dpiScale(dpi uint32) float32 {
+	if dpi == 0 {
+		return 0
+	}
+	return float32(dpi) / baseDPI
+}
+
+// pixelSize converts a logical length to device pixels, rounding up so nothing is stretched on screen
+func pixelSize(logical int, scale float32) int {
+	if scale <= 0 {
+		scale = 1
+	}
+	return int(math.Ceil(float64(logical) * float64(scale)))
+}
+
+// thumbPixelSize returns the bitmap size to decode a thumbnail at for the given scale
+// createThumbnailFast never upscales, so small images keep their own size
+func thumbPixelSize(tl thumbLayout, scale float32) (int, int) {
+	return pixelSize(tl.ImageW, scale), pixelSize(tl.ImageH, scale)
+}
+
+// iconPixelSize returns the pixel size to render the app icon at for the given scale
+func iconPixelSize(scale float32) int {
+	return pixelSize(iconBaseSize, scale)
+}
+
+// scaledWindowSize corrects a logical window size when Fyne's canvas scale differs from the
+// monitor's DPI scale, e.g. when the window was created on a monitor with another scaling
+// Returns base unchanged when either scale is unknown
+func scaledWindowSize(base fyne.Size, monitorScale, canvasScale float32) fyne.Size {
+	if monitorScale <= 0 || canvasScale <= 0 {
+		return base
+	}
+	factor := monitorScale / canvasScale
+	return fyne.NewSize(float32(math.Round(float64(base.Width*factor))), float32(math.Round(float64(base.Height*factor))))
+}
+
+// pixelScale returns the device pixels per logical unit for the main window
+// Windows reports the DPI of the monitor the window is on; elsewhere Fyne's scale is used
+func (a *App) pixelScale() float32 {
+	if scale := dpiScale(windowDPI(a.mainWindowHandle())); scale > 0 {
+		return scale
+	}
+	if c := a.window.Canvas(); c != nil && c.Scale() > 0 {
+		return c.Scale()
+	}
+	return 1
+}
+
+// applyDPI sizes thumbnails and icons for the current monitor (UI thread only)
+// Cached thumbnails are regenerated lazily because their entries remember the scale they were made at
+func (a *App) applyDPI() {
+	scale := a.pixelScale()
+	if scale == a.list.pixelScale {
+		return
+	}
+	a.list.SetPixelScale(scale)
+	a.list.Refresh()
+
+	if a.iconRenderer != nil {
+		if icon := a.iconRenderer(iconPixelSize(scale)); icon != nil {
+			a.fyneApp.SetIcon(icon)
+			a.window.SetIcon(icon)
+			a.setTrayIcon(icon)
+		}
+	}
+}
+
+// SetIconRenderer sets the function that draws the app icon at a pixel size
+// The icon is redrawn whenever the window moves to a monitor with another DPI
+func (a *App) SetIconRenderer(render func(size int) fyne.Resource) {
+	a.iconRenderer = render
+}
+
+// watchDPIChanges starts following the main window's DPI (Windows only)
+func (a *App) watchDPIChanges() {
+	hwnd := a.mainWindowHandle()
+	if hwnd == 0 {
+		return
+	}
+	stop, err := watchWindowDPI(hwnd, func() {
+		fyne.Do(a.applyDPI)
+	})
+	if err != nil {
+		return
+	}
+	a.stopDPIWatch = stop
+}
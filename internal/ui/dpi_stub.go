@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import "errors"
+
+// windowDPI is unknown on non-Windows platforms; callers fall back to Fyne's scale
+func windowDPI(hwnd uintptr) uint32 {
+	return 0
+}
+
+// watchWindowDPI is unavailable on non-Windows platforms
+func watchWindowDPI(hwnd uintptr, notify func()) (func(), error) {
+	return nil, errors.New("DPI change notifications are only available on Windows")
+}
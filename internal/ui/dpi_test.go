@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+)
+
+// TestDPIScale converts monitor DPIs to the pixel sizes thumbnails and icons are made at
+func TestDPIScale(t *testing.T) {
+	tests := []struct {
+		dpi    uint32
+		scale  float32
+		icon   int
+		thumbW int
+		thumbH int
+	}{
+		{0, 0, 32, 320, 180}, // Unknown: 100%
+		{96, 1, 32, 320, 180},
+		{120, 1.25, 40, 400, 225},
+		{144, 1.5, 48, 480, 270},
+		{168, 1.75, 56, 560, 315},
+		{192, 2, 64, 640, 360},
+	}
+	tl := thumbLayout{Width: 320, Height: 180, ImageW: 320, ImageH: 180}
+	for _, tt := range tests {
+		scale := dpiScale(tt.dpi)
+		if scale != tt.scale {
+			t.Errorf("dpiScale(%d) = %v, want %v", tt.dpi, scale, tt.scale)
+		}
+		if got := iconPixelSize(scale); got != tt.icon {
+			t.Errorf("icon at %d DPI is %d px, want %d", tt.dpi, got, tt.icon)
+		}
+		if w, h := thumbPixelSize(tl, scale); w != tt.thumbW || h != tt.thumbH {
+			t.Errorf("thumbnail at %d DPI is %dx%d px, want %dx%d", tt.dpi, w, h, tt.thumbW, tt.thumbH)
+		}
+	}
+
+	// Fractions round up, so a bitmap is never stretched on screen
+	if got := pixelSize(101, 1.25); got != 127 {
+		t.Errorf("pixelSize(101, 1.25) = %d, want 127", got)
+	}
+}
+
+// TestScaledWindowSize corrects the window size when Fyne scaled the canvas for another
+// monitor than the one the window ends up on
+func TestScaledWindowSize(t *testing.T) {
+	base := fyne.NewSize(380, 520)
+	tests := []struct {
+		name            string
+		monitor, canvas float32
+		want            fyne.Size
+	}{
+		{"same scale", 1.5, 1.5, base},
+		{"moved to a denser monitor", 1.5, 1, fyne.NewSize(570, 780)},
+		{"moved to a plainer monitor", 1, 1.25, fyne.NewSize(304, 416)},
+		{"unknown monitor", 0, 1.25, base},
+		{"unknown canvas", 1.5, 0, base},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaledWindowSize(base, tt.monitor, tt.canvas); got != tt.want {
+				t.Errorf("scaledWindowSize = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
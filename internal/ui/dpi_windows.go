@@ -0,0 +1,80 @@
+//go:build windows
+// +build windows
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+)
+
+var (
+	procGetDpiForWindow   = user32.NewProc("GetDpiForWindow")
+	procSetWindowLongPtrW = user32.NewProc("SetWindowLongPtrW")
+	procCallWindowProcW   = user32.NewProc("CallWindowProcW")
+)
+
+const (
+	wmDPIChanged = 0x02E0
+	gwlpWndProc  = ^uintptr(3) // GWLP_WNDPROC (-4)
+)
+
+// windowDPI returns the DPI of the monitor hwnd is on, or 0 when unknown
+// GetDpiForWindow needs Windows 10 1607; older systems fall back to Fyne's scale
+func windowDPI(hwnd uintptr) uint32 {
+	if hwnd == 0 || procGetDpiForWindow.Find() != nil {
+		return 0
+	}
+	dpi, _, _ := procGetDpiForWindow.Call(hwnd)
+	return uint32(dpi)
+}
+
+// dpiHook is the single subclass installed on the main window
+var dpiHook struct {
+	mu       sync.Mutex
+	oldProc  uintptr
+	callback uintptr
+	notify   func()
+}
+
+// watchWindowDPI subclasses hwnd to see WM_DPICHANGED and calls notify after each change
+// GLFW still handles the message itself; the hook only observes it
+//
+// Manual check: with monitors at 150% and 100%, open Pano on the 150% one, drag it to the
+// other and back; thumbnails stay sharp, and the tray icon is redrawn after each move
+func watchWindowDPI(hwnd uintptr, notify func()) (func(), error) {
+	dpiHook.mu.Lock()
+	defer dpiHook.mu.Unlock()
+	if dpiHook.oldProc != 0 {
+		return nil, fmt.Errorf("DPI watch already active")
+	}
+
+	// Callbacks are a limited resource, so the hook is created once per process
+	if dpiHook.callback == 0 {
+		dpiHook.callback = syscall.NewCallback(func(hwnd, message, wParam, lParam uintptr) uintptr {
+			ret, _, _ := procCallWindowProcW.Call(dpiHook.oldProc, hwnd, message, wParam, lParam)
+			if message == wmDPIChanged && dpiHook.notify != nil {
+				go dpiHook.notify()
+			}
+			return ret
+		})
+	}
+
+	old, _, err := procSetWindowLongPtrW.Call(hwnd, gwlpWndProc, dpiHook.callback)
+	if old == 0 {
+		return nil, fmt.Errorf("failed to subclass window: %v", err)
+	}
+	dpiHook.oldProc = old
+	dpiHook.notify = notify
+
+	stop := func() {
+		dpiHook.mu.Lock()
+		defer dpiHook.mu.Unlock()
+		if dpiHook.oldProc != 0 {
+			procSetWindowLongPtrW.Call(hwnd, gwlpWndProc, dpiHook.oldProc)
+			dpiHook.oldProc = 0
+		}
+	}
+	return stop, nil
+}
@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+)
+
+const (
+	draftInterval   = 2 * time.Second // How often the text of an open editor is saved as a draft
+	newItemDraftKey = "new-item"      // Draft of the "Yeni Öğe" dialog
+)
+
+// draftSaver saves the text of an open editor as a draft every draftInterval while it
+// changes, so a crash doesn't lose it; writes happen off the UI thread
+type draftSaver struct {
+	manager *clipboard.Manager
+	key     string
+	entry   *widget.Entry
+	stop    chan struct{}
+
+	mu     sync.Mutex // Held while writing, so finish can't race a save in flight
+	saved  string     // Text of the last draft written
+	held   bool       // A draft is on offer; saving would overwrite it
+	closed bool
+}
+
+// startDraft begins saving entry as the draft key; an empty key saves nothing and
+// returns nil, which every method accepts
+func (a *App) startDraft(key string, entry *widget.Entry) *draftSaver {
+	if key == "" {
+		return nil
+	}
+	s := &draftSaver{
+		manager: a.manager,
+		key:     key,
+		entry:   entry,
+		stop:    make(chan struct{}),
+		saved:   entry.Text,
+	}
+	go s.run()
+	return s
+}
+
+// run saves on every tick until finish
+func (s *draftSaver) run() {
+	ticker := time.NewTicker(draftInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.save()
+		}
+	}
+}
+
+// save writes the draft if the text changed since the last one; clearing the text
+// deletes it
+func (s *draftSaver) save() {
+	var text string
+	fyne.DoAndWait(func() {
+		text = s.entry.Text
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.held || text == s.saved {
+		return
+	}
+	var err error
+	if strings.TrimSpace(text) == "" {
+		err = s.manager.DeleteDraft(s.key)
+	} else {
+		err = s.manager.SaveDraft(s.key, text)
+	}
+	if err != nil {
+		if !errors.Is(err, storage.ErrDraftTooLarge) {
+			log.Printf("Warning: Failed to save draft: %v", err)
+		}
+		return
+	}
+	s.saved = text
+}
+
+// setHeld pauses or resumes saving while a draft is on offer
+func (s *draftSaver) setHeld(held bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held = held
+}
+
+// discard deletes the draft on offer and resumes saving
+func (s *draftSaver) discard() {
+	if s == nil {
+		return
+	}
+	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.held = false
+		s.saved = ""
+		if err := s.manager.DeleteDraft(s.key); err != nil {
+			log.Printf("Warning: Failed to delete draft: %v", err)
+		}
+	}()
+}
+
+// finish stops saving and deletes the draft; called once the dialog is saved or
+// cancelled
+func (s *draftSaver) finish() {
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.closed = true
+		if err := s.manager.DeleteDraft(s.key); err != nil {
+			log.Printf("Warning: Failed to delete draft: %v", err)
+		}
+	}()
+}
+
+// findDraft returns the saved draft of key
+func (a *App) findDraft(key string) (storage.Draft, bool) {
+	if key == "" {
+		return storage.Draft{}, false
+	}
+	drafts, err := a.manager.LoadDrafts()
+	if err != nil {
+		log.Printf("Warning: Failed to load drafts: %v", err)
+		return storage.Draft{}, false
+	}
+	for _, d := range drafts {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return storage.Draft{}, false
+}
+
+// draftBanner offers the draft left over for the editor of saver; nil without one
+// Saving waits until the user restores or discards it, so typing doesn't overwrite it
+func (a *App) draftBanner(saver *draftSaver, entry *widget.Entry) fyne.CanvasObject {
+	if saver == nil {
+		return nil
+	}
+	draft, ok := a.findDraft(saver.key)
+	if !ok {
+		return nil
+	}
+	saver.setHeld(true)
+
+	label := widget.NewLabel(fmt.Sprintf("Kaydedilmemiş bir taslak var (%s)", draft.Updated.Format("02.01.2006 15:04")))
+	label.Wrapping = fyne.TextWrapWord
+	var banner *fyne.Container
+	restoreBtn := widget.NewButton("Geri yükle", func() {
+		entry.SetText(draft.Text)
+		banner.Hide()
+		saver.setHeld(false)
+	})
+	restoreBtn.Importance = widget.HighImportance
+	discardBtn := widget.NewButton("Sil", func() {
+		banner.Hide()
+		saver.discard()
+	})
+	banner = container.NewBorder(nil, nil, nil, container.NewHBox(discardBtn, restoreBtn), label)
+	return banner
+}
+
+// offerDrafts points out drafts left by a crash, once per run (UI thread only)
+// The offer is a toast leading to the dialog, which shows the restore banner
+func (a *App) offerDrafts() {
+	if a.draftsOffered {
+		return
+	}
+	a.draftsOffered = true
+	if _, ok := a.findDraft(newItemDraftKey); !ok {
+		return
+	}
+	a.toasts.ShowWithAction("Kaydedilmemiş bir taslak bulundu", "Aç", a.showNewItemDialog)
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// showItemEditor opens a multi-line editor for writing item text by hand
+// onSave runs on the UI goroutine; an error keeps the dialog open and is shown in it.
+// While open, the text is kept as the draft draftKey (see drafts.go); "" keeps none
+func (a *App) showItemEditor(title, text string, pinned bool, draftKey string, onSave func(text string, pin bool) error) {
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("Metni buraya yazın")
+	entry.SetMinRowsVisible(8)
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetText(text)
+
+	pinCheck := widget.NewCheck("Sabitle", nil)
+	pinCheck.SetChecked(pinned)
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+	errorLabel.Hide()
+
+	drafts := a.startDraft(draftKey, entry)
+	banner := a.draftBanner(drafts, entry)
+
+	var d *dialog.CustomDialog
+	saveBtn := widget.NewButtonWithIcon("Kaydet", theme.ConfirmIcon(), func() {
+		if err := validateItemText(entry.Text); err != nil {
+			errorLabel.SetText(err.Error())
+			errorLabel.Show()
+			return
+		}
+		if err := onSave(entry.Text, pinCheck.Checked); err != nil {
+			errorLabel.SetText(err.Error())
+			errorLabel.Show()
+			return
+		}
+		drafts.finish()
+		d.Hide()
+	})
+	saveBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButtonWithIcon("İptal", theme.CancelIcon(), func() {
+		drafts.finish()
+		d.Hide()
+	})
+
+	content := container.NewBorder(banner, container.NewVBox(pinCheck, errorLabel), nil, nil, entry)
+	d = dialog.NewCustomWithoutButtons(title, content, a.window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, saveBtn})
+	d.Resize(fyne.NewSize(460, 360))
+	d.Show()
+	a.window.Canvas().Focus(entry)
+}
+
+// validateItemText checks text written in the editor before it is stored
+func validateItemText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return errors.New("Metin boş olamaz")
+	}
+	if len(text) > storage.MaxItemSize {
+		return fmt.Errorf("Metin en fazla %s olabilir", formatSize(storage.MaxItemSize))
+	}
+	return nil
+}
+
+// showEditItemDialog lets the user fix the text of an item; the edited item moves to
+// the top and older items with the same text go to the trash
+func (a *App) showEditItemDialog(item storage.ClipboardItem) {
+	content, err := a.manager.GetItemContent(item.ID)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	text := string(content)
+	storage.Zero(content)
+
+	a.showItemEditor("Düzenle", text, item.Pinned, "", func(text string, pin bool) error {
+		if err := a.manager.UpdateContent(item.ID, []byte(text)); err != nil {
+			return err
+		}
+		message := "Öğe güncellendi"
+		// The edit may have taken over the pin of a duplicate it replaced
+		if updated, ok := a.findItem(item.ID); ok && updated.Pinned != pin {
+			if err := a.manager.PinItem(item.ID); errors.Is(err, storage.ErrPinLimitReached) {
+				message = "Öğe güncellendi; sabitleme sınırı dolu olduğu için sabitlenmedi"
+			}
+		}
+		a.afterItemsChanged()
+		a.showToast(message)
+		return nil
+	})
+}
+
+// showNewItemDialog lets the user write a new item; it is stored like a manual capture
+func (a *App) showNewItemDialog() {
+	a.showItemEditor("Yeni Öğe", "", false, newItemDraftKey, func(text string, pin bool) error {
+		err := a.manager.AddText(text, pin)
+		var warning *storage.LimitWarning
+		var reject *storage.RejectError
+		switch {
+		case err == nil, errors.As(err, &warning):
+		case errors.Is(err, storage.ErrPinLimitReached):
+			return fmt.Errorf("En fazla %d öğe sabitlenebilir; sabitlemeden kaydedin veya önce birinin sabitlemesini kaldırın", a.manager.GetPinLimit())
+		case errors.As(err, &reject) && reject.Reason == storage.RejectLimitFull:
+			return fmt.Errorf("Pano limiti doldu (%d öğe); yer açın veya limiti artırın", reject.Limit)
+		default:
+			return err
+		}
+		a.afterItemsChanged()
+		a.showToast("Öğe eklendi")
+		return nil
+	})
+}
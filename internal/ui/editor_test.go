@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"pano/internal/storage"
+)
+
+func TestValidateItemText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		valid bool
+	}{
+		{"text", "Elle yazılan not", true},
+		{"empty", "", false},
+		{"only blanks", " \n\t ", false},
+		{"at the size cap", strings.Repeat("a", storage.MaxItemSize), true},
+		{"over the size cap", strings.Repeat("a", storage.MaxItemSize+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateItemText(tt.text); (err == nil) != tt.valid {
+				t.Errorf("validateItemText gave %v", err)
+			}
+		})
+	}
+}
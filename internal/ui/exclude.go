@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/clipboard"
+)
+
+// buildExclusionSettings returns the list of programs whose copies are never stored
+func (a *App) buildExclusionSettings(bind func(key string, fn func())) fyne.CanvasObject {
+	label := widget.NewLabelWithStyle("Kaydedilmeyen uygulamalar", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	hint := widget.NewLabel("Bu programlardan kopyalananlar geçmişe hiç yazılmaz (yalnızca Windows). Program adlarını virgülle ayırın.")
+	hint.Wrapping = fyne.TextWrapWord
+	hint.Importance = widget.LowImportance
+
+	current := func() string {
+		return a.settings.StringWithFallback("capture_excluded_apps", strings.Join(a.config.CaptureExcludedApps, ", "))
+	}
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("keepass.exe, banka.exe")
+	entry.SetMinRowsVisible(2)
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetText(current())
+	entry.OnChanged = func(text string) {
+		a.settings.SetString("capture_excluded_apps", strings.TrimSpace(text))
+	}
+	bind("capture_excluded_apps", func() {
+		// Typing in the entry lands here too; only outside changes are applied
+		if text := current(); text != strings.TrimSpace(entry.Text) {
+			entry.SetText(text)
+		}
+	})
+
+	resetBtn := widget.NewButton("Varsayılanlara dön", func() {
+		a.settings.SetString("capture_excluded_apps", strings.Join(clipboard.DefaultExcludedApps, ", "))
+	})
+
+	return container.NewVBox(label, hint, entry, container.NewHBox(resetBtn))
+}
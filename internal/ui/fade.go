@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+)
+
+// Fyne can't animate window opacity, so the window fades by fading a rectangle in the
+// background color over the content. The rectangle isn't tappable and the fade-in
+// doesn't hold back focusDelay, so the window takes keyboard input as soon as it would
+// without the animation.
+
+const (
+	fadeInDuration  = 120 * time.Millisecond // Show: content appears from the background color
+	fadeOutDuration = 90 * time.Millisecond  // Hide: the window is hidden once covered
+)
+
+// windowFader runs the show and hide fades of the main window
+// Its methods may be called from any goroutine; the work runs on the UI thread
+type windowFader struct {
+	overlay *canvas.Rectangle
+	anim    *fyne.Animation
+	gen     int // Bumped by every fade so a stopped fade-out never hides the window
+}
+
+// newWindowFader creates the hidden overlay
+func newWindowFader() *windowFader {
+	overlay := canvas.NewRectangle(color.Transparent)
+	overlay.Hide()
+	return &windowFader{overlay: overlay}
+}
+
+// Overlay returns the rectangle to stack over the window content
+func (f *windowFader) Overlay() fyne.CanvasObject {
+	return f.overlay
+}
+
+// FadeIn uncovers the content of a window that was just shown
+func (f *windowFader) FadeIn() {
+	fyne.Do(func() {
+		f.run(255, 0, fadeInDuration, nil)
+	})
+}
+
+// FadeOut covers the content and calls hide when it is fully covered
+// A FadeIn or Cancel before then stops the fade and hide is never called
+func (f *windowFader) FadeOut(hide func()) {
+	fyne.Do(func() {
+		f.run(0, 255, fadeOutDuration, hide)
+	})
+}
+
+// Cancel stops a running fade and removes the overlay
+func (f *windowFader) Cancel() {
+	fyne.Do(func() {
+		f.stop()
+		f.overlay.Hide()
+	})
+}
+
+// stop ends the running fade; must be called on the UI thread
+func (f *windowFader) stop() {
+	f.gen++
+	if f.anim != nil {
+		f.anim.Stop()
+		f.anim = nil
+	}
+}
+
+// run animates the overlay alpha from one value to another; must be called on the UI thread
+func (f *windowFader) run(from, to uint8, d time.Duration, done func()) {
+	f.stop()
+	gen := f.gen
+
+	r, g, b, _ := theme.Color(theme.ColorNameBackground).RGBA()
+	f.overlay.FillColor = color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: from}
+	f.overlay.Show()
+	f.overlay.Refresh()
+
+	f.anim = fyne.NewAnimation(d, func(progress float32) {
+		if gen != f.gen {
+			return
+		}
+		fill := f.overlay.FillColor.(color.NRGBA)
+		fill.A = uint8(float32(from) + (float32(to)-float32(from))*progress)
+		f.overlay.FillColor = fill
+		f.overlay.Refresh()
+		if progress < 1 {
+			return
+		}
+		f.anim = nil
+		if to == 0 {
+			f.overlay.Hide()
+		}
+		if done != nil {
+			done()
+		}
+	})
+	f.anim.Curve = fyne.AnimationEaseOut
+	f.anim.Start()
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"image/color"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// The test driver finishes animations at once, so each fade is checked at its end
+func TestWindowFader(t *testing.T) {
+	test.NewTempApp(t)
+	fader := newWindowFader()
+	overlay := fader.overlay
+	if overlay.Visible() {
+		t.Fatal("the overlay covers the window before any fade")
+	}
+
+	hidden := 0
+	fader.FadeOut(func() { hidden++ })
+	if hidden != 1 {
+		t.Errorf("hide called %d times after the fade-out, want once", hidden)
+	}
+	if !overlay.Visible() || overlay.FillColor.(color.NRGBA).A != 255 {
+		t.Errorf("window not covered after the fade-out: visible %v, fill %v", overlay.Visible(), overlay.FillColor)
+	}
+
+	fader.FadeIn()
+	if overlay.Visible() {
+		t.Error("the overlay still covers the window after the fade-in")
+	}
+	if hidden != 1 {
+		t.Error("the fade-in hid the window")
+	}
+
+	fader.FadeOut(nil)
+	fader.Cancel()
+	if overlay.Visible() {
+		t.Error("the overlay still covers the window after Cancel")
+	}
+}
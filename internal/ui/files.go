@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+)
+
+// filesCardNames is how many file names a files card lists; the rest are counted
+const filesCardNames = 4
+
+// filesSummary names the number of files, e.g. "3 dosya"
+func filesSummary(count int) string {
+	return fmt.Sprintf("%d dosya", count)
+}
+
+// createFilesPreview lists the first file names of a files item under their count
+// The compact style puts them on one truncated line
+func createFilesPreview(preview storage.Preview, compact bool) fyne.CanvasObject {
+	if preview.FileCount == 0 {
+		return widget.NewLabel("Dosyalar okunamadı")
+	}
+	summary := filesSummary(preview.FileCount)
+	if compact {
+		label := widget.NewLabel(summary + ": " + strings.Join(preview.Files, ", "))
+		label.Truncation = fyne.TextTruncateEllipsis
+		return label
+	}
+
+	box := container.NewVBox(widget.NewLabelWithStyle(summary, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	for _, name := range preview.Files[:min(len(preview.Files), filesCardNames)] {
+		label := widget.NewLabel(name)
+		label.Truncation = fyne.TextTruncateEllipsis
+		box.Add(container.NewBorder(nil, nil, widget.NewIcon(theme.FileIcon()), nil, label))
+	}
+	if rest := preview.FileCount - filesCardNames; rest > 0 {
+		more := widget.NewLabel(fmt.Sprintf("ve %d dosya daha", rest))
+		more.Importance = widget.LowImportance
+		box.Add(more)
+	}
+	return box
+}
+
+// createFileList shows the full paths of a files item for the details dialog
+func (a *App) createFileList(id string) fyne.CanvasObject {
+	data, err := a.manager.GetItemContent(id)
+	if err != nil {
+		return widget.NewLabel("Dosyalar okunamadı")
+	}
+	paths, err := storage.DecodeFiles(data)
+	storage.Zero(data)
+	if err != nil {
+		return widget.NewLabel("Dosyalar okunamadı")
+	}
+	list := widget.NewLabel(strings.Join(paths, "\n"))
+	list.Wrapping = fyne.TextWrapBreak
+	return container.NewVBox(
+		widget.NewLabelWithStyle(filesSummary(len(paths)), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		list,
+	)
+}
+
+// showMissingFiles tells which files of a copied files item no longer exist
+func (a *App) showMissingFiles(missing *clipboard.MissingFilesError) {
+	message := "Bu dosyalar taşınmış ya da silinmiş, kopyalanamadı:"
+	if len(missing.Paths) == 1 {
+		message = "Bu dosya taşınmış ya da silinmiş, kopyalanamadı:"
+	}
+	list := widget.NewLabel(strings.Join(missing.Paths, "\n"))
+	list.Wrapping = fyne.TextWrapBreak
+	content := container.NewVBox(widget.NewLabel(message), list)
+	if len(missing.Paths) < missing.Total {
+		content.Add(widget.NewLabel(fmt.Sprintf("Öğedeki %d dosyadan %d tanesi eksik.", missing.Total, len(missing.Paths))))
+	}
+	dialog.ShowCustom("Dosyalar bulunamadı", "Kapat", content, a.window)
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pano/internal/storage"
+)
+
+// itemPredicate decides whether an item passes a filter at the given time
+type itemPredicate func(item storage.ClipboardItem, now time.Time) bool
+
+// largeItemSize is the threshold for the "Büyük" chip
+const largeItemSize = 1024 * 1024
+
+// filterChip is a toggleable quick filter shown above the list
+type filterChip struct {
+	label     string
+	predicate itemPredicate
+	count     func(storage.Counts) int // Items the chip matches, shown on it; nil for none
+}
+
+// filterChips are the quick filters in display order
+var filterChips = []filterChip{
+	{"Bugün", isToday, nil},
+	{"Bu Hafta", isThisWeek, nil},
+	{"Görseller", isImage, func(c storage.Counts) int { return c.Images }},
+	{"Metin", isText, func(c storage.Counts) int { return c.Text }},
+	{"Sabitler", isPinned, func(c storage.Counts) int { return c.Pinned }},
+	{"Büyük", isLarge, nil},
+}
+
+// chipText returns the button text of a chip, with its item count if it has one
+func chipText(chip filterChip, counts storage.Counts) string {
+	if chip.count == nil {
+		return chip.label
+	}
+	return fmt.Sprintf("%s (%d)", chip.label, chip.count(counts))
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func isToday(item storage.ClipboardItem, now time.Time) bool {
+	return !item.Timestamp.Before(startOfDay(now))
+}
+
+// isThisWeek matches items since Monday of the current week
+func isThisWeek(item storage.ClipboardItem, now time.Time) bool {
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	return !item.Timestamp.Before(startOfDay(now).AddDate(0, 0, -daysSinceMonday))
+}
+
+func isImage(item storage.ClipboardItem, now time.Time) bool {
+	return item.Type == "image"
+}
+
+func isText(item storage.ClipboardItem, now time.Time) bool {
+	return item.Type == "text"
+}
+
+func isPinned(item storage.ClipboardItem, now time.Time) bool {
+	return item.Pinned
+}
+
+func isLarge(item storage.ClipboardItem, now time.Time) bool {
+	return item.Size > largeItemSize
+}
+
+// allOf combines predicates with AND; no predicates matches everything
+func allOf(predicates ...itemPredicate) itemPredicate {
+	return func(item storage.ClipboardItem, now time.Time) bool {
+		for _, p := range predicates {
+			if !p(item, now) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// activeChipFilter builds the combined predicate for the active chip labels
+func activeChipFilter(active map[string]bool) itemPredicate {
+	predicates := make([]itemPredicate, 0, len(active))
+	for _, chip := range filterChips {
+		if active[chip.label] {
+			predicates = append(predicates, chip.predicate)
+		}
+	}
+	return allOf(predicates...)
+}
+
+// formatActiveChips lists the active chip labels in display order, comma separated
+func formatActiveChips(active map[string]bool) string {
+	labels := make([]string, 0, len(active))
+	for _, chip := range filterChips {
+		if active[chip.label] {
+			labels = append(labels, chip.label)
+		}
+	}
+	return strings.Join(labels, ",")
+}
+
+// parseActiveChips reads a list written by formatActiveChips; unknown labels are dropped
+func parseActiveChips(text string) map[string]bool {
+	active := make(map[string]bool)
+	for _, label := range strings.Split(text, ",") {
+		for _, chip := range filterChips {
+			if chip.label == strings.TrimSpace(label) {
+				active[chip.label] = true
+			}
+		}
+	}
+	return active
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"pano/internal/storage"
+)
+
+// TestDateChips checks "Bugün" and "Bu Hafta" at the edges of the day and of a week
+// that starts on Monday
+func TestDateChips(t *testing.T) {
+	// Wednesday 5 March 2025, in the afternoon
+	now := time.Date(2025, time.March, 5, 15, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		at       time.Time
+		today    bool
+		thisWeek bool
+	}{
+		{"now", now, true, true},
+		{"midnight", time.Date(2025, time.March, 5, 0, 0, 0, 0, time.Local), true, true},
+		{"just before midnight", time.Date(2025, time.March, 4, 23, 59, 59, 0, time.Local), false, true},
+		{"monday", time.Date(2025, time.March, 3, 0, 0, 0, 0, time.Local), false, true},
+		{"sunday before", time.Date(2025, time.March, 2, 23, 59, 0, 0, time.Local), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := storage.ClipboardItem{Timestamp: tt.at}
+			if got := isToday(item, now); got != tt.today {
+				t.Errorf("isToday = %v, want %v", got, tt.today)
+			}
+			if got := isThisWeek(item, now); got != tt.thisWeek {
+				t.Errorf("isThisWeek = %v, want %v", got, tt.thisWeek)
+			}
+		})
+	}
+
+	// On a Sunday the week still began six days earlier
+	sunday := time.Date(2025, time.March, 9, 12, 0, 0, 0, time.Local)
+	if !isThisWeek(storage.ClipboardItem{Timestamp: time.Date(2025, time.March, 3, 8, 0, 0, 0, time.Local)}, sunday) {
+		t.Error("Monday's item isn't in the week on Sunday")
+	}
+}
+
+// TestActiveChipFilter checks that active chips combine with AND and that none match
+// everything
+func TestActiveChipFilter(t *testing.T) {
+	now := time.Date(2025, time.March, 5, 15, 0, 0, 0, time.Local)
+	items := map[string]storage.ClipboardItem{
+		"pinned image":    {Type: "image", Pinned: true, Timestamp: now, Size: 2 * largeItemSize},
+		"old pinned text": {Type: "text", Pinned: true, Timestamp: now.AddDate(0, 0, -10)},
+		"text today":      {Type: "text", Timestamp: now, Size: 10},
+	}
+	tests := []struct {
+		name   string
+		active map[string]bool
+		want   []string
+	}{
+		{"none", nil, []string{"pinned image", "old pinned text", "text today"}},
+		{"pinned", map[string]bool{"Sabitler": true}, []string{"pinned image", "old pinned text"}},
+		{"pinned today", map[string]bool{"Sabitler": true, "Bugün": true}, []string{"pinned image"}},
+		{"large images", map[string]bool{"Görseller": true, "Büyük": true}, []string{"pinned image"}},
+		{"images and text", map[string]bool{"Görseller": true, "Metin": true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := activeChipFilter(tt.active)
+			want := map[string]bool{}
+			for _, name := range tt.want {
+				want[name] = true
+			}
+			for name, item := range items {
+				if got := filter(item, now); got != want[name] {
+					t.Errorf("%s: matched %v, want %v", name, got, want[name])
+				}
+			}
+		})
+	}
+}
+
+// Chips with a count show it from the same Counts as the status bar
+func TestChipText(t *testing.T) {
+	counts := storage.Counts{Images: 3, Text: 12, Pinned: 2}
+	want := []string{"Bugün", "Bu Hafta", "Görseller (3)", "Metin (12)", "Sabitler (2)", "Büyük"}
+	for i, chip := range filterChips {
+		if got := chipText(chip, counts); got != want[i] {
+			t.Errorf("chip %d reads %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+// Active chips are saved in display order and read back without unknown or stale labels
+func TestActiveChipsSetting(t *testing.T) {
+	active := map[string]bool{"Sabitler": true, "Bugün": true, "Metin": false}
+	saved := formatActiveChips(active)
+	if saved != "Bugün,Sabitler" {
+		t.Errorf("formatActiveChips = %q, want %q", saved, "Bugün,Sabitler")
+	}
+	if got := parseActiveChips(saved); len(got) != 2 || !got["Bugün"] || !got["Sabitler"] {
+		t.Errorf("parseActiveChips(%q) = %v", saved, got)
+	}
+	if got := parseActiveChips(" Görseller , Eski Çip,,Büyük"); len(got) != 2 || !got["Görseller"] || !got["Büyük"] {
+		t.Errorf("parseActiveChips kept %v", got)
+	}
+	if got := formatActiveChips(parseActiveChips("")); got != "" {
+		t.Errorf("no active chips saved as %q", got)
+	}
+}
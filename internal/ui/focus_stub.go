@@ -3,7 +3,19 @@
 
 package ui
 
-// BringWindowToFront is a no-op on non-Windows platforms
-func BringWindowToFront(windowTitle string) {
+import "fyne.io/fyne/v2"
+
+// windowHandle is unavailable on non-Windows platforms
+func windowHandle(fyne.Window) uintptr {
+	return 0
+}
+
+// mainWindowHandle is unavailable on non-Windows platforms
+func (a *App) mainWindowHandle() uintptr {
+	return 0
+}
+
+// bringWindowToFront is a no-op on non-Windows platforms
+func bringWindowToFront(uintptr) {
 	// No-op on non-Windows
 }
@@ -4,20 +4,26 @@
 package ui
 
 import (
+	"os"
+	"sync"
 	"syscall"
 	"unsafe"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
 )
 
 var (
-	user32                  = syscall.NewLazyDLL("user32.dll")
-	procSetForegroundWindow = user32.NewProc("SetForegroundWindow")
-	procGetForegroundWindow = user32.NewProc("GetForegroundWindow")
-	procShowWindow          = user32.NewProc("ShowWindow")
-	procFindWindowW         = user32.NewProc("FindWindowW")
+	user32                       = syscall.NewLazyDLL("user32.dll")
+	procSetForegroundWindow      = user32.NewProc("SetForegroundWindow")
+	procGetForegroundWindow      = user32.NewProc("GetForegroundWindow")
+	procShowWindow               = user32.NewProc("ShowWindow")
+	procEnumWindows              = user32.NewProc("EnumWindows")
+	procGetClassNameW            = user32.NewProc("GetClassNameW")
 	procGetWindowThreadProcessId = user32.NewProc("GetWindowThreadProcessId")
-	procAttachThreadInput   = user32.NewProc("AttachThreadInput")
-	kernel32                = syscall.NewLazyDLL("kernel32.dll")
-	procGetCurrentThreadId  = kernel32.NewProc("GetCurrentThreadId")
+	procAttachThreadInput        = user32.NewProc("AttachThreadInput")
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procGetCurrentThreadId       = kernel32.NewProc("GetCurrentThreadId")
 )
 
 const (
@@ -25,35 +31,126 @@ const (
 	SW_RESTORE = 9
 )
 
-// BringWindowToFront forcefully brings a window to the foreground on Windows
-func BringWindowToFront(windowTitle string) {
-	// Convert window title to UTF16
-	titlePtr, _ := syscall.UTF16PtrFromString(windowTitle)
-	
-	// Find window by title
-	hwnd, _, _ := procFindWindowW.Call(0, uintptr(unsafe.Pointer(titlePtr)))
+// windowInfo describes a top-level window seen during enumeration
+type windowInfo struct {
+	hwnd      uintptr
+	processID uint32
+}
+
+// windowEnumerator lists top-level windows; replaced by a fake in tests
+type windowEnumerator func() []windowInfo
+
+var (
+	enumerateWindows windowEnumerator = enumerateTopLevelWindows
+	// lookupWindowHandle reads a window's HWND from Fyne; replaced by a fake in tests
+	lookupWindowHandle = nativeWindowHandle
+
+	handleMu    sync.Mutex
+	handleCache = make(map[fyne.Window]uintptr) // HWNDs looked up so far, by window
+
+	// enumMu serializes enumerations, which share enumCallback and collect into enumFound
+	enumMu    sync.Mutex
+	enumFound []windowInfo
+	// One callback for every enumeration: Go never frees the slots NewCallback takes
+	enumCallback = syscall.NewCallback(func(hwnd uintptr, _ uintptr) uintptr {
+		var pid uint32
+		procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+		enumFound = append(enumFound, windowInfo{hwnd: hwnd, processID: pid})
+		return 1 // Continue enumeration
+	})
+)
+
+// enumerateTopLevelWindows lists all top-level windows via EnumWindows
+func enumerateTopLevelWindows() []windowInfo {
+	enumMu.Lock()
+	defer enumMu.Unlock()
+
+	enumFound = nil
+	procEnumWindows.Call(enumCallback, 0)
+	windows := enumFound
+	enumFound = nil
+	return windows
+}
+
+// nativeWindowHandle asks Fyne for w's HWND, 0 before its native window exists
+func nativeWindowHandle(w fyne.Window) uintptr {
+	native, ok := w.(driver.NativeWindow)
+	if !ok {
+		return 0
+	}
+	var hwnd uintptr
+	native.RunNative(func(context any) {
+		if ctx, ok := context.(driver.WindowsWindowContext); ok {
+			hwnd = ctx.HWND
+		}
+	})
+	return hwnd
+}
+
+// isOwnWindow reports whether hwnd is among this process's top-level windows
+func isOwnWindow(windows []windowInfo, pid uint32, hwnd uintptr) bool {
+	for _, w := range windows {
+		if w.hwnd == hwnd {
+			return w.processID == pid
+		}
+	}
+	return false
+}
+
+// windowHandle returns the HWND of w, or 0 before its native window exists (UI thread only)
+// Handles are asked from Fyne once and cached; one whose native window was destroyed is
+// no longer among this process's windows and is dropped, for every window at once
+func windowHandle(w fyne.Window) uintptr {
+	handleMu.Lock()
+	defer handleMu.Unlock()
+
+	if len(handleCache) > 0 {
+		windows := enumerateWindows()
+		pid := uint32(os.Getpid())
+		for cached, hwnd := range handleCache {
+			if !isOwnWindow(windows, pid, hwnd) {
+				delete(handleCache, cached)
+			}
+		}
+	}
+	if hwnd, ok := handleCache[w]; ok {
+		return hwnd
+	}
+	hwnd := lookupWindowHandle(w)
+	if hwnd != 0 {
+		handleCache[w] = hwnd
+	}
+	return hwnd
+}
+
+// mainWindowHandle returns the HWND of the Pano window, 0 before it was first shown
+func (a *App) mainWindowHandle() uintptr {
+	return windowHandle(a.window)
+}
+
+// bringWindowToFront forcefully brings the window hwnd to the foreground
+func bringWindowToFront(hwnd uintptr) {
 	if hwnd == 0 {
 		return
 	}
 
 	// Get foreground window
 	foregroundHwnd, _, _ := procGetForegroundWindow.Call()
-	
+
 	// Get thread IDs
-	var foregroundThreadId uint32
-	procGetWindowThreadProcessId.Call(foregroundHwnd, uintptr(unsafe.Pointer(&foregroundThreadId)))
-	
+	foregroundThreadId, _, _ := procGetWindowThreadProcessId.Call(foregroundHwnd, 0)
+
 	currentThreadId, _, _ := procGetCurrentThreadId.Call()
-	
+
 	// Attach input threads to allow SetForegroundWindow
-	if foregroundThreadId != uint32(currentThreadId) {
-		procAttachThreadInput.Call(currentThreadId, uintptr(foregroundThreadId), 1)
-		defer procAttachThreadInput.Call(currentThreadId, uintptr(foregroundThreadId), 0)
+	if foregroundThreadId != currentThreadId {
+		procAttachThreadInput.Call(currentThreadId, foregroundThreadId, 1)
+		defer procAttachThreadInput.Call(currentThreadId, foregroundThreadId, 0)
 	}
-	
+
 	// Show and restore window if minimized
 	procShowWindow.Call(hwnd, SW_RESTORE)
-	
+
 	// Bring to foreground
 	procSetForegroundWindow.Call(hwnd)
 }
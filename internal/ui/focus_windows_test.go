@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"os"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+)
+
+// fakeWindows stands in for EnumWindows and Fyne's native window hook
+type fakeWindows struct {
+	listed  []windowInfo
+	handles map[fyne.Window]uintptr // What Fyne reports for each window
+	lookups int
+}
+
+// install replaces the enumerator and the lookup until the test ends
+func (f *fakeWindows) install(t *testing.T) {
+	t.Helper()
+	enumerate, lookup := enumerateWindows, lookupWindowHandle
+	enumerateWindows = func() []windowInfo { return f.listed }
+	lookupWindowHandle = func(w fyne.Window) uintptr {
+		f.lookups++
+		return f.handles[w]
+	}
+	handleCache = make(map[fyne.Window]uintptr)
+	t.Cleanup(func() {
+		enumerateWindows, lookupWindowHandle = enumerate, lookup
+		handleCache = make(map[fyne.Window]uintptr)
+	})
+}
+
+// TestWindowHandle checks that each window gets its own HWND whatever else the process
+// shows, that it is asked from Fyne only once, and that a destroyed window's handle is
+// dropped rather than reused
+func TestWindowHandle(t *testing.T) {
+	test.NewTempApp(t)
+	pid := uint32(os.Getpid())
+	main, unlock, settings := test.NewTempWindow(t, nil), test.NewTempWindow(t, nil), test.NewTempWindow(t, nil)
+
+	fake := &fakeWindows{
+		// Pano's other windows come first, as they would while they are open
+		listed: []windowInfo{
+			{hwnd: 0x10, processID: pid},     // Unlock window
+			{hwnd: 0x20, processID: pid},     // Settings window
+			{hwnd: 0x30, processID: pid + 1}, // Another Fyne app
+			{hwnd: 0x40, processID: pid},     // Main window
+		},
+		handles: map[fyne.Window]uintptr{unlock: 0x10, settings: 0x20, main: 0x40},
+	}
+	fake.install(t)
+
+	if got := windowHandle(main); got != 0x40 {
+		t.Errorf("main window handle is %#x, want 0x40", got)
+	}
+	if got := windowHandle(settings); got != 0x20 {
+		t.Errorf("settings window handle is %#x, want 0x20", got)
+	}
+	windowHandle(main)
+	if fake.lookups != 2 {
+		t.Errorf("%d lookups for two windows, want them cached", fake.lookups)
+	}
+
+	// The unlock window is destroyed; Fyne reports nothing for a window it never showed
+	fake.listed = fake.listed[1:]
+	fake.handles[unlock] = 0
+	if got := windowHandle(unlock); got != 0 {
+		t.Errorf("destroyed unlock window still has handle %#x", got)
+	}
+	if got := windowHandle(main); got != 0x40 {
+		t.Errorf("main window handle is %#x after another window closed, want 0x40", got)
+	}
+
+	// The main window is recreated with a new handle; its old one now belongs to another
+	// process, as Windows may reuse it
+	fake.listed = []windowInfo{
+		{hwnd: 0x40, processID: pid + 1},
+		{hwnd: 0x50, processID: pid},
+	}
+	fake.handles[main] = 0x50
+	if got := windowHandle(main); got != 0x50 {
+		t.Errorf("recreated main window handle is %#x, want 0x50", got)
+	}
+	if _, ok := handleCache[settings]; ok {
+		t.Error("the destroyed settings window is still cached")
+	}
+}
+
+// TestEnumerateTopLevelWindows runs the real enumeration more often than a process can
+// create callbacks, which works only because they all share one
+func TestEnumerateTopLevelWindows(t *testing.T) {
+	for range 2048 {
+		enumerateTopLevelWindows()
+	}
+	if len(enumerateTopLevelWindows()) == 0 {
+		t.Error("no top-level windows listed")
+	}
+}
@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// Cards of unpinned items fade with age: past aging_days their content is dimmed and a
+// thin strip marks the left edge, past stale_days they also get a "BAYAT" badge. When
+// stale items make up more than stalePromptShare of the unpinned history, opening the
+// window offers to delete them, at most once per stalePromptInterval.
+
+const (
+	defaultAgingDays = 7
+	defaultStaleDays = 30
+
+	stalePromptShare    = 0.4                // Share of stale unpinned items that triggers the offer
+	stalePromptInterval = 7 * 24 * time.Hour // Least time between two offers
+	staleStripWidth     = 3
+)
+
+// freshness is how old an item looks on its card
+type freshness int
+
+const (
+	freshnessFresh freshness = iota
+	freshnessAging           // Dimmed, with a strip on the left edge
+	freshnessStale           // Also badged "BAYAT"
+)
+
+// agingOptions and staleOptions are the choices for the two thresholds, in days
+var (
+	agingOptions = []int{3, 7, 14}
+	staleOptions = []int{14, 30, 60, 90}
+)
+
+// itemFreshness returns how aged item looks at now; pinned items never age
+// A zero threshold turns its stage off
+func itemFreshness(item storage.ClipboardItem, now time.Time, agingAfter, staleAfter time.Duration) freshness {
+	if item.Pinned {
+		return freshnessFresh
+	}
+	age := now.Sub(item.Timestamp)
+	switch {
+	case staleAfter > 0 && age > staleAfter:
+		return freshnessStale
+	case agingAfter > 0 && age > agingAfter:
+		return freshnessAging
+	default:
+		return freshnessFresh
+	}
+}
+
+// countStale returns how many unpinned items are stale at now, and how many are unpinned
+func countStale(items []storage.ClipboardItem, now time.Time, staleAfter time.Duration) (stale, unpinned int) {
+	for _, item := range items {
+		if item.Pinned {
+			continue
+		}
+		unpinned++
+		if itemFreshness(item, now, 0, staleAfter) == freshnessStale {
+			stale++
+		}
+	}
+	return stale, unpinned
+}
+
+// shouldOfferCleanup reports whether the stale share calls for the cleanup offer, given
+// when it was last made
+func shouldOfferCleanup(stale, unpinned int, lastOffer, now time.Time) bool {
+	if unpinned == 0 || float64(stale)/float64(unpinned) <= stalePromptShare {
+		return false
+	}
+	return now.Sub(lastOffer) >= stalePromptInterval
+}
+
+// daysDuration converts a day count from the preferences
+func daysDuration(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// applyFreshness passes the thresholds from the preferences to the list
+func (a *App) applyFreshness() {
+	a.list.SetFreshness(
+		daysDuration(a.settings.IntWithFallback("aging_days", defaultAgingDays)),
+		daysDuration(a.settings.IntWithFallback("stale_days", defaultStaleDays)),
+	)
+}
+
+// offerStaleCleanup suggests deleting stale items if they have piled up (UI thread only)
+// The offer is a toast, so ignoring it dismisses it until next week
+func (a *App) offerStaleCleanup() {
+	if !a.settings.BoolWithFallback("stale_prompt", true) {
+		return
+	}
+	now := time.Now()
+	staleDays := a.settings.IntWithFallback("stale_days", defaultStaleDays)
+	stale, unpinned := countStale(a.manager.GetAllItems(), now, daysDuration(staleDays))
+	lastOffer := time.Unix(int64(a.settings.IntWithFallback("stale_prompt_last", 0)), 0)
+	if !shouldOfferCleanup(stale, unpinned, lastOffer, now) {
+		return
+	}
+
+	a.settings.SetInt("stale_prompt_last", int(now.Unix()))
+	a.toasts.ShowWithAction(fmt.Sprintf("%d öğe %d günden eski", stale, staleDays), "Temizle", func() {
+		a.deleteStale(daysDuration(staleDays))
+	})
+}
+
+// deleteStale deletes unpinned items older than age, with an undo toast
+func (a *App) deleteStale(age time.Duration) {
+	removed, err := a.manager.DeleteOlderThan(age)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	a.afterItemsChanged()
+	a.toasts.ShowWithAction(fmt.Sprintf("%d eski öğe silindi", len(removed)), "Geri Al", func() {
+		a.undoDelete(removed)
+	})
+}
+
+// applyFreshness dims an aging card's content with veil and shows strip on its left
+// edge; fresh cards hide both
+func applyFreshness(veil, strip *canvas.Rectangle, fresh freshness) {
+	veil.FillColor = withAlpha(GetCardBackgroundColor(false), 0x70)
+	strip.FillColor = GetSecondaryTextColor()
+	setVisible(veil, fresh != freshnessFresh)
+	setVisible(strip, fresh != freshnessFresh)
+}
+
+// withAlpha returns c with its opacity replaced
+func withAlpha(c color.Color, alpha uint8) color.Color {
+	r, g, b, _ := c.RGBA()
+	return color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: alpha}
+}
+
+// newStaleBadge creates the badge shown on stale items
+func newStaleBadge() *Badge {
+	return NewBadge("BAYAT", badgeStale)
+}
+
+// buildFreshnessSettings returns the age thresholds and the cleanup offer switch
+func (a *App) buildFreshnessSettings(bind func(key string, fn func())) fyne.CanvasObject {
+	daySelect := func(key string, options []int, fallback int) *widget.Select {
+		labels := make([]string, 0, len(options))
+		for _, days := range options {
+			labels = append(labels, fmt.Sprintf("%d gün", days))
+		}
+		sel := widget.NewSelect(labels, func(selected string) {
+			for i, label := range labels {
+				if label == selected {
+					a.settings.SetInt(key, options[i])
+				}
+			}
+		})
+		syncSelect := func() {
+			current := a.settings.IntWithFallback(key, fallback)
+			for i, days := range options {
+				if days == current {
+					sel.SetSelected(labels[i])
+				}
+			}
+		}
+		syncSelect()
+		bind(key, syncSelect)
+		return sel
+	}
+
+	promptCheck := widget.NewCheck("Bayat öğeler birikince temizlemeyi öner", func(checked bool) {
+		a.settings.SetBool("stale_prompt", checked)
+	})
+	promptCheck.Checked = a.settings.BoolWithFallback("stale_prompt", true)
+	bind("stale_prompt", func() {
+		promptCheck.SetChecked(a.settings.BoolWithFallback("stale_prompt", true))
+	})
+
+	return container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Soluk göster"), nil, daySelect("aging_days", agingOptions, defaultAgingDays)),
+		container.NewBorder(nil, nil, widget.NewLabel("Bayat say"), nil, daySelect("stale_days", staleOptions, defaultStaleDays)),
+		promptCheck,
+	)
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"pano/internal/storage"
+)
+
+func TestItemFreshness(t *testing.T) {
+	now := time.Date(2024, 3, 11, 10, 0, 0, 0, time.UTC)
+	aging, stale := daysDuration(7), daysDuration(30)
+	aged := func(days int) storage.ClipboardItem {
+		return storage.ClipboardItem{Timestamp: now.Add(-daysDuration(days))}
+	}
+	tests := []struct {
+		name       string
+		item       storage.ClipboardItem
+		agingAfter time.Duration
+		staleAfter time.Duration
+		want       freshness
+	}{
+		{"new", aged(1), aging, stale, freshnessFresh},
+		{"on the aging day", aged(7), aging, stale, freshnessFresh},
+		{"aging", aged(8), aging, stale, freshnessAging},
+		{"stale", aged(31), aging, stale, freshnessStale},
+		{"pinned", storage.ClipboardItem{Timestamp: now.Add(-daysDuration(90)), Pinned: true}, aging, stale, freshnessFresh},
+		{"aging off", aged(8), 0, stale, freshnessFresh},
+		{"stale off", aged(31), aging, 0, freshnessAging},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := itemFreshness(tt.item, now, tt.agingAfter, tt.staleAfter); got != tt.want {
+				t.Errorf("freshness is %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// The offer needs more than stalePromptShare of the unpinned items stale, and comes at
+// most once a week
+func TestStaleCleanupOffer(t *testing.T) {
+	now := time.Date(2024, 3, 11, 10, 0, 0, 0, time.UTC)
+	items := []storage.ClipboardItem{
+		{Timestamp: now.Add(-daysDuration(40))},
+		{Timestamp: now.Add(-daysDuration(35))},
+		{Timestamp: now.Add(-daysDuration(1))},
+		{Timestamp: now.Add(-daysDuration(90)), Pinned: true},
+	}
+	stale, unpinned := countStale(items, now, daysDuration(30))
+	if stale != 2 || unpinned != 3 {
+		t.Fatalf("counted %d stale of %d unpinned, want 2 of 3", stale, unpinned)
+	}
+
+	never := time.Time{}
+	tests := []struct {
+		name            string
+		stale, unpinned int
+		lastOffer       time.Time
+		want            bool
+	}{
+		{"mostly stale", 2, 3, never, true},
+		{"at the share", 2, 5, never, false},
+		{"no unpinned items", 0, 0, never, false},
+		{"offered this week", 2, 3, now.Add(-daysDuration(6)), false},
+		{"offered a week ago", 2, 3, now.Add(-stalePromptInterval), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldOfferCleanup(tt.stale, tt.unpinned, tt.lastOffer, now); got != tt.want {
+				t.Errorf("shouldOfferCleanup = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
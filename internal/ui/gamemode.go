@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"log"
+)
+
+// SetGameMode is called by the game mode watcher when a full-screen application starts
+// or exits; safe from any goroutine
+// While active polling slows down, notifications are held back and, if enabled, the
+// keyboard hook is removed so it adds no input latency
+func (a *App) SetGameMode(active bool) {
+	if active && !a.settings.BoolWithFallback("game_mode", true) {
+		return
+	}
+
+	a.gameMu.Lock()
+	if active == a.gameMode {
+		a.gameMu.Unlock()
+		return
+	}
+	a.gameMode = active
+	stopHook := a.hotkeys != nil && (a.hookStopped || a.settings.BoolWithFallback("game_mode_disable_hotkeys", false))
+	a.gameMu.Unlock()
+
+	a.monitor.SetGameMode(active)
+	if stopHook {
+		a.setHotkeyHook(!active)
+	}
+	log.Printf("Game mode: %v", active)
+	a.refreshTray()
+}
+
+// IsGameMode returns whether a full-screen application is running
+func (a *App) IsGameMode() bool {
+	a.gameMu.Lock()
+	defer a.gameMu.Unlock()
+	return a.gameMode
+}
+
+// setHotkeyHook removes or reinstalls the global keyboard hook
+func (a *App) setHotkeyHook(enabled bool) {
+	a.gameMu.Lock()
+	defer a.gameMu.Unlock()
+	if enabled == !a.hookStopped {
+		return
+	}
+	if enabled {
+		if err := a.hotkeys.Start(); err != nil {
+			log.Printf("Warning: Failed to restore hotkeys after game mode: %v", err)
+			return
+		}
+	} else {
+		a.hotkeys.Stop()
+	}
+	a.hookStopped = !enabled
+}
+
+// trayTooltip returns the tray icon tooltip for the current state
+func (a *App) trayTooltip() string {
+	if a.IsGameMode() {
+		return "Pano - Oyun modu"
+	}
+	if a.monitor.IsPaused() {
+		return "Pano - Duraklatıldı"
+	}
+	return "Pano"
+}
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+
+	"pano/internal/storage"
+)
+
+// highlightDuration is how long a newly inserted card fades from the accent color
+const highlightDuration = 800 * time.Millisecond
+
+// listUpdate is how the rows changed since the last refresh
+type listUpdate int
+
+const (
+	updateRebuild listUpdate = iota // Anything but a single capture; rows are only filled again
+	updateInsert                    // A new history item at the top; cards at the tail may be evicted
+	updateMove                      // A history item moved to the top by a duplicate capture
+)
+
+// capture is a single new or moved history item found by followCapture
+type capture struct {
+	r          *clipboardListRenderer
+	op         listUpdate
+	top        storage.ClipboardItem
+	oldRow     int       // Row of the moved card before the refresh, -1 for a new one
+	oldHeights []float32 // Row heights before the refresh
+	offset     float32   // Scroll offset before the refresh
+}
+
+// planUpdate compares the history on screen with the new one
+// Only a single insert or move at the top is anchored and highlighted; a refresh with
+// unchanged items (settings, theme) must not be, so a top item only counts as moved
+// when its timestamp changed
+func planUpdate(old, current []storage.ClipboardItem) listUpdate {
+	if len(old) == 0 || len(current) == 0 {
+		return updateRebuild
+	}
+	top, rest := current[0], current[1:]
+
+	pos := -1
+	for i, item := range old {
+		if item.ID == top.ID {
+			pos = i
+			break
+		}
+	}
+
+	if pos < 0 {
+		// Evictions by the limit drop cards from the tail only
+		if len(rest) > len(old) || !sameIDs(rest, old[:len(rest)]) {
+			return updateRebuild
+		}
+		return updateInsert
+	}
+
+	if pos == 0 && top.Timestamp.Equal(old[0].Timestamp) {
+		return updateRebuild
+	}
+	without := make([]storage.ClipboardItem, 0, len(old)-1)
+	without = append(without, old[:pos]...)
+	without = append(without, old[pos+1:]...)
+	if !sameIDs(rest, without) {
+		return updateRebuild
+	}
+	return updateMove
+}
+
+// sameIDs reports whether two item lists hold the same IDs in the same order
+func sameIDs(a, b []storage.ClipboardItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+// historyItems returns the items of the unpinned cards in rows
+func historyItems(rows []listRow) []storage.ClipboardItem {
+	items := make([]storage.ClipboardItem, 0, len(rows))
+	for _, row := range rows {
+		if row.kind == rowCard && !row.item.Pinned {
+			items = append(items, row.item)
+		}
+	}
+	return items
+}
+
+// pinnedRows returns the rows above the history: the pinned header and cards
+func pinnedRows(rows []listRow) []listRow {
+	for i, row := range rows {
+		if (row.kind == rowCard && !row.item.Pinned) || row.kind == rowMore ||
+			(row.kind == rowHeader && row.section.kind == sectionHistory) {
+			return rows[:i]
+		}
+	}
+	return rows
+}
+
+// cardRow returns the row of an item's card, -1 if it has none
+func cardRow(rows []listRow, id string) int {
+	for i, row := range rows {
+		if row.kind == rowCard && row.item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// followCapture compares the rows on screen with the new ones (before their heights are
+// set) and finds a single capture at the top of an otherwise unchanged history
+func (r *clipboardListRenderer) followCapture(old []listRow) capture {
+	c := capture{r: r, op: updateRebuild}
+	oldPinned, pinned := pinnedRows(old), pinnedRows(r.rows)
+	if len(oldPinned) != len(pinned) {
+		return c
+	}
+	for i := range pinned {
+		if !pinned[i].sameRow(oldPinned[i]) || pinned[i].section.collapsed != oldPinned[i].section.collapsed {
+			return c
+		}
+	}
+
+	history := historyItems(r.rows)
+	c.op = planUpdate(historyItems(old), history)
+	if c.op == updateRebuild {
+		return c
+	}
+	c.top = history[0]
+	c.oldRow = cardRow(old, c.top.ID)
+	c.oldHeights = r.list.view.heights
+	c.offset = r.list.view.List.GetScrollOffset()
+	return c
+}
+
+// anchor keeps the content the user was looking at in place after a card was added
+// above it; at the very top the new card is simply shown
+func (c capture) anchor() {
+	if c.op == updateRebuild || c.offset <= 0 {
+		return
+	}
+	view := c.r.list.view
+	padding := view.List.Theme().Size(theme.SizeNamePadding)
+	row := cardRow(c.r.rows, c.top.ID)
+	if row < 0 || row >= len(view.heights) {
+		return
+	}
+	shift := view.heights[row] + padding
+
+	// A moved card above the viewport leaves a gap there as well, which the new card fills
+	if c.oldRow >= 0 && c.oldRow < len(c.oldHeights) {
+		if stackedOffset(c.oldHeights, c.oldRow, padding)+c.oldHeights[c.oldRow] <= c.offset {
+			shift -= c.oldHeights[c.oldRow] + padding
+		}
+	}
+	if shift != 0 {
+		view.List.ScrollToOffset(c.offset + shift)
+	}
+}
+
+// highlight flashes the card of the capture if it is on screen
+func (c capture) highlight() {
+	if c.op == updateRebuild {
+		return
+	}
+	if row, ok := c.r.shown[c.top.ID]; ok {
+		highlightCard(row, c.top.ID)
+	}
+}
+
+// highlightCard fades a card background from the accent color to its normal color
+func highlightCard(row *listRowView, id string) {
+	if row.row.kind != rowCard || row.row.item.ID != id {
+		return
+	}
+	anim := canvas.NewColorRGBAAnimation(theme.Color(theme.ColorNamePrimary), GetCardBackgroundColor(row.row.item.Pinned), highlightDuration, func(c color.Color) {
+		// The row is reused for another card once it scrolls out of view
+		if row.row.kind != rowCard || row.row.item.ID != id {
+			return
+		}
+		row.bg.FillColor = c
+		canvas.Refresh(row.bg)
+	})
+	anim.Curve = fyne.AnimationEaseOut
+	anim.Start()
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pano/internal/storage"
+)
+
+// historyOf returns text items named by ids; an ID with a "+" suffix was captured again
+// after the others
+func historyOf(ids string) []storage.ClipboardItem {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var items []storage.ClipboardItem
+	for _, id := range strings.Fields(ids) {
+		stamp := base
+		if cut, ok := strings.CutSuffix(id, "+"); ok {
+			id, stamp = cut, base.Add(time.Minute)
+		}
+		items = append(items, storage.ClipboardItem{ID: id, Type: "text", Timestamp: stamp})
+	}
+	return items
+}
+
+// Only a single capture at the top is applied in place; anything else rebuilds
+func TestPlanUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		old, current string
+		want         listUpdate
+	}{
+		{"first item", "", "a", updateRebuild},
+		{"history cleared", "a b", "", updateRebuild},
+		{"new capture", "b c", "a b c", updateInsert},
+		{"new capture evicting the oldest", "b c d", "a b c", updateInsert},
+		{"new capture evicting two", "b c d", "a b", updateInsert},
+		{"duplicate moved to the top", "a b c", "c+ a b", updateMove},
+		{"top item copied again", "a b", "a+ b", updateMove},
+		{"unchanged refresh", "a b c", "a b c", updateRebuild},
+		{"two new captures", "c d", "a b c d", updateRebuild},
+		{"item deleted below", "a b c", "x a c", updateRebuild},
+		{"moved and another deleted", "a b c", "c+ a", updateRebuild},
+		{"reordered", "a b c", "a c b", updateRebuild},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planUpdate(historyOf(tt.old), historyOf(tt.current)); got != tt.want {
+				t.Errorf("planUpdate(%q, %q) = %d, want %d", tt.old, tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+// The pinned part of the rows ends at the history header or the first unpinned card
+func TestPinnedRows(t *testing.T) {
+	pinned := storage.ClipboardItem{ID: "p", Pinned: true}
+	history := storage.ClipboardItem{ID: "h"}
+	rows := []listRow{
+		{kind: rowHeader, section: listSection{kind: sectionPinned}},
+		{kind: rowCard, item: pinned},
+		{kind: rowHeader, section: listSection{kind: sectionHistory}},
+		{kind: rowCard, item: history},
+		{kind: rowMore, more: 3},
+	}
+	if got := len(pinnedRows(rows)); got != 2 {
+		t.Errorf("%d pinned rows, want 2", got)
+	}
+	if got := len(pinnedRows(rows[3:])); got != 0 {
+		t.Errorf("%d pinned rows without pinned items, want 0", got)
+	}
+	if got := historyItems(rows); len(got) != 1 || got[0].ID != "h" {
+		t.Errorf("history items are %+v", got)
+	}
+	if cardRow(rows, "h") != 3 || cardRow(rows, "p") != 1 || cardRow(rows, "yok") != -1 {
+		t.Error("cardRow found the wrong rows")
+	}
+}
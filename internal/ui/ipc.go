@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+
+	"fyne.io/fyne/v2"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+	"pano/internal/system"
+)
+
+// StartIPC lets "pano add" and the Explorer entries reach this instance
+func (a *App) StartIPC() error {
+	dbPath, err := storage.GetDatabasePath()
+	if err != nil {
+		return err
+	}
+	server, err := system.StartIPCServer(filepath.Dir(dbPath), a.handleIPC)
+	if err != nil {
+		return err
+	}
+	a.ipc = server
+	return nil
+}
+
+// SetShellIntegration sets what the settings dialog uses to add the Explorer entries
+func (a *App) SetShellIntegration(shell system.ShellIntegration) {
+	a.shellMenu = shell
+}
+
+// handleIPC runs a command from another process; its result or error is sent back to it
+// Requests arrive one at a time (see system.IPCServer), never on the UI goroutine
+func (a *App) handleIPC(req system.IPCRequest) (any, error) {
+	switch req.Command {
+	case system.IPCCommandAdd:
+		return nil, a.addFiles(req.Paths)
+	case system.IPCCommandList:
+		return a.ipcListItems(req.Limit), nil
+	case system.IPCCommandGet:
+		return a.ipcItemContent(req.ID)
+	case system.IPCCommandAddText:
+		return nil, a.ipcAddText(req.Text, req.Pin)
+	case system.IPCCommandCopy:
+		return nil, a.manager.CopyToClipboard(req.ID)
+	case system.IPCCommandDelete:
+		if err := a.manager.DeleteItem(req.ID); err != nil {
+			return nil, err
+		}
+		fyne.Do(a.afterItemsChanged)
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown command %q", req.Command)
+	}
+}
+
+// ipcListItems describes the newest items, at most limit of them if it is positive
+func (a *App) ipcListItems(limit int) []system.IPCItem {
+	items := a.manager.GetAllItems()
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Timestamp.After(items[j].Timestamp)
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	result := make([]system.IPCItem, 0, len(items))
+	for _, item := range items {
+		entry := system.IPCItem{
+			ID:        item.ID,
+			Type:      item.Type,
+			Timestamp: item.Timestamp,
+			Pinned:    item.Pinned,
+			Size:      item.Size,
+			Protected: item.IsProtected(),
+		}
+		if item.Type == "text" {
+			if content, err := a.manager.GetItemContent(item.ID); err == nil {
+				entry.Preview = system.IPCPreview(string(content))
+				storage.Zero(content)
+			}
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// ipcItemContent returns the content of an item for a get request
+func (a *App) ipcItemContent(id string) (system.IPCContent, error) {
+	itemType := ""
+	items := a.manager.GetAllItems()
+	for _, item := range items {
+		if item.ID == id {
+			itemType = item.Type
+			break
+		}
+	}
+	if itemType == "" {
+		return system.IPCContent{}, fmt.Errorf("item not found")
+	}
+
+	content, err := a.manager.GetItemContent(id)
+	if err != nil {
+		return system.IPCContent{}, err
+	}
+	if itemType == "text" {
+		defer storage.Zero(content)
+		return system.IPCContent{Type: itemType, Text: string(content)}, nil
+	}
+	return system.IPCContent{Type: itemType, Data: content}, nil
+}
+
+// ipcAddText stores text sent by the CLI like text written in the editor
+func (a *App) ipcAddText(text string, pin bool) error {
+	if err := validateItemText(text); err != nil {
+		return err
+	}
+	err := a.manager.AddText(text, pin)
+	var warning *storage.LimitWarning
+	if err != nil && !errors.As(err, &warning) {
+		return err
+	}
+	fyne.Do(a.afterItemsChanged)
+	return nil
+}
+
+// addFiles stores files sent from Explorer and notifies once for all of them
+// Every file is tried; the first error is returned
+func (a *App) addFiles(paths []string) error {
+	var firstErr error
+	added := 0
+	var itemType string
+	for _, path := range paths {
+		t, err := a.manager.AddFile(path)
+		var warning *storage.LimitWarning
+		if err != nil && !errors.As(err, &warning) {
+			log.Printf("Warning: Failed to add %s: %v", path, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		added++
+		itemType = t
+	}
+
+	if added > 0 {
+		fyne.Do(a.afterItemsChanged)
+		switch {
+		case added > 1:
+			a.sendNotification("Yakalandı", fmt.Sprintf("%d dosya geçmişe eklendi.", added))
+		case itemType == "image":
+			a.sendNotification("Yakalandı", "Görsel dosyası geçmişe eklendi.")
+		default:
+			a.sendNotification("Yakalandı", "Metin dosyası geçmişe eklendi.")
+		}
+	}
+
+	var reject *storage.RejectError
+	switch {
+	case firstErr == nil:
+	case errors.As(firstErr, &reject):
+		a.handleCaptureError(firstErr)
+	case errors.Is(firstErr, clipboard.ErrUnsupportedFile):
+		a.sendNotification("Eklenemedi", "Yalnızca metin ve görsel dosyaları eklenebilir.")
+	default:
+		a.sendNotification("Eklenemedi", firstErr.Error())
+	}
+	return firstErr
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// jumpDuration is the length of the smooth scroll to the newest or oldest item
+const jumpDuration = 250 * time.Millisecond
+
+// jumpControl floats an "En yeni" pill over the list once it is scrolled more than
+// a screenful down, and scrolls smoothly to either end of the list
+type jumpControl struct {
+	list *ClipboardList
+	pill *widget.Button
+	anim *fyne.Animation
+}
+
+// newJumpControl creates the pill and follows the list's scroll offset
+func newJumpControl(list *ClipboardList) *jumpControl {
+	j := &jumpControl{list: list}
+	j.pill = widget.NewButtonWithIcon("En yeni", theme.MoveUpIcon(), j.ToTop)
+	j.pill.Importance = widget.HighImportance
+	j.pill.Hide()
+
+	list.SetOnScroll(j.update)
+	return j
+}
+
+// Overlay stacks the pill at the top of the list
+func (j *jumpControl) Overlay() fyne.CanvasObject {
+	return container.NewStack(j.list, container.NewVBox(container.NewCenter(j.pill)))
+}
+
+// showJumpPill reports whether the pill is shown at a scroll offset
+func showJumpPill(offset, viewport float32) bool {
+	return viewport > 0 && offset > viewport
+}
+
+// update shows or hides the pill for the current offset
+func (j *jumpControl) update(offset float32) {
+	if showJumpPill(offset, j.list.BaseWidget.Size().Height) {
+		j.pill.Show()
+	} else {
+		j.pill.Hide()
+	}
+}
+
+// ToTop scrolls smoothly to the newest item
+func (j *jumpControl) ToTop() {
+	j.scrollTo(0)
+}
+
+// ToBottom scrolls smoothly to the oldest item
+func (j *jumpControl) ToBottom() {
+	j.scrollTo(max(j.list.ContentHeight()-j.list.BaseWidget.Size().Height, 0))
+}
+
+// scrollTo animates the vertical offset; a new jump replaces a running one
+func (j *jumpControl) scrollTo(target float32) {
+	if j.anim != nil {
+		j.anim.Stop()
+	}
+	start := j.list.ScrollOffset()
+	j.anim = fyne.NewAnimation(jumpDuration, func(progress float32) {
+		j.list.ScrollToOffset(start + (target-start)*progress)
+		// ScrollToOffset doesn't notify like scrolling by the user does
+		j.update(j.list.ScrollOffset())
+	})
+	j.anim.Curve = fyne.AnimationEaseInOut
+	j.anim.Start()
+}
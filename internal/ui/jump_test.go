@@ -0,0 +1,27 @@
+package ui
+
+import "testing"
+
+// The pill shows once the list is scrolled more than a screenful down, and never before
+// the list has a size
+func TestShowJumpPill(t *testing.T) {
+	tests := []struct {
+		name             string
+		offset, viewport float32
+		want             bool
+	}{
+		{"at the top", 0, 400, false},
+		{"less than a screen", 250, 400, false},
+		{"exactly a screen", 400, 400, false},
+		{"past a screen", 401, 400, true},
+		{"far down", 5000, 400, true},
+		{"not laid out yet", 100, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := showJumpPill(tt.offset, tt.viewport); got != tt.want {
+				t.Errorf("showJumpPill(%v, %v) = %v, want %v", tt.offset, tt.viewport, got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"image"
 	"image/color"
 	"image/png"
+	"net/url"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -13,56 +15,108 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/layout"
-	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"pano/internal/clipboard"
 	"pano/internal/storage"
 )
 
+// thumbEntry is a cached thumbnail with the layout computed from the original image
+type thumbEntry struct {
+	img    image.Image
+	layout thumbLayout
+	scale  float32 // Pixel scale the bitmap was decoded for
+}
+
 type thumbnailCache struct {
 	mu    sync.RWMutex
-	cache map[string]image.Image
+	cache map[string]thumbEntry
 }
 
 var thumbCache = &thumbnailCache{
-	cache: make(map[string]image.Image),
+	cache: make(map[string]thumbEntry),
 }
 
-func (tc *thumbnailCache) get(id string) (image.Image, bool) {
+func (tc *thumbnailCache) get(id string) (thumbEntry, bool) {
 	tc.mu.RLock()
 	defer tc.mu.RUnlock()
-	img, ok := tc.cache[id]
-	return img, ok
+	entry, ok := tc.cache[id]
+	return entry, ok
 }
 
-func (tc *thumbnailCache) set(id string, img image.Image) {
+func (tc *thumbnailCache) set(id string, entry thumbEntry) {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
-	tc.cache[id] = img
+	tc.cache[id] = entry
 }
 
 func (tc *thumbnailCache) clear() {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
-	tc.cache = make(map[string]image.Image)
+	tc.cache = make(map[string]thumbEntry)
 }
 
 type ClipboardList struct {
 	widget.BaseWidget
-	manager  *clipboard.Manager
-	items    []storage.ClipboardItem
-	onSelect func(id string)
-	onPin    func(id string)
-	onDelete func(id string)
+	manager *clipboard.Manager
+	items   []storage.ClipboardItem
+	seq     uint64 // Change counter of the items on screen
+	limit   int    // Cards rendered, a multiple of listPageSize (see paging.go)
+	total   int    // Items matching the query and filter, rendered or not
+
+	pixelScale float32 // Device pixels per logical unit, thumbnails are decoded at this density
+	onSelect   func(id string)
+	onPin      func(id string)
+	onDelete   func(id string)
+
+	onCopyOriginal     func(id string)
+	onDetails          func(id string)
+	onCopyWithNewlines func(id string, mode clipboard.NewlineMode)
+	onCopyText         func(text string)
+	onCopyEncoded      func(id string, dataURI bool)
+	onSmartAction      func(item storage.ClipboardItem, action smartAction)
+	onEditTags         func(item storage.ClipboardItem)
+	onRename           func(item storage.ClipboardItem)
+	onEdit             func(item storage.ClipboardItem)
+	onProtect          func(item storage.ClipboardItem)
+
+	disabledActions map[string]bool // Smart action IDs turned off in the settings
+
+	keepLineBreaks bool // Render every text item line by line, not only code
+
+	agingAfter time.Duration // Unpinned cards older than this are dimmed, 0 never (see freshness.go)
+	staleAfter time.Duration // Unpinned cards older than this are badged stale, 0 never
+	compact    bool          // One-line text previews and a stacked footer, for the docked sidebar
+
+	pinnedCollapsed bool // Pinned section shows only its header
+	onSectionToggle func(collapsed bool)
+
+	query             string          // Current search query, empty shows everything
+	searchMode        searchMode      // How the query is matched
+	sortOrder         sortOrder       // How items are ordered within the pinned and history sections
+	searchErr         error           // Invalid regex or timeout from the last Refresh
+	filter            itemPredicate   // Quick filter chips, nil shows everything
+	selected          map[string]bool // Multi-selected item IDs
+	onSelectionChange func()
+
+	state     ListState // Chosen by the App; anything but ListItems replaces the cards
+	stateErr  error     // Load error shown in ListError
+	onRetry   func()    // Retry button of ListError
+	onRecover func()    // Recovery key button of ListError, for a history from another machine
+
+	view     *cardListView        // Rows of headers and cards (see listview.go)
+	onScroll func(offset float32) // Follows scrolling by the user, for the jump pill
 }
 
 func NewClipboardList(manager *clipboard.Manager) *ClipboardList {
 	list := &ClipboardList{
-		manager: manager,
-		items:   []storage.ClipboardItem{},
+		manager:  manager,
+		items:    []storage.ClipboardItem{},
+		limit:    listPageSize,
+		selected: make(map[string]bool),
+		view:     newCardListView(),
 	}
+	list.view.onScrolled = list.onScrolled
 	list.ExtendBaseWidget(list)
 	return list
 }
@@ -73,177 +127,720 @@ func (c *ClipboardList) SetCallbacks(onSelect, onPin, onDelete func(id string))
 	c.onDelete = onDelete
 }
 
+// SetOnCopyOriginal sets the callback for copying an item's pre-cleaning content
+func (c *ClipboardList) SetOnCopyOriginal(callback func(id string)) {
+	c.onCopyOriginal = callback
+}
+
+// SetOnCopyWithNewlines sets the callback for one-shot copies with converted line endings
+func (c *ClipboardList) SetOnCopyWithNewlines(callback func(id string, mode clipboard.NewlineMode)) {
+	c.onCopyWithNewlines = callback
+}
+
+// SetOnCopyEncoded sets the callback for copying an image as base64 or a data URI
+func (c *ClipboardList) SetOnCopyEncoded(callback func(id string, dataURI bool)) {
+	c.onCopyEncoded = callback
+}
+
+// SetOnCopyText sets the callback for quick actions that copy a single field
+func (c *ClipboardList) SetOnCopyText(callback func(text string)) {
+	c.onCopyText = callback
+}
+
+// SetOnSmartAction sets the callback for the content-aware actions on cards
+func (c *ClipboardList) SetOnSmartAction(callback func(item storage.ClipboardItem, action smartAction)) {
+	c.onSmartAction = callback
+}
+
+// SetOnEditTags sets the callback for opening an item's tag editor
+func (c *ClipboardList) SetOnEditTags(callback func(item storage.ClipboardItem)) {
+	c.onEditTags = callback
+}
+
+// SetOnRename sets the callback for editing an item's title
+func (c *ClipboardList) SetOnRename(callback func(item storage.ClipboardItem)) {
+	c.onRename = callback
+}
+
+// SetOnEdit sets the callback for editing a text item's content
+func (c *ClipboardList) SetOnEdit(callback func(item storage.ClipboardItem)) {
+	c.onEdit = callback
+}
+
+// SetOnProtect sets the callback for setting or removing an item's passphrase
+func (c *ClipboardList) SetOnProtect(callback func(item storage.ClipboardItem)) {
+	c.onProtect = callback
+}
+
+// SetDisabledActions sets the smart action IDs left off the cards
+func (c *ClipboardList) SetDisabledActions(ids map[string]bool) {
+	c.disabledActions = ids
+}
+
+// SetOnDetails sets the callback for opening an item's detail dialog
+func (c *ClipboardList) SetOnDetails(callback func(id string)) {
+	c.onDetails = callback
+}
+
+// SetOnScroll sets the callback fired with the offset when the user scrolls the cards
+func (c *ClipboardList) SetOnScroll(callback func(offset float32)) {
+	c.onScroll = callback
+}
+
+// SetOnRetry sets the callback of the retry button shown in the error state
+func (c *ClipboardList) SetOnRetry(callback func()) {
+	c.onRetry = callback
+}
+
+// SetOnRecover sets the callback of the recovery key button shown when the history was
+// encrypted under another machine's key
+func (c *ClipboardList) SetOnRecover(callback func()) {
+	c.onRecover = callback
+}
+
+// SetState switches between cards and the loading, empty, no-matches and error views
+// err is shown in ListError; the list is redrawn only when something changed
+func (c *ClipboardList) SetState(state ListState, err error) {
+	if state == c.state && err == c.stateErr {
+		return
+	}
+	c.state = state
+	c.stateErr = err
+	c.BaseWidget.Refresh()
+}
+
+// State returns the view the list shows
+func (c *ClipboardList) State() ListState {
+	return c.state
+}
+
+// SetOnSelectionChange sets the callback fired when the multi-selection changes
+func (c *ClipboardList) SetOnSelectionChange(callback func()) {
+	c.onSelectionChange = callback
+}
+
+// SetFilter sets the chip predicate applied on the next Refresh
+func (c *ClipboardList) SetFilter(filter itemPredicate) {
+	c.filter = filter
+	c.resetPaging()
+}
+
+// IsFiltered reports whether a query or filter hides some items
+func (c *ClipboardList) IsFiltered() bool {
+	return strings.TrimSpace(c.query) != "" || c.filter != nil
+}
+
+// VisibleCount returns the number of items matching the query and filter, including
+// those on pages not rendered yet
+func (c *ClipboardList) VisibleCount() int {
+	return c.total
+}
+
+// SetQuery sets the search query applied on the next Refresh
+func (c *ClipboardList) SetQuery(query string) {
+	if query != c.query {
+		c.resetPaging()
+	}
+	c.query = query
+}
+
+// SetSearchMode sets how the query is matched on the next Refresh
+func (c *ClipboardList) SetSearchMode(mode searchMode) {
+	c.searchMode = mode
+}
+
+// SetSortOrder sets how items are ordered on the next Refresh
+func (c *ClipboardList) SetSortOrder(order sortOrder) {
+	if order != c.sortOrder {
+		c.resetPaging()
+	}
+	c.sortOrder = order
+}
+
+// SearchError returns the regex error or timeout of the last Refresh, nil if none
+func (c *ClipboardList) SearchError() error {
+	return c.searchErr
+}
+
+// Selected returns the multi-selected item IDs in list order
+func (c *ClipboardList) Selected() []string {
+	ids := make([]string, 0, len(c.selected))
+	for _, item := range c.items {
+		if c.selected[item.ID] {
+			ids = append(ids, item.ID)
+		}
+	}
+	return ids
+}
+
+// ClearSelection deselects every item
+func (c *ClipboardList) ClearSelection() {
+	c.selected = make(map[string]bool)
+	if c.onSelectionChange != nil {
+		c.onSelectionChange()
+	}
+}
+
+// Search match ranks; title matches are listed before other matches
+const (
+	matchNone  = iota
+	matchBody  // Content, hash, source page or program
+	matchTitle // The user's title or the implicit title of a multi-line text
+)
+
+// appQueryPrefix starts a search for the program items were copied from, e.g. "uygulama:chrome"
+const appQueryPrefix = "uygulama:"
+
+// matchRank returns how an item matches the search query
+// "sha256:<prefix>" matches by content hash in every mode, anything else by title, text
+// content or the page or program the item was copied from
+func (c *ClipboardList) matchRank(item storage.ClipboardItem, query string, m *matcher) int {
+	if prefix, ok := strings.CutPrefix(strings.ToLower(query), "sha256:"); ok {
+		if strings.HasPrefix(item.Hash, strings.TrimSpace(prefix)) {
+			return matchBody
+		}
+		return matchNone
+	}
+	// "etiket:sql" lists the items tagged sql and nothing else
+	if tag, ok := strings.CutPrefix(storage.FoldText(query), tagQueryPrefix); ok {
+		if item.HasTag(tag) {
+			return matchBody
+		}
+		return matchNone
+	}
+	// "uygulama:chrome" lists the items copied in Chrome and nothing else
+	if app, ok := strings.CutPrefix(storage.FoldText(query), appQueryPrefix); ok {
+		if item.SourceApp == "" {
+			return matchNone
+		}
+		source, err := c.manager.GetItemSourceApp(item.ID)
+		if err == nil && strings.Contains(storage.FoldText(source), strings.TrimSpace(app)) {
+			return matchBody
+		}
+		return matchNone
+	}
+	for _, tag := range item.Tags {
+		if m.Match(tag) {
+			return matchBody
+		}
+	}
+	if item.Title != "" {
+		if title, err := c.manager.GetItemUserTitle(item.ID); err == nil && m.Match(title) {
+			return matchTitle
+		}
+	}
+	if item.TitleCache != "" {
+		if title, err := c.manager.GetItemTitle(item.ID); err == nil && m.Match(title) {
+			return matchTitle
+		}
+	}
+	if item.SourceURL != "" {
+		if source, err := c.manager.GetItemSourceURL(item.ID); err == nil && m.Match(source) {
+			return matchBody
+		}
+	}
+	if item.SourceApp != "" {
+		if source, err := c.manager.GetItemSourceApp(item.ID); err == nil && m.Match(source) {
+			return matchBody
+		}
+	}
+	if item.Type != "text" {
+		return matchNone
+	}
+
+	data, err := c.manager.GetItemContent(item.ID)
+	if err != nil {
+		return matchNone
+	}
+	defer storage.Zero(data)
+	if m.Match(string(data)) {
+		return matchBody
+	}
+	return matchNone
+}
+
+// SetPinnedCollapsed collapses or expands the pinned section
+func (c *ClipboardList) SetPinnedCollapsed(collapsed bool) {
+	c.pinnedCollapsed = collapsed
+}
+
+// SetOnSectionToggle sets the callback fired when the pinned section is collapsed or expanded
+func (c *ClipboardList) SetOnSectionToggle(callback func(collapsed bool)) {
+	c.onSectionToggle = callback
+}
+
+// SetPixelScale sets the pixel density thumbnails are decoded at
+func (c *ClipboardList) SetPixelScale(scale float32) {
+	c.pixelScale = scale
+}
+
+// SetCompact switches to the compact card style of the docked sidebar
+func (c *ClipboardList) SetCompact(compact bool) {
+	c.compact = compact
+}
+
+// SetFreshness sets the ages at which unpinned cards look aging and stale
+func (c *ClipboardList) SetFreshness(agingAfter, staleAfter time.Duration) {
+	c.agingAfter = agingAfter
+	c.staleAfter = staleAfter
+}
+
+// SetKeepLineBreaks toggles multi-line previews for all text items
+func (c *ClipboardList) SetKeepLineBreaks(keep bool) {
+	c.keepLineBreaks = keep
+}
+
 func (c *ClipboardList) Refresh() {
-	c.items = c.manager.GetAllItems()
+	if c.refreshPage() {
+		return
+	}
+	items, seq := c.manager.Snapshot()
+	// A snapshot older than the one on screen would resurrect deleted items
+	if seq < c.seq {
+		return
+	}
+	c.seq = seq
+	sortItems(items, c.sortOrder)
+
+	query := strings.TrimSpace(c.query)
+	c.searchErr = nil
+	var m *matcher
+	if query != "" {
+		var err error
+		if m, err = newMatcher(query, c.searchMode); err != nil {
+			// An invalid pattern leaves the list unfiltered by text; the error is shown under the box
+			c.searchErr = err
+			query = ""
+		}
+	}
+	if query != "" || c.filter != nil {
+		now := time.Now()
+		filtered := make([]storage.ClipboardItem, 0, len(items))
+		titleMatches := make([]storage.ClipboardItem, 0)
+		for _, item := range items {
+			// Cheap metadata predicates run before decrypting for the query
+			if c.filter != nil && !c.filter(item, now) {
+				continue
+			}
+			rank := matchBody
+			if query != "" {
+				rank = c.matchRank(item, query, m)
+			}
+			switch rank {
+			case matchTitle:
+				titleMatches = append(titleMatches, item)
+			case matchBody:
+				filtered = append(filtered, item)
+			}
+		}
+		items = append(titleMatches, filtered...)
+		if m != nil {
+			c.searchErr = m.Err()
+		}
+	}
+	c.items = items
+	c.total = len(items)
+	c.pruneSelection()
+
 	c.BaseWidget.Refresh()
 }
 
+// pruneSelection drops selections for items that are gone or filtered out
+func (c *ClipboardList) pruneSelection() {
+	visible := make(map[string]bool, len(c.items))
+	for _, item := range c.items {
+		visible[item.ID] = true
+	}
+	for id := range c.selected {
+		if !visible[id] {
+			delete(c.selected, id)
+		}
+	}
+}
+
 func (c *ClipboardList) CreateRenderer() fyne.WidgetRenderer {
-	return &clipboardListRenderer{list: c}
+	r := &clipboardListRenderer{
+		list:     c,
+		previews: make(map[string]cardPreview),
+		texts:    make(map[string]textPreview),
+		heights:  make(map[string]measuredHeight),
+		shown:    make(map[string]*listRowView),
+	}
+	r.measure = newListRowView(r)
+	c.view.List.Length = func() int {
+		return len(r.rows)
+	}
+	c.view.List.CreateItem = func() fyne.CanvasObject {
+		return newListRowView(r)
+	}
+	c.view.List.UpdateItem = r.updateRow
+	// Rows hold buttons, they aren't choices; a tap beside them mustn't leave a row selected
+	c.view.List.OnSelected = func(id widget.ListItemID) {
+		c.view.List.Unselect(id)
+	}
+	r.Refresh()
+	return r
 }
 
 type clipboardListRenderer struct {
 	list      *ClipboardList
-	container *fyne.Container
+	stateView *fyne.Container // Shown instead of the cards in any state but ListItems
+	rows      []listRow
+	width     float32 // Width the card heights were measured at
+
+	previews map[string]cardPreview    // By item ID, see cachedPreview
+	texts    map[string]textPreview    // Decrypted text previews by item ID
+	heights  map[string]measuredHeight // Card heights by item ID
+	measure  *listRowView              // Off-screen row cards are measured with
+	shown    map[string]*listRowView   // Row last filled with each card, for highlighting
+	filling  bool                      // Heights are being set; rows are filled afterwards
 }
 
 func (r *clipboardListRenderer) Layout(size fyne.Size) {
-	if r.container != nil {
-		r.container.Resize(size)
+	if r.stateView != nil {
+		r.stateView.Resize(size)
+		return
+	}
+	r.list.view.List.Resize(size)
+	if size.Width != r.width {
+		// Wrapped text takes another height at another width
+		r.width = size.Width
+		r.setHeights()
+		r.list.view.List.Refresh()
 	}
 }
 
 func (r *clipboardListRenderer) MinSize() fyne.Size {
-	if r.container != nil {
-		return r.container.MinSize()
+	if r.stateView != nil {
+		return r.stateView.MinSize()
 	}
-	return fyne.NewSize(300, 200)
+	return r.list.view.List.MinSize()
 }
 
 func (r *clipboardListRenderer) Refresh() {
-	r.container = r.buildList()
+	size := r.list.BaseWidget.Size()
+	if r.list.state != ListItems {
+		r.stateView = r.createStateView(r.list.state)
+		r.rows = nil
+		r.pruneCaches()
+		r.stateView.Resize(size)
+		canvas.Refresh(r.list)
+		return
+	}
+	wasState := r.stateView != nil
+	r.stateView = nil
+
+	old := r.rows
+	r.rows = r.buildRows()
+	r.pruneCaches()
+	// A single new or moved capture keeps the view where it was and flashes its card
+	capture := r.followCapture(old)
+	r.width = size.Width
+	r.setHeights()
+	if r.list.view.List.Size() != size {
+		r.list.view.List.Resize(size)
+	}
+	capture.anchor()
+	r.list.view.List.Refresh()
+	capture.highlight()
+	if wasState {
+		canvas.Refresh(r.list)
+	}
 }
 
 func (r *clipboardListRenderer) Objects() []fyne.CanvasObject {
-	if r.container == nil {
-		r.container = r.buildList()
+	if r.stateView != nil {
+		return []fyne.CanvasObject{r.stateView}
 	}
-	return []fyne.CanvasObject{r.container}
+	return []fyne.CanvasObject{r.list.view}
 }
 
 func (r *clipboardListRenderer) Destroy() {}
 
-func (r *clipboardListRenderer) buildList() *fyne.Container {
-	if len(r.list.items) == 0 {
-		return r.createEmptyState()
-	}
-
-	items := make([]fyne.CanvasObject, 0, len(r.list.items))
-	for _, item := range r.list.items {
-		items = append(items, r.createCard(item))
+// togglePinned collapses or expands the pinned section from its header
+func (r *clipboardListRenderer) togglePinned() {
+	r.list.pinnedCollapsed = !r.list.pinnedCollapsed
+	if r.list.onSectionToggle != nil {
+		r.list.onSectionToggle(r.list.pinnedCollapsed)
 	}
-
-	return container.NewVBox(items...)
+	r.list.BaseWidget.Refresh()
 }
 
-func (r *clipboardListRenderer) createEmptyState() *fyne.Container {
-	icon := widget.NewIcon(theme.ContentPasteIcon())
-	title := widget.NewLabelWithStyle("Pano boş", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
-	hint := widget.NewLabelWithStyle("Bir şey kopyaladığınızda burada görünür", fyne.TextAlignCenter, fyne.TextStyle{})
+// showCardMenu opens the card's extra actions below the given button
+// Smart actions that didn't fit on the card come first; text items can be edited and
+// copied with other line endings, images as base64 text; the tag editor comes last
+func (r *clipboardListRenderer) showCardMenu(anchor fyne.CanvasObject, item storage.ClipboardItem, moreActions []smartAction) {
+	itemID := item.ID
+	copyWith := func(mode clipboard.NewlineMode) func() {
+		return func() {
+			if r.list.onCopyWithNewlines != nil {
+				r.list.onCopyWithNewlines(itemID, mode)
+			}
+		}
+	}
+	copyEncoded := func(dataURI bool) func() {
+		return func() {
+			if r.list.onCopyEncoded != nil {
+				r.list.onCopyEncoded(itemID, dataURI)
+			}
+		}
+	}
 
-	return container.NewVBox(
-		layout.NewSpacer(),
-		container.NewCenter(icon),
-		container.NewCenter(title),
-		container.NewCenter(hint),
-		layout.NewSpacer(),
+	var menu *fyne.Menu
+	if item.IsProtected() {
+		// Other formats would need the content; the passphrase copy is the card's copy button
+		menu = fyne.NewMenu("")
+	} else if item.Type == "image" {
+		menu = fyne.NewMenu("",
+			fyne.NewMenuItem("data URI olarak kopyala", copyEncoded(true)),
+			fyne.NewMenuItem("base64 olarak kopyala", copyEncoded(false)),
+		)
+	} else {
+		menu = fyne.NewMenu("",
+			fyne.NewMenuItem("Düzenle…", func() {
+				if r.list.onEdit != nil {
+					r.list.onEdit(item)
+				}
+			}),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("LF ile kopyala", copyWith(clipboard.NewlineLF)),
+			fyne.NewMenuItem("CRLF ile kopyala", copyWith(clipboard.NewlineCRLF)),
+		)
+	}
+	protectLabel := "Parolayla koru…"
+	if item.IsProtected() {
+		protectLabel = "Korumayı kaldır…"
+	}
+	if len(menu.Items) > 0 {
+		menu.Items = append(menu.Items, fyne.NewMenuItemSeparator())
+	}
+	menu.Items = append(menu.Items,
+		fyne.NewMenuItem("Etiketler…", func() {
+			if r.list.onEditTags != nil {
+				r.list.onEditTags(item)
+			}
+		}),
+		fyne.NewMenuItem(protectLabel, func() {
+			if r.list.onProtect != nil {
+				r.list.onProtect(item)
+			}
+		}),
 	)
+	if len(moreActions) > 0 {
+		items := make([]*fyne.MenuItem, 0, len(moreActions)+1+len(menu.Items))
+		for _, action := range moreActions {
+			menuItem := fyne.NewMenuItem(action.label, r.smartActionHandler(item, action))
+			menuItem.Icon = action.icon
+			items = append(items, menuItem)
+		}
+		items = append(items, fyne.NewMenuItemSeparator())
+		menu.Items = append(items, menu.Items...)
+	}
+
+	driver := fyne.CurrentApp().Driver()
+	pos := driver.AbsolutePositionForObject(anchor).AddXY(0, anchor.Size().Height)
+	widget.ShowPopUpMenuAtPosition(menu, driver.CanvasForObject(anchor), pos)
 }
 
-func (r *clipboardListRenderer) createCard(item storage.ClipboardItem) fyne.CanvasObject {
+// createPreview builds the preview of an item's card with its smart action row
+func (r *clipboardListRenderer) createPreview(item storage.ClipboardItem) cardPreview {
 	var content fyne.CanvasObject
+	var smartRow fyne.CanvasObject
+	var moreActions []smartAction
+
+	if item.IsProtected() {
+		content = newProtectedPreview()
+	} else if item.Type == "text" {
+		// Cards only see the preview; the content is read on copy or in the viewer
+		// Structured previews need the whole text, which only short items' previews hold
+		preview := r.textPreview(item)
+		text, full := preview.Text, ""
+		longLine := isLongSingleLine(item.Size, text)
+		if preview.Complete(item.Size) {
+			full = text
+		}
 
-	if item.Type == "text" {
-		data, err := r.list.manager.GetItemContent(item.ID)
-		text := ""
-		if err == nil {
-			text = string(data)
-			text = strings.ReplaceAll(text, "\r\n", " ")
-			text = strings.ReplaceAll(text, "\n", " ")
-			text = strings.ReplaceAll(text, "\r", " ")
-			text = strings.TrimSpace(text)
-			if len(text) > 100 {
-				text = text[:100] + "..."
-			}
+		class := item.Class
+		if class == "" {
+			class = storage.ClassifyText(text)
+		}
+		if full != "" {
+			smartRow, moreActions = r.createSmartActions(item, class, full)
+		} else {
+			smartRow, moreActions = r.createSmartActions(item, class, text)
 		}
 
-		label := widget.NewLabel(text)
-		label.Wrapping = fyne.TextWrapWord
-		content = label
+		if r.list.compact {
+			label := widget.NewLabelWithStyle(buildFlatPreview(text), fyne.TextAlignLeading, fyne.TextStyle{Monospace: class == storage.ClassCode})
+			label.Truncation = fyne.TextTruncateEllipsis
+			content = label
+		} else if longLine {
+			content = createLongLinePreview(item.Size, text)
+		} else if structured := r.createStructuredPreview(class, full); structured != nil {
+			content = structured
+		} else if class == storage.ClassCode || r.list.keepLineBreaks {
+			label := widget.NewLabelWithStyle(buildCodePreview(text), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+			label.Truncation = fyne.TextTruncateEllipsis
+			content = label
+		} else if title, rest, ok := storage.ExtractTitle(text); ok {
+			content = r.createTitledPreview(item, title, rest)
+		} else {
+			label := widget.NewLabel(buildFlatPreview(text))
+			label.Wrapping = fyne.TextWrapWord
+			content = label
+		}
 
 	} else if item.Type == "image" {
-		var img image.Image
-		if cached, ok := thumbCache.get(item.ID); ok {
-			img = cached
-		} else {
-			data, err := r.list.manager.GetItemContent(item.ID)
+		entry, ok := thumbCache.get(item.ID)
+		// Thumbnails made for another monitor's DPI are decoded again
+		if !ok || entry.scale != r.list.pixelScale {
+			preview, err := r.list.manager.GetPreview(item.ID)
 			if err == nil {
-				decoded, err := png.Decode(bytes.NewReader(data))
+				decoded, err := png.Decode(bytes.NewReader(preview.Thumbnail))
+				storage.Zero(preview.Thumbnail)
 				if err == nil {
-					img = createThumbnailFast(decoded, 320, 160)
-					thumbCache.set(item.ID, img)
+					// Layout comes from the original size so the thumbnail's rounding can't shift it
+					entry.layout = computeThumbLayout(preview.Width, preview.Height)
+					pixelW, pixelH := thumbPixelSize(entry.layout, r.list.pixelScale)
+					entry.img = createThumbnailFast(decoded, pixelW, pixelH)
+					entry.scale = r.list.pixelScale
+					thumbCache.set(item.ID, entry)
 				}
 			}
 		}
 
-		if img != nil {
-			imgWidget := canvas.NewImageFromImage(img)
-			imgWidget.FillMode = canvas.ImageFillContain
-			imgWidget.ScaleMode = canvas.ImageScaleSmooth
-			imgWidget.SetMinSize(fyne.NewSize(320, 140))
-			content = container.NewCenter(imgWidget)
+		if entry.img != nil {
+			content = newImagePreview(entry.img, entry.layout)
 		} else {
 			content = widget.NewLabel("Görsel yüklenemedi")
 		}
+		smartRow, moreActions = r.createSmartActions(item, "", "")
+	} else if item.Type == "files" {
+		content = createFilesPreview(r.textPreview(item), r.list.compact)
 	} else {
 		content = widget.NewLabel("Bilinmeyen tür")
 	}
 
-	timeStr := formatTimestamp(item.Timestamp)
-	sizeStr := formatSize(item.Size)
+	return cardPreview{content: content, smart: smartRow, more: moreActions}
+}
 
-	var infoStr string
-	if item.Type == "text" {
-		infoStr = fmt.Sprintf("Metin - %s - %s", sizeStr, timeStr)
-	} else {
-		infoStr = fmt.Sprintf("Görsel - %s - %s", sizeStr, timeStr)
+// createTitledPreview shows the implicit title in bold above a dimmer flattened rest
+// The title stored at capture time wins over the one extracted from the preview
+func (r *clipboardListRenderer) createTitledPreview(item storage.ClipboardItem, title, rest string) fyne.CanvasObject {
+	if item.TitleCache != "" {
+		if stored, err := r.list.manager.GetItemTitle(item.ID); err == nil && stored != "" {
+			title = stored
+		}
 	}
+	titleLabel := widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	titleLabel.Truncation = fyne.TextTruncateEllipsis
+	restLabel := widget.NewLabel(buildFlatPreview(rest))
+	restLabel.Wrapping = fyne.TextWrapWord
+	restLabel.Importance = widget.LowImportance
+	return container.NewVBox(titleLabel, restLabel)
+}
 
-	if item.Pinned {
-		infoStr = "[Sabit] " + infoStr
+// sourceURL returns the page an item was copied from, nil if unknown
+func (r *clipboardListRenderer) sourceURL(item storage.ClipboardItem) *url.URL {
+	if item.SourceURL == "" {
+		return nil
+	}
+	source, err := r.list.manager.GetItemSourceURL(item.ID)
+	if err != nil || source == "" {
+		return nil
+	}
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil
 	}
+	return u
+}
 
-	infoLabel := widget.NewLabelWithStyle(infoStr, fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+// userTitleText returns the title the user gave an item, "" if it has none
+func (r *clipboardListRenderer) userTitleText(item storage.ClipboardItem) string {
+	if item.Title == "" {
+		return ""
+	}
+	title, err := r.list.manager.GetItemUserTitle(item.ID)
+	if err != nil {
+		return ""
+	}
+	return title
+}
 
-	itemID := item.ID
-	
-	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
-		if r.list.onSelect != nil {
-			r.list.onSelect(itemID)
-		}
-	})
-	copyBtn.Importance = widget.HighImportance
+// sourceAppText returns the program an item was copied from without ".exe", "" if unknown
+func (r *clipboardListRenderer) sourceAppText(item storage.ClipboardItem) string {
+	if item.SourceApp == "" {
+		return ""
+	}
+	source, err := r.list.manager.GetItemSourceApp(item.ID)
+	if err != nil {
+		return ""
+	}
+	return appDisplayName(source)
+}
 
-	pinBtn := widget.NewButtonWithIcon("", theme.CheckButtonIcon(), func() {
-		if r.list.onPin != nil {
-			r.list.onPin(itemID)
-		}
-	})
-	if item.Pinned {
-		pinBtn.Icon = theme.CheckButtonCheckedIcon()
+// appDisplayName drops the ".exe" of an executable name, in any case
+func appDisplayName(name string) string {
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+		return strings.TrimSuffix(name, ext)
 	}
+	return name
+}
 
-	delBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
-		if r.list.onDelete != nil {
-			r.list.onDelete(itemID)
-		}
-	})
+// sourceLinkText shortens a source URL to host and path for display
+func sourceLinkText(u *url.URL) string {
+	text := strings.TrimPrefix(u.Host, "www.") + u.Path
+	return strings.TrimSuffix(text, "/")
+}
 
-	buttons := container.NewHBox(copyBtn, pinBtn, delBtn)
+const (
+	flatPreviewMaxChars = 100 // Characters shown for flattened prose previews
+	codePreviewMaxLines = 8   // Lines shown for code previews
+	codePreviewMaxChars = 120 // Characters per code preview line
+)
 
-	cardContent := container.NewVBox(
-		content,
-		container.NewBorder(nil, nil, infoLabel, buttons),
-	)
+// buildFlatPreview collapses text into a single trimmed paragraph
+func buildFlatPreview(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", " ")
+	text = strings.ReplaceAll(text, "\n", " ")
+	text = strings.ReplaceAll(text, "\r", " ")
+	text = strings.TrimSpace(text)
+	if cut, ok := storage.CutGraphemes(text, flatPreviewMaxChars); ok {
+		text = cut + "..."
+	}
+	return text
+}
 
-	bg := canvas.NewRectangle(GetCardBackgroundColor(item.Pinned))
-	bg.CornerRadius = 8
-	bg.StrokeWidth = 1
-	bg.StrokeColor = GetCardBorderColor(item.Pinned)
+// buildCodePreview keeps the first lines verbatim, including leading tabs and spaces
+func buildCodePreview(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
 
-	card := container.NewStack(bg, container.NewPadded(cardContent))
+	// Leading blank lines carry no information in a preview
+	lines := strings.Split(strings.TrimLeft(text, "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
 
-	return card
+	truncated := false
+	if len(lines) > codePreviewMaxLines {
+		lines = lines[:codePreviewMaxLines]
+		truncated = true
+	}
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if cut, ok := storage.CutGraphemes(line, codePreviewMaxChars); ok {
+			line = cut + "…"
+		}
+		lines[i] = line
+	}
+
+	if truncated {
+		lines = append(lines, "…")
+	}
+	return strings.Join(lines, "\n")
 }
 
 // Fast thumbnail using nearest neighbor (much faster than bilinear)
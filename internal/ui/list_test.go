@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Code previews are snapshots of the text: indentation stays exactly as typed, tabs and
+// spaces mixed, and only line ends, trailing blanks and overlong parts change
+func TestBuildCodePreview(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			"mixed tabs and spaces",
+			"func main() {\n\tif ok {\n\t    run()\n  \t}\n}",
+			"func main() {\n\tif ok {\n\t    run()\n  \t}\n}",
+		},
+		{
+			"trailing blanks",
+			"a := 1 \t\n\tb := 2\t\t\n",
+			"a := 1\n\tb := 2",
+		},
+		{
+			"windows line ends and blank edges",
+			"\r\n\r\n\t\tx := 1\r\n  y := 2\r\n\r\n \t\r\n",
+			"\t\tx := 1\n  y := 2",
+		},
+		{
+			"blank lines inside kept",
+			"a\n\n\tb",
+			"a\n\n\tb",
+		},
+		{
+			"more lines than shown",
+			"1\n\t2\n  3\n\t 4\n 5\n\t\t6\n7\n8\n9\n10",
+			"1\n\t2\n  3\n\t 4\n 5\n\t\t6\n7\n8\n…",
+		},
+		{
+			"long line",
+			"\t" + strings.Repeat("ş", codePreviewMaxChars+10),
+			"\t" + strings.Repeat("ş", codePreviewMaxChars-1) + "…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildCodePreview(tt.text); got != tt.want {
+				t.Errorf("buildCodePreview(%q)\n got %q\nwant %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// Flat previews are cut by character, never inside a Turkish letter or a combined one
+func TestBuildFlatPreview(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"line ends", "  Merhaba\r\ndünya\nşimdi\r ", "Merhaba dünya şimdi"},
+		{"short", "İğne", "İğne"},
+		{
+			"cut after a two-byte letter",
+			strings.Repeat("a", flatPreviewMaxChars-1) + "ğİşç",
+			strings.Repeat("a", flatPreviewMaxChars-1) + "ğ...",
+		},
+		{
+			"cut before a combining mark",
+			strings.Repeat("ı", flatPreviewMaxChars-1) + "i\u0307" + "ş",
+			strings.Repeat("ı", flatPreviewMaxChars-1) + "i\u0307...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildFlatPreview(tt.text)
+			if got != tt.want {
+				t.Errorf("buildFlatPreview(%q)\n got %q\nwant %q", tt.text, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("preview %q isn't valid UTF-8", got)
+			}
+		})
+	}
+}
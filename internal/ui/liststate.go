@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ListState is what the clipboard list shows
+type ListState int
+
+const (
+	ListLoading   ListState = iota // Database is being read; new lists start here
+	ListItems                      // Cards for the visible items
+	ListEmpty                      // History is empty
+	ListNoMatches                  // Search or filter chips hide every item
+	ListError                      // Database failed to load, with a retry button
+)
+
+// selectListState picks the list state from the manager and filter status
+func selectListState(loading bool, loadErr error, total, visible int, filtered bool) ListState {
+	switch {
+	case loading:
+		return ListLoading
+	case loadErr != nil:
+		return ListError
+	case total == 0:
+		return ListEmpty
+	case visible == 0 && filtered:
+		return ListNoMatches
+	default:
+		return ListItems
+	}
+}
+
+// stateIllustrationSize is the pixel grid the state illustrations are drawn on
+const stateIllustrationSize = 64
+
+// Illustration colors, shared with the app icon
+var (
+	illustrationBlue  = color.RGBA{R: 59, G: 130, B: 246, A: 255}
+	illustrationDark  = color.RGBA{R: 37, G: 99, B: 235, A: 255}
+	illustrationClip  = color.RGBA{R: 75, G: 85, B: 99, A: 255}
+	illustrationLine  = color.RGBA{R: 209, G: 213, B: 219, A: 255}
+	illustrationRed   = color.RGBA{R: 220, G: 38, B: 38, A: 255}
+	illustrationWhite = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// createStateView builds the layout shown instead of cards for a non-item state
+func (r *clipboardListRenderer) createStateView(state ListState) *fyne.Container {
+	var title, hint string
+	var action fyne.CanvasObject
+
+	switch state {
+	case ListLoading:
+		title = "Yükleniyor"
+		hint = "Pano geçmişi okunuyor"
+		action = widget.NewProgressBarInfinite()
+	case ListNoMatches:
+		title = "Eşleşme yok"
+		hint = "Aramayı değiştirin veya filtreleri kaldırın"
+	case ListError:
+		title = "Pano geçmişi yüklenemedi"
+		hint = "Yeni kopyalamalar kaydedilmeye devam ediyor"
+		if r.list.stateErr != nil {
+			hint = r.list.stateErr.Error()
+		}
+		if r.list.onRetry != nil {
+			retryBtn := widget.NewButtonWithIcon("Yeniden dene", theme.ViewRefreshIcon(), r.list.onRetry)
+			retryBtn.Importance = widget.HighImportance
+			action = retryBtn
+		}
+		if isKeyMismatch(r.list.stateErr) && r.list.onRecover != nil {
+			title = "Geçmiş bu bilgisayarda açılamıyor"
+			hint = "Geçmiş başka bir anahtarla şifrelenmiş (ör. donanım değişikliğinden sonra). Kaydettiğiniz kurtarma anahtarıyla açabilirsiniz."
+			recoverBtn := widget.NewButtonWithIcon("Kurtarma anahtarı gir", theme.LoginIcon(), r.list.onRecover)
+			recoverBtn.Importance = widget.HighImportance
+			action = recoverBtn
+		}
+	default:
+		title = "Pano boş"
+		hint = "Bir şey kopyaladığınızda burada görünür"
+	}
+
+	illustration := canvas.NewImageFromImage(drawStateIllustration(state))
+	illustration.FillMode = canvas.ImageFillContain
+	illustration.ScaleMode = canvas.ImageScaleSmooth
+	illustration.SetMinSize(fyne.NewSize(stateIllustrationSize, stateIllustrationSize))
+
+	titleLabel := widget.NewLabelWithStyle(title, fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	hintLabel := widget.NewLabelWithStyle(hint, fyne.TextAlignCenter, fyne.TextStyle{})
+	hintLabel.Wrapping = fyne.TextWrapWord
+
+	view := container.NewVBox(
+		layout.NewSpacer(),
+		container.NewCenter(illustration),
+		container.NewCenter(titleLabel),
+		hintLabel,
+	)
+	if action != nil {
+		view.Add(container.NewCenter(action))
+	}
+	view.Add(layout.NewSpacer())
+	return view
+}
+
+// drawStateIllustration draws the app's clipboard with a mark for the state
+func drawStateIllustration(state ListState) *image.RGBA {
+	size := stateIllustrationSize
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	// Board, paper and clip follow the app icon
+	fillRoundedRect(img, 8, 10, 56, 58, 6, func(x, y int) color.RGBA {
+		return lerpRGBA(illustrationBlue, illustrationDark, float64(y-10)/48*0.3)
+	})
+	fillRoundedRect(img, 12, 18, 52, 54, 3, solid(illustrationWhite))
+	fillRoundedRect(img, 22, 4, 42, 14, 3, solid(illustrationClip))
+	fillRoundedRect(img, 26, 7, 38, 13, 2, solid(illustrationBlue))
+
+	switch state {
+	case ListNoMatches:
+		// Magnifier over the text lines
+		for _, line := range []struct{ y, x1, x2 int }{{24, 16, 42}, {30, 16, 48}, {36, 16, 38}} {
+			fillRoundedRect(img, line.x1, line.y, line.x2, line.y+2, 0, solid(illustrationLine))
+		}
+		fillRing(img, 34, 38, 9, 3, illustrationClip)
+		for i := 0; i < 8; i++ {
+			fillCircle(img, 41+i, 45+i, 2, illustrationClip)
+		}
+	case ListLoading:
+		for _, cx := range []int{22, 32, 42} {
+			fillCircle(img, cx, 36, 3, illustrationBlue)
+		}
+	case ListError:
+		fillCircle(img, 44, 46, 11, illustrationRed)
+		fillRoundedRect(img, 42, 38, 46, 49, 1, solid(illustrationWhite))
+		fillRoundedRect(img, 42, 51, 46, 55, 1, solid(illustrationWhite))
+	}
+	return img
+}
+
+// solid returns a fill that paints every pixel with c
+func solid(c color.RGBA) func(x, y int) color.RGBA {
+	return func(x, y int) color.RGBA { return c }
+}
+
+// fillRoundedRect paints the pixels of a rounded rectangle with fill
+func fillRoundedRect(img *image.RGBA, left, top, right, bottom int, radius float64, fill func(x, y int) color.RGBA) {
+	r := int(radius)
+	for y := top; y < bottom; y++ {
+		for x := left; x < right; x++ {
+			// Distance from the inner rectangle the corners are rounded around
+			dx := max(left+r-x, x-(right-r-1), 0)
+			dy := max(top+r-y, y-(bottom-r-1), 0)
+			if math.Hypot(float64(dx), float64(dy)) <= radius {
+				img.SetRGBA(x, y, fill(x, y))
+			}
+		}
+	}
+}
+
+// fillCircle paints a filled circle
+func fillCircle(img *image.RGBA, cx, cy, radius int, c color.RGBA) {
+	fillRing(img, cx, cy, radius, radius+1, c)
+}
+
+// fillRing paints a circle outline of the given thickness
+func fillRing(img *image.RGBA, cx, cy, radius, thickness int, c color.RGBA) {
+	for y := cy - radius; y <= cy+radius; y++ {
+		for x := cx - radius; x <= cx+radius; x++ {
+			d := math.Hypot(float64(x-cx), float64(y-cy))
+			if d <= float64(radius) && d > float64(radius-thickness) {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// lerpRGBA blends two colors, t=0 gives a and t=1 gives b
+func lerpRGBA(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t)
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: mix(a.A, b.A)}
+}
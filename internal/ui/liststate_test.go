@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+// Loading wins over everything, then a load error, then an empty history; no matches
+// is only shown while a search or chip hides every item
+func TestSelectListState(t *testing.T) {
+	failed := errors.New("okunamadı")
+	tests := []struct {
+		name     string
+		loading  bool
+		loadErr  error
+		total    int
+		visible  int
+		filtered bool
+		want     ListState
+	}{
+		{"loading with an error", true, failed, 0, 0, false, ListLoading},
+		{"loading over items", true, nil, 5, 5, false, ListLoading},
+		{"load error", false, failed, 3, 3, false, ListError},
+		{"load error while filtered", false, failed, 3, 0, true, ListError},
+		{"empty history", false, nil, 0, 0, false, ListEmpty},
+		{"empty history while filtered", false, nil, 0, 0, true, ListEmpty},
+		{"nothing matches", false, nil, 4, 0, true, ListNoMatches},
+		{"some match", false, nil, 4, 1, true, ListItems},
+		{"unfiltered", false, nil, 4, 4, false, ListItems},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectListState(tt.loading, tt.loadErr, tt.total, tt.visible, tt.filtered)
+			if got != tt.want {
+				t.Errorf("selectListState() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// The cards are rows of a widget.List, which keeps only the rows in view on the canvas
+// and reuses them while scrolling. A row is a listRowView filled with the header, card
+// or load-more button at its position (see cardrow.go); a row already showing the same
+// thing isn't filled again. Previews are made once per item and cached until the item
+// or the card settings change. widget.List needs every row's height up front, so cards
+// are measured off-screen when they are new or changed and again when the width changes.
+
+// cardHeightEstimate is the row height used before the list's width is known
+const cardHeightEstimate = 120
+
+// listRowKind is what a row of the list shows
+type listRowKind int
+
+const (
+	rowHeader listRowKind = iota // Section header
+	rowCard                      // Item card
+	rowMore                      // Load-more button below the cards
+)
+
+// listRow is a row of the list view model
+type listRow struct {
+	kind    listRowKind
+	section listSection           // Header rows
+	item    storage.ClipboardItem // Card rows
+	key     cardKey               // What the card shows
+	more    int                   // Items not rendered, for the load-more row
+}
+
+// sameRow reports whether two rows stand for the same header, card or button
+func (row listRow) sameRow(other listRow) bool {
+	if row.kind != other.kind {
+		return false
+	}
+	switch row.kind {
+	case rowHeader:
+		return row.section.kind == other.section.kind
+	case rowCard:
+		return row.item.ID == other.item.ID
+	}
+	return true
+}
+
+// unchanged reports whether a row shows exactly what other shows
+func (row listRow) unchanged(other listRow) bool {
+	if !row.sameRow(other) {
+		return false
+	}
+	switch row.kind {
+	case rowHeader:
+		return row.section.title == other.section.title && row.section.collapsed == other.section.collapsed
+	case rowCard:
+		return row.key == other.key
+	}
+	return row.more == other.more
+}
+
+// cardStyle holds the list settings every card depends on
+type cardStyle struct {
+	compact        bool
+	keepLineBreaks bool
+	dark           bool
+	pixelScale     float32
+	disabled       string // Disabled smart action IDs, sorted and joined
+}
+
+// previewKey is what an item's preview is made from
+type previewKey struct {
+	hash       string
+	class      string
+	titleCache string
+	size       int
+	protected  bool
+	style      cardStyle
+}
+
+// cardKey is everything a card shows; encrypted fields compare by ciphertext, which
+// changes with every new value
+type cardKey struct {
+	preview   previewKey
+	title     string
+	sourceURL string
+	sourceApp string
+	rtf       bool
+	original  bool
+	tags      string
+	pinned    bool
+	forced    bool
+	redacted  bool
+	corrupt   bool
+	selected  bool
+	time      string
+	fresh     freshness
+}
+
+// heightKey is what a card's height depends on besides its badges and buttons, which
+// stay on one line
+type heightKey struct {
+	preview   previewKey
+	title     string
+	sourceURL string
+	dimmed    bool
+	width     float32
+}
+
+// cardPreview is the cached preview of an item with its smart action row
+type cardPreview struct {
+	key     previewKey
+	content fyne.CanvasObject
+	smart   fyne.CanvasObject // nil without smart actions
+	more    []smartAction     // Smart actions left for the card menu
+}
+
+// textPreview is a cached decrypted text or files preview; a new hash means new content
+type textPreview struct {
+	hash    string
+	preview storage.Preview
+}
+
+// measuredHeight is a card's height for a heightKey
+type measuredHeight struct {
+	key    heightKey
+	height float32
+}
+
+// cardStyle returns the settings cards are currently made with
+func (c *ClipboardList) cardStyle() cardStyle {
+	disabled := make([]string, 0, len(c.disabledActions))
+	for id, off := range c.disabledActions {
+		if off {
+			disabled = append(disabled, id)
+		}
+	}
+	slices.Sort(disabled)
+	return cardStyle{
+		compact:        c.compact,
+		keepLineBreaks: c.keepLineBreaks,
+		dark:           IsDarkMode(),
+		pixelScale:     c.pixelScale,
+		disabled:       strings.Join(disabled, ","),
+	}
+}
+
+// cardKey returns what the card of an item shows at now
+func (r *clipboardListRenderer) cardKey(item storage.ClipboardItem, now time.Time, style cardStyle) cardKey {
+	corrupt, _ := r.list.manager.IsCorrupt(item.ID)
+	return cardKey{
+		preview: previewKey{
+			hash:       item.Hash,
+			class:      item.Class,
+			titleCache: item.TitleCache,
+			size:       item.Size,
+			protected:  item.IsProtected(),
+			style:      style,
+		},
+		title:     item.Title,
+		sourceURL: item.SourceURL,
+		sourceApp: item.SourceApp,
+		rtf:       item.RTF != "",
+		original:  item.Original != "",
+		tags:      strings.Join(item.Tags, "\x00"),
+		pinned:    item.Pinned,
+		forced:    item.Forced,
+		redacted:  item.Redacted,
+		corrupt:   corrupt,
+		selected:  r.list.selected[item.ID],
+		time:      formatTimestamp(item.Timestamp),
+		fresh:     itemFreshness(item, now, r.list.agingAfter, r.list.staleAfter),
+	}
+}
+
+// buildRows lays out the rendered pages as headers, cards and the load-more button
+func (r *clipboardListRenderer) buildRows() []listRow {
+	page := r.list.pageItems()
+	rows := make([]listRow, 0, len(page)+3)
+	now := time.Now()
+	style := r.list.cardStyle()
+	for _, section := range buildSections(page, r.list.pinnedCollapsed) {
+		if section.title != "" {
+			rows = append(rows, listRow{kind: rowHeader, section: section})
+		}
+		for _, item := range section.visibleItems() {
+			rows = append(rows, listRow{kind: rowCard, item: item, key: r.cardKey(item, now, style)})
+		}
+	}
+	if r.list.hasMore() {
+		rows = append(rows, listRow{kind: rowMore, more: r.list.total - len(page)})
+	}
+	return rows
+}
+
+// cachedPreview returns an item's preview, made again only when key changed
+func (r *clipboardListRenderer) cachedPreview(item storage.ClipboardItem, key previewKey) cardPreview {
+	if p, ok := r.previews[item.ID]; ok && p.key == key {
+		return p
+	}
+	p := r.createPreview(item)
+	p.key = key
+	r.previews[item.ID] = p
+	return p
+}
+
+// textPreview returns the decrypted preview of a text or files item, read once per content
+func (r *clipboardListRenderer) textPreview(item storage.ClipboardItem) storage.Preview {
+	if cached, ok := r.texts[item.ID]; ok && cached.hash == item.Hash {
+		return cached.preview
+	}
+	preview, err := r.list.manager.GetPreview(item.ID)
+	if err == nil {
+		r.texts[item.ID] = textPreview{hash: item.Hash, preview: preview}
+	}
+	return preview
+}
+
+// pruneCaches forgets the previews and heights of items no longer on a row
+func (r *clipboardListRenderer) pruneCaches() {
+	onRow := make(map[string]bool, len(r.rows))
+	for _, row := range r.rows {
+		if row.kind == rowCard {
+			onRow[row.item.ID] = true
+		}
+	}
+	for id := range r.previews {
+		if !onRow[id] {
+			delete(r.previews, id)
+		}
+	}
+	for id := range r.texts {
+		if !onRow[id] {
+			delete(r.texts, id)
+		}
+	}
+	for id := range r.heights {
+		if !onRow[id] {
+			delete(r.heights, id)
+		}
+	}
+	for id := range r.shown {
+		if !onRow[id] {
+			delete(r.shown, id)
+		}
+	}
+}
+
+// rowHeight returns the height of a row at the current width, measuring new cards
+func (r *clipboardListRenderer) rowHeight(row listRow) float32 {
+	if r.width <= 0 {
+		return cardHeightEstimate
+	}
+	if row.kind != rowCard {
+		return r.measure.measure(row, r.width)
+	}
+	key := heightKey{
+		preview:   row.key.preview,
+		title:     row.key.title,
+		sourceURL: row.key.sourceURL,
+		dimmed:    row.key.fresh != freshnessFresh,
+		width:     r.width,
+	}
+	if m, ok := r.heights[row.item.ID]; ok && m.key == key {
+		return m.height
+	}
+	height := r.measure.measure(row, r.width)
+	r.heights[row.item.ID] = measuredHeight{key: key, height: height}
+	return height
+}
+
+// setHeights gives the view the height of every row
+// Each changed height refreshes the view, so filling rows waits for the last one
+func (r *clipboardListRenderer) setHeights() {
+	heights := make([]float32, len(r.rows))
+	for i, row := range r.rows {
+		heights[i] = r.rowHeight(row)
+	}
+	r.filling = true
+	r.list.view.setHeights(heights)
+	r.filling = false
+}
+
+// updateRow fills a recycled row for the row at id
+func (r *clipboardListRenderer) updateRow(id widget.ListItemID, obj fyne.CanvasObject) {
+	if r.filling || id >= len(r.rows) {
+		return
+	}
+	view := obj.(*listRowView)
+	row := r.rows[id]
+	view.show(row)
+	if row.kind == rowCard {
+		r.shown[row.item.ID] = view
+	}
+}
+
+// cardListView is the widget.List of the rows; it reports scrolling, which List keeps
+// to itself, and remembers the row heights it was given
+type cardListView struct {
+	widget.List
+	heights    []float32
+	onScrolled func(offset fyne.Position)
+}
+
+// newCardListView creates the list without separators; cards are spaced by padding
+func newCardListView() *cardListView {
+	v := &cardListView{}
+	v.List.HideSeparators = true
+	v.ExtendBaseWidget(v)
+	return v
+}
+
+// CreateRenderer hooks into the scroll the list follows its offset through
+func (v *cardListView) CreateRenderer() fyne.WidgetRenderer {
+	renderer := v.List.CreateRenderer()
+	if scroll, ok := renderer.Objects()[0].(*container.Scroll); ok {
+		follow := scroll.OnScrolled
+		scroll.OnScrolled = func(offset fyne.Position) {
+			follow(offset)
+			if v.onScrolled != nil {
+				v.onScrolled(offset)
+			}
+		}
+	}
+	return renderer
+}
+
+// setHeights sets the height of every row; unchanged heights cost nothing
+func (v *cardListView) setHeights(heights []float32) {
+	v.heights = heights
+	for id, height := range heights {
+		v.List.SetItemHeight(id, height)
+	}
+}
+
+// contentHeight returns the height of all rows together
+func (v *cardListView) contentHeight() float32 {
+	if len(v.heights) == 0 {
+		return 0
+	}
+	padding := v.List.Theme().Size(theme.SizeNamePadding)
+	return stackedOffset(v.heights, len(v.heights), padding) - padding
+}
+
+// stackedOffset returns the top of row id among rows of heights spaced by padding
+func stackedOffset(heights []float32, id int, padding float32) float32 {
+	offset := float32(0)
+	for _, height := range heights[:min(id, len(heights))] {
+		offset += height + padding
+	}
+	return offset
+}
+
+// ScrollOffset returns how far the cards are scrolled down
+func (c *ClipboardList) ScrollOffset() float32 {
+	return c.view.List.GetScrollOffset()
+}
+
+// ScrollToOffset scrolls the cards; unlike scrolling by the user it doesn't notify
+func (c *ClipboardList) ScrollToOffset(offset float32) {
+	c.view.List.ScrollToOffset(offset)
+}
+
+// ContentHeight returns the height of all rendered cards, headers included
+func (c *ClipboardList) ContentHeight() float32 {
+	return c.view.contentHeight()
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+const (
+	longLineMinBytes  = 64 * 1024 // Texts at least this long without a line break get a summary preview
+	longLinePeekChars = 200       // Characters of a long line shown under its summary
+	viewerRowChars    = 160       // Long lines are split into rows of this width in the viewer
+)
+
+// isLongSingleLine reports whether a text of size bytes is long and its preview head has
+// no line break. Minified bundles and JSON blobs freeze word-wrapping labels, so they
+// get a summary instead
+func isLongSingleLine(size int, head string) bool {
+	return size >= longLineMinBytes && !strings.Contains(head, "\n")
+}
+
+// longLineSummary describes a long single line, e.g. "2.1 MB tek satır, JS benzeri içerik"
+func longLineSummary(size int, head string) string {
+	return fmt.Sprintf("%s tek satır, %s", formatSize(size), guessContentKind(head))
+}
+
+// guessContentKind names the likely format of a text from its beginning
+func guessContentKind(head string) string {
+	trimmed := strings.TrimSpace(head)
+	switch {
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		return "JSON benzeri içerik"
+	case strings.HasPrefix(trimmed, "<"):
+		return "HTML/XML benzeri içerik"
+	}
+	for _, marker := range []string{"function", "=>", "var ", "const ", "let ", "use strict", "require("} {
+		if strings.Contains(trimmed, marker) {
+			return "JS benzeri içerik"
+		}
+	}
+	return "metin"
+}
+
+// createLongLinePreview shows the summary and a single truncated line
+func createLongLinePreview(size int, head string) fyne.CanvasObject {
+	summary := widget.NewLabelWithStyle(longLineSummary(size, head), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	peek := head
+	if cut, ok := storage.CutGraphemes(peek, longLinePeekChars); ok {
+		peek = cut + "…"
+	}
+	peekLabel := widget.NewLabelWithStyle(peek, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	peekLabel.Truncation = fyne.TextTruncateEllipsis
+
+	return container.NewVBox(summary, peekLabel)
+}
+
+// splitViewerRows splits text into display rows, breaking long lines every viewerRowChars
+// characters (grapheme clusters, so an emoji or accented letter stays on one row)
+func splitViewerRows(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	rows := make([]string, 0, len(text)/viewerRowChars+1)
+	for _, line := range strings.Split(text, "\n") {
+		if len(line) <= viewerRowChars {
+			rows = append(rows, line)
+			continue
+		}
+		rows = append(rows, storage.SplitGraphemes(line, viewerRowChars)...)
+	}
+	return rows
+}
+
+// showContentViewer shows a text item's full content in a virtualized read-only list
+// Rows are prepared off the UI thread so multi-megabyte items don't block rendering
+func (a *App) showContentViewer(id string) {
+	progress := dialog.NewCustomWithoutButtons("İçerik", widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+
+	go func() {
+		data, err := a.manager.GetItemContent(id)
+		if err != nil {
+			fyne.Do(func() {
+				progress.Hide()
+				dialog.ShowError(err, a.window)
+			})
+			return
+		}
+		rows := splitViewerRows(string(data))
+		storage.Zero(data)
+
+		fyne.Do(func() {
+			progress.Hide()
+
+			list := widget.NewList(
+				func() int { return len(rows) },
+				func() fyne.CanvasObject {
+					return widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+				},
+				func(i widget.ListItemID, obj fyne.CanvasObject) {
+					obj.(*widget.Label).SetText(rows[i])
+				},
+			)
+
+			viewer := dialog.NewCustom("İçerik", "Kapat", list, a.window)
+			viewer.Resize(fyne.NewSize(640, 480))
+			viewer.Show()
+		})
+	}()
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestLongLineSummary checks which texts get a summary and what it says about them
+func TestLongLineSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		head string
+		long bool
+		want string
+	}{
+		{"minified JS", 2202009, `!function(e){"use strict";var t=1}`, true, "2.1 MB tek satır, JS benzeri içerik"},
+		{"JSON blob", longLineMinBytes, `  [{"id":1},{"id":2}]`, true, "64.0 KB tek satır, JSON benzeri içerik"},
+		{"markup", 100000, `<svg xmlns="http://www.w3.org/2000/svg">`, true, "97.7 KB tek satır, HTML/XML benzeri içerik"},
+		{"plain", 70000, "aaaa aaaa aaaa", true, "68.4 KB tek satır, metin"},
+		{"short", longLineMinBytes - 1, "{}", false, ""},
+		{"has line breaks", 1 << 20, "{\n  \"a\": 1\n}", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLongSingleLine(tt.size, tt.head); got != tt.long {
+				t.Fatalf("isLongSingleLine = %v, want %v", got, tt.long)
+			}
+			if !tt.long {
+				return
+			}
+			if got := longLineSummary(tt.size, tt.head); got != tt.want {
+				t.Errorf("summary is %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSplitViewerRows breaks long lines into rows of viewerRowChars characters without
+// splitting a character, and keeps short lines and empty ones as they are
+func TestSplitViewerRows(t *testing.T) {
+	rows := splitViewerRows("kısa\r\n\r\n" + strings.Repeat("ğ", viewerRowChars*2+5) + "\nson")
+	if len(rows) != 6 {
+		t.Fatalf("%d rows, want 6: %q", len(rows), rows)
+	}
+	if !slices.Equal([]string{rows[0], rows[1], rows[5]}, []string{"kısa", "", "son"}) {
+		t.Errorf("short lines became %q", []string{rows[0], rows[1], rows[5]})
+	}
+	for i, want := range []int{viewerRowChars, viewerRowChars, 5} {
+		row := rows[2+i]
+		if !utf8.ValidString(row) || utf8.RuneCountInString(row) != want {
+			t.Errorf("row %d has %d characters, want %d", 2+i, utf8.RuneCountInString(row), want)
+		}
+	}
+
+	// An emoji with a skin tone is one character and stays on one row
+	emoji := "👍🏽"
+	rows = splitViewerRows(strings.Repeat("a", viewerRowChars-1) + emoji + "b")
+	if len(rows) != 2 || !strings.HasSuffix(rows[0], emoji) || rows[1] != "b" {
+		t.Errorf("rows around an emoji are %q", rows)
+	}
+}
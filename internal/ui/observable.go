@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"pano/internal/clipboard"
+	"pano/internal/settings"
+	"pano/internal/storage"
+	"pano/internal/system"
+)
+
+// settingsModel wraps the settings store and notifies subscribers per key
+// Both the main window and the settings window bind to it, so a change made in
+// one shows up in the other (and in the tray) without reopening anything
+type settingsModel struct {
+	settings.Settings
+
+	mu     sync.Mutex
+	nextID int
+	subs   map[string]map[int]func()
+}
+
+// newSettingsModel wraps store
+func newSettingsModel(store settings.Settings) *settingsModel {
+	return &settingsModel{
+		Settings: store,
+		subs:     make(map[string]map[int]func()),
+	}
+}
+
+// Subscribe calls fn after every change of key and returns a function that removes it
+// Windows must call the returned function when they close
+func (m *settingsModel) Subscribe(key string, fn func()) (unsubscribe func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	id := m.nextID
+	if m.subs[key] == nil {
+		m.subs[key] = make(map[int]func())
+	}
+	m.subs[key][id] = fn
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			delete(m.subs[key], id)
+			if len(m.subs[key]) == 0 {
+				delete(m.subs, key)
+			}
+		})
+	}
+}
+
+// subscriberCount returns the number of subscriptions for key
+func (m *settingsModel) subscriberCount(key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.subs[key])
+}
+
+// notify runs the subscribers of key outside the lock, so they may subscribe or unsubscribe
+func (m *settingsModel) notify(key string) {
+	m.mu.Lock()
+	fns := make([]func(), 0, len(m.subs[key]))
+	for _, fn := range m.subs[key] {
+		fns = append(fns, fn)
+	}
+	m.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// SetBool stores value and notifies subscribers if it changed
+func (m *settingsModel) SetBool(key string, value bool) {
+	if m.Settings.BoolWithFallback(key, !value) == value {
+		return
+	}
+	m.Settings.SetBool(key, value)
+	m.notify(key)
+}
+
+// SetInt stores value and notifies subscribers if it changed
+func (m *settingsModel) SetInt(key string, value int) {
+	if m.Settings.IntWithFallback(key, value+1) == value {
+		return
+	}
+	m.Settings.SetInt(key, value)
+	m.notify(key)
+}
+
+// SetString stores value and notifies subscribers if it changed
+func (m *settingsModel) SetString(key string, value string) {
+	if m.Settings.StringWithFallback(key, value+"\x00") == value {
+		return
+	}
+	m.Settings.SetString(key, value)
+	m.notify(key)
+}
+
+// bindSettings makes the main window, manager and tray follow settings changes
+// These subscriptions live as long as the app; the main window only hides
+func (a *App) bindSettings() {
+	s := a.settings
+
+	s.Subscribe("dark_mode", func() {
+		a.isDarkMode = s.BoolWithFallback("dark_mode", true)
+		if a.isDarkMode {
+			a.fyneApp.Settings().SetTheme(NewDarkTheme())
+		} else {
+			a.fyneApp.Settings().SetTheme(NewLightTheme())
+		}
+		a.list.Refresh()
+	})
+	s.Subscribe("dock_sidebar", a.applyDock)
+	s.Subscribe("selection_capture", a.applySelection)
+	s.Subscribe("excluded_apps", a.applySelection)
+	s.Subscribe("keep_line_breaks", func() {
+		a.list.SetKeepLineBreaks(s.BoolWithFallback("keep_line_breaks", false))
+		a.list.Refresh()
+	})
+	s.Subscribe("retention_days", func() {
+		go a.pruneExpired(time.Now())
+	})
+	for _, key := range []string{"aging_days", "stale_days"} {
+		s.Subscribe(key, func() {
+			a.applyFreshness()
+			a.list.Refresh()
+		})
+	}
+	s.Subscribe("disabled_smart_actions", func() {
+		a.list.SetDisabledActions(a.disabledSmartActions())
+		a.list.Refresh()
+	})
+	s.Subscribe("pinned_collapsed", func() {
+		a.list.SetPinnedCollapsed(s.BoolWithFallback("pinned_collapsed", false))
+		a.list.Refresh()
+	})
+	s.Subscribe("newline_mode", func() {
+		mode := s.StringWithFallback("newline_mode", *a.config.NewlineMode)
+		a.config.NewlineMode = &mode
+		a.manager.SetNewlineMode(clipboard.NewlineMode(mode))
+	})
+	s.Subscribe("max_items", func() {
+		limit := s.IntWithFallback("max_items", *a.config.MaxItems)
+		a.config.MaxItems = &limit
+		a.manager.SetMaxItems(limit)
+		a.list.Refresh()
+		a.updateStatus()
+		a.refreshTray()
+	})
+	s.Subscribe("max_total_mb", func() {
+		mb := s.IntWithFallback("max_total_mb", *a.config.MaxTotalMB)
+		a.config.MaxTotalMB = &mb
+		a.manager.SetMaxTotalSize(int64(mb) * 1024 * 1024)
+		a.list.Refresh()
+		a.updateStatus()
+		a.refreshTray()
+	})
+	s.Subscribe("limit_policy", func() {
+		policy := s.StringWithFallback("limit_policy", *a.config.LimitPolicy)
+		a.config.LimitPolicy = &policy
+		a.manager.SetLimitPolicy(storage.LimitPolicy(policy))
+	})
+	s.Subscribe("dedup_mode", func() {
+		mode := s.StringWithFallback("dedup_mode", *a.config.DedupMode)
+		a.config.DedupMode = &mode
+		a.manager.SetDedupMode(storage.DedupMode(mode))
+	})
+	s.Subscribe("pin_limit", func() {
+		limit := s.IntWithFallback("pin_limit", *a.config.PinLimit)
+		a.config.PinLimit = &limit
+		a.manager.SetPinLimit(limit)
+	})
+	s.Subscribe("double_copy_pin_ms", func() {
+		ms := s.IntWithFallback("double_copy_pin_ms", *a.config.DoubleCopyPinMs)
+		a.config.DoubleCopyPinMs = &ms
+		a.monitor.SetDoubleCopyWindow(time.Duration(ms) * time.Millisecond)
+	})
+	s.Subscribe("poll_interval_ms", func() {
+		ms := s.IntWithFallback("poll_interval_ms", *a.config.PollIntervalMs)
+		a.config.PollIntervalMs = &ms
+		a.monitor.SetPollInterval(time.Duration(ms) * time.Millisecond)
+	})
+	s.Subscribe("grace_minutes", func() {
+		minutes := s.IntWithFallback("grace_minutes", *a.config.GraceMinutes)
+		a.config.GraceMinutes = &minutes
+		a.manager.SetGraceWindow(time.Duration(minutes) * time.Minute)
+		a.list.Refresh()
+		a.updateStatus()
+	})
+	s.Subscribe("delta_images", func() {
+		enabled := s.BoolWithFallback("delta_images", *a.config.DeltaImages)
+		a.config.DeltaImages = &enabled
+		a.manager.SetDeltaImages(enabled)
+	})
+	s.Subscribe("archive_enabled", func() {
+		enabled := s.BoolWithFallback("archive_enabled", *a.config.ArchiveEnabled)
+		a.config.ArchiveEnabled = &enabled
+		a.manager.SetArchiveEnabled(enabled)
+	})
+	s.Subscribe("archive_max_mb", func() {
+		mb := s.IntWithFallback("archive_max_mb", *a.config.ArchiveMaxMB)
+		a.config.ArchiveMaxMB = &mb
+		a.manager.SetArchiveMaxBytes(int64(mb) * 1024 * 1024)
+	})
+
+	urlCleaning := func() {
+		enabled := s.BoolWithFallback("strip_tracking", *a.config.StripTracking)
+		params := parseParamList(s.StringWithFallback("tracking_params", strings.Join(a.config.TrackingParams, ", ")))
+		a.config.StripTracking = &enabled
+		a.config.TrackingParams = params
+		a.manager.SetURLCleaning(enabled, params)
+	}
+	s.Subscribe("strip_tracking", urlCleaning)
+	s.Subscribe("tracking_params", urlCleaning)
+
+	s.Subscribe("capture_excluded_apps", func() {
+		apps := parseParamList(s.StringWithFallback("capture_excluded_apps", strings.Join(a.config.CaptureExcludedApps, ", ")))
+		a.config.CaptureExcludedApps = apps
+		a.monitor.SetExcludedApps(apps)
+	})
+
+	s.Subscribe("redaction_rules", func() {
+		if err := a.applyRedactionRules(); err != nil {
+			log.Printf("Warning: Ignoring redaction rules: %v", err)
+		}
+	})
+
+	s.Subscribe("capture_key", func() {
+		if a.hotkeys == nil {
+			return
+		}
+		if err := a.hotkeys.SetCaptureKey(s.StringWithFallback("capture_key", system.DefaultCaptureKey)); err != nil {
+			log.Printf("Warning: Failed to set capture hotkey: %v", err)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package ui
+
+import "testing"
+
+// TestSettingsModel stands in for the main window and the settings window bound to the
+// same key: a change made through one reaches both, unchanged values notify nobody, and
+// a closed window stops hearing about changes
+func TestSettingsModel(t *testing.T) {
+	m := newSettingsModel(newMemSettings())
+	var mainSeen, settingsSeen []int
+	m.Subscribe("max_items", func() { mainSeen = append(mainSeen, m.IntWithFallback("max_items", 0)) })
+	closeSettings := m.Subscribe("max_items", func() {
+		settingsSeen = append(settingsSeen, m.IntWithFallback("max_items", 0))
+	})
+
+	m.SetInt("max_items", 100)
+	m.SetInt("max_items", 100) // Unchanged
+	m.SetBool("paused", true)  // Another key
+	if len(mainSeen) != 1 || mainSeen[0] != 100 || len(settingsSeen) != 1 || settingsSeen[0] != 100 {
+		t.Errorf("main window saw %v and settings window %v, want [100] each", mainSeen, settingsSeen)
+	}
+
+	closeSettings()
+	closeSettings() // Closing twice is harmless
+	m.SetInt("max_items", 200)
+	if len(settingsSeen) != 1 {
+		t.Errorf("the closed settings window saw %v", settingsSeen)
+	}
+	if len(mainSeen) != 2 || m.subscriberCount("max_items") != 1 {
+		t.Errorf("main window saw %v with %d subscribers", mainSeen, m.subscriberCount("max_items"))
+	}
+}
+
+// The first write of a key notifies even when it equals what a fallback would return,
+// and strings and bools compare by value
+func TestSettingsModelFirstWrite(t *testing.T) {
+	m := newSettingsModel(newMemSettings())
+	notified := 0
+	for _, key := range []string{"dark_mode", "theme", "max_items"} {
+		m.Subscribe(key, func() { notified++ })
+	}
+	m.SetBool("dark_mode", false)
+	m.SetString("theme", "")
+	m.SetInt("max_items", 0)
+	if notified != 3 {
+		t.Errorf("%d notifications for three first writes, want 3", notified)
+	}
+	m.SetBool("dark_mode", false)
+	m.SetString("theme", "")
+	m.SetInt("max_items", 0)
+	if notified != 3 {
+		t.Errorf("%d notifications after rewriting the same values, want 3", notified)
+	}
+}
+
+// A subscriber may unsubscribe itself, or subscribe another, while being notified
+func TestSettingsModelReentrant(t *testing.T) {
+	m := newSettingsModel(newMemSettings())
+	var unsubscribe func()
+	calls := 0
+	unsubscribe = m.Subscribe("dock_side", func() {
+		calls++
+		unsubscribe()
+		m.Subscribe("dock_side", func() {})
+	})
+	m.SetString("dock_side", "left")
+	m.SetString("dock_side", "right")
+	if calls != 1 {
+		t.Errorf("the self-removing subscriber ran %d times, want 1", calls)
+	}
+	if n := m.subscriberCount("dock_side"); n != 1 {
+		t.Errorf("%d subscribers, want the one added during the first change", n)
+	}
+}
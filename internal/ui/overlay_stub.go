@@ -0,0 +1,7 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+// keepOverlayOnTop is a no-op on non-Windows platforms
+func keepOverlayOnTop(uintptr) {}
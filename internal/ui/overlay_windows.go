@@ -0,0 +1,41 @@
+//go:build windows
+// +build windows
+
+package ui
+
+import "unsafe"
+
+var (
+	procSetWindowPos          = user32.NewProc("SetWindowPos")
+	procGetWindowRect         = user32.NewProc("GetWindowRect")
+	procSystemParametersInfoW = user32.NewProc("SystemParametersInfoW")
+)
+
+const (
+	hwndTopmost     = ^uintptr(0) // HWND_TOPMOST, (HWND)-1
+	swpNoSize       = 0x0001
+	swpNoActivate   = 0x0010
+	spiGetWorkArea  = 0x0030
+	overlayMarginPx = 16
+)
+
+// rect mirrors the Win32 RECT
+type rect struct {
+	Left, Top, Right, Bottom int32
+}
+
+// keepOverlayOnTop makes the paste stack overlay, window hwnd, topmost in the bottom right
+// corner of the work area, without taking focus from the app being pasted into
+// Fyne has no API for either
+func keepOverlayOnTop(hwnd uintptr) {
+	if hwnd == 0 {
+		return
+	}
+
+	var work, win rect
+	procSystemParametersInfoW.Call(spiGetWorkArea, 0, uintptr(unsafe.Pointer(&work)), 0)
+	procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&win)))
+	x := work.Right - (win.Right - win.Left) - overlayMarginPx
+	y := work.Bottom - (win.Bottom - win.Top) - overlayMarginPx
+	procSetWindowPos.Call(hwnd, hwndTopmost, uintptr(x), uintptr(y), 0, 0, swpNoSize|swpNoActivate)
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+
+	"pano/internal/storage"
+)
+
+// Large histories are rendered a page at a time: the list starts with listPageSize
+// cards and adds the next page when the scroll nears the end or the "Daha fazla yükle"
+// button below the cards is pressed. Without a query, filter or custom order only the
+// loaded pages are read from the database; otherwise every item is matched and only
+// the rendering is paged. A new query, filter or order starts again at the first page.
+
+const (
+	listPageSize = 50
+
+	// loadMoreMargin is how close to the end of the cards scrolling loads the next page
+	loadMoreMargin = 400
+)
+
+// refreshPage reads the loaded pages when the list shows the history as stored
+// Returns false when a query, filter or custom order needs every item
+func (c *ClipboardList) refreshPage() bool {
+	if strings.TrimSpace(c.query) != "" || c.filter != nil || c.sortOrder == sortMostUsed {
+		return false
+	}
+	// Read before the page, so a change in between only makes the page newer than seq
+	seq := c.manager.Seq()
+	if seq < c.seq {
+		return true
+	}
+	items, total := c.manager.GetItems(0, c.limit)
+	c.seq = seq
+	c.searchErr = nil
+	c.items = items
+	c.total = total
+	c.pruneSelection()
+	c.BaseWidget.Refresh()
+	return true
+}
+
+// pageItems returns the items whose cards are rendered
+func (c *ClipboardList) pageItems() []storage.ClipboardItem {
+	return c.items[:min(c.limit, len(c.items))]
+}
+
+// hasMore reports whether items beyond the rendered pages match
+func (c *ClipboardList) hasMore() bool {
+	return c.total > len(c.pageItems())
+}
+
+// resetPaging goes back to the first page
+func (c *ClipboardList) resetPaging() {
+	c.limit = listPageSize
+}
+
+// LoadMore renders the next page of cards
+func (c *ClipboardList) LoadMore() {
+	if !c.hasMore() {
+		return
+	}
+	c.limit += listPageSize
+	c.Refresh()
+}
+
+// ShowAll renders every matching item, e.g. before jumping to the oldest one
+func (c *ClipboardList) ShowAll() {
+	if !c.hasMore() {
+		return
+	}
+	c.limit = c.total
+	c.Refresh()
+}
+
+// onScrolled loads the next page once the end of the cards comes near and passes the
+// offset on to the jump pill
+func (c *ClipboardList) onScrolled(offset fyne.Position) {
+	if c.hasMore() && offset.Y+c.BaseWidget.Size().Height >= c.ContentHeight()-loadMoreMargin {
+		c.LoadMore()
+	}
+	if c.onScroll != nil {
+		c.onScroll(c.ScrollOffset())
+	}
+}
+
+// loadMoreText labels the load-more button with the number of items not rendered
+func loadMoreText(remaining int) string {
+	return fmt.Sprintf("Daha fazla yükle (%d kaldı)", remaining)
+}
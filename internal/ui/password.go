@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// ShowUnlockWindow asks for the master password before anything else starts
+// open runs off the UI thread with each password entered; storage.ErrWrongPassword
+// asks again. Once it succeeds the window closes, then opened runs on the UI thread.
+// Closing the window quits the app
+func ShowUnlockWindow(fyneApp fyne.App, open func(password string) error, opened func()) {
+	w := fyneApp.NewWindow("Pano")
+	w.SetCloseIntercept(fyneApp.Quit)
+
+	entry := widget.NewPasswordEntry()
+	entry.SetPlaceHolder("Ana parola")
+	status := widget.NewLabel("Geçmiş ana parolayla kilitli.")
+	status.Wrapping = fyne.TextWrapWord
+
+	var unlockBtn *widget.Button
+	submit := func() {
+		if entry.Text == "" {
+			return
+		}
+		password := entry.Text
+		entry.Disable()
+		unlockBtn.Disable()
+		status.SetText("Açılıyor…")
+		go func() {
+			err := open(password)
+			fyne.Do(func() {
+				if err == nil {
+					// Closed first, so opened never sees this window; Fyne destroys it on its
+					// next loop, when the app window exists and keeps the app running
+					w.Close()
+					opened()
+					return
+				}
+				entry.Enable()
+				unlockBtn.Enable()
+				entry.SetText("")
+				w.Canvas().Focus(entry)
+				if errors.Is(err, storage.ErrWrongPassword) {
+					status.SetText("Parola yanlış.")
+				} else {
+					status.SetText("Geçmiş açılamadı: " + err.Error())
+				}
+			})
+		}()
+	}
+	unlockBtn = widget.NewButton("Aç", submit)
+	unlockBtn.Importance = widget.HighImportance
+	entry.OnSubmitted = func(string) { submit() }
+
+	w.SetContent(container.NewPadded(container.NewVBox(status, entry, unlockBtn)))
+	w.Resize(fyne.NewSize(340, 160))
+	w.CenterOnScreen()
+	w.Show()
+	w.Canvas().Focus(entry)
+}
+
+// showMasterPassword sets the master password, or changes or removes the one in place
+func (a *App) showMasterPassword() {
+	if !a.manager.HasPassword() {
+		a.showSetPassword()
+		return
+	}
+	hint := widget.NewLabel("Geçmiş ana parolayla kilitli; Pano her açılışta parolayı sorar.")
+	hint.Wrapping = fyne.TextWrapWord
+	var d dialog.Dialog
+	changeBtn := widget.NewButton("Parolayı değiştir", func() {
+		d.Hide()
+		a.showChangePassword()
+	})
+	removeBtn := widget.NewButton("Parolayı kaldır", func() {
+		d.Hide()
+		a.showRemovePassword()
+	})
+	removeBtn.Importance = widget.DangerImportance
+	d = dialog.NewCustom("Ana parola", "Kapat", container.NewVBox(hint, changeBtn, removeBtn), a.window)
+	d.Resize(fyne.NewSize(400, 200))
+	d.Show()
+}
+
+// showSetPassword locks the history with a new master password; the re-key it takes
+// runs behind a progress dialog
+func (a *App) showSetPassword() {
+	password := widget.NewPasswordEntry()
+	password.SetPlaceHolder("Ana parola")
+	confirm := widget.NewPasswordEntry()
+	confirm.SetPlaceHolder("Ana parola (tekrar)")
+	hint := widget.NewLabel("Geçmiş bu bilgisayarın anahtarına ek olarak bu parolayla şifrelenir ve Pano her açılışta " +
+		"parolayı sorar. Parola unutulursa geçmiş açılamaz; kurtarma anahtarı da parolayı gerektirir.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustomConfirm("Ana parola belirle", "Belirle", "İptal", container.NewVBox(hint, password, confirm), func(ok bool) {
+		if !ok {
+			return
+		}
+		if password.Text == "" {
+			dialog.ShowInformation("Ana parola", "Parola boş olamaz.", a.window)
+			return
+		}
+		if password.Text != confirm.Text {
+			dialog.ShowInformation("Ana parola", "Parolalar eşleşmiyor.", a.window)
+			return
+		}
+		text := password.Text
+
+		bar := widget.NewProgressBar()
+		progress := dialog.NewCustomWithoutButtons("Geçmiş şifreleniyor", bar, a.window)
+		progress.Resize(fyne.NewSize(360, 100))
+		progress.Show()
+		go func() {
+			err := a.manager.SetPassword(text, func(done, total int) {
+				fyne.Do(func() {
+					bar.SetValue(float64(done) / float64(total))
+				})
+			})
+			fyne.Do(func() {
+				progress.Hide()
+				if err != nil {
+					dialog.ShowError(err, a.window)
+					return
+				}
+				a.showToast("Ana parola belirlendi")
+			})
+		}()
+	}, a.window)
+	d.Resize(fyne.NewSize(420, 260))
+	d.Show()
+	a.window.Canvas().Focus(password)
+}
+
+// showChangePassword replaces the master password after asking for the current one
+func (a *App) showChangePassword() {
+	current := widget.NewPasswordEntry()
+	current.SetPlaceHolder("Şu anki parola")
+	password := widget.NewPasswordEntry()
+	password.SetPlaceHolder("Yeni parola")
+	confirm := widget.NewPasswordEntry()
+	confirm.SetPlaceHolder("Yeni parola (tekrar)")
+
+	d := dialog.NewCustomConfirm("Ana parolayı değiştir", "Değiştir", "İptal", container.NewVBox(current, password, confirm), func(ok bool) {
+		if !ok {
+			return
+		}
+		if password.Text == "" {
+			dialog.ShowInformation("Ana parola", "Parola boş olamaz.", a.window)
+			return
+		}
+		if password.Text != confirm.Text {
+			dialog.ShowInformation("Ana parola", "Parolalar eşleşmiyor.", a.window)
+			return
+		}
+		old, text := current.Text, password.Text
+		go func() {
+			err := a.manager.ChangePassword(old, text)
+			fyne.Do(func() {
+				if err != nil {
+					a.showPasswordError(err)
+					return
+				}
+				a.showToast("Ana parola değiştirildi")
+			})
+		}()
+	}, a.window)
+	d.Resize(fyne.NewSize(380, 220))
+	d.Show()
+	a.window.Canvas().Focus(current)
+}
+
+// showRemovePassword removes the master password once it is entered
+func (a *App) showRemovePassword() {
+	entry := widget.NewPasswordEntry()
+	entry.SetPlaceHolder("Ana parola")
+	hint := widget.NewLabel("Geçmiş yeniden yalnızca bu bilgisayarın anahtarıyla açılacak.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustomConfirm("Ana parolayı kaldır", "Kaldır", "İptal", container.NewVBox(hint, entry), func(ok bool) {
+		if !ok {
+			return
+		}
+		text := entry.Text
+		go func() {
+			err := a.manager.RemovePassword(text)
+			fyne.Do(func() {
+				if err != nil {
+					a.showPasswordError(err)
+					return
+				}
+				a.showToast("Ana parola kaldırıldı")
+			})
+		}()
+	}, a.window)
+	d.Resize(fyne.NewSize(380, 180))
+	d.Show()
+	a.window.Canvas().Focus(entry)
+}
+
+// showPasswordError explains a refused master password
+func (a *App) showPasswordError(err error) {
+	if errors.Is(err, storage.ErrWrongPassword) {
+		dialog.ShowInformation("Ana parola", "Parola yanlış.", a.window)
+		return
+	}
+	dialog.ShowError(err, a.window)
+}
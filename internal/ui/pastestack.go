@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/clipboard"
+)
+
+// A paste is only seen as a Ctrl+V key press; the target app reads the clipboard a moment
+// later. The next item goes on the clipboard after pasteSettleDelay, and only if the
+// clipboard still holds the queued item, so a Ctrl+V after copying something else ends
+// the queue instead of overwriting the new copy.
+const pasteSettleDelay = 300 * time.Millisecond
+
+// pasteOverlay is the small frameless window that shows the queue position
+type pasteOverlay struct {
+	window fyne.Window
+	label  *widget.Label
+}
+
+// queueSelected starts a paste stack with the multi-selected items, in list order
+func (a *App) queueSelected() {
+	ids := a.list.Selected()
+	if len(ids) == 0 {
+		return
+	}
+	state, err := a.manager.StartPasteStack(ids)
+	a.list.ClearSelection()
+	if err != nil {
+		a.showToast("Sıraya alınamadı: " + err.Error())
+		return
+	}
+	a.rememberStackSequence()
+	a.showPasteStack(state)
+	a.showToast(fmt.Sprintf("%d öğe sıraya alındı", state.Total))
+}
+
+// onPaste advances the queue after a Ctrl+V; runs on the hook goroutine
+func (a *App) onPaste() {
+	if !a.manager.GetPasteStack().Active() {
+		return
+	}
+
+	// Key repeat sends several Ctrl+V presses for one held key
+	a.stackMu.Lock()
+	if a.stackPending {
+		a.stackMu.Unlock()
+		return
+	}
+	a.stackPending = true
+	a.stackMu.Unlock()
+
+	time.Sleep(pasteSettleDelay)
+
+	a.stackMu.Lock()
+	a.stackPending = false
+	expected, tracked := a.stackSeq, a.stackHasSeq
+	a.stackMu.Unlock()
+
+	if seq, ok := a.monitor.Sequence(); ok && tracked && seq != expected {
+		a.clearPasteStack()
+		a.showToast("Pano değişti, sıra bitirildi")
+		return
+	}
+	a.advancePasteStack()
+}
+
+// advancePasteStack puts the next queued item on the clipboard; also the "sıradaki" hotkey
+func (a *App) advancePasteStack() {
+	if !a.manager.GetPasteStack().Active() {
+		return
+	}
+	state, err := a.manager.AdvancePasteStack()
+	if err != nil {
+		a.showToast("Sıradaki öğe kopyalanamadı: " + err.Error())
+	}
+	if state.Active() {
+		a.rememberStackSequence()
+	} else if err == nil {
+		a.showToast("Sıra tamamlandı")
+	}
+	a.showPasteStack(state)
+}
+
+// clearPasteStack ends the queue and hides the overlay
+func (a *App) clearPasteStack() {
+	a.manager.ClearPasteStack()
+	a.showPasteStack(clipboard.PasteStackState{})
+}
+
+// rememberStackSequence records the clipboard sequence right after the queue wrote it
+func (a *App) rememberStackSequence() {
+	seq, ok := a.monitor.Sequence()
+	a.stackMu.Lock()
+	defer a.stackMu.Unlock()
+	a.stackSeq, a.stackHasSeq = seq, ok
+}
+
+// showPasteStack shows, updates or closes the overlay; safe from any goroutine
+func (a *App) showPasteStack(state clipboard.PasteStackState) {
+	fyne.Do(func() {
+		if !state.Active() {
+			if a.overlay != nil {
+				a.overlay.window.Close()
+				a.overlay = nil
+			}
+			return
+		}
+		if a.overlay == nil {
+			a.overlay = a.newPasteOverlay()
+			if a.overlay == nil {
+				return
+			}
+			a.overlay.window.Show()
+			keepOverlayOnTop(windowHandle(a.overlay.window))
+		}
+		a.overlay.label.SetText(fmt.Sprintf("%d/%d sırada", state.Position, state.Total))
+	})
+}
+
+// newPasteOverlay creates the overlay window, or nil where the driver has no frameless windows
+func (a *App) newPasteOverlay() *pasteOverlay {
+	drv, ok := a.fyneApp.Driver().(desktop.Driver)
+	if !ok {
+		return nil
+	}
+	w := drv.CreateSplashWindow()
+	label := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	nextBtn := widget.NewButtonWithIcon("", theme.MediaSkipNextIcon(), func() {
+		go a.advancePasteStack()
+	})
+	stopBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), a.clearPasteStack)
+	hint := widget.NewLabelWithStyle("Sıradaki: Ctrl+Shift+Space", fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	w.SetContent(container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(nextBtn, stopBtn), label),
+		hint,
+	))
+	w.SetFixedSize(true)
+	return &pasteOverlay{window: w, label: label}
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/clipboard"
+)
+
+// pausedIndicatorText is the header label while paused, with the end of a timed pause
+func pausedIndicatorText(until time.Time) string {
+	if until.IsZero() {
+		return "Duraklatıldı"
+	}
+	return fmt.Sprintf("Duraklatıldı (bitiş %s)", until.Format("15:04"))
+}
+
+// newPausedIndicator returns the header button shown while capture is paused; clicking
+// it resumes capture
+func (a *App) newPausedIndicator() *widget.Button {
+	btn := widget.NewButtonWithIcon("", theme.MediaPauseIcon(), func() {
+		a.monitor.Resume()
+	})
+	btn.Importance = widget.WarningImportance
+	a.syncPausedIndicator(btn)
+	return btn
+}
+
+// updatePausedIndicator shows or hides the header indicator for the current pause state
+func (a *App) updatePausedIndicator() {
+	if a.pausedIndicator != nil {
+		a.syncPausedIndicator(a.pausedIndicator)
+	}
+}
+
+// syncPausedIndicator applies the pause state to btn
+func (a *App) syncPausedIndicator(btn *widget.Button) {
+	if !a.monitor.IsPaused() {
+		btn.Hide()
+		return
+	}
+	btn.SetText(pausedIndicatorText(a.monitor.PausedUntil()))
+	btn.Show()
+}
+
+// togglePause pauses capture until resumed, or resumes it
+func (a *App) togglePause() {
+	if a.monitor.IsPaused() {
+		a.monitor.Resume()
+	} else {
+		a.monitor.Pause()
+	}
+}
+
+// pauseForDefault pauses capture for clipboard.DefaultPauseDuration
+func (a *App) pauseForDefault() {
+	a.monitor.PauseFor(clipboard.DefaultPauseDuration)
+}
+
+// togglePauseFromHotkey toggles the pause and says so, since the window may be hidden
+func (a *App) togglePauseFromHotkey() {
+	a.togglePause()
+	if a.monitor.IsPaused() {
+		a.sendNotification("Yakalama duraklatıldı", "Kopyaladıklarınız kaydedilmeyecek. Sürdürmek için Ctrl+Shift+P.")
+	} else {
+		a.sendNotification("Yakalama sürüyor", "Pano yeniden kopyaladıklarınızı kaydediyor.")
+	}
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/clipboard"
+)
+
+// pollIntervalStepMs is the step of the poll interval slider
+const pollIntervalStepMs = 50
+
+// buildPollSettings returns the slider for how often the clipboard is checked
+func (a *App) buildPollSettings(bind func(key string, fn func())) fyne.CanvasObject {
+	minMs := float64(clipboard.MinPollInterval / time.Millisecond)
+	maxMs := float64(clipboard.MaxPollInterval / time.Millisecond)
+
+	valueLabel := widget.NewLabel("")
+	slider := widget.NewSlider(minMs, maxMs)
+	slider.Step = pollIntervalStepMs
+	slider.OnChanged = func(v float64) {
+		valueLabel.SetText(fmt.Sprintf("%d ms", int(v)))
+	}
+	slider.OnChangeEnded = func(v float64) {
+		a.settings.SetInt("poll_interval_ms", int(v))
+	}
+	sync := func() {
+		ms := a.settings.IntWithFallback("poll_interval_ms", *a.config.PollIntervalMs)
+		slider.SetValue(min(max(float64(ms), minMs), maxMs))
+	}
+	sync()
+	bind("poll_interval_ms", sync)
+
+	hint := widget.NewLabel(fmt.Sprintf("Pano %d saniye değişmezse aralık %s'ye kadar uzar, ilk kopyalamada geri döner.",
+		int(clipboard.IdleBackoffAfter/time.Second), formatPollCeiling()))
+	hint.Wrapping = fyne.TextWrapWord
+	hint.Importance = widget.LowImportance
+
+	return container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Pano kontrol aralığı"), valueLabel, slider),
+		hint,
+	)
+}
+
+// formatPollCeiling names the longest backed-off interval, e.g. "2 sn"
+func formatPollCeiling() string {
+	return fmt.Sprintf("%d sn", int(clipboard.IdlePollCeiling/time.Second))
+}
@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	fynestorage "fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// portableExportName is the file name the portable export dialog suggests
+const portableExportName = "pano-gecmis.panox"
+
+// minExportPassword is the shortest password the dialog accepts
+const minExportPassword = 8
+
+// Import modes offered by showImportOptions
+const (
+	importMerge   = "Mevcut geçmişle birleştir"
+	importReplace = "Mevcut geçmişin yerine koy"
+)
+
+// showPortableExport asks for a password and a target folder, then writes the history
+// encrypted with that password so it can be moved to another machine
+// A folder is picked instead of a file because Fyne's save dialog creates (and
+// truncates) the file before Pano can refuse a path inside its data directory
+func (a *App) showPortableExport() {
+	if !a.manager.Permissions().CanExport() {
+		return
+	}
+
+	password := widget.NewPasswordEntry()
+	confirm := widget.NewPasswordEntry()
+	name := widget.NewEntry()
+	name.SetText(portableExportName)
+
+	folder := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		folder = filepath.Join(home, "Documents")
+		if _, err := os.Stat(folder); err != nil {
+			folder = home
+		}
+	}
+	folderLabel := widget.NewLabel(folder)
+	folderLabel.Truncation = fyne.TextTruncateEllipsis
+	folderBtn := widget.NewButtonWithIcon("Klasör seç", theme.FolderOpenIcon(), func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			folder = uri.Path()
+			folderLabel.SetText(folder)
+		}, a.window)
+	})
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+	errorLabel.Hide()
+	showError := func(text string) {
+		errorLabel.SetText(text)
+		errorLabel.Show()
+	}
+
+	info := widget.NewLabel("Geçmiş bu parolayla şifrelenir; parola olmadan dosya açılamaz ve parola kurtarılamaz.")
+	info.Wrapping = fyne.TextWrapWord
+
+	var d *dialog.CustomDialog
+	exportBtn := widget.NewButtonWithIcon("Dışa aktar", theme.DownloadIcon(), func() {
+		switch {
+		case len([]rune(password.Text)) < minExportPassword:
+			showError("Parola en az 8 karakter olmalı")
+			return
+		case password.Text != confirm.Text:
+			showError("Parolalar eşleşmiyor")
+			return
+		case folder == "" || strings.TrimSpace(name.Text) == "":
+			showError("Bir klasör ve dosya adı seçin")
+			return
+		}
+
+		path := filepath.Join(folder, strings.TrimSpace(name.Text))
+		if _, err := os.Stat(path); err == nil {
+			dialog.ShowConfirm("Dosya var", filepath.Base(path)+" değiştirilsin mi?", func(ok bool) {
+				if ok {
+					a.writePortableExport(d, path, password.Text, showError)
+				}
+			}, a.window)
+			return
+		}
+		a.writePortableExport(d, path, password.Text, showError)
+	})
+	exportBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButtonWithIcon("İptal", theme.CancelIcon(), func() {
+		d.Hide()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Parola", password),
+		widget.NewFormItem("Parola (tekrar)", confirm),
+		widget.NewFormItem("Klasör", container.NewBorder(nil, nil, nil, folderBtn, folderLabel)),
+		widget.NewFormItem("Dosya adı", name),
+	)
+	content := container.NewVBox(info, form, errorLabel)
+	d = dialog.NewCustomWithoutButtons("Taşınabilir dışa aktarma", content, a.window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, exportBtn})
+	d.Resize(fyne.NewSize(480, 320))
+	d.Show()
+	a.window.Canvas().Focus(password)
+}
+
+// writePortableExport runs the export in the background, since deriving the key and
+// encrypting every item takes a moment; the dialog closes once it succeeded
+func (a *App) writePortableExport(d *dialog.CustomDialog, path, password string, showError func(string)) {
+	progress := dialog.NewCustomWithoutButtons("Dışa aktarılıyor", widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+	go func() {
+		err := a.manager.Export(path, password)
+		fyne.Do(func() {
+			progress.Hide()
+			switch {
+			case err == nil:
+				d.Hide()
+				a.showToast("Geçmiş dışa aktarıldı")
+			case errors.Is(err, storage.ErrExportInDataDir):
+				showError("Pano'nun veri klasörüne dışa aktarılamaz; başka bir klasör seçin")
+			default:
+				showError(err.Error())
+			}
+		})
+	}()
+}
+
+// showPortableImport picks a portable export, then asks for its password and whether
+// to merge it into the history or replace the history with it
+func (a *App) showPortableImport() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			return
+		}
+		path := reader.URI().Path()
+		reader.Close()
+		a.showImportOptions(path)
+	}, a.window)
+	open.SetFilter(fynestorage.NewExtensionFileFilter([]string{filepath.Ext(portableExportName)}))
+	open.Show()
+}
+
+// showImportOptions asks for the password and the import mode of the file at path
+func (a *App) showImportOptions(path string) {
+	password := widget.NewPasswordEntry()
+	mode := widget.NewRadioGroup([]string{importMerge, importReplace}, nil)
+	mode.SetSelected(importMerge)
+	mode.Required = true
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+	errorLabel.Hide()
+	showError := func(text string) {
+		errorLabel.SetText(text)
+		errorLabel.Show()
+	}
+
+	info := widget.NewLabel(filepath.Base(path) + "\nİçe aktarmadan önce geçmişin bir geri yükleme noktası alınır.")
+	info.Wrapping = fyne.TextWrapWord
+
+	var d *dialog.CustomDialog
+	importBtn := widget.NewButtonWithIcon("İçe aktar", theme.UploadIcon(), func() {
+		if password.Text == "" {
+			showError("Dosyanın parolasını girin")
+			return
+		}
+		merge := mode.Selected == importMerge
+		if merge {
+			a.runPortableImport(d, path, password.Text, true, showError)
+			return
+		}
+		dialog.ShowConfirm("Geçmiş değiştirilsin mi?", "Mevcut geçmiş silinip dosyadaki öğelerle değiştirilecek.", func(ok bool) {
+			if ok {
+				a.runPortableImport(d, path, password.Text, false, showError)
+			}
+		}, a.window)
+	})
+	importBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButtonWithIcon("İptal", theme.CancelIcon(), func() {
+		d.Hide()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Parola", password),
+		widget.NewFormItem("Yöntem", mode),
+	)
+	content := container.NewVBox(info, form, errorLabel)
+	d = dialog.NewCustomWithoutButtons("İçe aktar", content, a.window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, importBtn})
+	d.Resize(fyne.NewSize(440, 280))
+	d.Show()
+	a.window.Canvas().Focus(password)
+}
+
+// runPortableImport imports in the background, since deriving the key takes a moment;
+// the dialog closes once it succeeded
+func (a *App) runPortableImport(d *dialog.CustomDialog, path, password string, merge bool, showError func(string)) {
+	progress := dialog.NewCustomWithoutButtons("İçe aktarılıyor", widget.NewProgressBarInfinite(), a.window)
+	progress.Show()
+	go func() {
+		err := a.manager.Import(path, password, merge)
+		fyne.Do(func() {
+			progress.Hide()
+			switch {
+			case err == nil:
+				d.Hide()
+				a.showToast("Geçmiş içe aktarıldı")
+			case errors.Is(err, storage.ErrWrongPassword):
+				showError("Parola yanlış (ya da dosyanın şifreli kısmı bozulmuş)")
+			case errors.Is(err, storage.ErrCorruptExport):
+				showError("Dosya bozuk ya da Pano'nun dışa aktarma dosyası değil")
+			default:
+				showError(err.Error())
+			}
+		})
+	}()
+}
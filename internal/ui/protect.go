@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+)
+
+// protectedMask stands in for the preview of a protected item
+const protectedMask = "••••••••"
+
+// newProtectedBadge creates the badge shown on protected items
+func newProtectedBadge() *Badge {
+	return NewBadge("KORUMALI", badgeProtected)
+}
+
+// newProtectedPreview is the card content of a protected item; nothing of it is read
+func newProtectedPreview() fyne.CanvasObject {
+	label := widget.NewLabel(protectedMask)
+	label.Importance = widget.LowImportance
+	return container.NewHBox(widget.NewIcon(theme.VisibilityOffIcon()), label)
+}
+
+// copyItem copies an item, asking for the passphrase of a protected one; done runs
+// after a successful copy
+func (a *App) copyItem(id string, done func()) {
+	err := a.manager.CopyToClipboard(id)
+	if errors.Is(err, storage.ErrProtected) {
+		a.promptProtectedCopy(id, done)
+		return
+	}
+	var missing *clipboard.MissingFilesError
+	if errors.As(err, &missing) {
+		a.showMissingFiles(missing)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	a.showToast("Panoya kopyalandı")
+	if done != nil {
+		done()
+	}
+}
+
+// promptProtectedCopy asks for an item's passphrase and copies it if it matches
+func (a *App) promptProtectedCopy(id string, done func()) {
+	entry := widget.NewPasswordEntry()
+	entry.SetPlaceHolder("Parola")
+
+	d := dialog.NewCustomConfirm("Korumalı öğe", "Kopyala", "İptal", entry, func(ok bool) {
+		if !ok {
+			return
+		}
+		passphrase := entry.Text
+		// The check is slow on purpose, so it stays off the UI thread
+		go func() {
+			err := a.manager.CopyProtected(id, passphrase)
+			fyne.Do(func() {
+				if err != nil {
+					a.showProtectError(err)
+					return
+				}
+				a.showToast("Panoya kopyalandı")
+				if done != nil {
+					done()
+				}
+			})
+		}()
+	}, a.window)
+	d.Resize(fyne.NewSize(360, 160))
+	d.Show()
+	a.window.Canvas().Focus(entry)
+}
+
+// showProtectDialog sets a passphrase on an unprotected item, or removes it from a
+// protected one after asking for it
+func (a *App) showProtectDialog(item storage.ClipboardItem) {
+	if item.IsProtected() {
+		a.showUnprotectDialog(item.ID)
+		return
+	}
+
+	passphrase := widget.NewPasswordEntry()
+	passphrase.SetPlaceHolder("Parola")
+	confirm := widget.NewPasswordEntry()
+	confirm.SetPlaceHolder("Parola (tekrar)")
+	hint := widget.NewLabel("Öğenin önizlemesi gizlenir; kopyalamak için her seferinde parola sorulur. " +
+		"Parola unutulursa öğe yalnızca silinebilir.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustomConfirm("Parolayla koru", "Koru", "İptal", container.NewVBox(hint, passphrase, confirm), func(ok bool) {
+		if !ok {
+			return
+		}
+		if passphrase.Text == "" {
+			dialog.ShowInformation("Parolayla koru", "Parola boş olamaz.", a.window)
+			return
+		}
+		if passphrase.Text != confirm.Text {
+			dialog.ShowInformation("Parolayla koru", "Parolalar eşleşmiyor.", a.window)
+			return
+		}
+		text := passphrase.Text
+		go func() {
+			err := a.manager.Protect(item.ID, text)
+			fyne.Do(func() {
+				if err != nil {
+					dialog.ShowError(err, a.window)
+					return
+				}
+				a.list.Refresh()
+				a.refreshTray()
+				a.showToast("Öğe korumaya alındı")
+			})
+		}()
+	}, a.window)
+	d.Resize(fyne.NewSize(400, 240))
+	d.Show()
+	a.window.Canvas().Focus(passphrase)
+}
+
+// showUnprotectDialog removes an item's passphrase once it is entered
+func (a *App) showUnprotectDialog(id string) {
+	entry := widget.NewPasswordEntry()
+	entry.SetPlaceHolder("Parola")
+
+	d := dialog.NewCustomConfirm("Korumayı kaldır", "Kaldır", "İptal", entry, func(ok bool) {
+		if !ok {
+			return
+		}
+		passphrase := entry.Text
+		go func() {
+			err := a.manager.Unprotect(id, passphrase)
+			fyne.Do(func() {
+				if err != nil {
+					a.showProtectError(err)
+					return
+				}
+				a.list.Refresh()
+				a.refreshTray()
+				a.showToast("Koruma kaldırıldı")
+			})
+		}()
+	}, a.window)
+	d.Resize(fyne.NewSize(360, 160))
+	d.Show()
+	a.window.Canvas().Focus(entry)
+}
+
+// showProtectError explains a refused passphrase
+func (a *App) showProtectError(err error) {
+	var lockout *clipboard.LockoutError
+	switch {
+	case errors.Is(err, storage.ErrWrongPassphrase):
+		dialog.ShowInformation("Korumalı öğe", "Parola yanlış.", a.window)
+	case errors.As(err, &lockout):
+		wait := time.Until(lockout.Until).Round(time.Second)
+		dialog.ShowInformation("Korumalı öğe",
+			fmt.Sprintf("Çok fazla yanlış parola girildi. %s sonra tekrar deneyin.", wait), a.window)
+	default:
+		dialog.ShowError(err, a.window)
+	}
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"errors"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// isKeyMismatch reports whether a load error means the history was written under another
+// machine's key, which a recovery key can open
+func isKeyMismatch(err error) bool {
+	return errors.Is(err, storage.ErrKeyMismatch)
+}
+
+// showRecoveryKey shows the recovery key to write down; a history still encrypted with
+// the hardware key is re-keyed first, so that key is never shown
+func (a *App) showRecoveryKey() {
+	key, err := a.manager.RecoveryKey()
+	if errors.Is(err, storage.ErrNoDataKey) {
+		dialog.ShowConfirm("Kurtarma anahtarı",
+			"Geçmiş henüz bu bilgisayarın donanım anahtarıyla şifreli. Kurtarma anahtarı için önce anahtar yenilenecek. Devam edilsin mi?",
+			func(ok bool) {
+				if ok {
+					a.runRekey(a.showRecoveryKey)
+				}
+			}, a.window)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+
+	keyLabel := widget.NewLabelWithStyle(key, fyne.TextAlignCenter, fyne.TextStyle{Monospace: true})
+	keyLabel.Wrapping = fyne.TextWrapBreak
+	hint := widget.NewLabel("Bu anahtarı güvenli bir yere kaydedin. Anakart değişir ya da Windows yeniden kurulursa " +
+		"geçmiş yalnızca bu anahtarla açılabilir. Anahtar yenilendiğinde kurtarma anahtarı da değişir.")
+	hint.Wrapping = fyne.TextWrapWord
+	copyBtn := widget.NewButton("Kopyala", func() {
+		// The key must not end up in the history it protects
+		a.monitor.IgnoreNext("text", []byte(key))
+		if err := a.manager.CopyText(key); err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		a.showToast("Kurtarma anahtarı kopyalandı")
+	})
+
+	d := dialog.NewCustom("Kurtarma anahtarı", "Kapat", container.NewVBox(hint, keyLabel, copyBtn), a.window)
+	d.Resize(fyne.NewSize(420, 260))
+	d.Show()
+}
+
+// promptRecovery asks for the recovery key of a history that doesn't open on this
+// machine and re-encrypts it for this machine in the background
+func (a *App) promptRecovery() {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("XXXX-XXXX-…")
+	hint := widget.NewLabel("Geçmiş bu bilgisayarın anahtarıyla açılamıyor. Daha önce kaydettiğiniz kurtarma anahtarını girin.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustomConfirm("Kurtarma anahtarı gir", "Aç", "İptal", container.NewVBox(hint, entry), func(ok bool) {
+		if !ok {
+			return
+		}
+		input := entry.Text
+		a.reloading = true
+		a.updateStatus()
+		go func() {
+			err := a.manager.Recover(input)
+			fyne.Do(func() {
+				a.reloading = false
+				a.list.Refresh()
+				a.updateStatus()
+				a.refreshTray()
+				switch {
+				case errors.Is(err, storage.ErrInvalidRecoveryKey):
+					dialog.ShowInformation("Kurtarma anahtarı", "Girilen metin bir kurtarma anahtarı değil.", a.window)
+				case isKeyMismatch(err):
+					dialog.ShowInformation("Kurtarma anahtarı", "Bu anahtar geçmişi açmadı.", a.window)
+				case err != nil:
+					dialog.ShowError(err, a.window)
+				default:
+					a.showToast("Geçmiş kurtarıldı ve bu bilgisayar için yeniden şifrelendi")
+				}
+			})
+		}()
+	}, a.window)
+	d.Resize(fyne.NewSize(420, 220))
+	d.Show()
+	a.window.Canvas().Focus(entry)
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// redactionLocked reports whether the defaults file forbids user redaction rules
+func (a *App) redactionLocked() bool {
+	return a.config.RedactionLocked != nil && *a.config.RedactionLocked
+}
+
+// redactionRules returns the policy rules from the defaults file followed by the user's
+// Policy rules come first so they win where matches overlap
+func (a *App) redactionRules() []storage.RedactionRule {
+	rules := append([]storage.RedactionRule(nil), a.config.RedactionRules...)
+	if !a.redactionLocked() {
+		rules = append(rules, storage.ParseRedactionRules(a.settings.StringWithFallback("redaction_rules", ""))...)
+	}
+	return rules
+}
+
+// applyRedactionRules hands the current rules to the database
+func (a *App) applyRedactionRules() error {
+	return a.manager.SetRedactionRules(a.redactionRules())
+}
+
+// buildRedactionSettings creates the redaction rule editor of the settings dialog
+// Rules are saved only while they all compile, so a half-typed pattern never
+// replaces working rules
+func (a *App) buildRedactionSettings(bind func(key string, fn func())) fyne.CanvasObject {
+	label := widget.NewLabelWithStyle("Maskeleme", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	hint := widget.NewLabel("Her satıra bir kural: düzenli ifade => yerine yazılacak metin")
+	hint.Wrapping = fyne.TextWrapWord
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+	errorLabel.Hide()
+
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder(`CUST-\d{6} => CUST-████`)
+	entry.SetMinRowsVisible(3)
+	entry.SetText(a.settings.StringWithFallback("redaction_rules", ""))
+	entry.OnChanged = func(text string) {
+		if _, err := storage.NewRedactor(storage.ParseRedactionRules(text)); err != nil {
+			errorLabel.SetText(err.Error())
+			errorLabel.Show()
+			return
+		}
+		errorLabel.Hide()
+		a.settings.SetString("redaction_rules", text)
+	}
+	bind("redaction_rules", func() {
+		if text := a.settings.StringWithFallback("redaction_rules", ""); text != entry.Text {
+			entry.SetText(text)
+		}
+	})
+
+	section := container.NewVBox(label, hint)
+	if n := len(a.config.RedactionRules); n > 0 {
+		section.Add(widget.NewLabel(fmt.Sprintf("Yönetici tarafından tanımlanan %d kural her zaman uygulanır", n)))
+	}
+	if a.redactionLocked() {
+		entry.Disable()
+		section.Add(widget.NewLabel("Kural ekleme yönetici tarafından kapatıldı"))
+	}
+	section.Add(entry)
+	section.Add(errorLabel)
+	return section
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+
+	"pano/internal/storage"
+)
+
+// captureTreatment is how the user learns that a capture wasn't stored
+type captureTreatment int
+
+const (
+	treatSizeNotice   captureTreatment = iota // Notification with the size and the cap
+	treatLimitFull                            // Limit notification and a toast leading to the limit setting
+	treatStorageError                         // Notification and a status bar warning until a capture is stored again
+)
+
+// rejectTreatments assigns every storage.RejectReason a treatment
+// A reason missing here is logged and treated as a storage error
+var rejectTreatments = map[storage.RejectReason]captureTreatment{
+	storage.RejectTooLarge:  treatSizeNotice,
+	storage.RejectLimitFull: treatLimitFull,
+	storage.RejectRedaction: treatStorageError,
+	storage.RejectCrypto:    treatStorageError,
+	storage.RejectIO:        treatStorageError,
+}
+
+// treatmentFor returns the treatment for an error returned by a capture
+func treatmentFor(err error) captureTreatment {
+	var reject *storage.RejectError
+	if !errors.As(err, &reject) {
+		return treatStorageError
+	}
+	treatment, ok := rejectTreatments[reject.Reason]
+	if !ok {
+		log.Printf("Warning: No treatment for capture rejection %s", reject.Reason)
+		return treatStorageError
+	}
+	return treatment
+}
+
+// handleCaptureError tells the user why a capture wasn't stored; safe from any goroutine
+func (a *App) handleCaptureError(err error) {
+	var reject *storage.RejectError
+	errors.As(err, &reject)
+
+	fyne.Do(func() {
+		switch treatmentFor(err) {
+		case treatSizeNotice:
+			// MaxItemSize is fixed, so there is no setting to lead to
+			a.sendNotification("Kaydedilmedi", fmt.Sprintf("Kopyalanan öğe %s, en fazla %s saklanabiliyor.",
+				formatSize(reject.Size), formatSize(reject.Limit)))
+		case treatLimitFull:
+			a.sendNotification("Limit Doldu", "Pano limiti doldu! Yeni kopyalamalar kaydedilmiyor.")
+			a.toasts.ShowWithAction(fmt.Sprintf("Limit doldu (%d öğe)", reject.Limit), "Ayarlar", a.showSettingsDialog)
+		default:
+			log.Printf("Warning: Capture not stored: %v", err)
+			a.sendNotification("Kaydedilemedi", "Kopyalanan öğe kaydedilemedi: "+err.Error())
+			a.setCaptureFailed(true)
+		}
+	})
+}
+
+// setCaptureFailed shows or clears the status bar warning for unsaved captures
+func (a *App) setCaptureFailed(failed bool) {
+	a.toastMu.Lock()
+	defer a.toastMu.Unlock()
+	if a.captureFailed == failed {
+		return
+	}
+	a.captureFailed = failed
+	a.updateStatusInternal()
+}
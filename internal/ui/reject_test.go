@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"pano/internal/storage"
+)
+
+// Every rejection reason is handled on purpose; errors that aren't rejections are
+// treated as storage errors
+func TestTreatmentFor(t *testing.T) {
+	for _, reason := range storage.RejectReasons {
+		if _, ok := rejectTreatments[reason]; !ok {
+			t.Errorf("no treatment for %s", reason)
+		}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want captureTreatment
+	}{
+		{"too large", &storage.RejectError{Reason: storage.RejectTooLarge}, treatSizeNotice},
+		{"limit full", &storage.RejectError{Reason: storage.RejectLimitFull}, treatLimitFull},
+		{"wrapped limit full", fmt.Errorf("capture: %w", &storage.RejectError{Reason: storage.RejectLimitFull}), treatLimitFull},
+		{"io", &storage.RejectError{Reason: storage.RejectIO}, treatStorageError},
+		{"unknown reason", &storage.RejectError{Reason: storage.RejectReason(99)}, treatStorageError},
+		{"not a rejection", errors.New("disk full"), treatStorageError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := treatmentFor(tt.err); got != tt.want {
+				t.Errorf("treatmentFor(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
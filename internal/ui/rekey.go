@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// confirmRekey asks before re-encrypting the history with a new key
+func (a *App) confirmRekey() {
+	dialog.ShowConfirm("Anahtarı yenile",
+		"Geçmiş, arşiv, işlem geçmişi ve geri yükleme noktaları yeni bir anahtarla yeniden şifrelenecek. "+
+			"Bu sırada kopyalananlar biraz gecikmeyle kaydedilir; daha önce kaydedilen kurtarma anahtarı geçersiz olur. Devam edilsin mi?",
+		func(ok bool) {
+			if ok {
+				a.runRekey(nil)
+			}
+		}, a.window)
+}
+
+// runRekey re-keys in the background behind a progress dialog, then calls done if set
+// The database stays locked meanwhile, so the dialog is the only thing updated
+func (a *App) runRekey(done func()) {
+	bar := widget.NewProgressBar()
+	progress := dialog.NewCustomWithoutButtons("Anahtar yenileniyor", bar, a.window)
+	progress.Resize(fyne.NewSize(360, 100))
+	progress.Show()
+	go func() {
+		err := a.manager.Rekey(func(done, total int) {
+			fyne.Do(func() {
+				bar.SetValue(float64(done) / float64(total))
+			})
+		})
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			a.showToast("Anahtar yenilendi")
+			if done != nil {
+				done()
+			}
+		})
+	}()
+}
+
+// showNonceReport shows whether any stored ciphertext reuses a nonce
+func (a *App) showNonceReport() {
+	report := a.manager.NonceReport()
+	text := fmt.Sprintf("%d şifreli alan tarandı, tekrar eden nonce yok.", report.Scanned)
+	if report.Duplicates > 0 {
+		text = fmt.Sprintf("%d şifreli alan tarandı, %d alanda nonce tekrarlanıyor. "+
+			"Anahtarı yenilemeniz önerilir.", report.Scanned, report.Duplicates)
+	}
+	dialog.ShowInformation("Nonce denetimi", text, a.window)
+}
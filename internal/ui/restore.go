@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// safetyReasonLabels names the operation a restore point was taken before
+var safetyReasonLabels = map[storage.SafetyReason]string{
+	storage.SafetyClear:     "Temizleme öncesi",
+	storage.SafetyMigration: "Biçim dönüşümü öncesi",
+	storage.SafetyRestore:   "Geri yükleme öncesi",
+	storage.SafetyRekey:     "Anahtar yenileme öncesi",
+	storage.SafetyImport:    "İçe aktarma öncesi",
+}
+
+// showRestorePoints lists the automatic safety copies; each can be restored after a confirmation
+func (a *App) showRestorePoints() {
+	points, err := a.manager.RestorePoints()
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+
+	var d dialog.Dialog
+	rows := container.NewVBox()
+	if len(points) == 0 {
+		rows.Add(widget.NewLabel("Henüz geri yükleme noktası yok"))
+	}
+	for _, point := range points {
+		rows.Add(a.createRestorePointRow(point, func() {
+			d.Hide()
+		}))
+	}
+
+	hint := widget.NewLabel(fmt.Sprintf("Tümünü temizleme gibi işlemlerden önce otomatik oluşturulur; son %d nokta saklanır.", storage.SafetyKeep))
+	hint.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(380, 200))
+
+	d = dialog.NewCustom("Geri Yükleme Noktaları", "Kapat", container.NewBorder(hint, nil, nil, nil, scroll), a.window)
+	d.Show()
+}
+
+// createRestorePointRow builds a row with the restore button for one restore point
+// done closes the list once a restore succeeded
+func (a *App) createRestorePointRow(point storage.RestorePoint, done func()) fyne.CanvasObject {
+	reason := safetyReasonLabels[point.Reason]
+	if reason == "" {
+		reason = string(point.Reason)
+	}
+	when := point.Time.Format("02.01.2006 15:04:05")
+
+	titleLabel := widget.NewLabelWithStyle("Otomatik güvenlik - "+reason, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	infoLabel := widget.NewLabelWithStyle(fmt.Sprintf("%s - %s", when, formatSize(int(point.Size))),
+		fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+
+	restoreBtn := widget.NewButtonWithIcon("Geri yükle", theme.HistoryIcon(), func() {
+		dialog.ShowConfirm("Geri Yükle",
+			fmt.Sprintf("Geçmiş %s tarihindeki haliyle değiştirilecek. Şu anki geçmiş için de bir geri yükleme noktası oluşturulur. Devam edilsin mi?", when),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := a.manager.RestoreFromPoint(point.Path); err != nil {
+					dialog.ShowError(err, a.window)
+					return
+				}
+				thumbCache.clear()
+				a.afterItemsChanged()
+				done()
+				a.showToast("Geri yüklendi")
+			}, a.window)
+	})
+
+	return container.NewBorder(nil, nil, nil, restoreBtn, container.NewVBox(titleLabel, infoLabel))
+}
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// The retention period removes unpinned items older than retention_days. A ticker runs
+// the prune hourly and once right away, and again whenever the period changes; the time
+// of the last run and how many items it removed are saved for the settings dialog.
+// The same ticker empties the trash of items deleted more than a day ago.
+
+const retentionCheckInterval = time.Hour
+
+// retentionOptions are the choices for how long unpinned items are kept
+var retentionOptions = []struct {
+	label string
+	days  int
+}{
+	{"Süresiz", 0},
+	{"1 gün", 1},
+	{"7 gün", 7},
+	{"30 gün", 30},
+}
+
+// retentionLabels returns the labels of retentionOptions
+func retentionLabels() []string {
+	labels := make([]string, 0, len(retentionOptions))
+	for _, opt := range retentionOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// StartRetention begins the hourly prune; a no-op if already running
+func (a *App) StartRetention() {
+	a.retentionMu.Lock()
+	defer a.retentionMu.Unlock()
+	if a.retentionStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	a.retentionStop = stop
+
+	a.retentionWG.Add(1)
+	go func() {
+		defer a.retentionWG.Done()
+		ticker := time.NewTicker(retentionCheckInterval)
+		defer ticker.Stop()
+		for {
+			a.pruneExpired(time.Now())
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopRetention stops the hourly prune and waits for a running one to finish
+func (a *App) StopRetention() {
+	a.retentionMu.Lock()
+	if a.retentionStop != nil {
+		close(a.retentionStop)
+		a.retentionStop = nil
+	}
+	a.retentionMu.Unlock()
+	a.retentionWG.Wait()
+}
+
+// pruneExpired empties the trash of old deletions and removes unpinned items older than
+// the retention period, if one is set
+func (a *App) pruneExpired(now time.Time) {
+	// A failed delayed write is reported through SetOnSaveError
+	_, _ = a.manager.PurgeDeleted(storage.TrashRetention)
+
+	days := a.settings.IntWithFallback("retention_days", 0)
+	if days <= 0 {
+		return
+	}
+	pruned := a.manager.PruneOlderThan(time.Duration(days) * 24 * time.Hour)
+	a.settings.SetInt("retention_last_pruned", pruned)
+	a.settings.SetInt("retention_last_run", int(now.Unix()))
+}
+
+// retentionStatus describes the last prune for the settings dialog
+func retentionStatus(lastRun int64, pruned int) string {
+	if lastRun == 0 {
+		return "Henüz çalışmadı"
+	}
+	return fmt.Sprintf("Son çalışma: %s, %d öğe silindi", time.Unix(lastRun, 0).Format("02.01.2006 15:04"), pruned)
+}
+
+// buildRetentionSettings returns the retention period choice and the last run's result
+func (a *App) buildRetentionSettings(bind func(key string, fn func())) fyne.CanvasObject {
+	retentionSelect := widget.NewSelect(retentionLabels(), func(selected string) {
+		for _, opt := range retentionOptions {
+			if opt.label == selected {
+				a.settings.SetInt("retention_days", opt.days)
+			}
+		}
+	})
+	syncSelect := func() {
+		days := a.settings.IntWithFallback("retention_days", 0)
+		for _, opt := range retentionOptions {
+			if opt.days == days {
+				retentionSelect.SetSelected(opt.label)
+			}
+		}
+	}
+	syncSelect()
+	bind("retention_days", syncSelect)
+
+	statusLabel := widget.NewLabel("")
+	statusLabel.Importance = widget.LowImportance
+	syncStatus := func() {
+		lastRun := int64(a.settings.IntWithFallback("retention_last_run", 0))
+		statusLabel.SetText(retentionStatus(lastRun, a.settings.IntWithFallback("retention_last_pruned", 0)))
+	}
+	syncStatus()
+	// The prune runs on the ticker's goroutine
+	bind("retention_last_run", func() { fyne.Do(syncStatus) })
+
+	return container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Sabitlenmemiş öğeleri sakla"), nil, retentionSelect),
+		statusLabel,
+	)
+}
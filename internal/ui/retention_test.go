@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+)
+
+// memSettings keeps preferences in memory, safe for the goroutines that write them
+type memSettings struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+func newMemSettings() *memSettings {
+	return &memSettings{values: make(map[string]any)}
+}
+
+func (s *memSettings) get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func (s *memSettings) set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+func (s *memSettings) BoolWithFallback(key string, fallback bool) bool {
+	if v, ok := s.get(key); ok {
+		return v.(bool)
+	}
+	return fallback
+}
+
+func (s *memSettings) IntWithFallback(key string, fallback int) int {
+	if v, ok := s.get(key); ok {
+		return v.(int)
+	}
+	return fallback
+}
+
+func (s *memSettings) StringWithFallback(key string, fallback string) string {
+	if v, ok := s.get(key); ok {
+		return v.(string)
+	}
+	return fallback
+}
+
+func (s *memSettings) SetBool(key string, value bool)     { s.set(key, value) }
+func (s *memSettings) SetInt(key string, value int)       { s.set(key, value) }
+func (s *memSettings) SetString(key string, value string) { s.set(key, value) }
+func (s *memSettings) Backend() string                    { return "memory" }
+
+// StopRetention returns only once the prune goroutine has, so shutting down leaves
+// nothing running
+func TestStopRetentionLeaksNoGoroutines(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	storage.UseTempDataDir(t)
+	db, err := storage.NewDatabase()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	prefs := newMemSettings()
+	prefs.SetInt("retention_days", 1)
+	a := &App{manager: clipboard.NewManager(db), settings: newSettingsModel(prefs)}
+
+	a.StartRetention()
+	a.StartRetention()
+	// The first prune runs right away
+	deadline := time.Now().Add(5 * time.Second)
+	for prefs.IntWithFallback("retention_last_run", 0) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the prune didn't run")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	a.StopRetention()
+	a.StopRetention()
+
+	if err := a.manager.Close(); err != nil {
+		t.Errorf("failed to close manager: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"pano/internal/storage"
+)
+
+// searchMode selects how the search query is matched against item text
+type searchMode int
+
+const (
+	searchNormal    searchMode = iota // Case-insensitive substring, see storage.FoldText
+	searchWholeWord                   // Substring bounded by non-word characters
+	searchRegex                       // User-supplied regular expression
+)
+
+const (
+	regexMaxLength  = 1000                   // Longer patterns are rejected before compiling
+	regexTimeBudget = 250 * time.Millisecond // Matching stops for the rest of a refresh after this
+)
+
+// errSearchTimeout reports that a regex search ran out of its time budget
+var errSearchTimeout = errors.New("arama zaman aşımına uğradı, sonuçlar eksik olabilir")
+
+// searchModeOptions are the search mode choices next to the search box
+var searchModeOptions = []struct {
+	label string
+	mode  searchMode
+}{
+	{"Normal", searchNormal},
+	{"Tam sözcük", searchWholeWord},
+	{"Düzenli ifade", searchRegex},
+}
+
+// searchModeLabels returns the labels of searchModeOptions
+func searchModeLabels() []string {
+	labels := make([]string, 0, len(searchModeOptions))
+	for _, opt := range searchModeOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// matcher tests item text against a query in one search mode
+// A matcher lives for one refresh; the regex time budget starts when it is created
+type matcher struct {
+	mode     searchMode
+	query    string         // Folded query for normal and whole-word modes
+	re       *regexp.Regexp // Compiled pattern for regex mode
+	now      func() time.Time
+	deadline time.Time
+	timedOut bool
+}
+
+// newMatcher prepares query for mode; regex syntax errors are returned for inline display
+func newMatcher(query string, mode searchMode) (*matcher, error) {
+	return newMatcherWithClock(query, mode, time.Now)
+}
+
+// newMatcherWithClock is newMatcher with the clock the budget is measured on, for tests
+func newMatcherWithClock(query string, mode searchMode, now func() time.Time) (*matcher, error) {
+	m := &matcher{mode: mode, query: storage.FoldText(query), now: now}
+	if mode != searchRegex {
+		return m, nil
+	}
+
+	if len(query) > regexMaxLength {
+		return nil, fmt.Errorf("ifade çok uzun (en fazla %d karakter)", regexMaxLength)
+	}
+	re, err := regexp.Compile("(?i)" + query)
+	if err != nil {
+		var syntaxErr *syntax.Error
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("geçersiz ifade: %s", syntaxErr.Code)
+		}
+		return nil, fmt.Errorf("geçersiz ifade: %w", err)
+	}
+	m.re = re
+	m.deadline = now().Add(regexTimeBudget)
+	return m, nil
+}
+
+// Match reports whether text matches
+// Regex matching is linear in Go, but many large items can still add up, so once the
+// budget is spent the remaining items are skipped and Err reports the timeout
+func (m *matcher) Match(text string) bool {
+	switch m.mode {
+	case searchWholeWord:
+		return containsWord(storage.FoldText(text), m.query)
+	case searchRegex:
+		if m.timedOut || m.now().After(m.deadline) {
+			m.timedOut = true
+			return false
+		}
+		return m.re.MatchString(text)
+	default:
+		return strings.Contains(storage.FoldText(text), m.query)
+	}
+}
+
+// Err returns errSearchTimeout if matching stopped early
+func (m *matcher) Err() error {
+	if m.timedOut {
+		return errSearchTimeout
+	}
+	return nil
+}
+
+// containsWord reports whether word occurs in text with word boundaries on both sides
+// Boundaries are Unicode-aware: Turkish letters such as ş, ğ and ı count as word characters
+func containsWord(text, word string) bool {
+	if word == "" {
+		return false
+	}
+	// Edges that aren't word characters themselves ("foo(" or ".env") need no boundary
+	first, _ := utf8.DecodeRuneInString(word)
+	last, _ := utf8.DecodeLastRuneInString(word)
+	checkBefore, checkAfter := isWordRune(first), isWordRune(last)
+
+	for start := 0; start <= len(text)-len(word); {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (!checkBefore || i == 0 || !isWordRune(before)) && (!checkAfter || end == len(text) || !isWordRune(after)) {
+			return true
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+		start = i + size
+	}
+	return false
+}
+
+// isWordRune reports whether r belongs to an identifier or word
+// Combining marks count too, so the dot of a lower-cased "İ" doesn't end a word
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pano/internal/panotest"
+)
+
+// TestRegexSearchTimeout runs regex searches on a fake clock: once matching has taken
+// the budget, whether in the pattern or in reading the items, the rest of the refresh
+// is skipped and reported
+func TestRegexSearchTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		texts   []string
+		// step returns the matcher's clock and what runs before each match
+		step func(c *panotest.Clock) (clock func() time.Time, wait func())
+	}{
+		{
+			// Would backtrack exponentially in a backtracking engine; here every match
+			// is made to look slow instead
+			name:    "pathological pattern",
+			pattern: `(a|aa)*c`,
+			texts:   []string{strings.Repeat("a", 1<<12) + "c", strings.Repeat("a", 1<<12) + "c", "ac", "ac", "ac"},
+			step: func(c *panotest.Clock) (func() time.Time, func()) {
+				return c.Ticking(regexTimeBudget / 2), func() {}
+			},
+		},
+		{
+			// Reading and decrypting each item is what takes the time
+			name:    "slow reads",
+			pattern: `ödeme \d+`,
+			texts:   []string{"ödeme 1", "ödeme 2", "ödeme 3", "ödeme 4", "ödeme 5"},
+			step: func(c *panotest.Clock) (func() time.Time, func()) {
+				return c.Now, func() { c.Advance(regexTimeBudget / 2) }
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock, wait := tt.step(panotest.NewClock(panotest.Start))
+			m, err := newMatcherWithClock(tt.pattern, searchRegex, clock)
+			if err != nil {
+				t.Fatalf("failed to compile: %v", err)
+			}
+			var matched []bool
+			for _, text := range tt.texts {
+				wait()
+				matched = append(matched, m.Match(text))
+			}
+			want := []bool{true, true, false, false, false}
+			for i := range want {
+				if matched[i] != want[i] {
+					t.Fatalf("matched %v, want %v", matched, want)
+				}
+			}
+			if m.Err() != errSearchTimeout {
+				t.Errorf("err %v, want the timeout", m.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+
+	"pano/internal/storage"
+)
+
+// sectionKind identifies a block of cards in the list
+type sectionKind int
+
+const (
+	sectionPinned sectionKind = iota
+	sectionHistory
+)
+
+// listSection is a headed block of items in the list view model
+type listSection struct {
+	kind      sectionKind
+	title     string
+	items     []storage.ClipboardItem // Every item in the section, shown or not
+	collapsed bool                    // Header only, cards hidden
+}
+
+// visibleItems returns the items whose cards are rendered
+func (s listSection) visibleItems() []storage.ClipboardItem {
+	if s.collapsed {
+		return nil
+	}
+	return s.items
+}
+
+// buildSections splits already filtered items into a pinned and a history section
+// Without pinned items there is a single untitled section, so plain lists get no headers
+func buildSections(items []storage.ClipboardItem, pinnedCollapsed bool) []listSection {
+	pinned := make([]storage.ClipboardItem, 0)
+	history := make([]storage.ClipboardItem, 0, len(items))
+	for _, item := range items {
+		if item.Pinned {
+			pinned = append(pinned, item)
+		} else {
+			history = append(history, item)
+		}
+	}
+
+	if len(pinned) == 0 {
+		return []listSection{{kind: sectionHistory, items: history}}
+	}
+
+	sections := []listSection{{
+		kind:      sectionPinned,
+		title:     fmt.Sprintf("Sabitlenmiş (%d)", len(pinned)),
+		items:     pinned,
+		collapsed: pinnedCollapsed,
+	}}
+	if len(history) > 0 {
+		sections = append(sections, listSection{kind: sectionHistory, title: "Geçmiş", items: history})
+	}
+	return sections
+}
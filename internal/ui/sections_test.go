@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"testing"
+
+	"pano/internal/storage"
+)
+
+// sectionSummary is what a section shows: its title and the IDs of its rendered cards
+type sectionSummary struct {
+	title string
+	ids   string
+}
+
+// summarize flattens sections for comparison
+func summarize(sections []listSection) []sectionSummary {
+	got := make([]sectionSummary, 0, len(sections))
+	for _, s := range sections {
+		ids := ""
+		for _, item := range s.visibleItems() {
+			ids += item.ID
+		}
+		got = append(got, sectionSummary{s.title, ids})
+	}
+	return got
+}
+
+// TestBuildSections splits items into a pinned block and the history, keeping their
+// order, and hides the pinned cards while collapsed
+func TestBuildSections(t *testing.T) {
+	pinned := func(id string) storage.ClipboardItem { return storage.ClipboardItem{ID: id, Pinned: true} }
+	plain := func(id string) storage.ClipboardItem { return storage.ClipboardItem{ID: id} }
+	tests := []struct {
+		name      string
+		items     []storage.ClipboardItem
+		collapsed bool
+		want      []sectionSummary
+	}{
+		{"empty", nil, false, []sectionSummary{{"", ""}}},
+		{"no pins, no headers", []storage.ClipboardItem{plain("a"), plain("b")}, false, []sectionSummary{{"", "ab"}}},
+		{
+			"pins interleaved",
+			[]storage.ClipboardItem{plain("a"), pinned("P"), plain("b"), pinned("Q")},
+			false,
+			[]sectionSummary{{"Sabitlenmiş (2)", "PQ"}, {"Geçmiş", "ab"}},
+		},
+		{
+			"collapsed keeps the count",
+			[]storage.ClipboardItem{pinned("P"), plain("a")},
+			true,
+			[]sectionSummary{{"Sabitlenmiş (1)", ""}, {"Geçmiş", "a"}},
+		},
+		{"only pins", []storage.ClipboardItem{pinned("P")}, false, []sectionSummary{{"Sabitlenmiş (1)", "P"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarize(buildSections(tt.items, tt.collapsed))
+			if len(got) != len(tt.want) {
+				t.Fatalf("sections %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("section %d is %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/system"
+)
+
+// BindSelection connects the select-to-copy watcher and applies the saved settings;
+// the watcher must also be fed by the hotkey hook (HotkeyManager.SetInputHandler)
+func (a *App) BindSelection(w *system.SelectionWatcher) {
+	a.selection = w
+	w.SetOnOff(func() {
+		fyne.Do(func() {
+			a.settings.SetBool("selection_capture", false)
+			a.sendNotification("Seçince kopyala kapatıldı",
+				"Ctrl+Shift+"+system.SelectionOffKey+" ile kapatıldı; Ayarlar > Kısayollar'dan yeniden açabilirsiniz.")
+		})
+	})
+	a.applySelection()
+}
+
+// applySelection passes the select-to-copy settings to the watcher
+func (a *App) applySelection() {
+	if a.selection == nil {
+		return
+	}
+	a.selection.SetExcludedApps(parseParamList(a.settings.StringWithFallback("excluded_apps", "")))
+	a.selection.SetEnabled(a.settings.BoolWithFallback("selection_capture", false))
+}
+
+// buildSelectionSettings returns the select-to-copy switch and the apps it skips
+func (a *App) buildSelectionSettings(bind func(key string, fn func())) fyne.CanvasObject {
+	check := widget.NewCheck("Seçince kopyala (fareyle seçilen metin Ctrl+C ile kopyalanır)", func(checked bool) {
+		a.settings.SetBool("selection_capture", checked)
+	})
+	check.Checked = a.settings.BoolWithFallback("selection_capture", false)
+	// The off switch flips it while the dialog may be open
+	bind("selection_capture", func() {
+		check.SetChecked(a.settings.BoolWithFallback("selection_capture", false))
+	})
+
+	hint := widget.NewLabel("Ctrl+Shift+" + system.SelectionOffKey + " her yerden kapatır. Aşağıdaki uygulamalarda hiç kopyalanmaz.")
+	hint.Wrapping = fyne.TextWrapWord
+	hint.Importance = widget.LowImportance
+
+	appsEntry := widget.NewEntry()
+	appsEntry.SetPlaceHolder("oyun.exe, tasarim.exe")
+	appsEntry.SetText(a.settings.StringWithFallback("excluded_apps", ""))
+	appsEntry.OnChanged = func(text string) {
+		a.settings.SetString("excluded_apps", strings.TrimSpace(text))
+	}
+
+	box := container.NewVBox(
+		check,
+		hint,
+		container.NewBorder(nil, nil, widget.NewLabel("Hariç uygulamalar"), nil, appsEntry),
+	)
+	// Elsewhere there is no way to send the copy, see system.PlatformFeatures
+	if !system.PlatformFeatures().SelectionCapture {
+		box.Hide()
+	}
+	return box
+}
@@ -0,0 +1,409 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// Smart actions are the content-aware buttons under a card's preview: open a URL or a
+// file path, reformat JSON, convert a color. Each action names the subtypes it applies
+// to; a card detects its subtypes from the item's type, class and text, shows the
+// smartActionsShown matching actions with the highest priority (registration order
+// breaks ties) and puts the rest in its menu. Actions can be turned off one by one in
+// the settings, stored as disabled_smart_actions.
+
+// smartActionsShown is how many smart actions a card shows as buttons
+const smartActionsShown = 3
+
+// smartSubtype refines an item's type and class for choosing actions
+type smartSubtype string
+
+const (
+	subtypeText     smartSubtype = "text"
+	subtypeCode     smartSubtype = "code"
+	subtypeURL      smartSubtype = "url"
+	subtypePath     smartSubtype = "path"  // A Windows file or UNC path on one line
+	subtypeJSON     smartSubtype = "json"  // An object or array that parses as JSON
+	subtypeColor    smartSubtype = "color" // #rgb, #rrggbb or rgb(r, g, b)
+	subtypeContact  smartSubtype = "vcard"
+	subtypeCalendar smartSubtype = "calendar"
+	subtypeImage    smartSubtype = "image"
+)
+
+// smartAction describes one content-aware action
+type smartAction struct {
+	id       string // Stable name, kept in disabled_smart_actions
+	label    string
+	icon     fyne.Resource
+	subtypes []smartSubtype
+	priority int // Higher comes first on the card
+	run      func(a *App, item storage.ClipboardItem)
+}
+
+// smartActionRegistry holds the known actions in registration order
+type smartActionRegistry struct {
+	actions []smartAction
+}
+
+// register adds an action; a second action with the same ID replaces the first
+func (r *smartActionRegistry) register(action smartAction) {
+	for i := range r.actions {
+		if r.actions[i].id == action.id {
+			r.actions[i] = action
+			return
+		}
+	}
+	r.actions = append(r.actions, action)
+}
+
+// all returns every action in registration order, for the settings
+func (r *smartActionRegistry) all() []smartAction {
+	return append([]smartAction(nil), r.actions...)
+}
+
+// forSubtypes returns the enabled actions matching any of subtypes, highest priority first
+func (r *smartActionRegistry) forSubtypes(subtypes []smartSubtype, disabled map[string]bool) []smartAction {
+	matched := make([]smartAction, 0)
+	for _, action := range r.actions {
+		if disabled[action.id] || !action.matches(subtypes) {
+			continue
+		}
+		matched = append(matched, action)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].priority > matched[j].priority
+	})
+	return matched
+}
+
+// matches reports whether the action applies to any of subtypes
+func (s smartAction) matches(subtypes []smartSubtype) bool {
+	for _, want := range s.subtypes {
+		for _, have := range subtypes {
+			if want == have {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// smartActions is the registry cards draw from
+var smartActions = newDefaultSmartActions()
+
+// newDefaultSmartActions registers the built-in actions
+func newDefaultSmartActions() *smartActionRegistry {
+	r := &smartActionRegistry{}
+	r.register(smartAction{
+		id: "open_url", label: "Bağlantıyı aç", icon: theme.ComputerIcon(),
+		subtypes: []smartSubtype{subtypeURL}, priority: 30,
+		run: (*App).openItemURL,
+	})
+	r.register(smartAction{
+		id: "open_path", label: "Dosyayı aç", icon: theme.FileIcon(),
+		subtypes: []smartSubtype{subtypePath}, priority: 30,
+		run: (*App).openItemPath,
+	})
+	r.register(smartAction{
+		id: "show_path", label: "Klasörü aç", icon: theme.FolderOpenIcon(),
+		subtypes: []smartSubtype{subtypePath}, priority: 20,
+		run: (*App).openItemFolder,
+	})
+	r.register(smartAction{
+		id: "format_json", label: "JSON biçimlendir", icon: theme.DocumentIcon(),
+		subtypes: []smartSubtype{subtypeJSON}, priority: 20,
+		run: func(a *App, item storage.ClipboardItem) { a.copyReformattedJSON(item, true) },
+	})
+	r.register(smartAction{
+		id: "compact_json", label: "JSON sıkıştır", icon: theme.ContentCutIcon(),
+		subtypes: []smartSubtype{subtypeJSON}, priority: 10,
+		run: func(a *App, item storage.ClipboardItem) { a.copyReformattedJSON(item, false) },
+	})
+	r.register(smartAction{
+		id: "convert_color", label: "Rengi dönüştür", icon: theme.ColorPaletteIcon(),
+		subtypes: []smartSubtype{subtypeColor}, priority: 20,
+		run: (*App).copyConvertedColor,
+	})
+	return r
+}
+
+// detectSubtypes returns the subtypes of an item; text is its content (or its start)
+func detectSubtypes(item storage.ClipboardItem, class, text string) []smartSubtype {
+	if item.Type == "image" {
+		return []smartSubtype{subtypeImage}
+	}
+	if item.Type != "text" {
+		return nil
+	}
+
+	subtypes := []smartSubtype{smartSubtype(class)}
+	trimmed := strings.TrimSpace(text)
+	if isJSONText(trimmed) {
+		subtypes = append(subtypes, subtypeJSON)
+	}
+	if _, ok := parseColor(trimmed); ok {
+		subtypes = append(subtypes, subtypeColor)
+	}
+	if _, ok := parsePath(trimmed); ok {
+		subtypes = append(subtypes, subtypePath)
+	}
+	return subtypes
+}
+
+// isJSONText reports whether text is a JSON object or array
+func isJSONText(text string) bool {
+	if !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "[") {
+		return false
+	}
+	return json.Valid([]byte(text))
+}
+
+var (
+	hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	rgbColorPattern = regexp.MustCompile(`^(?i)rgb\(\s*(\d{1,3})\s*,\s*(\d{1,3})\s*,\s*(\d{1,3})\s*\)$`)
+)
+
+// parseColor reads #rgb, #rrggbb or rgb(r, g, b)
+func parseColor(text string) ([3]uint8, bool) {
+	var rgb [3]uint8
+	if m := hexColorPattern.FindStringSubmatch(text); m != nil {
+		hex := m[1]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		for i := range rgb {
+			v, _ := strconv.ParseUint(hex[i*2:i*2+2], 16, 8)
+			rgb[i] = uint8(v)
+		}
+		return rgb, true
+	}
+	if m := rgbColorPattern.FindStringSubmatch(text); m != nil {
+		for i := range rgb {
+			v, err := strconv.Atoi(m[i+1])
+			if err != nil || v > 255 {
+				return rgb, false
+			}
+			rgb[i] = uint8(v)
+		}
+		return rgb, true
+	}
+	return rgb, false
+}
+
+// convertColor returns a color in the other notation: hex becomes rgb() and back
+func convertColor(text string) (string, bool) {
+	rgb, ok := parseColor(text)
+	if !ok {
+		return "", false
+	}
+	if strings.HasPrefix(text, "#") {
+		return fmt.Sprintf("rgb(%d, %d, %d)", rgb[0], rgb[1], rgb[2]), true
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), true
+}
+
+// parsePath reads an absolute Windows path (C:\... or \\server\share) on one line,
+// with or without the quotes Explorer's "Copy as path" adds
+// The file isn't looked at; a network path could stall the list
+func parsePath(text string) (string, bool) {
+	text = strings.Trim(text, `"`)
+	if text == "" || len(text) > 1024 || strings.ContainsAny(text, "\r\n<>|?*") {
+		return "", false
+	}
+	drive := len(text) >= 3 && text[1] == ':' && (text[2] == '\\' || text[2] == '/') &&
+		(text[0] >= 'A' && text[0] <= 'Z' || text[0] >= 'a' && text[0] <= 'z')
+	unc := strings.HasPrefix(text, `\\`) && len(text) > 2
+	if !drive && !unc {
+		return "", false
+	}
+	return text, true
+}
+
+// createSmartActions builds the card's action row, nil without actions, and returns the
+// actions left for the card menu
+func (r *clipboardListRenderer) createSmartActions(item storage.ClipboardItem, class, text string) (fyne.CanvasObject, []smartAction) {
+	actions := smartActions.forSubtypes(detectSubtypes(item, class, text), r.list.disabledActions)
+	if len(actions) == 0 {
+		return nil, nil
+	}
+	shown := actions[:min(len(actions), smartActionsShown)]
+
+	row := container.NewHBox()
+	for _, action := range shown {
+		label := action.label
+		if r.list.compact {
+			label = ""
+		}
+		btn := widget.NewButtonWithIcon(label, action.icon, r.smartActionHandler(item, action))
+		btn.Importance = widget.LowImportance
+		row.Add(btn)
+	}
+	return row, actions[len(shown):]
+}
+
+// smartActionHandler returns the tap handler of an action on item
+func (r *clipboardListRenderer) smartActionHandler(item storage.ClipboardItem, action smartAction) func() {
+	return func() {
+		if r.list.onSmartAction != nil {
+			r.list.onSmartAction(item, action)
+		}
+	}
+}
+
+// disabledSmartActions returns the action IDs turned off in the settings
+func (a *App) disabledSmartActions() map[string]bool {
+	disabled := make(map[string]bool)
+	for _, id := range strings.Split(a.settings.StringWithFallback("disabled_smart_actions", ""), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			disabled[id] = true
+		}
+	}
+	return disabled
+}
+
+// setSmartActionEnabled turns one action on or off, keeping the list in registration order
+func (a *App) setSmartActionEnabled(id string, enabled bool) {
+	disabled := a.disabledSmartActions()
+	if enabled {
+		delete(disabled, id)
+	} else {
+		disabled[id] = true
+	}
+	ids := make([]string, 0, len(disabled))
+	for _, action := range smartActions.all() {
+		if disabled[action.id] {
+			ids = append(ids, action.id)
+		}
+	}
+	a.settings.SetString("disabled_smart_actions", strings.Join(ids, ","))
+}
+
+// buildSmartActionSettings returns the settings section with a check per action
+func (a *App) buildSmartActionSettings(bind func(key string, fn func())) fyne.CanvasObject {
+	label := widget.NewLabelWithStyle("Akıllı eylemler", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	section := container.NewVBox(label)
+
+	checks := make(map[string]*widget.Check)
+	disabled := a.disabledSmartActions()
+	for _, action := range smartActions.all() {
+		id := action.id
+		check := widget.NewCheck(action.label, func(checked bool) {
+			a.setSmartActionEnabled(id, checked)
+		})
+		check.Checked = !disabled[id]
+		checks[id] = check
+		section.Add(check)
+	}
+	bind("disabled_smart_actions", func() {
+		disabled := a.disabledSmartActions()
+		for id, check := range checks {
+			check.SetChecked(!disabled[id])
+		}
+	})
+	return section
+}
+
+// itemText decrypts a text item for an action, showing the error if that fails
+func (a *App) itemText(item storage.ClipboardItem) (string, bool) {
+	data, err := a.manager.GetItemContent(item.ID)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return "", false
+	}
+	defer storage.Zero(data)
+	return strings.TrimSpace(string(data)), true
+}
+
+// openItemURL opens a URL item in the browser
+func (a *App) openItemURL(item storage.ClipboardItem) {
+	text, ok := a.itemText(item)
+	if !ok {
+		return
+	}
+	u, err := url.Parse(text)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	if err := a.fyneApp.OpenURL(u); err != nil {
+		dialog.ShowError(err, a.window)
+	}
+}
+
+// openItemPath opens a path item with its default program
+func (a *App) openItemPath(item storage.ClipboardItem) {
+	a.openPath(item, false)
+}
+
+// openItemFolder opens the folder a path item is in
+func (a *App) openItemFolder(item storage.ClipboardItem) {
+	a.openPath(item, true)
+}
+
+// openPath opens a path item, or with folder the directory containing it
+func (a *App) openPath(item storage.ClipboardItem, folder bool) {
+	text, ok := a.itemText(item)
+	if !ok {
+		return
+	}
+	path, ok := parsePath(text)
+	if !ok {
+		return
+	}
+	if folder {
+		path = filepath.Dir(path)
+	}
+	u := &url.URL{Scheme: "file", Path: "/" + filepath.ToSlash(path)}
+	if strings.HasPrefix(path, `\\`) {
+		u = &url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	}
+	if err := a.fyneApp.OpenURL(u); err != nil {
+		dialog.ShowError(err, a.window)
+	}
+}
+
+// copyReformattedJSON copies a JSON item indented, or compacted without indent
+func (a *App) copyReformattedJSON(item storage.ClipboardItem, indent bool) {
+	text, ok := a.itemText(item)
+	if !ok {
+		return
+	}
+	var buf bytes.Buffer
+	var err error
+	if indent {
+		err = json.Indent(&buf, []byte(text), "", "  ")
+	} else {
+		err = json.Compact(&buf, []byte(text))
+	}
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	a.copyText(buf.String())
+}
+
+// copyConvertedColor copies a color item in the other notation
+func (a *App) copyConvertedColor(item storage.ClipboardItem) {
+	text, ok := a.itemText(item)
+	if !ok {
+		return
+	}
+	if converted, ok := convertColor(text); ok {
+		a.copyText(converted)
+	}
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	"pano/internal/storage"
+)
+
+func TestDetectSubtypes(t *testing.T) {
+	text := storage.ClipboardItem{Type: "text"}
+	tests := []struct {
+		name  string
+		item  storage.ClipboardItem
+		class string
+		text  string
+		want  []smartSubtype
+	}{
+		{"plain text", text, "text", "merhaba dünya", []smartSubtype{subtypeText}},
+		{"url", text, "url", "https://example.com", []smartSubtype{subtypeURL}},
+		{"json object", text, "code", ` {"ad": "Ayşe"} `, []smartSubtype{subtypeCode, subtypeJSON}},
+		{"json array", text, "code", "[1, 2]", []smartSubtype{subtypeCode, subtypeJSON}},
+		{"broken json", text, "code", `{"ad": }`, []smartSubtype{subtypeCode}},
+		{"number isn't json", text, "text", "42", []smartSubtype{subtypeText}},
+		{"hex color", text, "text", "#1e90ff", []smartSubtype{subtypeText, subtypeColor}},
+		{"path", text, "text", `"C:\Belgeler\not.txt"`, []smartSubtype{subtypeText, subtypePath}},
+		{"image", storage.ClipboardItem{Type: "image"}, "", "", []smartSubtype{subtypeImage}},
+		{"other type", storage.ClipboardItem{Type: "files"}, "", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectSubtypes(tt.item, tt.class, tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("detectSubtypes(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertColor(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{"#1e90ff", "rgb(30, 144, 255)", true},
+		{"#FFF", "rgb(255, 255, 255)", true},
+		{"rgb(30, 144, 255)", "#1e90ff", true},
+		{"RGB(0,0,0)", "#000000", true},
+		{"rgb(256, 0, 0)", "", false},
+		{"#12345", "", false},
+		{"kırmızı", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := convertColor(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("convertColor(%q) = %q, %v; want %q, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{`C:\Users\ayşe\not.txt`, `C:\Users\ayşe\not.txt`, true},
+		{`"d:/proje/main.go"`, `d:/proje/main.go`, true},
+		{`\\sunucu\paylaşım`, `\\sunucu\paylaşım`, true},
+		{`relative\path.txt`, "", false},
+		{`C:`, "", false},
+		{"C:\\a\nC:\\b", "", false},
+		{`C:\dosya?.txt`, "", false},
+		{`\\`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePath(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parsePath(%q) = %q, %v; want %q, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// actionIDs returns the IDs of actions, in order
+func actionIDs(actions []smartAction) []string {
+	ids := make([]string, 0, len(actions))
+	for _, action := range actions {
+		ids = append(ids, action.id)
+	}
+	return ids
+}
+
+// Actions come highest priority first, registration order breaking ties, without the
+// disabled ones; registering an ID again replaces the action in place
+func TestSmartActionRegistry(t *testing.T) {
+	r := &smartActionRegistry{}
+	r.register(smartAction{id: "a", subtypes: []smartSubtype{subtypeText}, priority: 10})
+	r.register(smartAction{id: "b", subtypes: []smartSubtype{subtypeJSON}, priority: 20})
+	r.register(smartAction{id: "c", subtypes: []smartSubtype{subtypeText, subtypeJSON}, priority: 10})
+	r.register(smartAction{id: "a", label: "yeni", subtypes: []smartSubtype{subtypeText}, priority: 10})
+
+	if got := actionIDs(r.all()); !slices.Equal(got, []string{"a", "b", "c"}) || r.all()[0].label != "yeni" {
+		t.Errorf("registered %q", got)
+	}
+	subtypes := []smartSubtype{subtypeCode, subtypeJSON, subtypeText}
+	if got := actionIDs(r.forSubtypes(subtypes, nil)); !slices.Equal(got, []string{"b", "a", "c"}) {
+		t.Errorf("actions are %q", got)
+	}
+	if got := actionIDs(r.forSubtypes(subtypes, map[string]bool{"b": true})); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("actions with b disabled are %q", got)
+	}
+	if got := r.forSubtypes([]smartSubtype{subtypeImage}, nil); len(got) != 0 {
+		t.Errorf("image got %q", actionIDs(got))
+	}
+}
+
+func TestDefaultSmartActions(t *testing.T) {
+	r := newDefaultSmartActions()
+	path := detectSubtypes(storage.ClipboardItem{Type: "text"}, "text", `C:\not.txt`)
+	if got := actionIDs(r.forSubtypes(path, nil)); !slices.Equal(got, []string{"open_path", "show_path"}) {
+		t.Errorf("path actions are %q", got)
+	}
+	code := detectSubtypes(storage.ClipboardItem{Type: "text"}, "code", `{"a":1}`)
+	if got := actionIDs(r.forSubtypes(code, nil)); !slices.Equal(got, []string{"format_json", "compact_json"}) {
+		t.Errorf("JSON actions are %q", got)
+	}
+}
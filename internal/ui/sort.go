@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"slices"
+
+	"pano/internal/storage"
+)
+
+// sortOrder selects how history items are ordered; pinned items stay first in every order
+type sortOrder string
+
+const (
+	sortNewest   sortOrder = "newest"    // Newest capture first, as stored
+	sortMostUsed sortOrder = "most_used" // Most often copied back first, then most recently used
+)
+
+// sortOrderOptions are the order choices next to the search box
+var sortOrderOptions = []struct {
+	label string
+	order sortOrder
+}{
+	{"En yeni", sortNewest},
+	{"En çok kullanılan", sortMostUsed},
+}
+
+// sortOrderLabels returns the labels of sortOrderOptions
+func sortOrderLabels() []string {
+	labels := make([]string, 0, len(sortOrderOptions))
+	for _, opt := range sortOrderOptions {
+		labels = append(labels, opt.label)
+	}
+	return labels
+}
+
+// sortItems orders items, which come pinned first and newest first, by order in place
+// Pinned and unpinned items are sorted separately, so pinned ones stay on top
+func sortItems(items []storage.ClipboardItem, order sortOrder) {
+	if order != sortMostUsed {
+		return
+	}
+	pinned := 0
+	for pinned < len(items) && items[pinned].Pinned {
+		pinned++
+	}
+	byUse := func(a, b storage.ClipboardItem) int {
+		if a.CopyCount != b.CopyCount {
+			return b.CopyCount - a.CopyCount
+		}
+		return b.LastUsed.Compare(a.LastUsed)
+	}
+	// Stable, so items never copied back keep the newest-first order
+	slices.SortStableFunc(items[:pinned], byUse)
+	slices.SortStableFunc(items[pinned:], byUse)
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+)
+
+// createStructuredPreview renders contacts and calendar events as a mini card
+// Returns nil when the class has no structured preview or the text doesn't parse
+func (r *clipboardListRenderer) createStructuredPreview(class, text string) fyne.CanvasObject {
+	switch class {
+	case storage.ClassVCard:
+		if contact, ok := clipboard.ParseVCard(text); ok {
+			return r.createContactPreview(contact)
+		}
+	case storage.ClassCalendar:
+		if event, ok := clipboard.ParseICalendar(text); ok {
+			return createEventPreview(event)
+		}
+	}
+	return nil
+}
+
+// createContactPreview shows name, organization, phone and email with copy actions
+func (r *clipboardListRenderer) createContactPreview(contact clipboard.Contact) fyne.CanvasObject {
+	rows := container.NewVBox()
+
+	title := contact.Name
+	if title == "" {
+		title = "Kişi"
+	}
+	rows.Add(container.NewHBox(
+		widget.NewIcon(theme.AccountIcon()),
+		widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	))
+	if contact.Org != "" {
+		rows.Add(widget.NewLabel(contact.Org))
+	}
+
+	actions := container.NewHBox()
+	if len(contact.Phones) > 0 {
+		rows.Add(widget.NewLabel("Tel: " + strings.Join(contact.Phones, ", ")))
+		phone := contact.Phones[0]
+		actions.Add(widget.NewButtonWithIcon("Telefonu kopyala", theme.ContentCopyIcon(), func() {
+			if r.list.onCopyText != nil {
+				r.list.onCopyText(phone)
+			}
+		}))
+	}
+	if len(contact.Emails) > 0 {
+		rows.Add(widget.NewLabel("E-posta: " + strings.Join(contact.Emails, ", ")))
+		email := contact.Emails[0]
+		actions.Add(widget.NewButtonWithIcon("E-postayı kopyala", theme.MailComposeIcon(), func() {
+			if r.list.onCopyText != nil {
+				r.list.onCopyText(email)
+			}
+		}))
+	}
+	if len(actions.Objects) > 0 {
+		rows.Add(actions)
+	}
+	return rows
+}
+
+// createEventPreview shows an event's title, time and location
+func createEventPreview(event clipboard.Event) fyne.CanvasObject {
+	title := event.Summary
+	if title == "" {
+		title = "Etkinlik"
+	}
+	rows := container.NewVBox(container.NewHBox(
+		widget.NewIcon(theme.HistoryIcon()),
+		widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+	))
+
+	if !event.Start.IsZero() {
+		rows.Add(widget.NewLabel(formatEventTime(event)))
+	}
+	if event.Location != "" {
+		rows.Add(widget.NewLabel("Yer: " + event.Location))
+	}
+	return rows
+}
+
+// formatEventTime formats an event's start and end for display
+func formatEventTime(event clipboard.Event) string {
+	if event.AllDay {
+		return event.Start.Format("02.01.2006") + " (tüm gün)"
+	}
+	text := event.Start.Format("02.01.2006 15:04")
+	if !event.End.IsZero() {
+		if event.End.YearDay() == event.Start.YearDay() && event.End.Year() == event.Start.Year() {
+			text += " - " + event.End.Format("15:04")
+		} else {
+			text += " - " + event.End.Format("02.01.2006 15:04")
+		}
+	}
+	return text
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+// platformInfo has no extra details on non-Windows platforms
+func platformInfo() map[string]string {
+	return map[string]string{}
+}
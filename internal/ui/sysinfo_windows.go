@@ -0,0 +1,24 @@
+//go:build windows
+// +build windows
+
+package ui
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetSystemMetrics = user32.NewProc("GetSystemMetrics")
+
+const smCMonitors = 80 // SM_CMONITORS
+
+// platformInfo returns the Windows version and the number of monitors
+func platformInfo() map[string]string {
+	v := windows.RtlGetVersion()
+	monitors, _, _ := procGetSystemMetrics.Call(smCMonitors)
+	return map[string]string{
+		"os_version": fmt.Sprintf("Windows %d.%d.%d", v.MajorVersion, v.MinorVersion, v.BuildNumber),
+		"monitors":   fmt.Sprintf("%d", monitors),
+	}
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// tagQueryPrefix starts a search for one tag, e.g. "etiket:sql"
+const tagQueryPrefix = "etiket:"
+
+// showTagEditor edits an item's tags as a comma-separated list
+// Tags already used on other items are offered below the entry
+func (a *App) showTagEditor(item storage.ClipboardItem) {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("sql, adres, şifreler")
+	entry.SetText(strings.Join(item.Tags, ", "))
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+	errorLabel.Hide()
+
+	suggestions := container.NewHBox()
+	for _, tag := range a.manager.AllTags() {
+		if item.HasTag(tag) {
+			continue
+		}
+		btn := widget.NewButton(tag, nil)
+		btn.Importance = widget.LowImportance
+		btn.OnTapped = func() {
+			text := strings.TrimRight(strings.TrimSpace(entry.Text), ",")
+			if text != "" {
+				text += ", "
+			}
+			entry.SetText(text + tag)
+			btn.Hide()
+		}
+		suggestions.Add(btn)
+	}
+
+	var d *dialog.CustomDialog
+	save := func() {
+		err := a.manager.SetTags(item.ID, strings.Split(entry.Text, ","))
+		switch {
+		case err == nil:
+			d.Hide()
+		case errors.Is(err, storage.ErrTooManyTags):
+			errorLabel.SetText(fmt.Sprintf("Bir öğeye en fazla %d etiket eklenebilir", storage.MaxTagsPerItem))
+			errorLabel.Show()
+		case errors.Is(err, storage.ErrTagTooLong):
+			errorLabel.SetText(fmt.Sprintf("Etiketler en fazla %d karakter olabilir", storage.MaxTagLength))
+			errorLabel.Show()
+		default:
+			dialog.ShowError(err, a.window)
+		}
+	}
+	entry.OnSubmitted = func(string) { save() }
+
+	saveBtn := widget.NewButtonWithIcon("Kaydet", theme.ConfirmIcon(), save)
+	saveBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButtonWithIcon("İptal", theme.CancelIcon(), func() {
+		d.Hide()
+	})
+
+	hint := widget.NewLabel("Etiketleri virgülle ayırın. Aramada \"" + tagQueryPrefix + "sql\" yalnızca o etiketi taşıyanları listeler.")
+	hint.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(hint, entry, errorLabel)
+	if len(suggestions.Objects) > 0 {
+		content.Add(widget.NewLabelWithStyle("Kullanılan etiketler", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		content.Add(container.NewHScroll(suggestions))
+	}
+
+	d = dialog.NewCustomWithoutButtons("Etiketler", content, a.window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, saveBtn})
+	d.Resize(fyne.NewSize(420, 260))
+	d.Show()
+	a.window.Canvas().Focus(entry)
+}
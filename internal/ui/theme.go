@@ -33,6 +33,28 @@ var (
 	darkPinBrd  = color.RGBA{R: 180, G: 140, B: 60, A: 255}
 )
 
+// Badge backgrounds by kind, for the light and dark themes
+var (
+	lightBadges = map[string]color.Color{
+		badgePinned:    color.RGBA{R: 245, G: 166, B: 35, A: 255},
+		badgeText:      color.RGBA{R: 0, G: 120, B: 212, A: 255},
+		badgeImage:     color.RGBA{R: 136, G: 23, B: 152, A: 255},
+		badgeFile:      color.RGBA{R: 118, G: 118, B: 118, A: 255},
+		badgeTag:       color.RGBA{R: 16, G: 124, B: 16, A: 255},
+		badgeStale:     color.RGBA{R: 142, G: 110, B: 74, A: 255},
+		badgeProtected: color.RGBA{R: 164, G: 38, B: 44, A: 255},
+	}
+	darkBadges = map[string]color.Color{
+		badgePinned:    color.RGBA{R: 214, G: 150, B: 40, A: 255},
+		badgeText:      color.RGBA{R: 40, G: 110, B: 190, A: 255},
+		badgeImage:     color.RGBA{R: 150, G: 95, B: 200, A: 255},
+		badgeFile:      color.RGBA{R: 96, G: 96, B: 96, A: 255},
+		badgeTag:       color.RGBA{R: 50, G: 140, B: 80, A: 255},
+		badgeStale:     color.RGBA{R: 130, G: 105, B: 75, A: 255},
+		badgeProtected: color.RGBA{R: 170, G: 60, B: 64, A: 255},
+	}
+)
+
 type PanoTheme struct {
 	variant fyne.ThemeVariant
 }
@@ -98,11 +120,17 @@ func GetPrimaryColor() color.Color {
 	return lightPrimary
 }
 
+// GetBadgeColors returns a badge's background and the text color with the better contrast on it
 func GetBadgeColors(badgeType string) (bg color.Color, fg color.Color) {
+	badges := lightBadges
 	if IsDarkMode() {
-		return darkSurface, darkTextSec
+		badges = darkBadges
+	}
+	bg, ok := badges[badgeType]
+	if !ok {
+		bg = badges[badgeFile]
 	}
-	return lightSurface, lightTextSec
+	return bg, contrastText(bg)
 }
 
 func (t *PanoTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"image"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+// Image preview bounds inside a card
+const (
+	thumbMaxWidth  = 320  // Full card content width
+	thumbMinHeight = 60   // Very wide images are letterboxed to at least this height
+	thumbMaxHeight = 180  // Tall images are narrowed to stay within this height
+	stripRatio     = 6.0  // Width/height ratio from which a scrollable strip is used
+	stripMaxHeight = 120  // Natural height cap for strips
+	stripMaxWidth  = 4096 // Decoded strip width cap to keep the cache small
+)
+
+// thumbLayout describes how an image preview is sized in a card
+type thumbLayout struct {
+	Width, Height  int  // Preview box (the visible viewport for strips)
+	ImageW, ImageH int  // Rendered image size; differs from the box only for strips
+	Strip          bool // Horizontally scrollable strip instead of a fitted thumbnail
+}
+
+// computeThumbLayout sizes an image preview from the image dimensions
+// The result only depends on w and h, so cards keep a stable min size across refreshes
+func computeThumbLayout(w, h int) thumbLayout {
+	if w <= 0 || h <= 0 {
+		return thumbLayout{Width: thumbMaxWidth, Height: thumbMinHeight, ImageW: thumbMaxWidth, ImageH: thumbMinHeight}
+	}
+	ratio := float64(w) / float64(h)
+
+	// Extreme panoramas become unreadable slivers when fitted, so show them at natural height
+	if ratio >= stripRatio {
+		imgH := h
+		if imgH > stripMaxHeight {
+			imgH = stripMaxHeight
+		}
+		imgW := int(float64(imgH) * ratio)
+		if imgW > stripMaxWidth {
+			imgW = stripMaxWidth
+			imgH = int(float64(imgW) / ratio)
+		}
+		if imgW <= thumbMaxWidth {
+			// Short enough to fit the card without scrolling
+			return thumbLayout{Width: imgW, Height: imgH, ImageW: imgW, ImageH: imgH}
+		}
+		return thumbLayout{Width: thumbMaxWidth, Height: imgH, ImageW: imgW, ImageH: imgH, Strip: true}
+	}
+
+	// Fit the full card width, then narrow the box for tall images
+	boxW := thumbMaxWidth
+	boxH := int(float64(boxW) / ratio)
+	if boxH > thumbMaxHeight {
+		boxH = thumbMaxHeight
+		boxW = int(float64(boxH) * ratio)
+	}
+
+	// Never upscale small images
+	if w < boxW && h < boxH {
+		boxW, boxH = w, h
+	}
+	if boxW < 1 {
+		boxW = 1
+	}
+
+	// Wide images letterbox vertically instead of shrinking the card
+	imgH := boxH
+	if boxH < thumbMinHeight {
+		boxH = thumbMinHeight
+	}
+	return thumbLayout{Width: boxW, Height: boxH, ImageW: boxW, ImageH: imgH}
+}
+
+// newImagePreview creates the preview widget for an image thumbnail
+func newImagePreview(img image.Image, tl thumbLayout) fyne.CanvasObject {
+	imgWidget := canvas.NewImageFromImage(img)
+	imgWidget.ScaleMode = canvas.ImageScaleSmooth
+
+	if tl.Strip {
+		imgWidget.FillMode = canvas.ImageFillStretch
+		imgWidget.SetMinSize(fyne.NewSize(float32(tl.ImageW), float32(tl.ImageH)))
+		scroll := container.NewHScroll(imgWidget)
+		scroll.SetMinSize(fyne.NewSize(float32(tl.Width), float32(tl.Height)))
+		return scroll
+	}
+
+	imgWidget.FillMode = canvas.ImageFillContain
+	imgWidget.SetMinSize(fyne.NewSize(float32(tl.Width), float32(tl.Height)))
+	return container.NewCenter(imgWidget)
+}
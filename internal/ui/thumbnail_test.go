@@ -0,0 +1,33 @@
+package ui
+
+import "testing"
+
+// TestComputeThumbLayout sizes previews from ordinary photos to panoramas too wide to
+// fit, which become strips capped in height and decoded width
+func TestComputeThumbLayout(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h int
+		want thumbLayout
+	}{
+		{"unknown size", 0, 0, thumbLayout{Width: 320, Height: 60, ImageW: 320, ImageH: 60}},
+		{"landscape photo", 1920, 1080, thumbLayout{Width: 320, Height: 180, ImageW: 320, ImageH: 180}},
+		{"portrait photo", 1080, 1920, thumbLayout{Width: 101, Height: 180, ImageW: 101, ImageH: 180}},
+		{"small icon, not upscaled", 100, 50, thumbLayout{Width: 100, Height: 60, ImageW: 100, ImageH: 50}},
+		{"wide, letterboxed", 1100, 200, thumbLayout{Width: 320, Height: 60, ImageW: 320, ImageH: 58}},
+		{"short strip fits the card", 300, 40, thumbLayout{Width: 300, Height: 40, ImageW: 300, ImageH: 40}},
+		{"panorama", 6000, 500, thumbLayout{Width: 320, Height: 120, ImageW: 1440, ImageH: 120, Strip: true}},
+		{"panorama over the decode cap", 60000, 1000, thumbLayout{Width: 320, Height: 68, ImageW: 4096, ImageH: 68, Strip: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeThumbLayout(tt.w, tt.h)
+			if got != tt.want {
+				t.Errorf("computeThumbLayout(%d, %d) = %+v, want %+v", tt.w, tt.h, got, tt.want)
+			}
+			if got.Width > thumbMaxWidth || got.Height > thumbMaxHeight {
+				t.Errorf("box %dx%d is larger than a card allows", got.Width, got.Height)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"pano/internal/storage"
+)
+
+// showTitleEditor names an item; the title is shown in bold above its preview
+// Saving an empty title removes it
+func (a *App) showTitleEditor(item storage.ClipboardItem) {
+	current, err := a.manager.GetItemUserTitle(item.ID)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("Müşteri sorgusu, ayar JSON'u…")
+	entry.SetText(current)
+
+	errorLabel := widget.NewLabel("")
+	errorLabel.Importance = widget.DangerImportance
+	errorLabel.Wrapping = fyne.TextWrapWord
+	errorLabel.Hide()
+
+	var d *dialog.CustomDialog
+	save := func() {
+		err := a.manager.SetTitle(item.ID, entry.Text)
+		switch {
+		case err == nil:
+			d.Hide()
+		case errors.Is(err, storage.ErrTitleTooLong):
+			errorLabel.SetText(fmt.Sprintf("Başlık en fazla %d karakter olabilir", storage.MaxUserTitleLength))
+			errorLabel.Show()
+		default:
+			dialog.ShowError(err, a.window)
+		}
+	}
+	entry.OnSubmitted = func(string) { save() }
+
+	saveBtn := widget.NewButtonWithIcon("Kaydet", theme.ConfirmIcon(), save)
+	saveBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButtonWithIcon("İptal", theme.CancelIcon(), func() {
+		d.Hide()
+	})
+
+	hint := widget.NewLabel("Başlık kartta önizlemenin üstünde gösterilir ve aramada eşleşir. Boş bırakılırsa kaldırılır.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	d = dialog.NewCustomWithoutButtons("Başlık", container.NewVBox(hint, entry, errorLabel), a.window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn, saveBtn})
+	d.Resize(fyne.NewSize(420, 200))
+	d.Show()
+	a.window.Canvas().Focus(entry)
+}
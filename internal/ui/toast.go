@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	toastDuration  = 3 * time.Second        // Time a toast stays on screen
+	toastSlideTime = 200 * time.Millisecond // Slide-in animation length
+	toastMaxStack  = 3                      // Older toasts are dropped beyond this
+	toastMargin    = 56                     // Distance from the window bottom (clears the footer)
+	toastSpacing   = 6
+)
+
+// toastRequest is a message queued from any goroutine
+type toastRequest struct {
+	message     string
+	actionLabel string // Optional button, e.g. "Geri Al"
+	action      func()
+}
+
+// toast is a single message on screen
+type toast struct {
+	object fyne.CanvasObject
+	offset float32 // Extra downward offset while sliding in
+	timer  *time.Timer
+}
+
+// toastManager shows transient messages stacked at the bottom of the window
+// Show is safe from any goroutine; requests are drained on the UI thread
+type toastManager struct {
+	requests chan toastRequest
+	overlay  *fyne.Container
+	layout   *toastLayout
+}
+
+// newToastManager creates the overlay and starts draining requests
+func newToastManager() *toastManager {
+	tl := &toastLayout{}
+	tm := &toastManager{
+		requests: make(chan toastRequest, 16),
+		layout:   tl,
+		overlay:  container.New(tl),
+	}
+	go tm.drain()
+	return tm
+}
+
+// Overlay returns the container to stack over the window content
+func (tm *toastManager) Overlay() fyne.CanvasObject {
+	return tm.overlay
+}
+
+// Show queues a message
+func (tm *toastManager) Show(message string) {
+	tm.enqueue(toastRequest{message: message})
+}
+
+// ShowWithAction queues a message with one action button
+func (tm *toastManager) ShowWithAction(message, actionLabel string, action func()) {
+	tm.enqueue(toastRequest{message: message, actionLabel: actionLabel, action: action})
+}
+
+func (tm *toastManager) enqueue(req toastRequest) {
+	select {
+	case tm.requests <- req:
+	default:
+		// A full queue means toasts are arriving faster than anyone can read them
+	}
+}
+
+// drain moves queued requests onto the UI thread
+func (tm *toastManager) drain() {
+	for req := range tm.requests {
+		fyne.Do(func() {
+			tm.add(req)
+		})
+	}
+}
+
+// add shows a toast (UI thread only)
+func (tm *toastManager) add(req toastRequest) {
+	t := &toast{}
+
+	label := widget.NewLabel(req.message)
+	label.Truncation = fyne.TextTruncateEllipsis
+	var body fyne.CanvasObject = label
+	if req.action != nil {
+		btn := widget.NewButton(req.actionLabel, nil)
+		btn.Importance = widget.LowImportance
+		btn.OnTapped = func() {
+			tm.remove(t)
+			req.action()
+		}
+		body = container.NewBorder(nil, nil, nil, btn, label)
+	}
+
+	bg := canvas.NewRectangle(theme.Color(theme.ColorNameOverlayBackground))
+	bg.CornerRadius = 6
+	bg.StrokeColor = theme.Color(theme.ColorNameShadow)
+	bg.StrokeWidth = 1
+	t.object = container.NewStack(bg, container.NewPadded(body))
+
+	// Drop the oldest toasts beyond the stack limit
+	for len(tm.layout.toasts) >= toastMaxStack {
+		tm.remove(tm.layout.toasts[0])
+	}
+	tm.layout.toasts = append(tm.layout.toasts, t)
+	tm.overlay.Add(t.object)
+
+	height := t.object.MinSize().Height + toastMargin
+	slide := fyne.NewAnimation(toastSlideTime, func(progress float32) {
+		t.offset = (1 - progress) * height
+		tm.overlay.Refresh()
+	})
+	slide.Curve = fyne.AnimationEaseOut
+	slide.Start()
+
+	t.timer = time.AfterFunc(toastDuration, func() {
+		fyne.Do(func() {
+			tm.remove(t)
+		})
+	})
+}
+
+// remove hides a toast if it is still shown (UI thread only)
+func (tm *toastManager) remove(t *toast) {
+	for i, shown := range tm.layout.toasts {
+		if shown == t {
+			if t.timer != nil {
+				t.timer.Stop()
+			}
+			tm.layout.toasts = append(tm.layout.toasts[:i], tm.layout.toasts[i+1:]...)
+			tm.overlay.Remove(t.object)
+			return
+		}
+	}
+}
+
+// toastLayout stacks toasts upwards from the bottom center, newest at the bottom
+type toastLayout struct {
+	toasts []*toast
+}
+
+func (l *toastLayout) Layout(_ []fyne.CanvasObject, size fyne.Size) {
+	width := size.Width - 2*theme.Padding()*4
+	if width > 340 {
+		width = 340
+	}
+	y := size.Height - toastMargin
+	for i := len(l.toasts) - 1; i >= 0; i-- {
+		t := l.toasts[i]
+		h := t.object.MinSize().Height
+		y -= h
+		t.object.Resize(fyne.NewSize(width, h))
+		t.object.Move(fyne.NewPos((size.Width-width)/2, y+t.offset))
+		y -= toastSpacing
+	}
+}
+
+// MinSize is zero so the overlay never affects the window layout
+func (l *toastLayout) MinSize(_ []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(0, 0)
+}
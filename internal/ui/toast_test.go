@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// newTestToasts returns a toast manager whose requests aren't drained, so add runs
+// directly on the test thread
+func newTestToasts(t *testing.T, queue int) *toastManager {
+	test.NewTempApp(t)
+	tl := &toastLayout{}
+	tm := &toastManager{requests: make(chan toastRequest, queue), layout: tl, overlay: container.New(tl)}
+	t.Cleanup(func() {
+		for len(tm.layout.toasts) > 0 {
+			tm.remove(tm.layout.toasts[0])
+		}
+	})
+	return tm
+}
+
+// toastMessages returns the text of the toasts on screen, oldest first
+func toastMessages(tm *toastManager) []string {
+	var got []string
+	for _, shown := range tm.layout.toasts {
+		var walk func(o fyne.CanvasObject)
+		walk = func(o fyne.CanvasObject) {
+			switch o := o.(type) {
+			case *widget.Label:
+				got = append(got, o.Text)
+			case *fyne.Container:
+				for _, child := range o.Objects {
+					walk(child)
+				}
+			}
+		}
+		walk(shown.object)
+	}
+	return got
+}
+
+// At most toastMaxStack toasts are shown; the oldest make room
+func TestToastStack(t *testing.T) {
+	tm := newTestToasts(t, 1)
+	for _, message := range []string{"bir", "iki", "üç", "dört"} {
+		tm.add(toastRequest{message: message})
+	}
+	if got, want := toastMessages(tm), []string{"iki", "üç", "dört"}; !slices.Equal(got, want) {
+		t.Errorf("toasts are %q, want %q", got, want)
+	}
+	if len(tm.overlay.Objects) != toastMaxStack {
+		t.Errorf("%d objects in the overlay, want %d", len(tm.overlay.Objects), toastMaxStack)
+	}
+
+	// Newest at the bottom, centered and no wider than the cap
+	tm.layout.Layout(nil, fyne.NewSize(400, 600))
+	var lastY float32
+	for i, shown := range tm.layout.toasts {
+		pos, size := shown.object.Position(), shown.object.Size()
+		if i > 0 && pos.Y <= lastY {
+			t.Errorf("toast %d is above an older one", i)
+		}
+		if size.Width > 340 || pos.X*2+size.Width != 400 {
+			t.Errorf("toast %d is %v wide at x %v", i, size.Width, pos.X)
+		}
+		lastY = pos.Y
+	}
+}
+
+// The action button runs its action and closes the toast
+func TestToastAction(t *testing.T) {
+	tm := newTestToasts(t, 1)
+	undone := false
+	tm.add(toastRequest{message: "Silindi", actionLabel: "Geri Al", action: func() { undone = true }})
+	tm.add(toastRequest{message: "Kopyalandı"})
+
+	var button *widget.Button
+	var find func(o fyne.CanvasObject)
+	find = func(o fyne.CanvasObject) {
+		switch o := o.(type) {
+		case *widget.Button:
+			button = o
+		case *fyne.Container:
+			for _, child := range o.Objects {
+				find(child)
+			}
+		}
+	}
+	find(tm.layout.toasts[0].object)
+	if button == nil || button.Text != "Geri Al" {
+		t.Fatal("the toast has no action button")
+	}
+	test.Tap(button)
+	if !undone {
+		t.Error("the action didn't run")
+	}
+	if got := toastMessages(tm); !slices.Equal(got, []string{"Kopyalandı"}) {
+		t.Errorf("toasts after the action are %q", got)
+	}
+}
+
+// Requests beyond the queue are dropped rather than blocking the caller
+func TestToastQueueFull(t *testing.T) {
+	tm := newTestToasts(t, 1)
+	tm.Show("bir")
+	tm.Show("iki")
+	if len(tm.requests) != 1 {
+		t.Errorf("%d queued requests, want 1", len(tm.requests))
+	}
+}
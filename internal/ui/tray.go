@@ -1,10 +1,82 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/systray"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
 )
 
+// trayRefreshInterval throttles tray menu rebuilds
+const trayRefreshInterval = time.Second
+
+// trayPreviewMaxChars limits the last item preview in the tray menu
+const trayPreviewMaxChars = 30
+
+// trayState is everything the tray menu displays
+type trayState struct {
+	paused      bool
+	pausedUntil time.Time // End of a timed pause, zero if paused until resumed
+	lastPreview string    // Empty when history is empty
+}
+
+// trayActions are the handlers wired into the tray menu
+type trayActions struct {
+	show          func()
+	hide          func()
+	toggleCapture func()
+	pauseTimed    func()
+	copyLast      func()
+	quit          func()
+}
+
+// buildTrayMenu builds the tray menu model for the given state
+func buildTrayMenu(state trayState, actions trayActions) *fyne.Menu {
+	captureLabel := "Yakalamayı Duraklat"
+	if !state.pausedUntil.IsZero() {
+		captureLabel += " (bitiş " + state.pausedUntil.Format("15:04") + ")"
+	}
+	captureItem := fyne.NewMenuItem(captureLabel, actions.toggleCapture)
+	captureItem.Checked = state.paused
+	pauseTimedItem := fyne.NewMenuItem(fmt.Sprintf("%d dakika duraklat", int(clipboard.DefaultPauseDuration/time.Minute)), actions.pauseTimed)
+
+	lastLabel := "Son: (boş)"
+	if state.lastPreview != "" {
+		lastLabel = "Son: " + state.lastPreview
+	}
+	lastItem := fyne.NewMenuItem(lastLabel, nil)
+	lastItem.Disabled = true
+
+	copyLastItem := fyne.NewMenuItem("Son öğeyi kopyala", actions.copyLast)
+	copyLastItem.Disabled = state.lastPreview == ""
+
+	return fyne.NewMenu("",
+		fyne.NewMenuItem("Aç", actions.show),
+		fyne.NewMenuItem("Gizle", actions.hide),
+		fyne.NewMenuItemSeparator(),
+		captureItem,
+		pauseTimedItem,
+		lastItem,
+		copyLastItem,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Çıkış", actions.quit),
+	)
+}
+
+// trayRefresher coalesces tray menu rebuild requests
+type trayRefresher struct {
+	mu      sync.Mutex
+	pending bool
+	last    time.Time
+}
+
 // SetupSystemTray creates a system tray icon with menu
 func SetupSystemTray(app *App) {
 	if desk, ok := app.fyneApp.(desktop.App); ok {
@@ -14,19 +86,133 @@ func SetupSystemTray(app *App) {
 			desk.SetSystemTrayIcon(appIcon)
 		}
 
-		menu := fyne.NewMenu("",
-			fyne.NewMenuItem("Aç", func() {
-				app.Show()
-			}),
-			fyne.NewMenuItem("Gizle", func() {
-				app.Hide()
-			}),
-			fyne.NewMenuItemSeparator(),
-			fyne.NewMenuItem("Çıkış", func() {
-				app.fyneApp.Quit()
-			}),
-		)
+		desk.SetSystemTrayMenu(app.buildTrayMenu())
+		bindTrayClick(func() {
+			fyne.Do(func() {
+				app.Toggle(ShowSourceTray)
+			})
+		})
+	}
+}
+
+// buildTrayMenu builds the tray menu from the current app state
+func (a *App) buildTrayMenu() *fyne.Menu {
+	state := trayState{
+		paused:      a.monitor.IsPaused(),
+		pausedUntil: a.monitor.PausedUntil(),
+		lastPreview: a.lastItemPreview(),
+	}
+
+	return buildTrayMenu(state, trayActions{
+		show: func() {
+			a.Show(ShowSourceTray)
+		},
+		hide: func() {
+			a.Hide()
+		},
+		// The pause callback refreshes the tray and the header indicator
+		toggleCapture: a.togglePause,
+		pauseTimed:    a.pauseForDefault,
+		copyLast: func() {
+			if id := a.lastItemID(); id != "" {
+				err := a.manager.CopyToClipboard(id)
+				if errors.Is(err, storage.ErrProtected) {
+					// The passphrase can only be asked for in the window
+					a.Show(ShowSourceTray)
+					a.promptProtectedCopy(id, nil)
+				} else if err == nil {
+					a.sendNotification("Pano", "Son öğe panoya kopyalandı")
+				}
+			}
+		},
+		quit: func() {
+			a.fyneApp.Quit()
+		},
+	})
+}
+
+// refreshTray rebuilds the tray menu, at most once per trayRefreshInterval
+func (a *App) refreshTray() {
+	desk, ok := a.fyneApp.(desktop.App)
+	if !ok {
+		return
+	}
+
+	a.tray.mu.Lock()
+	if a.tray.pending {
+		a.tray.mu.Unlock()
+		return
+	}
+	a.tray.pending = true
+	delay := trayRefreshInterval - time.Since(a.tray.last)
+	if delay < 0 {
+		delay = 0
+	}
+	a.tray.mu.Unlock()
+
+	time.AfterFunc(delay, func() {
+		a.tray.mu.Lock()
+		a.tray.pending = false
+		a.tray.last = time.Now()
+		a.tray.mu.Unlock()
+
+		fyne.Do(func() {
+			desk.SetSystemTrayMenu(a.buildTrayMenu())
+			systray.SetTooltip(a.trayTooltip())
+		})
+	})
+}
+
+// setTrayIcon replaces the tray icon, e.g. after a DPI change
+func (a *App) setTrayIcon(icon fyne.Resource) {
+	if desk, ok := a.fyneApp.(desktop.App); ok {
+		desk.SetSystemTrayIcon(icon)
+	}
+}
+
+// lastItemID returns the ID of the most recently captured item
+func (a *App) lastItemID() string {
+	var latest string
+	var latestTime time.Time
+	for _, item := range a.manager.GetAllItems() {
+		if latest == "" || item.Timestamp.After(latestTime) {
+			latest = item.ID
+			latestTime = item.Timestamp
+		}
+	}
+	return latest
+}
+
+// lastItemPreview returns a short preview of the most recent item
+func (a *App) lastItemPreview() string {
+	id := a.lastItemID()
+	if id == "" {
+		return ""
+	}
 
-		desk.SetSystemTrayMenu(menu)
+	for _, item := range a.manager.GetAllItems() {
+		if item.ID != id {
+			continue
+		}
+		if item.IsProtected() {
+			return "[Korumalı]"
+		}
+		if item.Type == "files" {
+			return "[Dosyalar]"
+		}
+		if item.Type != "text" {
+			return "[Görsel]"
+		}
+		data, err := a.manager.GetItemContent(id)
+		if err != nil {
+			return "[Okunamadı]"
+		}
+		text := buildFlatPreview(string(data))
+		storage.Zero(data)
+		if cut, ok := storage.CutGraphemes(text, trayPreviewMaxChars); ok {
+			return cut + "…"
+		}
+		return text
 	}
+	return ""
 }
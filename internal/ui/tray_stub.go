@@ -0,0 +1,8 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+// bindTrayClick keeps Fyne's tray behavior on non-Windows platforms, where a left
+// click opens the menu as users expect there
+func bindTrayClick(toggle func()) {}
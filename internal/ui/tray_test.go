@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"pano/internal/clipboard"
+)
+
+// menuLabels returns the labels of menu's items in order, "-" for separators
+func menuLabels(menu *fyne.Menu) []string {
+	var labels []string
+	for _, item := range menu.Items {
+		if item.IsSeparator {
+			labels = append(labels, "-")
+			continue
+		}
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+// TestBuildTrayMenu checks the tray menu's items, in order, and how pausing and the
+// last item change them
+func TestBuildTrayMenu(t *testing.T) {
+	pauseLabel := fmt.Sprintf("%d dakika duraklat", int(clipboard.DefaultPauseDuration/time.Minute))
+	tests := []struct {
+		name         string
+		state        trayState
+		wantCapture  string
+		wantLast     string
+		wantChecked  bool
+		wantCopyLast bool // Whether "Son öğeyi kopyala" is enabled
+	}{
+		{
+			name:         "capturing",
+			state:        trayState{lastPreview: "merhaba"},
+			wantCapture:  "Yakalamayı Duraklat",
+			wantLast:     "Son: merhaba",
+			wantCopyLast: true,
+		},
+		{
+			name:        "paused until resumed, empty history",
+			state:       trayState{paused: true},
+			wantCapture: "Yakalamayı Duraklat",
+			wantLast:    "Son: (boş)",
+			wantChecked: true,
+		},
+		{
+			name: "paused for a while",
+			state: trayState{
+				paused:      true,
+				pausedUntil: time.Date(2024, 3, 1, 14, 5, 0, 0, time.Local),
+				lastPreview: "şifre gibi",
+			},
+			wantCapture:  "Yakalamayı Duraklat (bitiş 14:05)",
+			wantLast:     "Son: şifre gibi",
+			wantChecked:  true,
+			wantCopyLast: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			menu := buildTrayMenu(tt.state, trayActions{})
+			want := []string{"Aç", "Gizle", "-", tt.wantCapture, pauseLabel, tt.wantLast, "Son öğeyi kopyala", "-", "Çıkış"}
+			if got := menuLabels(menu); !slices.Equal(got, want) {
+				t.Fatalf("menu is %q, want %q", got, want)
+			}
+			if got := menu.Items[3].Checked; got != tt.wantChecked {
+				t.Errorf("capture item checked %v, want %v", got, tt.wantChecked)
+			}
+			if !menu.Items[5].Disabled {
+				t.Error("the last item preview can be clicked")
+			}
+			if got := !menu.Items[6].Disabled; got != tt.wantCopyLast {
+				t.Errorf("copy last enabled %v, want %v", got, tt.wantCopyLast)
+			}
+		})
+	}
+}
+
+// TestBuildTrayMenuActions checks that each item runs its own action
+func TestBuildTrayMenuActions(t *testing.T) {
+	var ran []string
+	record := func(name string) func() {
+		return func() { ran = append(ran, name) }
+	}
+	menu := buildTrayMenu(trayState{lastPreview: "x"}, trayActions{
+		show:          record("show"),
+		hide:          record("hide"),
+		toggleCapture: record("toggleCapture"),
+		pauseTimed:    record("pauseTimed"),
+		copyLast:      record("copyLast"),
+		quit:          record("quit"),
+	})
+	for _, item := range menu.Items {
+		if item.Action != nil {
+			item.Action()
+		}
+	}
+	want := []string{"show", "hide", "toggleCapture", "pauseTimed", "copyLast", "quit"}
+	if !slices.Equal(ran, want) {
+		t.Errorf("actions ran %q, want %q", ran, want)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package ui
+
+import "fyne.io/systray"
+
+// bindTrayClick makes a left click on the tray icon call toggle, on the tray's thread
+// Fyne's systray already tells the clicks apart on its notify icon window; with a
+// tapped handler set, only the right click opens the menu
+func bindTrayClick(toggle func()) {
+	systray.SetOnTapped(toggle)
+}
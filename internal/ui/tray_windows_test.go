@@ -0,0 +1,122 @@
+//go:build windows && ci
+// +build windows,ci
+
+package ui
+
+import (
+	"runtime"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"fyne.io/systray"
+)
+
+var (
+	procEnumThreadWindows = user32.NewProc("EnumThreadWindows")
+	procPostMessageW      = user32.NewProc("PostMessageW")
+	procPeekMessageW      = user32.NewProc("PeekMessageW")
+	procTranslateMessage  = user32.NewProc("TranslateMessage")
+	procDispatchMessageW  = user32.NewProc("DispatchMessageW")
+)
+
+const (
+	// trayCallbackMessage is what Fyne's systray asks Shell_NotifyIcon to send its
+	// window (WM_USER + 1), with the mouse message in lParam
+	trayCallbackMessage = 0x0400 + 1
+
+	wmMouseMove     = 0x0200
+	wmLButtonDown   = 0x0201
+	wmLButtonUp     = 0x0202
+	wmLButtonDblClk = 0x0203
+	wmRButtonDown   = 0x0204
+	wmRButtonUp     = 0x0205
+
+	pmRemove = 0x0001
+)
+
+// winMsg is the Win32 MSG structure
+type winMsg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+}
+
+// trayWindow returns the notify icon window systray created on the calling thread
+func trayWindow(t *testing.T) uintptr {
+	t.Helper()
+	var found uintptr
+	enum := syscall.NewCallback(func(hwnd, _ uintptr) uintptr {
+		buf := make([]uint16, 64)
+		n, _, _ := procGetClassNameW.Call(hwnd, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+		if syscall.UTF16ToString(buf[:n]) == "SystrayClass" {
+			found = hwnd
+			return 0
+		}
+		return 1
+	})
+	tid, _, _ := procGetCurrentThreadId.Call()
+	procEnumThreadWindows.Call(tid, enum, 0)
+	if found == 0 {
+		t.Fatal("systray created no window on this thread")
+	}
+	return found
+}
+
+// pumpMessages dispatches everything queued for the calling thread, as the tray's
+// message loop would
+func pumpMessages() {
+	var msg winMsg
+	for {
+		ok, _, _ := procPeekMessageW.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0, pmRemove)
+		if ok == 0 {
+			return
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&msg)))
+	}
+}
+
+// TestTrayMessages posts notify icon messages to the real tray window: only a released
+// left button toggles, a released right button goes to the menu
+func TestTrayMessages(t *testing.T) {
+	// The tray window belongs to the thread that creates it and gets its messages there
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	systray.Register(nil, nil)
+	var toggles, menus int
+	bindTrayClick(func() { toggles++ })
+	// Stands in for the menu, whose modal loop the test couldn't close
+	systray.SetOnSecondaryTapped(func() { menus++ })
+	defer systray.SetOnSecondaryTapped(nil)
+	hwnd := trayWindow(t)
+
+	tests := []struct {
+		name          string
+		mouse         []uintptr // lParam of each callback message, in order
+		toggles, menu int
+	}{
+		{"left click", []uintptr{wmLButtonDown, wmLButtonUp}, 1, 0},
+		{"right click", []uintptr{wmRButtonDown, wmRButtonUp}, 0, 1},
+		{"double click", []uintptr{wmLButtonDown, wmLButtonUp, wmLButtonDblClk, wmLButtonUp}, 2, 0},
+		{"hover", []uintptr{wmMouseMove, wmMouseMove}, 0, 0},
+		{"press without release", []uintptr{wmLButtonDown, wmRButtonDown}, 0, 0},
+	}
+	// No subtests: t.Run would move each case to another goroutine, off this thread
+	for _, tt := range tests {
+		toggles, menus = 0, 0
+		for _, mouse := range tt.mouse {
+			if ok, _, err := procPostMessageW.Call(hwnd, trayCallbackMessage, 0, mouse); ok == 0 {
+				t.Fatalf("%s: failed to post message: %v", tt.name, err)
+			}
+		}
+		pumpMessages()
+		if toggles != tt.toggles || menus != tt.menu {
+			t.Errorf("%s: %d toggles and %d menus, want %d and %d", tt.name, toggles, menus, tt.toggles, tt.menu)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"pano/internal/clipboard"
+	"pano/internal/storage"
+	"pano/internal/system"
+)
+
+// The item subcommands ("list", "get", "copy", "delete") go through the running instance
+// when there is one, so the CLI never writes the database behind the GUI's back; without
+// one they open the database directly.
+
+// itemStore is where an item subcommand runs
+type itemStore interface {
+	List(limit int) ([]system.IPCItem, error)
+	Get(id string) (system.IPCContent, error)
+	Copy(id string) error
+	Delete(id string) error
+	Close() error
+}
+
+// openItemStore connects to the running instance, or opens the database without one
+func openItemStore() (itemStore, error) {
+	dbPath, err := storage.GetDatabasePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate database: %w", err)
+	}
+	client, err := system.DialIPC(filepath.Dir(dbPath))
+	if err == nil {
+		return remoteStore{client}, nil
+	}
+	if !errors.Is(err, system.ErrNoInstance) {
+		return nil, err
+	}
+
+	db, err := storage.NewDatabaseWithPassword(promptMasterPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetAuditSource(storage.AuditSourceCLI)
+	if err := db.LoadError(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load database: %w", err)
+	}
+	return localStore{db: db, manager: clipboard.NewManager(db)}, nil
+}
+
+// remoteStore sends item commands to the running instance
+type remoteStore struct {
+	client *system.IPCClient
+}
+
+func (s remoteStore) List(limit int) ([]system.IPCItem, error) {
+	var items []system.IPCItem
+	err := s.client.Call(system.IPCRequest{Command: system.IPCCommandList, Limit: limit}, &items)
+	return items, err
+}
+
+func (s remoteStore) Get(id string) (system.IPCContent, error) {
+	var content system.IPCContent
+	err := s.client.Call(system.IPCRequest{Command: system.IPCCommandGet, ID: id}, &content)
+	return content, err
+}
+
+func (s remoteStore) Copy(id string) error {
+	return s.client.Call(system.IPCRequest{Command: system.IPCCommandCopy, ID: id}, nil)
+}
+
+func (s remoteStore) Delete(id string) error {
+	return s.client.Call(system.IPCRequest{Command: system.IPCCommandDelete, ID: id}, nil)
+}
+
+func (s remoteStore) Close() error {
+	return nil
+}
+
+// localStore runs item commands on the database when no instance holds it
+type localStore struct {
+	db      *storage.Database
+	manager *clipboard.Manager
+}
+
+func (s localStore) List(limit int) ([]system.IPCItem, error) {
+	items := s.manager.GetAllItems()
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Timestamp.After(items[j].Timestamp)
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	result := make([]system.IPCItem, 0, len(items))
+	for _, item := range items {
+		entry := system.IPCItem{ID: item.ID, Type: item.Type, Timestamp: item.Timestamp, Pinned: item.Pinned, Size: item.Size,
+			Protected: item.IsProtected()}
+		if item.Type == "text" {
+			if content, err := s.manager.GetItemContent(item.ID); err == nil {
+				entry.Preview = system.IPCPreview(string(content))
+				storage.Zero(content)
+			}
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+func (s localStore) Get(id string) (system.IPCContent, error) {
+	item, content, err := s.db.GetItem(id)
+	if err != nil {
+		return system.IPCContent{}, err
+	}
+	if item.IsProtected() {
+		storage.Zero(content)
+		return system.IPCContent{}, storage.ErrProtected
+	}
+	if item.Type == "text" {
+		defer storage.Zero(content)
+		return system.IPCContent{Type: item.Type, Text: string(content)}, nil
+	}
+	return system.IPCContent{Type: item.Type, Data: content}, nil
+}
+
+func (s localStore) Copy(id string) error {
+	return s.manager.CopyToClipboard(id)
+}
+
+func (s localStore) Delete(id string) error {
+	return s.manager.DeleteItem(id)
+}
+
+func (s localStore) Close() error {
+	return s.db.Close()
+}
+
+// runItems handles "pano list|get|copy|delete" and returns the exit code
+func runItems(command string, args []string) int {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	limit := 0
+	out := "-"
+	if command == "list" {
+		fs.IntVar(&limit, "n", 20, "number of items to list, 0 for all")
+	}
+	if command == "get" {
+		fs.StringVar(&out, "o", "-", "output file, - for stdout")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	id := fs.Arg(0)
+	if command != "list" && id == "" {
+		fmt.Fprintf(os.Stderr, "no item given, use pano %s <id>\n", command)
+		return 2
+	}
+
+	store, err := openItemStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", command, err)
+		return 1
+	}
+	defer store.Close()
+
+	switch command {
+	case "list":
+		err = printItems(store, limit)
+	case "get":
+		err = writeItem(store, id, out)
+	case "copy":
+		err = store.Copy(id)
+	case "delete":
+		err = store.Delete(id)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", command, err)
+		return 1
+	}
+	return 0
+}
+
+// printItems writes one line per item: ID, time, type and size, pin and preview
+// (or [protected] for items that need their passphrase)
+func printItems(store itemStore, limit int) error {
+	items, err := store.List(limit)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		pin := " "
+		if item.Pinned {
+			pin = "*"
+		}
+		preview := item.Preview
+		if item.Protected {
+			preview = "[protected]"
+		}
+		fmt.Printf("%s\t%s\t%s\t%d\t%s %s\n", item.ID, item.Timestamp.Format(time.DateTime), item.Type, item.Size, pin, preview)
+	}
+	return nil
+}
+
+// writeItem writes an item's content to out (text as is, images as PNG)
+func writeItem(store itemStore, id, out string) error {
+	content, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+	data := content.Data
+	if content.Type == "text" {
+		data = []byte(content.Text)
+	}
+	defer storage.Zero(data)
+
+	if out == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(out, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	return nil
+}
@@ -1,20 +1,60 @@
 package main
 
 import (
+	"flag"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
 
+	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/dialog"
 
+	"pano/internal/clipboard"
 	"pano/internal/storage"
 	"pano/internal/system"
 	"pano/internal/ui"
 )
 
 func main() {
+	// Subcommands run without the UI and ask for a recovery key on the terminal; the
+	// window offers it from the list instead
+	if len(os.Args) > 1 {
+		storage.SetRecoveryPrompt(promptRecoveryKey)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExport(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "add" {
+		os.Exit(runAdd(os.Args[2:]))
+	}
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "list", "get", "copy", "delete":
+			os.Exit(runItems(os.Args[1], os.Args[2:]))
+		case "rekey":
+			os.Exit(runRekey(os.Args[2:]))
+		case "sqlite":
+			os.Exit(runSQLite(os.Args[2:]))
+		}
+	}
+
+	configPath, flagConfig := parseFlags()
+
+	// Keep a rotating log next to the database so bug reports can include it
+	if dbPath, err := storage.GetDatabasePath(); err == nil {
+		if logFile, err := system.OpenRotatingLog(filepath.Join(filepath.Dir(dbPath), system.LogDirName)); err == nil {
+			log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+			defer logFile.Close()
+		} else {
+			log.Printf("Warning: Logging to stderr only: %v", err)
+		}
+	}
+
 	// Initialize Fyne app with ID
 	fyneApp := app.NewWithID("com.pano.clipboard")
 
@@ -22,10 +62,58 @@ func main() {
 	appIcon := getPanoIcon()
 	fyneApp.SetIcon(appIcon)
 
-	// Initialize database
-	db, err := storage.NewDatabase()
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	// With a master password nothing is opened before it is entered in a window of its
+	// own; the rest starts once it matches
+	var shutdown func()
+	if storage.PasswordEnabled() {
+		var db *storage.Database
+		ui.ShowUnlockWindow(fyneApp, func(password string) error {
+			var err error
+			db, err = storage.NewDatabaseWithPassword(func(retry bool) (string, bool) {
+				return password, !retry
+			})
+			return err
+		}, func() {
+			appUI, stop, err := startApp(fyneApp, db, configPath, flagConfig)
+			if err != nil {
+				log.Printf("Failed to start monitoring: %v", err)
+				fyneApp.Quit()
+				return
+			}
+			shutdown = stop
+			appUI.ShowStarted()
+		})
+		fyneApp.Run()
+	} else {
+		db, err := storage.NewDatabase()
+		if err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		appUI, stop, err := startApp(fyneApp, db, configPath, flagConfig)
+		if err != nil {
+			dialog.ShowError(err, appUI.GetWindow())
+			return
+		}
+		shutdown = stop
+
+		// Run application - window starts hidden (background mode)
+		// User can show it with Alt+V or tray menu
+		// X button hides window instead of quitting (tray menu has Quit option)
+		appUI.Run()
+	}
+
+	// Cleanup on normal exit
+	if shutdown != nil {
+		shutdown()
+	}
+}
+
+// startApp builds the UI around an opened database and starts everything that runs in
+// the background; the returned func tears it down once. A failure to start monitoring
+// is returned with the app it left behind
+func startApp(fyneApp fyne.App, db *storage.Database, configPath string, flagConfig *clipboard.Config) (*ui.App, func(), error) {
+	if err := db.LoadError(); err != nil {
+		log.Printf("Warning: Failed to load database, captures are journaled until it loads: %v", err)
 	}
 
 	// Initialize autostart manager
@@ -34,8 +122,31 @@ func main() {
 		log.Fatalf("Failed to initialize autostart: %v", err)
 	}
 
+	// Load defaults file (flags and preferences override it)
+	if configPath == "" {
+		if configPath, err = clipboard.GetConfigPath(); err != nil {
+			log.Fatalf("Failed to locate config: %v", err)
+		}
+	}
+	fileConfig, err := clipboard.LoadConfig(configPath)
+	if err != nil {
+		log.Printf("Warning: Ignoring config file: %v", err)
+		fileConfig = &clipboard.Config{}
+	}
+
 	// Create UI
-	appUI := ui.NewApp(fyneApp, db, autostart)
+	appUI := ui.NewApp(fyneApp, db, autostart, fileConfig, flagConfig)
+
+	// Explorer entries that send files to Pano, toggled from settings
+	// Elsewhere there is no file manager to integrate with and the setting is hidden
+	if shellMenu, err := system.NewShellMenuManager(); err != nil {
+		log.Printf("Warning: Shell integration unavailable: %v", err)
+	} else if shellMenu.Supported() {
+		appUI.SetShellIntegration(shellMenu)
+	}
+
+	// Icons are redrawn at the pixel size of the monitor the window is on
+	appUI.SetIconRenderer(getPanoIconSized)
 
 	// Setup system tray
 	ui.SetupSystemTray(appUI)
@@ -43,37 +154,114 @@ func main() {
 	// Initialize hotkey manager (Ctrl+Shift+V to toggle window)
 	hotkeyMgr := system.NewHotkeyManager()
 	hotkeyMgr.SetCallback(func() {
-		appUI.Toggle()
+		appUI.Toggle(ui.ShowSourceHotkey)
 	})
 
+	// Ctrl+Shift+S (configurable) captures the clipboard even while paused
+	appUI.BindHotkeys(hotkeyMgr)
+
+	// Selecting text with the mouse copies it, if turned on; it reads the same hook
+	selectionWatcher := system.NewSelectionWatcher()
+	hotkeyMgr.SetInputHandler(selectionWatcher.HandleEvent)
+	appUI.BindSelection(selectionWatcher)
+
 	// Start hotkey listener
 	if err := hotkeyMgr.Start(); err != nil {
 		log.Printf("Warning: Failed to register hotkey: %v", err)
 	}
 
+	// Pause capture while the workstation is locked
+	sessionWatcher := system.NewSessionWatcher()
+	sessionWatcher.SetCallback(appUI.SetSessionLocked)
+	if err := sessionWatcher.Start(); err != nil {
+		log.Printf("Warning: Failed to watch session lock: %v", err)
+	}
+
+	// Back off while a full-screen game runs
+	gameWatcher := system.NewGameModeWatcher()
+	gameWatcher.SetCallback(appUI.SetGameMode)
+	if err := gameWatcher.Start(); err != nil {
+		log.Printf("Warning: Failed to watch for full-screen apps: %v", err)
+	}
+
 	// Start clipboard monitoring
 	if err := appUI.StartMonitoring(); err != nil {
-		dialog.ShowError(err, appUI.GetWindow())
-		return
+		return appUI, nil, err
+	}
+
+	// Accept files from "pano add" while running
+	if err := appUI.StartIPC(); err != nil {
+		log.Printf("Warning: Failed to start IPC server: %v", err)
 	}
 
+	// Verify stored items in the background
+	appUI.StartIntegrityCheck()
+
+	// Send the weekly digest when it is due, if enabled
+	appUI.StartDigest()
+
+	// Remove unpinned items older than the retention period, if one is set
+	appUI.StartRetention()
+
+	// Remove temp files left behind by crashes or interrupted writes
+	go func() {
+		report, err := storage.CleanTempFiles(storage.JanitorOptions{MaxAge: storage.DefaultTempMaxAge})
+		if err != nil {
+			log.Printf("Warning: Temp file cleanup failed: %v", err)
+			return
+		}
+		for _, path := range report.Files {
+			log.Printf("Removed temp file: %s", path)
+		}
+		for _, err := range report.Errors {
+			log.Printf("Warning: %v", err)
+		}
+		log.Printf("Temp file cleanup: %s", report)
+	}()
+
+	// Teardown runs once, from the signal handler or after the window closes
+	shutdown := sync.OnceFunc(func() {
+		gameWatcher.Stop()
+		sessionWatcher.Stop()
+		if err := appUI.Shutdown(); err != nil {
+			log.Printf("Warning: Shutdown incomplete: %v", err)
+		}
+	})
+
 	// Setup graceful shutdown handler
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
 		log.Println("Shutting down gracefully...")
-		hotkeyMgr.Stop()
-		appUI.StopMonitoring()
+		shutdown()
 		os.Exit(0)
 	}()
 
-	// Run application - window starts hidden (background mode)
-	// User can show it with Alt+V or tray menu
-	// X button hides window instead of quitting (tray menu has Quit option)
-	appUI.Run()
+	return appUI, shutdown, nil
+}
 
-	// Cleanup on normal exit
-	hotkeyMgr.Stop()
-	appUI.StopMonitoring()
+// parseFlags reads command line flags; only flags that were given end up in the config
+func parseFlags() (string, *clipboard.Config) {
+	configPath := flag.String("config", "", "path to the JSON defaults file")
+	maxItems := flag.Int("max-items", 0, "history limit")
+	pollInterval := flag.Int("poll-interval-ms", 0, "clipboard poll interval in milliseconds")
+	newline := flag.String("newline", "", "line endings for copied text: lf, crlf")
+	flag.Parse()
+
+	cfg := &clipboard.Config{}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "max-items":
+			cfg.MaxItems = maxItems
+		case "poll-interval-ms":
+			cfg.PollIntervalMs = pollInterval
+		case "newline":
+			cfg.NewlineMode = newline
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+	return *configPath, cfg
 }
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// promptMasterPassword reads the master password from the terminal with echo off;
+// without a terminal it gives up right away
+func promptMasterPassword(retry bool) (string, bool) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return "", false
+	}
+
+	if retry {
+		fmt.Fprintln(os.Stderr, "Wrong password.")
+	}
+	fmt.Fprint(os.Stderr, "Master password (empty to skip): ")
+	restore := disableEcho()
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	restore()
+	fmt.Fprintln(os.Stderr)
+	if err != nil && line == "" {
+		return "", false
+	}
+	line = strings.TrimRight(line, "\r\n")
+	return line, line != ""
+}
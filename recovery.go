@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"pano/internal/storage"
+)
+
+// promptRecoveryKey reads a recovery key from the terminal after the history failed to
+// open with this machine's key; without a terminal it gives up right away
+func promptRecoveryKey(cause error) (string, bool) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return "", false
+	}
+
+	if errors.Is(cause, storage.ErrInvalidRecoveryKey) {
+		fmt.Fprintln(os.Stderr, "That is not a recovery key.")
+	} else {
+		fmt.Fprintf(os.Stderr, "The history can't be opened with this key: %v\n", cause)
+	}
+	fmt.Fprint(os.Stderr, "Recovery key (empty to skip): ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	line = strings.TrimSpace(line)
+	return line, line != ""
+}
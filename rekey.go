@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"pano/internal/storage"
+	"pano/internal/system"
+)
+
+// runRekey handles "pano rekey": it re-encrypts the history with a new data key, or
+// with -check only reports reused nonces, and returns the exit code
+// The running instance holds the database in memory, so it has to be closed first
+func runRekey(args []string) int {
+	fs := flag.NewFlagSet("rekey", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only scan for reused nonces, don't re-key")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	dbPath, err := storage.GetDatabasePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate database: %v\n", err)
+		return 1
+	}
+	err = system.ProbeIPC(filepath.Dir(dbPath))
+	if err == nil {
+		fmt.Fprintln(os.Stderr, "Pano is running; close it first or use Settings > Diagnostics")
+		return 1
+	}
+	if !errors.Is(err, system.ErrNoInstance) {
+		fmt.Fprintf(os.Stderr, "failed to reach the running instance: %v\n", err)
+		return 1
+	}
+
+	db, err := storage.NewDatabaseWithPassword(promptMasterPassword)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	db.SetAuditSource(storage.AuditSourceCLI)
+	if err := db.LoadError(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load database: %v\n", err)
+		return 1
+	}
+
+	if !*check {
+		err := db.Rekey(func(done, total int) {
+			fmt.Fprintf(os.Stderr, "\rRe-keying %d/%d", done, total)
+		})
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rekey failed: %v\n", err)
+			return 1
+		}
+	}
+
+	report := db.NonceReport()
+	fmt.Fprintf(os.Stderr, "Scanned %d encrypted fields, %d reused nonces\n", report.Scanned, report.Duplicates)
+	if report.Duplicates > 0 {
+		return 1
+	}
+	return 0
+}
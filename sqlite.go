@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"pano/internal/storage"
+	"pano/internal/system"
+)
+
+// runSQLite handles "pano sqlite": it moves the history from the database file to the
+// SQLite row store, once, and returns the exit code
+// The running instance holds the database in memory, so it has to be closed first
+func runSQLite(args []string) int {
+	fs := flag.NewFlagSet("sqlite", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	dbPath, err := storage.GetDatabasePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to locate database: %v\n", err)
+		return 1
+	}
+	err = system.ProbeIPC(filepath.Dir(dbPath))
+	if err == nil {
+		fmt.Fprintln(os.Stderr, "Pano is running; close it first")
+		return 1
+	}
+	if !errors.Is(err, system.ErrNoInstance) {
+		fmt.Fprintf(os.Stderr, "failed to reach the running instance: %v\n", err)
+		return 1
+	}
+	if storage.SQLiteEnabled() {
+		fmt.Fprintf(os.Stderr, "History is already in %s\n", storage.SQLiteFile)
+		return 0
+	}
+
+	db, err := storage.NewDatabaseWithPassword(promptMasterPassword)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	db.SetAuditSource(storage.AuditSourceCLI)
+	if err := db.LoadError(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load database: %v\n", err)
+		return 1
+	}
+
+	if err := db.MigrateToSQLite(); err != nil {
+		fmt.Fprintf(os.Stderr, "migration failed: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Moved %d items to %s\n", len(db.GetAllItems()), storage.SQLiteFile)
+	return 0
+}